@@ -17,6 +17,7 @@ import (
 	"os"
 
 	"github.com/perses/perses/internal/cli/cmd/apply"
+	"github.com/perses/perses/internal/cli/cmd/backup"
 	"github.com/perses/perses/internal/cli/cmd/describe"
 	"github.com/perses/perses/internal/cli/cmd/get"
 	"github.com/perses/perses/internal/cli/cmd/lint"
@@ -24,6 +25,10 @@ import (
 	"github.com/perses/perses/internal/cli/cmd/migrate"
 	"github.com/perses/perses/internal/cli/cmd/project"
 	"github.com/perses/perses/internal/cli/cmd/remove"
+	"github.com/perses/perses/internal/cli/cmd/restore"
+	"github.com/perses/perses/internal/cli/cmd/search"
+	"github.com/perses/perses/internal/cli/cmd/token"
+	"github.com/perses/perses/internal/cli/cmd/trash"
 	"github.com/perses/perses/internal/cli/cmd/version"
 	"github.com/perses/perses/internal/cli/config"
 	"github.com/sirupsen/logrus"
@@ -43,6 +48,7 @@ func newRootCommand() *cobra.Command {
 
 	// The list of the commands supported
 	cmd.AddCommand(apply.NewCMD())
+	cmd.AddCommand(backup.NewCMD())
 	cmd.AddCommand(describe.NewCMD())
 	cmd.AddCommand(get.NewCMD())
 	cmd.AddCommand(lint.NewCMD())
@@ -50,6 +56,10 @@ func newRootCommand() *cobra.Command {
 	cmd.AddCommand(migrate.NewCMD())
 	cmd.AddCommand(project.NewCMD())
 	cmd.AddCommand(remove.NewCMD())
+	cmd.AddCommand(restore.NewCMD())
+	cmd.AddCommand(search.NewCMD())
+	cmd.AddCommand(token.NewCMD())
+	cmd.AddCommand(trash.NewCMD())
 	cmd.AddCommand(version.NewCMD())
 
 	// the list of the global flags supported
@@ -15,9 +15,15 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"os"
+	"reflect"
 
 	"github.com/perses/perses/internal/api/config"
 	"github.com/perses/perses/internal/api/core"
+	"github.com/perses/perses/internal/api/shared/database"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/internal/api/shared/storagemigrate"
 	"github.com/sirupsen/logrus"
 )
 
@@ -37,13 +43,29 @@ All your monitoring dashboards in one place.               <\
 `
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "storage-migrate" {
+		if err := storageMigrate(os.Args[2:]); err != nil {
+			logrus.WithError(err).Fatal("unable to copy the resources to the destination database")
+		}
+		return
+	}
 	configFile := flag.String("config", "", "Path to the YAML configuration file for the API. Configuration settings can be overridden when using environment variables.")
+	migrateDBOnly := flag.Bool("migrate-db-only", false, "Apply the migrations of the database schema and exit without starting the API.")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprintf(flag.CommandLine.Output(), "\nTo copy every resource from a database to another one, run:\n  %s storage-migrate -from <config> -to <config>\n", os.Args[0])
+	}
 	flag.Parse()
 	// load the config from file or/and from environment
 	conf, err := config.Resolve(*configFile)
 	if err != nil {
 		logrus.WithError(err).Fatalf("error reading configuration from file %q or from environment", *configFile)
 	}
+	if *migrateDBOnly {
+		migrateDatabase(conf)
+		return
+	}
 	runner, persistentManager, err := core.New(conf, banner)
 	if err != nil {
 		logrus.Fatal(err)
@@ -57,3 +79,97 @@ func main() {
 	// start the application
 	runner.Start()
 }
+
+// migrateDatabase initializes the database, which applies the migrations of the schema not applied yet.
+func migrateDatabase(conf config.Config) {
+	dao, err := database.New(conf.Database)
+	if err != nil {
+		logrus.WithError(err).Fatal("unable to connect to the database")
+	}
+	defer func() {
+		if daoCloseErr := dao.Close(); daoCloseErr != nil {
+			logrus.WithError(daoCloseErr).Error("unable to close the connection to the database")
+		}
+	}()
+	if initErr := dao.Init(); initErr != nil {
+		logrus.WithError(initErr).Fatal("unable to migrate the database")
+	}
+	logrus.Info("the database is up to date")
+}
+
+// storageMigrate copies every resource from the database of a configuration to the database of another configuration.
+// The API must not be running on either database while the resources are copied.
+func storageMigrate(args []string) error {
+	flags := flag.NewFlagSet("storage-migrate", flag.ExitOnError)
+	fromFile := flags.String("from", "", "Path to the YAML configuration file of the API whose database is copied.")
+	toFile := flags.String("to", "", "Path to the YAML configuration file of the API whose database receives the resources.")
+	dryRun := flags.Bool("dry-run", false, "Print what would be copied without writing anything in the destination database, not even its schema.")
+	resume := flags.Bool("resume", false, "Continue an interrupted migration. Without it, the destination database must be empty.")
+	_ = flags.Parse(args)
+	if len(*fromFile) == 0 || len(*toFile) == 0 {
+		return fmt.Errorf("both -from and -to must be provided")
+	}
+	fromConf, err := config.Resolve(*fromFile)
+	if err != nil {
+		return fmt.Errorf("error reading configuration from file %q or from environment: %w", *fromFile, err)
+	}
+	toConf, err := config.Resolve(*toFile)
+	if err != nil {
+		return fmt.Errorf("error reading configuration from file %q or from environment: %w", *toFile, err)
+	}
+	if reflect.DeepEqual(fromConf.Database, toConf.Database) {
+		return fmt.Errorf("the source and the destination are the same database")
+	}
+	from, err := openDatabase(fromConf, true)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(from)
+	// a dry run must not write anything in the destination, so its schema is not migrated.
+	to, err := openDatabase(toConf, !*dryRun)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(to)
+
+	report, err := storagemigrate.Migrate(from, to, storagemigrate.Options{DryRun: *dryRun, Resume: *resume})
+	if report != nil {
+		for _, k := range report.Kinds {
+			logrus.Infof("%s: %d in the source, %d created, %d updated, %d already copied", k.Kind, k.Source, k.Created, k.Updated, k.Unchanged)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("the migration has failed, it can be continued with -resume: %w", err)
+	}
+	if *dryRun {
+		logrus.Info("dry run: nothing has been written in the destination database")
+		return nil
+	}
+	if verifyErr := storagemigrate.Verify(from, to); verifyErr != nil {
+		return fmt.Errorf("the migration cannot be verified: %w", verifyErr)
+	}
+	logrus.Info("the migration is complete, the resource counts and checksums of both databases match")
+	return nil
+}
+
+// openDatabase connects to the database of the configuration. When init is true, it applies the migrations of its schema.
+func openDatabase(conf config.Config, init bool) (databaseModel.DAO, error) {
+	dao, err := database.New(conf.Database)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the database: %w", err)
+	}
+	if !init {
+		return dao, nil
+	}
+	if initErr := dao.Init(); initErr != nil {
+		closeDatabase(dao)
+		return nil, fmt.Errorf("unable to migrate the database: %w", initErr)
+	}
+	return dao, nil
+}
+
+func closeDatabase(dao databaseModel.DAO) {
+	if err := dao.Close(); err != nil {
+		logrus.WithError(err).Error("unable to close the connection to the database")
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+)
+
+type AuditSink string
+
+const (
+	// AuditSinkDatabase stores the audit records in the database, like the other resources.
+	AuditSinkDatabase AuditSink = "database"
+	// AuditSinkFile appends the audit records to a file, one JSON document per line.
+	AuditSinkFile AuditSink = "file"
+	// AuditSinkStdout writes the audit records to the standard output, one JSON document per line. They cannot be queried.
+	AuditSinkStdout AuditSink = "stdout"
+)
+
+// Audit contains the configuration of the audit log, recording every change made with the API and every request modifying a datasource.
+type Audit struct {
+	Enable bool `json:"enable" yaml:"enable"`
+	// Sink is where the records are written: database, file or stdout. Default is database.
+	Sink AuditSink `json:"sink,omitempty" yaml:"sink,omitempty"`
+	// File is the path of the file where the records are appended. It is required by the sink file.
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+}
+
+func (a *Audit) Verify() error {
+	if len(a.Sink) == 0 {
+		a.Sink = AuditSinkDatabase
+	}
+	switch a.Sink {
+	case AuditSinkDatabase, AuditSinkStdout:
+	case AuditSinkFile:
+		if len(a.File) == 0 {
+			return fmt.Errorf("audit.file is required by the sink %q", AuditSinkFile)
+		}
+	default:
+		return fmt.Errorf("unknown audit.sink %q, it can only be %q, %q or %q", a.Sink, AuditSinkDatabase, AuditSinkFile, AuditSinkStdout)
+	}
+	return nil
+}
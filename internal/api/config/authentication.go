@@ -0,0 +1,213 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+
+	promConfig "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	defaultAccessTokenTTL  = model.Duration(15 * time.Minute)
+	defaultRefreshTokenTTL = model.Duration(24 * time.Hour)
+	defaultAPITokenMaxTTL  = model.Duration(90 * 24 * time.Hour)
+	defaultOIDCLoginClaim  = "preferred_username"
+	defaultOIDCGroupsClaim = "groups"
+	defaultOAuthLoginClaim = "login"
+)
+
+var (
+	defaultOIDCScopes = []string{"openid", "profile", "email"}
+	slugIDPattern     = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// Authentication contains the configuration of the authentication of the users. The tokens are signed with keys derived from the encryption key,
+// so every instance sharing the encryption key accepts the tokens of the others.
+type Authentication struct {
+	// Enable rejects the requests to the API that don't have a valid access token. The tokens are obtained by logging in with a user.
+	Enable bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// DisableSignUp rejects the creation of a user by a request that is not authenticated.
+	// The sign-up is required to create the first user, and it should be disabled afterwards.
+	DisableSignUp bool `json:"disable_sign_up,omitempty" yaml:"disable_sign_up,omitempty"`
+	// AccessTokenTTL is how long an access token is valid. Default is 15 minutes.
+	AccessTokenTTL model.Duration `json:"access_token_ttl,omitempty" yaml:"access_token_ttl,omitempty"`
+	// RefreshTokenTTL is how long a refresh token, used to get a new access token, is valid. Default is 24 hours.
+	RefreshTokenTTL model.Duration `json:"refresh_token_ttl,omitempty" yaml:"refresh_token_ttl,omitempty"`
+	// APITokenMaxTTL is how long an API token of a service account can be valid at most. It is the validity of the tokens created without a ttl.
+	// Default is 90 days.
+	APITokenMaxTTL model.Duration `json:"api_token_max_ttl,omitempty" yaml:"api_token_max_ttl,omitempty"`
+	// Providers are the identity providers the users can log in with, in addition to the users managed by Perses.
+	Providers AuthProviders `json:"providers,omitempty" yaml:"providers,omitempty"`
+}
+
+func (a *Authentication) Verify() error {
+	if a.AccessTokenTTL < 0 || a.RefreshTokenTTL < 0 || a.APITokenMaxTTL < 0 {
+		return fmt.Errorf("authentication.access_token_ttl, authentication.refresh_token_ttl and authentication.api_token_max_ttl cannot be negative")
+	}
+	if a.AccessTokenTTL == 0 {
+		a.AccessTokenTTL = defaultAccessTokenTTL
+	}
+	if a.RefreshTokenTTL == 0 {
+		a.RefreshTokenTTL = defaultRefreshTokenTTL
+	}
+	if a.APITokenMaxTTL == 0 {
+		a.APITokenMaxTTL = defaultAPITokenMaxTTL
+	}
+	return nil
+}
+
+// AuthProviders are the OpenID Connect and the OAuth 2.0 providers. A user logging in with a provider for the first time is created,
+// with the login given by the provider. The groups given by the provider are the subjects of kind Group of the role bindings.
+type AuthProviders struct {
+	OIDC  []OIDCProvider  `json:"oidc,omitempty" yaml:"oidc,omitempty"`
+	OAuth []OAuthProvider `json:"oauth,omitempty" yaml:"oauth,omitempty"`
+}
+
+func (p *AuthProviders) Verify() error {
+	slugIDs := make(map[string]bool, len(p.OIDC)+len(p.OAuth))
+	for _, provider := range p.OIDC {
+		if slugIDs[provider.SlugID] {
+			return fmt.Errorf("the slug_id %q is used by several providers", provider.SlugID)
+		}
+		slugIDs[provider.SlugID] = true
+	}
+	for _, provider := range p.OAuth {
+		if slugIDs[provider.SlugID] {
+			return fmt.Errorf("the slug_id %q is used by several providers", provider.SlugID)
+		}
+		slugIDs[provider.SlugID] = true
+	}
+	return nil
+}
+
+// OIDCProvider is an OpenID Connect provider. The users are identified by the claims of the ID token.
+type OIDCProvider struct {
+	// SlugID identifies the provider in the URLs of the API, like /api/auth/providers/oidc/{slug_id}/login.
+	SlugID string `json:"slug_id" yaml:"slug_id"`
+	// Name is the name of the provider displayed to the users.
+	Name         string            `json:"name" yaml:"name"`
+	ClientID     string            `json:"client_id" yaml:"client_id"`
+	ClientSecret promConfig.Secret `json:"client_secret,omitempty" yaml:"client_secret,omitempty"`
+	// Issuer is the URL of the provider. Its endpoints are discovered from {issuer}/.well-known/openid-configuration.
+	Issuer string `json:"issuer" yaml:"issuer"`
+	// RedirectURI is the URL of the callback the provider redirects the user to once logged in.
+	// Default is built from the URL of the request: {scheme}://{host}/api/auth/providers/oidc/{slug_id}/callback.
+	// It must be set when Perses is behind a proxy that doesn't keep the host.
+	RedirectURI string `json:"redirect_uri,omitempty" yaml:"redirect_uri,omitempty"`
+	// Scopes are the scopes requested. Default is openid, profile and email.
+	Scopes []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	// LoginClaim is the claim of the ID token containing the login of the user. Default is preferred_username.
+	LoginClaim string `json:"login_claim,omitempty" yaml:"login_claim,omitempty"`
+	// GroupsClaim is the claim of the ID token containing the groups of the user. Default is groups.
+	GroupsClaim string `json:"groups_claim,omitempty" yaml:"groups_claim,omitempty"`
+}
+
+func (p *OIDCProvider) Verify() error {
+	if err := verifyProvider(p.SlugID, p.ClientID, p.RedirectURI); err != nil {
+		return err
+	}
+	if err := verifyURL("issuer", p.Issuer); err != nil {
+		return err
+	}
+	if len(p.Name) == 0 {
+		p.Name = p.SlugID
+	}
+	if len(p.Scopes) == 0 {
+		p.Scopes = defaultOIDCScopes
+	}
+	if len(p.LoginClaim) == 0 {
+		p.LoginClaim = defaultOIDCLoginClaim
+	}
+	if len(p.GroupsClaim) == 0 {
+		p.GroupsClaim = defaultOIDCGroupsClaim
+	}
+	return nil
+}
+
+// OAuthProvider is an OAuth 2.0 provider that doesn't implement OpenID Connect, like GitHub.
+// The users are identified by the fields returned by the user info endpoint.
+type OAuthProvider struct {
+	// SlugID identifies the provider in the URLs of the API, like /api/auth/providers/oauth/{slug_id}/login.
+	SlugID string `json:"slug_id" yaml:"slug_id"`
+	// Name is the name of the provider displayed to the users.
+	Name         string            `json:"name" yaml:"name"`
+	ClientID     string            `json:"client_id" yaml:"client_id"`
+	ClientSecret promConfig.Secret `json:"client_secret,omitempty" yaml:"client_secret,omitempty"`
+	AuthURL      string            `json:"auth_url" yaml:"auth_url"`
+	TokenURL     string            `json:"token_url" yaml:"token_url"`
+	// UserInfoURL is the endpoint returning the user authenticated by the access token, in JSON.
+	UserInfoURL string `json:"user_info_url" yaml:"user_info_url"`
+	// DeviceAuthURL is the endpoint of the device authorization, used to log in with percli. It is optional.
+	DeviceAuthURL string `json:"device_auth_url,omitempty" yaml:"device_auth_url,omitempty"`
+	// RedirectURI is the URL of the callback the provider redirects the user to once logged in.
+	// Default is built from the URL of the request: {scheme}://{host}/api/auth/providers/oauth/{slug_id}/callback.
+	RedirectURI string   `json:"redirect_uri,omitempty" yaml:"redirect_uri,omitempty"`
+	Scopes      []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	// LoginClaim is the field of the user info containing the login of the user. Default is login.
+	LoginClaim string `json:"login_claim,omitempty" yaml:"login_claim,omitempty"`
+	// GroupsClaim is the field of the user info containing the groups of the user. The user has no group when it is not set.
+	GroupsClaim string `json:"groups_claim,omitempty" yaml:"groups_claim,omitempty"`
+}
+
+func (p *OAuthProvider) Verify() error {
+	if err := verifyProvider(p.SlugID, p.ClientID, p.RedirectURI); err != nil {
+		return err
+	}
+	for name, u := range map[string]string{"auth_url": p.AuthURL, "token_url": p.TokenURL, "user_info_url": p.UserInfoURL} {
+		if err := verifyURL(name, u); err != nil {
+			return err
+		}
+	}
+	if len(p.DeviceAuthURL) > 0 {
+		if err := verifyURL("device_auth_url", p.DeviceAuthURL); err != nil {
+			return err
+		}
+	}
+	if len(p.Name) == 0 {
+		p.Name = p.SlugID
+	}
+	if len(p.LoginClaim) == 0 {
+		p.LoginClaim = defaultOAuthLoginClaim
+	}
+	return nil
+}
+
+func verifyProvider(slugID string, clientID string, redirectURI string) error {
+	if !slugIDPattern.MatchString(slugID) {
+		return fmt.Errorf("the slug_id %q of a provider must only contain letters, digits, '-' and '_'", slugID)
+	}
+	if len(clientID) == 0 {
+		return fmt.Errorf("the client_id of the provider %q cannot be empty", slugID)
+	}
+	if len(redirectURI) > 0 {
+		return verifyURL("redirect_uri", redirectURI)
+	}
+	return nil
+}
+
+func verifyURL(name string, value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("the %s of a provider is not a valid URL: %w", name, err)
+	}
+	if !u.IsAbs() || len(u.Host) == 0 {
+		return fmt.Errorf("the %s of a provider must be an absolute URL, not %q", name, value)
+	}
+	return nil
+}
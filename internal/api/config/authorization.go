@@ -0,0 +1,43 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"time"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/prometheus/common/model"
+)
+
+const defaultAuthorizationRefreshInterval = model.Duration(time.Minute)
+
+// Authorization contains the configuration of the permissions of the users, given by the roles and the role bindings.
+type Authorization struct {
+	// Enable rejects the requests of the users that don't have the permission to do what they ask. It requires the authentication.
+	Enable bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// Admins are the logins of the users having every permission. They are needed to create the first roles and role bindings.
+	Admins []string `json:"admins,omitempty" yaml:"admins,omitempty"`
+	// GuestPermissions are given to every authenticated user, in every project and on the global resources.
+	GuestPermissions []v1.Permission `json:"guest_permissions,omitempty" yaml:"guest_permissions,omitempty"`
+	// RefreshInterval is the interval between two reloads of the roles and of the role bindings.
+	// They are reloaded by each write, so it only matters when several instances of Perses share the same database.
+	RefreshInterval model.Duration `json:"refresh_interval,omitempty" yaml:"refresh_interval,omitempty"`
+}
+
+func (a *Authorization) Verify() error {
+	if a.RefreshInterval <= 0 {
+		a.RefreshInterval = defaultAuthorizationRefreshInterval
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	defaultCacheTTL        = model.Duration(time.Minute)
+	defaultCacheMaxEntries = 10000
+)
+
+// Cache contains the configuration of the cache of the resources read from the database.
+type Cache struct {
+	// Enable keeps in memory the resources read from the database. The cache is disabled by default.
+	Enable bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// TTL is how long a resource is kept in the cache. It is the maximum delay before a change made by another instance
+	// of Perses is visible, when the database doesn't send these changes.
+	TTL model.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	// MaxEntries is the maximum number of resources and lists kept in the cache. The least recently used ones are removed first.
+	MaxEntries int `json:"max_entries,omitempty" yaml:"max_entries,omitempty"`
+}
+
+func (c *Cache) Verify() error {
+	if c.TTL <= 0 {
+		c.TTL = defaultCacheTTL
+	}
+	if c.MaxEntries < 0 {
+		return fmt.Errorf("max_entries of the cache cannot be negative")
+	}
+	if c.MaxEntries == 0 {
+		c.MaxEntries = defaultCacheMaxEntries
+	}
+	return nil
+}
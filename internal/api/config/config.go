@@ -17,6 +17,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/rand"
+	"net"
 	"os"
 	"time"
 
@@ -58,10 +59,27 @@ type Config struct {
 	EncryptionKey promConfig.Secret `json:"encryption_key,omitempty" yaml:"encryption_key,omitempty"`
 	// EncryptionKeyFile is the path to file containing the secret key
 	EncryptionKeyFile string `json:"encryption_key_file,omitempty" yaml:"encryption_key_file,omitempty"`
+	// Authentication contains the configuration of the authentication of the users. It is disabled by default.
+	Authentication Authentication `json:"authentication" yaml:"authentication"`
+	// Authorization contains the configuration of the permissions of the users. It is disabled by default.
+	Authorization Authorization `json:"authorization" yaml:"authorization"`
+	// Audit contains the configuration of the audit log of the changes. It is disabled by default.
+	Audit Audit `json:"audit" yaml:"audit"`
+	// TrustedProxies are the CIDRs of the reverse proxies in front of Perses. The IP of a client, recorded in the audit log,
+	// is read from the header X-Forwarded-For only when the request comes from one of them. Otherwise, it is the IP of the connection.
+	TrustedProxies []string `json:"trusted_proxies,omitempty" yaml:"trusted_proxies,omitempty"`
 	// Database contains the different configuration depending on the database you want to use
 	Database Database `json:"database" yaml:"database"`
 	// Schemas contains the configuration to get access to the CUE schemas
 	Schemas Schemas `json:"schemas" yaml:"schemas"`
+	// DashboardRevision contains the retention policy of the revisions saved each time a dashboard is modified
+	DashboardRevision DashboardRevision `json:"dashboard_revision" yaml:"dashboard_revision"`
+	// Search contains the configuration of the full-text search of the dashboards and of the variables
+	Search Search `json:"search" yaml:"search"`
+	// Trash contains the configuration of the trash, where the dashboards and the projects are kept for a while once deleted
+	Trash Trash `json:"trash" yaml:"trash"`
+	// Webhook contains the configuration of the deliveries of the changes to the webhooks
+	Webhook Webhook `json:"webhook" yaml:"webhook"`
 	// ImportantDashboards contains important dashboard selectors
 	ImportantDashboards []dashboardSelector `json:"important_dashboards,omitempty" yaml:"important_dashboards,omitempty"`
 	// Information contains markdown content to be display on the home page
@@ -88,6 +106,14 @@ func (c *Config) Verify() error {
 		return fmt.Errorf("encryption_key must be longer than 32 bytes")
 	}
 	c.EncryptionKey = promConfig.Secret(hex.EncodeToString([]byte(c.EncryptionKey)))
+	if c.Authorization.Enable && !c.Authentication.Enable {
+		return fmt.Errorf("authorization cannot be enabled without the authentication, as the permissions are the ones of the user authenticated")
+	}
+	for _, proxy := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			return fmt.Errorf("the trusted proxy %q is not a CIDR, a single IP is written like 10.0.0.1/32: %w", proxy, err)
+		}
+	}
 	return nil
 }
 
@@ -16,6 +16,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/prometheus/common/config"
@@ -31,6 +32,13 @@ const (
 type File struct {
 	Folder    string        `json:"folder" yaml:"folder"`
 	Extension FileExtension `json:"extension" yaml:"extension"`
+	// Git turns the folder into a git repository, where every change is committed. It is disabled when not set.
+	Git *FileGit `json:"git,omitempty" yaml:"git,omitempty"`
+	// LockFile makes every write take an advisory lock on a file of the folder,
+	// so several instances of Perses can share the folder, like on a network storage.
+	LockFile bool `json:"lock_file,omitempty" yaml:"lock_file,omitempty"`
+	// IntegrityCheck is what is done with the documents that cannot be decoded when Perses starts. Default is "report".
+	IntegrityCheck IntegrityCheck `json:"integrity_check,omitempty" yaml:"integrity_check,omitempty"`
 }
 
 func (f *File) Verify() error {
@@ -40,6 +48,58 @@ func (f *File) Verify() error {
 	if f.Extension != YAMLExtension && f.Extension != JSONExtension {
 		return fmt.Errorf("wrong file extension defined when using the filesystem as a database. You can only define json or yaml")
 	}
+	if len(f.IntegrityCheck) == 0 {
+		f.IntegrityCheck = IntegrityCheckReport
+	}
+	if f.IntegrityCheck != IntegrityCheckReport && f.IntegrityCheck != IntegrityCheckQuarantine && f.IntegrityCheck != IntegrityCheckDisable {
+		return fmt.Errorf("wrong integrity check defined for the file database. You can only define report, quarantine or disable")
+	}
+	return nil
+}
+
+type IntegrityCheck string
+
+const (
+	// IntegrityCheckReport logs the documents that cannot be decoded.
+	IntegrityCheckReport IntegrityCheck = "report"
+	// IntegrityCheckQuarantine logs the documents that cannot be decoded and moves them out of the database.
+	IntegrityCheckQuarantine IntegrityCheck = "quarantine"
+	// IntegrityCheckDisable skips the check.
+	IntegrityCheckDisable IntegrityCheck = "disable"
+)
+
+const (
+	defaultGitCommitterName  = "Perses"
+	defaultGitCommitterEmail = "perses@localhost"
+	defaultGitBranch         = "main"
+)
+
+// FileGit contains the configuration of the git repository storing the history of the file database.
+type FileGit struct {
+	// CommitterName is the name of the committer of the commits. The author of a commit is the user who made the change,
+	// or the committer when the change is not made by a user.
+	CommitterName string `json:"committer_name,omitempty" yaml:"committer_name,omitempty"`
+	// CommitterEmail is the email of the committer of the commits.
+	CommitterEmail string `json:"committer_email,omitempty" yaml:"committer_email,omitempty"`
+	// Branch is the branch where the changes are committed when the repository is created, and where they are pushed.
+	Branch string `json:"branch,omitempty" yaml:"branch,omitempty"`
+	// Remote is a local path or a file:// URL of a repository where the commits are pushed. Nothing is pushed when it is empty.
+	Remote string `json:"remote,omitempty" yaml:"remote,omitempty"`
+}
+
+func (g *FileGit) Verify() error {
+	if len(g.CommitterName) == 0 {
+		g.CommitterName = defaultGitCommitterName
+	}
+	if len(g.CommitterEmail) == 0 {
+		g.CommitterEmail = defaultGitCommitterEmail
+	}
+	if len(g.Branch) == 0 {
+		g.Branch = defaultGitBranch
+	}
+	if strings.Contains(g.Remote, "://") && !strings.HasPrefix(g.Remote, "file://") {
+		return fmt.Errorf("the git remote of the file database can only be a local path or a file:// URL")
+	}
 	return nil
 }
 
@@ -119,17 +179,103 @@ func (s *SQL) Verify() error {
 	return nil
 }
 
+type Postgres struct {
+	// TLS configuration. Only the files (ca_file, cert_file, key_file) are used when connecting to PostgreSQL.
+	TLSConfig *config.TLSConfig `json:"tls_config,omitempty" yaml:"tls_config,omitempty"`
+	// SSLMode is the level of protection used by the connection.
+	// Possible values are disable, require, verify-ca and verify-full. Default is disable.
+	SSLMode string `json:"ssl_mode,omitempty" yaml:"ssl_mode,omitempty"`
+	// Username
+	User config.Secret `json:"user,omitempty" yaml:"user,omitempty"`
+	// Password (requires User)
+	Password config.Secret `json:"password,omitempty" yaml:"password,omitempty"`
+	// PasswordFile is a path to a file that contains a password
+	PasswordFile string `json:"password_file,omitempty" yaml:"password_file,omitempty"`
+	// Network address in the form host:port
+	Addr config.Secret `json:"addr" yaml:"addr"`
+	// Database name
+	DBName string `json:"db_name" yaml:"db_name"`
+	// Schema is the name of the PostgreSQL schema that contains the tables. Default is public.
+	Schema string `json:"schema,omitempty" yaml:"schema,omitempty"`
+	// Dial timeout
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// ApplicationName is the name reported to the server. It is displayed in pg_stat_activity.
+	ApplicationName string `json:"application_name,omitempty" yaml:"application_name,omitempty"`
+}
+
+func (p *Postgres) Verify() error {
+	if len(p.Addr) == 0 {
+		return fmt.Errorf("addr must be specified")
+	}
+	if len(p.DBName) == 0 {
+		return fmt.Errorf("db_name must be specified")
+	}
+	if len(p.Schema) == 0 {
+		p.Schema = "public"
+	}
+	if len(p.SSLMode) == 0 {
+		p.SSLMode = "disable"
+	}
+	if p.SSLMode != "disable" && p.SSLMode != "require" && p.SSLMode != "verify-ca" && p.SSLMode != "verify-full" {
+		return fmt.Errorf("wrong ssl_mode %q. Possible values are disable, require, verify-ca and verify-full", p.SSLMode)
+	}
+	if (len(p.Password) > 0 || len(p.PasswordFile) > 0) && len(p.User) == 0 {
+		return fmt.Errorf("password or password_file cannot be filled if no user is provided")
+	}
+	if len(p.Password) > 0 && len(p.PasswordFile) > 0 {
+		return fmt.Errorf("password and password_file are mutually exclusive. Use one or the other not both at the same time")
+	}
+	if len(p.PasswordFile) > 0 {
+		// Read the file and load the password contained
+		data, err := os.ReadFile(p.PasswordFile)
+		if err != nil {
+			return err
+		}
+		p.Password = config.Secret(data)
+	}
+	return nil
+}
+
+type SQLite struct {
+	// Path is the path to the SQLite database file. It is created if it doesn't exist.
+	Path string `json:"path" yaml:"path"`
+	// BusyTimeout is the time a connection waits for a lock to be released before returning an error. Default is 5s.
+	BusyTimeout time.Duration `json:"busy_timeout,omitempty" yaml:"busy_timeout,omitempty"`
+}
+
+func (s *SQLite) Verify() error {
+	if len(s.Path) == 0 {
+		return fmt.Errorf("path must be specified when using SQLite as a database")
+	}
+	if s.BusyTimeout == 0 {
+		s.BusyTimeout = 5 * time.Second
+	}
+	return nil
+}
+
 type Database struct {
-	File *File `json:"file,omitempty" yaml:"file,omitempty"`
-	SQL  *SQL  `json:"sql,omitempty" yaml:"sql,omitempty"`
+	File     *File     `json:"file,omitempty" yaml:"file,omitempty"`
+	SQL      *SQL      `json:"sql,omitempty" yaml:"sql,omitempty"`
+	Postgres *Postgres `json:"postgres,omitempty" yaml:"postgres,omitempty"`
+	SQLite   *SQLite   `json:"sqlite,omitempty" yaml:"sqlite,omitempty"`
+	// Watch contains the configuration of the changes sent to the clients watching the resources
+	Watch Watch `json:"watch,omitempty" yaml:"watch,omitempty"`
+	// Cache contains the configuration of the cache of the resources read from the database
+	Cache Cache `json:"cache,omitempty" yaml:"cache,omitempty"`
 }
 
 func (d *Database) Verify() error {
-	if d.File == nil && d.SQL == nil {
-		return fmt.Errorf("you must specify if Perses has to use SQL or filesystem as a database")
+	numberOfDatabase := 0
+	for _, isSet := range []bool{d.File != nil, d.SQL != nil, d.Postgres != nil, d.SQLite != nil} {
+		if isSet {
+			numberOfDatabase++
+		}
+	}
+	if numberOfDatabase == 0 {
+		return fmt.Errorf("you must specify if Perses has to use SQL, PostgreSQL, SQLite or filesystem as a database")
 	}
-	if d.File != nil && d.SQL != nil {
-		return fmt.Errorf("you cannot tel to Perses to use SQL and the filesystem at the same time")
+	if numberOfDatabase > 1 {
+		return fmt.Errorf("you cannot tel to Perses to use more than one database at the same time")
 	}
 	return nil
 }
@@ -0,0 +1,38 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/prometheus/common/model"
+)
+
+// DashboardRevision is the retention policy of the dashboard revisions.
+// The revision corresponding to the current version of a dashboard is never removed.
+type DashboardRevision struct {
+	// MaxCount is the maximum number of revisions kept for each dashboard. The oldest ones are removed first.
+	// 0 means there is no limit.
+	MaxCount int `json:"max_count,omitempty" yaml:"max_count,omitempty"`
+	// MaxAge is the maximum age of a revision. Older revisions are removed each time the dashboard is saved.
+	// 0 means there is no limit.
+	MaxAge model.Duration `json:"max_age,omitempty" yaml:"max_age,omitempty"`
+}
+
+func (d *DashboardRevision) Verify() error {
+	if d.MaxCount < 0 {
+		return fmt.Errorf("dashboard_revision.max_count cannot be negative")
+	}
+	return nil
+}
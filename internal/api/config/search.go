@@ -0,0 +1,36 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+const defaultSearchRefreshInterval = model.Duration(10 * time.Minute)
+
+// Search contains the configuration of the full-text search of the dashboards and of the variables.
+type Search struct {
+	// RefreshInterval is the interval between two rebuilds of the search index.
+	// The index is updated by each write, so it only matters when several instances of Perses share the same database.
+	RefreshInterval model.Duration `json:"refresh_interval,omitempty" yaml:"refresh_interval,omitempty"`
+}
+
+func (s *Search) Verify() error {
+	if s.RefreshInterval <= 0 {
+		s.RefreshInterval = defaultSearchRefreshInterval
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+const defaultTrashRetention = model.Duration(30 * 24 * time.Hour)
+
+// Trash contains the configuration of the trash, where the dashboards and the projects are moved when they are deleted.
+type Trash struct {
+	// Disable makes the deletions final: the resources are removed immediately instead of being moved to the trash.
+	Disable bool `json:"disable,omitempty" yaml:"disable,omitempty"`
+	// Retention is how long a resource deleted is kept in the trash. Once it is over, the resource is removed for good.
+	Retention model.Duration `json:"retention,omitempty" yaml:"retention,omitempty"`
+}
+
+func (t *Trash) Verify() error {
+	if t.Retention < 0 {
+		return fmt.Errorf("trash.retention cannot be negative")
+	}
+	if t.Retention == 0 {
+		t.Retention = defaultTrashRetention
+	}
+	return nil
+}
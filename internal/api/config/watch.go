@@ -0,0 +1,45 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	defaultWatchPollInterval = model.Duration(time.Second)
+	defaultWatchRetention    = model.Duration(time.Hour)
+)
+
+// Watch contains the configuration of the changes sent to the clients watching the resources.
+// It is only used by the SQL databases, where the changes are recorded so every instance of Perses sharing the database can send them.
+type Watch struct {
+	// PollInterval is the interval between two reads of the changes recorded in the database.
+	// It is the maximum delay before a change is sent to the watchers.
+	PollInterval model.Duration `json:"poll_interval,omitempty" yaml:"poll_interval,omitempty"`
+	// Retention is how long a change is kept in the database.
+	Retention model.Duration `json:"retention,omitempty" yaml:"retention,omitempty"`
+}
+
+func (w *Watch) Verify() error {
+	if w.PollInterval <= 0 {
+		w.PollInterval = defaultWatchPollInterval
+	}
+	if w.Retention <= 0 {
+		w.Retention = defaultWatchRetention
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	defaultWebhookInterval  = model.Duration(10 * time.Second)
+	defaultWebhookTimeout   = model.Duration(10 * time.Second)
+	defaultWebhookRetention = model.Duration(7 * 24 * time.Hour)
+)
+
+// Webhook contains the configuration of the deliveries of the changes to the webhooks.
+type Webhook struct {
+	// Disable stops sending the changes to the webhooks. The webhooks can still be managed with the API.
+	Disable bool `json:"disable,omitempty" yaml:"disable,omitempty"`
+	// Interval is the interval between two checks of the deliveries waiting to be sent again.
+	Interval model.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+	// Timeout is how long to wait for the response of a webhook before considering the attempt failed.
+	Timeout model.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// Retention is how long a delivery is kept in the log once it has succeeded or failed.
+	Retention model.Duration `json:"retention,omitempty" yaml:"retention,omitempty"`
+	// AllowedHosts, when not empty, are the only hosts the events can be sent to. A host is a name, like hooks.example.com,
+	// a name starting with "*." for every subdomain, like *.example.com, or a CIDR, like 10.0.0.0/8 (a single IP is written like 10.0.0.1/32).
+	// A CIDR is checked with the IP the name of the URL resolves to.
+	AllowedHosts []string `json:"allowed_hosts,omitempty" yaml:"allowed_hosts,omitempty"`
+	// DeniedHosts are the hosts the events cannot be sent to, even when they are allowed. They are written like the allowed hosts.
+	DeniedHosts []string `json:"denied_hosts,omitempty" yaml:"denied_hosts,omitempty"`
+}
+
+func (w *Webhook) Verify() error {
+	if w.Interval < 0 || w.Timeout < 0 || w.Retention < 0 {
+		return fmt.Errorf("webhook.interval, webhook.timeout and webhook.retention cannot be negative")
+	}
+	if w.Interval == 0 {
+		w.Interval = defaultWebhookInterval
+	}
+	if w.Timeout == 0 {
+		w.Timeout = defaultWebhookTimeout
+	}
+	if w.Retention == 0 {
+		w.Retention = defaultWebhookRetention
+	}
+	for _, host := range append(append([]string{}, w.AllowedHosts...), w.DeniedHosts...) {
+		if err := verifyWebhookHost(host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func verifyWebhookHost(host string) error {
+	if strings.Contains(host, "/") {
+		if _, _, err := net.ParseCIDR(host); err != nil {
+			return fmt.Errorf("the webhook host %q is not a valid CIDR: %w", host, err)
+		}
+		return nil
+	}
+	if net.ParseIP(host) != nil {
+		return fmt.Errorf("the webhook host %q must be written as a CIDR, like 10.0.0.1/32", host)
+	}
+	name := strings.TrimPrefix(host, "*.")
+	if len(name) == 0 || strings.ContainsAny(name, "*:") {
+		return fmt.Errorf("the webhook host %q must be a name, a name starting with \"*.\" or a CIDR", host)
+	}
+	return nil
+}
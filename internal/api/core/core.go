@@ -16,14 +16,19 @@ package core
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/perses/common/app"
 	"github.com/perses/perses/internal/api/config"
 	"github.com/perses/perses/internal/api/core/middleware"
+	"github.com/perses/perses/internal/api/impl/v1/trash"
+	"github.com/perses/perses/internal/api/impl/v1/webhookdelivery"
 	"github.com/perses/perses/internal/api/shared/dependency"
 	"github.com/perses/perses/internal/api/shared/migrate"
+	"github.com/perses/perses/internal/api/shared/rbac"
 	"github.com/perses/perses/internal/api/shared/schemas"
+	"github.com/perses/perses/internal/api/shared/search"
 	"github.com/perses/perses/ui"
 	"github.com/sirupsen/logrus"
 )
@@ -56,25 +61,49 @@ func New(conf config.Config, banner string) (*app.Runner, dependency.Persistence
 	// enable hot reload of CUE schemas for dashboards validation:
 	// - watch for changes on the schemas folders
 	// - register a cron task to reload all the schemas every <interval>
-	watcher, reloader, err := schemas.NewHotReloaders(serviceManager.GetSchemas().GetLoaders())
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to instantiate the tasks for hot reload of schemas: %w", err)
-	}
+	watcher, reloader := schemas.NewHotReloaders(serviceManager.GetSchemas().GetLoaders())
 	// enable hot reload of the migration schemas
-	migrateWatcher, migrateReloader, err := migrate.NewHotReloaders(serviceManager.GetMigration())
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to instantiate the tasks for hot reload of migration schema: %w", err)
-	}
+	migrateWatcher, migrateReloader := migrate.NewHotReloaders(serviceManager.GetMigration())
 	runner.WithTasks(watcher, migrateWatcher)
 	runner.WithCronTasks(conf.Schemas.Interval, reloader, migrateReloader)
+	// rebuild the search index periodically, to get the changes made by the other instances sharing the database
+	runner.WithCronTasks(time.Duration(conf.Search.RefreshInterval), search.NewRefresher(serviceManager.GetSearchIndex()))
+	// remove for good the resources that have been in the trash for longer than the retention
+	runner.WithCronTasks(trash.ExpirationInterval, trash.NewExpirer(serviceManager.GetTrash()))
+	if !conf.Webhook.Disable {
+		// send again the deliveries that have failed, and the ones that an instance hasn't been able to send before stopping
+		runner.WithCronTasks(time.Duration(conf.Webhook.Interval), webhookdelivery.NewDeliverer(serviceManager.GetWebhookDelivery()))
+	}
+	if conf.Authorization.Enable {
+		// reload the permissions periodically, to get the roles and the bindings written by the other instances sharing the database
+		runner.WithCronTasks(time.Duration(conf.Authorization.RefreshInterval), rbac.NewRefresher(serviceManager.GetRBAC()))
+	}
 
 	// register the API
-	runner.HTTPServerBuilder().
+	builder := runner.HTTPServerBuilder()
+	if conf.Authentication.Enable {
+		// the authentication is checked first, so nothing is done for a request that is not authenticated, like proxying it.
+		builder.Middleware(middleware.CheckAuthentication(serviceManager.GetJWT(), serviceManager.GetAuthentication(), !conf.Authentication.DisableSignUp))
+	}
+	if conf.Authorization.Enable {
+		builder.Middleware(middleware.CheckAuthorization(serviceManager.GetRBAC()))
+	}
+	if conf.Audit.Enable {
+		// the audit comes after the authentication, to know who made the change, and before the proxy, to record the writes sent to the datasources.
+		builder.Middleware(middleware.Audit(serviceManager.GetAudit()))
+	}
+	if !conf.Webhook.Disable {
+		// the changes are sent to the webhooks once the authentication is checked, to know who made them.
+		builder.Middleware(middleware.Webhook(serviceManager.GetWebhookDelivery()))
+	}
+	builder.
 		APIRegistration(persesAPI).
 		APIRegistration(persesFrontend).
 		GzipSkipper(func(c echo.Context) bool {
 			// let's skip the gzip compression when using the proxy and rely on the datasource behind.
-			return strings.HasPrefix(c.Request().URL.Path, "/proxy")
+			// The watches are skipped as well, as the compression would delay the events until its buffer is full.
+			// The backups are already compressed.
+			return strings.HasPrefix(c.Request().URL.Path, "/proxy") || c.QueryParam("watch") == "true" || c.Request().URL.Path == "/api/admin/export"
 		}).
 		Middleware(proxyMiddleware.Proxy()).
 		Middleware(middleware.HandleError()).
@@ -0,0 +1,145 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/interface/v1/audit"
+	"github.com/perses/perses/internal/api/shared"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// Audit is a middleware recording the changes made by the requests with the service.
+// The changes made with the API are recorded in detail by the endpoints, like shared.Toolbox. This middleware records the requests
+// modifying a datasource sent through the proxy, whatever their outcome, as a request failing may still have modified the datasource.
+// It also records any other request succeeding in modifying the resources without recording its changes, so none of them is missed.
+// It must be registered after CheckAuthentication, so the records contain who sent the requests, and before the proxy.
+func Audit(service audit.Service) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			shared.SetAuditor(c, service.Record)
+			err := next(c)
+			if !isWriteMethod(c.Request().Method) {
+				return err
+			}
+			statusCode := responseStatus(c, err)
+			if record := newProxyAuditRecord(c, statusCode); record != nil {
+				service.Record(record)
+			} else if statusCode < http.StatusBadRequest && !shared.IsRecorded(c) {
+				if record := newRequestAuditRecord(c, statusCode); record != nil {
+					service.Record(record)
+				}
+			}
+			return err
+		}
+	}
+}
+
+// responseStatus returns the status code of the response. When the request failed, the response is only written once the error
+// is returned to echo, so the status code is the one of the error.
+func responseStatus(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+	var httpErr *echo.HTTPError
+	if errors.As(shared.HandleError(err), &httpErr) {
+		return httpErr.Code
+	}
+	return http.StatusInternalServerError
+}
+
+func isWriteMethod(method string) bool {
+	return method != http.MethodGet && method != http.MethodHead && method != http.MethodOptions
+}
+
+// newProxyAuditRecord returns the record of the request sent to a datasource. It returns nil if the request is not sent through the proxy.
+func newProxyAuditRecord(c echo.Context, statusCode int) *v1.AuditRecord {
+	requestPath := c.Request().URL.Path
+	proxyRequest := &v1.AuditProxyRequest{
+		Method:     c.Request().Method,
+		StatusCode: statusCode,
+	}
+	var resource v1.AuditResource
+	var err error
+	// the patterns are checked in the same order as the proxy does.
+	switch {
+	case globalProxyMatcher.MatchString(requestPath):
+		resource.Kind = v1.KindGlobalDatasource
+		resource.Name, proxyRequest.Path, err = extractGlobalDatasourceAndPath(requestPath)
+	case projectProxyMatcher.MatchString(requestPath):
+		resource.Kind = v1.KindDatasource
+		resource.Project, resource.Name, proxyRequest.Path, err = extractProjectDatasourceAndPath(requestPath)
+	case dashboardProxyMatcher.MatchString(requestPath):
+		// the datasource is part of the dashboard.
+		resource.Kind = v1.KindDashboard
+		resource.Project, resource.Name, proxyRequest.Datasource, proxyRequest.Path, err = extractProjectDashboardDatasourceAndPath(requestPath)
+	default:
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+	record := shared.NewAuditRecord(c, resource)
+	record.Spec.Proxy = proxyRequest
+	return record
+}
+
+// newRequestAuditRecord returns the record of a request modifying the resources, without the detail of its changes.
+// The resource is the one found out from the path of the request. It returns nil if the request doesn't modify the resources,
+// like a login or a validation.
+func newRequestAuditRecord(c echo.Context, statusCode int) *v1.AuditRecord {
+	requestPath := c.Request().URL.Path
+	if !strings.HasPrefix(requestPath, fmt.Sprintf("%s/", shared.APIV1Prefix)) && !strings.HasPrefix(requestPath, "/api/admin/") {
+		return nil
+	}
+	record := shared.NewAuditRecord(c, getPathResource(requestPath))
+	record.Spec.Request = &v1.AuditRequest{
+		Method:     c.Request().Method,
+		Path:       requestPath,
+		StatusCode: statusCode,
+	}
+	return record
+}
+
+// getPathResource returns the resource served under the path of the API. It is empty if the path doesn't identify a resource.
+func getPathResource(requestPath string) v1.AuditResource {
+	apiPath, found := strings.CutPrefix(requestPath, fmt.Sprintf("%s/", shared.APIV1Prefix))
+	if !found {
+		return v1.AuditResource{}
+	}
+	segments := strings.Split(strings.Trim(apiPath, "/"), "/")
+	resource := v1.AuditResource{}
+	if segments[0] == shared.PathProject && len(segments) > 2 {
+		// a resource belonging to a project: /projects/:project/<kind>/...
+		resource.Project = segments[1]
+		segments = segments[2:]
+	}
+	kind, exists := pathKinds[segments[0]]
+	if !exists {
+		return v1.AuditResource{}
+	}
+	resource.Kind = kind
+	if len(segments) > 1 {
+		resource.Name = segments[1]
+	}
+	if kind == v1.KindProject {
+		resource.Project = resource.Name
+	}
+	return resource
+}
@@ -0,0 +1,203 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/interface/v1/audit"
+	"github.com/perses/perses/internal/api/shared"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAudit struct {
+	audit.Service
+	records []*v1.AuditRecord
+}
+
+func (f *fakeAudit) Record(record *v1.AuditRecord) {
+	f.records = append(f.records, record)
+}
+
+func TestAudit(t *testing.T) {
+	testSuite := []struct {
+		title      string
+		method     string
+		path       string
+		statusCode int
+		// recorded tells the handler records the changes of the request itself.
+		recorded bool
+		expected []v1.AuditRecordSpec
+	}{
+		{
+			title:      "write sent to a datasource of a project",
+			method:     http.MethodPost,
+			path:       "/proxy/projects/perses/datasources/prom/api/v1/admin/tsdb/delete_series",
+			statusCode: http.StatusNoContent,
+			expected: []v1.AuditRecordSpec{
+				{
+					Actor:    &v1.Subject{Kind: v1.SubjectKindUser, Name: "john"},
+					SourceIP: "192.0.2.1",
+					Resource: v1.AuditResource{Kind: v1.KindDatasource, Project: "perses", Name: "prom"},
+					Proxy: &v1.AuditProxyRequest{
+						Method:     http.MethodPost,
+						Path:       "/api/v1/admin/tsdb/delete_series",
+						StatusCode: http.StatusNoContent,
+					},
+				},
+			},
+		},
+		{
+			title:      "write sent to a datasource of a dashboard",
+			method:     http.MethodPut,
+			path:       "/proxy/projects/perses/dashboards/demo/datasources/prom/api/v1/write",
+			statusCode: http.StatusOK,
+			expected: []v1.AuditRecordSpec{
+				{
+					Actor:    &v1.Subject{Kind: v1.SubjectKindUser, Name: "john"},
+					SourceIP: "192.0.2.1",
+					Resource: v1.AuditResource{Kind: v1.KindDashboard, Project: "perses", Name: "demo"},
+					Proxy: &v1.AuditProxyRequest{
+						Datasource: "prom",
+						Method:     http.MethodPut,
+						Path:       "/api/v1/write",
+						StatusCode: http.StatusOK,
+					},
+				},
+			},
+		},
+		{
+			title:      "read sent to a global datasource",
+			method:     http.MethodGet,
+			path:       "/proxy/globaldatasources/prom/api/v1/query",
+			statusCode: http.StatusOK,
+			expected:   nil,
+		},
+		{
+			title:      "write failing",
+			method:     http.MethodDelete,
+			path:       "/proxy/globaldatasources/prom/api/v1/series",
+			statusCode: http.StatusBadGateway,
+			expected: []v1.AuditRecordSpec{
+				{
+					Actor:    &v1.Subject{Kind: v1.SubjectKindUser, Name: "john"},
+					SourceIP: "192.0.2.1",
+					Resource: v1.AuditResource{Kind: v1.KindGlobalDatasource, Name: "prom"},
+					Proxy: &v1.AuditProxyRequest{
+						Method:     http.MethodDelete,
+						Path:       "/api/v1/series",
+						StatusCode: http.StatusBadGateway,
+					},
+				},
+			},
+		},
+		{
+			title:      "request recording its changes",
+			method:     http.MethodPost,
+			path:       "/api/v1/projects",
+			statusCode: http.StatusOK,
+			recorded:   true,
+			expected:   nil,
+		},
+		{
+			title:      "request not recording its changes",
+			method:     http.MethodPost,
+			path:       "/api/v1/projects/perses/dashboards/demo/revisions/2/restore",
+			statusCode: http.StatusOK,
+			expected: []v1.AuditRecordSpec{
+				{
+					Actor:    &v1.Subject{Kind: v1.SubjectKindUser, Name: "john"},
+					SourceIP: "192.0.2.1",
+					Resource: v1.AuditResource{Kind: v1.KindDashboard, Project: "perses", Name: "demo"},
+					Request: &v1.AuditRequest{
+						Method:     http.MethodPost,
+						Path:       "/api/v1/projects/perses/dashboards/demo/revisions/2/restore",
+						StatusCode: http.StatusOK,
+					},
+				},
+			},
+		},
+		{
+			title:      "request not recording its changes and not about a resource",
+			method:     http.MethodPost,
+			path:       "/api/admin/import",
+			statusCode: http.StatusOK,
+			expected: []v1.AuditRecordSpec{
+				{
+					Actor:    &v1.Subject{Kind: v1.SubjectKindUser, Name: "john"},
+					SourceIP: "192.0.2.1",
+					Request: &v1.AuditRequest{
+						Method:     http.MethodPost,
+						Path:       "/api/admin/import",
+						StatusCode: http.StatusOK,
+					},
+				},
+			},
+		},
+		{
+			title:      "request failing",
+			method:     http.MethodDelete,
+			path:       "/api/v1/projects/perses",
+			statusCode: http.StatusNotFound,
+			expected:   nil,
+		},
+		{
+			title:      "request not modifying the resources",
+			method:     http.MethodPost,
+			path:       "/api/auth/providers/native/login",
+			statusCode: http.StatusOK,
+			expected:   nil,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			service := &fakeAudit{}
+			e := echo.New()
+			e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					shared.SetUsername(c, "john")
+					return next(c)
+				}
+			})
+			e.Use(Audit(service))
+			e.Any("/*", func(c echo.Context) error {
+				if !shared.IsAudited(c) {
+					t.Error("the auditor is not set in the context")
+				}
+				if test.recorded {
+					shared.MarkRecorded(c)
+				}
+				if test.statusCode >= http.StatusBadRequest {
+					return echo.NewHTTPError(test.statusCode)
+				}
+				return c.NoContent(test.statusCode)
+			})
+			req := httptest.NewRequest(test.method, test.path, nil)
+			e.ServeHTTP(httptest.NewRecorder(), req)
+			var specs []v1.AuditRecordSpec
+			for _, record := range service.records {
+				assert.False(t, record.Spec.Time.IsZero())
+				spec := record.Spec
+				spec.Time = time.Time{}
+				specs = append(specs, spec)
+			}
+			assert.Equal(t, test.expected, specs)
+		})
+	}
+}
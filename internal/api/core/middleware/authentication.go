@@ -0,0 +1,77 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/authentication"
+	"github.com/perses/perses/internal/api/shared/crypto"
+	"github.com/perses/perses/internal/api/shared/openapi"
+)
+
+// CheckAuthentication is a middleware rejecting the requests to the API and to the proxy that don't have a valid access token
+// in the header Authorization. The login of the user is then available with shared.GetUsername, and their groups with shared.GetGroups.
+// The header can contain an API token instead, and the service account owning it is then available with shared.GetServiceAccount.
+// The requests to log in, to refresh a token, to check the health of the server and to get the OpenAPI document don't have to be authenticated,
+// neither do the creations of a user when the sign-up is enabled.
+func CheckAuthentication(jwt crypto.JWT, authenticationService authentication.Authentication, enableSignUp bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !isAuthenticationRequired(c.Request(), enableSignUp) {
+				return next(c)
+			}
+			token, found := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !found || len(token) == 0 {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing access token")
+			}
+			if crypto.IsAPIToken(token) {
+				serviceAccount, apiToken, err := authenticationService.AuthenticateAPIToken(token)
+				if err != nil {
+					// this middleware runs before HandleError
+					return shared.HandleError(err)
+				}
+				shared.SetServiceAccount(c, serviceAccount.Metadata.Name, apiToken.Permissions)
+				return next(c)
+			}
+			claims, err := jwt.ValidateAccessToken(token)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, fmt.Sprintf("invalid access token: %s", err))
+			}
+			shared.SetUsername(c, claims.Subject)
+			shared.SetGroups(c, claims.Groups)
+			return next(c)
+		}
+	}
+}
+
+func isAuthenticationRequired(r *http.Request, enableSignUp bool) bool {
+	p := r.URL.Path
+	if strings.Contains(p, "/proxy/") {
+		// the proxy serves the paths matching its patterns anywhere in the URL.
+		return true
+	}
+	if !strings.HasPrefix(p, "/api/") {
+		// the UI is public, it is the API that is protected.
+		return false
+	}
+	if strings.HasPrefix(p, "/api/auth/") || p == fmt.Sprintf("%s/health", shared.APIV1Prefix) || p == "/api"+openapi.PathOpenAPI {
+		return false
+	}
+	return !enableSignUp || r.Method != http.MethodPost || p != fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathUser)
+}
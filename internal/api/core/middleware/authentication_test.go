@@ -0,0 +1,193 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/config"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/authentication"
+	"github.com/perses/perses/internal/api/shared/crypto"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAuthenticationRequired(t *testing.T) {
+	testSuite := []struct {
+		title        string
+		method       string
+		path         string
+		enableSignUp bool
+		expected     bool
+	}{
+		{
+			title:    "UI",
+			method:   http.MethodGet,
+			path:     "/projects/perses",
+			expected: false,
+		},
+		{
+			title:    "login",
+			method:   http.MethodPost,
+			path:     "/api/auth/login",
+			expected: false,
+		},
+		{
+			title:    "health",
+			method:   http.MethodGet,
+			path:     "/api/v1/health",
+			expected: false,
+		},
+		{
+			title:    "OpenAPI document",
+			method:   http.MethodGet,
+			path:     "/api/openapi.json",
+			expected: false,
+		},
+		{
+			title:    "list of the projects",
+			method:   http.MethodGet,
+			path:     "/api/v1/projects",
+			expected: true,
+		},
+		{
+			title:    "proxy",
+			method:   http.MethodGet,
+			path:     "/proxy/globaldatasources/prometheus/api/v1/query",
+			expected: true,
+		},
+		{
+			title:    "proxy hidden behind a public path",
+			method:   http.MethodGet,
+			path:     "/api/auth/proxy/globaldatasources/prometheus/api/v1/query",
+			expected: true,
+		},
+		{
+			title:        "sign-up enabled",
+			method:       http.MethodPost,
+			path:         "/api/v1/users",
+			enableSignUp: true,
+			expected:     false,
+		},
+		{
+			title:    "sign-up disabled",
+			method:   http.MethodPost,
+			path:     "/api/v1/users",
+			expected: true,
+		},
+		{
+			title:        "list of the users with sign-up enabled",
+			method:       http.MethodGet,
+			path:         "/api/v1/users",
+			enableSignUp: true,
+			expected:     true,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			assert.Equal(t, test.expected, isAuthenticationRequired(httptest.NewRequest(test.method, test.path, nil), test.enableSignUp))
+		})
+	}
+}
+
+type fakeAuthentication struct {
+	authentication.Authentication
+	// apiToken is the only API token accepted, owned by the service account ci.
+	apiToken string
+}
+
+func (f *fakeAuthentication) AuthenticateAPIToken(token string) (*v1.ServiceAccount, *v1.APIToken, error) {
+	if token != f.apiToken {
+		return nil, nil, shared.HandleUnauthorizedError("invalid API token")
+	}
+	return &v1.ServiceAccount{Metadata: v1.Metadata{Name: "ci"}}, &v1.APIToken{Name: "deploy"}, nil
+}
+
+func TestCheckAuthentication(t *testing.T) {
+	jwt, err := crypto.NewJWT(hex.EncodeToString([]byte("=tW$56zytgB&3jN2E%7-+qrGZE?v6LCc")), config.Authentication{
+		AccessTokenTTL:  model.Duration(time.Minute),
+		RefreshTokenTTL: model.Duration(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	accessToken, _ := jwt.SignedAccessToken("john", nil)
+	refreshToken, _ := jwt.SignedRefreshToken("john", nil)
+	apiToken, _, _ := crypto.NewAPIToken("ci", "deploy")
+	authenticationService := &fakeAuthentication{apiToken: apiToken}
+	testSuite := []struct {
+		title         string
+		authorization string
+		expectedCode  int
+		expectedBody  string
+	}{
+		{
+			title:        "no token",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			title:         "not a bearer token",
+			authorization: "Basic am9objpzZWNyZXQ=",
+			expectedCode:  http.StatusUnauthorized,
+		},
+		{
+			title:         "refresh token",
+			authorization: "Bearer " + refreshToken,
+			expectedCode:  http.StatusUnauthorized,
+		},
+		{
+			title:         "access token",
+			authorization: "Bearer " + accessToken,
+			expectedCode:  http.StatusOK,
+			expectedBody:  "john",
+		},
+		{
+			title:         "API token",
+			authorization: "Bearer " + apiToken,
+			expectedCode:  http.StatusOK,
+			expectedBody:  "ci",
+		},
+		{
+			title:         "invalid API token",
+			authorization: "Bearer " + crypto.APITokenPrefix + "ci.deploy.secret",
+			expectedCode:  http.StatusUnauthorized,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			e := echo.New()
+			e.Use(CheckAuthentication(jwt, authenticationService, true))
+			e.GET("/api/v1/projects", func(c echo.Context) error {
+				return c.String(http.StatusOK, shared.GetUsername(c)+shared.GetServiceAccount(c))
+			})
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/projects", nil)
+			if len(test.authorization) > 0 {
+				req.Header.Set(echo.HeaderAuthorization, test.authorization)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, test.expectedCode, rec.Code)
+			if test.expectedCode == http.StatusOK {
+				assert.Equal(t, test.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
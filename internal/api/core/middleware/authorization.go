@@ -0,0 +1,209 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/rbac"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// pathKinds gives the kind of the resources served under each path of the API.
+var pathKinds = map[string]v1.Kind{
+	shared.PathAudit:             v1.KindAuditRecord,
+	shared.PathDashboard:         v1.KindDashboard,
+	shared.PathDatasource:        v1.KindDatasource,
+	shared.PathFolder:            v1.KindFolder,
+	shared.PathGlobalDatasource:  v1.KindGlobalDatasource,
+	shared.PathGlobalRole:        v1.KindGlobalRole,
+	shared.PathGlobalRoleBinding: v1.KindGlobalRoleBinding,
+	shared.PathGlobalSecret:      v1.KindGlobalSecret,
+	shared.PathGlobalVariable:    v1.KindGlobalVariable,
+	shared.PathGlobalWebhook:     v1.KindGlobalWebhook,
+	shared.PathProject:           v1.KindProject,
+	shared.PathRole:              v1.KindRole,
+	shared.PathRoleBinding:       v1.KindRoleBinding,
+	shared.PathSecret:            v1.KindSecret,
+	shared.PathServiceAccount:    v1.KindServiceAccount,
+	shared.PathTrash:             v1.KindTrashEntry,
+	shared.PathUser:              v1.KindUser,
+	shared.PathVariable:          v1.KindVariable,
+	shared.PathWebhook:           v1.KindWebhook,
+}
+
+var methodActions = map[string]v1.Action{
+	http.MethodGet:    v1.ActionRead,
+	http.MethodPost:   v1.ActionCreate,
+	http.MethodPut:    v1.ActionUpdate,
+	http.MethodPatch:  v1.ActionUpdate,
+	http.MethodDelete: v1.ActionDelete,
+}
+
+// requiredPermission is what a request needs to be allowed. The project is empty for the global resources.
+type requiredPermission struct {
+	action  v1.Action
+	project string
+	scope   v1.Scope
+}
+
+func (p *requiredPermission) String() string {
+	if p.action == v1.ActionWildcard && p.scope == v1.ScopeWildcard {
+		return "manage every resource"
+	}
+	if p.scope == v1.ScopeWildcard {
+		return fmt.Sprintf("%s every resource", p.action)
+	}
+	if len(p.project) == 0 {
+		return fmt.Sprintf("%s the resources of the kind %s", p.action, p.scope)
+	}
+	return fmt.Sprintf("%s the resources of the kind %s in the project %q", p.action, p.scope, p.project)
+}
+
+// CheckAuthorization is a middleware rejecting the requests the authenticated user is not allowed to send by the roles bound to them.
+// The requests sent with an API token are checked against the roles bound to its service account, and against the permissions of the token.
+// It must be registered after CheckAuthentication, and before the proxy so the datasources are protected too.
+// It also stores in the context what the user is allowed to do, so the lists and the search only return the resources the user can read.
+func CheckAuthorization(r rbac.RBAC) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			subjects := shared.GetSubjects(c)
+			if len(subjects) == 0 {
+				// the request doesn't have to be authenticated, like a login, so there is nothing to check.
+				return next(c)
+			}
+			tokenPermissions := shared.GetTokenPermissions(c)
+			hasPermission := func(action v1.Action, project string, scope v1.Scope) bool {
+				return isAllowedByToken(tokenPermissions, action, scope) && r.HasPermission(subjects, action, project, scope)
+			}
+			shared.SetPermission(c, func(action v1.Action, kind v1.Kind, project string) bool {
+				return hasPermission(action, project, v1.Scope(kind))
+			})
+			permission, err := getRequiredPermission(c, shared.GetUsername(c))
+			if err != nil {
+				// this middleware runs before HandleError
+				return shared.HandleError(err)
+			}
+			if permission != nil && !hasPermission(permission.action, permission.project, permission.scope) {
+				return shared.HandleError(shared.HandleForbiddenError(fmt.Sprintf("the %s is not allowed to %s", describeSubject(subjects[0]), permission)))
+			}
+			return next(c)
+		}
+	}
+}
+
+// isAllowedByToken tells whether the permissions of an API token allow the action. A token without permissions allows everything
+// its service account is allowed to do.
+func isAllowedByToken(tokenPermissions []v1.Permission, action v1.Action, scope v1.Scope) bool {
+	if len(tokenPermissions) == 0 {
+		return true
+	}
+	for _, permission := range tokenPermissions {
+		if permission.Allows(action, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func describeSubject(subject v1.Subject) string {
+	if subject.Kind == v1.SubjectKindServiceAccount {
+		return fmt.Sprintf("service account %q", subject.Name)
+	}
+	return fmt.Sprintf("user %q", subject.Name)
+}
+
+// getRequiredPermission returns the permission needed by the request. It returns nil if every authenticated user can send it.
+// The login is empty when the request is sent by a service account.
+func getRequiredPermission(c echo.Context, login string) (*requiredPermission, error) {
+	requestPath := c.Request().URL.Path
+	// the patterns are checked in the same order as the proxy does.
+	if globalProxyMatcher.MatchString(requestPath) {
+		return &requiredPermission{action: v1.ActionRead, scope: v1.Scope(v1.KindGlobalDatasource)}, nil
+	}
+	if projectProxyMatcher.MatchString(requestPath) {
+		projectName, _, _, err := extractProjectDatasourceAndPath(requestPath)
+		if err != nil {
+			return nil, err
+		}
+		return &requiredPermission{action: v1.ActionRead, project: projectName, scope: v1.Scope(v1.KindDatasource)}, nil
+	}
+	if dashboardProxyMatcher.MatchString(requestPath) {
+		// the datasources of a dashboard are part of the dashboard.
+		projectName, _, _, _, err := extractProjectDashboardDatasourceAndPath(requestPath)
+		if err != nil {
+			return nil, err
+		}
+		return &requiredPermission{action: v1.ActionRead, project: projectName, scope: v1.Scope(v1.KindDashboard)}, nil
+	}
+	if strings.HasPrefix(requestPath, "/api/admin/") {
+		// the export and the import of a backup contain every resource.
+		return &requiredPermission{action: v1.ActionWildcard, scope: v1.ScopeWildcard}, nil
+	}
+	apiPath, found := strings.CutPrefix(c.Path(), fmt.Sprintf("%s/", shared.APIV1Prefix))
+	if !found {
+		// the configuration, the validation and the migration don't give access to any resource.
+		return nil, nil
+	}
+	segments := strings.Split(apiPath, "/")
+	projectName := ""
+	if segments[0] == shared.PathProject && len(segments) > 2 {
+		// a resource belonging to a project: /projects/:project/<kind>/...
+		projectName = shared.GetProjectParameter(c)
+		segments = segments[2:]
+	}
+	kind, exists := pathKinds[segments[0]]
+	if !exists {
+		// the search only returns what the user can read.
+		return nil, nil
+	}
+	name := shared.GetNameParameter(c)
+	method := c.Request().Method
+	action, exists := methodActions[method]
+	if !exists {
+		action = v1.ActionWildcard
+	}
+	if len(segments) > 2 && method != http.MethodGet {
+		// an action on the resource, like the restoration of a revision of a dashboard or of an entry of the trash,
+		// the creation and the revocation of the API tokens of a service account, or the replay of a delivery of a webhook.
+		action = v1.ActionUpdate
+	}
+	switch {
+	case kind == v1.KindProject:
+		// the permissions on a project are given in the project itself. Only a global permission allows to create a project.
+		projectName = name
+	case kind == v1.KindUser && len(login) > 0 && name == login && (action == v1.ActionRead || action == v1.ActionUpdate):
+		// every user can read their own user and change their password.
+		return nil, nil
+	case len(projectName) == 0 && isProjectResourceCreation(c):
+		var err error
+		if projectName, err = getBodyProject(c); err != nil {
+			return nil, err
+		}
+	}
+	if v1.InternalKindMap[kind] {
+		// the trash and the audit log contain the resources of every kind, and they are not filtered.
+		// The internal kinds cannot be the scope of a permission, so they require the wildcard scope.
+		return &requiredPermission{action: action, scope: v1.ScopeWildcard}, nil
+	}
+	if action == v1.ActionRead && len(name) == 0 {
+		// the lists are filtered, so they only contain what the user can read.
+		return nil, nil
+	}
+	return &requiredPermission{action: action, project: projectName, scope: v1.Scope(kind)}, nil
+}
@@ -0,0 +1,381 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/rbac"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRequiredPermission(t *testing.T) {
+	testSuite := []struct {
+		title    string
+		method   string
+		route    string
+		path     string
+		body     string
+		expected *requiredPermission
+	}{
+		{
+			title:  "get a dashboard",
+			method: http.MethodGet,
+			route:  "/api/v1/projects/:project/dashboards/:name",
+			path:   "/api/v1/projects/perses/dashboards/demo",
+			expected: &requiredPermission{
+				action:  v1.ActionRead,
+				project: "perses",
+				scope:   v1.Scope(v1.KindDashboard),
+			},
+		},
+		{
+			title:    "list the dashboards of a project",
+			method:   http.MethodGet,
+			route:    "/api/v1/projects/:project/dashboards",
+			path:     "/api/v1/projects/perses/dashboards",
+			expected: nil,
+		},
+		{
+			title:  "create a datasource in a project",
+			method: http.MethodPost,
+			route:  "/api/v1/projects/:project/datasources",
+			path:   "/api/v1/projects/perses/datasources",
+			expected: &requiredPermission{
+				action:  v1.ActionCreate,
+				project: "perses",
+				scope:   v1.Scope(v1.KindDatasource),
+			},
+		},
+		{
+			title:  "create a variable from the root endpoint",
+			method: http.MethodPost,
+			route:  "/api/v1/variables",
+			path:   "/api/v1/variables",
+			body:   `{"kind":"Variable","metadata":{"name":"job","project":"perses"}}`,
+			expected: &requiredPermission{
+				action:  v1.ActionCreate,
+				project: "perses",
+				scope:   v1.Scope(v1.KindVariable),
+			},
+		},
+		{
+			title:  "restore a revision of a dashboard",
+			method: http.MethodPost,
+			route:  "/api/v1/projects/:project/dashboards/:name/revisions/:version/restore",
+			path:   "/api/v1/projects/perses/dashboards/demo/revisions/2/restore",
+			expected: &requiredPermission{
+				action:  v1.ActionUpdate,
+				project: "perses",
+				scope:   v1.Scope(v1.KindDashboard),
+			},
+		},
+		{
+			title:  "revoke an API token of a service account",
+			method: http.MethodDelete,
+			route:  "/api/v1/serviceaccounts/:name/tokens/:token",
+			path:   "/api/v1/serviceaccounts/ci/tokens/deploy",
+			expected: &requiredPermission{
+				action: v1.ActionUpdate,
+				scope:  v1.Scope(v1.KindServiceAccount),
+			},
+		},
+		{
+			title:  "create a project",
+			method: http.MethodPost,
+			route:  "/api/v1/projects",
+			path:   "/api/v1/projects",
+			expected: &requiredPermission{
+				action: v1.ActionCreate,
+				scope:  v1.Scope(v1.KindProject),
+			},
+		},
+		{
+			title:  "delete a project",
+			method: http.MethodDelete,
+			route:  "/api/v1/projects/:name",
+			path:   "/api/v1/projects/perses",
+			expected: &requiredPermission{
+				action:  v1.ActionDelete,
+				project: "perses",
+				scope:   v1.Scope(v1.KindProject),
+			},
+		},
+		{
+			title:  "update a global role",
+			method: http.MethodPut,
+			route:  "/api/v1/globalroles/:name",
+			path:   "/api/v1/globalroles/viewer",
+			expected: &requiredPermission{
+				action: v1.ActionUpdate,
+				scope:  v1.Scope(v1.KindGlobalRole),
+			},
+		},
+		{
+			title:  "patch a dashboard",
+			method: http.MethodPatch,
+			route:  "/api/v1/projects/:project/dashboards/:name",
+			path:   "/api/v1/projects/perses/dashboards/demo",
+			expected: &requiredPermission{
+				action:  v1.ActionUpdate,
+				project: "perses",
+				scope:   v1.Scope(v1.KindDashboard),
+			},
+		},
+		{
+			title:    "update its own user",
+			method:   http.MethodPut,
+			route:    "/api/v1/users/:name",
+			path:     "/api/v1/users/john",
+			expected: nil,
+		},
+		{
+			title:  "delete its own user",
+			method: http.MethodDelete,
+			route:  "/api/v1/users/:name",
+			path:   "/api/v1/users/john",
+			expected: &requiredPermission{
+				action: v1.ActionDelete,
+				scope:  v1.Scope(v1.KindUser),
+			},
+		},
+		{
+			title:  "list the trash",
+			method: http.MethodGet,
+			route:  "/api/v1/trash",
+			path:   "/api/v1/trash",
+			expected: &requiredPermission{
+				action: v1.ActionRead,
+				scope:  v1.ScopeWildcard,
+			},
+		},
+		{
+			title:  "list the audit log",
+			method: http.MethodGet,
+			route:  "/api/v1/audit",
+			path:   "/api/v1/audit?kind=Dashboard",
+			expected: &requiredPermission{
+				action: v1.ActionRead,
+				scope:  v1.ScopeWildcard,
+			},
+		},
+		{
+			title:    "search",
+			method:   http.MethodGet,
+			route:    "/api/v1/search",
+			path:     "/api/v1/search",
+			expected: nil,
+		},
+		{
+			// the permissions of each resource applied are checked by the endpoint.
+			title:    "apply",
+			method:   http.MethodPost,
+			route:    "/api/v1/apply",
+			path:     "/api/v1/apply?dryRun=true",
+			expected: nil,
+		},
+		{
+			title:  "export a backup",
+			method: http.MethodGet,
+			route:  "/api/admin/export",
+			path:   "/api/admin/export",
+			expected: &requiredPermission{
+				action: v1.ActionWildcard,
+				scope:  v1.ScopeWildcard,
+			},
+		},
+		{
+			title:  "proxy of a global datasource",
+			method: http.MethodPost,
+			route:  "/*",
+			path:   "/proxy/globaldatasources/prometheus/api/v1/query",
+			expected: &requiredPermission{
+				action: v1.ActionRead,
+				scope:  v1.Scope(v1.KindGlobalDatasource),
+			},
+		},
+		{
+			title:  "proxy of a datasource of a project",
+			method: http.MethodGet,
+			route:  "/*",
+			path:   "/proxy/projects/perses/datasources/prometheus/api/v1/query",
+			expected: &requiredPermission{
+				action:  v1.ActionRead,
+				project: "perses",
+				scope:   v1.Scope(v1.KindDatasource),
+			},
+		},
+		{
+			title:  "proxy of a datasource of a dashboard",
+			method: http.MethodGet,
+			route:  "/*",
+			path:   "/proxy/projects/perses/dashboards/demo/datasources/prometheus/api/v1/query",
+			expected: &requiredPermission{
+				action:  v1.ActionRead,
+				project: "perses",
+				scope:   v1.Scope(v1.KindDashboard),
+			},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			e := echo.New()
+			var result *requiredPermission
+			e.Add(test.method, test.route, func(c echo.Context) error {
+				var err error
+				result, err = getRequiredPermission(c, "john")
+				return err
+			})
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+type fakeRBAC struct {
+	rbac.RBAC
+	// permissions are the actions allowed by project and by scope.
+	permissions map[string]map[v1.Scope]v1.Action
+}
+
+func (f *fakeRBAC) HasPermission(_ []v1.Subject, action v1.Action, project string, scope v1.Scope) bool {
+	allowed, exists := f.permissions[project][scope]
+	return exists && (allowed == v1.ActionWildcard || allowed == action)
+}
+
+func TestCheckAuthorization(t *testing.T) {
+	r := &fakeRBAC{permissions: map[string]map[v1.Scope]v1.Action{
+		"perses": {v1.Scope(v1.KindDashboard): v1.ActionWildcard},
+	}}
+	testSuite := []struct {
+		title            string
+		method           string
+		path             string
+		username         string
+		serviceAccount   string
+		tokenPermissions []v1.Permission
+		expectedCode     int
+		expectedBody     string
+	}{
+		{
+			title:        "allowed",
+			method:       http.MethodGet,
+			path:         "/api/v1/projects/perses/dashboards/demo",
+			username:     "john",
+			expectedCode: http.StatusOK,
+		},
+		{
+			title:        "action not allowed",
+			method:       http.MethodDelete,
+			path:         "/api/v1/projects/perses/variables/demo",
+			username:     "john",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			title:          "service account",
+			method:         http.MethodDelete,
+			path:           "/api/v1/projects/perses/dashboards/demo",
+			serviceAccount: "ci",
+			expectedCode:   http.StatusOK,
+		},
+		{
+			title:            "action not allowed by the API token",
+			method:           http.MethodDelete,
+			path:             "/api/v1/projects/perses/dashboards/demo",
+			serviceAccount:   "ci",
+			tokenPermissions: []v1.Permission{{Actions: []v1.Action{v1.ActionRead}, Scopes: []v1.Scope{v1.ScopeWildcard}}},
+			expectedCode:     http.StatusForbidden,
+		},
+		{
+			title:            "action allowed by the API token",
+			method:           http.MethodGet,
+			path:             "/api/v1/projects/perses/dashboards/demo",
+			serviceAccount:   "ci",
+			tokenPermissions: []v1.Permission{{Actions: []v1.Action{v1.ActionRead}, Scopes: []v1.Scope{v1.ScopeWildcard}}},
+			expectedCode:     http.StatusOK,
+		},
+		{
+			title:            "list filtered by the API token",
+			method:           http.MethodGet,
+			path:             "/api/v1/projects/perses/dashboards",
+			serviceAccount:   "ci",
+			tokenPermissions: []v1.Permission{{Actions: []v1.Action{v1.ActionRead}, Scopes: []v1.Scope{v1.Scope(v1.KindVariable)}}},
+			expectedCode:     http.StatusOK,
+			expectedBody:     "false",
+		},
+		{
+			title:        "other project",
+			method:       http.MethodGet,
+			path:         "/api/v1/projects/other/dashboards/demo",
+			username:     "john",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			title:        "request not authenticated",
+			method:       http.MethodDelete,
+			path:         "/api/v1/projects/perses/dashboards/demo",
+			expectedCode: http.StatusOK,
+		},
+		{
+			title:        "list filtered",
+			method:       http.MethodGet,
+			path:         "/api/v1/projects/other/dashboards",
+			username:     "john",
+			expectedCode: http.StatusOK,
+			expectedBody: "false",
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			e := echo.New()
+			e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					if len(test.username) > 0 {
+						shared.SetUsername(c, test.username)
+					}
+					if len(test.serviceAccount) > 0 {
+						shared.SetServiceAccount(c, test.serviceAccount, test.tokenPermissions)
+					}
+					return next(c)
+				}
+			})
+			e.Use(CheckAuthorization(r))
+			handler := func(c echo.Context) error {
+				if shared.CanRead(c, v1.KindDashboard, shared.GetProjectParameter(c)) {
+					return c.String(http.StatusOK, "true")
+				}
+				return c.String(http.StatusOK, "false")
+			}
+			e.GET("/api/v1/projects/:project/dashboards", handler)
+			e.GET("/api/v1/projects/:project/dashboards/:name", handler)
+			e.DELETE("/api/v1/projects/:project/dashboards/:name", handler)
+			e.DELETE("/api/v1/projects/:project/variables/:name", handler)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(test.method, test.path, nil))
+			assert.Equal(t, test.expectedCode, rec.Code)
+			if len(test.expectedBody) > 0 {
+				assert.Equal(t, test.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -42,8 +42,6 @@ var (
 	dashboardProxyMatcher = regexp.MustCompile(`/proxy/projects/([a-zA-Z-0-9_-]+)/dashboards/([a-zA-Z-0-9_-]+)/datasources/([a-zA-Z-0-9_-]+)(/.*)?`)
 )
 
-// TODO cache the request to the database
-
 func extractGlobalDatasourceAndPath(requestPath string) (dtsName string, path string, err error) {
 	matchingGroups := globalProxyMatcher.FindAllStringSubmatch(requestPath, -1)
 	if len(matchingGroups) > 1 || len(matchingGroups) == 0 || len(matchingGroups[0]) <= 1 {
@@ -19,7 +19,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/perses/perses/internal/api/interface/v1/project"
@@ -50,33 +49,10 @@ func CheckProject(svc project.Service) echo.MiddlewareFunc {
 			if len(projectName) == 0 && method == http.MethodPost {
 				// It's possible the HTTP Path doesn't contain the project because the user is calling the root endpoint to create a new resource.
 				// So we need to ensure the project name exists in the resource, which is why we will partially decode the body to get the project name.
-				// And just to avoid a non-necessary deserialization, we will ensure we are managing a resource that is part of a project by checking the HTTP Path.
-				for _, path := range shared.ProjectResourcePathList {
-					if strings.HasPrefix(c.Path(), fmt.Sprintf("%s/%s", shared.APIV1Prefix, path)) {
-						// Parsing the body in an Echo middleware may cause the error code=400, message=EOF.
-						//
-						// Context.Bind only can be called only once in the life of the request as it read the body which can only be read once.
-						// The request data reader is running out, Context.Bind() function read request body data from the socket buffer, once you took it out, it is just gone
-						// That’s why it returns EOF error.
-						//
-						// In this middleware we need to partially decode the body to see if the project is set.
-						// So we read the body, and then we re-inject it in the request.
-						bodyBytes, err := io.ReadAll(c.Request().Body)
-						if err != nil {
-							return shared.HandleBadRequestError(err.Error())
-						}
-						// write back to request body
-						c.Request().Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-						// now we can safely partially decode the body
-						o := &partialObject{}
-						if unmarshalErr := json.Unmarshal(bodyBytes, o); unmarshalErr != nil {
-							return shared.HandleBadRequestError(unmarshalErr.Error())
-						}
-						if len(o.Metadata.Project) == 0 {
-							return shared.HandleBadRequestError("metadata.project cannot be empty")
-						}
-						projectName = o.Metadata.Project
-						break
+				if isProjectResourceCreation(c) {
+					var err error
+					if projectName, err = getBodyProject(c); err != nil {
+						return err
 					}
 				}
 			}
@@ -92,3 +68,45 @@ func CheckProject(svc project.Service) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// isProjectResourceCreation returns true if the request creates a resource belonging to a project from the root endpoint of its kind,
+// so the project is only given in the body.
+// It avoids a non-necessary deserialization, by checking we are managing a resource that is part of a project with the HTTP Path.
+func isProjectResourceCreation(c echo.Context) bool {
+	if c.Request().Method != http.MethodPost || len(shared.GetProjectParameter(c)) > 0 || c.Request().Body == nil {
+		return false
+	}
+	for _, path := range shared.ProjectResourcePathList {
+		if c.Path() == fmt.Sprintf("%s/%s", shared.APIV1Prefix, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// getBodyProject returns the project of the resource sent in the body of the request.
+func getBodyProject(c echo.Context) (string, error) {
+	// Parsing the body in an Echo middleware may cause the error code=400, message=EOF.
+	//
+	// Context.Bind only can be called only once in the life of the request as it read the body which can only be read once.
+	// The request data reader is running out, Context.Bind() function read request body data from the socket buffer, once you took it out, it is just gone
+	// That’s why it returns EOF error.
+	//
+	// In this middleware we need to partially decode the body to see if the project is set.
+	// So we read the body, and then we re-inject it in the request.
+	bodyBytes, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return "", shared.HandleBadRequestError(err.Error())
+	}
+	// write back to request body
+	c.Request().Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+	// now we can safely partially decode the body
+	o := &partialObject{}
+	if unmarshalErr := json.Unmarshal(bodyBytes, o); unmarshalErr != nil {
+		return "", shared.HandleBadRequestError(unmarshalErr.Error())
+	}
+	if len(o.Metadata.Project) == 0 {
+		return "", shared.HandleBadRequestError("metadata.project cannot be empty")
+	}
+	return o.Metadata.Project, nil
+}
@@ -0,0 +1,33 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/interface/v1/webhookdelivery"
+	"github.com/perses/perses/internal/api/shared"
+)
+
+// Webhook is a middleware sending to the webhooks the changes made by the requests with the service.
+// The changes are sent by the endpoints once they are written: shared.Toolbox, the bulk apply, the restoration of a revision
+// of a dashboard or of an entry of the trash, and the import of a backup. It must be registered after CheckAuthentication,
+// so the events contain who sent the requests.
+func Webhook(service webhookdelivery.Service) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			shared.SetNotifier(c, service.Notify)
+			return next(c)
+		}
+	}
+}
@@ -14,21 +14,39 @@
 package core
 
 import (
+	"net"
+
 	"github.com/labstack/echo/v4"
 	echoUtils "github.com/perses/common/echo"
 	"github.com/perses/perses/internal/api/config"
+	adminendpoint "github.com/perses/perses/internal/api/impl/admin"
+	authendpoint "github.com/perses/perses/internal/api/impl/auth"
 	configendpoint "github.com/perses/perses/internal/api/impl/config"
 	migrateendpoint "github.com/perses/perses/internal/api/impl/migrate"
+	openapiendpoint "github.com/perses/perses/internal/api/impl/openapi"
+	"github.com/perses/perses/internal/api/impl/v1/apply"
+	"github.com/perses/perses/internal/api/impl/v1/audit"
 	"github.com/perses/perses/internal/api/impl/v1/dashboard"
 	"github.com/perses/perses/internal/api/impl/v1/datasource"
 	"github.com/perses/perses/internal/api/impl/v1/folder"
 	"github.com/perses/perses/internal/api/impl/v1/globaldatasource"
+	"github.com/perses/perses/internal/api/impl/v1/globalrole"
+	"github.com/perses/perses/internal/api/impl/v1/globalrolebinding"
 	"github.com/perses/perses/internal/api/impl/v1/globalsecret"
 	"github.com/perses/perses/internal/api/impl/v1/globalvariable"
+	"github.com/perses/perses/internal/api/impl/v1/globalwebhook"
 	"github.com/perses/perses/internal/api/impl/v1/health"
 	"github.com/perses/perses/internal/api/impl/v1/project"
+	"github.com/perses/perses/internal/api/impl/v1/role"
+	"github.com/perses/perses/internal/api/impl/v1/rolebinding"
+	"github.com/perses/perses/internal/api/impl/v1/search"
 	"github.com/perses/perses/internal/api/impl/v1/secret"
+	"github.com/perses/perses/internal/api/impl/v1/serviceaccount"
+	"github.com/perses/perses/internal/api/impl/v1/trash"
+	"github.com/perses/perses/internal/api/impl/v1/user"
 	"github.com/perses/perses/internal/api/impl/v1/variable"
+	"github.com/perses/perses/internal/api/impl/v1/webhook"
+	"github.com/perses/perses/internal/api/impl/v1/webhookdelivery"
 	validateendpoint "github.com/perses/perses/internal/api/impl/validate"
 	"github.com/perses/perses/internal/api/shared"
 	"github.com/perses/perses/internal/api/shared/dependency"
@@ -42,34 +60,71 @@ type api struct {
 	echoUtils.Register
 	apiV1Endpoints []endpoint
 	apiEndpoints   []endpoint
+	ipExtractor    echo.IPExtractor
 }
 
 func NewPersesAPI(serviceManager dependency.ServiceManager, cfg config.Config) echoUtils.Register {
 	readonly := cfg.Readonly
 	apiV1Endpoints := []endpoint{
+		apply.NewEndpoint(serviceManager.GetApply(), readonly),
+		audit.NewEndpoint(serviceManager.GetAudit()),
 		dashboard.NewEndpoint(serviceManager.GetDashboard(), readonly),
+		dashboard.NewRevisionEndpoint(serviceManager.GetDashboard(), readonly),
 		datasource.NewEndpoint(serviceManager.GetDatasource(), readonly),
 		folder.NewEndpoint(serviceManager.GetFolder(), readonly),
 		globaldatasource.NewEndpoint(serviceManager.GetGlobalDatasource(), readonly),
+		globalrole.NewEndpoint(serviceManager.GetGlobalRole(), readonly),
+		globalrolebinding.NewEndpoint(serviceManager.GetGlobalRoleBinding(), readonly),
 		globalsecret.NewEndpoint(serviceManager.GetGlobalSecret(), readonly),
 		globalvariable.NewEndpoint(serviceManager.GetGlobalVariable(), readonly),
+		globalwebhook.NewEndpoint(serviceManager.GetGlobalWebhook(), readonly),
 		health.NewEndpoint(serviceManager.GetHealth()),
 		project.NewEndpoint(serviceManager.GetProject(), readonly),
+		role.NewEndpoint(serviceManager.GetRole(), readonly),
+		rolebinding.NewEndpoint(serviceManager.GetRoleBinding(), readonly),
+		search.NewEndpoint(serviceManager.GetSearch()),
 		secret.NewEndpoint(serviceManager.GetSecret(), readonly),
+		serviceaccount.NewEndpoint(serviceManager.GetServiceAccount(), readonly),
+		serviceaccount.NewTokenEndpoint(serviceManager.GetServiceAccount(), readonly),
+		trash.NewEndpoint(serviceManager.GetTrash(), readonly),
+		user.NewEndpoint(serviceManager.GetUser(), readonly),
 		variable.NewEndpoint(serviceManager.GetVariable(), readonly),
+		webhook.NewEndpoint(serviceManager.GetWebhook(), readonly),
+		webhookdelivery.NewEndpoint(serviceManager.GetWebhookDelivery(), readonly),
 	}
 	apiEndpoints := []endpoint{
+		adminendpoint.New(serviceManager.GetBackup(), readonly),
+		authendpoint.New(serviceManager.GetAuthentication(), serviceManager.GetOAuthProviders()),
 		configendpoint.New(cfg),
 		migrateendpoint.New(serviceManager.GetMigration()),
+		openapiendpoint.New(),
 		validateendpoint.New(serviceManager.GetSchemas(), serviceManager.GetDashboard()),
 	}
 	return &api{
 		apiV1Endpoints: apiV1Endpoints,
 		apiEndpoints:   apiEndpoints,
+		ipExtractor:    newIPExtractor(cfg.TrustedProxies),
+	}
+}
+
+// newIPExtractor returns how the IP of a client is found. Without trusted proxies, it is the IP of the connection,
+// so it cannot be forged with a header. Otherwise, the header X-Forwarded-For is only used as far as it is written by the trusted proxies.
+func newIPExtractor(trustedProxies []string) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range trustedProxies {
+		// the CIDRs are verified with the configuration.
+		if _, ipRange, err := net.ParseCIDR(proxy); err == nil {
+			options = append(options, echo.TrustIPRange(ipRange))
+		}
 	}
+	return echo.ExtractIPFromXFFHeader(options...)
 }
 
 func (a *api) RegisterRoute(e *echo.Echo) {
+	e.IPExtractor = a.ipExtractor
 	a.registerAPIV1Route(e)
 }
 
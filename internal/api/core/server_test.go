@@ -0,0 +1,113 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/config"
+	"github.com/perses/perses/internal/api/shared/dependency"
+	"github.com/perses/perses/internal/api/shared/openapi"
+	promConfig "github.com/prometheus/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var routeParamMatcher = regexp.MustCompile(`:([^/]+)`)
+
+// registeredOperations returns the routes registered by the API, in the form "<method> <path>" with the parameters
+// of the path written like in the OpenAPI document.
+func registeredOperations(t *testing.T) map[string]bool {
+	schemasPath := t.TempDir()
+	conf := config.Config{
+		EncryptionKey: promConfig.Secret(hex.EncodeToString([]byte("=tW$56zytgB&3jN2E%7-+qrGZE?v6LCc"))),
+		Database: config.Database{
+			File: &config.File{Folder: t.TempDir(), Extension: config.JSONExtension},
+		},
+		Schemas: config.Schemas{
+			PanelsPath:      schemasPath,
+			QueriesPath:     schemasPath,
+			DatasourcesPath: schemasPath,
+			VariablesPath:   schemasPath,
+		},
+	}
+	persistenceManager, err := dependency.NewPersistenceManager(conf.Database)
+	require.NoError(t, err)
+	serviceManager, err := dependency.NewServiceManager(persistenceManager, conf)
+	require.NoError(t, err)
+	e := echo.New()
+	NewPersesAPI(serviceManager, conf).RegisterRoute(e)
+	result := make(map[string]bool)
+	for _, route := range e.Routes() {
+		result[route.Method+" "+routeParamMatcher.ReplaceAllString(route.Path, "{$1}")] = true
+	}
+	return result
+}
+
+func TestEveryRouteIsDescribed(t *testing.T) {
+	routes := registeredOperations(t)
+	described := make(map[string]bool)
+	for path, item := range openapi.New().Paths {
+		for method := range item {
+			described[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+	for route := range routes {
+		assert.Truef(t, described[route], "the route %s is not described in the OpenAPI document, see the package internal/api/shared/openapi", route)
+	}
+	for operation := range described {
+		assert.Truef(t, routes[operation], "the operation %s described in the OpenAPI document is not registered", operation)
+	}
+}
+
+func TestNewIPExtractor(t *testing.T) {
+	testSuite := []struct {
+		title          string
+		trustedProxies []string
+		remoteAddr     string
+		expected       string
+	}{
+		{
+			title:      "header ignored without trusted proxies",
+			remoteAddr: "10.0.0.1:4242",
+			expected:   "10.0.0.1",
+		},
+		{
+			title:          "header written by a trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.1:4242",
+			expected:       "203.0.113.7",
+		},
+		{
+			title:          "header sent by a client",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "192.168.1.1:4242",
+			expected:       "192.168.1.1",
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/projects", nil)
+			req.RemoteAddr = test.remoteAddr
+			req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.7")
+			assert.Equal(t, test.expected, newIPExtractor(test.trustedProxies)(req))
+		})
+	}
+}
@@ -23,3 +23,11 @@ package api
 //go:generate go run generate.go -package=variable -plural=variables -kind=Variable -isProjectResource=true
 //go:generate go run generate.go -package=globalsecret -plural=globalsecrets -kind=GlobalSecret
 //go:generate go run generate.go -package=secret -plural=secrets -kind=Secret -isProjectResource=true
+//go:generate go run generate.go -package=user -plural=users -kind=User
+//go:generate go run generate.go -package=role -plural=roles -kind=Role -isProjectResource=true
+//go:generate go run generate.go -package=globalrole -plural=globalroles -kind=GlobalRole
+//go:generate go run generate.go -package=rolebinding -plural=rolebindings -kind=RoleBinding -isProjectResource=true
+//go:generate go run generate.go -package=globalrolebinding -plural=globalrolebindings -kind=GlobalRoleBinding
+//go:generate go run generate.go -package=serviceaccount -plural=serviceaccounts -kind=ServiceAccount
+//go:generate go run generate.go -package=webhook -plural=webhooks -kind=Webhook -isProjectResource=true
+//go:generate go run generate.go -package=globalwebhook -plural=globalwebhooks -kind=GlobalWebhook
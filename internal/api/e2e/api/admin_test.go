@@ -0,0 +1,82 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	e2eframework "github.com/perses/perses/internal/api/e2e/framework"
+	"github.com/perses/perses/internal/api/impl/admin"
+	"github.com/perses/perses/internal/api/shared"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/internal/api/shared/dependency"
+	"github.com/perses/perses/pkg/model/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		expect.DELETE(trashPath).Expect().Status(http.StatusOK)
+		project := e2eframework.NewProject("perses")
+		e2eframework.CreateAndWaitUntilEntityExists(t, manager, project)
+		projectPath := fmt.Sprintf("%s/%s/%s", shared.APIV1Prefix, shared.PathProject, project.Metadata.Name)
+		dashboard := e2eframework.NewDashboard(t, project.Metadata.Name, "Demo")
+		expect.POST(fmt.Sprintf("%s/%s", projectPath, shared.PathDashboard)).WithJSON(dashboard).Expect().Status(http.StatusOK)
+		secret := e2eframework.NewSecret(project.Metadata.Name, "basic")
+		expect.POST(fmt.Sprintf("%s/%s", projectPath, shared.PathSecret)).WithJSON(secret).Expect().Status(http.StatusOK)
+
+		response := expect.GET("/api/admin/export").
+			WithHeader(admin.HeaderPassphrase, "passphrase").
+			Expect().
+			Status(http.StatusOK)
+		response.Header("Content-Type").IsEqual("application/gzip")
+		archive := []byte(response.Body().Raw())
+
+		// the project is deleted for good, with everything it contains.
+		expect.DELETE(projectPath).Expect().Status(http.StatusNoContent)
+		expect.DELETE(trashPath).Expect().Status(http.StatusOK)
+		_, err := manager.GetSecret().Get(project.Metadata.Name, secret.Metadata.Name)
+		assert.True(t, databaseModel.IsKeyNotFound(err))
+
+		expect.POST("/api/admin/import").
+			WithHeader(admin.HeaderPassphrase, "wrong passphrase").
+			WithHeader("Content-Type", "application/gzip").
+			WithBytes(archive).
+			Expect().
+			Status(http.StatusBadRequest)
+		resources := expect.POST("/api/admin/import").
+			WithHeader(admin.HeaderPassphrase, "passphrase").
+			WithHeader("Content-Type", "application/gzip").
+			WithBytes(archive).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().Value("resources").Object()
+		resources.ContainsKey("Project")
+		resources.ContainsKey("Dashboard")
+		resources.ContainsKey("DashboardRevision")
+		resources.ContainsKey("Secret")
+
+		_, err = manager.GetDashboard().Get(project.Metadata.Name, dashboard.Metadata.Name)
+		assert.NoError(t, err)
+		expect.GET(fmt.Sprintf("%s/%s/%s", projectPath, shared.PathSecret, secret.Metadata.Name)).
+			Expect().
+			Status(http.StatusOK)
+		return []api.Entity{project, dashboard, secret}
+	})
+}
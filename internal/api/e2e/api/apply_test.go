@@ -0,0 +1,171 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	e2eframework "github.com/perses/perses/internal/api/e2e/framework"
+	"github.com/perses/perses/internal/api/shared"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/internal/api/shared/dependency"
+	"github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	"github.com/stretchr/testify/assert"
+)
+
+var applyPath = fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathApply)
+
+// expectApplyResults checks the results of an apply, given as the kind, the name and the action of each resource.
+func expectApplyResults(response *httpexpect.Object, dryRun bool, expected [][3]string) {
+	response.Value("dryRun").IsEqual(dryRun)
+	results := response.Value("results").Array()
+	results.Length().IsEqual(len(expected))
+	for i, result := range expected {
+		object := results.Value(i).Object()
+		object.Value("kind").IsEqual(result[0])
+		object.Value("name").IsEqual(result[1])
+		object.Value("action").IsEqual(result[2])
+	}
+}
+
+func TestApply(t *testing.T) {
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		projectName := "perses"
+		project := e2eframework.NewProject(projectName)
+		secret := e2eframework.NewSecret(projectName, "credentials")
+		datasource := e2eframework.NewDatasource(t, projectName, "prometheus")
+		variable := e2eframework.NewVariable(projectName, "job")
+		dashboard := e2eframework.NewDashboard(t, projectName, "demo")
+		// the resources are given in any order, they are applied after the ones they depend on.
+		resources := []api.Entity{dashboard, variable, datasource, secret, project}
+
+		response := expect.POST(applyPath).
+			WithQuery("dryRun", true).
+			WithJSON(resources).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+		expectApplyResults(response, true, [][3]string{
+			{string(modelV1.KindProject), projectName, string(modelV1.ApplyActionCreate)},
+			{string(modelV1.KindSecret), "credentials", string(modelV1.ApplyActionCreate)},
+			{string(modelV1.KindDatasource), "prometheus", string(modelV1.ApplyActionCreate)},
+			{string(modelV1.KindVariable), "job", string(modelV1.ApplyActionCreate)},
+			{string(modelV1.KindDashboard), "demo", string(modelV1.ApplyActionCreate)},
+		})
+		_, err := manager.GetProject().Get(projectName)
+		assert.True(t, databaseModel.IsKeyNotFound(err))
+
+		response = expect.POST(applyPath).
+			WithJSON(resources).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+		response.Value("dryRun").IsEqual(false)
+		_, err = manager.GetDashboard().Get(projectName, "demo")
+		assert.NoError(t, err)
+		// the secret is written by its service, so it is encrypted.
+		storedSecret, err := manager.GetSecret().Get(projectName, "credentials")
+		assert.NoError(t, err)
+		assert.NotEqual(t, secret.Spec.BasicAuth.Password, storedSecret.Spec.BasicAuth.Password)
+
+		dashboard.Spec.Display = &common.Display{Name: "Demo applied"}
+		response = expect.POST(applyPath).
+			WithQuery("dryRun", true).
+			WithJSON(resources).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+		expectApplyResults(response, true, [][3]string{
+			{string(modelV1.KindProject), projectName, string(modelV1.ApplyActionUnchanged)},
+			{string(modelV1.KindSecret), "credentials", string(modelV1.ApplyActionUnchanged)},
+			{string(modelV1.KindDatasource), "prometheus", string(modelV1.ApplyActionUnchanged)},
+			{string(modelV1.KindVariable), "job", string(modelV1.ApplyActionUnchanged)},
+			{string(modelV1.KindDashboard), "demo", string(modelV1.ApplyActionUpdate)},
+		})
+		expect.POST(applyPath).
+			WithJSON(resources).
+			Expect().
+			Status(http.StatusOK)
+		storedDashboard, err := manager.GetDashboard().Get(projectName, "demo")
+		assert.NoError(t, err)
+		assert.Equal(t, "Demo applied", storedDashboard.Spec.Display.Name)
+		assert.Equal(t, uint64(2), storedDashboard.Metadata.Version)
+
+		// nothing is written when one of the resources is invalid.
+		otherVariable := e2eframework.NewVariable(projectName, "instance")
+		expect.POST(applyPath).
+			WithJSON([]api.Entity{otherVariable, e2eframework.NewDashboard(t, "unknown", "demo")}).
+			Expect().
+			Status(http.StatusBadRequest)
+		_, err = manager.GetVariable().Get(projectName, otherVariable.Metadata.Name)
+		assert.True(t, databaseModel.IsKeyNotFound(err))
+
+		// the kinds that cannot be applied are rejected.
+		expect.POST(applyPath).
+			WithJSON([]api.Entity{e2eframework.NewUser("john")}).
+			Expect().
+			Status(http.StatusBadRequest)
+
+		return []api.Entity{dashboard, variable, datasource, secret, project}
+	})
+}
+
+func TestApplyYAML(t *testing.T) {
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		body := `kind: Project
+metadata:
+  name: perses
+---
+- kind: GlobalVariable
+  metadata:
+    name: cluster
+  spec:
+    kind: TextVariable
+    spec:
+      value: demo
+- kind: Folder
+  metadata:
+    name: ops
+    project: perses
+  spec:
+    - kind: Dashboard
+      name: demo
+`
+		response := expect.POST(applyPath).
+			WithHeader("Content-Type", "application/yaml").
+			WithBytes([]byte(body)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+		expectApplyResults(response, false, [][3]string{
+			{string(modelV1.KindProject), "perses", string(modelV1.ApplyActionCreate)},
+			{string(modelV1.KindGlobalVariable), "cluster", string(modelV1.ApplyActionCreate)},
+			{string(modelV1.KindFolder), "ops", string(modelV1.ApplyActionCreate)},
+		})
+		folder, err := manager.GetFolder().Get("perses", "ops")
+		assert.NoError(t, err)
+		globalVariable, err := manager.GetGlobalVariable().Get("cluster")
+		assert.NoError(t, err)
+		project, err := manager.GetProject().Get("perses")
+		assert.NoError(t, err)
+		return []api.Entity{folder, globalVariable, project}
+	})
+}
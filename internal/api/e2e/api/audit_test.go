@@ -0,0 +1,232 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+	e2eframework "github.com/perses/perses/internal/api/e2e/framework"
+	"github.com/perses/perses/internal/api/interface/v1/audit"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/dependency"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/prometheus/common/model"
+)
+
+var auditPath = fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathAudit)
+
+func TestAudit(t *testing.T) {
+	e2eframework.WithServerAndAudit(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []modelAPI.Entity {
+		if err := manager.GetPersesDAO().DeleteByQuery(&audit.Query{}); err != nil {
+			t.Fatal(err)
+		}
+		admin := signUpAndLogin(expect, "admin")
+		john := signUpAndLogin(expect, "john")
+		perses := e2eframework.NewProject("perses")
+		expect.POST(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathProject)).
+			WithHeader("Authorization", admin).
+			WithJSON(perses).
+			Expect().
+			Status(http.StatusOK)
+		dashboard := e2eframework.NewDashboard(t, "perses", "demo")
+		dashboardsPath := fmt.Sprintf("%s/%s/perses/%s", shared.APIV1Prefix, shared.PathProject, shared.PathDashboard)
+		expect.POST(dashboardsPath).
+			WithHeader("Authorization", admin).
+			WithJSON(dashboard).
+			Expect().
+			Status(http.StatusOK)
+		dashboard.Spec.Duration = model.Duration(time.Hour)
+		expect.PUT(fmt.Sprintf("%s/demo", dashboardsPath)).
+			WithHeader("Authorization", admin).
+			WithJSON(dashboard).
+			Expect().
+			Status(http.StatusOK)
+		expect.DELETE(fmt.Sprintf("%s/demo", dashboardsPath)).
+			WithHeader("Authorization", admin).
+			Expect().
+			Status(http.StatusNoContent)
+
+		// the records are returned the most recent first.
+		records := expect.GET(auditPath).
+			WithHeader("Authorization", admin).
+			WithQuery("kind", modelV1.KindDashboard).
+			WithQuery("project", "perses").
+			WithQuery("name", "demo").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Array()
+		records.Length().IsEqual(3)
+		deletion := records.Value(0).Object().Value("spec").Object()
+		deletion.Value("action").IsEqual(modelV1.ActionDelete)
+		deletion.Value("diff").IsNull()
+		deletion.NotContainsKey("newVersion")
+		update := records.Value(1).Object().Value("spec").Object()
+		update.Value("action").IsEqual(modelV1.ActionUpdate)
+		update.Path("$.actor").IsEqual(modelV1.Subject{Kind: modelV1.SubjectKindUser, Name: "admin"})
+		update.Path("$.diff.spec").IsEqual(map[string]interface{}{"duration": "1h"})
+		oldVersion := update.Value("oldVersion").Number().Raw()
+		update.Value("newVersion").IsEqual(oldVersion + 1)
+		deletion.Value("oldVersion").IsEqual(oldVersion + 1)
+		creation := records.Value(2).Object().Value("spec").Object()
+		creation.Value("action").IsEqual(modelV1.ActionCreate)
+		creation.NotContainsKey("oldVersion")
+		creation.Path("$.diff.metadata.name").IsEqual("demo")
+		creation.Path("$.resource").IsEqual(modelV1.AuditResource{Kind: modelV1.KindDashboard, Project: "perses", Name: "demo"})
+
+		// the filters
+		expect.GET(auditPath).
+			WithHeader("Authorization", admin).
+			WithQuery("kind", modelV1.KindProject).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Array().Length().IsEqual(1)
+		expect.GET(auditPath).
+			WithHeader("Authorization", admin).
+			WithQuery("actor", "john").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Array().IsEmpty()
+		expect.GET(auditPath).
+			WithHeader("Authorization", admin).
+			WithQuery("since", time.Now().Add(time.Hour).Format(time.RFC3339)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Array().IsEmpty()
+
+		// only the users allowed to read the records can list them.
+		expect.GET(auditPath).
+			WithHeader("Authorization", john).
+			Expect().
+			Status(http.StatusForbidden)
+
+		expect.DELETE(trashPath).
+			WithHeader("Authorization", admin).
+			Expect().
+			Status(http.StatusOK)
+		if err := manager.GetPersesDAO().DeleteByQuery(&audit.Query{}); err != nil {
+			t.Fatal(err)
+		}
+		return []modelAPI.Entity{perses, e2eframework.NewUser("admin"), e2eframework.NewUser("john")}
+	})
+}
+
+// getAuditRecords returns the records of the changes of the resource, the most recent first.
+func getAuditRecords(expect *httpexpect.Expect, token string, kind modelV1.Kind, name string) *httpexpect.Array {
+	return expect.GET(auditPath).
+		WithHeader("Authorization", token).
+		WithQuery("kind", kind).
+		WithQuery("name", name).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Array()
+}
+
+func TestAuditActions(t *testing.T) {
+	e2eframework.WithServerAndAudit(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []modelAPI.Entity {
+		if err := manager.GetPersesDAO().DeleteByQuery(&audit.Query{}); err != nil {
+			t.Fatal(err)
+		}
+		admin := signUpAndLogin(expect, "admin")
+		perses := e2eframework.NewProject("perses")
+		expect.POST(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathProject)).
+			WithHeader("Authorization", admin).
+			WithJSON(perses).
+			Expect().
+			Status(http.StatusOK)
+		dashboard := e2eframework.NewDashboard(t, "perses", "demo")
+		dashboardsPath := fmt.Sprintf("%s/%s/perses/%s", shared.APIV1Prefix, shared.PathProject, shared.PathDashboard)
+		expect.POST(dashboardsPath).
+			WithHeader("Authorization", admin).
+			WithJSON(dashboard).
+			Expect().
+			Status(http.StatusOK)
+		dashboard.Spec.Duration = model.Duration(time.Hour)
+		expect.PUT(fmt.Sprintf("%s/demo", dashboardsPath)).
+			WithHeader("Authorization", admin).
+			WithJSON(dashboard).
+			Expect().
+			Status(http.StatusOK)
+
+		// the restoration of a revision is an update of the dashboard.
+		expect.POST(fmt.Sprintf("%s/demo/%s/1/restore", dashboardsPath, shared.PathRevision)).
+			WithHeader("Authorization", admin).
+			Expect().
+			Status(http.StatusOK)
+		records := getAuditRecords(expect, admin, modelV1.KindDashboard, "demo")
+		records.Length().IsEqual(3)
+		rollback := records.Value(0).Object().Value("spec").Object()
+		rollback.Value("action").IsEqual(modelV1.ActionUpdate)
+		rollback.Value("oldVersion").IsEqual(2)
+		rollback.Value("newVersion").IsEqual(3)
+		rollback.Path("$.diff.spec.duration").IsEqual(e2eframework.NewDashboard(t, "perses", "demo").Spec.Duration.String())
+
+		// the restoration of an entry of the trash is a creation of the dashboard.
+		expect.DELETE(fmt.Sprintf("%s/demo", dashboardsPath)).
+			WithHeader("Authorization", admin).
+			Expect().
+			Status(http.StatusNoContent)
+		entry := expect.GET(trashPath).
+			WithHeader("Authorization", admin).
+			WithQuery("kind", modelV1.KindDashboard).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Array().Value(0).Object().Path("$.metadata.name").String().Raw()
+		expect.POST(fmt.Sprintf("%s/%s/restore", trashPath, entry)).
+			WithHeader("Authorization", admin).
+			Expect().
+			Status(http.StatusOK)
+		records = getAuditRecords(expect, admin, modelV1.KindDashboard, "demo")
+		records.Length().IsEqual(5)
+		restoration := records.Value(0).Object().Value("spec").Object()
+		restoration.Value("action").IsEqual(modelV1.ActionCreate)
+		restoration.Path("$.diff.metadata.name").IsEqual("demo")
+
+		// the creation of a token is an update of the service account, without the hash of the token.
+		serviceAccount := e2eframework.NewServiceAccount("ci")
+		expect.POST(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathServiceAccount)).
+			WithHeader("Authorization", admin).
+			WithJSON(serviceAccount).
+			Expect().
+			Status(http.StatusOK)
+		expect.POST(fmt.Sprintf("%s/%s/ci/%s", shared.APIV1Prefix, shared.PathServiceAccount, shared.PathToken)).
+			WithHeader("Authorization", admin).
+			WithJSON(map[string]string{"name": "deploy"}).
+			Expect().
+			Status(http.StatusOK)
+		records = getAuditRecords(expect, admin, modelV1.KindServiceAccount, "ci")
+		records.Length().IsEqual(2)
+		tokenCreation := records.Value(0).Object().Value("spec").Object()
+		tokenCreation.Value("action").IsEqual(modelV1.ActionUpdate)
+		tokenCreation.Path("$.actor").IsEqual(modelV1.Subject{Kind: modelV1.SubjectKindUser, Name: "admin"})
+		token := tokenCreation.Path("$.diff.spec.tokens").Array().Value(0).Object()
+		token.Value("name").IsEqual("deploy")
+		token.NotContainsKey("hash")
+
+		expect.DELETE(trashPath).
+			WithHeader("Authorization", admin).
+			Expect().
+			Status(http.StatusOK)
+		if err := manager.GetPersesDAO().DeleteByQuery(&audit.Query{}); err != nil {
+			t.Fatal(err)
+		}
+		return []modelAPI.Entity{dashboard, perses, serviceAccount, e2eframework.NewUser("admin")}
+	})
+}
@@ -0,0 +1,174 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/perses/perses/internal/api/config"
+	e2eframework "github.com/perses/perses/internal/api/e2e/framework"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/dependency"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// browserLogin logs in with the authorization code flow, following the redirections like a browser does.
+func browserLogin(expect *httpexpect.Expect, kind modelAPI.AuthProviderKind, slugID string) *httpexpect.Response {
+	return expect.GET(fmt.Sprintf("/api/auth/providers/%s/%s/login", kind, slugID)).
+		WithClient(&http.Client{Jar: httpexpect.NewCookieJar()}).
+		Expect()
+}
+
+func TestOIDCLogin(t *testing.T) {
+	idp := e2eframework.NewMockIdentityProvider()
+	defer idp.Close()
+	idp.SetUser(e2eframework.MockUser{Subject: "1234", Login: "jane", Groups: []string{"sre"}})
+	providers := config.AuthProviders{OIDC: []config.OIDCProvider{idp.OIDCProvider("mock")}}
+	e2eframework.WithServerAndAuthProviders(t, providers, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []modelAPI.Entity {
+		expect.GET("/api/auth/providers").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Array().IsEqual([]modelAPI.AuthProvider{{Kind: modelAPI.AuthProviderKindOIDC, SlugID: "mock", Name: "mock"}})
+
+		admin := signUpAndLogin(expect, "admin")
+		perses := e2eframework.NewProject("perses")
+		dashboard := e2eframework.NewDashboard(t, "perses", "demo")
+		e2eframework.CreateAndWaitUntilEntitiesExist(t, manager, perses, dashboard)
+		dashboardPath := fmt.Sprintf("%s/%s/perses/%s/demo", shared.APIV1Prefix, shared.PathProject, shared.PathDashboard)
+
+		// the group sre can read the dashboards of the project perses
+		role := e2eframework.NewRole("perses", "viewer")
+		binding := e2eframework.NewRoleBinding("perses", "sre", "viewer", "sre")
+		binding.Spec.Subjects = []v1.Subject{{Kind: v1.SubjectKindGroup, Name: "sre"}}
+		expect.POST(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathRole)).
+			WithHeader("Authorization", admin).
+			WithJSON(role).
+			Expect().
+			Status(http.StatusOK)
+		expect.POST(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathRoleBinding)).
+			WithHeader("Authorization", admin).
+			WithJSON(binding).
+			Expect().
+			Status(http.StatusOK)
+
+		tokens := browserLogin(expect, modelAPI.AuthProviderKindOIDC, "mock").
+			Status(http.StatusOK).
+			JSON().Object()
+		jane := "Bearer " + tokens.Value("accessToken").String().Raw()
+		expect.GET(dashboardPath).
+			WithHeader("Authorization", jane).
+			Expect().
+			Status(http.StatusOK)
+		expect.DELETE(dashboardPath).
+			WithHeader("Authorization", jane).
+			Expect().
+			Status(http.StatusForbidden)
+		// the user has been created, and linked to the account of the provider
+		expect.GET(fmt.Sprintf("%s/%s/jane", shared.APIV1Prefix, shared.PathUser)).
+			WithHeader("Authorization", jane).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().Value("spec").Object().Value("oauthProviders").IsEqual([]v1.OAuthProvider{{Issuer: idp.Issuer(), Subject: "1234"}})
+
+		// the groups are kept when the access token is refreshed
+		refreshed := expect.POST("/api/auth/refresh").
+			WithJSON(modelAPI.RefreshRequest{RefreshToken: tokens.Value("refreshToken").String().Raw()}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().Value("accessToken").String().Raw()
+		expect.GET(dashboardPath).
+			WithHeader("Authorization", "Bearer "+refreshed).
+			Expect().
+			Status(http.StatusOK)
+
+		// another account of the provider cannot take the login of jane, neither can it take the one of a user of Perses
+		idp.SetUser(e2eframework.MockUser{Subject: "5678", Login: "jane"})
+		browserLogin(expect, modelAPI.AuthProviderKindOIDC, "mock").
+			Status(http.StatusUnauthorized)
+		idp.SetUser(e2eframework.MockUser{Subject: "5678", Login: "admin"})
+		browserLogin(expect, modelAPI.AuthProviderKindOIDC, "mock").
+			Status(http.StatusUnauthorized)
+
+		// the callback is rejected without the state kept by the browser
+		expect.GET("/api/auth/providers/oidc/mock/callback").
+			WithQuery("code", "unknown").
+			WithQuery("state", "unknown").
+			Expect().
+			Status(http.StatusBadRequest)
+		expect.GET("/api/auth/providers/oidc/unknown/login").
+			Expect().
+			Status(http.StatusNotFound)
+
+		return []modelAPI.Entity{
+			dashboard, binding, role, perses,
+			e2eframework.NewUser("admin"), e2eframework.NewUser("jane"),
+		}
+	})
+}
+
+func TestDeviceLogin(t *testing.T) {
+	idp := e2eframework.NewMockIdentityProvider()
+	defer idp.Close()
+	providers := config.AuthProviders{
+		OIDC:  []config.OIDCProvider{idp.OIDCProvider("mock-oidc")},
+		OAuth: []config.OAuthProvider{idp.OAuthProvider("mock-oauth")},
+	}
+	e2eframework.WithServerAndAuthProviders(t, providers, func(expect *httpexpect.Expect, _ dependency.PersistenceManager) []modelAPI.Entity {
+		// the users are different, as an account of a provider can't log in with the user of another provider.
+		for login, provider := range map[string]modelAPI.AuthProvider{
+			"bob":   {Kind: modelAPI.AuthProviderKindOIDC, SlugID: "mock-oidc"},
+			"alice": {Kind: modelAPI.AuthProviderKindOAuth, SlugID: "mock-oauth"},
+		} {
+			idp.SetUser(e2eframework.MockUser{Subject: login, Login: login, Groups: []string{"dev"}})
+			path := fmt.Sprintf("/api/auth/providers/%s/%s/device", provider.Kind, provider.SlugID)
+			deviceCode := expect.POST(path + "/code").
+				Expect().
+				Status(http.StatusOK).
+				JSON().Object()
+			code := deviceCode.Value("deviceCode").String().Raw()
+
+			expect.POST(path + "/token").
+				WithJSON(modelAPI.DeviceAccessTokenRequest{DeviceCode: code}).
+				Expect().
+				Status(http.StatusBadRequest).
+				JSON().Object().Value("message").IsEqual(modelAPI.DeviceAuthorizationPending)
+
+			if !idp.ApproveDevice(deviceCode.Value("userCode").String().Raw()) {
+				t.Fatal("the user code is unknown")
+			}
+			accessToken := expect.POST(path + "/token").
+				WithJSON(modelAPI.DeviceAccessTokenRequest{DeviceCode: code}).
+				Expect().
+				Status(http.StatusOK).
+				JSON().Object().Value("accessToken").String().Raw()
+			expect.GET(fmt.Sprintf("%s/%s/%s", shared.APIV1Prefix, shared.PathUser, login)).
+				WithHeader("Authorization", "Bearer "+accessToken).
+				Expect().
+				Status(http.StatusOK)
+
+			// the device code can only be used once
+			expect.POST(path + "/token").
+				WithJSON(modelAPI.DeviceAccessTokenRequest{DeviceCode: code}).
+				Expect().
+				Status(http.StatusUnauthorized)
+		}
+		return []modelAPI.Entity{e2eframework.NewUser("bob"), e2eframework.NewUser("alice")}
+	})
+}
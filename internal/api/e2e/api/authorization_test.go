@@ -0,0 +1,135 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	e2eframework "github.com/perses/perses/internal/api/e2e/framework"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/dependency"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+)
+
+// signUpAndLogin creates the user with the API and returns the header Authorization of its requests.
+func signUpAndLogin(expect *httpexpect.Expect, login string) string {
+	expect.POST(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathUser)).
+		WithJSON(e2eframework.NewUser(login)).
+		Expect().
+		Status(http.StatusOK)
+	accessToken := expect.POST("/api/auth/login").
+		WithJSON(modelAPI.Auth{Login: login, Password: e2eframework.UserPassword}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("accessToken").String().Raw()
+	return "Bearer " + accessToken
+}
+
+func TestAuthorization(t *testing.T) {
+	e2eframework.WithServerAndAuthorization(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []modelAPI.Entity {
+		admin := signUpAndLogin(expect, "admin")
+		john := signUpAndLogin(expect, "john")
+		perses := e2eframework.NewProject("perses")
+		other := e2eframework.NewProject("other")
+		dashboard := e2eframework.NewDashboard(t, "perses", "demo")
+		otherDashboard := e2eframework.NewDashboard(t, "other", "demo")
+		e2eframework.CreateAndWaitUntilEntitiesExist(t, manager, perses, other, dashboard, otherDashboard)
+		dashboardPath := fmt.Sprintf("%s/%s/perses/%s/demo", shared.APIV1Prefix, shared.PathProject, shared.PathDashboard)
+		otherDashboardPath := fmt.Sprintf("%s/%s/other/%s/demo", shared.APIV1Prefix, shared.PathProject, shared.PathDashboard)
+
+		// john doesn't have any permission yet
+		expect.GET(dashboardPath).
+			WithHeader("Authorization", john).
+			Expect().
+			Status(http.StatusForbidden)
+		expect.GET(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathDashboard)).
+			WithHeader("Authorization", john).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Array().IsEmpty()
+
+		// the admin gives him the permission to read the dashboards of the project perses
+		role := e2eframework.NewRole("perses", "viewer")
+		binding := e2eframework.NewRoleBinding("perses", "viewers", "viewer", "john")
+		expect.POST(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathRole)).
+			WithHeader("Authorization", admin).
+			WithJSON(role).
+			Expect().
+			Status(http.StatusOK)
+		// john cannot give himself the permission
+		expect.POST(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathRoleBinding)).
+			WithHeader("Authorization", john).
+			WithJSON(binding).
+			Expect().
+			Status(http.StatusForbidden)
+		expect.POST(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathRoleBinding)).
+			WithHeader("Authorization", admin).
+			WithJSON(binding).
+			Expect().
+			Status(http.StatusOK)
+
+		expect.GET(dashboardPath).
+			WithHeader("Authorization", john).
+			Expect().
+			Status(http.StatusOK)
+		expect.DELETE(dashboardPath).
+			WithHeader("Authorization", john).
+			Expect().
+			Status(http.StatusForbidden)
+		expect.GET(otherDashboardPath).
+			WithHeader("Authorization", john).
+			Expect().
+			Status(http.StatusForbidden)
+		expect.POST(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathProject)).
+			WithHeader("Authorization", john).
+			WithJSON(e2eframework.NewProject("mine")).
+			Expect().
+			Status(http.StatusForbidden)
+		expect.GET("/api/admin/export").
+			WithHeader("Authorization", john).
+			Expect().
+			Status(http.StatusForbidden)
+		// the apply checks the permissions of each resource, even in a dry run
+		expect.POST(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathApply)).
+			WithHeader("Authorization", john).
+			WithQuery("dryRun", true).
+			WithJSON([]modelAPI.Entity{otherDashboard}).
+			Expect().
+			Status(http.StatusForbidden)
+
+		// the lists only contain what john can read
+		dashboards := expect.GET(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathDashboard)).
+			WithHeader("Authorization", john).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Array()
+		dashboards.Length().IsEqual(1)
+		dashboards.Value(0).Object().Value("metadata").Object().Value("project").IsEqual("perses")
+		expect.GET(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathDashboard)).
+			WithHeader("Authorization", admin).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Array().Length().IsEqual(2)
+
+		return []modelAPI.Entity{
+			dashboard, otherDashboard, binding, role, perses, other,
+			e2eframework.NewUser("admin"), e2eframework.NewUser("john"),
+		}
+	})
+}
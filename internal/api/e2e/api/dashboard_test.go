@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/gavv/httpexpect/v2"
 	e2eframework "github.com/perses/perses/internal/api/e2e/framework"
@@ -27,6 +28,7 @@ import (
 	testUtils "github.com/perses/perses/internal/test"
 	"github.com/perses/perses/pkg/model/api"
 	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -72,7 +74,7 @@ func TestUpdateDashboardIncreaseVersion(t *testing.T) {
 		assert.True(t, dashboard.Metadata.Version+1 == updatedDashboard.Metadata.Version)
 
 		updatedDashboard = extractDashboardFromHTTPBody(expect.PUT(fmt.Sprintf("%s/%s/%s/%s/%s", shared.APIV1Prefix, shared.PathProject, dashboard.Metadata.Project, shared.PathDashboard, dashboard.Metadata.Name)).
-			WithJSON(entity).
+			WithJSON(updatedDashboard).
 			Expect().
 			Status(http.StatusOK).
 			JSON().
@@ -83,6 +85,197 @@ func TestUpdateDashboardIncreaseVersion(t *testing.T) {
 	})
 }
 
+func TestUpdateDashboardWithStaleVersion(t *testing.T) {
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		entity := e2eframework.NewDashboard(t, "perses", "test")
+		project := e2eframework.NewProject("perses")
+		e2eframework.CreateAndWaitUntilEntityExists(t, manager, project)
+		path := fmt.Sprintf("%s/%s/%s/%s", shared.APIV1Prefix, shared.PathProject, entity.Metadata.Project, shared.PathDashboard)
+
+		expect.POST(path).
+			WithJSON(entity).
+			Expect().
+			Status(http.StatusOK).
+			Header(shared.HeaderETag).IsEqual(`"1"`)
+
+		// first update based on the version 1, it is accepted and the version is now 2
+		expect.PUT(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			WithJSON(entity).
+			WithHeader(shared.HeaderIfMatch, `"1"`).
+			Expect().
+			Status(http.StatusOK).
+			Header(shared.HeaderETag).IsEqual(`"2"`)
+
+		// a second update still based on the version 1 must be rejected
+		expect.PUT(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			WithJSON(entity).
+			WithHeader(shared.HeaderIfMatch, `"1"`).
+			Expect().
+			Status(http.StatusConflict)
+
+		// same thing when the version is provided in the metadata
+		staleEntity := e2eframework.NewDashboard(t, "perses", "test")
+		staleEntity.Metadata.Version = 3
+		expect.PUT(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			WithJSON(staleEntity).
+			Expect().
+			Status(http.StatusConflict)
+
+		// the update based on the current version is accepted
+		staleEntity.Metadata.Version = 2
+		expect.PUT(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			WithJSON(staleEntity).
+			Expect().
+			Status(http.StatusOK)
+
+		// without the header If-Match nor the version in the metadata, the client opts out of the check
+		expect.PUT(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			WithJSON(e2eframework.NewDashboard(t, "perses", "test")).
+			Expect().
+			Status(http.StatusOK)
+
+		expect.GET(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			Expect().
+			Status(http.StatusOK).
+			Header(shared.HeaderETag).IsEqual(`"4"`)
+		return []api.Entity{project, entity}
+	})
+}
+
+func TestConcurrentUpdatesOfNewDashboard(t *testing.T) {
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		entity := e2eframework.NewDashboard(t, "perses", "test")
+		project := e2eframework.NewProject("perses")
+		e2eframework.CreateAndWaitUntilEntityExists(t, manager, project)
+		path := fmt.Sprintf("%s/%s/%s/%s", shared.APIV1Prefix, shared.PathProject, entity.Metadata.Project, shared.PathDashboard)
+
+		expect.POST(path).
+			WithJSON(entity).
+			Expect().
+			Status(http.StatusOK)
+
+		// two clients read the dashboard that has never been updated, and both send back their own modification.
+		first := extractDashboardFromHTTPBody(expect.GET(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Raw(), t)
+		second := extractDashboardFromHTTPBody(expect.GET(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Raw(), t)
+		first.Spec.Duration = model.Duration(time.Hour)
+		second.Spec.Duration = model.Duration(2 * time.Hour)
+
+		expect.PUT(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			WithJSON(first).
+			Expect().
+			Status(http.StatusOK)
+		// the second client must not overwrite the modification of the first one.
+		expect.PUT(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			WithJSON(second).
+			Expect().
+			Status(http.StatusConflict)
+
+		expect.GET(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().Path("$.spec.duration").IsEqual(first.Spec.Duration.String())
+		return []api.Entity{project, entity}
+	})
+}
+
+func TestDashboardRevisions(t *testing.T) {
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		entity := e2eframework.NewDashboard(t, "perses", "test")
+		project := e2eframework.NewProject("perses")
+		e2eframework.CreateAndWaitUntilEntityExists(t, manager, project)
+		path := fmt.Sprintf("%s/%s/%s/%s", shared.APIV1Prefix, shared.PathProject, entity.Metadata.Project, shared.PathDashboard)
+		revisionPath := fmt.Sprintf("%s/%s/%s", path, entity.Metadata.Name, shared.PathRevision)
+
+		expect.POST(path).
+			WithJSON(entity).
+			Expect().
+			Status(http.StatusOK)
+
+		// update the duration of the dashboard, so we can distinguish the different revisions
+		updatedEntity := e2eframework.NewDashboard(t, "perses", "test")
+		updatedEntity.Spec.Duration = model.Duration(time.Hour)
+		expect.PUT(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			WithJSON(updatedEntity).
+			Expect().
+			Status(http.StatusOK)
+
+		revisions := expect.GET(revisionPath).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+		revisions.Length().IsEqual(2)
+		// the most recent revision comes first
+		revisions.Value(0).Object().Path("$.metadata.version").IsEqual(2)
+		revisions.Value(1).Object().Path("$.metadata.version").IsEqual(1)
+
+		expect.GET(fmt.Sprintf("%s/1", revisionPath)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().Path("$.spec.duration").IsEqual(entity.Spec.Duration.String())
+
+		expect.GET(fmt.Sprintf("%s/42", revisionPath)).
+			Expect().
+			Status(http.StatusNotFound)
+
+		// restoring the first revision is creating a new version of the dashboard, with the content of the first revision
+		restoredDashboard := extractDashboardFromHTTPBody(expect.POST(fmt.Sprintf("%s/1/restore", revisionPath)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Raw(), t)
+		assert.Equal(t, uint64(3), restoredDashboard.Metadata.Version)
+		assert.Equal(t, entity.Spec.Duration, restoredDashboard.Spec.Duration)
+
+		expect.GET(revisionPath).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array().
+			Length().IsEqual(3)
+
+		// once the dashboard is removed, the revisions are removed too
+		expect.DELETE(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			Expect().
+			Status(http.StatusNoContent)
+		expect.GET(fmt.Sprintf("%s/1", revisionPath)).
+			Expect().
+			Status(http.StatusNotFound)
+		return []api.Entity{project}
+	})
+}
+
+func TestDashboardRevisionsAuthor(t *testing.T) {
+	e2eframework.WithServerAndAuthentication(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		john := signUpAndLogin(expect, "john")
+		entity := e2eframework.NewDashboard(t, "perses", "test")
+		project := e2eframework.NewProject("perses")
+		e2eframework.CreateAndWaitUntilEntityExists(t, manager, project)
+		path := fmt.Sprintf("%s/%s/%s/%s", shared.APIV1Prefix, shared.PathProject, entity.Metadata.Project, shared.PathDashboard)
+
+		expect.POST(path).
+			WithHeader("Authorization", john).
+			WithJSON(entity).
+			Expect().
+			Status(http.StatusOK)
+
+		expect.GET(fmt.Sprintf("%s/%s/%s/1", path, entity.Metadata.Name, shared.PathRevision)).
+			WithHeader("Authorization", john).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().Path("$.metadata.author").IsEqual("john")
+		return []api.Entity{project, entity, e2eframework.NewUser("john")}
+	})
+}
+
 func TestListDashboardInEmptyProject(t *testing.T) {
 	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
 		demoDashboard := e2eframework.NewDashboard(t, "perses", "Demo")
@@ -108,3 +301,46 @@ func extractDashboardFromHTTPBody(body interface{}, t *testing.T) *modelV1.Dashb
 	testUtils.JSONUnmarshal(b, dashboard)
 	return dashboard
 }
+
+func TestPatchDashboard(t *testing.T) {
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		entity := e2eframework.NewDashboard(t, "perses", "test")
+		project := e2eframework.NewProject("perses")
+		e2eframework.CreateAndWaitUntilEntityExists(t, manager, project)
+		dashboardsPath := fmt.Sprintf("%s/%s/%s/%s", shared.APIV1Prefix, shared.PathProject, entity.Metadata.Project, shared.PathDashboard)
+		dashboard := extractDashboardFromHTTPBody(expect.POST(dashboardsPath).
+			WithJSON(entity).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Raw(), t)
+		dashboardPath := fmt.Sprintf("%s/%s", dashboardsPath, dashboard.Metadata.Name)
+
+		patchedDashboard := extractDashboardFromHTTPBody(expect.PATCH(dashboardPath).
+			WithHeader("Content-Type", string(api.MergePatchType)).
+			WithBytes([]byte(`{"spec":{"display":{"name":"Patched"}}}`)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Raw(), t)
+		assert.Equal(t, "Patched", patchedDashboard.Spec.Display.Name)
+		assert.Equal(t, dashboard.Metadata.Version+1, patchedDashboard.Metadata.Version)
+		assert.Equal(t, dashboard.Spec.Panels, patchedDashboard.Spec.Panels)
+
+		// the result of the patch is validated like a dashboard sent with PUT.
+		expect.PATCH(dashboardPath).
+			WithHeader("Content-Type", string(api.JSONPatchType)).
+			WithBytes([]byte(`[{"op":"replace","path":"/spec/panels/defaultTimeSeriesChart/spec/plugin/kind","value":"UnknownChart"}]`)).
+			Expect().
+			Status(http.StatusBadRequest)
+
+		// the version given with If-Match must be the current one.
+		expect.PATCH(dashboardPath).
+			WithHeader("Content-Type", string(api.MergePatchType)).
+			WithHeader(shared.HeaderIfMatch, fmt.Sprintf("%q", fmt.Sprint(dashboard.Metadata.Version))).
+			WithBytes([]byte(`{"spec":{"duration":"1h"}}`)).
+			Expect().
+			Status(http.StatusConflict)
+		return []api.Entity{project, entity}
+	})
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	e2eframework "github.com/perses/perses/internal/api/e2e/framework"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/dependency"
+	"github.com/perses/perses/internal/api/shared/openapi"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+)
+
+func TestOpenAPI(t *testing.T) {
+	e2eframework.WithServerAndAuthentication(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []modelAPI.Entity {
+		// the document is public, so a client can be generated without being logged in.
+		doc := expect.GET("/api" + openapi.PathOpenAPI).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+		doc.Value("openapi").String().Equal(openapi.Version)
+		paths := doc.Value("paths").Object()
+		paths.Value(fmt.Sprintf("%s/%s/{%s}/%s/{%s}", shared.APIV1Prefix, shared.PathProject, shared.ParamProject, shared.PathDashboard, shared.ParamName)).
+			Object().Keys().ContainsOnly("get", "put", "patch", "delete")
+		paths.ContainsKey("/api" + openapi.PathOpenAPI)
+		doc.Path("$.components.schemas.Plugin.properties.spec.additionalProperties").Boolean().True()
+		return []modelAPI.Entity{}
+	})
+}
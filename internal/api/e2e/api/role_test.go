@@ -0,0 +1,99 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	e2eframework "github.com/perses/perses/internal/api/e2e/framework"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/dependency"
+	"github.com/perses/perses/pkg/model/api"
+)
+
+func TestMainScenarioRole(t *testing.T) {
+	e2eframework.MainTestScenarioWithProject(t, shared.PathRole, func(projectName string, name string) (api.Entity, api.Entity) {
+		return e2eframework.NewProject(projectName), e2eframework.NewRole(projectName, name)
+	})
+}
+
+func TestMainScenarioGlobalRole(t *testing.T) {
+	e2eframework.MainTestScenario(t, shared.PathGlobalRole, func(name string) api.Entity {
+		return e2eframework.NewGlobalRole(name)
+	})
+}
+
+func TestCreateRoleBinding(t *testing.T) {
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		project := e2eframework.NewProject("perses")
+		role := e2eframework.NewRole("perses", "viewer")
+		user := e2eframework.NewUser("john")
+		e2eframework.CreateAndWaitUntilEntitiesExist(t, manager, project, role, user)
+		path := fmt.Sprintf("%s/%s/%s/%s", shared.APIV1Prefix, shared.PathProject, "perses", shared.PathRoleBinding)
+
+		// the role and the users must exist
+		expect.POST(path).
+			WithJSON(e2eframework.NewRoleBinding("perses", "viewers", "unknown", "john")).
+			Expect().
+			Status(http.StatusBadRequest)
+		expect.POST(path).
+			WithJSON(e2eframework.NewRoleBinding("perses", "viewers", "viewer", "unknown")).
+			Expect().
+			Status(http.StatusBadRequest)
+
+		entity := e2eframework.NewRoleBinding("perses", "viewers", "viewer", "john")
+		expect.POST(path).
+			WithJSON(entity).
+			Expect().
+			Status(http.StatusOK)
+		expect.GET(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().Value("spec").Object().Value("role").IsEqual("viewer")
+		expect.DELETE(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			Expect().
+			Status(http.StatusNoContent)
+		return []api.Entity{project, role, user}
+	})
+}
+
+func TestCreateGlobalRoleBinding(t *testing.T) {
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		role := e2eframework.NewGlobalRole("viewer")
+		user := e2eframework.NewUser("john")
+		e2eframework.CreateAndWaitUntilEntitiesExist(t, manager, role, user)
+		path := fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathGlobalRoleBinding)
+
+		expect.POST(path).
+			WithJSON(e2eframework.NewGlobalRoleBinding("viewers", "unknown", "john")).
+			Expect().
+			Status(http.StatusBadRequest)
+
+		entity := e2eframework.NewGlobalRoleBinding("viewers", "viewer", "john")
+		expect.POST(path).
+			WithJSON(entity).
+			Expect().
+			Status(http.StatusOK)
+		expect.GET(fmt.Sprintf("%s/%s", path, entity.Metadata.Name)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().Value("spec").Object().Value("role").IsEqual("viewer")
+		return []api.Entity{role, user, entity}
+	})
+}
@@ -75,6 +75,36 @@ func TestMainScenarioSecret(t *testing.T) {
 		})
 	})
 
+	t.Run(fmt.Sprintf("Patch test (%s)", path), func(t *testing.T) {
+		e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+			parent, entity := creator("myProject", "myResource")
+			e2eframework.CreateAndWaitUntilEntityExists(t, manager, parent)
+			secretsPath := fmt.Sprintf("%s/%s/%s/%s", shared.APIV1Prefix, shared.PathProject, parent.GetMetadata().GetName(), path)
+			expect.POST(secretsPath).
+				WithJSON(entity).
+				Expect().
+				Status(http.StatusOK)
+
+			basicAuth := expect.PATCH(fmt.Sprintf("%s/%s", secretsPath, entity.GetMetadata().GetName())).
+				WithHeader("Content-Type", string(api.MergePatchType)).
+				WithBytes([]byte(`{"spec":{"basicAuth":{"username":"Sherlock"}}}`)).
+				Expect().
+				Status(http.StatusOK).
+				JSON().Path("$.spec.basicAuth").Object()
+			basicAuth.Value("username").IsEqual("Sherlock")
+			basicAuth.Value("password").IsEqual("<secret>")
+
+			// the patch is applied to the decrypted secret, so the password is kept as it is.
+			expect.PATCH(fmt.Sprintf("%s/%s", secretsPath, entity.GetMetadata().GetName())).
+				WithHeader("Content-Type", string(api.JSONPatchType)).
+				WithBytes([]byte(`[{"op":"test","path":"/spec/basicAuth/password","value":"Detective"},{"op":"replace","path":"/spec/basicAuth/username","value":"Basil"}]`)).
+				Expect().
+				Status(http.StatusOK).
+				JSON().Path("$.spec.basicAuth.username").IsEqual("Basil")
+			return []api.Entity{parent, entity}
+		})
+	})
+
 	e2eframework.DeleteTestScenarioWithProject(t, path, creator)
 	e2eframework.NotFoundTestScenarioWithProject(t, path, creator)
 }
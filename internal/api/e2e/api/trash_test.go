@@ -0,0 +1,142 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	e2eframework "github.com/perses/perses/internal/api/e2e/framework"
+	"github.com/perses/perses/internal/api/shared"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/internal/api/shared/dependency"
+	"github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+var trashPath = fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathTrash)
+
+// getTrashEntry returns the name of the single entry of the trash matching the kind and the project.
+func getTrashEntry(expect *httpexpect.Expect, kind modelV1.Kind, project string) string {
+	entries := expect.GET(trashPath).
+		WithQuery("kind", kind).
+		WithQuery("project", project).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Array()
+	entries.Length().IsEqual(1)
+	entry := entries.Value(0).Object()
+	// the content is never returned, as it can contain secrets.
+	entry.Path("$.spec").Object().NotContainsKey("content")
+	return entry.Path("$.metadata.name").String().Raw()
+}
+
+func TestTrashDashboard(t *testing.T) {
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		expect.DELETE(trashPath).Expect().Status(http.StatusOK)
+		project := e2eframework.NewProject("perses")
+		e2eframework.CreateAndWaitUntilEntityExists(t, manager, project)
+		dashboardPath := fmt.Sprintf("%s/%s/%s/%s", shared.APIV1Prefix, shared.PathProject, project.Metadata.Name, shared.PathDashboard)
+		entity := e2eframework.NewDashboard(t, project.Metadata.Name, "Demo")
+		expect.POST(dashboardPath).WithJSON(entity).Expect().Status(http.StatusOK)
+
+		expect.DELETE(fmt.Sprintf("%s/%s", dashboardPath, entity.Metadata.Name)).
+			Expect().
+			Status(http.StatusNoContent)
+		_, err := manager.GetDashboard().Get(project.Metadata.Name, entity.Metadata.Name)
+		assert.True(t, databaseModel.IsKeyNotFound(err))
+
+		name := getTrashEntry(expect, modelV1.KindDashboard, project.Metadata.Name)
+		expect.POST(fmt.Sprintf("%s/%s/restore", trashPath, name)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().Path("$.metadata.name").IsEqual(entity.Metadata.Name)
+
+		// the dashboard is back with its revisions, and the entry is removed from the trash.
+		_, err = manager.GetDashboard().Get(project.Metadata.Name, entity.Metadata.Name)
+		assert.NoError(t, err)
+		expect.GET(fmt.Sprintf("%s/%s/%s", dashboardPath, entity.Metadata.Name, shared.PathRevision)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Array().Length().IsEqual(1)
+		expect.GET(fmt.Sprintf("%s/%s", trashPath, name)).
+			Expect().
+			Status(http.StatusNotFound)
+		return []api.Entity{project, entity}
+	})
+}
+
+func TestTrashProject(t *testing.T) {
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		expect.DELETE(trashPath).Expect().Status(http.StatusOK)
+		projectName := "perses"
+		project := e2eframework.NewProject(projectName)
+		dashboard := e2eframework.NewDashboard(t, projectName, "Demo")
+		datasource := e2eframework.NewDatasource(t, projectName, "Demo")
+		e2eframework.CreateAndWaitUntilEntitiesExist(t, manager, project, dashboard, datasource)
+		projectPath := fmt.Sprintf("%s/%s/%s", shared.APIV1Prefix, shared.PathProject, projectName)
+
+		// the dashboard is deleted before the project, so it is in its own entry.
+		expect.DELETE(fmt.Sprintf("%s/%s/%s", projectPath, shared.PathDashboard, dashboard.Metadata.Name)).
+			Expect().
+			Status(http.StatusNoContent)
+		expect.DELETE(projectPath).
+			Expect().
+			Status(http.StatusNoContent)
+		_, err := manager.GetDatasource().Get(projectName, datasource.Metadata.Name)
+		assert.True(t, databaseModel.IsKeyNotFound(err))
+
+		// the dashboard cannot be restored while its project doesn't exist.
+		dashboardEntry := getTrashEntry(expect, modelV1.KindDashboard, projectName)
+		expect.POST(fmt.Sprintf("%s/%s/restore", trashPath, dashboardEntry)).
+			Expect().
+			Status(http.StatusBadRequest)
+
+		projectEntry := getTrashEntry(expect, modelV1.KindProject, projectName)
+		expect.POST(fmt.Sprintf("%s/%s/restore", trashPath, projectEntry)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().Path("$.metadata.name").IsEqual(projectName)
+		expect.POST(fmt.Sprintf("%s/%s/restore", trashPath, dashboardEntry)).
+			Expect().
+			Status(http.StatusOK)
+		_, err = manager.GetDatasource().Get(projectName, datasource.Metadata.Name)
+		assert.NoError(t, err)
+		_, err = manager.GetDashboard().Get(projectName, dashboard.Metadata.Name)
+		assert.NoError(t, err)
+
+		// once purged, the project cannot be restored anymore.
+		expect.DELETE(projectPath).
+			Expect().
+			Status(http.StatusNoContent)
+		projectEntry = getTrashEntry(expect, modelV1.KindProject, projectName)
+		expect.DELETE(fmt.Sprintf("%s/%s", trashPath, projectEntry)).
+			Expect().
+			Status(http.StatusNoContent)
+		expect.POST(fmt.Sprintf("%s/%s/restore", trashPath, projectEntry)).
+			Expect().
+			Status(http.StatusNotFound)
+		expect.GET(trashPath).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Array().Length().IsEqual(0)
+		return []api.Entity{}
+	})
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	e2eframework "github.com/perses/perses/internal/api/e2e/framework"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/dependency"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodePublicUser(t *testing.T, object interface{}) *modelV1.PublicUser {
+	raw, err := json.Marshal(object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &modelV1.PublicUser{}
+	if unmarshalErr := json.Unmarshal(raw, result); unmarshalErr != nil {
+		t.Fatal(unmarshalErr)
+	}
+	return result
+}
+
+func TestMainScenarioUser(t *testing.T) {
+	path := shared.PathUser
+	creator := func(name string) modelAPI.Entity {
+		return e2eframework.NewUser(name)
+	}
+	e2eframework.CreateTestScenario(t, path, creator)
+	t.Run(fmt.Sprintf("Update test (%s)", path), func(t *testing.T) {
+		e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []modelAPI.Entity {
+			entity := creator("myResource")
+			e2eframework.CreateAndWaitUntilEntityExists(t, manager, entity)
+
+			o := expect.PUT(fmt.Sprintf("%s/%s/%s", shared.APIV1Prefix, path, entity.GetMetadata().GetName())).
+				WithJSON(entity).
+				Expect().
+				Status(http.StatusOK).
+				JSON().Raw()
+
+			// the password is never returned
+			result := decodePublicUser(t, o)
+			assert.Equal(t, e2eframework.NewPublicUser(entity.GetMetadata().GetName()).GetSpec(), result.GetSpec())
+
+			// the password is stored hashed
+			stored, err := manager.GetUser().Get(entity.GetMetadata().GetName())
+			if assert.NoError(t, err) {
+				assert.NotEqual(t, e2eframework.UserPassword, stored.Spec.NativeProvider.Password)
+				assert.NotEmpty(t, stored.Spec.NativeProvider.Password)
+			}
+			return []modelAPI.Entity{entity}
+		})
+	})
+	t.Run(fmt.Sprintf("Creation without password (%s)", path), func(t *testing.T) {
+		e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []modelAPI.Entity {
+			entity := e2eframework.NewUser("myResource")
+			entity.Spec.NativeProvider.Password = ""
+			expect.POST(fmt.Sprintf("%s/%s", shared.APIV1Prefix, path)).
+				WithJSON(entity).
+				Expect().
+				Status(http.StatusBadRequest)
+			return []modelAPI.Entity{}
+		})
+	})
+	e2eframework.DeleteTestScenario(t, path, creator)
+	e2eframework.NotFoundTestScenario(t, path, creator)
+}
+
+func TestAuthentication(t *testing.T) {
+	e2eframework.WithServerAndAuthentication(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []modelAPI.Entity {
+		projectsPath := fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathProject)
+		entity := e2eframework.NewUser("john")
+
+		// the health and the sign-up are public, the rest of the API is not
+		expect.GET(fmt.Sprintf("%s/health", shared.APIV1Prefix)).
+			Expect().
+			Status(http.StatusOK)
+		expect.GET(projectsPath).
+			Expect().
+			Status(http.StatusUnauthorized)
+		expect.POST(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathUser)).
+			WithJSON(entity).
+			Expect().
+			Status(http.StatusOK)
+
+		expect.POST("/api/auth/login").
+			WithJSON(modelAPI.Auth{Login: "john", Password: "wrong"}).
+			Expect().
+			Status(http.StatusUnauthorized)
+		tokens := expect.POST("/api/auth/login").
+			WithJSON(modelAPI.Auth{Login: "john", Password: e2eframework.UserPassword}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+		accessToken := tokens.Value("accessToken").String().NotEmpty().Raw()
+		refreshToken := tokens.Value("refreshToken").String().NotEmpty().Raw()
+
+		expect.GET(projectsPath).
+			WithHeader("Authorization", "Bearer "+accessToken).
+			Expect().
+			Status(http.StatusOK)
+		// the refresh token cannot be used to access the API
+		expect.GET(projectsPath).
+			WithHeader("Authorization", "Bearer "+refreshToken).
+			Expect().
+			Status(http.StatusUnauthorized)
+
+		refreshed := expect.POST("/api/auth/refresh").
+			WithJSON(modelAPI.RefreshRequest{RefreshToken: refreshToken}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+		refreshed.NotContainsKey("refreshToken")
+		expect.GET(projectsPath).
+			WithHeader("Authorization", "Bearer "+refreshed.Value("accessToken").String().Raw()).
+			Expect().
+			Status(http.StatusOK)
+		expect.POST("/api/auth/refresh").
+			WithJSON(modelAPI.RefreshRequest{RefreshToken: accessToken}).
+			Expect().
+			Status(http.StatusUnauthorized)
+		return []modelAPI.Entity{entity}
+	})
+}
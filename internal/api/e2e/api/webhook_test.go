@@ -0,0 +1,365 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/perses/perses/internal/api/config"
+	e2eframework "github.com/perses/perses/internal/api/e2e/framework"
+	webhookdeliveryImpl "github.com/perses/perses/internal/api/impl/v1/webhookdelivery"
+	"github.com/perses/perses/internal/api/interface/v1/webhookdelivery"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/dependency"
+	"github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/secret"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// unreachableWebhookURL is the URL of the webhooks whose deliveries are not tested.
+const unreachableWebhookURL = "http://localhost:1/hooks"
+
+func TestMainScenarioWebhook(t *testing.T) {
+	e2eframework.MainTestScenarioWithProject(t, shared.PathWebhook, func(projectName string, name string) (api.Entity, api.Entity) {
+		return e2eframework.NewProject(projectName), e2eframework.NewWebhook(projectName, name, unreachableWebhookURL)
+	})
+}
+
+func TestMainScenarioGlobalWebhook(t *testing.T) {
+	e2eframework.MainTestScenario(t, shared.PathGlobalWebhook, func(name string) api.Entity {
+		return e2eframework.NewGlobalWebhook(name, unreachableWebhookURL)
+	})
+}
+
+// webhookReceiver is a webhook answering with the status codes given, and then with 200.
+type webhookReceiver struct {
+	mutex       sync.Mutex
+	statusCodes []int
+	requests    []*http.Request
+	bodies      [][]byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	statusCode := http.StatusOK
+	if len(r.statusCodes) > 0 {
+		statusCode = r.statusCodes[0]
+		r.statusCodes = r.statusCodes[1:]
+	}
+	w.WriteHeader(statusCode)
+}
+
+func (r *webhookReceiver) received() ([]*http.Request, [][]byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]*http.Request{}, r.requests...), append([][]byte{}, r.bodies...)
+}
+
+// waitForDeliveries waits until the webhook has the number of deliveries with the status, and returns them.
+func waitForDeliveries(t *testing.T, expect *httpexpect.Expect, deliveriesPath string, status modelV1.WebhookDeliveryStatus, count int) []*modelV1.WebhookDelivery {
+	var deliveries []*modelV1.WebhookDelivery
+	for i := 0; i < 50; i++ {
+		raw := expect.GET(deliveriesPath).
+			WithQuery("status", status).
+			Expect().
+			Status(http.StatusOK).
+			Body().Raw()
+		deliveries = nil
+		if err := json.Unmarshal([]byte(raw), &deliveries); err != nil {
+			t.Fatal(err)
+		}
+		if len(deliveries) == count {
+			return deliveries
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	t.Fatalf("the webhook has %d deliveries %s instead of %d", len(deliveries), status, count)
+	return nil
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	receiver := &webhookReceiver{}
+	receiverServer := httptest.NewServer(receiver)
+	defer receiverServer.Close()
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		projectName := "perses"
+		project := e2eframework.NewProject(projectName)
+		e2eframework.CreateAndWaitUntilEntityExists(t, manager, project)
+		projectPath := fmt.Sprintf("%s/%s/%s", shared.APIV1Prefix, shared.PathProject, projectName)
+
+		signingSecret := &modelV1.Secret{
+			Kind:     modelV1.KindSecret,
+			Metadata: modelV1.ProjectMetadata{Metadata: modelV1.Metadata{Name: "signing"}, Project: projectName},
+			Spec:     modelV1.SecretSpec{Authorization: &secret.Authorization{Type: "Bearer", Credentials: "my-signing-key"}},
+		}
+		expect.POST(fmt.Sprintf("%s/%s", projectPath, shared.PathSecret)).WithJSON(signingSecret).Expect().Status(http.StatusOK)
+		webhook := e2eframework.NewWebhook(projectName, "ci", receiverServer.URL)
+		webhook.Spec.Secret = signingSecret.Metadata.Name
+		expect.POST(fmt.Sprintf("%s/%s", projectPath, shared.PathWebhook)).WithJSON(webhook).Expect().Status(http.StatusOK)
+		deliveriesPath := fmt.Sprintf("%s/%s/%s/%s", projectPath, shared.PathWebhook, webhook.Metadata.Name, shared.PathDelivery)
+
+		// the creation of the dashboard is sent right away, signed with the credentials of the secret.
+		dashboard := e2eframework.NewDashboard(t, projectName, "Demo")
+		expect.POST(fmt.Sprintf("%s/%s", projectPath, shared.PathDashboard)).WithJSON(dashboard).Expect().Status(http.StatusOK)
+		deliveries := waitForDeliveries(t, expect, deliveriesPath, modelV1.WebhookDeliveryStatusSucceeded, 1)
+		delivery := deliveries[0]
+		assert.Len(t, delivery.Spec.Attempts, 1)
+		assert.Equal(t, http.StatusOK, delivery.Spec.Attempts[0].StatusCode)
+		requests, bodies := receiver.received()
+		assert.Len(t, requests, 1)
+		assert.Equal(t, delivery.Metadata.Name, requests[0].Header.Get(webhookdeliveryImpl.HeaderDelivery))
+		assert.Equal(t, webhookdeliveryImpl.Sign([]byte("my-signing-key"), bodies[0]), requests[0].Header.Get(webhookdeliveryImpl.HeaderSignature))
+		event := &modelV1.WebhookEvent{}
+		assert.NoError(t, json.Unmarshal(bodies[0], event))
+		assert.Equal(t, modelV1.ActionCreate, event.Action)
+		assert.Equal(t, modelV1.AuditResource{Kind: modelV1.KindDashboard, Project: projectName, Name: dashboard.Metadata.Name}, event.Resource)
+
+		// the changes of the other kinds are not sent.
+		variable := e2eframework.NewVariable(projectName, "job")
+		expect.POST(fmt.Sprintf("%s/%s", projectPath, shared.PathVariable)).WithJSON(variable).Expect().Status(http.StatusOK)
+
+		// a delivery that is over can be replayed, as a new delivery.
+		replay := expect.POST(fmt.Sprintf("%s/%s/replay", deliveriesPath, delivery.Metadata.Name)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+		replay.Path("$.spec.replayOf").IsEqual(delivery.Metadata.Name)
+		waitForDeliveries(t, expect, deliveriesPath, modelV1.WebhookDeliveryStatusSucceeded, 2)
+		requests, bodies = receiver.received()
+		assert.Len(t, requests, 2)
+		assert.Equal(t, replay.Path("$.metadata.name").String().Raw(), requests[1].Header.Get(webhookdeliveryImpl.HeaderDelivery))
+		assert.Equal(t, bodies[0], bodies[1])
+
+		expect.GET(fmt.Sprintf("%s/%s", deliveriesPath, delivery.Metadata.Name)).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().Path("$.spec.status").IsEqual(modelV1.WebhookDeliveryStatusSucceeded)
+		expect.GET(deliveriesPath).
+			WithQuery("status", "unknown").
+			Expect().
+			Status(http.StatusBadRequest)
+
+		// the deliveries are removed with their webhook.
+		expect.DELETE(fmt.Sprintf("%s/%s/%s", projectPath, shared.PathWebhook, webhook.Metadata.Name)).
+			Expect().
+			Status(http.StatusNoContent)
+		expect.GET(deliveriesPath).
+			Expect().
+			Status(http.StatusNotFound)
+		_, err := manager.GetWebhookDelivery().Get(delivery.Metadata.Name)
+		assert.Error(t, err)
+		return []api.Entity{project, signingSecret, dashboard, variable}
+	})
+}
+
+func TestGlobalWebhookFailedDelivery(t *testing.T) {
+	receiver := &webhookReceiver{statusCodes: []int{http.StatusInternalServerError}}
+	receiverServer := httptest.NewServer(receiver)
+	defer receiverServer.Close()
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		projectName := "perses"
+		project := e2eframework.NewProject(projectName)
+		e2eframework.CreateAndWaitUntilEntityExists(t, manager, project)
+		webhook := e2eframework.NewGlobalWebhook("cmdb", receiverServer.URL)
+		webhook.Spec.MaxAttempts = 1
+		expect.POST(fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathGlobalWebhook)).WithJSON(webhook).Expect().Status(http.StatusOK)
+		deliveriesPath := fmt.Sprintf("%s/%s/%s/%s", shared.APIV1Prefix, shared.PathGlobalWebhook, webhook.Metadata.Name, shared.PathDelivery)
+
+		dashboard := e2eframework.NewDashboard(t, projectName, "Demo")
+		expect.POST(fmt.Sprintf("%s/%s/%s/%s", shared.APIV1Prefix, shared.PathProject, projectName, shared.PathDashboard)).
+			WithJSON(dashboard).
+			Expect().
+			Status(http.StatusOK)
+		deliveries := waitForDeliveries(t, expect, deliveriesPath, modelV1.WebhookDeliveryStatusFailed, 1)
+		assert.Len(t, deliveries[0].Spec.Attempts, 1)
+		assert.Equal(t, http.StatusInternalServerError, deliveries[0].Spec.Attempts[0].StatusCode)
+		assert.Nil(t, deliveries[0].Spec.NextAttemptAt)
+		waitForDeliveries(t, expect, deliveriesPath, modelV1.WebhookDeliveryStatusSucceeded, 0)
+
+		// once the webhook is fixed, the failed delivery can be replayed.
+		expect.POST(fmt.Sprintf("%s/%s/replay", deliveriesPath, deliveries[0].Metadata.Name)).
+			Expect().
+			Status(http.StatusOK)
+		waitForDeliveries(t, expect, deliveriesPath, modelV1.WebhookDeliveryStatusSucceeded, 1)
+		waitForDeliveries(t, expect, deliveriesPath, "", 2)
+
+		expect.DELETE(fmt.Sprintf("%s/%s/%s", shared.APIV1Prefix, shared.PathGlobalWebhook, webhook.Metadata.Name)).
+			Expect().
+			Status(http.StatusNoContent)
+		return []api.Entity{project, dashboard}
+	})
+}
+
+func TestWebhookDeliverRetry(t *testing.T) {
+	receiver := &webhookReceiver{statusCodes: []int{http.StatusServiceUnavailable}}
+	receiverServer := httptest.NewServer(receiver)
+	defer receiverServer.Close()
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		projectName := "perses"
+		project := e2eframework.NewProject(projectName)
+		webhook := e2eframework.NewWebhook(projectName, "chat", receiverServer.URL)
+		webhook.Spec.Backoff = model.Duration(time.Millisecond)
+		e2eframework.CreateAndWaitUntilEntitiesExist(t, manager, project, webhook)
+		service := webhookdeliveryImpl.NewService(manager.GetWebhookDelivery(), manager.GetWebhook(), manager.GetGlobalWebhook(),
+			manager.GetSecret(), manager.GetGlobalSecret(), nil, config.Webhook{Timeout: model.Duration(5 * time.Second), Retention: model.Duration(time.Hour)})
+		query := &webhookdelivery.Query{Kind: modelV1.KindWebhook, Project: projectName, Name: webhook.Metadata.Name}
+
+		service.Notify(&modelV1.WebhookEvent{
+			Time:     time.Now().UTC(),
+			Action:   modelV1.ActionDelete,
+			Resource: modelV1.AuditResource{Kind: modelV1.KindDashboard, Project: projectName, Name: "Demo"},
+		})
+		// the first attempt fails, so the delivery stays pending until its next attempt.
+		var deliveries []*modelV1.WebhookDelivery
+		for i := 0; i < 50 && (len(deliveries) != 1 || len(deliveries[0].Spec.Attempts) != 1); i++ {
+			time.Sleep(100 * time.Millisecond)
+			var err error
+			if deliveries, err = service.List(query); err != nil {
+				t.Fatal(err)
+			}
+		}
+		assert.Len(t, deliveries, 1)
+		assert.Len(t, deliveries[0].Spec.Attempts, 1)
+		assert.Equal(t, modelV1.WebhookDeliveryStatusPending, deliveries[0].Spec.Status)
+		assert.Equal(t, http.StatusServiceUnavailable, deliveries[0].Spec.Attempts[0].StatusCode)
+
+		// the delivery is sent again by the next run of the deliverer.
+		time.Sleep(10 * time.Millisecond)
+		assert.NoError(t, service.Deliver())
+		delivery, err := service.Get(deliveries[0].Spec.Webhook, deliveries[0].Metadata.Name)
+		assert.NoError(t, err)
+		assert.Equal(t, modelV1.WebhookDeliveryStatusSucceeded, delivery.Spec.Status)
+		assert.Len(t, delivery.Spec.Attempts, 2)
+		requests, _ := receiver.received()
+		assert.Len(t, requests, 2)
+
+		expect.DELETE(fmt.Sprintf("%s/%s/%s/%s/%s", shared.APIV1Prefix, shared.PathProject, projectName, shared.PathWebhook, webhook.Metadata.Name)).
+			Expect().
+			Status(http.StatusNoContent)
+		return []api.Entity{project}
+	})
+}
+
+func TestWebhookDeniedHost(t *testing.T) {
+	receiver := &webhookReceiver{}
+	receiverServer := httptest.NewServer(receiver)
+	defer receiverServer.Close()
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		projectName := "perses"
+		project := e2eframework.NewProject(projectName)
+		// the name is allowed, but it resolves to an IP denied.
+		webhook := e2eframework.NewWebhook(projectName, "chat", strings.Replace(receiverServer.URL, "127.0.0.1", "localhost", 1))
+		e2eframework.CreateAndWaitUntilEntitiesExist(t, manager, project, webhook)
+		service := webhookdeliveryImpl.NewService(manager.GetWebhookDelivery(), manager.GetWebhook(), manager.GetGlobalWebhook(),
+			manager.GetSecret(), manager.GetGlobalSecret(), nil, config.Webhook{
+				Timeout:      model.Duration(5 * time.Second),
+				Retention:    model.Duration(time.Hour),
+				AllowedHosts: []string{"localhost"},
+				DeniedHosts:  []string{"127.0.0.0/8", "::1/128"},
+			})
+		query := &webhookdelivery.Query{Kind: modelV1.KindWebhook, Project: projectName, Name: webhook.Metadata.Name}
+
+		service.Notify(&modelV1.WebhookEvent{
+			Time:     time.Now().UTC(),
+			Action:   modelV1.ActionDelete,
+			Resource: modelV1.AuditResource{Kind: modelV1.KindDashboard, Project: projectName, Name: "Demo"},
+		})
+		var deliveries []*modelV1.WebhookDelivery
+		for i := 0; i < 50 && (len(deliveries) != 1 || len(deliveries[0].Spec.Attempts) != 1); i++ {
+			time.Sleep(100 * time.Millisecond)
+			var err error
+			if deliveries, err = service.List(query); err != nil {
+				t.Fatal(err)
+			}
+		}
+		assert.Len(t, deliveries, 1)
+		assert.Len(t, deliveries[0].Spec.Attempts, 1)
+		assert.Contains(t, deliveries[0].Spec.Attempts[0].Error, "is denied for the webhooks")
+		requests, _ := receiver.received()
+		assert.Len(t, requests, 0)
+
+		expect.DELETE(fmt.Sprintf("%s/%s/%s/%s/%s", shared.APIV1Prefix, shared.PathProject, projectName, shared.PathWebhook, webhook.Metadata.Name)).
+			Expect().
+			Status(http.StatusNoContent)
+		return []api.Entity{project}
+	})
+}
+
+func TestWebhookRestorations(t *testing.T) {
+	receiver := &webhookReceiver{}
+	receiverServer := httptest.NewServer(receiver)
+	defer receiverServer.Close()
+	e2eframework.WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []api.Entity {
+		expect.DELETE(trashPath).Expect().Status(http.StatusOK)
+		projectName := "perses"
+		project := e2eframework.NewProject(projectName)
+		webhook := e2eframework.NewWebhook(projectName, "ci", receiverServer.URL)
+		webhook.Spec.Kinds = []modelV1.Kind{modelV1.KindDashboard}
+		e2eframework.CreateAndWaitUntilEntitiesExist(t, manager, project, webhook)
+		projectPath := fmt.Sprintf("%s/%s/%s", shared.APIV1Prefix, shared.PathProject, projectName)
+		dashboardsPath := fmt.Sprintf("%s/%s", projectPath, shared.PathDashboard)
+		deliveriesPath := fmt.Sprintf("%s/%s/%s/%s", projectPath, shared.PathWebhook, webhook.Metadata.Name, shared.PathDelivery)
+
+		dashboard := e2eframework.NewDashboard(t, projectName, "Demo")
+		expect.POST(dashboardsPath).WithJSON(dashboard).Expect().Status(http.StatusOK)
+		dashboard.Spec.Duration = model.Duration(time.Hour)
+		expect.PUT(fmt.Sprintf("%s/%s", dashboardsPath, dashboard.Metadata.Name)).WithJSON(dashboard).Expect().Status(http.StatusOK)
+		waitForDeliveries(t, expect, deliveriesPath, modelV1.WebhookDeliveryStatusSucceeded, 2)
+
+		// the restoration of a revision is sent as an update of the dashboard.
+		expect.POST(fmt.Sprintf("%s/%s/%s/1/restore", dashboardsPath, dashboard.Metadata.Name, shared.PathRevision)).
+			Expect().
+			Status(http.StatusOK)
+		waitForDeliveries(t, expect, deliveriesPath, modelV1.WebhookDeliveryStatusSucceeded, 3)
+
+		// the restoration of an entry of the trash is sent as a creation of the dashboard.
+		expect.DELETE(fmt.Sprintf("%s/%s", dashboardsPath, dashboard.Metadata.Name)).Expect().Status(http.StatusNoContent)
+		waitForDeliveries(t, expect, deliveriesPath, modelV1.WebhookDeliveryStatusSucceeded, 4)
+		entry := getTrashEntry(expect, modelV1.KindDashboard, projectName)
+		expect.POST(fmt.Sprintf("%s/%s/restore", trashPath, entry)).Expect().Status(http.StatusOK)
+		waitForDeliveries(t, expect, deliveriesPath, modelV1.WebhookDeliveryStatusSucceeded, 5)
+
+		_, bodies := receiver.received()
+		actions := make(map[modelV1.Action]int)
+		for _, body := range bodies {
+			event := &modelV1.WebhookEvent{}
+			assert.NoError(t, json.Unmarshal(body, event))
+			assert.Equal(t, modelV1.AuditResource{Kind: modelV1.KindDashboard, Project: projectName, Name: dashboard.Metadata.Name}, event.Resource)
+			actions[event.Action]++
+		}
+		assert.Equal(t, map[modelV1.Action]int{modelV1.ActionCreate: 2, modelV1.ActionUpdate: 2, modelV1.ActionDelete: 1}, actions)
+
+		expect.DELETE(fmt.Sprintf("%s/%s/%s", projectPath, shared.PathWebhook, webhook.Metadata.Name)).
+			Expect().
+			Status(http.StatusNoContent)
+		return []api.Entity{project, dashboard}
+	})
+}
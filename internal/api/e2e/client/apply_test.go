@@ -0,0 +1,57 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package client
+
+import (
+	"testing"
+
+	e2eframework "github.com/perses/perses/internal/api/e2e/framework"
+	"github.com/perses/perses/internal/api/shared/dependency"
+	"github.com/perses/perses/pkg/client/api/v1"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApply(t *testing.T) {
+	withClient(t, func(clientInterface v1.ClientInterface, manager dependency.PersistenceManager) []modelAPI.Entity {
+		projectEntity := e2eframework.NewProject("perses")
+		variableEntity := e2eframework.NewVariable("perses", "job")
+		resources := []modelAPI.Entity{variableEntity, projectEntity}
+
+		response, err := clientInterface.Apply().Apply(resources, true)
+		assert.NoError(t, err)
+		assert.True(t, response.DryRun)
+		assert.Equal(t, []*modelV1.ApplyResult{
+			{Kind: modelV1.KindProject, Name: "perses", Action: modelV1.ApplyActionCreate},
+			{Kind: modelV1.KindVariable, Project: "perses", Name: "job", Action: modelV1.ApplyActionCreate},
+		}, response.Results)
+
+		response, err = clientInterface.Apply().Apply(resources, false)
+		assert.NoError(t, err)
+		assert.False(t, response.DryRun)
+		_, err = clientInterface.Variable("perses").Get("job")
+		assert.NoError(t, err)
+
+		// applying the same resources again doesn't change anything.
+		response, err = clientInterface.Apply().Apply(resources, false)
+		assert.NoError(t, err)
+		for _, result := range response.Results {
+			assert.Equal(t, modelV1.ApplyActionUnchanged, result.Action)
+		}
+		return []modelAPI.Entity{variableEntity, projectEntity}
+	})
+}
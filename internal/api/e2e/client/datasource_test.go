@@ -21,6 +21,7 @@ import (
 	e2eframework "github.com/perses/perses/internal/api/e2e/framework"
 	"github.com/perses/perses/internal/api/shared/dependency"
 	"github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
 	modelAPI "github.com/perses/perses/pkg/model/api"
 	"github.com/stretchr/testify/assert"
 )
@@ -56,6 +57,43 @@ func TestUpdateDatasource(t *testing.T) {
 	})
 }
 
+func TestUpdateDatasourceWithStaleVersion(t *testing.T) {
+	withClient(t, func(clientInterface v1.ClientInterface, manager dependency.PersistenceManager) []modelAPI.Entity {
+		projectEntity := e2eframework.NewProject("perses")
+		entity := e2eframework.NewDatasource(t, "perses", "myDTS")
+		e2eframework.CreateAndWaitUntilEntityExists(t, manager, projectEntity)
+		e2eframework.CreateAndWaitUntilEntityExists(t, manager, entity)
+
+		object, err := clientInterface.Datasource(entity.Metadata.Project).Update(entity)
+		assert.NoError(t, err)
+		// someone else is updating the datasource in the meantime
+		_, err = clientInterface.Datasource(entity.Metadata.Project).Update(object)
+		assert.NoError(t, err)
+
+		_, err = clientInterface.Datasource(entity.Metadata.Project).Update(object)
+		assert.True(t, perseshttp.IsConflictError(err))
+		return []modelAPI.Entity{projectEntity, entity}
+	})
+}
+
+func TestPatchDatasource(t *testing.T) {
+	withClient(t, func(clientInterface v1.ClientInterface, manager dependency.PersistenceManager) []modelAPI.Entity {
+		projectEntity := e2eframework.NewProject("perses")
+		entity := e2eframework.NewDatasource(t, "perses", "myDTS")
+		e2eframework.CreateAndWaitUntilEntityExists(t, manager, projectEntity)
+		e2eframework.CreateAndWaitUntilEntityExists(t, manager, entity)
+
+		object, err := clientInterface.Datasource(entity.Metadata.Project).Patch(entity.Metadata.Name, modelAPI.MergePatchType, []byte(`{"spec":{"default":true}}`))
+		assert.NoError(t, err)
+		assert.True(t, object.Spec.Default)
+		assert.Equal(t, entity.Spec.Plugin, object.Spec.Plugin)
+
+		_, err = clientInterface.Datasource(entity.Metadata.Project).Patch(entity.Metadata.Name, modelAPI.JSONPatchType, []byte(`[{"op":"test","path":"/spec/default","value":false}]`))
+		assert.True(t, perseshttp.IsConflictError(err))
+		return []modelAPI.Entity{projectEntity, entity}
+	})
+}
+
 func TestGetDatasource(t *testing.T) {
 	withClient(t, func(clientInterface v1.ClientInterface, manager dependency.PersistenceManager) []modelAPI.Entity {
 		projectEntity := e2eframework.NewProject("perses")
@@ -99,3 +137,76 @@ func TestListDatasource(t *testing.T) {
 		return []modelAPI.Entity{projectEntity, entity}
 	})
 }
+
+func TestListDatasourcePage(t *testing.T) {
+	withClient(t, func(clientInterface v1.ClientInterface, manager dependency.PersistenceManager) []modelAPI.Entity {
+		projectEntity := e2eframework.NewProject("perses")
+		entities := []modelAPI.Entity{projectEntity}
+		for _, name := range []string{"c", "a", "b"} {
+			entities = append(entities, e2eframework.NewDatasource(t, "perses", name))
+		}
+		for _, entity := range entities {
+			e2eframework.CreateAndWaitUntilEntityExists(t, manager, entity)
+		}
+
+		firstPage, next, err := clientInterface.Datasource("perses").ListPage("", v1.ListOptions{Limit: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(firstPage))
+		assert.Equal(t, "a", firstPage[0].Metadata.Name)
+		assert.Equal(t, "b", firstPage[1].Metadata.Name)
+		assert.NotEmpty(t, next)
+
+		secondPage, next, err := clientInterface.Datasource("perses").ListPage("", v1.ListOptions{Limit: 2, Continue: next})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(secondPage))
+		assert.Equal(t, "c", secondPage[0].Metadata.Name)
+		assert.Empty(t, next)
+
+		descending, _, err := clientInterface.Datasource("perses").ListPage("", v1.ListOptions{Sort: "-name"})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(descending))
+		assert.Equal(t, "c", descending[0].Metadata.Name)
+
+		_, _, err = clientInterface.Datasource("perses").ListPage("", v1.ListOptions{Sort: "unknown"})
+		assert.Error(t, err)
+		return entities
+	})
+}
+
+func TestListDatasourceLabelSelector(t *testing.T) {
+	withClient(t, func(clientInterface v1.ClientInterface, manager dependency.PersistenceManager) []modelAPI.Entity {
+		projectEntity := e2eframework.NewProject("perses")
+		entities := []modelAPI.Entity{projectEntity}
+		for name, team := range map[string]string{"prometheus": "sre", "tempo": "dev", "loki": ""} {
+			entity := e2eframework.NewDatasource(t, "perses", name)
+			if len(team) > 0 {
+				entity.Metadata.Labels = map[string]string{"team": team}
+			}
+			entities = append(entities, entity)
+		}
+		for _, entity := range entities {
+			e2eframework.CreateAndWaitUntilEntityExists(t, manager, entity)
+		}
+
+		sre, _, err := clientInterface.Datasource("perses").ListPage("", v1.ListOptions{LabelSelector: "team=sre"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(sre))
+		assert.Equal(t, "prometheus", sre[0].Metadata.Name)
+		assert.Equal(t, map[string]string{"team": "sre"}, sre[0].Metadata.Labels)
+
+		notSRE, _, err := clientInterface.Datasource("perses").ListPage("", v1.ListOptions{LabelSelector: "team notin (sre)", Sort: "name"})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(notSRE))
+		assert.Equal(t, "loki", notSRE[0].Metadata.Name)
+		assert.Equal(t, "tempo", notSRE[1].Metadata.Name)
+
+		_, _, err = clientInterface.Datasource("perses").ListPage("", v1.ListOptions{LabelSelector: "team in sre"})
+		assert.Error(t, err)
+
+		invalid := e2eframework.NewDatasource(t, "perses", "invalid")
+		invalid.Metadata.Labels = map[string]string{"team": "not valid"}
+		_, err = clientInterface.Datasource("perses").Create(invalid)
+		assert.Error(t, err)
+		return entities
+	})
+}
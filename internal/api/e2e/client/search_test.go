@@ -0,0 +1,60 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package client
+
+import (
+	"testing"
+
+	e2eframework "github.com/perses/perses/internal/api/e2e/framework"
+	"github.com/perses/perses/internal/api/shared/dependency"
+	"github.com/perses/perses/pkg/client/api/v1"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	withClient(t, func(clientInterface v1.ClientInterface, manager dependency.PersistenceManager) []modelAPI.Entity {
+		projectEntity := e2eframework.NewProject("perses")
+		e2eframework.CreateAndWaitUntilEntityExists(t, manager, projectEntity)
+		entity := e2eframework.NewDashboard(t, "perses", "node_exporter")
+		_, err := clientInterface.Dashboard("perses").Create(entity)
+		assert.NoError(t, err)
+
+		// the dashboard is found by the title of one of its panels, as soon as it is created.
+		results, err := clientInterface.Search().Search("ram used", v1.SearchOptions{})
+		assert.NoError(t, err)
+		if assert.Equal(t, 1, len(results)) {
+			assert.Equal(t, modelV1.KindDashboard, results[0].Kind)
+			assert.Equal(t, "perses", results[0].Project)
+			assert.Equal(t, "node_exporter", results[0].Name)
+		}
+
+		results, err = clientInterface.Search().Search("ram used", v1.SearchOptions{Project: "another"})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(results))
+
+		_, err = clientInterface.Search().Search("ram", v1.SearchOptions{Kind: modelV1.KindFolder})
+		assert.Error(t, err)
+
+		// and it is no longer found once it is deleted.
+		assert.NoError(t, clientInterface.Dashboard("perses").Delete("node_exporter"))
+		results, err = clientInterface.Search().Search("ram used", v1.SearchOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(results))
+		return []modelAPI.Entity{projectEntity}
+	})
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	e2eframework "github.com/perses/perses/internal/api/e2e/framework"
+	"github.com/perses/perses/internal/api/shared/dependency"
+	"github.com/perses/perses/pkg/client/api/v1"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func receive(t *testing.T, watcher *v1.Watcher[*modelV1.Project]) v1.Event[*modelV1.Project] {
+	select {
+	case event, isOpen := <-watcher.Events():
+		if !isOpen {
+			t.Fatalf("the watch has stopped: %v", watcher.Err())
+		}
+		return event
+	case <-time.After(10 * time.Second):
+		t.Fatal("no event received")
+		return v1.Event[*modelV1.Project]{}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	withClient(t, func(clientInterface v1.ClientInterface, manager dependency.PersistenceManager) []modelAPI.Entity {
+		existing := e2eframework.NewProject("perses")
+		e2eframework.CreateAndWaitUntilEntityExists(t, manager, existing)
+
+		watcher, err := clientInterface.Project().Watch(context.Background(), "perses", v1.ListOptions{})
+		if !assert.NoError(t, err) {
+			return []modelAPI.Entity{existing}
+		}
+		// the existing projects are received first
+		event := receive(t, watcher)
+		assert.Equal(t, modelV1.EventTypeAdded, event.Type)
+		assert.Equal(t, "perses", event.Object.Metadata.Name)
+
+		// then the changes, as long as the name matches the prefix
+		_, err = clientInterface.Project().Create(e2eframework.NewProject("another"))
+		assert.NoError(t, err)
+		created, err := clientInterface.Project().Create(e2eframework.NewProject("perses-dev"))
+		assert.NoError(t, err)
+		event = receive(t, watcher)
+		assert.Equal(t, modelV1.EventTypeAdded, event.Type)
+		assert.Equal(t, "perses-dev", event.Object.Metadata.Name)
+
+		_, err = clientInterface.Project().Update(created)
+		assert.NoError(t, err)
+		event = receive(t, watcher)
+		assert.Equal(t, modelV1.EventTypeModified, event.Type)
+		assert.Equal(t, uint64(2), event.Object.Metadata.Version)
+
+		assert.NoError(t, clientInterface.Project().Delete("perses-dev"))
+		event = receive(t, watcher)
+		assert.Equal(t, modelV1.EventTypeDeleted, event.Type)
+		assert.Equal(t, "perses-dev", event.Object.Metadata.Name)
+
+		watcher.Stop()
+		for range watcher.Events() {
+		}
+		assert.NoError(t, watcher.Err())
+
+		// a watch cannot be paginated
+		_, err = clientInterface.Project().Watch(context.Background(), "", v1.ListOptions{Limit: 1})
+		assert.Error(t, err)
+		return []modelAPI.Entity{existing, e2eframework.NewProject("another")}
+	})
+}
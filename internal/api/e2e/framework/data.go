@@ -32,6 +32,7 @@ import (
 	datasourceHTTP "github.com/perses/perses/pkg/model/api/v1/datasource/http"
 	"github.com/perses/perses/pkg/model/api/v1/secret"
 	"github.com/perses/perses/pkg/model/api/v1/variable"
+	"github.com/prometheus/common/model"
 )
 
 type GetFunc func() (api.Entity, error)
@@ -46,56 +47,112 @@ func CreateGetFunc(t *testing.T, persistenceManager dependency.PersistenceManage
 			return persistenceManager.GetProject().Get(entity.Metadata.Name)
 		}
 		upsertFunc = func() error {
-			return persistenceManager.GetProject().Update(entity)
+			return persistenceManager.GetPersesDAO().Upsert(entity)
 		}
 	case *v1.Datasource:
 		getFunc = func() (api.Entity, error) {
 			return persistenceManager.GetDatasource().Get(entity.Metadata.Project, entity.Metadata.Name)
 		}
 		upsertFunc = func() error {
-			return persistenceManager.GetDatasource().Update(entity)
+			return persistenceManager.GetPersesDAO().Upsert(entity)
 		}
 	case *v1.GlobalDatasource:
 		getFunc = func() (api.Entity, error) {
 			return persistenceManager.GetGlobalDatasource().Get(entity.Metadata.Name)
 		}
 		upsertFunc = func() error {
-			return persistenceManager.GetGlobalDatasource().Update(entity)
+			return persistenceManager.GetPersesDAO().Upsert(entity)
 		}
 	case *v1.Dashboard:
 		getFunc = func() (api.Entity, error) {
 			return persistenceManager.GetDashboard().Get(entity.Metadata.Project, entity.Metadata.Name)
 		}
 		upsertFunc = func() error {
-			return persistenceManager.GetDashboard().Update(entity)
+			return persistenceManager.GetPersesDAO().Upsert(entity)
 		}
 	case *v1.Variable:
 		getFunc = func() (api.Entity, error) {
 			return persistenceManager.GetVariable().Get(entity.Metadata.Project, entity.Metadata.Name)
 		}
 		upsertFunc = func() error {
-			return persistenceManager.GetVariable().Update(entity)
+			return persistenceManager.GetPersesDAO().Upsert(entity)
 		}
 	case *v1.GlobalVariable:
 		getFunc = func() (api.Entity, error) {
 			return persistenceManager.GetGlobalVariable().Get(entity.Metadata.Name)
 		}
 		upsertFunc = func() error {
-			return persistenceManager.GetGlobalVariable().Update(entity)
+			return persistenceManager.GetPersesDAO().Upsert(entity)
 		}
 	case *v1.Secret:
 		getFunc = func() (api.Entity, error) {
 			return persistenceManager.GetSecret().Get(entity.Metadata.Project, entity.Metadata.Name)
 		}
 		upsertFunc = func() error {
-			return persistenceManager.GetSecret().Update(entity)
+			return persistenceManager.GetPersesDAO().Upsert(entity)
 		}
 	case *v1.GlobalSecret:
 		getFunc = func() (api.Entity, error) {
 			return persistenceManager.GetGlobalSecret().Get(entity.Metadata.Name)
 		}
 		upsertFunc = func() error {
-			return persistenceManager.GetGlobalSecret().Update(entity)
+			return persistenceManager.GetPersesDAO().Upsert(entity)
+		}
+	case *v1.User:
+		getFunc = func() (api.Entity, error) {
+			return persistenceManager.GetUser().Get(entity.Metadata.Name)
+		}
+		upsertFunc = func() error {
+			return persistenceManager.GetPersesDAO().Upsert(entity)
+		}
+	case *v1.ServiceAccount:
+		getFunc = func() (api.Entity, error) {
+			return persistenceManager.GetServiceAccount().Get(entity.Metadata.Name)
+		}
+		upsertFunc = func() error {
+			return persistenceManager.GetPersesDAO().Upsert(entity)
+		}
+	case *v1.GlobalRole:
+		getFunc = func() (api.Entity, error) {
+			return persistenceManager.GetGlobalRole().Get(entity.Metadata.Name)
+		}
+		upsertFunc = func() error {
+			return persistenceManager.GetPersesDAO().Upsert(entity)
+		}
+	case *v1.GlobalRoleBinding:
+		getFunc = func() (api.Entity, error) {
+			return persistenceManager.GetGlobalRoleBinding().Get(entity.Metadata.Name)
+		}
+		upsertFunc = func() error {
+			return persistenceManager.GetPersesDAO().Upsert(entity)
+		}
+	case *v1.Role:
+		getFunc = func() (api.Entity, error) {
+			return persistenceManager.GetRole().Get(entity.Metadata.Project, entity.Metadata.Name)
+		}
+		upsertFunc = func() error {
+			return persistenceManager.GetPersesDAO().Upsert(entity)
+		}
+	case *v1.RoleBinding:
+		getFunc = func() (api.Entity, error) {
+			return persistenceManager.GetRoleBinding().Get(entity.Metadata.Project, entity.Metadata.Name)
+		}
+		upsertFunc = func() error {
+			return persistenceManager.GetPersesDAO().Upsert(entity)
+		}
+	case *v1.Webhook:
+		getFunc = func() (api.Entity, error) {
+			return persistenceManager.GetWebhook().Get(entity.Metadata.Project, entity.Metadata.Name)
+		}
+		upsertFunc = func() error {
+			return persistenceManager.GetPersesDAO().Upsert(entity)
+		}
+	case *v1.GlobalWebhook:
+		getFunc = func() (api.Entity, error) {
+			return persistenceManager.GetGlobalWebhook().Get(entity.Metadata.Name)
+		}
+		upsertFunc = func() error {
+			return persistenceManager.GetPersesDAO().Upsert(entity)
 		}
 	default:
 		t.Fatalf("%T is not managed", object)
@@ -359,3 +416,129 @@ func NewPublicGlobalSecret(name string) *v1.PublicGlobalSecret {
 	entity.Metadata.CreateNow()
 	return entity
 }
+
+// UserPassword is the password of the users returned by NewUser.
+const UserPassword = "f9bn6_Zq!pT3"
+
+func NewUser(name string) *v1.User {
+	entity := &v1.User{
+		Kind:     v1.KindUser,
+		Metadata: newMetadata(name),
+		Spec: v1.UserSpec{
+			FirstName:      "John",
+			LastName:       "Doe",
+			NativeProvider: v1.NativeProvider{Password: UserPassword},
+		},
+	}
+	entity.Metadata.CreateNow()
+	return entity
+}
+
+func NewPublicUser(name string) *v1.PublicUser {
+	return v1.NewPublicUser(NewUser(name))
+}
+
+func NewServiceAccount(name string) *v1.ServiceAccount {
+	entity := &v1.ServiceAccount{
+		Kind:     v1.KindServiceAccount,
+		Metadata: newMetadata(name),
+		Spec: v1.ServiceAccountSpec{
+			Description: "Used by the CI to deploy the dashboards",
+		},
+	}
+	entity.Metadata.CreateNow()
+	return entity
+}
+
+// newRoleSpec returns a spec allowing to read the dashboards.
+func newRoleSpec() v1.RoleSpec {
+	return v1.RoleSpec{
+		Permissions: []v1.Permission{
+			{
+				Actions: []v1.Action{v1.ActionRead},
+				Scopes:  []v1.Scope{v1.Scope(v1.KindDashboard)},
+			},
+		},
+	}
+}
+
+func NewRole(projectName string, name string) *v1.Role {
+	entity := &v1.Role{
+		Kind:     v1.KindRole,
+		Metadata: newProjectMetadata(projectName, name),
+		Spec:     newRoleSpec(),
+	}
+	entity.Metadata.CreateNow()
+	return entity
+}
+
+func NewGlobalRole(name string) *v1.GlobalRole {
+	entity := &v1.GlobalRole{
+		Kind:     v1.KindGlobalRole,
+		Metadata: newMetadata(name),
+		Spec:     newRoleSpec(),
+	}
+	entity.Metadata.CreateNow()
+	return entity
+}
+
+func newRoleBindingSpec(roleName string, login string) v1.RoleBindingSpec {
+	return v1.RoleBindingSpec{
+		Role:     roleName,
+		Subjects: []v1.Subject{{Kind: v1.SubjectKindUser, Name: login}},
+	}
+}
+
+// NewRoleBinding returns a binding of the user to the role. Both must exist to create it with the API.
+func NewRoleBinding(projectName string, name string, roleName string, login string) *v1.RoleBinding {
+	entity := &v1.RoleBinding{
+		Kind:     v1.KindRoleBinding,
+		Metadata: newProjectMetadata(projectName, name),
+		Spec:     newRoleBindingSpec(roleName, login),
+	}
+	entity.Metadata.CreateNow()
+	return entity
+}
+
+// NewGlobalRoleBinding returns a binding of the user to the global role. Both must exist to create it with the API.
+func NewGlobalRoleBinding(name string, roleName string, login string) *v1.GlobalRoleBinding {
+	entity := &v1.GlobalRoleBinding{
+		Kind:     v1.KindGlobalRoleBinding,
+		Metadata: newMetadata(name),
+		Spec:     newRoleBindingSpec(roleName, login),
+	}
+	entity.Metadata.CreateNow()
+	return entity
+}
+
+// newWebhookSpec returns a spec sending the changes of the dashboards to the URL, with the defaults set by the API.
+func newWebhookSpec(url string) v1.WebhookSpec {
+	return v1.WebhookSpec{
+		URL:         url,
+		Kinds:       []v1.Kind{v1.KindDashboard},
+		MaxAttempts: 5,
+		Backoff:     model.Duration(30 * time.Second),
+	}
+}
+
+// NewWebhook returns a webhook sending the changes of the dashboards of the project to the URL.
+func NewWebhook(projectName string, name string, url string) *v1.Webhook {
+	entity := &v1.Webhook{
+		Kind:     v1.KindWebhook,
+		Metadata: newProjectMetadata(projectName, name),
+		Spec:     newWebhookSpec(url),
+	}
+	entity.Metadata.CreateNow()
+	return entity
+}
+
+// NewGlobalWebhook returns a webhook sending the changes of the dashboards of every project to the URL.
+func NewGlobalWebhook(name string, url string) *v1.GlobalWebhook {
+	entity := &v1.GlobalWebhook{
+		Kind:     v1.KindGlobalWebhook,
+		Metadata: newMetadata(name),
+		Spec:     newWebhookSpec(url),
+	}
+	entity.Metadata.CreateNow()
+	return entity
+}
@@ -0,0 +1,311 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package e2eframework
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/perses/perses/internal/api/config"
+	promConfig "github.com/prometheus/common/config"
+)
+
+const (
+	MockClientID     = "perses"
+	MockClientSecret = "secret"
+	mockKeyID        = "mock"
+)
+
+// MockUser is the user logged in by the MockIdentityProvider.
+type MockUser struct {
+	Subject string
+	Login   string
+	Groups  []string
+}
+
+type mockGrant struct {
+	user          MockUser
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+}
+
+type mockDevice struct {
+	userCode string
+	user     *MockUser
+}
+
+// MockIdentityProvider is an OpenID Connect provider, also usable as an OAuth 2.0 provider, logging in the current user without asking anything.
+// It implements the authorization code flow with PKCE, and the device authorization flow where the devices are approved by ApproveDevice.
+type MockIdentityProvider struct {
+	server       *httptest.Server
+	key          *rsa.PrivateKey
+	mutex        sync.Mutex
+	user         MockUser
+	codes        map[string]*mockGrant
+	devices      map[string]*mockDevice
+	accessTokens map[string]MockUser
+}
+
+func NewMockIdentityProvider() *MockIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	m := &MockIdentityProvider{
+		key:          key,
+		codes:        make(map[string]*mockGrant),
+		devices:      make(map[string]*mockDevice),
+		accessTokens: make(map[string]MockUser),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("/keys", m.keys)
+	mux.HandleFunc("/authorize", m.authorize)
+	mux.HandleFunc("/token", m.token)
+	mux.HandleFunc("/device/code", m.deviceCode)
+	mux.HandleFunc("/userinfo", m.userInfo)
+	m.server = httptest.NewServer(mux)
+	return m
+}
+
+func (m *MockIdentityProvider) Close() {
+	m.server.Close()
+}
+
+// Issuer returns the URL of the provider.
+func (m *MockIdentityProvider) Issuer() string {
+	return m.server.URL
+}
+
+// SetUser changes the user logged in from now on.
+func (m *MockIdentityProvider) SetUser(user MockUser) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.user = user
+}
+
+// ApproveDevice logs in the current user on the device having the user code. It returns false if the code is unknown.
+func (m *MockIdentityProvider) ApproveDevice(userCode string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, device := range m.devices {
+		if device.userCode == userCode {
+			user := m.user
+			device.user = &user
+			return true
+		}
+	}
+	return false
+}
+
+// OIDCProvider returns the configuration of the provider used as an OpenID Connect provider.
+func (m *MockIdentityProvider) OIDCProvider(slugID string) config.OIDCProvider {
+	return config.OIDCProvider{
+		SlugID:       slugID,
+		ClientID:     MockClientID,
+		ClientSecret: promConfig.Secret(MockClientSecret),
+		Issuer:       m.Issuer(),
+	}
+}
+
+// OAuthProvider returns the configuration of the provider used as an OAuth 2.0 provider.
+func (m *MockIdentityProvider) OAuthProvider(slugID string) config.OAuthProvider {
+	return config.OAuthProvider{
+		SlugID:        slugID,
+		ClientID:      MockClientID,
+		ClientSecret:  promConfig.Secret(MockClientSecret),
+		AuthURL:       m.Issuer() + "/authorize",
+		TokenURL:      m.Issuer() + "/token",
+		UserInfoURL:   m.Issuer() + "/userinfo",
+		DeviceAuthURL: m.Issuer() + "/device/code",
+		GroupsClaim:   "groups",
+	}
+}
+
+func (m *MockIdentityProvider) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                        m.Issuer(),
+		"authorization_endpoint":        m.Issuer() + "/authorize",
+		"token_endpoint":                m.Issuer() + "/token",
+		"userinfo_endpoint":             m.Issuer() + "/userinfo",
+		"jwks_uri":                      m.Issuer() + "/keys",
+		"device_authorization_endpoint": m.Issuer() + "/device/code",
+	})
+}
+
+func (m *MockIdentityProvider) keys(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": mockKeyID,
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (m *MockIdentityProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI := query.Get("redirect_uri")
+	if query.Get("client_id") != MockClientID || query.Get("response_type") != "code" || len(redirectURI) == 0 ||
+		query.Get("code_challenge_method") != "S256" || len(query.Get("code_challenge")) == 0 {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	code := randomCode()
+	m.mutex.Lock()
+	m.codes[code] = &mockGrant{user: m.user, redirectURI: redirectURI, codeChallenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	m.mutex.Unlock()
+	callback, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	callbackQuery := callback.Query()
+	callbackQuery.Set("code", code)
+	callbackQuery.Set("state", query.Get("state"))
+	callback.RawQuery = callbackQuery.Encode()
+	http.Redirect(w, r, callback.String(), http.StatusFound)
+}
+
+func (m *MockIdentityProvider) deviceCode(w http.ResponseWriter, r *http.Request) {
+	if r.PostFormValue("client_id") != MockClientID || r.PostFormValue("client_secret") != MockClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	deviceCode := randomCode()
+	userCode := strings.ToUpper(randomCode()[:8])
+	m.mutex.Lock()
+	m.devices[deviceCode] = &mockDevice{userCode: userCode}
+	m.mutex.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"device_code":      deviceCode,
+		"user_code":        userCode,
+		"verification_uri": m.Issuer() + "/device",
+		"expires_in":       60,
+		"interval":         1,
+	})
+}
+
+func (m *MockIdentityProvider) token(w http.ResponseWriter, r *http.Request) {
+	if r.PostFormValue("client_id") != MockClientID || r.PostFormValue("client_secret") != MockClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	switch r.PostFormValue("grant_type") {
+	case "authorization_code":
+		grant, exists := m.codes[r.PostFormValue("code")]
+		delete(m.codes, r.PostFormValue("code"))
+		sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if !exists || grant.redirectURI != r.PostFormValue("redirect_uri") || grant.codeChallenge != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		m.writeTokens(w, grant.user, grant.nonce)
+	case "urn:ietf:params:oauth:grant-type:device_code":
+		device, exists := m.devices[r.PostFormValue("device_code")]
+		if !exists {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "expired_token"})
+			return
+		}
+		if device.user == nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "authorization_pending"})
+			return
+		}
+		delete(m.devices, r.PostFormValue("device_code"))
+		m.writeTokens(w, *device.user, "")
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+	}
+}
+
+// writeTokens must be called with the lock held.
+func (m *MockIdentityProvider) writeTokens(w http.ResponseWriter, user MockUser, nonce string) {
+	claims := jwt.MapClaims{
+		"iss":                m.Issuer(),
+		"sub":                user.Subject,
+		"aud":                MockClientID,
+		"iat":                time.Now().Unix(),
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"preferred_username": user.Login,
+		"groups":             user.Groups,
+	}
+	if len(nonce) > 0 {
+		claims["nonce"] = nonce
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = mockKeyID
+	idToken, err := token.SignedString(m.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	accessToken := randomCode()
+	m.accessTokens[accessToken] = user
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (m *MockIdentityProvider) userInfo(w http.ResponseWriter, r *http.Request) {
+	m.mutex.Lock()
+	user, exists := m.accessTokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	m.mutex.Unlock()
+	if !exists {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"sub":                user.Subject,
+		"login":              user.Login,
+		"preferred_username": user.Login,
+		"groups":             user.Groups,
+	})
+}
+
+func randomCode() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("%x", b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
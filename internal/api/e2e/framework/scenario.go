@@ -147,9 +147,47 @@ func WriteTestScenario(t *testing.T, path string, creator func(name string) mode
 		})
 	})
 
+	t.Run(fmt.Sprintf("Patch test (%s)", path), func(t *testing.T) {
+		WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []modelAPI.Entity {
+			entity := creator("myResource")
+			CreateAndWaitUntilEntityExists(t, manager, entity)
+			PatchLabelsScenario(expect, fmt.Sprintf("%s/%s/%s", shared.APIV1Prefix, path, entity.GetMetadata().GetName()))
+			return []modelAPI.Entity{entity}
+		})
+	})
+
 	DeleteTestScenario(t, path, creator)
 }
 
+// PatchLabelsScenario adds a label to the resource with a merge patch, and then removes it with a JSON patch.
+func PatchLabelsScenario(expect *httpexpect.Expect, resourcePath string) {
+	expect.PATCH(resourcePath).
+		WithHeader("Content-Type", string(modelAPI.MergePatchType)).
+		WithBytes([]byte(`{"metadata":{"labels":{"team":"perses"}}}`)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Path("$.metadata.labels.team").IsEqual("perses")
+
+	// the test operation fails, so the labels are not removed.
+	expect.PATCH(resourcePath).
+		WithHeader("Content-Type", string(modelAPI.JSONPatchType)).
+		WithBytes([]byte(`[{"op":"test","path":"/metadata/labels/team","value":"other"},{"op":"remove","path":"/metadata/labels"}]`)).
+		Expect().
+		Status(http.StatusConflict)
+	expect.PATCH(resourcePath).
+		WithHeader("Content-Type", string(modelAPI.JSONPatchType)).
+		WithBytes([]byte(`[{"op":"test","path":"/metadata/labels/team","value":"perses"},{"op":"remove","path":"/metadata/labels"}]`)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Path("$.metadata").Object().NotContainsKey("labels")
+
+	// a whole document is not a patch.
+	expect.PATCH(resourcePath).
+		WithJSON(map[string]interface{}{}).
+		Expect().
+		Status(http.StatusUnsupportedMediaType)
+}
+
 func MainTestScenario(t *testing.T, path string, creator func(name string) modelAPI.Entity) {
 
 	WriteTestScenario(t, path, creator)
@@ -311,6 +349,15 @@ func WriteTestScenarioWithProject(t *testing.T, path string, creator func(projec
 		})
 	})
 
+	t.Run(fmt.Sprintf("Patch test (%s)", path), func(t *testing.T) {
+		WithServer(t, func(expect *httpexpect.Expect, manager dependency.PersistenceManager) []modelAPI.Entity {
+			parent, entity := creator("myProject", "myResource")
+			CreateAndWaitUntilEntitiesExist(t, manager, parent, entity)
+			PatchLabelsScenario(expect, fmt.Sprintf("%s/%s/%s/%s/%s", shared.APIV1Prefix, shared.PathProject, parent.GetMetadata().GetName(), path, entity.GetMetadata().GetName()))
+			return []modelAPI.Entity{parent, entity}
+		})
+	})
+
 	DeleteTestScenarioWithProject(t, path, creator)
 }
 
@@ -21,10 +21,12 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/gavv/httpexpect/v2"
 	"github.com/perses/perses/internal/api/config"
 	"github.com/perses/perses/internal/api/core"
+	"github.com/perses/perses/internal/api/interface/v1/dashboard"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
 	"github.com/perses/perses/internal/api/shared/dependency"
 	"github.com/perses/perses/internal/test"
@@ -32,9 +34,13 @@ import (
 	modelV1 "github.com/perses/perses/pkg/model/api/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	promConfig "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
 )
 
 var useSQL = os.Getenv("PERSES_TEST_USE_SQL")
+var usePostgres = os.Getenv("PERSES_TEST_USE_POSTGRES")
+var useSQLite = os.Getenv("PERSES_TEST_USE_SQLITE")
+var useCache = os.Getenv("PERSES_TEST_USE_CACHE")
 
 func ClearAllKeys(t *testing.T, dao databaseModel.DAO, entities ...modelAPI.Entity) {
 	for _, entity := range entities {
@@ -42,6 +48,12 @@ func ClearAllKeys(t *testing.T, dao databaseModel.DAO, entities ...modelAPI.Enti
 		if err != nil {
 			t.Fatal(err)
 		}
+		if d, ok := entity.(*modelV1.Dashboard); ok {
+			// remove the revisions saved by the API, so they don't leak from one test to another.
+			if revisionErr := dao.DeleteByQuery(&dashboard.RevisionQuery{Project: d.Metadata.Project, Name: d.Metadata.Name}); revisionErr != nil {
+				t.Fatal(revisionErr)
+			}
+		}
 	}
 }
 
@@ -53,6 +65,90 @@ func defaultFileConfig() *config.File {
 }
 
 func CreateServer(t *testing.T) (*httptest.Server, *httpexpect.Expect, dependency.PersistenceManager) {
+	return createServer(t, defaultConfig())
+}
+
+// CreateServerWithAuthentication creates a server rejecting the requests that are not authenticated. The sign-up is enabled.
+func CreateServerWithAuthentication(t *testing.T) (*httptest.Server, *httpexpect.Expect, dependency.PersistenceManager) {
+	conf := defaultConfig()
+	conf.Authentication = config.Authentication{
+		Enable:          true,
+		AccessTokenTTL:  model.Duration(time.Minute),
+		RefreshTokenTTL: model.Duration(time.Hour),
+		APITokenMaxTTL:  model.Duration(24 * time.Hour),
+	}
+	return createServer(t, conf)
+}
+
+// CreateServerWithAuthorization is like CreateServerWithAuthentication, with the authorization enabled.
+// The user "admin" has every permission.
+func CreateServerWithAuthorization(t *testing.T) (*httptest.Server, *httpexpect.Expect, dependency.PersistenceManager) {
+	conf := defaultConfig()
+	conf.Authentication = config.Authentication{
+		Enable:          true,
+		AccessTokenTTL:  model.Duration(time.Minute),
+		RefreshTokenTTL: model.Duration(time.Hour),
+		APITokenMaxTTL:  model.Duration(24 * time.Hour),
+	}
+	conf.Authorization = config.Authorization{
+		Enable:          true,
+		Admins:          []string{"admin"},
+		RefreshInterval: model.Duration(time.Minute),
+	}
+	return createServer(t, conf)
+}
+
+// CreateServerWithAuthProviders is like CreateServerWithAuthorization, with the users able to log in with the identity providers.
+func CreateServerWithAuthProviders(t *testing.T, providers config.AuthProviders) (*httptest.Server, *httpexpect.Expect, dependency.PersistenceManager) {
+	// the defaults of the providers are set by the verification of the configuration.
+	for i := range providers.OIDC {
+		if err := providers.OIDC[i].Verify(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range providers.OAuth {
+		if err := providers.OAuth[i].Verify(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conf := defaultConfig()
+	conf.Authentication = config.Authentication{
+		Enable:          true,
+		AccessTokenTTL:  model.Duration(time.Minute),
+		RefreshTokenTTL: model.Duration(time.Hour),
+		APITokenMaxTTL:  model.Duration(24 * time.Hour),
+		Providers:       providers,
+	}
+	conf.Authorization = config.Authorization{
+		Enable:          true,
+		Admins:          []string{"admin"},
+		RefreshInterval: model.Duration(time.Minute),
+	}
+	return createServer(t, conf)
+}
+
+// CreateServerWithAudit is like CreateServerWithAuthorization, with the changes recorded in the database.
+func CreateServerWithAudit(t *testing.T) (*httptest.Server, *httpexpect.Expect, dependency.PersistenceManager) {
+	conf := defaultConfig()
+	conf.Authentication = config.Authentication{
+		Enable:          true,
+		AccessTokenTTL:  model.Duration(time.Minute),
+		RefreshTokenTTL: model.Duration(time.Hour),
+		APITokenMaxTTL:  model.Duration(24 * time.Hour),
+	}
+	conf.Authorization = config.Authorization{
+		Enable:          true,
+		Admins:          []string{"admin"},
+		RefreshInterval: model.Duration(time.Minute),
+	}
+	conf.Audit = config.Audit{
+		Enable: true,
+		Sink:   config.AuditSinkDatabase,
+	}
+	return createServer(t, conf)
+}
+
+func defaultConfig() config.Config {
 	projectPath := test.GetRepositoryPath()
 	conf := config.Config{
 		EncryptionKey: promConfig.Secret(hex.EncodeToString([]byte("=tW$56zytgB&3jN2E%7-+qrGZE?v6LCc"))),
@@ -75,11 +171,40 @@ func CreateServer(t *testing.T) (*httptest.Server, *httpexpect.Expect, dependenc
 				AllowNativePasswords: true,
 			},
 		}
+	} else if usePostgres == "true" {
+		conf.Database = config.Database{
+			Postgres: &config.Postgres{
+				User:     "user",
+				Password: "password",
+				Addr:     "localhost:5432",
+				DBName:   "perses",
+				Schema:   "public",
+				SSLMode:  "disable",
+			},
+		}
+	} else if useSQLite == "true" {
+		conf.Database = config.Database{
+			SQLite: &config.SQLite{
+				Path:        "./test/perses.db",
+				BusyTimeout: 5 * time.Second,
+			},
+		}
 	} else {
 		conf.Database = config.Database{
 			File: defaultFileConfig(),
 		}
 	}
+	if useCache == "true" {
+		conf.Database.Cache = config.Cache{
+			Enable:     true,
+			TTL:        model.Duration(time.Minute),
+			MaxEntries: 1000,
+		}
+	}
+	return conf
+}
+
+func createServer(t *testing.T, conf config.Config) (*httptest.Server, *httpexpect.Expect, dependency.PersistenceManager) {
 	runner, persistenceManager, err := core.New(conf, "")
 	if err != nil {
 		t.Fatal(err)
@@ -96,7 +221,34 @@ func CreateServer(t *testing.T) (*httptest.Server, *httpexpect.Expect, dependenc
 }
 
 func WithServer(t *testing.T, testFunc func(*httpexpect.Expect, dependency.PersistenceManager) []modelAPI.Entity) {
-	server, expect, persistenceManager := CreateServer(t)
+	withServer(t, CreateServer, testFunc)
+}
+
+// WithServerAndAuthentication is like WithServer, with a server created by CreateServerWithAuthentication.
+func WithServerAndAuthentication(t *testing.T, testFunc func(*httpexpect.Expect, dependency.PersistenceManager) []modelAPI.Entity) {
+	withServer(t, CreateServerWithAuthentication, testFunc)
+}
+
+// WithServerAndAuthorization is like WithServer, with a server created by CreateServerWithAuthorization.
+func WithServerAndAuthorization(t *testing.T, testFunc func(*httpexpect.Expect, dependency.PersistenceManager) []modelAPI.Entity) {
+	withServer(t, CreateServerWithAuthorization, testFunc)
+}
+
+// WithServerAndAuthProviders is like WithServer, with a server created by CreateServerWithAuthProviders.
+func WithServerAndAuthProviders(t *testing.T, providers config.AuthProviders, testFunc func(*httpexpect.Expect, dependency.PersistenceManager) []modelAPI.Entity) {
+	withServer(t, func(t *testing.T) (*httptest.Server, *httpexpect.Expect, dependency.PersistenceManager) {
+		return CreateServerWithAuthProviders(t, providers)
+	}, testFunc)
+}
+
+// WithServerAndAudit is like WithServer, with a server created by CreateServerWithAudit.
+func WithServerAndAudit(t *testing.T, testFunc func(*httpexpect.Expect, dependency.PersistenceManager) []modelAPI.Entity) {
+	withServer(t, CreateServerWithAudit, testFunc)
+}
+
+func withServer(t *testing.T, createServer func(*testing.T) (*httptest.Server, *httpexpect.Expect, dependency.PersistenceManager),
+	testFunc func(*httpexpect.Expect, dependency.PersistenceManager) []modelAPI.Entity) {
+	server, expect, persistenceManager := createServer(t)
 	defer persistenceManager.GetPersesDAO().Close()
 	defer server.Close()
 	entities := testFunc(expect, persistenceManager)
@@ -80,9 +80,11 @@ func (e *Endpoint) RegisterRoutes(g *echo.Group) {
 {{ if $endpoint.IsProjectResource -}}
 		subGroup.POST("", e.Create)
 		subGroup.PUT(fmt.Sprintf("/:%s", shared.ParamName), e.Update)
+		subGroup.PATCH(fmt.Sprintf("/:%s", shared.ParamName), e.Patch)
 		subGroup.DELETE(fmt.Sprintf("/:%s", shared.ParamName), e.Delete)
 {{- else -}}
 		group.PUT(fmt.Sprintf("/:%s", shared.ParamName), e.Update)
+		group.PATCH(fmt.Sprintf("/:%s", shared.ParamName), e.Patch)
 		group.DELETE(fmt.Sprintf("/:%s", shared.ParamName), e.Delete)
 {{- end }}
 	}
@@ -105,6 +107,11 @@ func (e *Endpoint) Update(ctx echo.Context) error {
 	return e.toolbox.Update(ctx, entity)
 }
 
+func (e *Endpoint) Patch(ctx echo.Context) error {
+	entity := &v1.{{ $kind }}{}
+	return e.toolbox.Patch(ctx, entity)
+}
+
 func (e *Endpoint) Delete(ctx echo.Context) error {
 	return e.toolbox.Delete(ctx)
 }
@@ -142,6 +149,8 @@ func (e *Endpoint) List(ctx echo.Context) error {
 package {{ $package }}
 
 import (
+	"context"
+
 	"github.com/perses/perses/internal/api/shared"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
@@ -149,6 +158,10 @@ import (
 
 type Query struct {
 	databaseModel.Query
+	// Pagination contains the parameters to sort the list and to get only a page of it.
+	databaseModel.Pagination
+	// LabelSelection contains the label selector used to filter the list.
+	databaseModel.LabelSelection
 	// NamePrefix is a prefix of the {{ $kind }}.metadata.name that is used to filter the list of the {{ $kind }}.
 	// NamePrefix can be empty in case you want to return the full list of {{ $kind }} available.
 	NamePrefix string {{ tag "query:\"name\"" }}
@@ -165,7 +178,7 @@ func (q *Query) Build() (string, error) {
 
 type DAO interface {
 	Create(entity *v1.{{ $kind }}) error
-	Update(entity *v1.{{ $kind }}) error
+	Update(entity *v1.{{ $kind }}, expectedVersion uint64) error
 {{ if $endpoint.IsProjectResource -}}
 	Delete(project string, name string) error
 	Get(project string, name string) (*v1.{{ $kind }}, error)
@@ -174,6 +187,8 @@ type DAO interface {
 	Get(name string) (*v1.{{ $kind }}, error)
 {{- end }}
 	List(q databaseModel.Query) ([]*v1.{{ $kind }}, error)
+	// Watch returns the changes of the {{ $kind }} matching the query, made from now on.
+	Watch(ctx context.Context, q *Query) (<-chan *v1.WatchEvent, error)
 }
 
 type Service interface {
@@ -201,8 +216,11 @@ type Service interface {
 package {{ $package }}
 
 import (
+	"context"
+
 	"github.com/perses/perses/internal/api/interface/v1/{{ $package }}"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelAPI "github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
@@ -223,8 +241,8 @@ func (d *dao) Create(entity *v1.{{ $kind }}) error {
 	return d.client.Create(entity)
 }
 
-func (d *dao) Update(entity *v1.{{ $kind }}) error {
-	return d.client.Upsert(entity)
+func (d *dao) Update(entity *v1.{{ $kind }}, expectedVersion uint64) error {
+	return d.client.Update(entity, expectedVersion)
 }
 
 func (d *dao) Delete({{- if $endpoint.IsProjectResource -}}project string,{{- end -}} name string) error {
@@ -256,6 +274,19 @@ func (d *dao) List(q databaseModel.Query) ([]*v1.{{ $kind }}, error) {
 	return result, err
 }
 
+func (d *dao) Watch(ctx context.Context, q *{{ $package }}.Query) (<-chan *v1.WatchEvent, error) {
+	filter := databaseModel.WatchFilter{
+{{ if $endpoint.IsProjectResource -}}
+		Project:        q.Project,
+{{ end -}}
+		NamePrefix:     q.NamePrefix,
+		LabelSelection: q.LabelSelection,
+	}
+	return databaseModel.Watch(ctx, d.client, d.kind, filter, func() modelAPI.Entity {
+		return &v1.{{ $kind }}{}
+	})
+}
+
 `))
 	clientTemplate = template.Must(
 		template.New("interface").Funcs(tplFunc).Parse(`{{- $endpoint := . -}}
@@ -280,7 +311,11 @@ func (d *dao) List(q databaseModel.Query) ([]*v1.{{ $kind }}, error) {
 package v1
 
 import (
+	"bytes"
+	"context"
+
 	"github.com/perses/perses/pkg/client/perseshttp"
+	"github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
@@ -289,6 +324,9 @@ const {{ unTitle $kind }}Resource = "{{ $plural }}"
 type {{ $kind }}Interface interface {
 	Create(entity *v1.{{ $kind }}) (*v1.{{ $kind }}, error)
 	Update(entity *v1.{{ $kind }}) (*v1.{{ $kind }}, error)
+	// Patch applies the patch to the {{ $kind }} named name and returns the result.
+	// The patch is either a JSON merge patch or a JSON patch, depending on patchType.
+	Patch(name string, patchType api.PatchType, patch []byte) (*v1.{{ $kind }}, error)
 	Delete(name string) error
 	// Get is returning an unique {{ $kind }}.
 	// As such name is the exact value of {{ $kind }}.metadata.name. It cannot be empty.
@@ -297,6 +335,12 @@ type {{ $kind }}Interface interface {
 	// prefix is a prefix of the {{ $kind }}.metadata.name to search for.
 	// It can be empty in case you want to get the full list of {{ $kind }} available
 	List(prefix string) ([]*v1.{{ $kind }}, error)
+	// ListPage returns the page of the list of {{ $kind }} described by the options.
+	// It returns as well the token to get the next page. The token is empty when there is no more page.
+	ListPage(prefix string, options ListOptions) ([]*v1.{{ $kind }}, string, error)
+	// Watch returns the changes of the {{ $kind }} whose name starts with the prefix. The existing {{ $kind }} are received first, as ADDED events.
+	// The options Limit and Continue cannot be used. The watch stops when ctx is done or when Watcher.Stop is called.
+	Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.{{ $kind }}], error)
 }
 
 type {{ unTitle $kind }} struct {
@@ -343,6 +387,20 @@ func (c *{{ unTitle $kind }}) Update(entity *v1.{{ $kind }}) (*v1.{{ $kind }}, e
 	return result, err
 }
 
+func (c *{{ unTitle $kind }}) Patch(name string, patchType api.PatchType, patch []byte) (*v1.{{ $kind }}, error) {
+	result := &v1.{{ $kind }}{}
+	err := c.client.Patch().
+		Resource({{ unTitle $kind }}Resource).
+		Name(name).
+{{ if $endpoint.IsProjectResource -}}
+		Project(c.project).
+{{- end }}
+		RawBody(bytes.NewReader(patch), string(patchType)).
+		Do().
+		Object(result)
+	return result, err
+}
+
 func (c *{{ unTitle $kind }}) Delete(name string) error {
 	return c.client.Delete().
 		Resource({{ unTitle $kind }}Resource).
@@ -382,6 +440,38 @@ func (c *{{ unTitle $kind }}) List(prefix string) ([]*v1.{{ $kind }}, error) {
 	return result, err
 }
 
+func (c *{{ unTitle $kind }}) ListPage(prefix string, options ListOptions) ([]*v1.{{ $kind }}, string, error) {
+	var result []*v1.{{ $kind }}
+	response := c.client.Get().
+		Resource({{ unTitle $kind }}Resource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+		}).
+{{ if $endpoint.IsProjectResource -}}
+		Project(c.project).
+{{- end }}
+		Do()
+	err := response.Object(&result)
+	return result, response.Header().Get(HeaderContinue), err
+}
+
+func (c *{{ unTitle $kind }}) Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.{{ $kind }}], error) {
+	request := c.client.Get().
+		Resource({{ unTitle $kind }}Resource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+			watch:   true,
+		})
+{{- if $endpoint.IsProjectResource }}.
+		Project(c.project)
+{{- end }}
+	return watch(ctx, request, func() *v1.{{ $kind }} {
+		return &v1.{{ $kind }}{}
+	})
+}
+
 `))
 )
 
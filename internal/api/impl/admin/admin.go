@@ -0,0 +1,81 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/backup"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// HeaderPassphrase is the header of the request containing the passphrase used to encrypt or to decrypt the secrets of a backup.
+// It is not a query parameter, so it doesn't appear in the logs.
+const HeaderPassphrase = "X-Backup-Passphrase"
+
+// Endpoint is the struct that define all endpoint delivered by the path /admin
+type Endpoint struct {
+	backupService backup.Backup
+	readonly      bool
+}
+
+// New create an instance of the object Endpoint.
+// You should have at most one instance of this object as it is only used by the struct api in the method api.registerRoute
+func New(backupService backup.Backup, readonly bool) *Endpoint {
+	return &Endpoint{
+		backupService: backupService,
+		readonly:      readonly,
+	}
+}
+
+// RegisterRoutes is the method to use to register the routes prefixed by /api
+func (e *Endpoint) RegisterRoutes(g *echo.Group) {
+	group := g.Group("/admin")
+	group.GET("/export", e.Export)
+	if !e.readonly {
+		group.POST("/import", e.Import)
+	}
+}
+
+// Export streams an archive containing every resource of the server.
+func (e *Endpoint) Export(ctx echo.Context) error {
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderContentType, "application/gzip")
+	header.Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("perses-backup-%s.ndjson.gz", time.Now().UTC().Format("20060102-150405"))))
+	if err := e.backupService.Export(ctx.Response(), ctx.Request().Header.Get(HeaderPassphrase)); err != nil {
+		if ctx.Response().Committed {
+			// the archive is partially sent, it will be rejected when restored as its footer is missing.
+			return nil
+		}
+		header.Del(echo.HeaderContentDisposition)
+		return err
+	}
+	return nil
+}
+
+// Import restores the resources of the archive sent in the body of the request.
+func (e *Endpoint) Import(ctx echo.Context) error {
+	summary, err := e.backupService.Import(ctx.Request().Body, ctx.Request().Header.Get(HeaderPassphrase), func(action v1.Action, oldEntity interface{}, newEntity interface{}) {
+		shared.AuditChange(ctx, action, oldEntity, newEntity)
+		shared.NotifyChange(ctx, action, oldEntity, newEntity)
+	})
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, summary)
+}
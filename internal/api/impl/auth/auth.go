@@ -0,0 +1,254 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/authentication"
+	"github.com/perses/perses/internal/api/shared/oauth"
+	"github.com/perses/perses/pkg/model/api"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	paramKind   = "kind"
+	paramSlugID = "slugID"
+	// stateCookieName is the cookie keeping the state of the authorization code flow, between the login and the callback.
+	stateCookieName = "perses_oauth_state"
+	stateTTL        = 10 * time.Minute
+)
+
+// authState is kept in a cookie during the authorization code flow, so the callback can verify it comes from the browser that started the login.
+type authState struct {
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"codeVerifier"`
+}
+
+// Endpoint is the struct that define all endpoint delivered by the path /auth
+type Endpoint struct {
+	authenticationService authentication.Authentication
+	providers             oauth.Providers
+}
+
+// New create an instance of the object Endpoint.
+// You should have at most one instance of this object as it is only used by the struct api in the method api.registerRoute
+func New(authenticationService authentication.Authentication, providers oauth.Providers) *Endpoint {
+	return &Endpoint{
+		authenticationService: authenticationService,
+		providers:             providers,
+	}
+}
+
+// RegisterRoutes is the method to use to register the routes prefixed by /api
+func (e *Endpoint) RegisterRoutes(g *echo.Group) {
+	group := g.Group("/auth")
+	group.POST("/login", e.Login)
+	group.POST("/refresh", e.Refresh)
+	group.GET("/providers", e.ListProviders)
+	providerGroup := group.Group(fmt.Sprintf("/providers/:%s/:%s", paramKind, paramSlugID))
+	providerGroup.GET("/login", e.ProviderLogin)
+	providerGroup.GET("/callback", e.ProviderCallback)
+	providerGroup.POST("/device/code", e.DeviceCode)
+	providerGroup.POST("/device/token", e.DeviceAccessToken)
+}
+
+// Login returns the tokens of the user whose credentials are sent in the body of the request.
+func (e *Endpoint) Login(ctx echo.Context) error {
+	body := &api.Auth{}
+	if err := ctx.Bind(body); err != nil {
+		return shared.HandleBadRequestError(err.Error())
+	}
+	response, err := e.authenticationService.Login(body)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// Refresh returns a new access token for the refresh token sent in the body of the request.
+func (e *Endpoint) Refresh(ctx echo.Context) error {
+	body := &api.RefreshRequest{}
+	if err := ctx.Bind(body); err != nil {
+		return shared.HandleBadRequestError(err.Error())
+	}
+	response, err := e.authenticationService.Refresh(body.RefreshToken)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// ListProviders returns the identity providers the users can log in with.
+func (e *Endpoint) ListProviders(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, e.providers.List())
+}
+
+// ProviderLogin redirects the user to the provider to log in, with the authorization code flow protected by PKCE.
+func (e *Endpoint) ProviderLogin(ctx echo.Context) error {
+	provider, err := e.getProvider(ctx)
+	if err != nil {
+		return err
+	}
+	state := &authState{}
+	for _, value := range []*string{&state.State, &state.Nonce, &state.CodeVerifier} {
+		if *value, err = oauth.RandomString(); err != nil {
+			logrus.WithError(err).Error("unable to generate the state of the login")
+			return shared.InternalError
+		}
+	}
+	authCodeURL, err := provider.AuthCodeURL(ctx.Request().Context(), redirectURI(ctx, provider), state.State, state.Nonce, state.CodeVerifier)
+	if err != nil {
+		logrus.WithError(err).Errorf("unable to start the login with the provider %q", provider.Info().SlugID)
+		return echo.NewHTTPError(http.StatusBadGateway, "the identity provider is unavailable")
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	ctx.SetCookie(stateCookie(ctx, base64.RawURLEncoding.EncodeToString(data), int(stateTTL.Seconds())))
+	return ctx.Redirect(http.StatusFound, authCodeURL)
+}
+
+// ProviderCallback is where the provider redirects the user once logged in. It returns the tokens of the user, like Login does.
+func (e *Endpoint) ProviderCallback(ctx echo.Context) error {
+	provider, err := e.getProvider(ctx)
+	if err != nil {
+		return err
+	}
+	if errorCode := ctx.QueryParam("error"); len(errorCode) > 0 {
+		return shared.HandleUnauthorizedError(fmt.Sprintf("the provider returned the error %q: %s", errorCode, ctx.QueryParam("error_description")))
+	}
+	cookie, err := ctx.Cookie(stateCookieName)
+	if err != nil {
+		return shared.HandleBadRequestError("the state of the login is missing, it may have expired")
+	}
+	// the state can only be used once.
+	ctx.SetCookie(stateCookie(ctx, "", -1))
+	state := &authState{}
+	data, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return shared.HandleBadRequestError("invalid state of the login")
+	}
+	if unmarshalErr := json.Unmarshal(data, state); unmarshalErr != nil {
+		return shared.HandleBadRequestError("invalid state of the login")
+	}
+	if len(state.State) == 0 || subtle.ConstantTimeCompare([]byte(state.State), []byte(ctx.QueryParam("state"))) != 1 {
+		return shared.HandleBadRequestError("the state sent by the provider doesn't match the one of the login")
+	}
+	code := ctx.QueryParam("code")
+	if len(code) == 0 {
+		return shared.HandleBadRequestError("the code sent by the provider is missing")
+	}
+	identity, err := provider.Exchange(ctx.Request().Context(), redirectURI(ctx, provider), code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		logrus.WithError(err).Warningf("unable to authenticate a user with the provider %q", provider.Info().SlugID)
+		return shared.HandleUnauthorizedError(err.Error())
+	}
+	response, err := e.authenticationService.ExternalLogin(identity)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// DeviceCode starts the device authorization flow, used by percli to log in with the provider.
+func (e *Endpoint) DeviceCode(ctx echo.Context) error {
+	provider, err := e.getProvider(ctx)
+	if err != nil {
+		return err
+	}
+	response, err := provider.DeviceCode(ctx.Request().Context())
+	if err != nil {
+		if errors.Is(err, oauth.ErrDeviceCodeNotSupported) {
+			return shared.HandleBadRequestError(err.Error())
+		}
+		logrus.WithError(err).Errorf("unable to start the device authorization with the provider %q", provider.Info().SlugID)
+		return echo.NewHTTPError(http.StatusBadGateway, "the identity provider is unavailable")
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// DeviceAccessToken returns the tokens of the user who approved the device. While the user hasn't approved it yet,
+// it returns the status code 400 with the message api.DeviceAuthorizationPending or api.DeviceSlowDown.
+func (e *Endpoint) DeviceAccessToken(ctx echo.Context) error {
+	provider, err := e.getProvider(ctx)
+	if err != nil {
+		return err
+	}
+	body := &api.DeviceAccessTokenRequest{}
+	if bindErr := ctx.Bind(body); bindErr != nil {
+		return shared.HandleBadRequestError(bindErr.Error())
+	}
+	identity, err := provider.DeviceAccessToken(ctx.Request().Context(), body.DeviceCode)
+	if err != nil {
+		if errors.Is(err, oauth.ErrAuthorizationPending) || errors.Is(err, oauth.ErrSlowDown) {
+			// the message is exactly the error code, so the client can check it.
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, oauth.ErrDeviceCodeNotSupported) {
+			return shared.HandleBadRequestError(err.Error())
+		}
+		logrus.WithError(err).Warningf("unable to authenticate a device with the provider %q", provider.Info().SlugID)
+		return shared.HandleUnauthorizedError(err.Error())
+	}
+	response, err := e.authenticationService.ExternalLogin(identity)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (e *Endpoint) getProvider(ctx echo.Context) (oauth.Provider, error) {
+	kind := api.AuthProviderKind(ctx.Param(paramKind))
+	slugID := ctx.Param(paramSlugID)
+	provider, exists := e.providers.Get(kind, slugID)
+	if !exists {
+		return nil, echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("the provider %s/%s doesn't exist", kind, slugID))
+	}
+	return provider, nil
+}
+
+// redirectURI returns the URL of the callback of the provider. By default, it is built from the URL of the request.
+func redirectURI(ctx echo.Context, provider oauth.Provider) string {
+	if uri := provider.RedirectURI(); len(uri) > 0 {
+		return uri
+	}
+	info := provider.Info()
+	return fmt.Sprintf("%s://%s/api/auth/providers/%s/%s/callback", ctx.Scheme(), ctx.Request().Host, info.Kind, info.SlugID)
+}
+
+func stateCookie(ctx echo.Context, value string, maxAge int) *http.Cookie {
+	info := ctx.Param(paramKind) + "/" + ctx.Param(paramSlugID)
+	return &http.Cookie{
+		Name:  stateCookieName,
+		Value: value,
+		// the cookie is only sent to the callback of the provider.
+		Path:     fmt.Sprintf("/api/auth/providers/%s/callback", info),
+		MaxAge:   maxAge,
+		Secure:   ctx.Scheme() == "https",
+		HttpOnly: true,
+		// the callback is a navigation from the provider, so the cookie must be sent with the cross-site requests of the top-level windows.
+		SameSite: http.SameSiteLaxMode,
+	}
+}
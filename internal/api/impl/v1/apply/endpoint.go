@@ -0,0 +1,194 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/interface/v1/apply"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"gopkg.in/yaml.v2"
+)
+
+// yamlContentTypes are the content types of a body containing YAML documents. Any other body is decoded as JSON.
+var yamlContentTypes = map[string]bool{
+	"application/yaml":   true,
+	"application/x-yaml": true,
+	"text/yaml":          true,
+}
+
+// Endpoint is the struct that define the endpoint delivered by the path /apply
+type Endpoint struct {
+	service  apply.Service
+	readonly bool
+}
+
+// NewEndpoint create an instance of the object Endpoint.
+func NewEndpoint(service apply.Service, readonly bool) *Endpoint {
+	return &Endpoint{
+		service:  service,
+		readonly: readonly,
+	}
+}
+
+func (e *Endpoint) RegisterRoutes(g *echo.Group) {
+	if !e.readonly {
+		g.POST(fmt.Sprintf("/%s", shared.PathApply), e.Apply)
+	}
+}
+
+// Apply creates or updates the resources sent in the body, and returns what has been done with each of them.
+// With the query parameter dryRun, nothing is written and the response tells what would be done.
+func (e *Endpoint) Apply(ctx echo.Context) error {
+	dryRun, err := isDryRun(ctx)
+	if err != nil {
+		return shared.HandleBadRequestError(fmt.Sprintf("invalid value for the parameter dryRun: %s", err))
+	}
+	resources, err := decodeResources(ctx)
+	if err != nil {
+		return err
+	}
+	// the changes are recorded one by one, and there is none for a dry run.
+	shared.MarkRecorded(ctx)
+	result, err := e.service.Apply(&apply.Request{
+		Resources: resources,
+		DryRun:    dryRun,
+		Author:    shared.GetAuthor(ctx),
+		IsAllowed: func(action v1.Action, kind v1.Kind, project string) bool {
+			return shared.IsAllowed(ctx, action, kind, project)
+		},
+		OnChange: func(action v1.Action, oldEntity interface{}, newEntity interface{}) {
+			shared.AuditChange(ctx, action, oldEntity, newEntity)
+			shared.NotifyChange(ctx, action, oldEntity, newEntity)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func isDryRun(ctx echo.Context) (bool, error) {
+	value := ctx.QueryParam("dryRun")
+	if len(value) == 0 {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// decodeResources returns the resources sent in the body. It is either a JSON array of resources,
+// or YAML documents each containing a resource or a list of resources. A single resource is accepted too.
+func decodeResources(ctx echo.Context) ([]api.Entity, error) {
+	body, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return nil, shared.HandleBadRequestError(err.Error())
+	}
+	contentType, _, _ := mime.ParseMediaType(ctx.Request().Header.Get(echo.HeaderContentType))
+	if yamlContentTypes[contentType] {
+		return decodeYAMLResources(body)
+	}
+	return decodeJSONResources(body)
+}
+
+func decodeJSONResources(body []byte) ([]api.Entity, error) {
+	var documents []json.RawMessage
+	if err := json.Unmarshal(body, &documents); err != nil {
+		var document json.RawMessage
+		if objectErr := json.Unmarshal(body, &document); objectErr != nil {
+			return nil, shared.HandleBadRequestError(fmt.Sprintf("unable to decode the resources: %s", err))
+		}
+		documents = append(documents, document)
+	}
+	result := make([]api.Entity, 0, len(documents))
+	for i, document := range documents {
+		kind := struct {
+			Kind string `json:"kind"`
+		}{}
+		if err := json.Unmarshal(document, &kind); err != nil {
+			return nil, shared.HandleBadRequestError(fmt.Sprintf("resources[%d]: %s", i, err))
+		}
+		entity, err := newEntity(i, kind.Kind)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(document, entity); err != nil {
+			return nil, shared.HandleBadRequestError(fmt.Sprintf("resources[%d]: %s", i, err))
+		}
+		result = append(result, entity)
+	}
+	return result, nil
+}
+
+func decodeYAMLResources(body []byte) ([]api.Entity, error) {
+	var documents []interface{}
+	decoder := yaml.NewDecoder(bytes.NewReader(body))
+	for {
+		var document interface{}
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, shared.HandleBadRequestError(fmt.Sprintf("unable to decode the resources: %s", err))
+		}
+		if list, isList := document.([]interface{}); isList {
+			documents = append(documents, list...)
+		} else if document != nil {
+			documents = append(documents, document)
+		}
+	}
+	result := make([]api.Entity, 0, len(documents))
+	for i, document := range documents {
+		// the document is encoded again, so it can be decoded with the struct of its kind.
+		data, err := yaml.Marshal(document)
+		if err != nil {
+			return nil, shared.HandleBadRequestError(fmt.Sprintf("resources[%d]: %s", i, err))
+		}
+		kind := struct {
+			Kind string `yaml:"kind"`
+		}{}
+		if err := yaml.Unmarshal(data, &kind); err != nil {
+			return nil, shared.HandleBadRequestError(fmt.Sprintf("resources[%d]: %s", i, err))
+		}
+		entity, err := newEntity(i, kind.Kind)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, entity); err != nil {
+			return nil, shared.HandleBadRequestError(fmt.Sprintf("resources[%d]: %s", i, err))
+		}
+		result = append(result, entity)
+	}
+	return result, nil
+}
+
+func newEntity(index int, kind string) (api.Entity, error) {
+	if len(kind) == 0 {
+		return nil, shared.HandleBadRequestError(fmt.Sprintf("resources[%d]: unable to find the field 'kind'", index))
+	}
+	entity, err := v1.GetStruct(v1.Kind(kind))
+	if err != nil {
+		return nil, shared.HandleBadRequestError(fmt.Sprintf("resources[%d]: %s", index, err))
+	}
+	return entity, nil
+}
@@ -0,0 +1,414 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	datasourceImpl "github.com/perses/perses/internal/api/impl/v1/datasource"
+	globalDatasourceImpl "github.com/perses/perses/internal/api/impl/v1/globaldatasource"
+	globalVariableImpl "github.com/perses/perses/internal/api/impl/v1/globalvariable"
+	variableImpl "github.com/perses/perses/internal/api/impl/v1/variable"
+	"github.com/perses/perses/internal/api/interface/v1/apply"
+	"github.com/perses/perses/internal/api/interface/v1/datasource"
+	"github.com/perses/perses/internal/api/interface/v1/globaldatasource"
+	"github.com/perses/perses/internal/api/interface/v1/globalvariable"
+	"github.com/perses/perses/internal/api/interface/v1/variable"
+	"github.com/perses/perses/internal/api/shared"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/internal/api/shared/schemas"
+	"github.com/perses/perses/internal/api/shared/validate"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// kindOrder contains the kinds that can be applied, in the order they are written: a resource comes after the ones it can depend on.
+var kindOrder = []v1.Kind{
+	v1.KindProject,
+	v1.KindGlobalSecret,
+	v1.KindSecret,
+	v1.KindGlobalDatasource,
+	v1.KindDatasource,
+	v1.KindGlobalVariable,
+	v1.KindVariable,
+	v1.KindDashboard,
+	v1.KindFolder,
+}
+
+// change is what the apply does with one of the resources.
+type change struct {
+	entity     api.Entity
+	kind       v1.Kind
+	parameters shared.Parameters
+	action     v1.ApplyAction
+	// current is the resource stored, as returned by the API. It is nil when the resource doesn't exist yet.
+	current interface{}
+}
+
+func (c *change) String() string {
+	if len(c.parameters.Project) == 0 {
+		return fmt.Sprintf("%s %q", c.kind, c.parameters.Name)
+	}
+	return fmt.Sprintf("%s %q of the project %q", c.kind, c.parameters.Name, c.parameters.Project)
+}
+
+// batch contains the resources applied, so each one is validated against the others rather than only against the ones stored.
+type batch struct {
+	projects          map[string]bool
+	globalDatasources []*v1.GlobalDatasource
+	datasources       map[string][]*v1.Datasource
+	globalVariables   []*v1.GlobalVariable
+	variables         map[string][]*v1.Variable
+}
+
+func newBatch(resources []api.Entity) *batch {
+	b := &batch{
+		projects:    make(map[string]bool),
+		datasources: make(map[string][]*v1.Datasource),
+		variables:   make(map[string][]*v1.Variable),
+	}
+	for _, resource := range resources {
+		switch entity := resource.(type) {
+		case *v1.Project:
+			b.projects[entity.Metadata.Name] = true
+		case *v1.GlobalDatasource:
+			b.globalDatasources = append(b.globalDatasources, entity)
+		case *v1.Datasource:
+			b.datasources[entity.Metadata.Project] = append(b.datasources[entity.Metadata.Project], entity)
+		case *v1.GlobalVariable:
+			b.globalVariables = append(b.globalVariables, entity)
+		case *v1.Variable:
+			b.variables[entity.Metadata.Project] = append(b.variables[entity.Metadata.Project], entity)
+		}
+	}
+	return b
+}
+
+type service struct {
+	apply.Service
+	// services are the ones used to read and to write each kind, so a resource applied is written like with its own endpoint.
+	services            map[v1.Kind]shared.TransactionalService
+	persesDAO           databaseModel.DAO
+	globalDatasourceDAO globaldatasource.DAO
+	datasourceDAO       datasource.DAO
+	globalVariableDAO   globalvariable.DAO
+	variableDAO         variable.DAO
+	sch                 schemas.Schemas
+}
+
+// NewService returns the service applying the resources. services must contain the service of each kind that can be applied.
+func NewService(services map[v1.Kind]shared.TransactionalService, persesDAO databaseModel.DAO, sch schemas.Schemas) apply.Service {
+	return &service{
+		services:            services,
+		persesDAO:           persesDAO,
+		globalDatasourceDAO: globalDatasourceImpl.NewDAO(persesDAO),
+		datasourceDAO:       datasourceImpl.NewDAO(persesDAO),
+		globalVariableDAO:   globalVariableImpl.NewDAO(persesDAO),
+		variableDAO:         variableImpl.NewDAO(persesDAO),
+		sch:                 sch,
+	}
+}
+
+func (s *service) Apply(request *apply.Request) (*v1.ApplyResponse, error) {
+	resources, err := sortResources(request.Resources)
+	if err != nil {
+		return nil, err
+	}
+	b := newBatch(resources)
+	// every resource is checked before the first one is written, so an invalid resource doesn't leave the others half-applied.
+	changes := make([]*change, 0, len(resources))
+	for _, entity := range resources {
+		c, planErr := s.plan(entity, b, request)
+		if planErr != nil {
+			return nil, planErr
+		}
+		changes = append(changes, c)
+	}
+	response := &v1.ApplyResponse{DryRun: request.DryRun, Results: make([]*v1.ApplyResult, 0, len(changes))}
+	for _, c := range changes {
+		response.Results = append(response.Results, &v1.ApplyResult{
+			Kind:    c.kind,
+			Project: c.parameters.Project,
+			Name:    c.parameters.Name,
+			Action:  c.action,
+		})
+	}
+	if request.DryRun {
+		return response, nil
+	}
+	// the resources are written in a single transaction, so either all of them are applied or none of them.
+	var notifications []func()
+	if err := databaseModel.WithAuthor(s.persesDAO, request.Author).Transaction(func(tx databaseModel.DAO) error {
+		for _, c := range changes {
+			notify, writeErr := s.write(tx, c, request.OnChange)
+			if writeErr != nil {
+				return fmt.Errorf("unable to apply the %s, no resource has been applied: %w", c, writeErr)
+			}
+			notifications = append(notifications, notify)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	// the changes are notified once committed, so nothing is notified about a resource that has been rolled back.
+	for _, notify := range notifications {
+		notify()
+	}
+	return response, nil
+}
+
+// sortResources returns the resources in the order they must be written. The resources of the same kind keep the order they are given in.
+func sortResources(resources []api.Entity) ([]api.Entity, error) {
+	if len(resources) == 0 {
+		return nil, shared.HandleBadRequestError("there is no resource to apply")
+	}
+	rank := make(map[v1.Kind]int, len(kindOrder))
+	for i, kind := range kindOrder {
+		rank[kind] = i
+	}
+	seen := make(map[string]bool, len(resources))
+	for _, entity := range resources {
+		kind := v1.Kind(entity.GetKind())
+		if _, ok := rank[kind]; !ok {
+			return nil, shared.HandleBadRequestError(fmt.Sprintf("the kind %q cannot be applied, the possible values are %s", kind, joinKinds(kindOrder)))
+		}
+		project := getProject(entity)
+		if _, isProjectResource := entity.GetMetadata().(*v1.ProjectMetadata); isProjectResource && len(project) == 0 {
+			return nil, shared.HandleBadRequestError(fmt.Sprintf("metadata.project of the %s %q cannot be empty", kind, entity.GetMetadata().GetName()))
+		}
+		key := fmt.Sprintf("%s/%s/%s", kind, project, entity.GetMetadata().GetName())
+		if seen[key] {
+			return nil, shared.HandleBadRequestError(fmt.Sprintf("the %s %q is given several times", kind, entity.GetMetadata().GetName()))
+		}
+		seen[key] = true
+	}
+	result := make([]api.Entity, len(resources))
+	copy(result, resources)
+	sort.SliceStable(result, func(i, j int) bool {
+		return rank[v1.Kind(result[i].GetKind())] < rank[v1.Kind(result[j].GetKind())]
+	})
+	return result, nil
+}
+
+// plan validates the resource, checks the user is allowed to apply it, and finds out whether it is created, updated or left unchanged.
+func (s *service) plan(entity api.Entity, b *batch, request *apply.Request) (*change, error) {
+	c := &change{
+		entity:     entity,
+		kind:       v1.Kind(entity.GetKind()),
+		parameters: shared.Parameters{Project: getProject(entity), Name: entity.GetMetadata().GetName(), Author: request.Author},
+	}
+	if err := shared.ValidateMetadata(entity.GetMetadata()); err != nil {
+		return nil, shared.HandleBadRequestError(fmt.Sprintf("the %s is invalid: %s", c, err))
+	}
+	if len(c.parameters.Project) > 0 {
+		if err := s.checkProject(c.parameters.Project, b); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.validate(entity, b); err != nil {
+		return nil, shared.HandleBadRequestError(fmt.Sprintf("the %s is invalid: %s", c, err))
+	}
+	svc := s.services[c.kind]
+	current, err := svc.Get(c.parameters)
+	if err != nil {
+		if !databaseModel.IsKeyNotFound(err) {
+			return nil, err
+		}
+		c.action = v1.ApplyActionCreate
+	} else {
+		c.current = current
+		if versionErr := shared.CheckVersion(c.parameters, getMetadata(entity).Version, getMetadata(current).Version); versionErr != nil {
+			return nil, versionErr
+		}
+		unchanged, compareErr := s.isUnchanged(c, svc)
+		if compareErr != nil {
+			return nil, compareErr
+		}
+		c.action = v1.ApplyActionUpdate
+		if unchanged {
+			c.action = v1.ApplyActionUnchanged
+		}
+	}
+	action, permissionProject := v1.ActionUpdate, c.parameters.Project
+	if c.action == v1.ApplyActionCreate {
+		action = v1.ActionCreate
+	}
+	if c.kind == v1.KindProject && c.action != v1.ApplyActionCreate {
+		// a project is modified with the permissions given in the project itself.
+		permissionProject = c.parameters.Name
+	}
+	if request.IsAllowed != nil && !request.IsAllowed(action, c.kind, permissionProject) {
+		return nil, shared.HandleForbiddenError(fmt.Sprintf("you are not allowed to %s the %s", action, c))
+	}
+	return c, nil
+}
+
+// checkProject returns an error if the project doesn't exist and is not applied with the resource.
+func (s *service) checkProject(project string, b *batch) error {
+	if b.projects[project] {
+		return nil
+	}
+	if _, err := s.services[v1.KindProject].Get(shared.Parameters{Name: project}); err != nil {
+		if databaseModel.IsKeyNotFound(err) {
+			return shared.HandleBadRequestError(fmt.Sprintf("metadata.project %q doesn't exist", project))
+		}
+		return err
+	}
+	return nil
+}
+
+// validate checks the spec of the resource like its service does, except the resources of the batch are taken into account.
+func (s *service) validate(resource api.Entity, b *batch) error {
+	switch entity := resource.(type) {
+	case *v1.GlobalDatasource:
+		if !entity.Spec.Default {
+			return validate.Datasource(entity, nil, s.sch)
+		}
+		stored, err := s.globalDatasourceDAO.List(&globaldatasource.Query{})
+		if err != nil {
+			return err
+		}
+		return validate.Datasource(entity, merge(stored, b.globalDatasources), s.sch)
+	case *v1.Datasource:
+		if !entity.Spec.Default {
+			return validate.Datasource(entity, nil, s.sch)
+		}
+		stored, err := s.datasourceDAO.List(&datasource.Query{Project: entity.Metadata.Project})
+		if err != nil {
+			return err
+		}
+		return validate.Datasource(entity, merge(stored, b.datasources[entity.Metadata.Project]), s.sch)
+	case *v1.GlobalVariable:
+		return s.sch.ValidateGlobalVariable(entity.Spec)
+	case *v1.Variable:
+		return validate.Variable(entity, s.sch)
+	case *v1.Dashboard:
+		globalVariables, err := s.globalVariableDAO.List(&globalvariable.Query{})
+		if err != nil {
+			return err
+		}
+		projectVariables, err := s.variableDAO.List(&variable.Query{Project: entity.Metadata.Project})
+		if err != nil {
+			return err
+		}
+		return validate.DashboardWithVars(entity, s.sch, merge(projectVariables, b.variables[entity.Metadata.Project]), merge(globalVariables, b.globalVariables))
+	}
+	return nil
+}
+
+// isUnchanged returns true if the resource stored has the same spec, labels and annotations as the one applied.
+func (s *service) isUnchanged(c *change, svc shared.ToolboxService) (bool, error) {
+	current := c.current
+	if patchTargetService, ok := svc.(shared.PatchTargetService); ok {
+		// the resource returned by Get doesn't contain the whole spec, like for a secret.
+		var err error
+		if current, err = patchTargetService.GetPatchTarget(c.parameters); err != nil {
+			return false, err
+		}
+	}
+	currentEntity, ok := current.(api.Entity)
+	if !ok {
+		return false, nil
+	}
+	currentMetadata, metadata := getMetadata(currentEntity), getMetadata(c.entity)
+	if !sameMap(currentMetadata.Labels, metadata.Labels) || !sameMap(currentMetadata.Annotations, metadata.Annotations) {
+		return false, nil
+	}
+	currentSpec, err := json.Marshal(currentEntity.GetSpec())
+	if err != nil {
+		return false, err
+	}
+	spec, err := json.Marshal(c.entity.GetSpec())
+	if err != nil {
+		return false, err
+	}
+	return string(currentSpec) == string(spec), nil
+}
+
+// write writes the resource with tx. It returns the function notifying the change, to call once the transaction is committed.
+func (s *service) write(tx databaseModel.DAO, c *change, onChange func(action v1.Action, oldEntity interface{}, newEntity interface{})) (func(), error) {
+	if c.action == v1.ApplyActionUnchanged {
+		return func() {}, nil
+	}
+	svc, commit := s.services[c.kind].InTransaction(tx)
+	action, oldEntity := v1.ActionCreate, c.current
+	var newEntity interface{}
+	var err error
+	if c.action == v1.ApplyActionCreate {
+		newEntity, err = svc.Create(c.entity, c.parameters)
+	} else {
+		action = v1.ActionUpdate
+		newEntity, err = svc.Update(c.entity, c.parameters)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		commit()
+		if onChange != nil {
+			onChange(action, oldEntity, newEntity)
+		}
+	}, nil
+}
+
+// merge returns the resources stored, the ones applied replacing the stored ones with the same name.
+func merge[T api.Entity](stored []T, applied []T) []T {
+	names := make(map[string]bool, len(applied))
+	for _, entity := range applied {
+		names[entity.GetMetadata().GetName()] = true
+	}
+	result := make([]T, 0, len(stored)+len(applied))
+	for _, entity := range stored {
+		if !names[entity.GetMetadata().GetName()] {
+			result = append(result, entity)
+		}
+	}
+	return append(result, applied...)
+}
+
+func getProject(entity api.Entity) string {
+	if metadata, ok := entity.GetMetadata().(*v1.ProjectMetadata); ok {
+		return metadata.Project
+	}
+	return ""
+}
+
+func getMetadata(object interface{}) *v1.Metadata {
+	if entity, ok := object.(api.Entity); ok {
+		switch metadata := entity.GetMetadata().(type) {
+		case *v1.ProjectMetadata:
+			return &metadata.Metadata
+		case *v1.Metadata:
+			return metadata
+		}
+	}
+	return &v1.Metadata{}
+}
+
+// sameMap returns true if the maps contain the same entries. A nil map is the same as an empty one.
+func sameMap(a map[string]string, b map[string]string) bool {
+	return (len(a) == 0 && len(b) == 0) || reflect.DeepEqual(a, b)
+}
+
+func joinKinds(kinds []v1.Kind) string {
+	names := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		names = append(names, string(kind))
+	}
+	return strings.Join(names, ", ")
+}
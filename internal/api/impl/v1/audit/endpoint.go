@@ -0,0 +1,52 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/interface/v1/audit"
+	"github.com/perses/perses/internal/api/shared"
+)
+
+// Endpoint is the struct that define the endpoint delivered by the path /audit
+type Endpoint struct {
+	service audit.Service
+}
+
+// NewEndpoint create an instance of the object Endpoint.
+func NewEndpoint(service audit.Service) *Endpoint {
+	return &Endpoint{
+		service: service,
+	}
+}
+
+func (e *Endpoint) RegisterRoutes(g *echo.Group) {
+	g.GET(fmt.Sprintf("/%s", shared.PathAudit), e.List)
+}
+
+// List returns the records of the audit log, the most recent first. They can be filtered by resource, by actor and by time.
+func (e *Endpoint) List(ctx echo.Context) error {
+	query := &audit.Query{}
+	if err := ctx.Bind(query); err != nil {
+		return shared.HandleBadRequestError(err.Error())
+	}
+	result, err := e.service.List(query)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"github.com/perses/perses/internal/api/interface/v1/audit"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type dao struct {
+	audit.DAO
+	client databaseModel.DAO
+}
+
+func NewDAO(persesDAO databaseModel.DAO) audit.DAO {
+	return &dao{
+		client: persesDAO,
+	}
+}
+
+func (d *dao) Create(entity *v1.AuditRecord) error {
+	return d.client.Create(entity)
+}
+
+func (d *dao) List(q *audit.Query) ([]*v1.AuditRecord, error) {
+	var result []*v1.AuditRecord
+	err := d.client.Query(q, &result)
+	return result, err
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"sort"
+
+	"github.com/perses/perses/internal/api/interface/v1/audit"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+type service struct {
+	audit.Service
+	sink audit.Sink
+}
+
+func NewService(sink audit.Sink) audit.Service {
+	return &service{
+		sink: sink,
+	}
+}
+
+func (s *service) Record(record *v1.AuditRecord) {
+	if err := s.sink.Write(record); err != nil {
+		logrus.WithError(err).Errorf("unable to record the %s of the %s %q", record.Spec.Action, record.Spec.Resource.Kind, record.Spec.Resource.Name)
+	}
+}
+
+func (s *service) List(q *audit.Query) ([]*v1.AuditRecord, error) {
+	records, err := s.sink.List(q)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Spec.Time.After(records[j].Spec.Time)
+	})
+	return records, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/config"
+	"github.com/perses/perses/internal/api/interface/v1/audit"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// NewSink returns the sink configured. The records are stored in the database with the DAO by default.
+func NewSink(conf config.Audit, dao audit.DAO) audit.Sink {
+	switch conf.Sink {
+	case config.AuditSinkFile:
+		return &fileSink{path: conf.File}
+	case config.AuditSinkStdout:
+		return &writerSink{writer: os.Stdout}
+	default:
+		return &databaseSink{dao: dao}
+	}
+}
+
+type databaseSink struct {
+	audit.Sink
+	dao audit.DAO
+}
+
+func (s *databaseSink) Write(record *v1.AuditRecord) error {
+	return s.dao.Create(record)
+}
+
+func (s *databaseSink) List(q *audit.Query) ([]*v1.AuditRecord, error) {
+	records, err := s.dao.List(q)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*v1.AuditRecord, 0, len(records))
+	for _, record := range records {
+		if q.Accept(record) {
+			result = append(result, record)
+		}
+	}
+	return result, nil
+}
+
+// fileSink appends the records to a file, one JSON document per line, so the file can be read by the usual log collectors.
+type fileSink struct {
+	audit.Sink
+	path string
+	// mutex prevents two records from being written at the same time, so they are not interleaved.
+	mutex sync.Mutex
+}
+
+func (s *fileSink) Write(record *v1.AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, writeErr := file.Write(append(data, '\n')); writeErr != nil {
+		_ = file.Close()
+		return writeErr
+	}
+	return file.Close()
+}
+
+func (s *fileSink) List(q *audit.Query) ([]*v1.AuditRecord, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// nothing has been recorded yet.
+			return []*v1.AuditRecord{}, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+	result := []*v1.AuditRecord{}
+	decoder := json.NewDecoder(file)
+	for {
+		record := &v1.AuditRecord{}
+		if decodeErr := decoder.Decode(record); decodeErr != nil {
+			if decodeErr == io.EOF {
+				return result, nil
+			}
+			return nil, decodeErr
+		}
+		if q.Accept(record) {
+			result = append(result, record)
+		}
+	}
+}
+
+// writerSink writes the records, one JSON document per line, to a writer that cannot be read back, like the standard output.
+type writerSink struct {
+	audit.Sink
+	writer io.Writer
+	mutex  sync.Mutex
+}
+
+func (s *writerSink) Write(record *v1.AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.writer.Write(append(data, '\n'))
+	return err
+}
+
+func (s *writerSink) List(_ *audit.Query) ([]*v1.AuditRecord, error) {
+	return nil, echo.NewHTTPError(http.StatusNotImplemented, "the audit records are written to the standard output, they cannot be queried")
+}
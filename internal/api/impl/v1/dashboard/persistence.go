@@ -14,38 +14,59 @@
 package dashboard
 
 import (
+	"context"
+
 	"github.com/perses/perses/internal/api/interface/v1/dashboard"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelAPI "github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
 type dao struct {
 	dashboard.DAO
-	client databaseModel.DAO
-	kind   v1.Kind
+	client       databaseModel.DAO
+	kind         v1.Kind
+	revisionKind v1.Kind
 }
 
 func NewDAO(persesDAO databaseModel.DAO) dashboard.DAO {
 	return &dao{
-		client: persesDAO,
-		kind:   v1.KindDashboard,
+		client:       persesDAO,
+		kind:         v1.KindDashboard,
+		revisionKind: v1.KindDashboardRevision,
 	}
 }
 
+func (d *dao) WithAuthor(author string) dashboard.DAO {
+	return NewDAO(databaseModel.WithAuthor(d.client, author))
+}
+
 func (d *dao) Create(entity *v1.Dashboard) error {
 	return d.client.Create(entity)
 }
 
-func (d *dao) Update(entity *v1.Dashboard) error {
-	return d.client.Upsert(entity)
+func (d *dao) Update(entity *v1.Dashboard, expectedVersion uint64) error {
+	return d.client.Update(entity, expectedVersion)
 }
 
+// Delete removes the dashboard and its revisions in a single transaction, so no revision is left without its dashboard.
 func (d *dao) Delete(project string, name string) error {
-	return d.client.Delete(d.kind, v1.NewProjectMetadata(project, name))
+	return d.client.Transaction(func(tx databaseModel.DAO) error {
+		if err := tx.Delete(d.kind, v1.NewProjectMetadata(project, name)); err != nil {
+			return err
+		}
+		return tx.DeleteByQuery(&dashboard.RevisionQuery{Project: project, Name: name})
+	})
 }
 
+// DeleteAll removes the dashboards of the project and their revisions in a single transaction.
 func (d *dao) DeleteAll(project string) error {
-	return d.client.DeleteByQuery(&dashboard.Query{Project: project})
+	return d.client.Transaction(func(tx databaseModel.DAO) error {
+		if err := tx.DeleteByQuery(&dashboard.Query{Project: project}); err != nil {
+			return err
+		}
+		return tx.DeleteByQuery(&dashboard.RevisionQuery{Project: project})
+	})
 }
 
 func (d *dao) Get(project string, name string) (*v1.Dashboard, error) {
@@ -58,3 +79,39 @@ func (d *dao) List(q databaseModel.Query) ([]*v1.Dashboard, error) {
 	err := d.client.Query(q, &result)
 	return result, err
 }
+
+func (d *dao) Watch(ctx context.Context, q *dashboard.Query) (<-chan *v1.WatchEvent, error) {
+	filter := databaseModel.WatchFilter{
+		Project:        q.Project,
+		NamePrefix:     q.NamePrefix,
+		LabelSelection: q.LabelSelection,
+	}
+	return databaseModel.Watch(ctx, d.client, d.kind, filter, func() modelAPI.Entity {
+		return &v1.Dashboard{}
+	})
+}
+
+func (d *dao) CreateRevision(entity *v1.DashboardRevision) error {
+	return d.client.Upsert(entity)
+}
+
+func (d *dao) DeleteRevision(project string, name string, version uint64) error {
+	return d.client.Delete(d.revisionKind, newRevisionMetadata(project, name, version))
+}
+
+func (d *dao) GetRevision(project string, name string, version uint64) (*v1.DashboardRevision, error) {
+	entity := &v1.DashboardRevision{}
+	return entity, d.client.Get(d.revisionKind, newRevisionMetadata(project, name, version), entity)
+}
+
+func (d *dao) ListRevisions(project string, name string) ([]*v1.DashboardRevision, error) {
+	var result []*v1.DashboardRevision
+	err := d.client.Query(&dashboard.RevisionQuery{Project: project, Name: name}, &result)
+	return result, err
+}
+
+func newRevisionMetadata(project string, name string, version uint64) *v1.RevisionMetadata {
+	metadata := &v1.RevisionMetadata{ProjectMetadata: *v1.NewProjectMetadata(project, name)}
+	metadata.Version = version
+	return metadata
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/interface/v1/dashboard"
+	"github.com/perses/perses/internal/api/shared"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// RevisionEndpoint exposes the history of the dashboards.
+type RevisionEndpoint struct {
+	service  dashboard.Service
+	readonly bool
+}
+
+func NewRevisionEndpoint(service dashboard.Service, readonly bool) *RevisionEndpoint {
+	return &RevisionEndpoint{
+		service:  service,
+		readonly: readonly,
+	}
+}
+
+func (e *RevisionEndpoint) RegisterRoutes(g *echo.Group) {
+	group := g.Group(fmt.Sprintf("/%s/:%s/%s/:%s/%s", shared.PathProject, shared.ParamProject, shared.PathDashboard, shared.ParamName, shared.PathRevision))
+	if !e.readonly {
+		group.POST(fmt.Sprintf("/:%s/restore", shared.ParamVersion), e.Restore)
+	}
+	group.GET("", e.List)
+	group.GET(fmt.Sprintf("/:%s", shared.ParamVersion), e.Get)
+}
+
+func (e *RevisionEndpoint) List(ctx echo.Context) error {
+	result, err := e.service.ListRevisions(shared.ExtractParameters(ctx))
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (e *RevisionEndpoint) Get(ctx echo.Context) error {
+	version, err := extractVersionParameter(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := e.service.GetRevision(shared.ExtractParameters(ctx), version)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (e *RevisionEndpoint) Restore(ctx echo.Context) error {
+	version, err := extractVersionParameter(ctx)
+	if err != nil {
+		return err
+	}
+	parameters := shared.ExtractParameters(ctx)
+	oldEntity := shared.GetChanged(ctx, func() (interface{}, error) {
+		return e.service.Get(parameters)
+	})
+	result, err := e.service.RestoreRevision(parameters, version)
+	if err != nil {
+		return err
+	}
+	shared.AuditChange(ctx, v1.ActionUpdate, oldEntity, result)
+	shared.NotifyChange(ctx, v1.ActionUpdate, oldEntity, result)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func extractVersionParameter(ctx echo.Context) (uint64, error) {
+	version, err := strconv.ParseUint(ctx.Param(shared.ParamVersion), 10, 64)
+	if err != nil {
+		return 0, shared.HandleBadRequestError(fmt.Sprintf("%q is not a valid version", ctx.Param(shared.ParamVersion)))
+	}
+	return version, nil
+}
@@ -14,14 +14,21 @@
 package dashboard
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"time"
 
+	"github.com/perses/perses/internal/api/config"
+	trashImpl "github.com/perses/perses/internal/api/impl/v1/trash"
 	"github.com/perses/perses/internal/api/interface/v1/dashboard"
 	"github.com/perses/perses/internal/api/interface/v1/globalvariable"
 	"github.com/perses/perses/internal/api/interface/v1/variable"
 	"github.com/perses/perses/internal/api/shared"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
 	"github.com/perses/perses/internal/api/shared/schemas"
+	searchIndex "github.com/perses/perses/internal/api/shared/search"
 	"github.com/perses/perses/internal/api/shared/validate"
 	"github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
@@ -30,29 +37,64 @@ import (
 
 type service struct {
 	dashboard.Service
-	dao           dashboard.DAO
-	sch           schemas.Schemas
-	globalVarDAO  globalvariable.DAO
-	projectVarDAO variable.DAO
+	dao dashboard.DAO
+	// persesDAO is used to move a dashboard to the trash in a single transaction.
+	persesDAO      databaseModel.DAO
+	sch            schemas.Schemas
+	globalVarDAO   globalvariable.DAO
+	projectVarDAO  variable.DAO
+	revisionConfig config.DashboardRevision
+	trashConfig    config.Trash
+	index          searchIndex.Index
+	// inTransaction is true for the service returned by InTransaction.
+	inTransaction bool
 }
 
-func NewService(dao dashboard.DAO, sch schemas.Schemas, globalVarDAO globalvariable.DAO, projectVarDAO variable.DAO) dashboard.Service {
+func NewService(dao dashboard.DAO, persesDAO databaseModel.DAO, sch schemas.Schemas, globalVarDAO globalvariable.DAO, projectVarDAO variable.DAO, revisionConfig config.DashboardRevision, trashConfig config.Trash, index searchIndex.Index) dashboard.Service {
 	return &service{
-		dao:           dao,
-		sch:           sch,
-		globalVarDAO:  globalVarDAO,
-		projectVarDAO: projectVarDAO,
+		dao:            dao,
+		persesDAO:      persesDAO,
+		sch:            sch,
+		globalVarDAO:   globalVarDAO,
+		projectVarDAO:  projectVarDAO,
+		revisionConfig: revisionConfig,
+		trashConfig:    trashConfig,
+		index:          index,
 	}
 }
 
-func (s *service) Create(entity api.Entity) (interface{}, error) {
+// InTransaction returns a copy of the service writing with tx. The search index is updated once commit is called.
+// The resources are not validated, as the caller validates them against the ones written in the same transaction.
+func (s *service) InTransaction(tx databaseModel.DAO) (shared.ToolboxService, func()) {
+	txService := *s
+	txService.dao = NewDAO(tx)
+	txService.persesDAO = tx
+	txService.inTransaction = true
+	index, commit := searchIndex.NewDeferred(s.index)
+	txService.index = index
+	return &txService, commit
+}
+
+// WithAuthor returns a copy of the service whose changes are attributed to author.
+func (s *service) WithAuthor(author string) shared.ToolboxService {
+	return s.withAuthor(author)
+}
+
+func (s *service) withAuthor(author string) *service {
+	authorService := *s
+	authorService.dao = s.dao.WithAuthor(author)
+	authorService.persesDAO = databaseModel.WithAuthor(s.persesDAO, author)
+	return &authorService
+}
+
+func (s *service) Create(entity api.Entity, parameters shared.Parameters) (interface{}, error) {
 	if object, ok := entity.(*v1.Dashboard); ok {
-		return s.create(object)
+		return s.create(object, parameters.Author)
 	}
 	return nil, shared.HandleBadRequestError(fmt.Sprintf("wrong entity format, attempting dashboard format, received '%T'", entity))
 }
 
-func (s *service) create(entity *v1.Dashboard) (*v1.Dashboard, error) {
+func (s *service) create(entity *v1.Dashboard, author string) (*v1.Dashboard, error) {
 	// verify this new dashboard passes the validation
 	if err := s.Validate(entity); err != nil {
 		return nil, err
@@ -60,9 +102,17 @@ func (s *service) create(entity *v1.Dashboard) (*v1.Dashboard, error) {
 
 	// Update the time contains in the entity
 	entity.Metadata.CreateNow()
-	if err := s.dao.Create(entity); err != nil {
+	// the dashboard and its first revision are saved together, so the history cannot miss it.
+	if err := s.persesDAO.Transaction(func(tx databaseModel.DAO) error {
+		dao := NewDAO(tx)
+		if createErr := dao.Create(entity); createErr != nil {
+			return createErr
+		}
+		return s.saveRevision(dao, entity, author)
+	}); err != nil {
 		return nil, err
 	}
+	s.index.Add(entity)
 	return entity, nil
 }
 
@@ -95,16 +145,55 @@ func (s *service) update(entity *v1.Dashboard, parameters shared.Parameters) (*v
 	if err != nil {
 		return nil, err
 	}
-	entity.Metadata.Update(oldEntity.Metadata)
-	if updateErr := s.dao.Update(entity); updateErr != nil {
-		logrus.WithError(updateErr).Errorf("unable to perform the update of the dashboard %q, something wrong with the database", entity.Metadata.Name)
+	if updateErr := shared.UpdateEntity(parameters, entity, oldEntity, func(previousVersion uint64) error {
+		return s.persesDAO.Transaction(func(tx databaseModel.DAO) error {
+			dao := NewDAO(tx)
+			if txErr := dao.Update(entity, previousVersion); txErr != nil {
+				return txErr
+			}
+			return s.saveRevision(dao, entity, parameters.Author)
+		})
+	}); updateErr != nil {
 		return nil, updateErr
 	}
+	s.index.Add(entity)
 	return entity, nil
 }
 
 func (s *service) Delete(parameters shared.Parameters) error {
-	return s.dao.Delete(parameters.Project, parameters.Name)
+	if s.trashConfig.Disable {
+		if err := s.dao.Delete(parameters.Project, parameters.Name); err != nil {
+			return err
+		}
+	} else if err := s.moveToTrash(parameters.Project, parameters.Name); err != nil {
+		return err
+	}
+	s.index.Remove(v1.KindDashboard, parameters.Project, parameters.Name)
+	return nil
+}
+
+// moveToTrash removes the dashboard and its revisions, and keeps them in the trash so the dashboard can be restored.
+func (s *service) moveToTrash(project string, name string) error {
+	return s.persesDAO.Transaction(func(tx databaseModel.DAO) error {
+		dao := NewDAO(tx)
+		entity, err := dao.Get(project, name)
+		if err != nil {
+			return err
+		}
+		revisions, err := dao.ListRevisions(project, name)
+		if err != nil {
+			return err
+		}
+		if deleteErr := dao.Delete(project, name); deleteErr != nil {
+			return deleteErr
+		}
+		entry := v1.NewTrashEntry(
+			v1.TrashResource{Kind: v1.KindDashboard, Project: project, Name: name},
+			&v1.TrashContent{Dashboards: []*v1.Dashboard{entity}, DashboardRevisions: revisions},
+			time.Duration(s.trashConfig.Retention),
+		)
+		return trashImpl.NewDAO(tx).Create(entry)
+	})
 }
 
 func (s *service) Get(parameters shared.Parameters) (interface{}, error) {
@@ -115,7 +204,100 @@ func (s *service) List(q databaseModel.Query, _ shared.Parameters) (interface{},
 	return s.dao.List(q)
 }
 
+func (s *service) Watch(ctx context.Context, q databaseModel.Query, _ shared.Parameters) (<-chan *v1.WatchEvent, error) {
+	query, ok := q.(*dashboard.Query)
+	if !ok {
+		return nil, fmt.Errorf("wrong query format, attempting Dashboard query, received '%T'", q)
+	}
+	return s.dao.Watch(ctx, query)
+}
+
+func (s *service) ListRevisions(parameters shared.Parameters) ([]*v1.DashboardRevision, error) {
+	// ensure the dashboard exists, so we don't return an empty list for a dashboard that doesn't exist.
+	if _, err := s.dao.Get(parameters.Project, parameters.Name); err != nil {
+		return nil, err
+	}
+	revisions, err := s.dao.ListRevisions(parameters.Project, parameters.Name)
+	if err != nil {
+		return nil, err
+	}
+	sortRevisions(revisions)
+	return revisions, nil
+}
+
+func (s *service) GetRevision(parameters shared.Parameters, version uint64) (*v1.DashboardRevision, error) {
+	return s.dao.GetRevision(parameters.Project, parameters.Name, version)
+}
+
+func (s *service) RestoreRevision(parameters shared.Parameters, version uint64) (*v1.Dashboard, error) {
+	revision, err := s.dao.GetRevision(parameters.Project, parameters.Name, version)
+	if err != nil {
+		return nil, err
+	}
+	// The revision is decoded as a Dashboard, so it goes through the same checks as a dashboard sent by a user.
+	data, err := json.Marshal(revision.ToDashboard())
+	if err != nil {
+		return nil, err
+	}
+	entity := &v1.Dashboard{}
+	if unmarshalErr := json.Unmarshal(data, entity); unmarshalErr != nil {
+		return nil, shared.HandleBadRequestError(unmarshalErr.Error())
+	}
+	// The version of the revision is obviously not the current one. Reset it, so it's not taken for an outdated update.
+	entity.Metadata.Version = 0
+	return s.withAuthor(parameters.Author).update(entity, shared.Parameters{Project: parameters.Project, Name: parameters.Name, Author: parameters.Author})
+}
+
+// saveRevision stores a copy of the dashboard and then removes the revisions that are out of the retention policy.
+// dao must be bound to the transaction writing the dashboard, so the dashboard is not saved without its revision.
+func (s *service) saveRevision(dao dashboard.DAO, entity *v1.Dashboard, author string) error {
+	revision := v1.NewDashboardRevision(entity, author)
+	if err := dao.CreateRevision(revision); err != nil {
+		return fmt.Errorf("unable to save the revision %d of the dashboard %q: %w", entity.Metadata.Version, entity.Metadata.Name, err)
+	}
+	if s.revisionConfig.MaxCount == 0 && s.revisionConfig.MaxAge == 0 {
+		return nil
+	}
+	revisions, err := dao.ListRevisions(entity.Metadata.Project, entity.Metadata.Name)
+	if err != nil {
+		return fmt.Errorf("unable to get the revisions of the dashboard %q: %w", entity.Metadata.Name, err)
+	}
+	sortRevisions(revisions)
+	for _, pruned := range s.revisionsToPrune(revisions) {
+		if deleteErr := dao.DeleteRevision(pruned.Metadata.Project, pruned.Metadata.Name, pruned.Metadata.Version); deleteErr != nil {
+			return fmt.Errorf("unable to remove the revision %d of the dashboard %q: %w", pruned.Metadata.Version, pruned.Metadata.Name, deleteErr)
+		}
+	}
+	return nil
+}
+
+// revisionsToPrune returns the revisions out of the retention policy. The revisions must be sorted from the most recent to the oldest one.
+func (s *service) revisionsToPrune(revisions []*v1.DashboardRevision) []*v1.DashboardRevision {
+	var result []*v1.DashboardRevision
+	oldestDate := time.Now().Add(-time.Duration(s.revisionConfig.MaxAge))
+	// the first revision is the current version of the dashboard, and it is always kept.
+	for i := 1; i < len(revisions); i++ {
+		revision := revisions[i]
+		if s.revisionConfig.MaxCount > 0 && i >= s.revisionConfig.MaxCount {
+			result = append(result, revision)
+		} else if s.revisionConfig.MaxAge > 0 && revision.Metadata.UpdatedAt.Before(oldestDate) {
+			result = append(result, revision)
+		}
+	}
+	return result
+}
+
+// sortRevisions sorts the revisions from the most recent to the oldest one.
+func sortRevisions(revisions []*v1.DashboardRevision) {
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Metadata.Version > revisions[j].Metadata.Version
+	})
+}
+
 func (s *service) Validate(entity *v1.Dashboard) error {
+	if s.inTransaction {
+		return nil
+	}
 	projectVars, projectVarsErr := s.collectProjectVariables(entity.Metadata.Project)
 	if projectVarsErr != nil {
 		return shared.HandleError(projectVarsErr)
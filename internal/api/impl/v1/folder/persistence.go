@@ -14,8 +14,11 @@
 package folder
 
 import (
+	"context"
+
 	"github.com/perses/perses/internal/api/interface/v1/folder"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelAPI "github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
@@ -32,12 +35,16 @@ func NewDAO(persesDAO databaseModel.DAO) folder.DAO {
 	}
 }
 
+func (d *dao) WithAuthor(author string) folder.DAO {
+	return NewDAO(databaseModel.WithAuthor(d.client, author))
+}
+
 func (d *dao) Create(entity *v1.Folder) error {
 	return d.client.Create(entity)
 }
 
-func (d *dao) Update(entity *v1.Folder) error {
-	return d.client.Upsert(entity)
+func (d *dao) Update(entity *v1.Folder, expectedVersion uint64) error {
+	return d.client.Update(entity, expectedVersion)
 }
 
 func (d *dao) Delete(project string, name string) error {
@@ -58,3 +65,14 @@ func (d *dao) List(q databaseModel.Query) ([]*v1.Folder, error) {
 	err := d.client.Query(q, &result)
 	return result, err
 }
+
+func (d *dao) Watch(ctx context.Context, q *folder.Query) (<-chan *v1.WatchEvent, error) {
+	filter := databaseModel.WatchFilter{
+		Project:        q.Project,
+		NamePrefix:     q.NamePrefix,
+		LabelSelection: q.LabelSelection,
+	}
+	return databaseModel.Watch(ctx, d.client, d.kind, filter, func() modelAPI.Entity {
+		return &v1.Folder{}
+	})
+}
@@ -14,6 +14,7 @@
 package folder
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/perses/perses/internal/api/interface/v1/folder"
@@ -35,7 +36,21 @@ func NewService(dao folder.DAO) folder.Service {
 	}
 }
 
-func (s *service) Create(entity api.Entity) (interface{}, error) {
+// InTransaction returns a copy of the service writing with tx. There is nothing to do once the transaction is committed.
+func (s *service) InTransaction(tx databaseModel.DAO) (shared.ToolboxService, func()) {
+	txService := *s
+	txService.dao = NewDAO(tx)
+	return &txService, func() {}
+}
+
+// WithAuthor returns a copy of the service whose changes are attributed to author.
+func (s *service) WithAuthor(author string) shared.ToolboxService {
+	authorService := *s
+	authorService.dao = s.dao.WithAuthor(author)
+	return &authorService
+}
+
+func (s *service) Create(entity api.Entity, _ shared.Parameters) (interface{}, error) {
 	if object, ok := entity.(*v1.Folder); ok {
 		return s.create(object)
 	}
@@ -74,9 +89,9 @@ func (s *service) update(entity *v1.Folder, parameters shared.Parameters) (*v1.F
 	if err != nil {
 		return nil, err
 	}
-	entity.Metadata.Update(oldEntity.Metadata)
-	if updateErr := s.dao.Update(entity); updateErr != nil {
-		logrus.WithError(updateErr).Errorf("unable to perform the update of the Folder %q, something wrong with the database", entity.Metadata.Name)
+	if updateErr := shared.UpdateEntity(parameters, entity, oldEntity, func(previousVersion uint64) error {
+		return s.dao.Update(entity, previousVersion)
+	}); updateErr != nil {
 		return nil, updateErr
 	}
 	return entity, nil
@@ -93,3 +108,11 @@ func (s *service) Get(parameters shared.Parameters) (interface{}, error) {
 func (s *service) List(q databaseModel.Query, _ shared.Parameters) (interface{}, error) {
 	return s.dao.List(q)
 }
+
+func (s *service) Watch(ctx context.Context, q databaseModel.Query, _ shared.Parameters) (<-chan *v1.WatchEvent, error) {
+	query, ok := q.(*folder.Query)
+	if !ok {
+		return nil, fmt.Errorf("wrong query format, attempting Folder query, received '%T'", q)
+	}
+	return s.dao.Watch(ctx, query)
+}
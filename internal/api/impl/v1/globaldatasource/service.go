@@ -14,6 +14,7 @@
 package globaldatasource
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/perses/perses/internal/api/interface/v1/globaldatasource"
@@ -30,6 +31,8 @@ type service struct {
 	globaldatasource.Service
 	dao globaldatasource.DAO
 	sch schemas.Schemas
+	// inTransaction is true for the service returned by InTransaction.
+	inTransaction bool
 }
 
 func NewService(dao globaldatasource.DAO, sch schemas.Schemas) globaldatasource.Service {
@@ -39,7 +42,23 @@ func NewService(dao globaldatasource.DAO, sch schemas.Schemas) globaldatasource.
 	}
 }
 
-func (s *service) Create(entity api.Entity) (interface{}, error) {
+// InTransaction returns a copy of the service writing with tx. There is nothing to do once the transaction is committed.
+// The resources are not validated, as the caller validates them against the ones written in the same transaction.
+func (s *service) InTransaction(tx databaseModel.DAO) (shared.ToolboxService, func()) {
+	txService := *s
+	txService.dao = NewDAO(tx)
+	txService.inTransaction = true
+	return &txService, func() {}
+}
+
+// WithAuthor returns a copy of the service whose changes are attributed to author.
+func (s *service) WithAuthor(author string) shared.ToolboxService {
+	authorService := *s
+	authorService.dao = s.dao.WithAuthor(author)
+	return &authorService
+}
+
+func (s *service) Create(entity api.Entity, _ shared.Parameters) (interface{}, error) {
 	if object, ok := entity.(*v1.GlobalDatasource); ok {
 		return s.create(object)
 	}
@@ -78,9 +97,9 @@ func (s *service) update(entity *v1.GlobalDatasource, parameters shared.Paramete
 	if err != nil {
 		return nil, err
 	}
-	entity.Metadata.Update(oldEntity.Metadata)
-	if updateErr := s.dao.Update(entity); updateErr != nil {
-		logrus.WithError(updateErr).Errorf("unable to perform the update of the GlobalDatasource %q, something wrong with the database", entity.Metadata.Name)
+	if updateErr := shared.UpdateEntity(parameters, entity, oldEntity, func(previousVersion uint64) error {
+		return s.dao.Update(entity, previousVersion)
+	}); updateErr != nil {
 		return nil, updateErr
 	}
 	return entity, nil
@@ -103,7 +122,18 @@ func (s *service) List(q databaseModel.Query, _ shared.Parameters) (interface{},
 	return v1.FilterDatasource(dtsQuery.Kind, dtsQuery.Default, dtsList), nil
 }
 
+func (s *service) Watch(ctx context.Context, q databaseModel.Query, _ shared.Parameters) (<-chan *v1.WatchEvent, error) {
+	query, ok := q.(*globaldatasource.Query)
+	if !ok {
+		return nil, fmt.Errorf("wrong query format, attempting GlobalDatasource query, received '%T'", q)
+	}
+	return s.dao.Watch(ctx, query)
+}
+
 func (s *service) validate(entity *v1.GlobalDatasource) error {
+	if s.inTransaction {
+		return nil
+	}
 	var list []*v1.GlobalDatasource
 	if entity.Spec.Default {
 		var err error
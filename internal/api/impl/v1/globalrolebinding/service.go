@@ -0,0 +1,148 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalrolebinding
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/perses/perses/internal/api/impl/v1/rolebinding"
+	"github.com/perses/perses/internal/api/interface/v1/globalrole"
+	"github.com/perses/perses/internal/api/interface/v1/globalrolebinding"
+	"github.com/perses/perses/internal/api/interface/v1/serviceaccount"
+	"github.com/perses/perses/internal/api/interface/v1/user"
+	"github.com/perses/perses/internal/api/shared"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/internal/api/shared/rbac"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+type service struct {
+	globalrolebinding.Service
+	dao               globalrolebinding.DAO
+	globalRoleDAO     globalrole.DAO
+	userDAO           user.DAO
+	serviceAccountDAO serviceaccount.DAO
+	rbac              rbac.RBAC
+}
+
+func NewService(dao globalrolebinding.DAO, globalRoleDAO globalrole.DAO, userDAO user.DAO, serviceAccountDAO serviceaccount.DAO, rbac rbac.RBAC) globalrolebinding.Service {
+	return &service{
+		dao:               dao,
+		globalRoleDAO:     globalRoleDAO,
+		userDAO:           userDAO,
+		serviceAccountDAO: serviceAccountDAO,
+		rbac:              rbac,
+	}
+}
+
+// WithAuthor returns a copy of the service whose changes are attributed to author.
+func (s *service) WithAuthor(author string) shared.ToolboxService {
+	authorService := *s
+	authorService.dao = s.dao.WithAuthor(author)
+	return &authorService
+}
+
+func (s *service) Create(entity api.Entity, _ shared.Parameters) (interface{}, error) {
+	if object, ok := entity.(*v1.GlobalRoleBinding); ok {
+		return s.create(object)
+	}
+	return nil, shared.HandleBadRequestError(fmt.Sprintf("wrong entity format, attempting GlobalRoleBinding format, received '%T'", entity))
+}
+
+func (s *service) create(entity *v1.GlobalRoleBinding) (*v1.GlobalRoleBinding, error) {
+	if err := s.validate(entity); err != nil {
+		return nil, err
+	}
+	// Update the time contains in the entity
+	entity.Metadata.CreateNow()
+	if err := s.dao.Create(entity); err != nil {
+		return nil, err
+	}
+	s.refresh()
+	return entity, nil
+}
+
+func (s *service) Update(entity api.Entity, parameters shared.Parameters) (interface{}, error) {
+	if object, ok := entity.(*v1.GlobalRoleBinding); ok {
+		return s.update(object, parameters)
+	}
+	return nil, shared.HandleBadRequestError(fmt.Sprintf("wrong entity format, attempting GlobalRoleBinding format, received '%T'", entity))
+}
+
+func (s *service) update(entity *v1.GlobalRoleBinding, parameters shared.Parameters) (*v1.GlobalRoleBinding, error) {
+	if entity.Metadata.Name != parameters.Name {
+		logrus.Debugf("name in GlobalRoleBinding %q and name from the http request: %q don't match", entity.Metadata.Name, parameters.Name)
+		return nil, shared.HandleBadRequestError("metadata.name and the name in the http path request don't match")
+	}
+	// find the previous version of the GlobalRoleBinding
+	oldEntity, err := s.dao.Get(parameters.Name)
+	if err != nil {
+		return nil, err
+	}
+	if validateErr := s.validate(entity); validateErr != nil {
+		return nil, validateErr
+	}
+	if updateErr := shared.UpdateEntity(parameters, entity, oldEntity, func(previousVersion uint64) error {
+		return s.dao.Update(entity, previousVersion)
+	}); updateErr != nil {
+		return nil, updateErr
+	}
+	s.refresh()
+	return entity, nil
+}
+
+func (s *service) Delete(parameters shared.Parameters) error {
+	if err := s.dao.Delete(parameters.Name); err != nil {
+		return err
+	}
+	s.refresh()
+	return nil
+}
+
+func (s *service) Get(parameters shared.Parameters) (interface{}, error) {
+	return s.dao.Get(parameters.Name)
+}
+
+func (s *service) List(q databaseModel.Query, _ shared.Parameters) (interface{}, error) {
+	return s.dao.List(q)
+}
+
+func (s *service) Watch(ctx context.Context, q databaseModel.Query, _ shared.Parameters) (<-chan *v1.WatchEvent, error) {
+	query, ok := q.(*globalrolebinding.Query)
+	if !ok {
+		return nil, fmt.Errorf("wrong query format, attempting GlobalRoleBinding query, received '%T'", q)
+	}
+	return s.dao.Watch(ctx, query)
+}
+
+// validate verifies the global role, the users and the service accounts bound exist.
+func (s *service) validate(entity *v1.GlobalRoleBinding) error {
+	if _, err := s.globalRoleDAO.Get(entity.Spec.Role); err != nil {
+		if databaseModel.IsKeyNotFound(err) {
+			return shared.HandleBadRequestError(fmt.Sprintf("the global role %q doesn't exist", entity.Spec.Role))
+		}
+		return err
+	}
+	return rolebinding.ValidateSubjects(s.userDAO, s.serviceAccountDAO, entity.Spec.Subjects)
+}
+
+// refresh applies the change to the permissions of the users.
+func (s *service) refresh() {
+	if err := s.rbac.Refresh(); err != nil {
+		logrus.WithError(err).Error("unable to reload the permissions")
+	}
+}
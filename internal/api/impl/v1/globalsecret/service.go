@@ -14,6 +14,7 @@
 package globalsecret
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/perses/perses/internal/api/interface/v1/globalsecret"
@@ -38,7 +39,21 @@ func NewService(dao globalsecret.DAO, crypto crypto.Crypto) globalsecret.Service
 	}
 }
 
-func (s *service) Create(entity api.Entity) (interface{}, error) {
+// InTransaction returns a copy of the service writing with tx. There is nothing to do once the transaction is committed.
+func (s *service) InTransaction(tx databaseModel.DAO) (shared.ToolboxService, func()) {
+	txService := *s
+	txService.dao = NewDAO(tx)
+	return &txService, func() {}
+}
+
+// WithAuthor returns a copy of the service whose changes are attributed to author.
+func (s *service) WithAuthor(author string) shared.ToolboxService {
+	authorService := *s
+	authorService.dao = s.dao.WithAuthor(author)
+	return &authorService
+}
+
+func (s *service) Create(entity api.Entity, _ shared.Parameters) (interface{}, error) {
 	if object, ok := entity.(*v1.GlobalSecret); ok {
 		return s.create(object)
 	}
@@ -75,14 +90,13 @@ func (s *service) update(entity *v1.GlobalSecret, parameters shared.Parameters)
 	if err != nil {
 		return nil, err
 	}
-	entity.Metadata.Update(oldEntity.Metadata)
-
 	if encryptErr := s.crypto.Encrypt(&entity.Spec); encryptErr != nil {
 		logrus.WithError(encryptErr).Errorf("unable to encrypt the secret spec")
 		return nil, shared.InternalError
 	}
-	if updateErr := s.dao.Update(entity); updateErr != nil {
-		logrus.WithError(updateErr).Errorf("unable to perform the update of the GlobalSecret %q, something wrong with the database", entity.Metadata.Name)
+	if updateErr := shared.UpdateEntity(parameters, entity, oldEntity, func(previousVersion uint64) error {
+		return s.dao.Update(entity, previousVersion)
+	}); updateErr != nil {
 		return nil, updateErr
 	}
 	return v1.NewPublicGlobalSecret(entity), nil
@@ -100,6 +114,19 @@ func (s *service) Get(parameters shared.Parameters) (interface{}, error) {
 	return v1.NewPublicGlobalSecret(scrt), nil
 }
 
+// GetPatchTarget returns the GlobalSecret with its spec decrypted, so a patch can modify the secret values that Get hides.
+func (s *service) GetPatchTarget(parameters shared.Parameters) (api.Entity, error) {
+	scrt, err := s.dao.Get(parameters.Name)
+	if err != nil {
+		return nil, err
+	}
+	if decryptErr := s.crypto.Decrypt(&scrt.Spec); decryptErr != nil {
+		logrus.WithError(decryptErr).Errorf("unable to decrypt the secret spec")
+		return nil, shared.InternalError
+	}
+	return scrt, nil
+}
+
 func (s *service) List(q databaseModel.Query, _ shared.Parameters) (interface{}, error) {
 	l, err := s.dao.List(q)
 	if err != nil {
@@ -111,3 +138,17 @@ func (s *service) List(q databaseModel.Query, _ shared.Parameters) (interface{},
 	}
 	return result, nil
 }
+
+func (s *service) Watch(ctx context.Context, q databaseModel.Query, _ shared.Parameters) (<-chan *v1.WatchEvent, error) {
+	query, ok := q.(*globalsecret.Query)
+	if !ok {
+		return nil, fmt.Errorf("wrong query format, attempting GlobalSecret query, received '%T'", q)
+	}
+	events, err := s.dao.Watch(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return shared.MapWatchEvents(ctx, events, func(object interface{}) interface{} {
+		return v1.NewPublicGlobalSecret(object.(*v1.GlobalSecret))
+	}), nil
+}
@@ -14,8 +14,11 @@
 package globalvariable
 
 import (
+	"context"
+
 	"github.com/perses/perses/internal/api/interface/v1/globalvariable"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelAPI "github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
@@ -32,12 +35,16 @@ func NewDAO(persesDAO databaseModel.DAO) globalvariable.DAO {
 	}
 }
 
+func (d *dao) WithAuthor(author string) globalvariable.DAO {
+	return NewDAO(databaseModel.WithAuthor(d.client, author))
+}
+
 func (d *dao) Create(entity *v1.GlobalVariable) error {
 	return d.client.Create(entity)
 }
 
-func (d *dao) Update(entity *v1.GlobalVariable) error {
-	return d.client.Upsert(entity)
+func (d *dao) Update(entity *v1.GlobalVariable, expectedVersion uint64) error {
+	return d.client.Update(entity, expectedVersion)
 }
 
 func (d *dao) Delete(name string) error {
@@ -54,3 +61,13 @@ func (d *dao) List(q databaseModel.Query) ([]*v1.GlobalVariable, error) {
 	err := d.client.Query(q, &result)
 	return result, err
 }
+
+func (d *dao) Watch(ctx context.Context, q *globalvariable.Query) (<-chan *v1.WatchEvent, error) {
+	filter := databaseModel.WatchFilter{
+		NamePrefix:     q.NamePrefix,
+		LabelSelection: q.LabelSelection,
+	}
+	return databaseModel.Watch(ctx, d.client, d.kind, filter, func() modelAPI.Entity {
+		return &v1.GlobalVariable{}
+	})
+}
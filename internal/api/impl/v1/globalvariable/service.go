@@ -14,12 +14,14 @@
 package globalvariable
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/perses/perses/internal/api/interface/v1/globalvariable"
 	"github.com/perses/perses/internal/api/shared"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
 	"github.com/perses/perses/internal/api/shared/schemas"
+	searchIndex "github.com/perses/perses/internal/api/shared/search"
 	"github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 	"github.com/sirupsen/logrus"
@@ -27,18 +29,36 @@ import (
 
 type service struct {
 	globalvariable.Service
-	dao globalvariable.DAO
-	sch schemas.Schemas
+	dao   globalvariable.DAO
+	sch   schemas.Schemas
+	index searchIndex.Index
 }
 
-func NewService(dao globalvariable.DAO, sch schemas.Schemas) globalvariable.Service {
+func NewService(dao globalvariable.DAO, sch schemas.Schemas, index searchIndex.Index) globalvariable.Service {
 	return &service{
-		dao: dao,
-		sch: sch,
+		dao:   dao,
+		sch:   sch,
+		index: index,
 	}
 }
 
-func (s *service) Create(entity api.Entity) (interface{}, error) {
+// InTransaction returns a copy of the service writing with tx. The search index is updated once commit is called.
+func (s *service) InTransaction(tx databaseModel.DAO) (shared.ToolboxService, func()) {
+	txService := *s
+	txService.dao = NewDAO(tx)
+	index, commit := searchIndex.NewDeferred(s.index)
+	txService.index = index
+	return &txService, commit
+}
+
+// WithAuthor returns a copy of the service whose changes are attributed to author.
+func (s *service) WithAuthor(author string) shared.ToolboxService {
+	authorService := *s
+	authorService.dao = s.dao.WithAuthor(author)
+	return &authorService
+}
+
+func (s *service) Create(entity api.Entity, _ shared.Parameters) (interface{}, error) {
 	if object, ok := entity.(*v1.GlobalVariable); ok {
 		return s.create(object)
 	}
@@ -55,6 +75,7 @@ func (s *service) create(entity *v1.GlobalVariable) (*v1.GlobalVariable, error)
 	if err := s.dao.Create(entity); err != nil {
 		return nil, err
 	}
+	s.index.Add(entity)
 	return entity, nil
 }
 
@@ -78,16 +99,21 @@ func (s *service) update(entity *v1.GlobalVariable, parameters shared.Parameters
 	if err != nil {
 		return nil, err
 	}
-	entity.Metadata.Update(oldEntity.Metadata)
-	if updateErr := s.dao.Update(entity); updateErr != nil {
-		logrus.WithError(updateErr).Errorf("unable to perform the update of the Globalvariable %q, something wrong with the database", entity.Metadata.Name)
+	if updateErr := shared.UpdateEntity(parameters, entity, oldEntity, func(previousVersion uint64) error {
+		return s.dao.Update(entity, previousVersion)
+	}); updateErr != nil {
 		return nil, updateErr
 	}
+	s.index.Add(entity)
 	return entity, nil
 }
 
 func (s *service) Delete(parameters shared.Parameters) error {
-	return s.dao.Delete(parameters.Name)
+	if err := s.dao.Delete(parameters.Name); err != nil {
+		return err
+	}
+	s.index.Remove(v1.KindGlobalVariable, "", parameters.Name)
+	return nil
 }
 
 func (s *service) Get(parameters shared.Parameters) (interface{}, error) {
@@ -97,3 +123,11 @@ func (s *service) Get(parameters shared.Parameters) (interface{}, error) {
 func (s *service) List(q databaseModel.Query, _ shared.Parameters) (interface{}, error) {
 	return s.dao.List(q)
 }
+
+func (s *service) Watch(ctx context.Context, q databaseModel.Query, _ shared.Parameters) (<-chan *v1.WatchEvent, error) {
+	query, ok := q.(*globalvariable.Query)
+	if !ok {
+		return nil, fmt.Errorf("wrong query format, attempting GlobalVariable query, received '%T'", q)
+	}
+	return s.dao.Watch(ctx, query)
+}
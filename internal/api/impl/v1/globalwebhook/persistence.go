@@ -0,0 +1,73 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalwebhook
+
+import (
+	"context"
+
+	"github.com/perses/perses/internal/api/interface/v1/globalwebhook"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type dao struct {
+	globalwebhook.DAO
+	client databaseModel.DAO
+	kind   v1.Kind
+}
+
+func NewDAO(persesDAO databaseModel.DAO) globalwebhook.DAO {
+	return &dao{
+		client: persesDAO,
+		kind:   v1.KindGlobalWebhook,
+	}
+}
+
+func (d *dao) WithAuthor(author string) globalwebhook.DAO {
+	return NewDAO(databaseModel.WithAuthor(d.client, author))
+}
+
+func (d *dao) Create(entity *v1.GlobalWebhook) error {
+	return d.client.Create(entity)
+}
+
+func (d *dao) Update(entity *v1.GlobalWebhook, expectedVersion uint64) error {
+	return d.client.Update(entity, expectedVersion)
+}
+
+func (d *dao) Delete(name string) error {
+	return d.client.Delete(d.kind, v1.NewMetadata(name))
+}
+
+func (d *dao) Get(name string) (*v1.GlobalWebhook, error) {
+	entity := &v1.GlobalWebhook{}
+	return entity, d.client.Get(d.kind, v1.NewMetadata(name), entity)
+}
+
+func (d *dao) List(q databaseModel.Query) ([]*v1.GlobalWebhook, error) {
+	var result []*v1.GlobalWebhook
+	err := d.client.Query(q, &result)
+	return result, err
+}
+
+func (d *dao) Watch(ctx context.Context, q *globalwebhook.Query) (<-chan *v1.WatchEvent, error) {
+	filter := databaseModel.WatchFilter{
+		NamePrefix:     q.NamePrefix,
+		LabelSelection: q.LabelSelection,
+	}
+	return databaseModel.Watch(ctx, d.client, d.kind, filter, func() modelAPI.Entity {
+		return &v1.GlobalWebhook{}
+	})
+}
@@ -14,16 +14,35 @@
 package project
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	"github.com/perses/perses/internal/api/config"
+	dashboardImpl "github.com/perses/perses/internal/api/impl/v1/dashboard"
+	datasourceImpl "github.com/perses/perses/internal/api/impl/v1/datasource"
+	folderImpl "github.com/perses/perses/internal/api/impl/v1/folder"
+	roleImpl "github.com/perses/perses/internal/api/impl/v1/role"
+	rolebindingImpl "github.com/perses/perses/internal/api/impl/v1/rolebinding"
+	secretImpl "github.com/perses/perses/internal/api/impl/v1/secret"
+	trashImpl "github.com/perses/perses/internal/api/impl/v1/trash"
+	variableImpl "github.com/perses/perses/internal/api/impl/v1/variable"
+	webhookImpl "github.com/perses/perses/internal/api/impl/v1/webhook"
+	webhookdeliveryImpl "github.com/perses/perses/internal/api/impl/v1/webhookdelivery"
 	"github.com/perses/perses/internal/api/interface/v1/dashboard"
 	"github.com/perses/perses/internal/api/interface/v1/datasource"
 	"github.com/perses/perses/internal/api/interface/v1/folder"
 	"github.com/perses/perses/internal/api/interface/v1/project"
+	"github.com/perses/perses/internal/api/interface/v1/role"
+	"github.com/perses/perses/internal/api/interface/v1/rolebinding"
 	"github.com/perses/perses/internal/api/interface/v1/secret"
 	"github.com/perses/perses/internal/api/interface/v1/variable"
+	"github.com/perses/perses/internal/api/interface/v1/webhook"
+	"github.com/perses/perses/internal/api/interface/v1/webhookdelivery"
 	"github.com/perses/perses/internal/api/shared"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/internal/api/shared/rbac"
+	searchIndex "github.com/perses/perses/internal/api/shared/search"
 	"github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 	"github.com/sirupsen/logrus"
@@ -31,26 +50,43 @@ import (
 
 type service struct {
 	project.Service
-	dao           project.DAO
-	folderDAO     folder.DAO
-	datasourceDAO datasource.DAO
-	dashboardDAO  dashboard.DAO
-	secretDAO     secret.DAO
-	variableDAO   variable.DAO
+	dao project.DAO
+	// persesDAO is used to delete a project and all its resources in a single transaction.
+	persesDAO   databaseModel.DAO
+	trashConfig config.Trash
+	index       searchIndex.Index
+	rbac        rbac.RBAC
 }
 
-func NewService(dao project.DAO, folderDAO folder.DAO, datasourceDAO datasource.DAO, dashboardDAO dashboard.DAO, secretDAO secret.DAO, variableDAO variable.DAO) project.Service {
+func NewService(dao project.DAO, persesDAO databaseModel.DAO, trashConfig config.Trash, index searchIndex.Index, rbac rbac.RBAC) project.Service {
 	return &service{
-		dao:           dao,
-		folderDAO:     folderDAO,
-		datasourceDAO: datasourceDAO,
-		dashboardDAO:  dashboardDAO,
-		secretDAO:     secretDAO,
-		variableDAO:   variableDAO,
+		dao:         dao,
+		persesDAO:   persesDAO,
+		trashConfig: trashConfig,
+		index:       index,
+		rbac:        rbac,
 	}
 }
 
-func (s *service) Create(entity api.Entity) (interface{}, error) {
+// InTransaction returns a copy of the service writing with tx. The search index is updated once commit is called.
+func (s *service) InTransaction(tx databaseModel.DAO) (shared.ToolboxService, func()) {
+	txService := *s
+	txService.dao = NewDAO(tx)
+	txService.persesDAO = tx
+	index, commit := searchIndex.NewDeferred(s.index)
+	txService.index = index
+	return &txService, commit
+}
+
+// WithAuthor returns a copy of the service whose changes are attributed to author.
+func (s *service) WithAuthor(author string) shared.ToolboxService {
+	authorService := *s
+	authorService.dao = s.dao.WithAuthor(author)
+	authorService.persesDAO = databaseModel.WithAuthor(s.persesDAO, author)
+	return &authorService
+}
+
+func (s *service) Create(entity api.Entity, _ shared.Parameters) (interface{}, error) {
 	if object, ok := entity.(*v1.Project); ok {
 		return s.create(object)
 	}
@@ -83,9 +119,9 @@ func (s *service) update(entity *v1.Project, parameters shared.Parameters) (*v1.
 	if err != nil {
 		return nil, err
 	}
-	entity.Metadata.Update(oldEntity.Metadata)
-	if updateErr := s.dao.Update(entity); updateErr != nil {
-		logrus.WithError(updateErr).Errorf("unable to perform the update of the project %q, something wrong with the database", entity.Metadata.Name)
+	if updateErr := shared.UpdateEntity(parameters, entity, oldEntity, func(previousVersion uint64) error {
+		return s.dao.Update(entity, previousVersion)
+	}); updateErr != nil {
 		return nil, updateErr
 	}
 	return entity, nil
@@ -93,27 +129,113 @@ func (s *service) update(entity *v1.Project, parameters shared.Parameters) (*v1.
 
 func (s *service) Delete(parameters shared.Parameters) error {
 	projectName := parameters.Name
-	if err := s.folderDAO.DeleteAll(projectName); err != nil {
-		logrus.WithError(err).Error("unable to delete all folders")
+	// The resources and the project are removed in the same transaction, so the project is never left half-deleted.
+	// Unless the trash is disabled, everything removed is kept in the trash, within the same transaction.
+	err := s.persesDAO.Transaction(func(tx databaseModel.DAO) error {
+		var entry *v1.TrashEntry
+		if !s.trashConfig.Disable {
+			content, err := collectContent(tx, projectName)
+			if err != nil {
+				return err
+			}
+			entry = v1.NewTrashEntry(
+				v1.TrashResource{Kind: v1.KindProject, Project: projectName, Name: projectName},
+				content,
+				time.Duration(s.trashConfig.Retention),
+			)
+		}
+		if err := folderImpl.NewDAO(tx).DeleteAll(projectName); err != nil {
+			logrus.WithError(err).Error("unable to delete all folders")
+			return err
+		}
+		if err := dashboardImpl.NewDAO(tx).DeleteAll(projectName); err != nil {
+			logrus.WithError(err).Error("unable to delete all dashboards")
+			return err
+		}
+		if err := datasourceImpl.NewDAO(tx).DeleteAll(projectName); err != nil {
+			logrus.WithError(err).Error("unable to delete all datasources")
+			return err
+		}
+		if err := secretImpl.NewDAO(tx).DeleteAll(projectName); err != nil {
+			logrus.WithError(err).Error("unable to delete all secrets")
+			return err
+		}
+		if err := variableImpl.NewDAO(tx).DeleteAll(projectName); err != nil {
+			logrus.WithError(err).Error("unable to delete all variables")
+			return err
+		}
+		if err := webhookImpl.NewDAO(tx).DeleteAll(projectName); err != nil {
+			logrus.WithError(err).Error("unable to delete all webhooks")
+			return err
+		}
+		// the deliveries are not kept in the trash, they are only a log of what has been sent.
+		if err := webhookdeliveryImpl.NewDAO(tx).DeleteAll(&webhookdelivery.Query{Kind: v1.KindWebhook, Project: projectName}); err != nil {
+			logrus.WithError(err).Error("unable to delete all webhook deliveries")
+			return err
+		}
+		if err := rolebindingImpl.NewDAO(tx).DeleteAll(projectName); err != nil {
+			logrus.WithError(err).Error("unable to delete all role bindings")
+			return err
+		}
+		if err := roleImpl.NewDAO(tx).DeleteAll(projectName); err != nil {
+			logrus.WithError(err).Error("unable to delete all roles")
+			return err
+		}
+		if err := NewDAO(tx).Delete(projectName); err != nil {
+			return err
+		}
+		if entry == nil {
+			return nil
+		}
+		return trashImpl.NewDAO(tx).Create(entry)
+	})
+	if err != nil {
 		return err
 	}
-	if err := s.dashboardDAO.DeleteAll(projectName); err != nil {
-		logrus.WithError(err).Error("unable to delete all dashboards")
-		return err
+	s.index.RemoveProject(projectName)
+	// the bindings of the project are removed, so they don't give any permission on a new project with the same name.
+	if refreshErr := s.rbac.Refresh(); refreshErr != nil {
+		logrus.WithError(refreshErr).Error("unable to reload the permissions")
 	}
-	if err := s.datasourceDAO.DeleteAll(projectName); err != nil {
-		logrus.WithError(err).Error("unable to delete all datasources")
-		return err
+	return nil
+}
+
+// collectContent returns the project and every resource it contains, so they can be restored from the trash.
+func collectContent(tx databaseModel.DAO, projectName string) (*v1.TrashContent, error) {
+	entity, err := NewDAO(tx).Get(projectName)
+	if err != nil {
+		return nil, err
 	}
-	if err := s.secretDAO.DeleteAll(projectName); err != nil {
-		logrus.WithError(err).Error("unable to delete all secrets")
-		return err
+	content := &v1.TrashContent{Project: entity}
+	dashboardDAO := dashboardImpl.NewDAO(tx)
+	if content.Dashboards, err = dashboardDAO.List(&dashboard.Query{Project: projectName}); err != nil {
+		return nil, err
 	}
-	if err := s.variableDAO.DeleteAll(projectName); err != nil {
-		logrus.WithError(err).Error("unable to delete all variables")
-		return err
+	if content.DashboardRevisions, err = dashboardDAO.ListRevisions(projectName, ""); err != nil {
+		return nil, err
+	}
+	if content.Datasources, err = datasourceImpl.NewDAO(tx).List(&datasource.Query{Project: projectName}); err != nil {
+		return nil, err
+	}
+	if content.Folders, err = folderImpl.NewDAO(tx).List(&folder.Query{Project: projectName}); err != nil {
+		return nil, err
+	}
+	if content.Secrets, err = secretImpl.NewDAO(tx).List(&secret.Query{Project: projectName}); err != nil {
+		return nil, err
+	}
+	if content.Variables, err = variableImpl.NewDAO(tx).List(&variable.Query{Project: projectName}); err != nil {
+		return nil, err
+	}
+	if content.Webhooks, err = webhookImpl.NewDAO(tx).List(&webhook.Query{Project: projectName}); err != nil {
+		return nil, err
+	}
+	if content.Roles, err = roleImpl.NewDAO(tx).List(&role.Query{Project: projectName}); err != nil {
+		return nil, err
+	}
+	if content.RoleBindings, err = rolebindingImpl.NewDAO(tx).List(&rolebinding.Query{Project: projectName}); err != nil {
+		return nil, err
 	}
-	return s.dao.Delete(parameters.Name)
+	return content, nil
 }
 
 func (s *service) Get(parameters shared.Parameters) (interface{}, error) {
@@ -123,3 +245,11 @@ func (s *service) Get(parameters shared.Parameters) (interface{}, error) {
 func (s *service) List(q databaseModel.Query, _ shared.Parameters) (interface{}, error) {
 	return s.dao.List(q)
 }
+
+func (s *service) Watch(ctx context.Context, q databaseModel.Query, _ shared.Parameters) (<-chan *v1.WatchEvent, error) {
+	query, ok := q.(*project.Query)
+	if !ok {
+		return nil, fmt.Errorf("wrong query format, attempting Project query, received '%T'", q)
+	}
+	return s.dao.Watch(ctx, query)
+}
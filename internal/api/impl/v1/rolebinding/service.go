@@ -0,0 +1,179 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rolebinding
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/perses/perses/internal/api/interface/v1/role"
+	"github.com/perses/perses/internal/api/interface/v1/rolebinding"
+	"github.com/perses/perses/internal/api/interface/v1/serviceaccount"
+	"github.com/perses/perses/internal/api/interface/v1/user"
+	"github.com/perses/perses/internal/api/shared"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/internal/api/shared/rbac"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+type service struct {
+	rolebinding.Service
+	dao               rolebinding.DAO
+	roleDAO           role.DAO
+	userDAO           user.DAO
+	serviceAccountDAO serviceaccount.DAO
+	rbac              rbac.RBAC
+}
+
+func NewService(dao rolebinding.DAO, roleDAO role.DAO, userDAO user.DAO, serviceAccountDAO serviceaccount.DAO, rbac rbac.RBAC) rolebinding.Service {
+	return &service{
+		dao:               dao,
+		roleDAO:           roleDAO,
+		userDAO:           userDAO,
+		serviceAccountDAO: serviceAccountDAO,
+		rbac:              rbac,
+	}
+}
+
+// WithAuthor returns a copy of the service whose changes are attributed to author.
+func (s *service) WithAuthor(author string) shared.ToolboxService {
+	authorService := *s
+	authorService.dao = s.dao.WithAuthor(author)
+	return &authorService
+}
+
+func (s *service) Create(entity api.Entity, _ shared.Parameters) (interface{}, error) {
+	if object, ok := entity.(*v1.RoleBinding); ok {
+		return s.create(object)
+	}
+	return nil, shared.HandleBadRequestError(fmt.Sprintf("wrong entity format, attempting RoleBinding format, received '%T'", entity))
+}
+
+func (s *service) create(entity *v1.RoleBinding) (*v1.RoleBinding, error) {
+	if err := s.validate(entity); err != nil {
+		return nil, err
+	}
+	// Update the time contains in the entity
+	entity.Metadata.CreateNow()
+	if err := s.dao.Create(entity); err != nil {
+		return nil, err
+	}
+	s.refresh()
+	return entity, nil
+}
+
+func (s *service) Update(entity api.Entity, parameters shared.Parameters) (interface{}, error) {
+	if object, ok := entity.(*v1.RoleBinding); ok {
+		return s.update(object, parameters)
+	}
+	return nil, shared.HandleBadRequestError(fmt.Sprintf("wrong entity format, attempting RoleBinding format, received '%T'", entity))
+}
+
+func (s *service) update(entity *v1.RoleBinding, parameters shared.Parameters) (*v1.RoleBinding, error) {
+	if entity.Metadata.Name != parameters.Name {
+		logrus.Debugf("name in RoleBinding %q and name from the http request: %q don't match", entity.Metadata.Name, parameters.Name)
+		return nil, shared.HandleBadRequestError("metadata.name and the name in the http path request don't match")
+	}
+	if len(entity.Metadata.Project) == 0 {
+		entity.Metadata.Project = parameters.Project
+	} else if entity.Metadata.Project != parameters.Project {
+		logrus.Debugf("project in RoleBinding %q and project from the http request %q don't match", entity.Metadata.Project, parameters.Project)
+		return nil, shared.HandleBadRequestError("metadata.project and the project name in the http path request don't match")
+	}
+	// find the previous version of the RoleBinding
+	oldEntity, err := s.dao.Get(parameters.Project, parameters.Name)
+	if err != nil {
+		return nil, err
+	}
+	if validateErr := s.validate(entity); validateErr != nil {
+		return nil, validateErr
+	}
+	if updateErr := shared.UpdateEntity(parameters, entity, oldEntity, func(previousVersion uint64) error {
+		return s.dao.Update(entity, previousVersion)
+	}); updateErr != nil {
+		return nil, updateErr
+	}
+	s.refresh()
+	return entity, nil
+}
+
+func (s *service) Delete(parameters shared.Parameters) error {
+	if err := s.dao.Delete(parameters.Project, parameters.Name); err != nil {
+		return err
+	}
+	s.refresh()
+	return nil
+}
+
+func (s *service) Get(parameters shared.Parameters) (interface{}, error) {
+	return s.dao.Get(parameters.Project, parameters.Name)
+}
+
+func (s *service) List(q databaseModel.Query, _ shared.Parameters) (interface{}, error) {
+	return s.dao.List(q)
+}
+
+func (s *service) Watch(ctx context.Context, q databaseModel.Query, _ shared.Parameters) (<-chan *v1.WatchEvent, error) {
+	query, ok := q.(*rolebinding.Query)
+	if !ok {
+		return nil, fmt.Errorf("wrong query format, attempting RoleBinding query, received '%T'", q)
+	}
+	return s.dao.Watch(ctx, query)
+}
+
+// validate verifies the role, the users and the service accounts bound exist.
+func (s *service) validate(entity *v1.RoleBinding) error {
+	if _, err := s.roleDAO.Get(entity.Metadata.Project, entity.Spec.Role); err != nil {
+		if databaseModel.IsKeyNotFound(err) {
+			return shared.HandleBadRequestError(fmt.Sprintf("the role %q doesn't exist in the project %q", entity.Spec.Role, entity.Metadata.Project))
+		}
+		return err
+	}
+	return ValidateSubjects(s.userDAO, s.serviceAccountDAO, entity.Spec.Subjects)
+}
+
+// ValidateSubjects verifies the users and the service accounts exist. It is used by the GlobalRoleBinding as well.
+// The groups are not verified, as they are only known by the identity providers.
+func ValidateSubjects(userDAO user.DAO, serviceAccountDAO serviceaccount.DAO, subjects []v1.Subject) error {
+	for _, subject := range subjects {
+		var err error
+		var description string
+		switch subject.Kind {
+		case v1.SubjectKindUser:
+			_, err = userDAO.Get(subject.Name)
+			description = "user"
+		case v1.SubjectKindServiceAccount:
+			_, err = serviceAccountDAO.Get(subject.Name)
+			description = "service account"
+		default:
+			continue
+		}
+		if err != nil {
+			if databaseModel.IsKeyNotFound(err) {
+				return shared.HandleBadRequestError(fmt.Sprintf("the %s %q doesn't exist", description, subject.Name))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// refresh applies the change to the permissions of the users.
+func (s *service) refresh() {
+	if err := s.rbac.Refresh(); err != nil {
+		logrus.WithError(err).Error("unable to reload the permissions")
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/interface/v1/search"
+	"github.com/perses/perses/internal/api/shared"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+const PathSearch = "/search"
+
+// Endpoint is the struct that define the endpoint delivered by the path /search
+type Endpoint struct {
+	service search.Service
+}
+
+// NewEndpoint create an instance of the object Endpoint.
+func NewEndpoint(service search.Service) *Endpoint {
+	return &Endpoint{
+		service: service,
+	}
+}
+
+func (e *Endpoint) RegisterRoutes(g *echo.Group) {
+	g.GET(PathSearch, e.Search)
+}
+
+// Search returns the dashboards and the variables matching the text given by the query parameter q, the most relevant first.
+func (e *Endpoint) Search(ctx echo.Context) error {
+	query := &search.Query{}
+	if err := ctx.Bind(query); err != nil {
+		return shared.HandleBadRequestError(err.Error())
+	}
+	query.CanRead = func(kind v1.Kind, project string) bool {
+		return shared.CanRead(ctx, kind, project)
+	}
+	result, err := e.service.Search(query)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"fmt"
+
+	"github.com/perses/perses/internal/api/interface/v1/search"
+	"github.com/perses/perses/internal/api/shared"
+	searchIndex "github.com/perses/perses/internal/api/shared/search"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+const defaultLimit = 50
+
+// searchableKinds are the kinds of the resources indexed.
+var searchableKinds = map[v1.Kind]bool{
+	v1.KindDashboard:      true,
+	v1.KindGlobalVariable: true,
+	v1.KindVariable:       true,
+}
+
+type service struct {
+	search.Service
+	index searchIndex.Index
+}
+
+func NewService(index searchIndex.Index) search.Service {
+	return &service{
+		index: index,
+	}
+}
+
+func (s *service) Search(query *search.Query) ([]*v1.SearchResult, error) {
+	if len(query.Text) == 0 {
+		return nil, shared.HandleBadRequestError("the parameter q cannot be empty")
+	}
+	if len(query.Kind) > 0 && !searchableKinds[query.Kind] {
+		return nil, shared.HandleBadRequestError(fmt.Sprintf("the kind %q cannot be searched, the possible values are %s, %s and %s", query.Kind, v1.KindDashboard, v1.KindVariable, v1.KindGlobalVariable))
+	}
+	if query.Limit < 0 {
+		return nil, shared.HandleBadRequestError("limit cannot be negative")
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	return s.index.Search(query.Text, searchIndex.Filter{
+		Project: query.Project,
+		Kind:    query.Kind,
+		Limit:   limit,
+		CanRead: query.CanRead,
+	}), nil
+}
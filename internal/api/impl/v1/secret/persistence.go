@@ -14,8 +14,11 @@
 package secret
 
 import (
+	"context"
+
 	"github.com/perses/perses/internal/api/interface/v1/secret"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelAPI "github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
@@ -32,12 +35,16 @@ func NewDAO(persesDAO databaseModel.DAO) secret.DAO {
 	}
 }
 
+func (d *dao) WithAuthor(author string) secret.DAO {
+	return NewDAO(databaseModel.WithAuthor(d.client, author))
+}
+
 func (d *dao) Create(entity *v1.Secret) error {
 	return d.client.Create(entity)
 }
 
-func (d *dao) Update(entity *v1.Secret) error {
-	return d.client.Upsert(entity)
+func (d *dao) Update(entity *v1.Secret, expectedVersion uint64) error {
+	return d.client.Update(entity, expectedVersion)
 }
 
 func (d *dao) Delete(project string, name string) error {
@@ -61,3 +68,14 @@ func (d *dao) List(q databaseModel.Query) ([]*v1.Secret, error) {
 	err := d.client.Query(q, &result)
 	return result, err
 }
+
+func (d *dao) Watch(ctx context.Context, q *secret.Query) (<-chan *v1.WatchEvent, error) {
+	filter := databaseModel.WatchFilter{
+		Project:        q.Project,
+		NamePrefix:     q.NamePrefix,
+		LabelSelection: q.LabelSelection,
+	}
+	return databaseModel.Watch(ctx, d.client, d.kind, filter, func() modelAPI.Entity {
+		return &v1.Secret{}
+	})
+}
@@ -14,6 +14,7 @@
 package secret
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/perses/perses/internal/api/interface/v1/secret"
@@ -38,7 +39,21 @@ func NewService(dao secret.DAO, crypto crypto.Crypto) secret.Service {
 	}
 }
 
-func (s *service) Create(entity api.Entity) (interface{}, error) {
+// InTransaction returns a copy of the service writing with tx. There is nothing to do once the transaction is committed.
+func (s *service) InTransaction(tx databaseModel.DAO) (shared.ToolboxService, func()) {
+	txService := *s
+	txService.dao = NewDAO(tx)
+	return &txService, func() {}
+}
+
+// WithAuthor returns a copy of the service whose changes are attributed to author.
+func (s *service) WithAuthor(author string) shared.ToolboxService {
+	authorService := *s
+	authorService.dao = s.dao.WithAuthor(author)
+	return &authorService
+}
+
+func (s *service) Create(entity api.Entity, _ shared.Parameters) (interface{}, error) {
 	if object, ok := entity.(*v1.Secret); ok {
 		return s.create(object)
 	}
@@ -81,14 +96,13 @@ func (s *service) update(entity *v1.Secret, parameters shared.Parameters) (*v1.P
 	if err != nil {
 		return nil, err
 	}
-	entity.Metadata.Update(oldEntity.Metadata)
-
 	if encryptErr := s.crypto.Encrypt(&entity.Spec); encryptErr != nil {
 		logrus.WithError(encryptErr).Errorf("unable to encrypt the secret spec")
 		return nil, shared.InternalError
 	}
-	if updateErr := s.dao.Update(entity); updateErr != nil {
-		logrus.WithError(updateErr).Errorf("unable to perform the update of the Secret %q, something wrong with the database", entity.Metadata.Name)
+	if updateErr := shared.UpdateEntity(parameters, entity, oldEntity, func(previousVersion uint64) error {
+		return s.dao.Update(entity, previousVersion)
+	}); updateErr != nil {
 		return nil, updateErr
 	}
 	return v1.NewPublicSecret(entity), nil
@@ -106,6 +120,19 @@ func (s *service) Get(parameters shared.Parameters) (interface{}, error) {
 	return v1.NewPublicSecret(scrt), nil
 }
 
+// GetPatchTarget returns the Secret with its spec decrypted, so a patch can modify the secret values that Get hides.
+func (s *service) GetPatchTarget(parameters shared.Parameters) (api.Entity, error) {
+	scrt, err := s.dao.Get(parameters.Project, parameters.Name)
+	if err != nil {
+		return nil, err
+	}
+	if decryptErr := s.crypto.Decrypt(&scrt.Spec); decryptErr != nil {
+		logrus.WithError(decryptErr).Errorf("unable to decrypt the secret spec")
+		return nil, shared.InternalError
+	}
+	return scrt, nil
+}
+
 func (s *service) List(q databaseModel.Query, _ shared.Parameters) (interface{}, error) {
 	l, err := s.dao.List(q)
 	if err != nil {
@@ -117,3 +144,17 @@ func (s *service) List(q databaseModel.Query, _ shared.Parameters) (interface{},
 	}
 	return result, nil
 }
+
+func (s *service) Watch(ctx context.Context, q databaseModel.Query, _ shared.Parameters) (<-chan *v1.WatchEvent, error) {
+	query, ok := q.(*secret.Query)
+	if !ok {
+		return nil, fmt.Errorf("wrong query format, attempting Secret query, received '%T'", q)
+	}
+	events, err := s.dao.Watch(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return shared.MapWatchEvents(ctx, events, func(object interface{}) interface{} {
+		return v1.NewPublicSecret(object.(*v1.Secret))
+	}), nil
+}
@@ -0,0 +1,238 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceaccount
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/perses/perses/internal/api/interface/v1/serviceaccount"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/crypto"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// maxTokenUpdateAttempts is how many times the tokens are modified again when the service account has been updated in the meantime,
+// like when a token has just been used.
+const maxTokenUpdateAttempts = 3
+
+type service struct {
+	serviceaccount.Service
+	dao         serviceaccount.DAO
+	maxTokenTTL time.Duration
+}
+
+func NewService(dao serviceaccount.DAO, maxTokenTTL time.Duration) serviceaccount.Service {
+	return &service{
+		dao:         dao,
+		maxTokenTTL: maxTokenTTL,
+	}
+}
+
+// WithAuthor returns a copy of the service whose changes are attributed to author.
+func (s *service) WithAuthor(author string) shared.ToolboxService {
+	authorService := *s
+	authorService.dao = s.dao.WithAuthor(author)
+	return &authorService
+}
+
+func (s *service) Create(entity api.Entity, _ shared.Parameters) (interface{}, error) {
+	if object, ok := entity.(*v1.ServiceAccount); ok {
+		return s.create(object)
+	}
+	return nil, shared.HandleBadRequestError(fmt.Sprintf("wrong entity format, attempting ServiceAccount format, received '%T'", entity))
+}
+
+func (s *service) create(entity *v1.ServiceAccount) (*v1.ServiceAccount, error) {
+	// the tokens are only created with CreateToken, so their secret is generated by the server.
+	entity.Spec.Tokens = nil
+	// Update the time contains in the entity
+	entity.Metadata.CreateNow()
+	if err := s.dao.Create(entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (s *service) Update(entity api.Entity, parameters shared.Parameters) (interface{}, error) {
+	if object, ok := entity.(*v1.ServiceAccount); ok {
+		return s.update(object, parameters)
+	}
+	return nil, shared.HandleBadRequestError(fmt.Sprintf("wrong entity format, attempting ServiceAccount format, received '%T'", entity))
+}
+
+func (s *service) update(entity *v1.ServiceAccount, parameters shared.Parameters) (*v1.ServiceAccount, error) {
+	if entity.Metadata.Name != parameters.Name {
+		logrus.Debugf("name in ServiceAccount %q and name from the http request: %q don't match", entity.Metadata.Name, parameters.Name)
+		return nil, shared.HandleBadRequestError("metadata.name and the name in the http path request don't match")
+	}
+	// find the previous version of the ServiceAccount
+	oldEntity, err := s.dao.Get(parameters.Name)
+	if err != nil {
+		return nil, err
+	}
+	entity.Spec.Tokens = oldEntity.Spec.Tokens
+	if updateErr := shared.UpdateEntity(parameters, entity, oldEntity, func(previousVersion uint64) error {
+		return s.dao.Update(entity, previousVersion)
+	}); updateErr != nil {
+		return nil, updateErr
+	}
+	return withoutHashes(entity), nil
+}
+
+func (s *service) Delete(parameters shared.Parameters) error {
+	return s.dao.Delete(parameters.Name)
+}
+
+func (s *service) Get(parameters shared.Parameters) (interface{}, error) {
+	entity, err := s.dao.Get(parameters.Name)
+	if err != nil {
+		return nil, err
+	}
+	return withoutHashes(entity), nil
+}
+
+func (s *service) List(q databaseModel.Query, _ shared.Parameters) (interface{}, error) {
+	l, err := s.dao.List(q)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*v1.ServiceAccount, 0, len(l))
+	for _, entity := range l {
+		result = append(result, withoutHashes(entity))
+	}
+	return result, nil
+}
+
+func (s *service) Watch(ctx context.Context, q databaseModel.Query, _ shared.Parameters) (<-chan *v1.WatchEvent, error) {
+	query, ok := q.(*serviceaccount.Query)
+	if !ok {
+		return nil, fmt.Errorf("wrong query format, attempting ServiceAccount query, received '%T'", q)
+	}
+	events, err := s.dao.Watch(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return shared.MapWatchEvents(ctx, events, func(object interface{}) interface{} {
+		return withoutHashes(object.(*v1.ServiceAccount))
+	}), nil
+}
+
+func (s *service) CreateToken(parameters shared.Parameters, request *v1.APITokenRequest) (*v1.APITokenResponse, *serviceaccount.Change, error) {
+	ttl := time.Duration(request.TTL)
+	if ttl == 0 {
+		ttl = s.maxTokenTTL
+	} else if ttl > s.maxTokenTTL {
+		return nil, nil, shared.HandleBadRequestError(fmt.Sprintf("the ttl of a token cannot be longer than %s", s.maxTokenTTL))
+	}
+	token, hash, err := crypto.NewAPIToken(parameters.Name, request.Name)
+	if err != nil {
+		logrus.WithError(err).Error("unable to generate an API token")
+		return nil, nil, shared.InternalError
+	}
+	now := time.Now().UTC()
+	apiToken := v1.APIToken{
+		Name:        request.Name,
+		Permissions: request.Permissions,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+		Hash:        hash,
+	}
+	change, err := s.modifyTokens(parameters.Name, func(entity *v1.ServiceAccount) error {
+		if entity.Spec.GetToken(request.Name) != nil {
+			return shared.HandleBadRequestError(fmt.Sprintf("the service account %q already has a token named %q", parameters.Name, request.Name))
+		}
+		entity.Spec.Tokens = append(entity.Spec.Tokens, apiToken)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	apiToken.Hash = ""
+	return &v1.APITokenResponse{APIToken: apiToken, Token: token}, change, nil
+}
+
+func (s *service) ListTokens(parameters shared.Parameters) ([]v1.APIToken, error) {
+	entity, err := s.dao.Get(parameters.Name)
+	if err != nil {
+		return nil, err
+	}
+	tokens := withoutHashes(entity).Spec.Tokens
+	if tokens == nil {
+		tokens = []v1.APIToken{}
+	}
+	return tokens, nil
+}
+
+func (s *service) RevokeToken(parameters shared.Parameters, tokenName string) (*serviceaccount.Change, error) {
+	return s.modifyTokens(parameters.Name, func(entity *v1.ServiceAccount) error {
+		tokens := make([]v1.APIToken, 0, len(entity.Spec.Tokens))
+		for _, token := range entity.Spec.Tokens {
+			if token.Name != tokenName {
+				tokens = append(tokens, token)
+			}
+		}
+		if len(tokens) == len(entity.Spec.Tokens) {
+			return shared.NotFoundError
+		}
+		entity.Spec.Tokens = tokens
+		return nil
+	})
+}
+
+// modifyTokens applies the modification to the last version of the service account, and saves it.
+// The modification is applied again if the service account is updated in the meantime.
+func (s *service) modifyTokens(name string, modify func(entity *v1.ServiceAccount) error) (*serviceaccount.Change, error) {
+	for attempt := 1; ; attempt++ {
+		entity, err := s.dao.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		oldEntity := withoutHashes(entity)
+		if modifyErr := modify(entity); modifyErr != nil {
+			return nil, modifyErr
+		}
+		version := entity.Metadata.Version
+		entity.Metadata.Update(entity.Metadata)
+		updateErr := s.dao.Update(entity, version)
+		if updateErr == nil {
+			return &serviceaccount.Change{Old: oldEntity, New: withoutHashes(entity)}, nil
+		}
+		if !databaseModel.IsKeyConflict(updateErr) {
+			logrus.WithError(updateErr).Errorf("unable to save the tokens of the ServiceAccount %q, something wrong with the database", name)
+			return nil, updateErr
+		}
+		if attempt == maxTokenUpdateAttempts {
+			return nil, shared.HandleVersionConflictError(fmt.Sprintf("the ServiceAccount %q is modified too often", name))
+		}
+	}
+}
+
+// withoutHashes returns a copy of the service account without the hashes of its tokens, as they are never returned by the API.
+func withoutHashes(entity *v1.ServiceAccount) *v1.ServiceAccount {
+	result := *entity
+	result.Spec.Tokens = make([]v1.APIToken, 0, len(entity.Spec.Tokens))
+	for _, token := range entity.Spec.Tokens {
+		token.Hash = ""
+		result.Spec.Tokens = append(result.Spec.Tokens, token)
+	}
+	if len(result.Spec.Tokens) == 0 {
+		result.Spec.Tokens = nil
+	}
+	return &result
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceaccount
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/interface/v1/serviceaccount"
+	"github.com/perses/perses/internal/api/shared"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// TokenEndpoint manages the API tokens of the service accounts.
+type TokenEndpoint struct {
+	service  serviceaccount.Service
+	readonly bool
+}
+
+func NewTokenEndpoint(service serviceaccount.Service, readonly bool) *TokenEndpoint {
+	return &TokenEndpoint{
+		service:  service,
+		readonly: readonly,
+	}
+}
+
+func (e *TokenEndpoint) RegisterRoutes(g *echo.Group) {
+	group := g.Group(fmt.Sprintf("/%s/:%s/%s", shared.PathServiceAccount, shared.ParamName, shared.PathToken))
+	if !e.readonly {
+		group.POST("", e.Create)
+		group.DELETE(fmt.Sprintf("/:%s", shared.ParamToken), e.Revoke)
+	}
+	group.GET("", e.List)
+}
+
+// Create returns the token created. It is the only time the token is returned.
+func (e *TokenEndpoint) Create(ctx echo.Context) error {
+	body := &v1.APITokenRequest{}
+	if err := ctx.Bind(body); err != nil {
+		return shared.HandleBadRequestError(err.Error())
+	}
+	result, change, err := e.service.CreateToken(shared.ExtractParameters(ctx), body)
+	if err != nil {
+		return err
+	}
+	shared.AuditChange(ctx, v1.ActionUpdate, change.Old, change.New)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (e *TokenEndpoint) List(ctx echo.Context) error {
+	result, err := e.service.ListTokens(shared.ExtractParameters(ctx))
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (e *TokenEndpoint) Revoke(ctx echo.Context) error {
+	change, err := e.service.RevokeToken(shared.ExtractParameters(ctx), ctx.Param(shared.ParamToken))
+	if err != nil {
+		return err
+	}
+	shared.AuditChange(ctx, v1.ActionUpdate, change.Old, change.New)
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trash
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/interface/v1/trash"
+	"github.com/perses/perses/internal/api/shared"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// Endpoint is the struct that define the endpoint delivered by the path /trash
+type Endpoint struct {
+	service  trash.Service
+	readonly bool
+}
+
+// NewEndpoint create an instance of the object Endpoint.
+func NewEndpoint(service trash.Service, readonly bool) *Endpoint {
+	return &Endpoint{
+		service:  service,
+		readonly: readonly,
+	}
+}
+
+func (e *Endpoint) RegisterRoutes(g *echo.Group) {
+	group := g.Group(fmt.Sprintf("/%s", shared.PathTrash))
+	if !e.readonly {
+		group.POST(fmt.Sprintf("/:%s/restore", shared.ParamName), e.Restore)
+		group.DELETE(fmt.Sprintf("/:%s", shared.ParamName), e.Purge)
+		group.DELETE("", e.PurgeAll)
+	}
+	group.GET("", e.List)
+	group.GET(fmt.Sprintf("/:%s", shared.ParamName), e.Get)
+}
+
+// List returns the entries of the trash, the most recent first. They can be filtered by project and by kind.
+func (e *Endpoint) List(ctx echo.Context) error {
+	query := &trash.Query{}
+	if err := ctx.Bind(query); err != nil {
+		return shared.HandleBadRequestError(err.Error())
+	}
+	result, err := e.service.List(query)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (e *Endpoint) Get(ctx echo.Context) error {
+	result, err := e.service.Get(shared.GetNameParameter(ctx))
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// Restore puts back the resources of the entry, and returns the dashboard or the project restored.
+func (e *Endpoint) Restore(ctx echo.Context) error {
+	result, err := e.service.WithAuthor(shared.GetAuthor(ctx)).Restore(shared.GetNameParameter(ctx))
+	if err != nil {
+		return err
+	}
+	shared.AuditChange(ctx, v1.ActionCreate, nil, result)
+	shared.NotifyChange(ctx, v1.ActionCreate, nil, result)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (e *Endpoint) Purge(ctx echo.Context) error {
+	name := shared.GetNameParameter(ctx)
+	oldEntry := shared.GetChanged(ctx, func() (interface{}, error) {
+		return e.service.Get(name)
+	})
+	if err := e.service.WithAuthor(shared.GetAuthor(ctx)).Purge(name); err != nil {
+		return err
+	}
+	shared.AuditChange(ctx, v1.ActionDelete, oldEntry, nil)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// PurgeAll removes the entries matching the query parameters project and kind, or every entry when they are not set.
+// It returns the entries removed.
+func (e *Endpoint) PurgeAll(ctx echo.Context) error {
+	query := &trash.Query{}
+	if err := ctx.Bind(query); err != nil {
+		return shared.HandleBadRequestError(err.Error())
+	}
+	result, err := e.service.WithAuthor(shared.GetAuthor(ctx)).PurgeAll(query)
+	if err != nil {
+		return err
+	}
+	for _, entry := range result {
+		shared.AuditChange(ctx, v1.ActionDelete, entry, nil)
+	}
+	// nothing is recorded when the trash is already empty.
+	shared.MarkRecorded(ctx)
+	return ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,257 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trash
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/perses/common/async"
+	"github.com/perses/perses/internal/api/interface/v1/trash"
+	"github.com/perses/perses/internal/api/shared"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/internal/api/shared/rbac"
+	searchIndex "github.com/perses/perses/internal/api/shared/search"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// ExpirationInterval is the interval between two removals of the expired entries.
+const ExpirationInterval = time.Hour
+
+type service struct {
+	trash.Service
+	dao trash.DAO
+	// persesDAO is used to restore the content of an entry and to remove the entry in a single transaction.
+	persesDAO databaseModel.DAO
+	index     searchIndex.Index
+	rbac      rbac.RBAC
+}
+
+func NewService(dao trash.DAO, persesDAO databaseModel.DAO, index searchIndex.Index, rbac rbac.RBAC) trash.Service {
+	return &service{
+		dao:       dao,
+		persesDAO: persesDAO,
+		index:     index,
+		rbac:      rbac,
+	}
+}
+
+func (s *service) WithAuthor(author string) trash.Service {
+	authorService := *s
+	authorService.dao = s.dao.WithAuthor(author)
+	authorService.persesDAO = databaseModel.WithAuthor(s.persesDAO, author)
+	return &authorService
+}
+
+func (s *service) List(q *trash.Query) ([]*v1.TrashEntry, error) {
+	entries, err := s.list(q)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		entry.Spec.Content = nil
+	}
+	return entries, nil
+}
+
+func (s *service) list(q *trash.Query) ([]*v1.TrashEntry, error) {
+	if len(q.Kind) > 0 && q.Kind != v1.KindDashboard && q.Kind != v1.KindProject {
+		return nil, shared.HandleBadRequestError(fmt.Sprintf("the trash only contains the kinds %s and %s, not %q", v1.KindDashboard, v1.KindProject, q.Kind))
+	}
+	entries, err := s.dao.List(q)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*v1.TrashEntry, 0, len(entries))
+	for _, entry := range entries {
+		if q.Accept(entry) {
+			result = append(result, entry)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Spec.DeletedAt.Equal(result[j].Spec.DeletedAt) {
+			return result[i].Spec.DeletedAt.After(result[j].Spec.DeletedAt)
+		}
+		return result[i].Metadata.Name < result[j].Metadata.Name
+	})
+	return result, nil
+}
+
+func (s *service) Get(name string) (*v1.TrashEntry, error) {
+	entry, err := s.dao.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	entry.Spec.Content = nil
+	return entry, nil
+}
+
+func (s *service) Restore(name string) (api.Entity, error) {
+	entry, err := s.dao.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	content := entry.Spec.Content
+	if content == nil {
+		logrus.Errorf("the content of the trash entry %q is missing", name)
+		return nil, shared.InternalError
+	}
+	resource := entry.Spec.Resource
+	entities := contentEntities(content)
+	err = s.persesDAO.Transaction(func(tx databaseModel.DAO) error {
+		if content.Project != nil {
+			if createErr := tx.Create(content.Project); createErr != nil {
+				return createErr
+			}
+		} else if getErr := tx.Get(v1.KindProject, v1.NewMetadata(resource.Project), &v1.Project{}); getErr != nil {
+			if databaseModel.IsKeyNotFound(getErr) {
+				return shared.HandleBadRequestError(fmt.Sprintf("the project %q doesn't exist, it must be created or restored first", resource.Project))
+			}
+			return getErr
+		}
+		for _, entity := range entities {
+			if createErr := tx.Create(entity); createErr != nil {
+				return createErr
+			}
+		}
+		for _, revision := range content.DashboardRevisions {
+			if upsertErr := tx.Upsert(revision); upsertErr != nil {
+				return upsertErr
+			}
+		}
+		return NewDAO(tx).Delete(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range entities {
+		s.index.Add(entity)
+	}
+	if len(content.Roles) > 0 || len(content.RoleBindings) > 0 {
+		if refreshErr := s.rbac.Refresh(); refreshErr != nil {
+			logrus.WithError(refreshErr).Error("unable to reload the permissions")
+		}
+	}
+	if content.Project != nil {
+		return content.Project, nil
+	}
+	for _, dashboard := range content.Dashboards {
+		if dashboard.Metadata.Name == resource.Name {
+			return dashboard, nil
+		}
+	}
+	return nil, fmt.Errorf("the dashboard %q is missing in the trash entry %q", resource.Name, name)
+}
+
+func (s *service) Purge(name string) error {
+	return s.dao.Delete(name)
+}
+
+func (s *service) PurgeAll(q *trash.Query) ([]*v1.TrashEntry, error) {
+	entries, err := s.list(q)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if deleteErr := s.dao.Delete(entry.Metadata.Name); deleteErr != nil && !databaseModel.IsKeyNotFound(deleteErr) {
+			return nil, deleteErr
+		}
+		entry.Spec.Content = nil
+	}
+	return entries, nil
+}
+
+func (s *service) Expire() error {
+	entries, err := s.dao.List(&trash.Query{})
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	count := 0
+	for _, entry := range entries {
+		if !entry.IsExpired(now) {
+			continue
+		}
+		// the entry may have been restored or purged in the meantime, or removed by another instance.
+		if deleteErr := s.dao.Delete(entry.Metadata.Name); deleteErr != nil && !databaseModel.IsKeyNotFound(deleteErr) {
+			return deleteErr
+		}
+		count++
+	}
+	if count > 0 {
+		logrus.Infof("%d expired entries removed from the trash", count)
+	}
+	return nil
+}
+
+// contentEntities returns the resources of the content, except the project and the revisions of the dashboards.
+// They are sorted, so the resources are restored before the ones referencing them.
+func contentEntities(content *v1.TrashContent) []api.Entity {
+	var result []api.Entity
+	for _, entity := range content.Secrets {
+		result = append(result, entity)
+	}
+	for _, entity := range content.Datasources {
+		result = append(result, entity)
+	}
+	for _, entity := range content.Webhooks {
+		result = append(result, entity)
+	}
+	for _, entity := range content.Variables {
+		result = append(result, entity)
+	}
+	for _, entity := range content.Dashboards {
+		result = append(result, entity)
+	}
+	for _, entity := range content.Folders {
+		result = append(result, entity)
+	}
+	for _, entity := range content.Roles {
+		result = append(result, entity)
+	}
+	for _, entity := range content.RoleBindings {
+		result = append(result, entity)
+	}
+	return result
+}
+
+// NewExpirer returns the task removing periodically the expired entries of the trash.
+func NewExpirer(service trash.Service) async.SimpleTask {
+	return &expirer{service: service}
+}
+
+type expirer struct {
+	async.SimpleTask
+	service trash.Service
+}
+
+func (e *expirer) String() string {
+	return "trash expirer"
+}
+
+func (e *expirer) Execute(ctx context.Context, _ context.CancelFunc) error {
+	select {
+	case <-ctx.Done():
+		logrus.Infof("canceled %s", e.String())
+	default:
+		if err := e.service.Expire(); err != nil {
+			logrus.WithError(err).Error("unable to remove the expired entries of the trash")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/perses/perses/internal/api/interface/v1/user"
+	"github.com/perses/perses/internal/api/shared"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type service struct {
+	user.Service
+	dao user.DAO
+}
+
+func NewService(dao user.DAO) user.Service {
+	return &service{
+		dao: dao,
+	}
+}
+
+// WithAuthor returns a copy of the service whose changes are attributed to author.
+func (s *service) WithAuthor(author string) shared.ToolboxService {
+	authorService := *s
+	authorService.dao = s.dao.WithAuthor(author)
+	return &authorService
+}
+
+func (s *service) Create(entity api.Entity, _ shared.Parameters) (interface{}, error) {
+	if object, ok := entity.(*v1.User); ok {
+		return s.create(object)
+	}
+	return nil, shared.HandleBadRequestError(fmt.Sprintf("wrong entity format, attempting User format, received '%T'", entity))
+}
+
+func (s *service) create(entity *v1.User) (*v1.PublicUser, error) {
+	if len(entity.Spec.NativeProvider.Password) == 0 {
+		return nil, shared.HandleBadRequestError("spec.nativeProvider.password cannot be empty")
+	}
+	// the providers are only linked by logging in with them.
+	entity.Spec.OAuthProviders = nil
+	// Update the time contains in the entity
+	entity.Metadata.CreateNow()
+	if err := hashPassword(entity); err != nil {
+		return nil, err
+	}
+	if err := s.dao.Create(entity); err != nil {
+		return nil, err
+	}
+	return v1.NewPublicUser(entity), nil
+}
+
+func (s *service) Update(entity api.Entity, parameters shared.Parameters) (interface{}, error) {
+	if object, ok := entity.(*v1.User); ok {
+		return s.update(object, parameters)
+	}
+	return nil, shared.HandleBadRequestError(fmt.Sprintf("wrong entity format, attempting User format, received '%T'", entity))
+}
+
+func (s *service) update(entity *v1.User, parameters shared.Parameters) (*v1.PublicUser, error) {
+	if entity.Metadata.Name != parameters.Name {
+		logrus.Debugf("name in User %q and name from the http request: %q don't match", entity.Metadata.Name, parameters.Name)
+		return nil, shared.HandleBadRequestError("metadata.name and the name in the http path request don't match")
+	}
+	// find the previous version of the User
+	oldEntity, err := s.dao.Get(parameters.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(entity.Spec.NativeProvider.Password) == 0 {
+		// the password is never returned by the API, so it is kept when it is not sent again.
+		entity.Spec.NativeProvider.Password = oldEntity.Spec.NativeProvider.Password
+	} else if hashErr := hashPassword(entity); hashErr != nil {
+		return nil, hashErr
+	}
+	entity.Spec.OAuthProviders = oldEntity.Spec.OAuthProviders
+	if updateErr := shared.UpdateEntity(parameters, entity, oldEntity, func(previousVersion uint64) error {
+		return s.dao.Update(entity, previousVersion)
+	}); updateErr != nil {
+		return nil, updateErr
+	}
+	return v1.NewPublicUser(entity), nil
+}
+
+func (s *service) Delete(parameters shared.Parameters) error {
+	return s.dao.Delete(parameters.Name)
+}
+
+func (s *service) Get(parameters shared.Parameters) (interface{}, error) {
+	entity, err := s.dao.Get(parameters.Name)
+	if err != nil {
+		return nil, err
+	}
+	return v1.NewPublicUser(entity), nil
+}
+
+func (s *service) List(q databaseModel.Query, _ shared.Parameters) (interface{}, error) {
+	l, err := s.dao.List(q)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*v1.PublicUser, 0, len(l))
+	for _, entity := range l {
+		result = append(result, v1.NewPublicUser(entity))
+	}
+	return result, nil
+}
+
+func (s *service) Watch(ctx context.Context, q databaseModel.Query, _ shared.Parameters) (<-chan *v1.WatchEvent, error) {
+	query, ok := q.(*user.Query)
+	if !ok {
+		return nil, fmt.Errorf("wrong query format, attempting User query, received '%T'", q)
+	}
+	events, err := s.dao.Watch(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return shared.MapWatchEvents(ctx, events, func(object interface{}) interface{} {
+		return v1.NewPublicUser(object.(*v1.User))
+	}), nil
+}
+
+// hashPassword replaces the password of the user by its bcrypt hash.
+func hashPassword(entity *v1.User) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(entity.Spec.NativeProvider.Password), bcrypt.DefaultCost)
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			return shared.HandleBadRequestError("spec.nativeProvider.password cannot be longer than 72 bytes")
+		}
+		logrus.WithError(err).Error("unable to hash the password of the user")
+		return shared.InternalError
+	}
+	entity.Spec.NativeProvider.Password = string(hash)
+	return nil
+}
@@ -14,6 +14,7 @@
 package variable
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/perses/perses/internal/api/shared/validate"
@@ -22,6 +23,7 @@ import (
 	"github.com/perses/perses/internal/api/shared"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
 	"github.com/perses/perses/internal/api/shared/schemas"
+	searchIndex "github.com/perses/perses/internal/api/shared/search"
 	"github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 	"github.com/sirupsen/logrus"
@@ -29,18 +31,36 @@ import (
 
 type service struct {
 	variable.Service
-	dao variable.DAO
-	sch schemas.Schemas
+	dao   variable.DAO
+	sch   schemas.Schemas
+	index searchIndex.Index
 }
 
-func NewService(dao variable.DAO, sch schemas.Schemas) variable.Service {
+func NewService(dao variable.DAO, sch schemas.Schemas, index searchIndex.Index) variable.Service {
 	return &service{
-		dao: dao,
-		sch: sch,
+		dao:   dao,
+		sch:   sch,
+		index: index,
 	}
 }
 
-func (s *service) Create(entity api.Entity) (interface{}, error) {
+// InTransaction returns a copy of the service writing with tx. The search index is updated once commit is called.
+func (s *service) InTransaction(tx databaseModel.DAO) (shared.ToolboxService, func()) {
+	txService := *s
+	txService.dao = NewDAO(tx)
+	index, commit := searchIndex.NewDeferred(s.index)
+	txService.index = index
+	return &txService, commit
+}
+
+// WithAuthor returns a copy of the service whose changes are attributed to author.
+func (s *service) WithAuthor(author string) shared.ToolboxService {
+	authorService := *s
+	authorService.dao = s.dao.WithAuthor(author)
+	return &authorService
+}
+
+func (s *service) Create(entity api.Entity, _ shared.Parameters) (interface{}, error) {
 	if object, ok := entity.(*v1.Variable); ok {
 		return s.create(object)
 	}
@@ -56,6 +76,7 @@ func (s *service) create(entity *v1.Variable) (*v1.Variable, error) {
 	if err := s.dao.Create(entity); err != nil {
 		return nil, err
 	}
+	s.index.Add(entity)
 	return entity, nil
 }
 
@@ -87,16 +108,21 @@ func (s *service) update(entity *v1.Variable, parameters shared.Parameters) (*v1
 	if err != nil {
 		return nil, err
 	}
-	entity.Metadata.Update(oldEntity.Metadata)
-	if updateErr := s.dao.Update(entity); updateErr != nil {
-		logrus.WithError(updateErr).Errorf("unable to perform the update of the Variable %q, something wrong with the database", entity.Metadata.Name)
+	if updateErr := shared.UpdateEntity(parameters, entity, oldEntity, func(previousVersion uint64) error {
+		return s.dao.Update(entity, previousVersion)
+	}); updateErr != nil {
 		return nil, updateErr
 	}
+	s.index.Add(entity)
 	return entity, nil
 }
 
 func (s *service) Delete(parameters shared.Parameters) error {
-	return s.dao.Delete(parameters.Project, parameters.Name)
+	if err := s.dao.Delete(parameters.Project, parameters.Name); err != nil {
+		return err
+	}
+	s.index.Remove(v1.KindVariable, parameters.Project, parameters.Name)
+	return nil
 }
 
 func (s *service) Get(parameters shared.Parameters) (interface{}, error) {
@@ -106,3 +132,11 @@ func (s *service) Get(parameters shared.Parameters) (interface{}, error) {
 func (s *service) List(q databaseModel.Query, _ shared.Parameters) (interface{}, error) {
 	return s.dao.List(q)
 }
+
+func (s *service) Watch(ctx context.Context, q databaseModel.Query, _ shared.Parameters) (<-chan *v1.WatchEvent, error) {
+	query, ok := q.(*variable.Query)
+	if !ok {
+		return nil, fmt.Errorf("wrong query format, attempting Variable query, received '%T'", q)
+	}
+	return s.dao.Watch(ctx, query)
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/perses/perses/internal/api/config"
+	webhookdeliveryImpl "github.com/perses/perses/internal/api/impl/v1/webhookdelivery"
+	"github.com/perses/perses/internal/api/interface/v1/webhook"
+	"github.com/perses/perses/internal/api/interface/v1/webhookdelivery"
+	"github.com/perses/perses/internal/api/shared"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+type service struct {
+	webhook.Service
+	dao webhook.DAO
+	// persesDAO is used to remove a webhook and its deliveries in a single transaction.
+	persesDAO databaseModel.DAO
+	// hosts are the hosts the events can be sent to, according to the configuration.
+	hosts *webhookdeliveryImpl.HostFilter
+}
+
+func NewService(dao webhook.DAO, persesDAO databaseModel.DAO, conf config.Webhook) webhook.Service {
+	return &service{
+		dao:       dao,
+		persesDAO: persesDAO,
+		hosts:     webhookdeliveryImpl.NewHostFilter(conf),
+	}
+}
+
+// WithAuthor returns a copy of the service whose changes are attributed to author.
+func (s *service) WithAuthor(author string) shared.ToolboxService {
+	authorService := *s
+	authorService.dao = s.dao.WithAuthor(author)
+	authorService.persesDAO = databaseModel.WithAuthor(s.persesDAO, author)
+	return &authorService
+}
+
+func (s *service) Create(entity api.Entity, _ shared.Parameters) (interface{}, error) {
+	if object, ok := entity.(*v1.Webhook); ok {
+		return s.create(object)
+	}
+	return nil, shared.HandleBadRequestError(fmt.Sprintf("wrong entity format, attempting Webhook format, received '%T'", entity))
+}
+
+func (s *service) create(entity *v1.Webhook) (*v1.Webhook, error) {
+	if err := s.hosts.CheckURL(entity.Spec.URL); err != nil {
+		return nil, shared.HandleBadRequestError(err.Error())
+	}
+	// Update the time contains in the entity
+	entity.Metadata.CreateNow()
+	if err := s.dao.Create(entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (s *service) Update(entity api.Entity, parameters shared.Parameters) (interface{}, error) {
+	if object, ok := entity.(*v1.Webhook); ok {
+		return s.update(object, parameters)
+	}
+	return nil, shared.HandleBadRequestError(fmt.Sprintf("wrong entity format, attempting Webhook format, received '%T'", entity))
+}
+
+func (s *service) update(entity *v1.Webhook, parameters shared.Parameters) (*v1.Webhook, error) {
+	if entity.Metadata.Name != parameters.Name {
+		logrus.Debugf("name in Webhook %q and name from the http request: %q don't match", entity.Metadata.Name, parameters.Name)
+		return nil, shared.HandleBadRequestError("metadata.name and the name in the http path request don't match")
+	}
+	if len(entity.Metadata.Project) == 0 {
+		entity.Metadata.Project = parameters.Project
+	} else if entity.Metadata.Project != parameters.Project {
+		logrus.Debugf("project in webhook %q and project from the http request %q don't match", entity.Metadata.Project, parameters.Project)
+		return nil, shared.HandleBadRequestError("metadata.project and the project name in the http path request don't match")
+	}
+	if err := s.hosts.CheckURL(entity.Spec.URL); err != nil {
+		return nil, shared.HandleBadRequestError(err.Error())
+	}
+	// find the previous version of the Webhook
+	oldEntity, err := s.dao.Get(parameters.Project, parameters.Name)
+	if err != nil {
+		return nil, err
+	}
+	if updateErr := shared.UpdateEntity(parameters, entity, oldEntity, func(previousVersion uint64) error {
+		return s.dao.Update(entity, previousVersion)
+	}); updateErr != nil {
+		return nil, updateErr
+	}
+	return entity, nil
+}
+
+func (s *service) Delete(parameters shared.Parameters) error {
+	return s.persesDAO.Transaction(func(tx databaseModel.DAO) error {
+		if err := NewDAO(tx).Delete(parameters.Project, parameters.Name); err != nil {
+			return err
+		}
+		return webhookdeliveryImpl.NewDAO(tx).DeleteAll(&webhookdelivery.Query{Kind: v1.KindWebhook, Project: parameters.Project, Name: parameters.Name})
+	})
+}
+
+func (s *service) Get(parameters shared.Parameters) (interface{}, error) {
+	return s.dao.Get(parameters.Project, parameters.Name)
+}
+
+func (s *service) List(q databaseModel.Query, _ shared.Parameters) (interface{}, error) {
+	return s.dao.List(q)
+}
+
+func (s *service) Watch(ctx context.Context, q databaseModel.Query, _ shared.Parameters) (<-chan *v1.WatchEvent, error) {
+	query, ok := q.(*webhook.Query)
+	if !ok {
+		return nil, fmt.Errorf("wrong query format, attempting Webhook query, received '%T'", q)
+	}
+	return s.dao.Watch(ctx, query)
+}
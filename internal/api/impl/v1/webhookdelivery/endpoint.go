@@ -0,0 +1,101 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhookdelivery
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/internal/api/interface/v1/webhookdelivery"
+	"github.com/perses/perses/internal/api/shared"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// Endpoint is the struct that define the endpoint delivered by the paths /projects/:project/webhooks/:name/deliveries
+// and /globalwebhooks/:name/deliveries
+type Endpoint struct {
+	service  webhookdelivery.Service
+	readonly bool
+}
+
+// NewEndpoint create an instance of the object Endpoint.
+func NewEndpoint(service webhookdelivery.Service, readonly bool) *Endpoint {
+	return &Endpoint{
+		service:  service,
+		readonly: readonly,
+	}
+}
+
+func (e *Endpoint) RegisterRoutes(g *echo.Group) {
+	e.registerRoutes(g.Group(fmt.Sprintf("/%s/:%s/%s/:%s/%s", shared.PathProject, shared.ParamProject, shared.PathWebhook, shared.ParamName, shared.PathDelivery)))
+	e.registerRoutes(g.Group(fmt.Sprintf("/%s/:%s/%s", shared.PathGlobalWebhook, shared.ParamName, shared.PathDelivery)))
+}
+
+func (e *Endpoint) registerRoutes(group *echo.Group) {
+	if !e.readonly {
+		group.POST(fmt.Sprintf("/:%s/replay", shared.ParamDelivery), e.Replay)
+	}
+	group.GET("", e.List)
+	group.GET(fmt.Sprintf("/:%s", shared.ParamDelivery), e.Get)
+}
+
+// List returns the deliveries of the webhook, the most recent first. They can be filtered with the query parameter status.
+func (e *Endpoint) List(ctx echo.Context) error {
+	webhook := getWebhookReference(ctx)
+	query := &webhookdelivery.Query{
+		Kind:    webhook.Kind,
+		Project: webhook.Project,
+		Name:    webhook.Name,
+		Status:  v1.WebhookDeliveryStatus(ctx.QueryParam("status")),
+	}
+	switch query.Status {
+	case "", v1.WebhookDeliveryStatusPending, v1.WebhookDeliveryStatusSucceeded, v1.WebhookDeliveryStatusFailed:
+	default:
+		return shared.HandleBadRequestError(fmt.Sprintf("unknown status %q, it should be %q, %q or %q", query.Status,
+			v1.WebhookDeliveryStatusPending, v1.WebhookDeliveryStatusSucceeded, v1.WebhookDeliveryStatusFailed))
+	}
+	result, err := e.service.List(query)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (e *Endpoint) Get(ctx echo.Context) error {
+	result, err := e.service.Get(getWebhookReference(ctx), ctx.Param(shared.ParamDelivery))
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// Replay sends again the event of a delivery that is over, and returns the new delivery.
+func (e *Endpoint) Replay(ctx echo.Context) error {
+	result, err := e.service.Replay(getWebhookReference(ctx), ctx.Param(shared.ParamDelivery))
+	if err != nil {
+		return err
+	}
+	shared.AuditChange(ctx, v1.ActionCreate, nil, result)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// getWebhookReference returns the webhook of the path: a Webhook when the path has a project, a GlobalWebhook otherwise.
+func getWebhookReference(ctx echo.Context) v1.WebhookReference {
+	project := shared.GetProjectParameter(ctx)
+	if len(project) == 0 {
+		return v1.WebhookReference{Kind: v1.KindGlobalWebhook, Name: shared.GetNameParameter(ctx)}
+	}
+	return v1.WebhookReference{Kind: v1.KindWebhook, Project: project, Name: shared.GetNameParameter(ctx)}
+}
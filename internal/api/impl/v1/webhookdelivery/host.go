@@ -0,0 +1,141 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhookdelivery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"github.com/perses/perses/internal/api/config"
+)
+
+// hostList is a list of hosts of the configuration: the names, the wildcard names without their "*", and the CIDRs.
+type hostList struct {
+	names    []string
+	suffixes []string
+	networks []*net.IPNet
+}
+
+func newHostList(hosts []string) hostList {
+	var result hostList
+	for _, host := range hosts {
+		// the hosts are verified with the configuration.
+		if _, network, err := net.ParseCIDR(host); err == nil {
+			result.networks = append(result.networks, network)
+		} else if strings.HasPrefix(host, "*.") {
+			result.suffixes = append(result.suffixes, strings.ToLower(strings.TrimPrefix(host, "*")))
+		} else {
+			result.names = append(result.names, strings.ToLower(host))
+		}
+	}
+	return result
+}
+
+func (l hostList) isEmpty() bool {
+	return len(l.names) == 0 && len(l.suffixes) == 0 && len(l.networks) == 0
+}
+
+func (l hostList) containsName(name string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	for _, n := range l.names {
+		if name == n {
+			return true
+		}
+	}
+	for _, suffix := range l.suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l hostList) containsIP(ip net.IP) bool {
+	for _, network := range l.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// HostFilter checks the hosts the events are sent to against the hosts allowed and denied by the configuration.
+// Every host is allowed when the configuration has none.
+type HostFilter struct {
+	allowed hostList
+	denied  hostList
+}
+
+func NewHostFilter(conf config.Webhook) *HostFilter {
+	return &HostFilter{
+		allowed: newHostList(conf.AllowedHosts),
+		denied:  newHostList(conf.DeniedHosts),
+	}
+}
+
+// CheckURL returns an error if the host of the URL is denied, or if it is not allowed. When the host is a name and the
+// configuration contains CIDRs, the IP it resolves to is only known when connecting, so it is checked again at this point.
+func (f *HostFilter) CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		return f.checkIP(ip, false)
+	}
+	if f.denied.containsName(host) {
+		return fmt.Errorf("the host %q is denied for the webhooks", host)
+	}
+	if f.allowed.isEmpty() || f.allowed.containsName(host) || len(f.allowed.networks) > 0 {
+		return nil
+	}
+	return fmt.Errorf("the host %q is not allowed for the webhooks", host)
+}
+
+// checkIP returns an error if the IP is denied, or if it is not allowed while the name it has been resolved from is not allowed either.
+func (f *HostFilter) checkIP(ip net.IP, isNameAllowed bool) error {
+	if f.denied.containsIP(ip) {
+		return fmt.Errorf("the IP %s is denied for the webhooks", ip)
+	}
+	if f.allowed.isEmpty() || isNameAllowed || f.allowed.containsIP(ip) {
+		return nil
+	}
+	return fmt.Errorf("the IP %s is not allowed for the webhooks", ip)
+}
+
+// dialContext returns the function opening the connections to the webhooks. The IP each host resolves to is checked
+// right before connecting, so a name allowed cannot be used to reach an IP denied.
+func (f *HostFilter) dialContext(dialer *net.Dialer) func(ctx context.Context, network string, address string) (net.Conn, error) {
+	return func(ctx context.Context, network string, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		isNameAllowed := net.ParseIP(host) == nil && f.allowed.containsName(host)
+		checkedDialer := *dialer
+		checkedDialer.Control = func(_ string, resolvedAddress string, _ syscall.RawConn) error {
+			resolvedHost, _, splitErr := net.SplitHostPort(resolvedAddress)
+			if splitErr != nil {
+				return splitErr
+			}
+			return f.checkIP(net.ParseIP(resolvedHost), isNameAllowed)
+		}
+		return checkedDialer.DialContext(ctx, network, address)
+	}
+}
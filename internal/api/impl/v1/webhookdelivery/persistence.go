@@ -0,0 +1,78 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhookdelivery
+
+import (
+	"github.com/perses/perses/internal/api/interface/v1/webhookdelivery"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type dao struct {
+	webhookdelivery.DAO
+	client databaseModel.DAO
+	kind   v1.Kind
+}
+
+func NewDAO(persesDAO databaseModel.DAO) webhookdelivery.DAO {
+	return &dao{
+		client: persesDAO,
+		kind:   v1.KindWebhookDelivery,
+	}
+}
+
+func (d *dao) Create(entity *v1.WebhookDelivery) error {
+	return d.client.Create(entity)
+}
+
+func (d *dao) Update(entity *v1.WebhookDelivery, expectedVersion uint64) error {
+	return d.client.Update(entity, expectedVersion)
+}
+
+func (d *dao) Delete(name string) error {
+	return d.client.Delete(d.kind, v1.NewMetadata(name))
+}
+
+// DeleteAll reads the deliveries first, as their webhook is only known from their document.
+func (d *dao) DeleteAll(q *webhookdelivery.Query) error {
+	deliveries, err := d.List(q)
+	if err != nil {
+		return err
+	}
+	for _, delivery := range deliveries {
+		if deleteErr := d.Delete(delivery.Metadata.Name); deleteErr != nil && !databaseModel.IsKeyNotFound(deleteErr) {
+			return deleteErr
+		}
+	}
+	return nil
+}
+
+func (d *dao) Get(name string) (*v1.WebhookDelivery, error) {
+	entity := &v1.WebhookDelivery{}
+	return entity, d.client.Get(d.kind, v1.NewMetadata(name), entity)
+}
+
+func (d *dao) List(q *webhookdelivery.Query) ([]*v1.WebhookDelivery, error) {
+	var deliveries []*v1.WebhookDelivery
+	if err := d.client.Query(q, &deliveries); err != nil {
+		return nil, err
+	}
+	result := make([]*v1.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		if q.Accept(delivery) {
+			result = append(result, delivery)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,427 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhookdelivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/perses/common/async"
+	"github.com/perses/perses/internal/api/config"
+	"github.com/perses/perses/internal/api/interface/v1/globalsecret"
+	"github.com/perses/perses/internal/api/interface/v1/globalwebhook"
+	"github.com/perses/perses/internal/api/interface/v1/secret"
+	"github.com/perses/perses/internal/api/interface/v1/webhook"
+	"github.com/perses/perses/internal/api/interface/v1/webhookdelivery"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/crypto"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// HeaderDelivery is the header of the requests containing the name of the delivery. It is the same for every attempt of a delivery,
+	// so a webhook can ignore an event it has already received.
+	HeaderDelivery = "X-Perses-Delivery"
+	// HeaderSignature is the header of the requests containing the signature of the body, when the webhook has a secret:
+	// sha256=<hex encoded HMAC-SHA256 of the body, keyed with the credentials of the secret>.
+	HeaderSignature = "X-Perses-Signature"
+	// maxConcurrentAttempts is how many deliveries Deliver sends at the same time.
+	maxConcurrentAttempts = 8
+	// maxErrorBodyLength is how much of the body of a response is kept in the attempt when the status code is not 2xx.
+	maxErrorBodyLength = 512
+	// leaseMargin is added to the timeout to get how long a delivery is claimed by an instance sending it.
+	leaseMargin = time.Minute
+	// maxRedirects is how many redirections a webhook can answer before the attempt is considered failed.
+	maxRedirects = 10
+)
+
+// Sign returns the value of the header HeaderSignature of a body signed with the key.
+func Sign(key []byte, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return fmt.Sprintf("sha256=%s", hex.EncodeToString(mac.Sum(nil)))
+}
+
+type service struct {
+	webhookdelivery.Service
+	dao              webhookdelivery.DAO
+	webhookDAO       webhook.DAO
+	globalWebhookDAO globalwebhook.DAO
+	secretDAO        secret.DAO
+	globalSecretDAO  globalsecret.DAO
+	crypto           crypto.Crypto
+	hosts            *HostFilter
+	client           *http.Client
+	timeout          time.Duration
+	retention        time.Duration
+}
+
+func NewService(dao webhookdelivery.DAO, webhookDAO webhook.DAO, globalWebhookDAO globalwebhook.DAO, secretDAO secret.DAO,
+	globalSecretDAO globalsecret.DAO, crypto crypto.Crypto, conf config.Webhook) webhookdelivery.Service {
+	hosts := NewHostFilter(conf)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = hosts.dialContext(&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second})
+	return &service{
+		dao:              dao,
+		webhookDAO:       webhookDAO,
+		globalWebhookDAO: globalWebhookDAO,
+		secretDAO:        secretDAO,
+		globalSecretDAO:  globalSecretDAO,
+		crypto:           crypto,
+		hosts:            hosts,
+		client: &http.Client{
+			Timeout:   time.Duration(conf.Timeout),
+			Transport: transport,
+			// a webhook could otherwise redirect the events to a host that is not allowed.
+			CheckRedirect: func(request *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return errors.New("stopped after too many redirects")
+				}
+				return hosts.CheckURL(request.URL.String())
+			},
+		},
+		timeout:   time.Duration(conf.Timeout),
+		retention: time.Duration(conf.Retention),
+	}
+}
+
+func (s *service) Notify(event *v1.WebhookEvent) {
+	webhooks, err := s.listWebhooks(event.Resource.Project)
+	if err != nil {
+		logrus.WithError(err).Errorf("unable to find the webhooks of the %s of the %s %q", event.Action, event.Resource.Kind, event.Resource.Name)
+		return
+	}
+	for reference, spec := range webhooks {
+		if !spec.Matches(event) {
+			continue
+		}
+		delivery := v1.NewWebhookDelivery(reference, *event)
+		if createErr := s.dao.Create(delivery); createErr != nil {
+			logrus.WithError(createErr).Errorf("unable to queue the %s of the %s %q for the %s %q", event.Action, event.Resource.Kind, event.Resource.Name, reference.Kind, reference.Name)
+			continue
+		}
+		go s.attempt(delivery)
+	}
+}
+
+// listWebhooks returns the webhooks of the project and the global ones. Only the global ones are returned when the project is empty.
+func (s *service) listWebhooks(project string) (map[v1.WebhookReference]*v1.WebhookSpec, error) {
+	result := make(map[v1.WebhookReference]*v1.WebhookSpec)
+	if len(project) > 0 {
+		webhooks, err := s.webhookDAO.List(&webhook.Query{Project: project})
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range webhooks {
+			result[v1.WebhookReference{Kind: v1.KindWebhook, Project: w.Metadata.Project, Name: w.Metadata.Name}] = &w.Spec
+		}
+	}
+	globalWebhooks, err := s.globalWebhookDAO.List(&globalwebhook.Query{})
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range globalWebhooks {
+		result[v1.WebhookReference{Kind: v1.KindGlobalWebhook, Name: w.Metadata.Name}] = &w.Spec
+	}
+	return result, nil
+}
+
+func (s *service) Deliver() error {
+	deliveries, err := s.dao.List(&webhookdelivery.Query{Status: v1.WebhookDeliveryStatusPending})
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	semaphore := make(chan struct{}, maxConcurrentAttempts)
+	wg := sync.WaitGroup{}
+	for _, delivery := range deliveries {
+		if !delivery.IsDue(now) {
+			continue
+		}
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(d *v1.WebhookDelivery) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			s.attempt(d)
+		}(delivery)
+	}
+	wg.Wait()
+	return s.removeExpired(now)
+}
+
+// removeExpired removes the deliveries that have succeeded or failed for longer than the retention.
+func (s *service) removeExpired(now time.Time) error {
+	count := 0
+	for _, status := range []v1.WebhookDeliveryStatus{v1.WebhookDeliveryStatusSucceeded, v1.WebhookDeliveryStatusFailed} {
+		deliveries, err := s.dao.List(&webhookdelivery.Query{Status: status, UpdatedBefore: now.Add(-s.retention)})
+		if err != nil {
+			return err
+		}
+		for _, delivery := range deliveries {
+			// the delivery may have been removed with its webhook in the meantime, or by another instance.
+			if deleteErr := s.dao.Delete(delivery.Metadata.Name); deleteErr != nil && !databaseModel.IsKeyNotFound(deleteErr) {
+				logrus.WithError(deleteErr).Errorf("unable to remove the expired delivery %q", delivery.Metadata.Name)
+				continue
+			}
+			count++
+		}
+	}
+	if count > 0 {
+		logrus.Infof("%d expired deliveries removed from the log of the webhooks", count)
+	}
+	return nil
+}
+
+// attempt sends the delivery once, and records the result. The delivery is claimed first by pushing back its next attempt,
+// so it is not sent at the same time by another instance sharing the database. If this instance stops while sending it,
+// the delivery is sent again once the claim is over.
+func (s *service) attempt(delivery *v1.WebhookDelivery) {
+	claimEnd := time.Now().UTC().Add(s.timeout + leaseMargin)
+	delivery.Spec.NextAttemptAt = &claimEnd
+	if err := s.save(delivery); err != nil {
+		if !databaseModel.IsKeyConflict(err) && !databaseModel.IsKeyNotFound(err) {
+			logrus.WithError(err).Errorf("unable to claim the delivery %q", delivery.Metadata.Name)
+		}
+		// otherwise, it has been claimed by another instance, or removed, in the meantime.
+		return
+	}
+	spec, err := s.getWebhook(delivery.Spec.Webhook)
+	if err != nil && !databaseModel.IsKeyNotFound(err) {
+		logrus.WithError(err).Errorf("unable to find the webhook of the delivery %q", delivery.Metadata.Name)
+		return
+	}
+	var attempt v1.WebhookAttempt
+	isSuccess := false
+	if spec == nil {
+		attempt = v1.WebhookAttempt{Time: time.Now().UTC(), Error: fmt.Sprintf("the %s doesn't exist anymore", delivery.Spec.Webhook.Kind)}
+	} else {
+		attempt, isSuccess = s.send(delivery, spec)
+	}
+	delivery.Spec.Attempts = append(delivery.Spec.Attempts, attempt)
+	switch {
+	case isSuccess:
+		delivery.Spec.Status = v1.WebhookDeliveryStatusSucceeded
+		delivery.Spec.NextAttemptAt = nil
+	case spec == nil || len(delivery.Spec.Attempts) >= spec.MaxAttempts:
+		delivery.Spec.Status = v1.WebhookDeliveryStatusFailed
+		delivery.Spec.NextAttemptAt = nil
+	default:
+		nextAttemptAt := attempt.Time.Add(spec.RetryDelay(len(delivery.Spec.Attempts)))
+		delivery.Spec.NextAttemptAt = &nextAttemptAt
+	}
+	if saveErr := s.save(delivery); saveErr != nil {
+		logrus.WithError(saveErr).Errorf("unable to record the attempt of the delivery %q", delivery.Metadata.Name)
+	}
+}
+
+// save replaces the delivery stored, only if it has not been modified since it has been read.
+func (s *service) save(delivery *v1.WebhookDelivery) error {
+	previous := delivery.Metadata
+	delivery.Metadata.Update(previous)
+	return s.dao.Update(delivery, previous.Version)
+}
+
+func (s *service) getWebhook(reference v1.WebhookReference) (*v1.WebhookSpec, error) {
+	if reference.Kind == v1.KindGlobalWebhook {
+		entity, err := s.globalWebhookDAO.Get(reference.Name)
+		if err != nil {
+			return nil, err
+		}
+		return &entity.Spec, nil
+	}
+	entity, err := s.webhookDAO.Get(reference.Project, reference.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.Spec, nil
+}
+
+// send posts the event of the delivery to the webhook. It returns true if the webhook has answered with a 2xx status code.
+func (s *service) send(delivery *v1.WebhookDelivery, spec *v1.WebhookSpec) (v1.WebhookAttempt, bool) {
+	attempt := v1.WebhookAttempt{Time: time.Now().UTC()}
+	body, err := json.Marshal(delivery.Spec.Event)
+	if err != nil {
+		attempt.Error = fmt.Sprintf("unable to encode the event: %s", err)
+		return attempt, false
+	}
+	// the hosts allowed may have changed since the webhook has been saved.
+	if err = s.hosts.CheckURL(spec.URL); err != nil {
+		attempt.Error = err.Error()
+		return attempt, false
+	}
+	request, err := http.NewRequest(http.MethodPost, spec.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, false
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "Perses")
+	request.Header.Set(HeaderDelivery, delivery.Metadata.Name)
+	if len(spec.Secret) > 0 {
+		key, keyErr := s.getSigningKey(delivery.Spec.Webhook, spec.Secret)
+		if keyErr != nil {
+			attempt.Error = keyErr.Error()
+			return attempt, false
+		}
+		request.Header.Set(HeaderSignature, Sign(key, body))
+	}
+	response, err := s.client.Do(request)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, false
+	}
+	defer response.Body.Close()
+	attempt.StatusCode = response.StatusCode
+	content, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBodyLength))
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return attempt, true
+	}
+	attempt.Error = strings.TrimSpace(fmt.Sprintf("unexpected status code %d: %s", response.StatusCode, content))
+	return attempt, false
+}
+
+// getSigningKey returns the credentials of the secret of the webhook. It is a secret of the same project for a Webhook,
+// and a global secret for a GlobalWebhook.
+func (s *service) getSigningKey(reference v1.WebhookReference, name string) ([]byte, error) {
+	var spec v1.SecretSpec
+	var err error
+	if reference.Kind == v1.KindGlobalWebhook {
+		var entity *v1.GlobalSecret
+		if entity, err = s.globalSecretDAO.Get(name); err == nil {
+			spec = entity.Spec
+		}
+	} else {
+		var entity *v1.Secret
+		if entity, err = s.secretDAO.Get(reference.Project, name); err == nil {
+			spec = entity.Spec
+		}
+	}
+	if err != nil {
+		if databaseModel.IsKeyNotFound(err) {
+			return nil, fmt.Errorf("the secret %q doesn't exist", name)
+		}
+		logrus.WithError(err).Errorf("unable to read the secret %q, something wrong with the database", name)
+		return nil, fmt.Errorf("unable to read the secret %q", name)
+	}
+	if decryptErr := s.crypto.Decrypt(&spec); decryptErr != nil {
+		logrus.WithError(decryptErr).Errorf("unable to decrypt the secret %q", name)
+		return nil, fmt.Errorf("unable to decrypt the secret %q", name)
+	}
+	if spec.Authorization == nil {
+		return nil, fmt.Errorf("the secret %q has no authorization credentials to sign the events", name)
+	}
+	credentials, err := spec.Authorization.GetCredentials()
+	if err != nil {
+		logrus.WithError(err).Errorf("unable to read the credentials of the secret %q", name)
+		return nil, fmt.Errorf("unable to read the credentials of the secret %q", name)
+	}
+	if len(credentials) == 0 {
+		return nil, fmt.Errorf("the credentials of the secret %q are empty", name)
+	}
+	return []byte(credentials), nil
+}
+
+func (s *service) List(q *webhookdelivery.Query) ([]*v1.WebhookDelivery, error) {
+	if _, err := s.getWebhook(v1.WebhookReference{Kind: q.Kind, Project: q.Project, Name: q.Name}); err != nil {
+		return nil, err
+	}
+	result, err := s.dao.List(q)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Metadata.CreatedAt.Equal(result[j].Metadata.CreatedAt) {
+			return result[i].Metadata.CreatedAt.After(result[j].Metadata.CreatedAt)
+		}
+		return result[i].Metadata.Name > result[j].Metadata.Name
+	})
+	return result, nil
+}
+
+func (s *service) Get(reference v1.WebhookReference, name string) (*v1.WebhookDelivery, error) {
+	if _, err := s.getWebhook(reference); err != nil {
+		return nil, err
+	}
+	delivery, err := s.dao.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Spec.Webhook != reference {
+		return nil, shared.NotFoundError
+	}
+	return delivery, nil
+}
+
+func (s *service) Replay(reference v1.WebhookReference, name string) (*v1.WebhookDelivery, error) {
+	delivery, err := s.Get(reference, name)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Spec.Status == v1.WebhookDeliveryStatusPending {
+		return nil, shared.HandleBadRequestError(fmt.Sprintf("the delivery %q is still pending, it cannot be replayed", name))
+	}
+	replay := v1.NewWebhookDelivery(reference, delivery.Spec.Event)
+	replay.Spec.ReplayOf = name
+	if createErr := s.dao.Create(replay); createErr != nil {
+		return nil, createErr
+	}
+	// the delivery sent is a copy, as the one returned is encoded in the response in the meantime.
+	sent := *replay
+	go s.attempt(&sent)
+	return replay, nil
+}
+
+// NewDeliverer returns the task sending periodically the deliveries waiting to be sent again.
+func NewDeliverer(service webhookdelivery.Service) async.SimpleTask {
+	return &deliverer{service: service}
+}
+
+type deliverer struct {
+	async.SimpleTask
+	service webhookdelivery.Service
+}
+
+func (d *deliverer) String() string {
+	return "webhook deliverer"
+}
+
+func (d *deliverer) Execute(ctx context.Context, _ context.CancelFunc) error {
+	select {
+	case <-ctx.Done():
+		logrus.Infof("canceled %s", d.String())
+	default:
+		if err := d.service.Deliver(); err != nil {
+			logrus.WithError(err).Error("unable to send the deliveries of the webhooks")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// Request contains the resources to apply.
+type Request struct {
+	Resources []api.Entity
+	// DryRun only validates the resources and tells what would be done, without writing anything.
+	DryRun bool
+	// Author is who applies the resources. It is empty when the request is not authenticated.
+	Author string
+	// IsAllowed tells whether the user can do the action on the resources of the kind in the project.
+	// It is nil when every action is allowed.
+	IsAllowed func(action v1.Action, kind v1.Kind, project string) bool
+	// OnChange is called after each resource created or updated, with the resource before and after the change.
+	// The former is nil for a creation. It can be nil.
+	OnChange func(action v1.Action, oldEntity interface{}, newEntity interface{})
+}
+
+type Service interface {
+	// Apply creates or updates the resources, the dependencies first: projects, secrets, datasources, variables, dashboards and folders.
+	// Every resource is validated before the first one is written.
+	Apply(request *Request) (*v1.ApplyResponse, error)
+}
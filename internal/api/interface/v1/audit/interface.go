@@ -0,0 +1,77 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"time"
+
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type Query struct {
+	databaseModel.Query
+	// Kind is the kind of the resources modified. It can be empty to get the records of every kind.
+	Kind v1.Kind `query:"kind"`
+	// Project is the exact name of the project of the resources modified.
+	Project string `query:"project"`
+	// Name is the exact name of the resource modified.
+	Name string `query:"name"`
+	// Actor is the exact name of the user or of the service account who sent the requests.
+	Actor string `query:"actor"`
+	// Since and Until restrict the records to the changes made in this period. They are ignored when they are not set.
+	Since time.Time `query:"since"`
+	Until time.Time `query:"until"`
+}
+
+// Accept returns true if the record matches every field of the query.
+func (q *Query) Accept(record *v1.AuditRecord) bool {
+	spec := record.Spec
+	if len(q.Kind) > 0 && spec.Resource.Kind != q.Kind {
+		return false
+	}
+	if len(q.Project) > 0 && spec.Resource.Project != q.Project {
+		return false
+	}
+	if len(q.Name) > 0 && spec.Resource.Name != q.Name {
+		return false
+	}
+	if len(q.Actor) > 0 && (spec.Actor == nil || spec.Actor.Name != q.Actor) {
+		return false
+	}
+	if !q.Since.IsZero() && spec.Time.Before(q.Since) {
+		return false
+	}
+	return q.Until.IsZero() || !spec.Time.After(q.Until)
+}
+
+type DAO interface {
+	Create(entity *v1.AuditRecord) error
+	// List returns every record. The fields of the query are ignored.
+	List(q *Query) ([]*v1.AuditRecord, error)
+}
+
+// Sink is where the records are written, configured with audit.sink.
+type Sink interface {
+	Write(record *v1.AuditRecord) error
+	// List returns the records matching the query, in any order.
+	List(q *Query) ([]*v1.AuditRecord, error)
+}
+
+type Service interface {
+	// Record writes the record in the sink. A record that cannot be written is logged, the change it describes is done anyway.
+	Record(record *v1.AuditRecord)
+	// List returns the records matching the query, the most recent first.
+	List(q *Query) ([]*v1.AuditRecord, error)
+}
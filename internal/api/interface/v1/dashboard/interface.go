@@ -14,6 +14,8 @@
 package dashboard
 
 import (
+	"context"
+
 	"github.com/perses/perses/internal/api/shared"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
@@ -21,6 +23,10 @@ import (
 
 type Query struct {
 	databaseModel.Query
+	// Pagination contains the parameters to sort the list and to get only a page of it.
+	databaseModel.Pagination
+	// LabelSelection contains the label selector used to filter the list.
+	databaseModel.LabelSelection
 	// NamePrefix is a prefix of the Dashboard.metadata.name that is used to filter the list of the Dashboard.
 	// NamePrefix can be empty in case you want to return the full list of Dashboard available.
 	NamePrefix string `query:"name"`
@@ -29,16 +35,42 @@ type Query struct {
 	Project string `param:"project" query:"project"`
 }
 
+// RevisionQuery is used to get the revisions of a dashboard.
+type RevisionQuery struct {
+	databaseModel.Query
+	// Project is the exact name of the project.
+	Project string
+	// Name is the exact name of the dashboard.
+	// It can be empty in case you want to target the revisions of all dashboards of the project.
+	Name string
+}
+
 type DAO interface {
 	Create(entity *v1.Dashboard) error
-	Update(entity *v1.Dashboard) error
+	Update(entity *v1.Dashboard, expectedVersion uint64) error
+	// Delete removes the dashboard and all its revisions.
 	Delete(project string, name string) error
+	// DeleteAll removes every dashboard of the project and all their revisions.
 	DeleteAll(project string) error
 	Get(project string, name string) (*v1.Dashboard, error)
 	List(q databaseModel.Query) ([]*v1.Dashboard, error)
+	// Watch returns the changes of the Dashboard matching the query, made from now on.
+	Watch(ctx context.Context, q *Query) (<-chan *v1.WatchEvent, error)
+	CreateRevision(entity *v1.DashboardRevision) error
+	DeleteRevision(project string, name string, version uint64) error
+	GetRevision(project string, name string, version uint64) (*v1.DashboardRevision, error)
+	// ListRevisions returns the revisions of the dashboard. The name can be empty to get the revisions of every dashboard of the project.
+	ListRevisions(project string, name string) ([]*v1.DashboardRevision, error)
+	// WithAuthor returns a copy of the DAO attributing the changes it makes to author, when the database records who made each change.
+	WithAuthor(author string) DAO
 }
 
 type Service interface {
-	shared.ToolboxService
+	shared.TransactionalService
 	Validate(entity *v1.Dashboard) error
+	// ListRevisions returns the revisions of the dashboard, from the most recent to the oldest one.
+	ListRevisions(parameters shared.Parameters) ([]*v1.DashboardRevision, error)
+	GetRevision(parameters shared.Parameters, version uint64) (*v1.DashboardRevision, error)
+	// RestoreRevision updates the dashboard with the content of the given revision. It creates a new version of the dashboard.
+	RestoreRevision(parameters shared.Parameters, version uint64) (*v1.Dashboard, error)
 }
@@ -14,6 +14,8 @@
 package folder
 
 import (
+	"context"
+
 	"github.com/perses/perses/internal/api/shared"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
@@ -21,6 +23,10 @@ import (
 
 type Query struct {
 	databaseModel.Query
+	// Pagination contains the parameters to sort the list and to get only a page of it.
+	databaseModel.Pagination
+	// LabelSelection contains the label selector used to filter the list.
+	databaseModel.LabelSelection
 	// NamePrefix is a prefix of the Folders.metadata.name that is used to filter the list of the Folders.
 	// NamePrefix can be empty in case you want to return the full list of Folders available.
 	NamePrefix string `query:"name"`
@@ -31,13 +37,17 @@ type Query struct {
 
 type DAO interface {
 	Create(entity *v1.Folder) error
-	Update(entity *v1.Folder) error
+	Update(entity *v1.Folder, expectedVersion uint64) error
 	Delete(project string, name string) error
 	DeleteAll(project string) error
 	Get(project string, name string) (*v1.Folder, error)
 	List(q databaseModel.Query) ([]*v1.Folder, error)
+	// Watch returns the changes of the Folder matching the query, made from now on.
+	Watch(ctx context.Context, q *Query) (<-chan *v1.WatchEvent, error)
+	// WithAuthor returns a copy of the DAO attributing the changes it makes to author, when the database records who made each change.
+	WithAuthor(author string) DAO
 }
 
 type Service interface {
-	shared.ToolboxService
+	shared.TransactionalService
 }
@@ -0,0 +1,49 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalwebhook
+
+import (
+	"context"
+
+	"github.com/perses/perses/internal/api/shared"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type Query struct {
+	databaseModel.Query
+	// Pagination contains the parameters to sort the list and to get only a page of it.
+	databaseModel.Pagination
+	// LabelSelection contains the label selector used to filter the list.
+	databaseModel.LabelSelection
+	// NamePrefix is a prefix of the GlobalWebhook.metadata.name that is used to filter the list of the GlobalWebhook.
+	// NamePrefix can be empty in case you want to return the full list of GlobalWebhook available.
+	NamePrefix string `query:"name"`
+}
+
+type DAO interface {
+	Create(entity *v1.GlobalWebhook) error
+	Update(entity *v1.GlobalWebhook, expectedVersion uint64) error
+	Delete(name string) error
+	Get(name string) (*v1.GlobalWebhook, error)
+	List(q databaseModel.Query) ([]*v1.GlobalWebhook, error)
+	// Watch returns the changes of the GlobalWebhook matching the query, made from now on.
+	Watch(ctx context.Context, q *Query) (<-chan *v1.WatchEvent, error)
+	// WithAuthor returns a copy of the DAO attributing the changes it makes to author, when the database records who made each change.
+	WithAuthor(author string) DAO
+}
+
+type Service interface {
+	shared.ToolboxService
+}
@@ -0,0 +1,37 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// Query contains the parameters of a search.
+type Query struct {
+	// Text contains the terms to search, separated by spaces. A resource is returned only if it contains every term.
+	Text string `query:"q"`
+	// Project is the exact name of the project. It can be empty to search in every project and in the global resources.
+	Project string `query:"project"`
+	// Kind can be used to return only the resources of this kind: Dashboard, Variable or GlobalVariable.
+	Kind v1.Kind `query:"kind"`
+	// Limit is the maximum number of results. The default value is used when it is 0.
+	Limit int `query:"limit"`
+	// CanRead excludes the resources the user cannot read. It is nil when every resource can be returned.
+	CanRead func(kind v1.Kind, project string) bool
+}
+
+type Service interface {
+	// Search returns the dashboards and the variables matching the query, the most relevant first.
+	Search(query *Query) ([]*v1.SearchResult, error)
+}
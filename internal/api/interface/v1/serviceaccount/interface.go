@@ -0,0 +1,62 @@
+// Copyright 2021 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceaccount
+
+import (
+	"context"
+
+	"github.com/perses/perses/internal/api/shared"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type Query struct {
+	databaseModel.Query
+	// Pagination contains the parameters to sort the list and to get only a page of it.
+	databaseModel.Pagination
+	// LabelSelection contains the label selector used to filter the list.
+	databaseModel.LabelSelection
+	// NamePrefix is a prefix of the ServiceAccount.metadata.name that is used to filter the list of the ServiceAccount.
+	// NamePrefix can be empty in case you want to return the full list of ServiceAccount available.
+	NamePrefix string `query:"name"`
+}
+
+type DAO interface {
+	Create(entity *v1.ServiceAccount) error
+	Update(entity *v1.ServiceAccount, expectedVersion uint64) error
+	Delete(name string) error
+	Get(name string) (*v1.ServiceAccount, error)
+	List(q databaseModel.Query) ([]*v1.ServiceAccount, error)
+	// Watch returns the changes of the ServiceAccount matching the query, made from now on.
+	Watch(ctx context.Context, q *Query) (<-chan *v1.WatchEvent, error)
+	// WithAuthor returns a copy of the DAO attributing the changes it makes to author, when the database records who made each change.
+	WithAuthor(author string) DAO
+}
+
+// Change is a modification of the tokens of a service account. The service account is given before and after the modification,
+// without the hashes of its tokens, so the change can be recorded.
+type Change struct {
+	Old *v1.ServiceAccount
+	New *v1.ServiceAccount
+}
+
+type Service interface {
+	shared.ToolboxService
+	// CreateToken creates a token for the service account. The response is the only time the token itself is returned.
+	CreateToken(parameters shared.Parameters, request *v1.APITokenRequest) (*v1.APITokenResponse, *Change, error)
+	// ListTokens returns the tokens of the service account, without their hash.
+	ListTokens(parameters shared.Parameters) ([]v1.APIToken, error)
+	// RevokeToken removes the token, so it can't be used anymore.
+	RevokeToken(parameters shared.Parameters, tokenName string) (*Change, error)
+}
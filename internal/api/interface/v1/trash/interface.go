@@ -0,0 +1,65 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trash
+
+import (
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type Query struct {
+	databaseModel.Query
+	// NamePrefix is a prefix of the TrashEntry.metadata.name that is used to filter the list of the entries.
+	NamePrefix string `query:"name"`
+	// Project is the exact name of the project of the resources deleted. It can be empty to get the entries of every project.
+	Project string `query:"project"`
+	// Kind can be used to get only the entries of the deleted resources of this kind: Dashboard or Project.
+	Kind v1.Kind `query:"kind"`
+}
+
+// Accept returns true if the entry matches the project and the kind of the query.
+func (q *Query) Accept(entry *v1.TrashEntry) bool {
+	if len(q.Project) > 0 && entry.Spec.Resource.Project != q.Project {
+		return false
+	}
+	return len(q.Kind) == 0 || entry.Spec.Resource.Kind == q.Kind
+}
+
+type DAO interface {
+	Create(entity *v1.TrashEntry) error
+	Delete(name string) error
+	Get(name string) (*v1.TrashEntry, error)
+	// List returns the entries whose name starts with the prefix of the query. The other fields of the query are ignored.
+	List(q *Query) ([]*v1.TrashEntry, error)
+	// WithAuthor returns a copy of the DAO attributing the changes it makes to author, when the database records who made each change.
+	WithAuthor(author string) DAO
+}
+
+type Service interface {
+	// List returns the entries matching the query, the most recent first. Their content is removed.
+	List(q *Query) ([]*v1.TrashEntry, error)
+	// Get returns the entry without its content.
+	Get(name string) (*v1.TrashEntry, error)
+	// Restore creates again the resources contained in the entry, and then removes the entry. It returns the resource restored.
+	Restore(name string) (api.Entity, error)
+	// Purge removes the entry for good.
+	Purge(name string) error
+	// PurgeAll removes for good every entry matching the query, and returns them without their content.
+	PurgeAll(q *Query) ([]*v1.TrashEntry, error)
+	// Expire removes the entries whose retention is over.
+	Expire() error
+	// WithAuthor returns a copy of the service whose changes are attributed to author.
+	WithAuthor(author string) Service
+}
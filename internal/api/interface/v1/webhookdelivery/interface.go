@@ -0,0 +1,78 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhookdelivery
+
+import (
+	"time"
+
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type Query struct {
+	databaseModel.Query
+	// Kind is the kind of the webhooks: Webhook or GlobalWebhook. It can be empty to get the deliveries of every webhook.
+	Kind v1.Kind
+	// Project is the exact name of the project of the webhooks.
+	Project string
+	// Name is the exact name of the webhook.
+	Name string
+	// Status can be used to get only the deliveries pending, succeeded or failed.
+	Status v1.WebhookDeliveryStatus
+	// UpdatedBefore can be used to get only the deliveries modified for the last time before this date.
+	UpdatedBefore time.Time
+}
+
+// Accept returns true if the delivery matches every field of the query.
+func (q *Query) Accept(delivery *v1.WebhookDelivery) bool {
+	webhook := delivery.Spec.Webhook
+	if len(q.Kind) > 0 && webhook.Kind != q.Kind {
+		return false
+	}
+	if len(q.Project) > 0 && webhook.Project != q.Project {
+		return false
+	}
+	if len(q.Name) > 0 && webhook.Name != q.Name {
+		return false
+	}
+	if !q.UpdatedBefore.IsZero() && !delivery.Metadata.UpdatedAt.Before(q.UpdatedBefore) {
+		return false
+	}
+	return len(q.Status) == 0 || delivery.Spec.Status == q.Status
+}
+
+type DAO interface {
+	Create(entity *v1.WebhookDelivery) error
+	Update(entity *v1.WebhookDelivery, expectedVersion uint64) error
+	Delete(name string) error
+	// DeleteAll removes the deliveries matching the query.
+	DeleteAll(q *Query) error
+	Get(name string) (*v1.WebhookDelivery, error)
+	// List returns the deliveries matching the query. With a SQL database, the status and the date of the query are
+	// filtered by the database, the other fields once the deliveries are read.
+	List(q *Query) ([]*v1.WebhookDelivery, error)
+}
+
+type Service interface {
+	// Notify creates a delivery of the event for each webhook whose filters it passes, and sends them right away.
+	// A delivery that cannot be created is logged, the change it describes is done anyway.
+	Notify(event *v1.WebhookEvent)
+	// Deliver sends the pending deliveries whose next attempt is due, and removes the ones over for longer than the retention.
+	Deliver() error
+	// List returns the deliveries of the webhook matching the query, the most recent first.
+	List(q *Query) ([]*v1.WebhookDelivery, error)
+	Get(webhook v1.WebhookReference, name string) (*v1.WebhookDelivery, error)
+	// Replay creates a new delivery of the event of a delivery that is over, and sends it right away.
+	Replay(webhook v1.WebhookReference, name string) (*v1.WebhookDelivery, error)
+}
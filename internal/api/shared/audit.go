@@ -0,0 +1,152 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"encoding/json"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// contextKeyAuditor is the key of the echo context where the Auditor of the request is stored.
+	contextKeyAuditor = "perses.auditor"
+	// contextKeyRecorded is the key of the echo context telling whether the changes made by the request have been recorded.
+	contextKeyRecorded = "perses.audit.recorded"
+)
+
+// Auditor records a change made by a request.
+type Auditor func(record *v1.AuditRecord)
+
+// SetAuditor stores in the context where the changes made by the request are recorded. Nothing is recorded when it is not set.
+func SetAuditor(ctx echo.Context, auditor Auditor) {
+	ctx.Set(contextKeyAuditor, auditor)
+}
+
+// IsAudited returns true if the changes made by the request are recorded.
+func IsAudited(ctx echo.Context) bool {
+	_, ok := ctx.Get(contextKeyAuditor).(Auditor)
+	return ok
+}
+
+// NewAuditRecord returns a record of a change of the resource, made now by the request.
+func NewAuditRecord(ctx echo.Context, resource v1.AuditResource) *v1.AuditRecord {
+	record := v1.NewAuditRecord(resource)
+	record.Spec.SourceIP = ctx.RealIP()
+	if subjects := GetSubjects(ctx); len(subjects) > 0 {
+		// the first subject is the user or the service account, the others are the groups of the user.
+		record.Spec.Actor = &subjects[0]
+	}
+	return record
+}
+
+// Audit records the change made by the request, if the changes are recorded.
+func Audit(ctx echo.Context, record *v1.AuditRecord) {
+	if auditor, ok := ctx.Get(contextKeyAuditor).(Auditor); ok {
+		auditor(record)
+		MarkRecorded(ctx)
+	}
+}
+
+// MarkRecorded tells the request records its changes itself, even when it doesn't make any, like a dry run.
+// Otherwise, the request is recorded without the detail of its changes once it succeeds.
+func MarkRecorded(ctx echo.Context) {
+	ctx.Set(contextKeyRecorded, true)
+}
+
+// IsRecorded returns true if a change made by the request has been recorded, or if the request records its changes itself.
+func IsRecorded(ctx echo.Context) bool {
+	recorded, _ := ctx.Get(contextKeyRecorded).(bool)
+	return recorded
+}
+
+// GetChanged returns the entity about to be changed, read with get, so the change can be recorded and sent to the webhooks.
+// It returns nil if the changes are neither recorded nor sent, or if the entity cannot be read.
+func GetChanged(ctx echo.Context, get func() (interface{}, error)) interface{} {
+	if !IsAudited(ctx) && !IsNotified(ctx) {
+		return nil
+	}
+	entity, err := get()
+	if err != nil {
+		// the change fails as well when the entity cannot be read.
+		return nil
+	}
+	return entity
+}
+
+// AuditChange records the creation, the update or the deletion of an entity. The old entity is nil for a creation,
+// and the new one is nil for a deletion. They are the ones returned by the API, so they don't contain any secret.
+func AuditChange(ctx echo.Context, action v1.Action, oldEntity interface{}, newEntity interface{}) {
+	if !IsAudited(ctx) {
+		return
+	}
+	entity, ok := newEntity.(api.Entity)
+	if !ok {
+		if entity, ok = oldEntity.(api.Entity); !ok {
+			return
+		}
+	}
+	record := NewAuditRecord(ctx, getAuditResource(entity))
+	record.Spec.Action = action
+	if version, hasVersion := getVersion(oldEntity); hasVersion {
+		record.Spec.OldVersion = &version
+	}
+	if version, hasVersion := getVersion(newEntity); hasVersion {
+		record.Spec.NewVersion = &version
+	}
+	diff, err := diffEntities(oldEntity, newEntity)
+	if err != nil {
+		// the change is recorded anyway, only its content is missing.
+		logrus.WithError(err).Errorf("unable to compute the changes of the %s %q", entity.GetKind(), entity.GetMetadata().GetName())
+	}
+	record.Spec.Diff = diff
+	Audit(ctx, record)
+}
+
+func getAuditResource(entity api.Entity) v1.AuditResource {
+	resource := v1.AuditResource{
+		Kind: v1.Kind(entity.GetKind()),
+		Name: entity.GetMetadata().GetName(),
+	}
+	if metadata, ok := entity.GetMetadata().(*v1.ProjectMetadata); ok {
+		resource.Project = metadata.Project
+	} else if resource.Kind == v1.KindProject {
+		resource.Project = resource.Name
+	}
+	return resource
+}
+
+// diffEntities returns the JSON merge patch changing the old entity into the new one.
+// It is the whole new entity for a creation, and null for a deletion, as a merge patch replaces the document when it is not an object.
+func diffEntities(oldEntity interface{}, newEntity interface{}) (json.RawMessage, error) {
+	if newEntity == nil {
+		return json.RawMessage("null"), nil
+	}
+	newData, err := json.Marshal(newEntity)
+	if err != nil {
+		return nil, err
+	}
+	if oldEntity == nil {
+		return newData, nil
+	}
+	oldData, err := json.Marshal(oldEntity)
+	if err != nil {
+		return nil, err
+	}
+	return jsonpatch.CreateMergePatch(oldData, newData)
+}
@@ -0,0 +1,223 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package authentication authenticates the users with their password or with an identity provider,
+// and gives them the tokens used to authenticate their requests. It verifies as well the API tokens of the service accounts.
+package authentication
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/perses/perses/internal/api/interface/v1/serviceaccount"
+	"github.com/perses/perses/internal/api/interface/v1/user"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/crypto"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/internal/api/shared/oauth"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type Authentication interface {
+	// Login verifies the password of the user and returns an access token and a refresh token.
+	Login(auth *api.Auth) (*api.AuthResponse, error)
+	// ExternalLogin returns an access token and a refresh token for the user authenticated by an identity provider.
+	// The user is created the first time they log in.
+	ExternalLogin(identity *oauth.Identity) (*api.AuthResponse, error)
+	// Refresh returns a new access token for the user of the refresh token.
+	Refresh(refreshToken string) (*api.AuthResponse, error)
+	// AuthenticateAPIToken returns the service account owning the API token, and the token itself.
+	// It returns an error if the token doesn't exist, is not valid or has expired.
+	AuthenticateAPIToken(token string) (*v1.ServiceAccount, *v1.APIToken, error)
+}
+
+// lastUseInterval is how often the last use of an API token is saved at most, so a service account is not written at every request.
+const lastUseInterval = time.Minute
+
+// lastUseAttempts is how many times the last use of an API token is saved when the service account is modified at the same time.
+const lastUseAttempts = 3
+
+func New(dao user.DAO, serviceAccountDAO serviceaccount.DAO, jwt crypto.JWT) Authentication {
+	return &authentication{
+		dao:               dao,
+		serviceAccountDAO: serviceAccountDAO,
+		jwt:               jwt,
+		lastUseSaved:      make(map[string]time.Time),
+	}
+}
+
+type authentication struct {
+	Authentication
+	dao               user.DAO
+	serviceAccountDAO serviceaccount.DAO
+	jwt               crypto.JWT
+	// lastUseSaved is when the last use of each API token has been saved by this instance, so it is not tried again at every request.
+	lastUseSaved map[string]time.Time
+	lastUseMutex sync.Mutex
+}
+
+func (a *authentication) Login(auth *api.Auth) (*api.AuthResponse, error) {
+	entity, err := a.dao.Get(auth.Login)
+	if err != nil {
+		if databaseModel.IsKeyNotFound(err) {
+			// the error is the same as for a wrong password, so it doesn't reveal which users exist.
+			return nil, shared.HandleUnauthorizedError("wrong login or password")
+		}
+		return nil, err
+	}
+	hash := entity.Spec.NativeProvider.Password
+	if len(hash) == 0 || bcrypt.CompareHashAndPassword([]byte(hash), []byte(auth.Password)) != nil {
+		return nil, shared.HandleUnauthorizedError("wrong login or password")
+	}
+	return a.signTokens(auth.Login, nil)
+}
+
+func (a *authentication) ExternalLogin(identity *oauth.Identity) (*api.AuthResponse, error) {
+	if err := common.ValidateID(identity.Login); err != nil {
+		return nil, shared.HandleUnauthorizedError(fmt.Sprintf("the login given by the provider is not valid: %s", err))
+	}
+	entity, err := a.dao.Get(identity.Login)
+	if err != nil {
+		if !databaseModel.IsKeyNotFound(err) {
+			return nil, err
+		}
+		entity = &v1.User{
+			Kind:     v1.KindUser,
+			Metadata: v1.Metadata{Name: identity.Login},
+			Spec: v1.UserSpec{
+				FirstName:      identity.FirstName,
+				LastName:       identity.LastName,
+				OAuthProviders: []v1.OAuthProvider{{Issuer: identity.Issuer, Subject: identity.Subject}},
+			},
+		}
+		entity.Metadata.CreateNow()
+		if createErr := a.dao.Create(entity); createErr != nil {
+			return nil, createErr
+		}
+	} else if !entity.Spec.HasOAuthProvider(identity.Issuer, identity.Subject) {
+		// the user is not linked to this account of the provider, otherwise anyone choosing the same login in the provider could impersonate them.
+		return nil, shared.HandleUnauthorizedError(fmt.Sprintf("the login %q is already used by another user", identity.Login))
+	}
+	return a.signTokens(identity.Login, identity.Groups)
+}
+
+func (a *authentication) Refresh(refreshToken string) (*api.AuthResponse, error) {
+	claims, err := a.jwt.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, shared.HandleUnauthorizedError(err.Error())
+	}
+	// the user may have been removed since the refresh token has been given.
+	if _, getErr := a.dao.Get(claims.Subject); getErr != nil {
+		if databaseModel.IsKeyNotFound(getErr) {
+			return nil, shared.HandleUnauthorizedError("the user doesn't exist anymore")
+		}
+		return nil, getErr
+	}
+	// the groups are the ones given by the identity provider when the user logged in.
+	accessToken, err := a.jwt.SignedAccessToken(claims.Subject, claims.Groups)
+	if err != nil {
+		logrus.WithError(err).Error("unable to sign the access token")
+		return nil, shared.InternalError
+	}
+	return &api.AuthResponse{AccessToken: accessToken}, nil
+}
+
+func (a *authentication) AuthenticateAPIToken(token string) (*v1.ServiceAccount, *v1.APIToken, error) {
+	serviceAccountName, tokenName, secret, err := crypto.ParseAPIToken(token)
+	if err != nil {
+		return nil, nil, shared.HandleUnauthorizedError(err.Error())
+	}
+	entity, err := a.serviceAccountDAO.Get(serviceAccountName)
+	if err != nil {
+		if databaseModel.IsKeyNotFound(err) {
+			// the error is the same as for a wrong secret, so it doesn't reveal which service accounts exist.
+			return nil, nil, shared.HandleUnauthorizedError("invalid API token")
+		}
+		return nil, nil, err
+	}
+	apiToken := entity.Spec.GetToken(tokenName)
+	if apiToken == nil || !crypto.VerifyAPITokenSecret(secret, apiToken.Hash) {
+		return nil, nil, shared.HandleUnauthorizedError("invalid API token")
+	}
+	now := time.Now().UTC()
+	if apiToken.IsExpired(now) {
+		return nil, nil, shared.HandleUnauthorizedError("the API token has expired")
+	}
+	if a.isLastUseOutdated(serviceAccountName, apiToken, now) {
+		a.saveLastUse(entity, tokenName, now)
+	}
+	return entity, apiToken, nil
+}
+
+// isLastUseOutdated tells whether the last use of the token must be saved. It is saved at most once per lastUseInterval,
+// even if saving it fails.
+func (a *authentication) isLastUseOutdated(serviceAccountName string, apiToken *v1.APIToken, now time.Time) bool {
+	if apiToken.LastUsedAt != nil && now.Sub(*apiToken.LastUsedAt) < lastUseInterval {
+		return false
+	}
+	key := serviceAccountName + "/" + apiToken.Name
+	a.lastUseMutex.Lock()
+	defer a.lastUseMutex.Unlock()
+	if saved, ok := a.lastUseSaved[key]; ok && now.Sub(saved) < lastUseInterval {
+		return false
+	}
+	a.lastUseSaved[key] = now
+	return true
+}
+
+// saveLastUse saves the last use of the token. The request is authenticated even if it fails.
+// The version of the service account is kept, as the last use is not a change made by a user: the clients updating
+// the service account don't get a conflict because of it. The expected version still protects the changes made at the same time,
+// in which case the service account is read again.
+func (a *authentication) saveLastUse(entity *v1.ServiceAccount, tokenName string, now time.Time) {
+	name := entity.Metadata.Name
+	for attempt := 1; ; attempt++ {
+		apiToken := entity.Spec.GetToken(tokenName)
+		if apiToken == nil {
+			// the token has just been revoked.
+			return
+		}
+		apiToken.LastUsedAt = &now
+		err := a.serviceAccountDAO.Update(entity, entity.Metadata.Version)
+		if err == nil {
+			return
+		}
+		if !databaseModel.IsKeyConflict(err) || attempt == lastUseAttempts {
+			logrus.WithError(err).Errorf("unable to save the last use of the token %q of the service account %q", tokenName, name)
+			return
+		}
+		if entity, err = a.serviceAccountDAO.Get(name); err != nil {
+			logrus.WithError(err).Errorf("unable to save the last use of the token %q of the service account %q", tokenName, name)
+			return
+		}
+	}
+}
+
+func (a *authentication) signTokens(login string, groups []string) (*api.AuthResponse, error) {
+	accessToken, err := a.jwt.SignedAccessToken(login, groups)
+	if err != nil {
+		logrus.WithError(err).Error("unable to sign the access token")
+		return nil, shared.InternalError
+	}
+	refreshToken, err := a.jwt.SignedRefreshToken(login, groups)
+	if err != nil {
+		logrus.WithError(err).Error("unable to sign the refresh token")
+		return nil, shared.InternalError
+	}
+	return &api.AuthResponse{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
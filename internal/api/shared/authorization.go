@@ -0,0 +1,84 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"reflect"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// contextKeyPermission is the key of the echo context where the Permission of the request is stored.
+const contextKeyPermission = "perses.permission"
+
+// Permission returns true if the user who sent the request is allowed to do the action on the resources of the kind in the project.
+// The project is empty for the global resources.
+type Permission func(action v1.Action, kind v1.Kind, project string) bool
+
+// SetPermission stores in the context what the user who sent the request is allowed to do.
+// It is used to filter the lists, and by the endpoints modifying several resources at once.
+func SetPermission(ctx echo.Context, permission Permission) {
+	ctx.Set(contextKeyPermission, permission)
+}
+
+// IsAllowed returns true if the user who sent the request is allowed to do the action on the resources of the kind in the project.
+// It is always true when the authorization is disabled.
+func IsAllowed(ctx echo.Context, action v1.Action, kind v1.Kind, project string) bool {
+	permission, ok := ctx.Get(contextKeyPermission).(Permission)
+	return !ok || permission(action, kind, project)
+}
+
+// CanRead returns true if the user who sent the request can read the resources of the kind in the project.
+// It is always true when the authorization is disabled.
+func CanRead(ctx echo.Context, kind v1.Kind, project string) bool {
+	return IsAllowed(ctx, v1.ActionRead, kind, project)
+}
+
+func canReadObject(ctx echo.Context, object interface{}) bool {
+	entity, ok := object.(api.Entity)
+	if !ok {
+		return true
+	}
+	kind := v1.Kind(entity.GetKind())
+	switch metadata := entity.GetMetadata().(type) {
+	case *v1.ProjectMetadata:
+		return CanRead(ctx, kind, metadata.Project)
+	default:
+		if kind == v1.KindProject {
+			// a project is read with the permissions given in the project itself.
+			return CanRead(ctx, kind, metadata.GetName())
+		}
+		return CanRead(ctx, kind, "")
+	}
+}
+
+// filterReadable returns the list without the resources the user who sent the request cannot read.
+func filterReadable(ctx echo.Context, list interface{}) interface{} {
+	if _, ok := ctx.Get(contextKeyPermission).(Permission); !ok {
+		return list
+	}
+	items := reflect.ValueOf(list)
+	if items.Kind() != reflect.Slice {
+		return list
+	}
+	result := reflect.MakeSlice(items.Type(), 0, items.Len())
+	for i := 0; i < items.Len(); i++ {
+		if canReadObject(ctx, items.Index(i).Interface()) {
+			result = reflect.Append(result, items.Index(i))
+		}
+	}
+	return result.Interface()
+}
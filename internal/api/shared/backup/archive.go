@@ -0,0 +1,172 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// maxRecordSize is the maximum size of a record in an archive. It is the size of a very large dashboard.
+const maxRecordSize = 64 * 1024 * 1024
+
+// archiveWriter writes the records of an archive. The footer is computed from the resources written.
+type archiveWriter struct {
+	gzipWriter *gzip.Writer
+	encoder    *json.Encoder
+	checksum   hash.Hash
+	resources  map[v1.Kind]int
+}
+
+func newArchiveWriter(w io.Writer) *archiveWriter {
+	gzipWriter := gzip.NewWriter(w)
+	return &archiveWriter{
+		gzipWriter: gzipWriter,
+		encoder:    json.NewEncoder(gzipWriter),
+		checksum:   sha256.New(),
+		resources:  make(map[v1.Kind]int),
+	}
+}
+
+func (a *archiveWriter) writeHeader(header *v1.BackupHeader) error {
+	return a.encoder.Encode(&v1.BackupRecord{Header: header})
+}
+
+func (a *archiveWriter) writeResource(entity modelAPI.Entity) error {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+	a.checksum.Write(data)
+	a.resources[v1.Kind(entity.GetKind())]++
+	return a.encoder.Encode(&v1.BackupRecord{Resource: data})
+}
+
+// close writes the footer and flushes the archive. The underlying writer is not closed.
+func (a *archiveWriter) close() error {
+	footer := &v1.BackupFooter{
+		Resources: a.resources,
+		Checksum:  hex.EncodeToString(a.checksum.Sum(nil)),
+	}
+	if err := a.encoder.Encode(&v1.BackupRecord{Footer: footer}); err != nil {
+		return err
+	}
+	return a.gzipWriter.Close()
+}
+
+// archive is the content of an archive once read.
+type archive struct {
+	header    *v1.BackupHeader
+	resources []modelAPI.Entity
+}
+
+// readArchive reads and checks an archive. An error is returned if the archive is truncated, if its resources don't match
+// the footer, or if it was written by a newer version of Perses.
+func readArchive(r io.Reader) (*archive, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("the archive is not compressed with gzip: %w", err)
+	}
+	defer gzipReader.Close() // nolint: errcheck
+	scanner := bufio.NewScanner(gzipReader)
+	scanner.Buffer(nil, maxRecordSize)
+	result := &archive{}
+	checksum := sha256.New()
+	resources := make(map[v1.Kind]int)
+	var footer *v1.BackupFooter
+	for i := 0; scanner.Scan(); i++ {
+		if footer != nil {
+			return nil, fmt.Errorf("the archive contains records after its footer")
+		}
+		record := &v1.BackupRecord{}
+		if unmarshalErr := json.Unmarshal(scanner.Bytes(), record); unmarshalErr != nil {
+			return nil, fmt.Errorf("the record %d of the archive is invalid: %w", i, unmarshalErr)
+		}
+		if i == 0 {
+			if record.Header == nil {
+				return nil, fmt.Errorf("the archive doesn't start with a header")
+			}
+			if record.Header.Version > v1.BackupVersion {
+				return nil, fmt.Errorf("the version %d of the archive is not supported, the latest version supported is %d", record.Header.Version, v1.BackupVersion)
+			}
+			result.header = record.Header
+			continue
+		}
+		switch {
+		case record.Footer != nil:
+			footer = record.Footer
+		case len(record.Resource) > 0:
+			entity, decodeErr := decodeResource(record.Resource)
+			if decodeErr != nil {
+				return nil, fmt.Errorf("the record %d of the archive is invalid: %w", i, decodeErr)
+			}
+			checksum.Write(record.Resource)
+			resources[v1.Kind(entity.GetKind())]++
+			result.resources = append(result.resources, entity)
+		default:
+			return nil, fmt.Errorf("the record %d of the archive is empty", i)
+		}
+	}
+	if scanErr := scanner.Err(); scanErr != nil {
+		return nil, fmt.Errorf("unable to read the archive: %w", scanErr)
+	}
+	if result.header == nil {
+		return nil, fmt.Errorf("the archive is empty")
+	}
+	if footer == nil {
+		return nil, fmt.Errorf("the archive is incomplete, its footer is missing")
+	}
+	if hex.EncodeToString(checksum.Sum(nil)) != footer.Checksum || !sameCount(resources, footer.Resources) {
+		return nil, fmt.Errorf("the archive is corrupted, its resources don't match its footer")
+	}
+	return result, nil
+}
+
+func decodeResource(data []byte) (modelAPI.Entity, error) {
+	kind := &struct {
+		Kind v1.Kind `json:"kind"`
+	}{}
+	if err := json.Unmarshal(data, kind); err != nil {
+		return nil, err
+	}
+	entity, err := v1.GetInternalStruct(kind.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if unmarshalErr := json.Unmarshal(data, entity); unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	return entity, nil
+}
+
+func sameCount(a map[v1.Kind]int, b map[v1.Kind]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for kind, count := range a {
+		if b[kind] != count {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,395 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backup exports every resource stored in the database to an archive, and restores them from it.
+// The archive doesn't depend on the database used, so it can be restored on a server using another database.
+package backup
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/perses/perses/internal/api/interface/v1/audit"
+	"github.com/perses/perses/internal/api/interface/v1/dashboard"
+	"github.com/perses/perses/internal/api/interface/v1/datasource"
+	"github.com/perses/perses/internal/api/interface/v1/folder"
+	"github.com/perses/perses/internal/api/interface/v1/globaldatasource"
+	"github.com/perses/perses/internal/api/interface/v1/globalrole"
+	"github.com/perses/perses/internal/api/interface/v1/globalrolebinding"
+	"github.com/perses/perses/internal/api/interface/v1/globalsecret"
+	"github.com/perses/perses/internal/api/interface/v1/globalvariable"
+	"github.com/perses/perses/internal/api/interface/v1/globalwebhook"
+	"github.com/perses/perses/internal/api/interface/v1/project"
+	"github.com/perses/perses/internal/api/interface/v1/role"
+	"github.com/perses/perses/internal/api/interface/v1/rolebinding"
+	"github.com/perses/perses/internal/api/interface/v1/secret"
+	"github.com/perses/perses/internal/api/interface/v1/serviceaccount"
+	"github.com/perses/perses/internal/api/interface/v1/trash"
+	"github.com/perses/perses/internal/api/interface/v1/user"
+	"github.com/perses/perses/internal/api/interface/v1/variable"
+	"github.com/perses/perses/internal/api/interface/v1/webhook"
+	"github.com/perses/perses/internal/api/interface/v1/webhookdelivery"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/crypto"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/internal/api/shared/search"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// Kinds is the order in which the resources are written in an archive. The projects come first,
+// so they exist before their resources when the archive is restored. It contains every kind of v1.KindMap and of v1.InternalKindMap.
+var Kinds = []v1.Kind{
+	v1.KindProject,
+	v1.KindGlobalDatasource,
+	v1.KindGlobalSecret,
+	v1.KindGlobalVariable,
+	v1.KindGlobalWebhook,
+	v1.KindDatasource,
+	v1.KindFolder,
+	v1.KindSecret,
+	v1.KindVariable,
+	v1.KindWebhook,
+	v1.KindDashboard,
+	v1.KindDashboardRevision,
+	v1.KindTrashEntry,
+	v1.KindUser,
+	v1.KindServiceAccount,
+	v1.KindGlobalRole,
+	v1.KindGlobalRoleBinding,
+	v1.KindRole,
+	v1.KindRoleBinding,
+	v1.KindAuditRecord,
+	v1.KindWebhookDelivery,
+}
+
+type Backup interface {
+	// Export writes an archive containing every resource stored in the database. The resources are read within a single transaction.
+	// If a passphrase is given, the secrets are encrypted with it instead of the encryption key of the server.
+	// Nothing is written if the resources cannot be read.
+	Export(w io.Writer, passphrase string) error
+	// Import restores every resource of the archive within a single transaction. The resources existing with the same name are replaced,
+	// the other ones are kept. The passphrase is required if the archive has been exported with one.
+	// Once the transaction is committed, onChange is called with the creation or the update of each resource of a kind of v1.KindMap,
+	// given like the API returns it. onChange can be nil.
+	Import(r io.Reader, passphrase string, onChange OnChange) (*v1.BackupSummary, error)
+}
+
+// OnChange is called with a change made by the import of an archive. The old entity is nil for a creation.
+type OnChange func(action v1.Action, oldEntity interface{}, newEntity interface{})
+
+// change is what the import does with one of the resources.
+type change struct {
+	action    v1.Action
+	oldEntity interface{}
+	newEntity interface{}
+}
+
+func New(dao databaseModel.DAO, serverCrypto crypto.Crypto, index search.Index) Backup {
+	return &backup{
+		dao:    dao,
+		crypto: serverCrypto,
+		index:  index,
+	}
+}
+
+// Verify reads the archive and checks it is complete and consistent, without restoring anything.
+func Verify(r io.Reader) (*v1.BackupSummary, error) {
+	a, err := readArchive(r)
+	if err != nil {
+		return nil, err
+	}
+	return a.summary(), nil
+}
+
+type backup struct {
+	Backup
+	dao    databaseModel.DAO
+	crypto crypto.Crypto
+	index  search.Index
+}
+
+func (b *backup) Export(w io.Writer, passphrase string) error {
+	header := &v1.BackupHeader{
+		Version:   v1.BackupVersion,
+		CreatedAt: time.Now().UTC(),
+		Encryption: v1.BackupEncryption{
+			Mode:           v1.BackupEncryptionServerKey,
+			KeyFingerprint: b.crypto.Fingerprint(),
+		},
+	}
+	var archiveCrypto crypto.Crypto
+	if len(passphrase) > 0 {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+		var err error
+		archiveCrypto, err = crypto.NewFromPassphrase(passphrase, salt)
+		if err != nil {
+			return err
+		}
+		header.Encryption = v1.BackupEncryption{
+			Mode:           v1.BackupEncryptionPassphrase,
+			Salt:           salt,
+			KeyFingerprint: archiveCrypto.Fingerprint(),
+		}
+	}
+	var resources []modelAPI.Entity
+	if err := b.dao.Transaction(func(tx databaseModel.DAO) error {
+		var listErr error
+		resources, listErr = ListAll(tx)
+		return listErr
+	}); err != nil {
+		return err
+	}
+	if archiveCrypto != nil {
+		if err := reEncryptSecrets(resources, b.crypto, archiveCrypto); err != nil {
+			logrus.WithError(err).Error("unable to encrypt the secrets with the passphrase")
+			return shared.InternalError
+		}
+	}
+	writer := newArchiveWriter(w)
+	if err := writer.writeHeader(header); err != nil {
+		return err
+	}
+	for _, entity := range resources {
+		if err := writer.writeResource(entity); err != nil {
+			return err
+		}
+	}
+	return writer.close()
+}
+
+func (b *backup) Import(r io.Reader, passphrase string, onChange OnChange) (*v1.BackupSummary, error) {
+	a, err := readArchive(r)
+	if err != nil {
+		return nil, shared.HandleBadRequestError(err.Error())
+	}
+	encryption := a.header.Encryption
+	switch encryption.Mode {
+	case v1.BackupEncryptionServerKey:
+		if len(passphrase) > 0 {
+			return nil, shared.HandleBadRequestError("the secrets of the archive are not encrypted with a passphrase")
+		}
+		if encryption.KeyFingerprint != b.crypto.Fingerprint() {
+			return nil, shared.HandleBadRequestError("the secrets of the archive are encrypted with another key than the one of the server, the archive must be exported with a passphrase")
+		}
+	case v1.BackupEncryptionPassphrase:
+		if len(passphrase) == 0 {
+			return nil, shared.HandleBadRequestError("the secrets of the archive are encrypted with a passphrase, it must be provided")
+		}
+		archiveCrypto, cryptoErr := crypto.NewFromPassphrase(passphrase, encryption.Salt)
+		if cryptoErr != nil {
+			return nil, shared.HandleBadRequestError(cryptoErr.Error())
+		}
+		if encryption.KeyFingerprint != archiveCrypto.Fingerprint() {
+			return nil, shared.HandleBadRequestError("the passphrase is wrong")
+		}
+		if reEncryptErr := reEncryptSecrets(a.resources, archiveCrypto, b.crypto); reEncryptErr != nil {
+			return nil, shared.HandleBadRequestError(fmt.Sprintf("unable to decrypt the secrets of the archive: %s", reEncryptErr))
+		}
+	default:
+		return nil, shared.HandleBadRequestError(fmt.Sprintf("the encryption %q of the archive is not supported", encryption.Mode))
+	}
+	var changes []*change
+	if txErr := b.dao.Transaction(func(tx databaseModel.DAO) error {
+		for _, entity := range a.resources {
+			if onChange != nil && v1.KindMap[v1.Kind(entity.GetKind())] {
+				c, changeErr := getChange(tx, entity)
+				if changeErr != nil {
+					return changeErr
+				}
+				changes = append(changes, c)
+			}
+			if upsertErr := tx.Upsert(entity); upsertErr != nil {
+				return upsertErr
+			}
+		}
+		return nil
+	}); txErr != nil {
+		return nil, txErr
+	}
+	if indexErr := b.index.Rebuild(); indexErr != nil {
+		// the resources are restored, the index will be rebuilt by the next refresh.
+		logrus.WithError(indexErr).Error("unable to rebuild the search index once the archive has been restored")
+	}
+	for _, c := range changes {
+		onChange(c.action, c.oldEntity, c.newEntity)
+	}
+	return a.summary(), nil
+}
+
+// getChange returns the change made by the restoration of the entity. It must be called before the entity is written.
+func getChange(tx databaseModel.DAO, entity modelAPI.Entity) (*change, error) {
+	kind := v1.Kind(entity.GetKind())
+	current, err := v1.GetStruct(kind)
+	if err != nil {
+		return nil, err
+	}
+	if getErr := tx.Get(kind, entity.GetMetadata(), current); getErr != nil {
+		if databaseModel.IsKeyNotFound(getErr) {
+			return &change{action: v1.ActionCreate, newEntity: publicEntity(entity)}, nil
+		}
+		return nil, getErr
+	}
+	return &change{action: v1.ActionUpdate, oldEntity: publicEntity(current), newEntity: publicEntity(entity)}, nil
+}
+
+// publicEntity returns the entity like the API returns it, so the secrets, the passwords and the hashes of the tokens are not exposed.
+func publicEntity(entity modelAPI.Entity) interface{} {
+	switch e := entity.(type) {
+	case *v1.Secret:
+		return v1.NewPublicSecret(e)
+	case *v1.GlobalSecret:
+		return v1.NewPublicGlobalSecret(e)
+	case *v1.User:
+		return v1.NewPublicUser(e)
+	case *v1.ServiceAccount:
+		result := *e
+		result.Spec.Tokens = make([]v1.APIToken, 0, len(e.Spec.Tokens))
+		for _, token := range e.Spec.Tokens {
+			token.Hash = ""
+			result.Spec.Tokens = append(result.Spec.Tokens, token)
+		}
+		return &result
+	}
+	return entity
+}
+
+func (a *archive) summary() *v1.BackupSummary {
+	resources := make(map[v1.Kind]int)
+	for _, entity := range a.resources {
+		resources[v1.Kind(entity.GetKind())]++
+	}
+	return &v1.BackupSummary{
+		CreatedAt: a.header.CreatedAt,
+		Resources: resources,
+	}
+}
+
+// reEncryptSecrets decrypts the secrets contained in the resources with from, and encrypts them again with to.
+func reEncryptSecrets(resources []modelAPI.Entity, from crypto.Crypto, to crypto.Crypto) error {
+	reEncrypt := func(spec *v1.SecretSpec) error {
+		if err := from.Decrypt(spec); err != nil {
+			return err
+		}
+		return to.Encrypt(spec)
+	}
+	for _, entity := range resources {
+		switch e := entity.(type) {
+		case *v1.GlobalSecret:
+			if err := reEncrypt(&e.Spec); err != nil {
+				return err
+			}
+		case *v1.Secret:
+			if err := reEncrypt(&e.Spec); err != nil {
+				return err
+			}
+		case *v1.TrashEntry:
+			if e.Spec.Content == nil {
+				continue
+			}
+			for _, s := range e.Spec.Content.Secrets {
+				if err := reEncrypt(&s.Spec); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ListAll returns every resource stored in the database, in the order of Kinds.
+func ListAll(dao databaseModel.DAO) ([]modelAPI.Entity, error) {
+	var result []modelAPI.Entity
+	var projects []modelAPI.Entity
+	for _, kind := range Kinds {
+		var list []modelAPI.Entity
+		var err error
+		switch kind {
+		case v1.KindProject:
+			list, err = query[*v1.Project](dao, &project.Query{})
+			projects = list
+		case v1.KindGlobalDatasource:
+			list, err = query[*v1.GlobalDatasource](dao, &globaldatasource.Query{})
+		case v1.KindGlobalSecret:
+			list, err = query[*v1.GlobalSecret](dao, &globalsecret.Query{})
+		case v1.KindGlobalVariable:
+			list, err = query[*v1.GlobalVariable](dao, &globalvariable.Query{})
+		case v1.KindGlobalWebhook:
+			list, err = query[*v1.GlobalWebhook](dao, &globalwebhook.Query{})
+		case v1.KindDatasource:
+			list, err = query[*v1.Datasource](dao, &datasource.Query{})
+		case v1.KindFolder:
+			list, err = query[*v1.Folder](dao, &folder.Query{})
+		case v1.KindSecret:
+			list, err = query[*v1.Secret](dao, &secret.Query{})
+		case v1.KindVariable:
+			list, err = query[*v1.Variable](dao, &variable.Query{})
+		case v1.KindWebhook:
+			list, err = query[*v1.Webhook](dao, &webhook.Query{})
+		case v1.KindDashboard:
+			list, err = query[*v1.Dashboard](dao, &dashboard.Query{})
+		case v1.KindDashboardRevision:
+			// the revisions can only be listed by project.
+			for _, p := range projects {
+				revisions, revisionErr := query[*v1.DashboardRevision](dao, &dashboard.RevisionQuery{Project: p.GetMetadata().GetName()})
+				if revisionErr != nil {
+					return nil, revisionErr
+				}
+				list = append(list, revisions...)
+			}
+		case v1.KindTrashEntry:
+			list, err = query[*v1.TrashEntry](dao, &trash.Query{})
+		case v1.KindUser:
+			list, err = query[*v1.User](dao, &user.Query{})
+		case v1.KindServiceAccount:
+			list, err = query[*v1.ServiceAccount](dao, &serviceaccount.Query{})
+		case v1.KindGlobalRole:
+			list, err = query[*v1.GlobalRole](dao, &globalrole.Query{})
+		case v1.KindGlobalRoleBinding:
+			list, err = query[*v1.GlobalRoleBinding](dao, &globalrolebinding.Query{})
+		case v1.KindRole:
+			list, err = query[*v1.Role](dao, &role.Query{})
+		case v1.KindRoleBinding:
+			list, err = query[*v1.RoleBinding](dao, &rolebinding.Query{})
+		case v1.KindAuditRecord:
+			list, err = query[*v1.AuditRecord](dao, &audit.Query{})
+		case v1.KindWebhookDelivery:
+			list, err = query[*v1.WebhookDelivery](dao, &webhookdelivery.Query{})
+		default:
+			return nil, fmt.Errorf("the kind %q cannot be exported", kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, list...)
+	}
+	return result, nil
+}
+
+func query[T modelAPI.Entity](dao databaseModel.DAO, q databaseModel.Query) ([]modelAPI.Entity, error) {
+	var list []T
+	if err := dao.Query(q, &list); err != nil {
+		return nil, err
+	}
+	result := make([]modelAPI.Entity, 0, len(list))
+	for _, entity := range list {
+		result = append(result, entity)
+	}
+	return result, nil
+}
@@ -0,0 +1,253 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/perses/perses/internal/api/config"
+	"github.com/perses/perses/internal/api/interface/v1/trash"
+	"github.com/perses/perses/internal/api/shared/crypto"
+	databaseFile "github.com/perses/perses/internal/api/shared/database/file"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/internal/api/shared/search"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	"github.com/perses/perses/pkg/model/api/v1/secret"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeIndex struct {
+	search.Index
+}
+
+func (f *fakeIndex) Rebuild() error {
+	return nil
+}
+
+func newCrypto(t *testing.T, key string) crypto.Crypto {
+	c, err := crypto.New(hex.EncodeToString([]byte(key)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func newDAO(t *testing.T) databaseModel.DAO {
+	return &databaseFile.DAO{
+		Folder:    t.TempDir(),
+		Extension: config.JSONExtension,
+	}
+}
+
+func newSecret(t *testing.T, c crypto.Crypto, project string, name string) *v1.Secret {
+	entity := &v1.Secret{
+		Kind: v1.KindSecret,
+		Metadata: v1.ProjectMetadata{
+			Metadata: v1.Metadata{Name: name},
+			Project:  project,
+		},
+		Spec: v1.SecretSpec{
+			BasicAuth: &secret.BasicAuth{Username: "user", Password: "password"},
+		},
+	}
+	if err := c.Encrypt(&entity.Spec); err != nil {
+		t.Fatal(err)
+	}
+	return entity
+}
+
+// newSource returns a DAO containing a resource of every kind. The secrets are encrypted with c.
+func newSource(t *testing.T, c crypto.Crypto) databaseModel.DAO {
+	dao := newDAO(t)
+	project := &v1.Project{Kind: v1.KindProject, Metadata: v1.Metadata{Name: "perses"}}
+	dashboard := &v1.Dashboard{
+		Kind: v1.KindDashboard,
+		Metadata: v1.ProjectMetadata{
+			Metadata: v1.Metadata{Name: "demo"},
+			Project:  "perses",
+		},
+		Spec: v1.DashboardSpec{
+			Panels: map[string]*v1.Panel{
+				"cpu": {Kind: "Panel", Spec: v1.PanelSpec{Display: v1.PanelDisplay{Name: "CPU"}, Plugin: common.Plugin{Kind: "TimeSeriesChart"}}},
+			},
+		},
+	}
+	deletedProject := &v1.Project{Kind: v1.KindProject, Metadata: v1.Metadata{Name: "deleted"}}
+	content := &v1.TrashContent{
+		Project: deletedProject,
+		Secrets: []*v1.Secret{newSecret(t, c, "deleted", "trashed")},
+	}
+	globalSecret := &v1.GlobalSecret{
+		Kind:     v1.KindGlobalSecret,
+		Metadata: v1.Metadata{Name: "global"},
+		Spec: v1.SecretSpec{
+			Authorization: &secret.Authorization{Type: "Bearer", Credentials: "token"},
+		},
+	}
+	if err := c.Encrypt(&globalSecret.Spec); err != nil {
+		t.Fatal(err)
+	}
+	resources := []modelAPI.Entity{
+		project,
+		dashboard,
+		v1.NewDashboardRevision(dashboard, ""),
+		newSecret(t, c, "perses", "secret"),
+		globalSecret,
+		v1.NewTrashEntry(v1.TrashResource{Kind: v1.KindProject, Project: "deleted", Name: "deleted"}, content, 0),
+	}
+	for _, entity := range resources {
+		if err := dao.Create(entity); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dao
+}
+
+func getPassword(t *testing.T, dao databaseModel.DAO, c crypto.Crypto) string {
+	entity := &v1.Secret{}
+	if err := dao.Get(v1.KindSecret, &v1.ProjectMetadata{Metadata: v1.Metadata{Name: "secret"}, Project: "perses"}, entity); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Decrypt(&entity.Spec); err != nil {
+		t.Fatal(err)
+	}
+	return entity.Spec.BasicAuth.Password
+}
+
+func TestKinds(t *testing.T) {
+	assert.Len(t, Kinds, len(v1.KindMap)+len(v1.InternalKindMap))
+	for _, kind := range Kinds {
+		assert.True(t, v1.KindMap[kind] || v1.InternalKindMap[kind], kind)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	sourceCrypto := newCrypto(t, "=tW$56zytgB&3jN2E%7-+qrGZE?v6LCc")
+	targetCrypto := newCrypto(t, "f5Kd9x!qLm2#Wz7@Rt4$Yp8&Hv3*Nc6J")
+	source := New(newSource(t, sourceCrypto), sourceCrypto, &fakeIndex{})
+	expectedResources := map[v1.Kind]int{
+		v1.KindProject:           1,
+		v1.KindDashboard:         1,
+		v1.KindDashboardRevision: 1,
+		v1.KindSecret:            1,
+		v1.KindGlobalSecret:      1,
+		v1.KindTrashEntry:        1,
+	}
+
+	// without passphrase, the archive can only be restored by a server using the same key.
+	buffer := &bytes.Buffer{}
+	assert.NoError(t, source.Export(buffer, ""))
+	archive := buffer.Bytes()
+	_, err := New(newDAO(t), targetCrypto, &fakeIndex{}).Import(bytes.NewReader(archive), "", nil)
+	assert.Error(t, err)
+	sameKeyDAO := newDAO(t)
+	summary, err := New(sameKeyDAO, sourceCrypto, &fakeIndex{}).Import(bytes.NewReader(archive), "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedResources, summary.Resources)
+	assert.Equal(t, "password", getPassword(t, sameKeyDAO, sourceCrypto))
+
+	// with a passphrase, the secrets are encrypted again with the key of the server restoring the archive.
+	buffer = &bytes.Buffer{}
+	assert.NoError(t, source.Export(buffer, "passphrase"))
+	archive = buffer.Bytes()
+	targetDAO := newDAO(t)
+	target := New(targetDAO, targetCrypto, &fakeIndex{})
+	_, err = target.Import(bytes.NewReader(archive), "", nil)
+	assert.Error(t, err)
+	_, err = target.Import(bytes.NewReader(archive), "wrong passphrase", nil)
+	assert.Error(t, err)
+	summary, err = target.Import(bytes.NewReader(archive), "passphrase", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedResources, summary.Resources)
+	assert.Equal(t, "password", getPassword(t, targetDAO, targetCrypto))
+
+	// the secrets kept in the trash are encrypted again as well.
+	var entries []*v1.TrashEntry
+	assert.NoError(t, targetDAO.Query(&trash.Query{}, &entries))
+	if assert.Len(t, entries, 1) {
+		trashedSecret := entries[0].Spec.Content.Secrets[0]
+		assert.NoError(t, targetCrypto.Decrypt(&trashedSecret.Spec))
+		assert.Equal(t, "password", trashedSecret.Spec.BasicAuth.Password)
+	}
+}
+
+func TestImportChanges(t *testing.T) {
+	c := newCrypto(t, "=tW$56zytgB&3jN2E%7-+qrGZE?v6LCc")
+	buffer := &bytes.Buffer{}
+	assert.NoError(t, New(newSource(t, c), c, &fakeIndex{}).Export(buffer, ""))
+	target := New(newDAO(t), c, &fakeIndex{})
+	var actions []string
+	onChange := func(action v1.Action, oldEntity interface{}, newEntity interface{}) {
+		entity := newEntity.(modelAPI.Entity)
+		actions = append(actions, fmt.Sprintf("%s %s %s", action, entity.GetKind(), entity.GetMetadata().GetName()))
+		// the secrets are given like the API returns them.
+		_, isSecret := newEntity.(*v1.Secret)
+		assert.False(t, isSecret)
+		assert.Equal(t, action == v1.ActionCreate, oldEntity == nil)
+	}
+
+	// the revisions and the entries of the trash are not part of the changes, as they cannot be modified with the API.
+	_, err := target.Import(bytes.NewReader(buffer.Bytes()), "", onChange)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"create Project perses", "create GlobalSecret global", "create Secret secret", "create Dashboard demo"}, actions)
+
+	actions = nil
+	_, err = target.Import(bytes.NewReader(buffer.Bytes()), "", onChange)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"update Project perses", "update GlobalSecret global", "update Secret secret", "update Dashboard demo"}, actions)
+}
+
+func TestReadArchive(t *testing.T) {
+	c := newCrypto(t, "=tW$56zytgB&3jN2E%7-+qrGZE?v6LCc")
+	buffer := &bytes.Buffer{}
+	assert.NoError(t, New(newSource(t, c), c, &fakeIndex{}).Export(buffer, ""))
+	summary, err := Verify(bytes.NewReader(buffer.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.Resources[v1.KindProject])
+
+	header := &v1.BackupHeader{Version: v1.BackupVersion}
+	project := &v1.Project{Kind: v1.KindProject, Metadata: v1.Metadata{Name: "perses"}}
+
+	// the footer is missing
+	buffer = &bytes.Buffer{}
+	writer := newArchiveWriter(buffer)
+	assert.NoError(t, writer.writeHeader(header))
+	assert.NoError(t, writer.writeResource(project))
+	assert.NoError(t, writer.gzipWriter.Close())
+	_, err = Verify(bytes.NewReader(buffer.Bytes()))
+	assert.EqualError(t, err, "the archive is incomplete, its footer is missing")
+
+	// the footer doesn't match the resources
+	buffer = &bytes.Buffer{}
+	writer = newArchiveWriter(buffer)
+	assert.NoError(t, writer.writeHeader(header))
+	assert.NoError(t, writer.writeResource(project))
+	writer.resources[v1.KindDashboard] = 1
+	assert.NoError(t, writer.close())
+	_, err = Verify(bytes.NewReader(buffer.Bytes()))
+	assert.EqualError(t, err, "the archive is corrupted, its resources don't match its footer")
+
+	// the archive has been written by a newer version
+	buffer = &bytes.Buffer{}
+	writer = newArchiveWriter(buffer)
+	assert.NoError(t, writer.writeHeader(&v1.BackupHeader{Version: v1.BackupVersion + 1}))
+	assert.NoError(t, writer.close())
+	_, err = Verify(bytes.NewReader(buffer.Bytes()))
+	assert.Error(t, err)
+}
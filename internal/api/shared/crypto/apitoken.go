@@ -0,0 +1,81 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APITokenPrefix starts every API token, so they are not confused with the access tokens of the users, which are JWT.
+const APITokenPrefix = "perses_"
+
+// apiTokenSecretSize is the number of random bytes of the secret part of an API token.
+const apiTokenSecretSize = 32
+
+// NewAPIToken generates the token of a service account, and the hash of its secret that is stored in the database.
+// The token is "perses_<service account>.<token name>.<secret>". The names of the tokens and the secrets don't contain a dot,
+// so the token can be split from the end even if the name of the service account contains one.
+func NewAPIToken(serviceAccount string, tokenName string) (token string, hash string, err error) {
+	secret := make([]byte, apiTokenSecretSize)
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	encodedSecret := base64.RawURLEncoding.EncodeToString(secret)
+	return fmt.Sprintf("%s%s.%s.%s", APITokenPrefix, serviceAccount, tokenName, encodedSecret), HashAPITokenSecret(encodedSecret), nil
+}
+
+// IsAPIToken returns true if the token looks like an API token rather than an access token.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// ParseAPIToken returns the name of the service account, the name of the token and the secret contained in the token.
+func ParseAPIToken(token string) (serviceAccount string, tokenName string, secret string, err error) {
+	rest, found := strings.CutPrefix(token, APITokenPrefix)
+	if !found {
+		return "", "", "", fmt.Errorf("an API token must start with %q", APITokenPrefix)
+	}
+	secretIndex := strings.LastIndex(rest, ".")
+	if secretIndex < 0 {
+		return "", "", "", fmt.Errorf("malformed API token")
+	}
+	rest, secret = rest[:secretIndex], rest[secretIndex+1:]
+	nameIndex := strings.LastIndex(rest, ".")
+	if nameIndex < 0 {
+		return "", "", "", fmt.Errorf("malformed API token")
+	}
+	serviceAccount, tokenName = rest[:nameIndex], rest[nameIndex+1:]
+	if len(serviceAccount) == 0 || len(tokenName) == 0 || len(secret) == 0 {
+		return "", "", "", fmt.Errorf("malformed API token")
+	}
+	return serviceAccount, tokenName, secret, nil
+}
+
+// HashAPITokenSecret returns the SHA-256 hash of the secret, encoded in hexadecimal.
+// The secret is random and long enough, so it doesn't need a slow hash like a password does.
+func HashAPITokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyAPITokenSecret compares in constant time the hash of the secret with the hash stored.
+func VerifyAPITokenSecret(secret string, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashAPITokenSecret(secret)), []byte(hash)) == 1
+}
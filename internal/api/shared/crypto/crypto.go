@@ -16,20 +16,33 @@ package crypto
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"io"
 
 	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"golang.org/x/crypto/scrypt"
 )
 
 // based on https://www.golinuxcloud.com/golang-encrypt-decrypt/
 
+// The parameters of scrypt recommended for interactive logins. Deriving a key takes around 100ms.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
 type Crypto interface {
 	Encrypt(spec *modelV1.SecretSpec) error
 	Decrypt(spec *modelV1.SecretSpec) error
+	// Fingerprint identifies the key without revealing it. Two Crypto have the same fingerprint only if they use the same key.
+	Fingerprint() string
 }
 
 func New(encodedKey string) (Crypto, error) {
@@ -37,18 +50,42 @@ func New(encodedKey string) (Crypto, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newCrypto(key)
+}
 
+// NewFromPassphrase returns a Crypto using a key derived from the passphrase and the salt.
+// The same passphrase and the same salt always give the same key.
+func NewFromPassphrase(passphrase string, salt []byte) (Crypto, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("the passphrase cannot be empty")
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	return newCrypto(key)
+}
+
+func newCrypto(key []byte) (Crypto, error) {
 	aesBlock, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("perses key fingerprint"))
 	return &crypto{
-		block: aesBlock,
+		block:       aesBlock,
+		fingerprint: hex.EncodeToString(mac.Sum(nil)),
 	}, nil
 }
 
 type crypto struct {
-	block cipher.Block
+	block       cipher.Block
+	fingerprint string
+}
+
+func (c *crypto) Fingerprint() string {
+	return c.fingerprint
 }
 
 func (c *crypto) Encrypt(spec *modelV1.SecretSpec) error {
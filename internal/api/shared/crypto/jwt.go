@@ -0,0 +1,116 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/perses/perses/internal/api/config"
+)
+
+// JWTClaims are the claims of the tokens. The subject is the login of the user.
+type JWTClaims struct {
+	jwt.StandardClaims
+	// Groups are the groups of the user given by the identity provider they logged in with.
+	// They are empty for a user authenticated by Perses itself.
+	Groups []string `json:"groups,omitempty"`
+}
+
+// JWT signs and verifies the tokens given to the users once they are authenticated.
+// The access tokens and the refresh tokens are signed with different keys, so one cannot be used instead of the other.
+type JWT interface {
+	SignedAccessToken(login string, groups []string) (string, error)
+	SignedRefreshToken(login string, groups []string) (string, error)
+	// ValidateAccessToken returns the claims of the access token, or an error if it is not valid or has expired.
+	ValidateAccessToken(token string) (*JWTClaims, error)
+	// ValidateRefreshToken returns the claims of the refresh token, or an error if it is not valid or has expired.
+	ValidateRefreshToken(token string) (*JWTClaims, error)
+}
+
+// NewJWT returns the JWT signing the tokens with keys derived from the encryption key, encoded in hexadecimal like the one of New.
+func NewJWT(encodedKey string, conf config.Authentication) (JWT, error) {
+	key, err := hex.DecodeString(encodedKey)
+	if err != nil {
+		return nil, err
+	}
+	deriveKey := func(purpose string) []byte {
+		mac := hmac.New(sha512.New, key)
+		mac.Write([]byte(purpose))
+		return mac.Sum(nil)
+	}
+	return &jwtImpl{
+		accessKey:       deriveKey("perses access token"),
+		refreshKey:      deriveKey("perses refresh token"),
+		accessTokenTTL:  time.Duration(conf.AccessTokenTTL),
+		refreshTokenTTL: time.Duration(conf.RefreshTokenTTL),
+	}, nil
+}
+
+type jwtImpl struct {
+	JWT
+	accessKey       []byte
+	refreshKey      []byte
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+}
+
+func (j *jwtImpl) SignedAccessToken(login string, groups []string) (string, error) {
+	return sign(login, groups, j.accessKey, j.accessTokenTTL)
+}
+
+func (j *jwtImpl) SignedRefreshToken(login string, groups []string) (string, error) {
+	return sign(login, groups, j.refreshKey, j.refreshTokenTTL)
+}
+
+func (j *jwtImpl) ValidateAccessToken(token string) (*JWTClaims, error) {
+	return validate(token, j.accessKey)
+}
+
+func (j *jwtImpl) ValidateRefreshToken(token string) (*JWTClaims, error) {
+	return validate(token, j.refreshKey)
+}
+
+func sign(login string, groups []string, key []byte, ttl time.Duration) (string, error) {
+	claims := &JWTClaims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   login,
+			ExpiresAt: time.Now().Add(ttl).Unix(),
+		},
+		Groups: groups,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS512, claims).SignedString(key)
+}
+
+func validate(token string, key []byte) (*JWTClaims, error) {
+	claims := &JWTClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		// the algorithm must be checked, otherwise a token signed with another one, like "none", would be accepted.
+		if t.Method != jwt.SigningMethodHS512 {
+			return nil, fmt.Errorf("unexpected signing method %q", t.Header["alg"])
+		}
+		return key, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(claims.Subject) == 0 {
+		return nil, fmt.Errorf("the subject of the token is missing")
+	}
+	return claims, nil
+}
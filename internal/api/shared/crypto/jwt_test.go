@@ -0,0 +1,80 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/perses/perses/internal/api/config"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// testEncryptionKey is encoded in hexadecimal like the one of the configuration.
+var testEncryptionKey = hex.EncodeToString([]byte("=tW$56zytgB&3jN2E%7-+qrGZE?v6LCc"))
+
+func newTestJWT(t *testing.T, accessTokenTTL time.Duration) JWT {
+	j, err := NewJWT(testEncryptionKey, config.Authentication{
+		AccessTokenTTL:  model.Duration(accessTokenTTL),
+		RefreshTokenTTL: model.Duration(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return j
+}
+
+func TestJWT(t *testing.T) {
+	j := newTestJWT(t, time.Minute)
+	accessToken, err := j.SignedAccessToken("john", nil)
+	assert.NoError(t, err)
+	refreshToken, err := j.SignedRefreshToken("john", []string{"sre"})
+	assert.NoError(t, err)
+
+	claims, err := j.ValidateAccessToken(accessToken)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "john", claims.Subject)
+		assert.Empty(t, claims.Groups)
+	}
+	claims, err = j.ValidateRefreshToken(refreshToken)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "john", claims.Subject)
+		assert.Equal(t, []string{"sre"}, claims.Groups)
+	}
+	// a token cannot be used in place of the other one.
+	_, err = j.ValidateAccessToken(refreshToken)
+	assert.Error(t, err)
+	_, err = j.ValidateRefreshToken(accessToken)
+	assert.Error(t, err)
+}
+
+func TestJWTExpired(t *testing.T) {
+	j := newTestJWT(t, -time.Minute)
+	accessToken, err := j.SignedAccessToken("john", nil)
+	assert.NoError(t, err)
+	_, err = j.ValidateAccessToken(accessToken)
+	assert.Error(t, err)
+}
+
+func TestJWTOtherSigningMethod(t *testing.T) {
+	j := newTestJWT(t, time.Minute)
+	claims := &JWTClaims{StandardClaims: jwt.StandardClaims{Subject: "john", ExpiresAt: time.Now().Add(time.Minute).Unix()}}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	assert.NoError(t, err)
+	_, err = j.ValidateAccessToken(token)
+	assert.Error(t, err)
+}
@@ -0,0 +1,474 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package databasecache provides a DAO keeping in memory the resources read from another DAO.
+//
+// The resources are invalidated when they are written through the cache. The changes made by the other instances of Perses
+// are received with the Watch of the DAO, so with the SQL databases, the cache stays coherent across the instances sharing the
+// database, with the delay of the poll interval. Otherwise, the TTL of the entries is the maximum delay before these changes are visible.
+package databasecache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/perses/perses/internal/api/config"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	operationGet   = "get"
+	operationQuery = "query"
+	resultHit      = "hit"
+	resultMiss     = "miss"
+
+	// watchRetryInterval is the time waited before watching again the changes of a kind, when the database refused it.
+	watchRetryInterval = 5 * time.Second
+)
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "perses",
+		Subsystem: "database_cache",
+		Name:      "requests_total",
+		Help:      "The number of reads served by the cache of the database, by operation (get or query) and by result (hit or miss).",
+	}, []string{"operation", "result"})
+	entriesGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "perses",
+		Subsystem: "database_cache",
+		Name:      "entries",
+		Help:      "The number of resources and lists kept in the cache of the database.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(requestsTotal, entriesGauge)
+}
+
+type entry struct {
+	key       string
+	isQuery   bool
+	data      []byte
+	expiresAt time.Time
+}
+
+// DAO is a databaseModel.DAO caching the result of Get and Query. The paginated queries and the reads made within a transaction
+// are not cached. The resources are kept encoded in JSON, so the entities returned are never shared between the callers.
+type DAO struct {
+	databaseModel.DAO
+	dao        databaseModel.DAO
+	ttl        time.Duration
+	maxEntries int
+	// now returns the current time. It is replaced in the tests.
+	now   func() time.Time
+	mutex sync.Mutex
+	// entries contains the elements of lru, by key.
+	entries map[string]*list.Element
+	// lru contains the entries from the most recently used to the least recently used one.
+	lru *list.List
+	// queryKeys are the keys of the entries containing the result of a query. They are all invalidated by any write.
+	queryKeys map[string]bool
+	// generation is incremented by every invalidation. A result read from the database is only cached if no invalidation happened
+	// during the read, otherwise it could be older than the write that caused the invalidation.
+	generation uint64
+	cancel     context.CancelFunc
+	done       sync.WaitGroup
+}
+
+func New(dao databaseModel.DAO, conf config.Cache) *DAO {
+	return &DAO{
+		dao:        dao,
+		ttl:        time.Duration(conf.TTL),
+		maxEntries: conf.MaxEntries,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		queryKeys:  make(map[string]bool),
+	}
+}
+
+// Init initializes the database, and then starts to receive the changes of every kind to invalidate the resources modified.
+func (d *DAO) Init() error {
+	if err := d.dao.Init(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	for kind := range modelV1.PluralKindMap {
+		d.done.Add(1)
+		go d.watch(ctx, kind)
+	}
+	return nil
+}
+
+func (d *DAO) Close() error {
+	if d.cancel != nil {
+		d.cancel()
+		d.done.Wait()
+	}
+	return d.dao.Close()
+}
+
+func (d *DAO) Create(entity modelAPI.Entity) error {
+	return d.create(d.dao, entity)
+}
+
+func (d *DAO) create(dao databaseModel.DAO, entity modelAPI.Entity) error {
+	err := dao.Create(entity)
+	d.invalidate(false, entityKey(entity))
+	return err
+}
+
+func (d *DAO) Upsert(entity modelAPI.Entity) error {
+	return d.upsert(d.dao, entity)
+}
+
+func (d *DAO) upsert(dao databaseModel.DAO, entity modelAPI.Entity) error {
+	err := dao.Upsert(entity)
+	d.invalidate(false, entityKey(entity))
+	return err
+}
+
+func (d *DAO) Update(entity modelAPI.Entity, expectedVersion uint64) error {
+	return d.update(d.dao, entity, expectedVersion)
+}
+
+func (d *DAO) update(dao databaseModel.DAO, entity modelAPI.Entity, expectedVersion uint64) error {
+	err := dao.Update(entity, expectedVersion)
+	d.invalidate(false, entityKey(entity))
+	return err
+}
+
+func (d *DAO) Get(kind modelV1.Kind, metadata modelAPI.Metadata, entity modelAPI.Entity) error {
+	key := getKey(kind, metadata)
+	data, generation, ok := d.load(key)
+	if ok {
+		requestsTotal.WithLabelValues(operationGet, resultHit).Inc()
+		return json.Unmarshal(data, entity)
+	}
+	requestsTotal.WithLabelValues(operationGet, resultMiss).Inc()
+	if err := d.dao.Get(kind, metadata, entity); err != nil {
+		return err
+	}
+	d.store(key, false, generation, entity)
+	return nil
+}
+
+func (d *DAO) Query(query databaseModel.Query, slice interface{}) error {
+	if q, ok := query.(databaseModel.PaginatedQuery); ok && q.GetPagination().IsEnabled() {
+		// the token of the next page is returned through the query, so the result cannot be cached.
+		return d.dao.Query(query, slice)
+	}
+	key, err := queryKey(query)
+	if err != nil {
+		return d.dao.Query(query, slice)
+	}
+	data, generation, ok := d.load(key)
+	if ok {
+		requestsTotal.WithLabelValues(operationQuery, resultHit).Inc()
+		return json.Unmarshal(data, slice)
+	}
+	requestsTotal.WithLabelValues(operationQuery, resultMiss).Inc()
+	if queryErr := d.dao.Query(query, slice); queryErr != nil {
+		return queryErr
+	}
+	d.store(key, true, generation, slice)
+	return nil
+}
+
+func (d *DAO) Delete(kind modelV1.Kind, metadata modelAPI.Metadata) error {
+	return d.delete(d.dao, kind, metadata)
+}
+
+func (d *DAO) delete(dao databaseModel.DAO, kind modelV1.Kind, metadata modelAPI.Metadata) error {
+	err := dao.Delete(kind, metadata)
+	d.invalidate(false, getKey(kind, metadata))
+	return err
+}
+
+func (d *DAO) DeleteByQuery(query databaseModel.Query) error {
+	return d.deleteByQuery(d.dao, query)
+}
+
+func (d *DAO) deleteByQuery(dao databaseModel.DAO, query databaseModel.Query) error {
+	err := dao.DeleteByQuery(query)
+	d.invalidate(true)
+	return err
+}
+
+// Transaction runs f with the DAO of the transaction, so the reads made by f are made within the transaction and are not cached.
+// The resources written by f are invalidated once the transaction is over.
+func (d *DAO) Transaction(f func(tx databaseModel.DAO) error) error {
+	return d.transaction(d.dao, f)
+}
+
+func (d *DAO) transaction(dao databaseModel.DAO, f func(tx databaseModel.DAO) error) error {
+	changes := &changeSet{}
+	err := dao.Transaction(func(tx databaseModel.DAO) error {
+		return f(&recorder{DAO: tx, changes: changes})
+	})
+	d.invalidate(changes.all, changes.keys...)
+	return err
+}
+
+// WithAuthor returns a DAO sharing the cache, whose changes are attributed to author when the database records who made each change.
+func (d *DAO) WithAuthor(author string) databaseModel.DAO {
+	dao := databaseModel.WithAuthor(d.dao, author)
+	if dao == d.dao {
+		return d
+	}
+	return &authoredDAO{DAO: d, dao: dao}
+}
+
+func (d *DAO) Watch(ctx context.Context, kind modelV1.Kind) (<-chan *databaseModel.Event, error) {
+	return d.dao.Watch(ctx, kind)
+}
+
+func (d *DAO) HealthCheck() bool {
+	return d.dao.HealthCheck()
+}
+
+// load returns the content of the entry if it exists and is not expired. Otherwise, it returns the current generation,
+// to give to store once the content has been read from the database.
+func (d *DAO) load(key string) ([]byte, uint64, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	element, ok := d.entries[key]
+	if !ok {
+		return nil, d.generation, false
+	}
+	e := element.Value.(*entry)
+	if d.now().After(e.expiresAt) {
+		d.remove(element)
+		return nil, d.generation, false
+	}
+	d.lru.MoveToFront(element)
+	return e.data, 0, true
+}
+
+// store keeps the value in the cache, unless an invalidation happened since the given generation.
+func (d *DAO) store(key string, isQuery bool, generation uint64, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		logrus.WithError(err).Errorf("unable to encode the value of %q to keep it in the cache", key)
+		return
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if generation != d.generation {
+		return
+	}
+	if element, ok := d.entries[key]; ok {
+		d.remove(element)
+	}
+	d.entries[key] = d.lru.PushFront(&entry{key: key, isQuery: isQuery, data: data, expiresAt: d.now().Add(d.ttl)})
+	if isQuery {
+		d.queryKeys[key] = true
+	}
+	for len(d.entries) > d.maxEntries {
+		d.remove(d.lru.Back())
+	}
+	entriesGauge.Set(float64(len(d.entries)))
+}
+
+// invalidate removes the entries of the keys and the results of the queries, or every entry if all is true.
+func (d *DAO) invalidate(all bool, keys ...string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.generation++
+	if all {
+		d.entries = make(map[string]*list.Element)
+		d.lru.Init()
+		d.queryKeys = make(map[string]bool)
+		entriesGauge.Set(0)
+		return
+	}
+	for _, key := range keys {
+		if element, ok := d.entries[key]; ok {
+			d.remove(element)
+		}
+	}
+	for key := range d.queryKeys {
+		d.remove(d.entries[key])
+	}
+	entriesGauge.Set(float64(len(d.entries)))
+}
+
+// remove must be called with the lock held.
+func (d *DAO) remove(element *list.Element) {
+	e := d.lru.Remove(element).(*entry)
+	delete(d.entries, e.key)
+	if e.isQuery {
+		delete(d.queryKeys, e.key)
+	}
+}
+
+// watch invalidates the resources of the kind modified, including by the other instances of Perses, until ctx is done.
+func (d *DAO) watch(ctx context.Context, kind modelV1.Kind) {
+	defer d.done.Done()
+	for {
+		events, err := d.dao.Watch(ctx, kind)
+		if err != nil {
+			logrus.WithError(err).Errorf("unable to watch the changes of the kind %s, the cache relies on its TTL until it succeeds", kind)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(watchRetryInterval):
+			}
+			continue
+		}
+		// some changes may have been missed while the changes were not watched, like when they were not consumed fast enough.
+		d.invalidate(true)
+		for event := range events {
+			key, keyErr := eventKey(event)
+			if keyErr != nil {
+				logrus.WithError(keyErr).Errorf("unable to decode the %s of the change, every entry of the cache is invalidated", kind)
+			}
+			d.invalidate(keyErr != nil, key)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// authoredDAO writes with the DAO of the database attributing the changes to an author, and reads with the cache.
+type authoredDAO struct {
+	*DAO
+	dao databaseModel.DAO
+}
+
+func (a *authoredDAO) Create(entity modelAPI.Entity) error {
+	return a.create(a.dao, entity)
+}
+
+func (a *authoredDAO) Upsert(entity modelAPI.Entity) error {
+	return a.upsert(a.dao, entity)
+}
+
+func (a *authoredDAO) Update(entity modelAPI.Entity, expectedVersion uint64) error {
+	return a.update(a.dao, entity, expectedVersion)
+}
+
+func (a *authoredDAO) Delete(kind modelV1.Kind, metadata modelAPI.Metadata) error {
+	return a.delete(a.dao, kind, metadata)
+}
+
+func (a *authoredDAO) DeleteByQuery(query databaseModel.Query) error {
+	return a.deleteByQuery(a.dao, query)
+}
+
+func (a *authoredDAO) Transaction(f func(tx databaseModel.DAO) error) error {
+	return a.transaction(a.dao, f)
+}
+
+// changeSet contains what has been written within a transaction.
+type changeSet struct {
+	mutex sync.Mutex
+	keys  []string
+	// all is true when the resources written cannot be known, like when they are deleted by a query.
+	all bool
+}
+
+func (c *changeSet) add(all bool, keys ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.all = c.all || all
+	c.keys = append(c.keys, keys...)
+}
+
+// recorder is the DAO of a transaction recording what is written through it.
+type recorder struct {
+	databaseModel.DAO
+	changes *changeSet
+}
+
+func (r *recorder) Create(entity modelAPI.Entity) error {
+	r.changes.add(false, entityKey(entity))
+	return r.DAO.Create(entity)
+}
+
+func (r *recorder) Upsert(entity modelAPI.Entity) error {
+	r.changes.add(false, entityKey(entity))
+	return r.DAO.Upsert(entity)
+}
+
+func (r *recorder) Update(entity modelAPI.Entity, expectedVersion uint64) error {
+	r.changes.add(false, entityKey(entity))
+	return r.DAO.Update(entity, expectedVersion)
+}
+
+func (r *recorder) Delete(kind modelV1.Kind, metadata modelAPI.Metadata) error {
+	r.changes.add(false, getKey(kind, metadata))
+	return r.DAO.Delete(kind, metadata)
+}
+
+func (r *recorder) DeleteByQuery(query databaseModel.Query) error {
+	r.changes.add(true)
+	return r.DAO.DeleteByQuery(query)
+}
+
+func (r *recorder) Transaction(f func(tx databaseModel.DAO) error) error {
+	return r.DAO.Transaction(func(tx databaseModel.DAO) error {
+		return f(&recorder{DAO: tx, changes: r.changes})
+	})
+}
+
+func entityKey(entity modelAPI.Entity) string {
+	return getKey(modelV1.Kind(entity.GetKind()), entity.GetMetadata())
+}
+
+// getKey returns the key of the entry containing the resource. Only the fields identifying the resource are used,
+// so the key doesn't depend on the other fields of the metadata given.
+func getKey(kind modelV1.Kind, metadata modelAPI.Metadata) string {
+	switch m := metadata.(type) {
+	case *modelV1.RevisionMetadata:
+		return path.Join(operationGet, string(kind), m.Project, m.Name, strconv.FormatUint(m.Version, 10))
+	case *modelV1.ProjectMetadata:
+		return path.Join(operationGet, string(kind), m.Project, m.Name)
+	default:
+		return path.Join(operationGet, string(kind), metadata.GetName())
+	}
+}
+
+// queryKey returns the key of the entry containing the result of the query. The parameters of a query are all exported,
+// so the query is encoded in JSON.
+func queryKey(query databaseModel.Query) (string, error) {
+	data, err := json.Marshal(query)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%T/%s", operationQuery, query, data), nil
+}
+
+// eventKey returns the key of the entry containing the resource of the event.
+func eventKey(event *databaseModel.Event) (string, error) {
+	entity, err := modelV1.GetInternalStruct(event.Kind)
+	if err != nil {
+		return "", err
+	}
+	if unmarshalErr := json.Unmarshal(event.Document, entity); unmarshalErr != nil {
+		return "", unmarshalErr
+	}
+	return entityKey(entity), nil
+}
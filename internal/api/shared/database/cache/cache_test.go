@@ -0,0 +1,189 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasecache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/perses/perses/internal/api/config"
+	"github.com/perses/perses/internal/api/interface/v1/project"
+	databaseFile "github.com/perses/perses/internal/api/shared/database/file"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingDAO counts the reads reaching the database.
+type countingDAO struct {
+	databaseModel.DAO
+	gets    atomic.Int32
+	queries atomic.Int32
+}
+
+func (c *countingDAO) Get(kind modelV1.Kind, metadata modelAPI.Metadata, entity modelAPI.Entity) error {
+	c.gets.Add(1)
+	return c.DAO.Get(kind, metadata, entity)
+}
+
+func (c *countingDAO) Query(query databaseModel.Query, slice interface{}) error {
+	c.queries.Add(1)
+	return c.DAO.Query(query, slice)
+}
+
+func newDAO(t *testing.T, maxEntries int) (*DAO, *countingDAO) {
+	db := &countingDAO{DAO: &databaseFile.DAO{Folder: t.TempDir(), Extension: config.JSONExtension}}
+	dao := New(db, config.Cache{Enable: true, TTL: model.Duration(time.Minute), MaxEntries: maxEntries})
+	if err := dao.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = dao.Close()
+	})
+	return dao, db
+}
+
+func newProject(name string, version uint64) *modelV1.Project {
+	return &modelV1.Project{Kind: modelV1.KindProject, Metadata: modelV1.Metadata{Name: name, Version: version}}
+}
+
+func getProject(t *testing.T, dao databaseModel.DAO, name string) *modelV1.Project {
+	entity := &modelV1.Project{}
+	if err := dao.Get(modelV1.KindProject, modelV1.NewMetadata(name), entity); err != nil {
+		t.Fatal(err)
+	}
+	return entity
+}
+
+func listProjects(t *testing.T, dao databaseModel.DAO, q *project.Query) []*modelV1.Project {
+	var list []*modelV1.Project
+	if err := dao.Query(q, &list); err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
+
+func TestGet(t *testing.T) {
+	dao, db := newDAO(t, 10)
+	if err := dao.Create(newProject("perses", 1)); err != nil {
+		t.Fatal(err)
+	}
+	first := getProject(t, dao, "perses")
+	// the entity returned is not shared with the cache.
+	first.Metadata.Version = 42
+	assert.Equal(t, uint64(1), getProject(t, dao, "perses").Metadata.Version)
+	assert.Equal(t, int32(1), db.gets.Load())
+
+	// a write invalidates the resource.
+	if err := dao.Update(newProject("perses", 2), 1); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(2), getProject(t, dao, "perses").Metadata.Version)
+	assert.Equal(t, int32(2), db.gets.Load())
+
+	// a resource not found is not cached.
+	err := dao.Get(modelV1.KindProject, modelV1.NewMetadata("unknown"), &modelV1.Project{})
+	assert.True(t, databaseModel.IsKeyNotFound(err))
+}
+
+func TestQuery(t *testing.T) {
+	dao, db := newDAO(t, 10)
+	if err := dao.Create(newProject("perses", 1)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, listProjects(t, dao, &project.Query{}), 1)
+	assert.Len(t, listProjects(t, dao, &project.Query{}), 1)
+	assert.Len(t, listProjects(t, dao, &project.Query{NamePrefix: "other"}), 0)
+	assert.Equal(t, int32(2), db.queries.Load())
+
+	// any write invalidates the results of the queries.
+	if err := dao.Create(newProject("other", 1)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, listProjects(t, dao, &project.Query{}), 2)
+	assert.Len(t, listProjects(t, dao, &project.Query{NamePrefix: "other"}), 1)
+	assert.Equal(t, int32(4), db.queries.Load())
+
+	// the paginated queries are not cached.
+	q := &project.Query{Pagination: databaseModel.Pagination{Limit: 1}}
+	assert.Len(t, listProjects(t, dao, q), 1)
+	assert.NotEmpty(t, q.Next())
+	listProjects(t, dao, q)
+	assert.Equal(t, int32(6), db.queries.Load())
+}
+
+func TestTransaction(t *testing.T) {
+	dao, _ := newDAO(t, 10)
+	if err := dao.Create(newProject("perses", 1)); err != nil {
+		t.Fatal(err)
+	}
+	getProject(t, dao, "perses")
+	if err := dao.Transaction(func(tx databaseModel.DAO) error {
+		return tx.Upsert(newProject("perses", 2))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(2), getProject(t, dao, "perses").Metadata.Version)
+}
+
+func TestLimits(t *testing.T) {
+	dao, db := newDAO(t, 2)
+	now := time.Now()
+	dao.now = func() time.Time { return now }
+	for _, name := range []string{"a", "b", "c"} {
+		if err := dao.Create(newProject(name, 1)); err != nil {
+			t.Fatal(err)
+		}
+		getProject(t, dao, name)
+	}
+	assert.Equal(t, int32(3), db.gets.Load())
+	// "a" is the least recently used resource, so it has been removed to keep only 2 entries.
+	getProject(t, dao, "c")
+	getProject(t, dao, "a")
+	assert.Equal(t, int32(4), db.gets.Load())
+
+	// once the TTL is over, the resource is read again.
+	now = now.Add(2 * time.Minute)
+	getProject(t, dao, "a")
+	assert.Equal(t, int32(5), db.gets.Load())
+}
+
+func TestWatch(t *testing.T) {
+	dao, _ := newDAO(t, 10)
+	if err := dao.Create(newProject("perses", 1)); err != nil {
+		t.Fatal(err)
+	}
+	getProject(t, dao, "perses")
+	// a write that doesn't go through the cache, like the ones made by another instance sharing the database.
+	if err := dao.dao.Upsert(newProject("perses", 2)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Eventually(t, func() bool {
+		return getProject(t, dao, "perses").Metadata.Version == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestStaleRead(t *testing.T) {
+	dao, _ := newDAO(t, 10)
+	_, generation, ok := dao.load("get/Project/perses")
+	assert.False(t, ok)
+	// the resource is modified while it is read from the database, what has been read must not be cached.
+	dao.invalidate(false, "get/Project/perses")
+	dao.store("get/Project/perses", false, generation, newProject("perses", 1))
+	_, _, ok = dao.load("get/Project/perses")
+	assert.False(t, ok)
+}
@@ -16,21 +16,31 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
+	"github.com/huandu/go-sqlbuilder"
+	_ "github.com/lib/pq" // register the PostgreSQL driver
 	"github.com/perses/perses/internal/api/config"
 	databaseFile "github.com/perses/perses/internal/api/shared/database/file"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
 	databaseSQL "github.com/perses/perses/internal/api/shared/database/sql"
 	promConfig "github.com/prometheus/common/config"
 	"github.com/sirupsen/logrus"
+	_ "modernc.org/sqlite" // register the SQLite driver
 )
 
 func New(conf config.Database) (databaseModel.DAO, error) {
 	if conf.File != nil {
 		return &databaseFile.DAO{
-			Folder:    conf.File.Folder,
-			Extension: conf.File.Extension,
+			Folder:         conf.File.Folder,
+			Extension:      conf.File.Extension,
+			Git:            conf.File.Git,
+			LockFile:       conf.File.LockFile,
+			IntegrityCheck: conf.File.IntegrityCheck,
 		}, nil
 	} else if conf.SQL != nil {
 		c := conf.SQL
@@ -81,9 +91,86 @@ func New(conf config.Database) (databaseModel.DAO, error) {
 			return nil, err
 		}
 		return &databaseSQL.DAO{
-			DB:         db,
-			SchemaName: c.DBName,
+			DB:              db,
+			SchemaName:      c.DBName,
+			Flavor:          sqlbuilder.MySQL,
+			PollInterval:    time.Duration(conf.Watch.PollInterval),
+			ChangeRetention: time.Duration(conf.Watch.Retention),
+		}, nil
+	} else if conf.Postgres != nil {
+		db, err := sql.Open("postgres", buildPostgresDSN(conf.Postgres))
+		if err != nil {
+			return nil, err
+		}
+		return &databaseSQL.DAO{
+			DB:              db,
+			SchemaName:      conf.Postgres.Schema,
+			Flavor:          sqlbuilder.PostgreSQL,
+			PollInterval:    time.Duration(conf.Watch.PollInterval),
+			ChangeRetention: time.Duration(conf.Watch.Retention),
+		}, nil
+	} else if conf.SQLite != nil {
+		if err := os.MkdirAll(filepath.Dir(conf.SQLite.Path), 0755); err != nil {
+			return nil, err
+		}
+		db, err := sql.Open("sqlite", buildSQLiteDSN(conf.SQLite))
+		if err != nil {
+			return nil, err
+		}
+		// SQLite only supports one writer at a time.
+		// Using a single connection avoids getting "database is locked" errors when concurrent requests are writing.
+		db.SetMaxOpenConns(1)
+		return &databaseSQL.DAO{
+			DB:              db,
+			SchemaName:      "main",
+			Flavor:          sqlbuilder.SQLite,
+			PollInterval:    time.Duration(conf.Watch.PollInterval),
+			ChangeRetention: time.Duration(conf.Watch.Retention),
 		}, nil
 	}
 	return nil, fmt.Errorf("no dao defined")
 }
+
+func buildPostgresDSN(c *config.Postgres) string {
+	dsn := &url.URL{
+		Scheme: "postgres",
+		Host:   string(c.Addr),
+		Path:   c.DBName,
+	}
+	if len(c.User) > 0 {
+		if len(c.Password) > 0 {
+			dsn.User = url.UserPassword(string(c.User), string(c.Password))
+		} else {
+			dsn.User = url.User(string(c.User))
+		}
+	}
+	params := url.Values{}
+	params.Set("sslmode", c.SSLMode)
+	if c.TLSConfig != nil {
+		if len(c.TLSConfig.CAFile) > 0 {
+			params.Set("sslrootcert", c.TLSConfig.CAFile)
+		}
+		if len(c.TLSConfig.CertFile) > 0 {
+			params.Set("sslcert", c.TLSConfig.CertFile)
+		}
+		if len(c.TLSConfig.KeyFile) > 0 {
+			params.Set("sslkey", c.TLSConfig.KeyFile)
+		}
+	}
+	if c.Timeout > 0 {
+		params.Set("connect_timeout", fmt.Sprintf("%d", int(c.Timeout.Seconds())))
+	}
+	if len(c.ApplicationName) > 0 {
+		params.Set("application_name", c.ApplicationName)
+	}
+	dsn.RawQuery = params.Encode()
+	return dsn.String()
+}
+
+func buildSQLiteDSN(c *config.SQLite) string {
+	params := url.Values{}
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", c.BusyTimeout.Milliseconds()))
+	// WAL allows reading the database while it is being written.
+	params.Add("_pragma", "journal_mode(WAL)")
+	return fmt.Sprintf("file:%s?%s", c.Path, params.Encode())
+}
@@ -15,23 +15,29 @@ package databasefile
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/perses/perses/internal/api/config"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
 	modelAPI "github.com/perses/perses/pkg/model/api"
 	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
 	"gopkg.in/yaml.v2"
 )
 
 func generateID(kind modelV1.Kind, metadata modelAPI.Metadata) (string, error) {
 	switch m := metadata.(type) {
+	case *modelV1.RevisionMetadata:
+		return path.Join(modelV1.PluralKindMap[kind], m.Project, m.Name, strconv.FormatUint(m.Version, 10)), nil
 	case *modelV1.ProjectMetadata:
 		return path.Join(modelV1.PluralKindMap[kind], m.Project, m.Name), nil
 	case *modelV1.Metadata:
@@ -40,46 +46,163 @@ func generateID(kind modelV1.Kind, metadata modelAPI.Metadata) (string, error) {
 	return "", fmt.Errorf("metadata %T not managed", metadata)
 }
 
+// versionedDocument is used to decode only the version of a document stored.
+type versionedDocument struct {
+	Metadata struct {
+		Version uint64 `json:"version" yaml:"version"`
+	} `json:"metadata" yaml:"metadata"`
+}
+
 type DAO struct {
 	databaseModel.DAO
 	Folder    string
 	Extension config.FileExtension
+	// mutex is held for reading by the writes of a single document, and for writing by the writes of several documents at once.
+	mutex sync.RWMutex
+	// keys ensures no document is written between the time its version is checked and the time it is replaced.
+	keys keyLocker
+	// notifier sends the changes to the watchers.
+	notifier databaseModel.Notifier
+	// Git turns the folder into a git repository, where every change is committed. It is disabled when nil.
+	Git        *config.FileGit
+	repository *repository
+	// LockFile makes every write take an advisory lock on a file of the folder, so several processes can share it.
+	LockFile bool
+	// IntegrityCheck is what is done with the documents that cannot be decoded when the database is initialized.
+	IntegrityCheck config.IntegrityCheck
 }
 
 func (d *DAO) Init() error {
-	return nil
+	if err := d.recoverTransactions(); err != nil {
+		return err
+	}
+	if _, err := d.checkIntegrity(); err != nil {
+		return err
+	}
+	if d.Git == nil {
+		return nil
+	}
+	d.repository = newRepository(d.Folder, *d.Git)
+	return d.repository.init()
 }
 
 func (d *DAO) Close() error {
+	if d.repository != nil {
+		d.repository.close()
+		d.repository = nil
+	}
 	return nil
 }
 
 func (d *DAO) Create(entity modelAPI.Entity) error {
+	return d.createAs("", entity)
+}
+
+func (d *DAO) createAs(author string, entity modelAPI.Entity) error {
 	key, generateIDErr := generateID(modelV1.Kind(entity.GetKind()), entity.GetMetadata())
 	if generateIDErr != nil {
 		return generateIDErr
 	}
-	filePath := d.buildPath(key)
-	if _, err := os.Stat(filePath); err == nil {
-		// The file exists, so we should return a conflict error.
-		return &databaseModel.Error{Key: key, Code: databaseModel.ErrorCodeConflict}
+	unlock, lockErr := d.lockKey(key)
+	if lockErr != nil {
+		return lockErr
 	}
-	return d.upsert(key, entity)
+	defer unlock()
+	data, err := d.marshal(entity)
+	if err != nil {
+		return err
+	}
+	if writeErr := writeFile(d.buildPath(key), data, true); writeErr != nil {
+		if errors.Is(writeErr, fs.ErrExist) {
+			// The file exists, so we should return a conflict error.
+			return &databaseModel.Error{Key: key, Code: databaseModel.ErrorCodeConflict}
+		}
+		return writeErr
+	}
+	d.commit(author, []string{d.buildPath(key)}, changeDescription(modelV1.EventTypeAdded, key))
+	d.publish(d.entityEvent(modelV1.EventTypeAdded, entity))
+	return nil
 }
 func (d *DAO) Upsert(entity modelAPI.Entity) error {
+	return d.upsertAs("", entity)
+}
+
+func (d *DAO) upsertAs(author string, entity modelAPI.Entity) error {
+	key, generateIDErr := generateID(modelV1.Kind(entity.GetKind()), entity.GetMetadata())
+	if generateIDErr != nil {
+		return generateIDErr
+	}
+	unlock, lockErr := d.lockKey(key)
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+	eventType := modelV1.EventTypeModified
+	if _, err := os.Stat(d.buildPath(key)); os.IsNotExist(err) {
+		eventType = modelV1.EventTypeAdded
+	}
+	if err := d.upsert(key, entity); err != nil {
+		return err
+	}
+	d.commit(author, []string{d.buildPath(key)}, changeDescription(eventType, key))
+	d.publish(d.entityEvent(eventType, entity))
+	return nil
+}
+func (d *DAO) Update(entity modelAPI.Entity, expectedVersion uint64) error {
+	return d.updateAs("", entity, expectedVersion)
+}
+
+func (d *DAO) updateAs(author string, entity modelAPI.Entity, expectedVersion uint64) error {
 	key, generateIDErr := generateID(modelV1.Kind(entity.GetKind()), entity.GetMetadata())
 	if generateIDErr != nil {
 		return generateIDErr
 	}
-	return d.upsert(key, entity)
+	unlock, lockErr := d.lockKey(key)
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+	if err := d.checkVersion(d, key, expectedVersion); err != nil {
+		return err
+	}
+	if err := d.upsert(key, entity); err != nil {
+		return err
+	}
+	d.commit(author, []string{d.buildPath(key)}, changeDescription(modelV1.EventTypeModified, key))
+	d.publish(d.entityEvent(modelV1.EventTypeModified, entity))
+	return nil
+}
+
+// fileSource lists and reads the documents of the database. It is the folder of the database itself,
+// or the documents as modified by a transaction, for the reads made within it.
+type fileSource interface {
+	// list returns the documents of the folder whose name starts with the prefix. isExist tells if the folder exists on the disk.
+	list(folder string, prefix string, isExist bool) ([]string, error)
+	read(filePath string) ([]byte, error)
+}
+
+func (d *DAO) list(folder string, prefix string, isExist bool) ([]string, error) {
+	if !isExist {
+		return nil, nil
+	}
+	return d.visit(folder, prefix)
 }
+
+func (d *DAO) read(filePath string) ([]byte, error) {
+	return os.ReadFile(filePath)
+}
+
 func (d *DAO) Get(kind modelV1.Kind, metadata modelAPI.Metadata, entity modelAPI.Entity) error {
+	return d.get(d, kind, metadata, entity)
+}
+
+func (d *DAO) get(source fileSource, kind modelV1.Kind, metadata modelAPI.Metadata, entity modelAPI.Entity) error {
 	key, generateIDErr := generateID(kind, metadata)
 	if generateIDErr != nil {
 		return generateIDErr
 	}
 	filePath := d.buildPath(key)
-	data, err := os.ReadFile(filePath)
+	data, err := source.read(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return &databaseModel.Error{Key: key, Code: databaseModel.ErrorCodeNotFound}
@@ -97,7 +220,12 @@ func (d *DAO) Get(kind modelV1.Kind, metadata modelAPI.Metadata, entity modelAPI
 	}
 	return nil
 }
+
 func (d *DAO) Query(query databaseModel.Query, slice interface{}) error {
+	return d.query(d, query, slice)
+}
+
+func (d *DAO) query(source fileSource, query databaseModel.Query, slice interface{}) error {
 	typeParameter := reflect.TypeOf(slice)
 	result := reflect.ValueOf(slice)
 	// to avoid any miss usage when using this method, slice should be a pointer to a slice.
@@ -117,29 +245,29 @@ func (d *DAO) Query(query databaseModel.Query, slice interface{}) error {
 	if typeParameter.Kind() != reflect.Slice {
 		return fmt.Errorf("slice in parameter is not actually a slice but a %q", typeParameter.Kind())
 	}
+	if paginatedQuery, ok := query.(databaseModel.PaginatedQuery); ok {
+		paginatedQuery.GetPagination().ResetNext()
+	}
 	folder, prefix, isExist, err := d.buildQuery(query)
 	if err != nil {
 		return fmt.Errorf("unable to build the query: %s", err)
 	}
-	if !isExist {
-		// there is nothing to return. So let's initialize the slice just to avoid returning a nil slice
-		sliceElem = reflect.MakeSlice(typeParameter, 0, 0)
-		//and finally reset the element of the slice to ensure we didn't disconnect the link between the pointer to the slice and the actual slice
-		result.Elem().Set(sliceElem)
-		return nil
-	}
-	// so now we have the proper folder to looking for and potentially a filter to use
+	// so now we have the proper folder to looking for and potentially a filter to use.
+	// When there is nothing to return, the slice is initialized anyway to avoid returning a nil slice.
 	var files []string
-	if files, err = d.visit(folder, prefix); err != nil {
+	if files, err = source.list(folder, prefix, isExist); err != nil {
 		return err
 	}
-	if len(files) <= 0 {
-		// in case the result is empty, let's initialize the slice just to avoid returning a nil slice
-		sliceElem = reflect.MakeSlice(typeParameter, 0, 0)
+	var selector common.LabelSelector
+	if selectionQuery, ok := query.(databaseModel.LabelSelectionQuery); ok {
+		if selector, err = selectionQuery.GetLabelSelection().Selector(); err != nil {
+			return err
+		}
 	}
+	var entities []modelAPI.Entity
 	for _, file := range files {
 		// now read all file and append them to the final result
-		data, readErr := os.ReadFile(file)
+		data, readErr := source.read(file)
 		if readErr != nil {
 			return readErr
 		}
@@ -156,12 +284,29 @@ func (d *DAO) Query(query databaseModel.Query, slice interface{}) error {
 		if unmarshalErr := d.unmarshal(data, obj); unmarshalErr != nil {
 			return unmarshalErr
 		}
+		entity, isEntity := obj.(modelAPI.Entity)
+		if !isEntity {
+			return fmt.Errorf("%T is not an entity", obj)
+		}
+		if !selector.Matches(databaseModel.GetLabels(entity.GetMetadata())) {
+			continue
+		}
+		entities = append(entities, entity)
+	}
+	if paginatedQuery, ok := query.(databaseModel.PaginatedQuery); ok {
+		if entities, err = paginate(paginatedQuery.GetPagination(), entities); err != nil {
+			return err
+		}
+	}
+	sliceElem = reflect.MakeSlice(typeParameter, 0, len(entities))
+	for _, entity := range entities {
+		value := reflect.ValueOf(entity)
 		if typeParameter.Elem().Kind() != reflect.Ptr {
 			// In case the type of the slice element is not a pointer,
 			// we should return the value of the pointer created in the previous step.
-			sliceElem.Set(reflect.Append(sliceElem, value.Elem()))
+			sliceElem = reflect.Append(sliceElem, value.Elem())
 		} else {
-			sliceElem.Set(reflect.Append(sliceElem, value))
+			sliceElem = reflect.Append(sliceElem, value)
 		}
 	}
 	// at the end reset the element of the slice to ensure we didn't disconnect the link between the pointer to the slice and the actual slice
@@ -169,11 +314,21 @@ func (d *DAO) Query(query databaseModel.Query, slice interface{}) error {
 	return nil
 }
 func (d *DAO) Delete(kind modelV1.Kind, metadata modelAPI.Metadata) error {
+	return d.deleteAs("", kind, metadata)
+}
+
+func (d *DAO) deleteAs(author string, kind modelV1.Kind, metadata modelAPI.Metadata) error {
 	key, generateIDErr := generateID(kind, metadata)
 	if generateIDErr != nil {
 		return generateIDErr
 	}
+	unlock, lockErr := d.lockKey(key)
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
 	filePath := d.buildPath(key)
+	events := d.fileEvents(modelV1.EventTypeDeleted, []string{filePath})
 	err := os.Remove(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -181,10 +336,16 @@ func (d *DAO) Delete(kind modelV1.Kind, metadata modelAPI.Metadata) error {
 		}
 		return err
 	}
+	d.commit(author, []string{filePath}, changeDescription(modelV1.EventTypeDeleted, key))
+	d.publish(events...)
 	return nil
 }
 
 func (d *DAO) DeleteByQuery(query databaseModel.Query) error {
+	return d.deleteByQueryAs("", query)
+}
+
+func (d *DAO) deleteByQueryAs(author string, query databaseModel.Query) error {
 	folder, prefix, isExist, err := d.buildQuery(query)
 	if err != nil {
 		return fmt.Errorf("unable to build the query: %s", err)
@@ -192,14 +353,25 @@ func (d *DAO) DeleteByQuery(query databaseModel.Query) error {
 	if !isExist {
 		return nil
 	}
-	if len(prefix) == 0 {
-		return os.RemoveAll(folder)
+	unlock, lockErr := d.lockAll()
+	if lockErr != nil {
+		return lockErr
 	}
-	// in case there is a prefix file name we need to delete only the files that is matching the prefix and not the folder entirely
+	defer unlock()
 	var files []string
 	if files, err = d.visit(folder, prefix); err != nil {
 		return err
 	}
+	events := d.fileEvents(modelV1.EventTypeDeleted, files)
+	if len(prefix) == 0 {
+		if removeErr := os.RemoveAll(folder); removeErr != nil {
+			return removeErr
+		}
+		d.commit(author, files, d.fileChanges(modelV1.EventTypeDeleted, files)...)
+		d.publish(events...)
+		return nil
+	}
+	// in case there is a prefix file name we need to delete only the files that is matching the prefix and not the folder entirely
 	if len(files) <= 0 {
 		return nil
 	}
@@ -208,6 +380,8 @@ func (d *DAO) DeleteByQuery(query databaseModel.Query) error {
 			return removeErr
 		}
 	}
+	d.commit(author, files, d.fileChanges(modelV1.EventTypeDeleted, files)...)
+	d.publish(events...)
 	return nil
 }
 
@@ -216,19 +390,99 @@ func (d *DAO) HealthCheck() bool {
 }
 
 func (d *DAO) upsert(key string, entity modelAPI.Entity) error {
-	filePath := d.buildPath(key)
-	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+	data, err := d.marshal(entity)
+	if err != nil {
 		return err
 	}
-	data, err := d.marshal(entity)
+	return writeFile(d.buildPath(key), data, false)
+}
+
+// writeFile writes the data in a temporary file of the same folder that is then renamed, so a document is never read partially written,
+// even when the process stops in the middle of the write. The name of the temporary file starts with a dot and doesn't have the extension of the documents,
+// so it is ignored by the queries.
+// If exclusive is true, it fails with an error wrapping fs.ErrExist when the file already exists.
+func writeFile(filePath string, data []byte, exclusive bool) error {
+	folder := filepath.Dir(filePath)
+	if err := os.MkdirAll(folder, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(folder, fmt.Sprintf(".%s%s*", filepath.Base(filePath), tmpFileSuffix))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+	if _, writeErr := tmp.Write(data); writeErr != nil {
+		_ = tmp.Close()
+		return writeErr
+	}
+	if syncErr := tmp.Sync(); syncErr != nil {
+		_ = tmp.Close()
+		return syncErr
+	}
+	if closeErr := tmp.Close(); closeErr != nil {
+		return closeErr
+	}
+	if exclusive {
+		err = linkFile(tmp.Name(), filePath)
+	} else {
+		err = os.Rename(tmp.Name(), filePath)
+	}
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filePath, data, 0600)
+	syncFolder(folder)
+	return nil
+}
+
+// linkFile creates dst as a hard link of src. Unlike a rename, it fails when dst already exists, even if another process creates it at the same time.
+// On a filesystem not supporting the hard links, it falls back to a rename, and the existence of dst is only checked before.
+func linkFile(src string, dst string) error {
+	err := os.Link(src, dst)
+	if err == nil || errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	if _, statErr := os.Stat(dst); statErr == nil {
+		return &fs.PathError{Op: "link", Path: dst, Err: fs.ErrExist}
+	}
+	return os.Rename(src, dst)
+}
+
+// syncFolder flushes the entries of the folder, so a renamed file is still there after a crash.
+// It is not supported by every platform, so the errors are ignored.
+func syncFolder(folder string) {
+	f, err := os.Open(folder)
+	if err != nil {
+		return
+	}
+	_ = f.Sync()
+	_ = f.Close()
+}
+
+// checkVersion verifies the document stored with the given key exists and has the expected version.
+func (d *DAO) checkVersion(source fileSource, key string, expectedVersion uint64) error {
+	data, err := source.read(d.buildPath(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &databaseModel.Error{Key: key, Code: databaseModel.ErrorCodeNotFound}
+		}
+		return err
+	}
+	stored := &versionedDocument{}
+	if unmarshalErr := d.unmarshal(data, stored); unmarshalErr != nil {
+		return unmarshalErr
+	}
+	if stored.Metadata.Version != expectedVersion {
+		return &databaseModel.Error{Key: key, Code: databaseModel.ErrorCodeConflict}
+	}
+	return nil
 }
 
 func (d *DAO) buildPath(key string) string {
-	return path.Join(d.Folder, fmt.Sprintf("%s.%s", key, d.Extension))
+	return path.Join(d.Folder, d.fileName(key))
+}
+
+func (d *DAO) fileName(key string) string {
+	return fmt.Sprintf("%s.%s", key, d.Extension)
 }
 
 func (d *DAO) unmarshal(data []byte, entity interface{}) error {
@@ -245,6 +499,14 @@ func (d *DAO) marshal(entity interface{}) ([]byte, error) {
 	return yaml.Marshal(entity)
 }
 
+// isInFolder tells whether the document is one of the documents of the folder returned by visit.
+func (d *DAO) isInFolder(filePath string, folder string, prefix string) bool {
+	if !strings.HasPrefix(filePath, folder+"/") || filepath.Ext(filePath) != fmt.Sprintf(".%s", d.Extension) {
+		return false
+	}
+	return len(prefix) == 0 || strings.HasPrefix(path.Base(filePath), prefix)
+}
+
 func (d *DAO) visit(rootPath string, prefix string) ([]string, error) {
 	var result []string
 	err := filepath.Walk(rootPath, func(path string, info fs.FileInfo, err error) error {
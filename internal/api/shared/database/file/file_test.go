@@ -14,8 +14,11 @@
 package databasefile
 
 import (
+	"context"
+	"encoding/json"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/perses/perses/internal/api/config"
 	"github.com/perses/perses/internal/api/interface/v1/project"
@@ -63,6 +66,26 @@ func TestDAO_Upsert(t *testing.T) {
 	clear(t)
 }
 
+func TestDAO_Update(t *testing.T) {
+	d := newDAO()
+	projectEntity := &modelV1.Project{
+		Kind: modelV1.KindProject,
+		Metadata: modelV1.Metadata{
+			Name: "perses",
+		},
+	}
+	assert.True(t, databaseModel.IsKeyNotFound(d.Update(projectEntity, 0)))
+	assert.NoError(t, d.Create(projectEntity))
+	projectEntity.Metadata.Version = 1
+	assert.NoError(t, d.Update(projectEntity, 0))
+	// the version stored is now 1, so an update based on the version 0 must be rejected
+	projectEntity.Metadata.Version = 1
+	assert.True(t, databaseModel.IsKeyConflict(d.Update(projectEntity, 0)))
+	projectEntity.Metadata.Version = 2
+	assert.NoError(t, d.Update(projectEntity, 1))
+	clear(t)
+}
+
 func TestDAO_Get(t *testing.T) {
 	d := newDAO()
 	projectEntity := &modelV1.Project{
@@ -96,6 +119,33 @@ func TestDAO_Query(t *testing.T) {
 	clear(t)
 }
 
+func TestDAO_QueryLabelSelector(t *testing.T) {
+	d := newDAO()
+	for name, team := range map[string]string{"perses": "sre", "amadeus": "dev", "chronosphere": ""} {
+		projectEntity := &modelV1.Project{
+			Kind: modelV1.KindProject,
+			Metadata: modelV1.Metadata{
+				Name: name,
+			},
+		}
+		if len(team) > 0 {
+			projectEntity.Metadata.Labels = map[string]string{"team": team}
+		}
+		assert.NoError(t, d.Create(projectEntity))
+	}
+	var result []*modelV1.Project
+	assert.NoError(t, d.Query(&project.Query{LabelSelection: databaseModel.LabelSelection{LabelSelector: "team=sre"}}, &result))
+	assert.Len(t, result, 1)
+	assert.Equal(t, "perses", result[0].Metadata.Name)
+	var notDev []*modelV1.Project
+	assert.NoError(t, d.Query(&project.Query{LabelSelection: databaseModel.LabelSelection{LabelSelector: "team!=dev"}, Pagination: databaseModel.Pagination{Sort: databaseModel.SortByName}}, &notDev))
+	assert.Len(t, notDev, 2)
+	assert.Equal(t, "chronosphere", notDev[0].Metadata.Name)
+	assert.Equal(t, "perses", notDev[1].Metadata.Name)
+	assert.Error(t, d.Query(&project.Query{LabelSelection: databaseModel.LabelSelection{LabelSelector: "team in"}}, &result))
+	clear(t)
+}
+
 func TestDAO_Delete(t *testing.T) {
 	d := newDAO()
 	projectEntity := &modelV1.Project{
@@ -110,3 +160,49 @@ func TestDAO_Delete(t *testing.T) {
 	assert.True(t, databaseModel.IsKeyNotFound(d.Get(modelV1.KindProject, projectEntity.GetMetadata(), result)))
 	clear(t)
 }
+
+// receive returns the next event, or fails if none is received.
+func receive(t *testing.T, events <-chan *databaseModel.Event) *databaseModel.Event {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return nil
+	}
+}
+
+func TestDAO_Watch(t *testing.T) {
+	d := newDAO()
+	// the documents deleted are converted from YAML to JSON to be sent.
+	d.Extension = config.YAMLExtension
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := d.Watch(ctx, modelV1.KindProject)
+	assert.NoError(t, err)
+	projectEntity := &modelV1.Project{
+		Kind: modelV1.KindProject,
+		Metadata: modelV1.Metadata{
+			Name:   "perses",
+			Labels: map[string]string{"team": "sre"},
+		},
+	}
+	assert.NoError(t, d.Create(projectEntity))
+	assert.Equal(t, modelV1.EventTypeAdded, receive(t, events).Type)
+	assert.NoError(t, d.Upsert(projectEntity))
+	assert.Equal(t, modelV1.EventTypeModified, receive(t, events).Type)
+	assert.NoError(t, d.Update(projectEntity, 0))
+	assert.Equal(t, modelV1.EventTypeModified, receive(t, events).Type)
+	assert.NoError(t, d.Delete(modelV1.KindProject, projectEntity.GetMetadata()))
+	event := receive(t, events)
+	assert.Equal(t, modelV1.EventTypeDeleted, event.Type)
+	deleted := &modelV1.Project{}
+	assert.NoError(t, json.Unmarshal(event.Document, deleted))
+	assert.Equal(t, projectEntity.Metadata.Labels, deleted.Metadata.Labels)
+
+	assert.NoError(t, d.Upsert(projectEntity))
+	assert.Equal(t, modelV1.EventTypeAdded, receive(t, events).Type)
+	assert.NoError(t, d.DeleteByQuery(&project.Query{}))
+	assert.Equal(t, modelV1.EventTypeDeleted, receive(t, events).Type)
+	clear(t)
+}
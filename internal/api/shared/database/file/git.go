@@ -0,0 +1,287 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasefile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/perses/perses/internal/api/config"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// repository commits the changes of the documents in the git repository of the database, and pushes them to the remote if there is one.
+// The commands are run with the git executable.
+type repository struct {
+	folder string
+	conf   config.FileGit
+	// push is signaled when there are commits to push. Its capacity is 1, so the pushes are coalesced.
+	push chan struct{}
+	done chan struct{}
+}
+
+func newRepository(folder string, conf config.FileGit) *repository {
+	return &repository{
+		folder: folder,
+		conf:   conf,
+		push:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+// init creates the repository if it doesn't exist, and commits the documents that are not committed yet.
+func (r *repository) init() error {
+	if err := os.MkdirAll(r.folder, 0700); err != nil {
+		return err
+	}
+	if _, err := os.Stat(path.Join(r.folder, ".git")); os.IsNotExist(err) {
+		if initErr := r.run("init", "--quiet", "--initial-branch", r.conf.Branch); initErr != nil {
+			return initErr
+		}
+	}
+	// the staging folder of the transactions, the quarantine folder and the lock file must not be committed.
+	excludeFile := path.Join(r.folder, ".git", "info", "exclude")
+	exclude, err := os.ReadFile(excludeFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	isExcludeModified := false
+	for _, pattern := range []string{"/" + transactionFolder + "/", "/" + quarantineFolder + "/", "/" + lockFileName} {
+		if !bytes.Contains(exclude, []byte(pattern+"\n")) {
+			exclude = append(exclude, []byte(fmt.Sprintf("\n%s\n", pattern))...)
+			isExcludeModified = true
+		}
+	}
+	if isExcludeModified {
+		if mkdirErr := os.MkdirAll(filepath.Dir(excludeFile), 0700); mkdirErr != nil {
+			return mkdirErr
+		}
+		if writeErr := os.WriteFile(excludeFile, exclude, 0600); writeErr != nil {
+			return writeErr
+		}
+	}
+	// the documents modified while Perses wasn't running are committed on their own, as they are not attributed to anyone.
+	if addErr := r.run("add", "--all"); addErr != nil {
+		return addErr
+	}
+	if commitErr := r.commitStaged("", []string{"Commit the documents modified outside of Perses"}); commitErr != nil {
+		return commitErr
+	}
+	go r.pushLoop()
+	return nil
+}
+
+// close waits for the commits to be pushed.
+func (r *repository) close() {
+	close(r.push)
+	<-r.done
+}
+
+// commit stages the files, which are the documents written or removed, and commits them along with what is already staged.
+// The other changes of the folder, like the ones made outside of Perses, are not committed. The message is built from the description
+// of the changes, one per line. The changes are attributed to author, or to the identity of the configuration when author is empty.
+// Nothing is committed if nothing changed.
+func (r *repository) commit(author string, files []string, changes []string) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	var written, removed []string
+	for _, file := range files {
+		relativePath, err := filepath.Rel(r.folder, file)
+		if err != nil {
+			return err
+		}
+		if _, statErr := os.Stat(file); statErr == nil {
+			written = append(written, relativePath)
+		} else {
+			removed = append(removed, relativePath)
+		}
+	}
+	if len(written) > 0 {
+		if err := r.runWithPaths(written, "add"); err != nil {
+			return err
+		}
+	}
+	if len(removed) > 0 {
+		// a document created and then removed before being committed is not in the index, it is ignored.
+		if err := r.runWithPaths(removed, "rm", "--cached", "--quiet", "--ignore-unmatch"); err != nil {
+			return err
+		}
+	}
+	return r.commitStaged(author, changes)
+}
+
+// commitStaged commits what is staged, if anything.
+func (r *repository) commitStaged(author string, changes []string) error {
+	if err := r.run("diff", "--cached", "--quiet"); err == nil {
+		return nil
+	}
+	message := changes[0]
+	if len(changes) > 1 {
+		message = fmt.Sprintf("Apply %d changes\n\n%s", len(changes), strings.Join(changes, "\n"))
+	}
+	cmd := r.command("commit", "--quiet", "--no-verify", "--message", message)
+	if len(author) > 0 {
+		// the users are only known by their login, so the email of the author is left empty.
+		cmd.Env = append(cmd.Env, "GIT_AUTHOR_NAME="+author, "GIT_AUTHOR_EMAIL=")
+	}
+	if err := r.exec("commit", cmd); err != nil {
+		return err
+	}
+	if len(r.conf.Remote) > 0 {
+		select {
+		case r.push <- struct{}{}:
+		default:
+			// a push is already pending, it will include this commit.
+		}
+	}
+	return nil
+}
+
+func (r *repository) pushLoop() {
+	defer close(r.done)
+	for range r.push {
+		if err := r.run("push", "--quiet", r.conf.Remote, "HEAD:refs/heads/"+r.conf.Branch); err != nil {
+			logrus.WithError(err).Errorf("unable to push the changes of the database to %q, they will be pushed with the next change", r.conf.Remote)
+		}
+	}
+}
+
+func (r *repository) run(args ...string) error {
+	return r.exec(args[0], r.command(args...))
+}
+
+// runWithPaths runs the command with the paths given on its standard input, so there is no limit on the number of paths.
+func (r *repository) runWithPaths(paths []string, args ...string) error {
+	cmd := r.command(append(args, "--pathspec-from-file=-", "--pathspec-file-nul")...)
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00"))
+	return r.exec(args[0], cmd)
+}
+
+// command returns the git command run in the folder. The identity of the configuration is the committer, and the author
+// unless the command sets another one.
+func (r *repository) command(args ...string) *exec.Cmd {
+	// the configuration of the user must not prevent committing, like when the commits must be signed.
+	cmd := exec.Command("git", append([]string{"-c", "commit.gpgsign=false"}, args...)...)
+	cmd.Dir = r.folder
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+r.conf.CommitterName,
+		"GIT_AUTHOR_EMAIL="+r.conf.CommitterEmail,
+		"GIT_COMMITTER_NAME="+r.conf.CommitterName,
+		"GIT_COMMITTER_EMAIL="+r.conf.CommitterEmail,
+		// the paths are the ones of the documents, they are not patterns.
+		"GIT_LITERAL_PATHSPECS=1",
+		"GIT_TERMINAL_PROMPT=0",
+	)
+	return cmd
+}
+
+// exec runs the command, whose name is used in the error returned.
+func (r *repository) exec(name string, cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return fmt.Errorf("git %s: %s", name, strings.TrimSpace(stderr.String()))
+		}
+		return fmt.Errorf("git %s: %w", name, err)
+	}
+	return nil
+}
+
+// changeDescription describes the change of a document for the message of the commit, like "Update dashboards/perses/demo".
+func changeDescription(eventType modelV1.EventType, key string) string {
+	verb := "Update"
+	switch eventType {
+	case modelV1.EventTypeAdded:
+		verb = "Create"
+	case modelV1.EventTypeDeleted:
+		verb = "Delete"
+	}
+	return fmt.Sprintf("%s %s", verb, key)
+}
+
+// commit commits the changes of the files in the git repository, if the database is one. The changes are already stored,
+// so a failure is only logged: what is staged will be committed with the next change, and the rest when Perses restarts.
+// It must be called with the lock held, so the changes are committed in the order they are made.
+func (d *DAO) commit(author string, files []string, changes ...string) {
+	if d.repository == nil {
+		return
+	}
+	if err := d.repository.commit(author, files, changes); err != nil {
+		logrus.WithError(err).Error("unable to commit the change of the database in git, it will be committed with the next one or when Perses restarts")
+	}
+}
+
+// WithAuthor returns a DAO whose changes are committed in git with author as the author, the identity of the configuration
+// being the committer. It returns d when the database is not a git repository.
+func (d *DAO) WithAuthor(author string) databaseModel.DAO {
+	if d.Git == nil || len(author) == 0 {
+		return d
+	}
+	return &authoredDAO{DAO: d, author: author}
+}
+
+// authoredDAO is the database whose changes are attributed to an author in git.
+type authoredDAO struct {
+	*DAO
+	author string
+}
+
+func (a *authoredDAO) Create(entity modelAPI.Entity) error {
+	return a.createAs(a.author, entity)
+}
+
+func (a *authoredDAO) Upsert(entity modelAPI.Entity) error {
+	return a.upsertAs(a.author, entity)
+}
+
+func (a *authoredDAO) Update(entity modelAPI.Entity, expectedVersion uint64) error {
+	return a.updateAs(a.author, entity, expectedVersion)
+}
+
+func (a *authoredDAO) Delete(kind modelV1.Kind, metadata modelAPI.Metadata) error {
+	return a.deleteAs(a.author, kind, metadata)
+}
+
+func (a *authoredDAO) DeleteByQuery(query databaseModel.Query) error {
+	return a.deleteByQueryAs(a.author, query)
+}
+
+func (a *authoredDAO) Transaction(f func(tx databaseModel.DAO) error) error {
+	return a.transactionAs(a.author, f)
+}
+
+// fileChanges describes the change of every file for the message of the commit.
+func (d *DAO) fileChanges(eventType modelV1.EventType, files []string) []string {
+	changes := make([]string, 0, len(files))
+	for _, file := range files {
+		key, err := filepath.Rel(d.Folder, file)
+		if err != nil {
+			key = file
+		}
+		changes = append(changes, changeDescription(eventType, strings.TrimSuffix(filepath.ToSlash(key), "."+string(d.Extension))))
+	}
+	return changes
+}
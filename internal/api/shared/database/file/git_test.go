@@ -0,0 +1,132 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasefile
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/perses/perses/internal/api/config"
+	"github.com/perses/perses/internal/api/interface/v1/project"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+// gitLog returns the subject and the author of the commits of the repository, from the most recent one.
+func gitLog(t *testing.T, gitDir string, ref string) []string {
+	output, err := exec.Command("git", "--git-dir", gitDir, "log", "--format=%s|%an <%ae>", ref).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(output)), "\n")
+}
+
+func TestDAO_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	folder := t.TempDir()
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if err := exec.Command("git", "init", "--quiet", "--bare", remote).Run(); err != nil {
+		t.Fatal(err)
+	}
+	// a document existing before the repository is created
+	if err := os.MkdirAll(filepath.Join(folder, "projects"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, "projects", "existing.json"), []byte(`{"kind":"Project","metadata":{"name":"existing"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	gitConf := &config.FileGit{CommitterName: "Perses", CommitterEmail: "perses@example.com", Branch: "main", Remote: "file://" + remote}
+	d := &DAO{Folder: folder, Extension: config.JSONExtension, Git: gitConf}
+	if err := d.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	projectEntity := &modelV1.Project{Kind: modelV1.KindProject, Metadata: modelV1.Metadata{Name: "perses"}}
+	assert.NoError(t, d.Create(projectEntity))
+	// nothing is committed when the document doesn't change
+	assert.NoError(t, d.Upsert(projectEntity))
+	projectEntity.Metadata.Version = 1
+	assert.NoError(t, d.Update(projectEntity, 0))
+	assert.NoError(t, d.Transaction(func(tx databaseModel.DAO) error {
+		if err := tx.Create(&modelV1.Project{Kind: modelV1.KindProject, Metadata: modelV1.Metadata{Name: "other"}}); err != nil {
+			return err
+		}
+		return tx.Delete(modelV1.KindProject, modelV1.NewMetadata("perses"))
+	}))
+	assert.NoError(t, d.DeleteByQuery(&project.Query{NamePrefix: "oth"}))
+	assert.NoError(t, d.Close())
+
+	expected := []string{
+		"Delete projects/other|Perses <perses@example.com>",
+		"Apply 2 changes|Perses <perses@example.com>",
+		"Update projects/perses|Perses <perses@example.com>",
+		"Create projects/perses|Perses <perses@example.com>",
+		"Commit the documents modified outside of Perses|Perses <perses@example.com>",
+	}
+	assert.Equal(t, expected, gitLog(t, filepath.Join(folder, ".git"), "HEAD"))
+	// the commits have been pushed before closing the database
+	assert.Equal(t, expected, gitLog(t, remote, "main"))
+
+	// the repository is reused, and the staging folder of the transactions is never committed
+	d = &DAO{Folder: folder, Extension: config.JSONExtension, Git: gitConf}
+	assert.NoError(t, d.Init())
+	assert.NoError(t, os.MkdirAll(filepath.Join(folder, transactionFolder, "tx-1"), 0700))
+	assert.NoError(t, os.WriteFile(filepath.Join(folder, transactionFolder, "tx-1", "staged.json"), []byte("{}"), 0600))
+	assert.NoError(t, d.Create(&modelV1.Project{Kind: modelV1.KindProject, Metadata: modelV1.Metadata{Name: "again", CreatedAt: time.Now()}}))
+	assert.NoError(t, d.Close())
+	output, err := exec.Command("git", "-C", folder, "ls-files").Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "projects/again.json\nprojects/existing.json\n", string(output))
+}
+
+func TestDAO_GitAuthor(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	folder := t.TempDir()
+	gitConf := &config.FileGit{CommitterName: "Perses", CommitterEmail: "perses@example.com", Branch: "main"}
+	d := &DAO{Folder: folder, Extension: config.JSONExtension, Git: gitConf}
+	if err := d.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	// a document modified outside of Perses is not committed with the next change.
+	assert.NoError(t, os.MkdirAll(filepath.Join(folder, "projects"), 0700))
+	assert.NoError(t, os.WriteFile(filepath.Join(folder, "projects", "manual.json"), []byte(`{"kind":"Project","metadata":{"name":"manual"}}`), 0600))
+
+	authored := databaseModel.WithAuthor(d, "jdoe")
+	assert.NoError(t, authored.Create(&modelV1.Project{Kind: modelV1.KindProject, Metadata: modelV1.Metadata{Name: "perses"}}))
+	assert.NoError(t, authored.Transaction(func(tx databaseModel.DAO) error {
+		return tx.Delete(modelV1.KindProject, modelV1.NewMetadata("perses"))
+	}))
+	assert.NoError(t, d.Create(&modelV1.Project{Kind: modelV1.KindProject, Metadata: modelV1.Metadata{Name: "other"}}))
+
+	output, err := exec.Command("git", "-C", folder, "log", "--format=%s|%an <%ae>|%cn <%ce>").Output()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"Create projects/other|Perses <perses@example.com>|Perses <perses@example.com>",
+		"Delete projects/perses|jdoe <>|Perses <perses@example.com>",
+		"Create projects/perses|jdoe <>|Perses <perses@example.com>",
+	}, strings.Split(strings.TrimSpace(string(output)), "\n"))
+	output, err = exec.Command("git", "-C", folder, "status", "--porcelain").Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "?? projects/manual.json\n", string(output))
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasefile
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/perses/perses/internal/api/config"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// quarantineFolder is the folder, relative to the root of the database, where the documents that cannot be decoded are moved.
+const quarantineFolder = ".quarantine"
+
+// tmpFileSuffix follows the name of a document in the name of the temporary file used to write it.
+const tmpFileSuffix = ".tmp-"
+
+// storedDocument is used to verify a document stored can be decoded.
+type storedDocument struct {
+	Kind     string `json:"kind" yaml:"kind"`
+	Metadata struct {
+		Name string `json:"name" yaml:"name"`
+	} `json:"metadata" yaml:"metadata"`
+}
+
+// checkIntegrity verifies every document can be decoded and is stored in the folder of its kind. Depending on the configuration,
+// the other documents are only logged, or moved to the quarantine folder where they can be repaired before being moved back.
+// It also removes the temporary files left by the writes interrupted by a crash.
+// It returns the path of the documents that cannot be decoded, relative to the root of the database.
+func (d *DAO) checkIntegrity() ([]string, error) {
+	if d.IntegrityCheck == config.IntegrityCheckDisable {
+		return nil, nil
+	}
+	var invalidDocuments []string
+	for kind, plural := range modelV1.PluralKindMap {
+		folder := path.Join(d.Folder, plural)
+		if _, err := os.Stat(folder); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.WalkDir(folder, func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			if strings.HasPrefix(entry.Name(), ".") && strings.Contains(entry.Name(), tmpFileSuffix) {
+				logrus.Infof("removing the temporary file %q left by an interrupted write", filePath)
+				return os.Remove(filePath)
+			}
+			if filepath.Ext(entry.Name()) != fmt.Sprintf(".%s", d.Extension) {
+				return nil
+			}
+			checkErr := d.checkDocument(kind, filePath)
+			if checkErr == nil {
+				return nil
+			}
+			relativePath, relErr := filepath.Rel(d.Folder, filePath)
+			if relErr != nil {
+				return relErr
+			}
+			invalidDocuments = append(invalidDocuments, filepath.ToSlash(relativePath))
+			if d.IntegrityCheck != config.IntegrityCheckQuarantine {
+				logrus.WithError(checkErr).Warningf("the document %q of the database cannot be decoded", relativePath)
+				return nil
+			}
+			logrus.WithError(checkErr).Warningf("the document %q of the database cannot be decoded, it is moved to the folder %q", relativePath, quarantineFolder)
+			return d.quarantine(filePath, relativePath)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return invalidDocuments, nil
+}
+
+// checkDocument verifies the file can be decoded as a document of the kind.
+func (d *DAO) checkDocument(kind modelV1.Kind, filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	doc := &storedDocument{}
+	if unmarshalErr := d.unmarshal(data, doc); unmarshalErr != nil {
+		return unmarshalErr
+	}
+	if doc.Kind != string(kind) {
+		return fmt.Errorf("the kind %q is not the one of the folder, %q", doc.Kind, kind)
+	}
+	if len(doc.Metadata.Name) == 0 {
+		return fmt.Errorf("the name is missing")
+	}
+	return nil
+}
+
+// quarantine moves the document to the quarantine folder. A document already there with the same path is replaced.
+func (d *DAO) quarantine(filePath string, relativePath string) error {
+	dst := path.Join(d.Folder, quarantineFolder, relativePath)
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	return os.Rename(filePath, dst)
+}
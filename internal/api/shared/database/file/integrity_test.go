@@ -0,0 +1,69 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasefile
+
+import (
+	"os"
+	"testing"
+
+	"github.com/perses/perses/internal/api/config"
+	"github.com/perses/perses/internal/api/interface/v1/project"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeInvalidDocuments(t *testing.T, d *DAO) {
+	assert.NoError(t, d.Create(&modelV1.Project{Kind: modelV1.KindProject, Metadata: modelV1.Metadata{Name: "perses"}}))
+	files := map[string]string{
+		// a document truncated by a crash
+		"./test/projects/truncated.json": `{"kind":"Project","metadata":{"na`,
+		// a document stored in the folder of another kind
+		"./test/projects/misplaced.json": `{"kind":"GlobalDatasource","metadata":{"name":"misplaced"}}`,
+		// a temporary file left by an interrupted write
+		"./test/projects/.perses.json.tmp-1234": `{"kind":"Pro`,
+	}
+	for file, content := range files {
+		assert.NoError(t, os.WriteFile(file, []byte(content), 0600))
+	}
+}
+
+func TestDAO_CheckIntegrity(t *testing.T) {
+	defer clear(t)
+	d := newDAO()
+	writeInvalidDocuments(t, d)
+	invalidDocuments, err := d.checkIntegrity()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"projects/truncated.json", "projects/misplaced.json"}, invalidDocuments)
+	// in the report mode, the documents are kept
+	assert.FileExists(t, "./test/projects/truncated.json")
+	assert.NoFileExists(t, "./test/projects/.perses.json.tmp-1234")
+}
+
+func TestDAO_CheckIntegrityQuarantine(t *testing.T) {
+	defer clear(t)
+	d := newDAO()
+	d.IntegrityCheck = config.IntegrityCheckQuarantine
+	writeInvalidDocuments(t, d)
+	assert.NoError(t, d.Init())
+	assert.NoFileExists(t, "./test/projects/truncated.json")
+	assert.FileExists(t, "./test/.quarantine/projects/truncated.json")
+	assert.FileExists(t, "./test/.quarantine/projects/misplaced.json")
+	// the other documents can be read again
+	var projects []*modelV1.Project
+	assert.NoError(t, d.Query(&project.Query{}, &projects))
+	assert.Len(t, projects, 1)
+	invalidDocuments, err := d.checkIntegrity()
+	assert.NoError(t, err)
+	assert.Empty(t, invalidDocuments)
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasefile
+
+import (
+	"os"
+	"path"
+	"sync"
+)
+
+// lockFileName is the file, relative to the root of the database, locked by the writes when the lock file is enabled.
+const lockFileName = ".lock"
+
+// keyLocker provides a mutex per key. A mutex is removed once nobody uses it anymore. The zero value is ready to use.
+type keyLocker struct {
+	mutex sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	// users is the number of goroutines holding or waiting for the mutex.
+	users int
+}
+
+// lock locks the key and returns the function unlocking it.
+func (k *keyLocker) lock(key string) func() {
+	k.mutex.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyLock)
+	}
+	l, exists := k.locks[key]
+	if !exists {
+		l = &keyLock{}
+		k.locks[key] = l
+	}
+	l.users++
+	k.mutex.Unlock()
+	l.Lock()
+	return func() {
+		l.Unlock()
+		k.mutex.Lock()
+		defer k.mutex.Unlock()
+		l.users--
+		if l.users == 0 {
+			delete(k.locks, key)
+		}
+	}
+}
+
+// lockKey prevents any other write of the document with the given key, and of the whole database.
+// The documents with a different key can be written at the same time.
+// It returns the function releasing the lock.
+func (d *DAO) lockKey(key string) (func(), error) {
+	if d.repository != nil {
+		// a git commit includes every change of the folder, so the writes are serialized to commit them one by one.
+		return d.lockAll()
+	}
+	d.mutex.RLock()
+	unlockKey := d.keys.lock(key)
+	unlockFile, err := d.lockFile()
+	if err != nil {
+		unlockKey()
+		d.mutex.RUnlock()
+		return nil, err
+	}
+	return func() {
+		unlockFile()
+		unlockKey()
+		d.mutex.RUnlock()
+	}, nil
+}
+
+// lockAll prevents any other write of the database, like while a transaction is committed.
+// It returns the function releasing the lock.
+func (d *DAO) lockAll() (func(), error) {
+	d.mutex.Lock()
+	unlockFile, err := d.lockFile()
+	if err != nil {
+		d.mutex.Unlock()
+		return nil, err
+	}
+	return func() {
+		unlockFile()
+		d.mutex.Unlock()
+	}, nil
+}
+
+// lockFile takes the advisory lock on the lock file, if it is enabled, so the other processes sharing the folder wait for the write to end.
+// As the lock is held by the open file, a write waits for the other writes of the same process too.
+func (d *DAO) lockFile() (func(), error) {
+	if !d.LockFile {
+		return func() {}, nil
+	}
+	if err := os.MkdirAll(d.Folder, 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path.Join(d.Folder, lockFileName), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if lockErr := lockExclusive(file); lockErr != nil {
+		_ = file.Close()
+		return nil, lockErr
+	}
+	return func() {
+		_ = unlock(file)
+		_ = file.Close()
+	}, nil
+}
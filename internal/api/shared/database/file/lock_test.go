@@ -0,0 +1,122 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasefile
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDAO_CreateConcurrently(t *testing.T) {
+	d := newDAO()
+	defer clear(t)
+	const creates = 20
+	errs := make(chan error, creates)
+	wg := sync.WaitGroup{}
+	for i := 0; i < creates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- d.Create(&modelV1.Project{Kind: modelV1.KindProject, Metadata: modelV1.Metadata{Name: "perses"}})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		assert.True(t, databaseModel.IsKeyConflict(err), err)
+	}
+	assert.Equal(t, 1, created)
+}
+
+func TestDAO_ReadWhileWriting(t *testing.T) {
+	d := newDAO()
+	defer clear(t)
+	newProject := func(i int) *modelV1.Project {
+		return &modelV1.Project{
+			Kind: modelV1.KindProject,
+			Metadata: modelV1.Metadata{
+				Name:   "perses",
+				Labels: map[string]string{"description": fmt.Sprintf("%0*d", i*100, i)},
+			},
+		}
+	}
+	assert.NoError(t, d.Create(newProject(1)))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i < 50; i++ {
+			assert.NoError(t, d.Upsert(newProject(i)))
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			files, err := d.visit("./test/projects", "")
+			assert.NoError(t, err)
+			// the temporary files are removed once the document is written.
+			assert.Equal(t, []string{"test/projects/perses.json"}, files)
+			return
+		default:
+			assert.NoError(t, d.Get(modelV1.KindProject, &modelV1.Metadata{Name: "perses"}, &modelV1.Project{}))
+		}
+	}
+}
+
+func TestDAO_LockFile(t *testing.T) {
+	defer clear(t)
+	// two DAOs sharing the folder behave like two processes: only the lock file prevents a change from being lost.
+	daos := []*DAO{newDAO(), newDAO()}
+	for _, d := range daos {
+		d.LockFile = true
+	}
+	assert.NoError(t, daos[0].Create(&modelV1.Project{Kind: modelV1.KindProject, Metadata: modelV1.Metadata{Name: "perses"}}))
+	const updates = 20
+	wg := sync.WaitGroup{}
+	for _, d := range daos {
+		wg.Add(1)
+		go func(d *DAO) {
+			defer wg.Done()
+			for i := 0; i < updates; {
+				stored := &modelV1.Project{}
+				if err := d.Get(modelV1.KindProject, &modelV1.Metadata{Name: "perses"}, stored); !assert.NoError(t, err) {
+					return
+				}
+				expectedVersion := stored.Metadata.Version
+				stored.Metadata.Version++
+				err := d.Update(stored, expectedVersion)
+				if databaseModel.IsKeyConflict(err) {
+					continue
+				}
+				if !assert.NoError(t, err) {
+					return
+				}
+				i++
+			}
+		}(d)
+	}
+	wg.Wait()
+	result := &modelV1.Project{}
+	assert.NoError(t, daos[0].Get(modelV1.KindProject, &modelV1.Metadata{Name: "perses"}, result))
+	assert.Equal(t, uint64(len(daos)*updates), result.Metadata.Version)
+}
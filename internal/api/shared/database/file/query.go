@@ -17,17 +17,30 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"sort"
 
+	"github.com/perses/perses/internal/api/interface/v1/audit"
 	"github.com/perses/perses/internal/api/interface/v1/dashboard"
 	"github.com/perses/perses/internal/api/interface/v1/datasource"
 	"github.com/perses/perses/internal/api/interface/v1/folder"
 	"github.com/perses/perses/internal/api/interface/v1/globaldatasource"
+	"github.com/perses/perses/internal/api/interface/v1/globalrole"
+	"github.com/perses/perses/internal/api/interface/v1/globalrolebinding"
 	"github.com/perses/perses/internal/api/interface/v1/globalsecret"
 	"github.com/perses/perses/internal/api/interface/v1/globalvariable"
+	"github.com/perses/perses/internal/api/interface/v1/globalwebhook"
 	"github.com/perses/perses/internal/api/interface/v1/project"
+	"github.com/perses/perses/internal/api/interface/v1/role"
+	"github.com/perses/perses/internal/api/interface/v1/rolebinding"
 	"github.com/perses/perses/internal/api/interface/v1/secret"
+	"github.com/perses/perses/internal/api/interface/v1/serviceaccount"
+	"github.com/perses/perses/internal/api/interface/v1/trash"
+	"github.com/perses/perses/internal/api/interface/v1/user"
 	"github.com/perses/perses/internal/api/interface/v1/variable"
+	"github.com/perses/perses/internal/api/interface/v1/webhook"
+	"github.com/perses/perses/internal/api/interface/v1/webhookdelivery"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelAPI "github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 	"github.com/sirupsen/logrus"
 )
@@ -58,9 +71,14 @@ func (d *DAO) generateResourceQuery(kind v1.Kind) string {
 
 func (d *DAO) buildQuery(query databaseModel.Query) (pathFolder string, prefix string, isExist bool, err error) {
 	switch qt := query.(type) {
+	case *audit.Query:
+		pathFolder = d.generateResourceQuery(v1.KindAuditRecord)
 	case *dashboard.Query:
 		pathFolder = d.generateProjectResourceQuery(v1.KindDashboard, qt.Project)
 		prefix = qt.NamePrefix
+	case *dashboard.RevisionQuery:
+		// revisions are stored in a folder per dashboard, named by the version of the dashboard.
+		pathFolder = path.Join(d.generateProjectResourceQuery(v1.KindDashboardRevision, qt.Project), qt.Name)
 	case *datasource.Query:
 		pathFolder = d.generateProjectResourceQuery(v1.KindDatasource, qt.Project)
 		prefix = qt.NamePrefix
@@ -73,21 +91,90 @@ func (d *DAO) buildQuery(query databaseModel.Query) (pathFolder string, prefix s
 	case *globalsecret.Query:
 		pathFolder = d.generateResourceQuery(v1.KindGlobalSecret)
 		prefix = qt.NamePrefix
+	case *globalrole.Query:
+		pathFolder = d.generateResourceQuery(v1.KindGlobalRole)
+		prefix = qt.NamePrefix
+	case *globalrolebinding.Query:
+		pathFolder = d.generateResourceQuery(v1.KindGlobalRoleBinding)
+		prefix = qt.NamePrefix
 	case *globalvariable.Query:
 		pathFolder = d.generateResourceQuery(v1.KindGlobalVariable)
 		prefix = qt.NamePrefix
+	case *globalwebhook.Query:
+		pathFolder = d.generateResourceQuery(v1.KindGlobalWebhook)
+		prefix = qt.NamePrefix
 	case *project.Query:
 		pathFolder = d.generateResourceQuery(v1.KindProject)
 		prefix = qt.NamePrefix
+	case *role.Query:
+		pathFolder = d.generateProjectResourceQuery(v1.KindRole, qt.Project)
+		prefix = qt.NamePrefix
+	case *rolebinding.Query:
+		pathFolder = d.generateProjectResourceQuery(v1.KindRoleBinding, qt.Project)
+		prefix = qt.NamePrefix
 	case *secret.Query:
 		pathFolder = d.generateProjectResourceQuery(v1.KindSecret, qt.Project)
 		prefix = qt.NamePrefix
+	case *serviceaccount.Query:
+		pathFolder = d.generateResourceQuery(v1.KindServiceAccount)
+		prefix = qt.NamePrefix
+	case *trash.Query:
+		pathFolder = d.generateResourceQuery(v1.KindTrashEntry)
+		prefix = qt.NamePrefix
+	case *user.Query:
+		pathFolder = d.generateResourceQuery(v1.KindUser)
+		prefix = qt.NamePrefix
 	case *variable.Query:
 		pathFolder = d.generateProjectResourceQuery(v1.KindVariable, qt.Project)
 		prefix = qt.NamePrefix
+	case *webhook.Query:
+		pathFolder = d.generateProjectResourceQuery(v1.KindWebhook, qt.Project)
+		prefix = qt.NamePrefix
+	case *webhookdelivery.Query:
+		pathFolder = d.generateResourceQuery(v1.KindWebhookDelivery)
 	default:
 		return "", "", false, fmt.Errorf("this type of query '%T' is not managed", qt)
 	}
 	isExist, err = isFolderExist(pathFolder)
 	return
 }
+
+// paginate sorts the entities, and then returns the page requested.
+// As every document has to be read anyway, it is done in memory.
+func paginate(pagination *databaseModel.Pagination, entities []modelAPI.Entity) ([]modelAPI.Entity, error) {
+	if !pagination.IsEnabled() {
+		return entities, nil
+	}
+	cursor, err := pagination.Cursor()
+	if err != nil {
+		return nil, err
+	}
+	_, descending := pagination.SortField()
+	cursors := make(map[modelAPI.Entity]*databaseModel.Cursor, len(entities))
+	for _, entity := range entities {
+		cursors[entity] = databaseModel.NewCursor(pagination, entity.GetMetadata())
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		if descending {
+			return cursors[entities[i]].Compare(cursors[entities[j]]) > 0
+		}
+		return cursors[entities[i]].Compare(cursors[entities[j]]) < 0
+	})
+	if cursor != nil {
+		// skip the entities returned in the previous pages.
+		start := sort.Search(len(entities), func(i int) bool {
+			if descending {
+				return cursors[entities[i]].Compare(cursor) < 0
+			}
+			return cursors[entities[i]].Compare(cursor) > 0
+		})
+		entities = entities[start:]
+	}
+	if pagination.Limit > 0 && len(entities) > pagination.Limit {
+		entities = entities[:pagination.Limit]
+		if nextErr := pagination.SetNext(entities[len(entities)-1]); nextErr != nil {
+			return nil, nextErr
+		}
+	}
+	return entities, nil
+}
@@ -22,6 +22,8 @@ import (
 	"github.com/perses/perses/internal/api/interface/v1/globaldatasource"
 	"github.com/perses/perses/internal/api/interface/v1/project"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -95,3 +97,29 @@ func TestBuildQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestPaginate(t *testing.T) {
+	var entities []modelAPI.Entity
+	for _, name := range []string{"c", "a", "d", "b"} {
+		entities = append(entities, &v1.Project{Kind: v1.KindProject, Metadata: *v1.NewMetadata(name)})
+	}
+	pagination := &databaseModel.Pagination{Limit: 3}
+	firstPage, err := paginate(pagination, entities)
+	assert.NoError(t, err)
+	assert.Len(t, firstPage, 3)
+	assert.Equal(t, "a", firstPage[0].GetMetadata().GetName())
+	assert.Equal(t, "c", firstPage[2].GetMetadata().GetName())
+	assert.NotEmpty(t, pagination.Next())
+
+	nextPage := &databaseModel.Pagination{Limit: 3, Continue: pagination.Next()}
+	secondPage, err := paginate(nextPage, entities)
+	assert.NoError(t, err)
+	assert.Len(t, secondPage, 1)
+	assert.Equal(t, "d", secondPage[0].GetMetadata().GetName())
+	assert.Empty(t, nextPage.Next())
+
+	descending, err := paginate(&databaseModel.Pagination{Sort: "-name"}, entities)
+	assert.NoError(t, err)
+	assert.Len(t, descending, 4)
+	assert.Equal(t, "d", descending[0].GetMetadata().GetName())
+}
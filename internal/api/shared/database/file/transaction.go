@@ -0,0 +1,632 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasefile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// transactionFolder is the folder, relative to the root of the database, where the transactions are staged while they are committed.
+const transactionFolder = ".transactions"
+
+// journalFile is the file of a staging folder listing the renames done to commit the transaction, one JSON document per line.
+// A rename is written to the journal before it is done, so a transaction interrupted by a crash can be rolled back.
+const journalFile = "journal"
+
+// committedFile is created in a staging folder once every change of the transaction is applied.
+// A transaction interrupted after it exists is complete, and only its staging folder remains to be removed.
+const committedFile = "committed"
+
+// operation is a change staged in a transaction.
+type operation struct {
+	// check verifies the operation can be applied. It is run when the operation is staged to report an error as soon as possible,
+	// and once again when the transaction is committed, as the database may have been modified in the meantime.
+	check func() error
+	// apply performs the operation. Every file replaced or removed must be moved to the staging folder using the commit,
+	// so it can be restored if the transaction fails.
+	apply func(c *commit) error
+}
+
+// rename is a file or a folder moved while committing a transaction. The paths are relative to the root of the database.
+type rename struct {
+	Src string `json:"src"`
+	Dst string `json:"dst"`
+}
+
+// commit keeps track of what has been done while committing a transaction, so it can be undone.
+type commit struct {
+	dao     *DAO
+	folder  string
+	journal *os.File
+	renames []rename
+	// events are the changes to send to the watchers once the transaction is committed.
+	events []*databaseModel.Event
+	// changes describe the changes for the message of the git commit.
+	changes []string
+	// files are the paths of the documents written or removed, the only ones staged in git.
+	files []string
+}
+
+// newCommit creates the staging folder of a transaction and its journal.
+func (d *DAO) newCommit() (*commit, error) {
+	root := path.Join(d.Folder, transactionFolder)
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+	folder, err := os.MkdirTemp(root, "tx-")
+	if err != nil {
+		return nil, err
+	}
+	journal, err := os.OpenFile(path.Join(folder, journalFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &commit{dao: d, folder: folder, journal: journal}, nil
+}
+
+// move renames src to dst once it is written in the journal, so it can be moved back even after a crash.
+func (c *commit) move(src string, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	relativeSrc, err := filepath.Rel(c.dao.Folder, src)
+	if err != nil {
+		return err
+	}
+	relativeDst, err := filepath.Rel(c.dao.Folder, dst)
+	if err != nil {
+		return err
+	}
+	r := rename{Src: filepath.ToSlash(relativeSrc), Dst: filepath.ToSlash(relativeDst)}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, writeErr := c.journal.Write(append(data, '\n')); writeErr != nil {
+		return writeErr
+	}
+	if syncErr := c.journal.Sync(); syncErr != nil {
+		return syncErr
+	}
+	c.renames = append(c.renames, r)
+	return os.Rename(src, dst)
+}
+
+// backup moves the file or the folder to the staging folder. It does nothing if it doesn't exist.
+// Each one is moved to its own folder, as the same path can be backed up twice by a transaction, like a document removed,
+// written again and then removed with its folder.
+func (c *commit) backup(filePath string) error {
+	if _, err := os.Stat(filePath); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	relativePath, err := filepath.Rel(c.dao.Folder, filePath)
+	if err != nil {
+		return err
+	}
+	return c.move(filePath, path.Join(c.folder, "backup", strconv.Itoa(len(c.renames)), relativePath))
+}
+
+// write stages the document in the staging folder and then renames it to replace the previous one.
+// fileName is relative to the root of the database.
+func (c *commit) write(fileName string, data []byte) error {
+	stagedFile := path.Join(c.folder, "new", fileName)
+	if err := writeFile(stagedFile, data, false); err != nil {
+		return err
+	}
+	filePath := path.Join(c.dao.Folder, fileName)
+	if err := c.backup(filePath); err != nil {
+		return err
+	}
+	return c.move(stagedFile, filePath)
+}
+
+// markCommitted records every change of the transaction has been applied.
+func (c *commit) markCommitted() error {
+	if err := c.journal.Close(); err != nil {
+		return err
+	}
+	return writeFile(path.Join(c.folder, committedFile), nil, false)
+}
+
+// rollback undoes every change in the reverse order. The staging folder is kept if something cannot be restored,
+// so it remains possible to recover the documents manually.
+func (c *commit) rollback() {
+	_ = c.journal.Close()
+	if err := c.dao.undo(c.renames); err != nil {
+		logrus.WithError(err).Errorf("unable to rollback the transaction, the previous documents are kept in %q", c.folder)
+		return
+	}
+	if err := os.RemoveAll(c.folder); err != nil {
+		logrus.WithError(err).Errorf("unable to remove the transaction folder %q", c.folder)
+	}
+}
+
+// undo moves back the files renamed, in the reverse order. A rename is skipped when it has not been done,
+// which happens when the transaction is interrupted between the time the rename is written in the journal and the time it is done.
+func (d *DAO) undo(renames []rename) error {
+	for i := len(renames) - 1; i >= 0; i-- {
+		src := path.Join(d.Folder, renames[i].Src)
+		dst := path.Join(d.Folder, renames[i].Dst)
+		if _, err := os.Lstat(dst); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		moved, err := isMovedAway(src)
+		if err != nil {
+			return err
+		}
+		if !moved {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(src), 0700); err != nil {
+			return err
+		}
+		if err := os.Rename(dst, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isMovedAway tells whether the source of a rename doesn't exist anymore. An empty folder is removed and considered as moved away,
+// as it is the one created again by a later change, like a document written in a folder backed up by the same transaction.
+func isMovedAway(src string) (bool, error) {
+	info, err := os.Lstat(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	if !info.IsDir() {
+		return false, nil
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil || len(entries) > 0 {
+		return false, err
+	}
+	return true, os.Remove(src)
+}
+
+// recoverTransactions completes the transactions interrupted by a crash. The ones interrupted while their changes were applied
+// are rolled back from their journal, and the ones interrupted once they were committed only have their staging folder removed.
+func (d *DAO) recoverTransactions() error {
+	root := path.Join(d.Folder, transactionFolder)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil
+	}
+	// a transaction being committed by another process holds the lock, so every staging folder left once it is taken is an interrupted transaction.
+	unlock, lockErr := d.lockAll()
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+	transactions, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	for _, transaction := range transactions {
+		folder := path.Join(root, transaction.Name())
+		if _, statErr := os.Stat(path.Join(folder, committedFile)); statErr == nil {
+			logrus.Infof("removing the staging folder %q of a transaction interrupted once committed", folder)
+		} else {
+			logrus.Warningf("rolling back the transaction staged in %q, it has been interrupted before being committed", folder)
+			renames, readErr := readJournal(folder)
+			if readErr != nil {
+				return fmt.Errorf("unable to read the journal of the transaction staged in %q: %w", folder, readErr)
+			}
+			if undoErr := d.undo(renames); undoErr != nil {
+				return fmt.Errorf("unable to rollback the transaction staged in %q, the previous documents are kept in its backup folder: %w", folder, undoErr)
+			}
+		}
+		if removeErr := os.RemoveAll(folder); removeErr != nil {
+			return removeErr
+		}
+	}
+	return nil
+}
+
+// readJournal returns the renames written in the journal of the staging folder. A last line partially written is ignored,
+// as the rename it describes has not been done.
+func readJournal(folder string) ([]rename, error) {
+	data, err := os.ReadFile(path.Join(folder, journalFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var renames []rename
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+		r := rename{}
+		if unmarshalErr := json.Unmarshal([]byte(line), &r); unmarshalErr != nil {
+			if i == len(lines)-1 {
+				break
+			}
+			return nil, unmarshalErr
+		}
+		renames = append(renames, r)
+	}
+	return renames, nil
+}
+
+// transactionDAO stages every change and applies them all at once when the transaction is committed.
+// Reading through it returns the documents as modified by the changes staged, like a SQL transaction.
+type transactionDAO struct {
+	databaseModel.DAO
+	dao *DAO
+	// author is who the changes are attributed to in git. It is empty for the identity of the configuration.
+	author     string
+	operations []operation
+	// documents are the content of the documents written by the transaction, by path. The content is nil for a document removed.
+	documents map[string][]byte
+	// deletions are the documents removed by a query, except the ones written afterward, which are in documents.
+	deletions []deletion
+}
+
+// deletion is a folder, and the prefix of the name of the documents, removed by a query.
+type deletion struct {
+	folder string
+	prefix string
+}
+
+func (d *DAO) Transaction(f func(tx databaseModel.DAO) error) error {
+	return d.transactionAs("", f)
+}
+
+func (d *DAO) transactionAs(author string, f func(tx databaseModel.DAO) error) error {
+	tx := &transactionDAO{dao: d, author: author}
+	if err := f(tx); err != nil {
+		return err
+	}
+	return tx.commit()
+}
+
+func (t *transactionDAO) commit() error {
+	if len(t.operations) == 0 {
+		return nil
+	}
+	unlock, lockErr := t.dao.lockAll()
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+	for _, op := range t.operations {
+		if op.check == nil {
+			continue
+		}
+		if err := op.check(); err != nil {
+			return err
+		}
+	}
+	c, err := t.dao.newCommit()
+	if err != nil {
+		return err
+	}
+	for _, op := range t.operations {
+		if applyErr := op.apply(c); applyErr != nil {
+			c.rollback()
+			return applyErr
+		}
+	}
+	if markErr := c.markCommitted(); markErr != nil {
+		c.rollback()
+		return markErr
+	}
+	t.dao.commit(t.author, c.files, c.changes...)
+	t.dao.publish(c.events...)
+	return os.RemoveAll(c.folder)
+}
+
+// stage adds the operation to the transaction. When the document has already been modified by the transaction, the check is made
+// with the document staged, and it is not made again with the database when the transaction is committed: the one staged is written
+// by the same commit, and the document stored has been checked by the first operation modifying it.
+func (t *transactionDAO) stage(filePath string, op operation) error {
+	if _, isStaged := t.staged(filePath); isStaged {
+		op.check = nil
+	}
+	if op.check != nil {
+		if err := op.check(); err != nil {
+			return err
+		}
+	}
+	t.operations = append(t.operations, op)
+	return nil
+}
+
+// staged returns the content of the document as modified by the transaction. It returns false if the transaction has not modified it.
+func (t *transactionDAO) staged(filePath string) ([]byte, bool) {
+	if data, ok := t.documents[filePath]; ok {
+		return data, true
+	}
+	for _, d := range t.deletions {
+		if t.dao.isInFolder(filePath, d.folder, d.prefix) {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// setDocument records the content of the document once the changes staged are applied.
+func (t *transactionDAO) setDocument(filePath string, data []byte) {
+	if t.documents == nil {
+		t.documents = make(map[string][]byte)
+	}
+	t.documents[filePath] = data
+}
+
+func (t *transactionDAO) list(folder string, prefix string, isExist bool) ([]string, error) {
+	files, err := t.dao.list(folder, prefix, isExist)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, file := range files {
+		if _, isStaged := t.staged(file); !isStaged {
+			result = append(result, file)
+		}
+	}
+	for file, data := range t.documents {
+		if data != nil && t.dao.isInFolder(file, folder, prefix) {
+			result = append(result, file)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (t *transactionDAO) read(filePath string) ([]byte, error) {
+	data, isStaged := t.staged(filePath)
+	if !isStaged {
+		return t.dao.read(filePath)
+	}
+	if data == nil {
+		return nil, &os.PathError{Op: "open", Path: filePath, Err: os.ErrNotExist}
+	}
+	return data, nil
+}
+
+func (t *transactionDAO) Init() error {
+	return nil
+}
+
+func (t *transactionDAO) Close() error {
+	return nil
+}
+
+func (t *transactionDAO) Create(entity modelAPI.Entity) error {
+	key, generateIDErr := generateID(modelV1.Kind(entity.GetKind()), entity.GetMetadata())
+	if generateIDErr != nil {
+		return generateIDErr
+	}
+	data, err := t.dao.marshal(entity)
+	if err != nil {
+		return err
+	}
+	filePath := t.dao.buildPath(key)
+	if staged, isStaged := t.staged(filePath); isStaged && staged != nil {
+		return &databaseModel.Error{Key: key, Code: databaseModel.ErrorCodeConflict}
+	}
+	if stageErr := t.stage(filePath, operation{
+		check: func() error {
+			if _, statErr := os.Stat(filePath); statErr == nil {
+				return &databaseModel.Error{Key: key, Code: databaseModel.ErrorCodeConflict}
+			}
+			return nil
+		},
+		apply: func(c *commit) error {
+			if writeErr := c.write(t.dao.fileName(key), data); writeErr != nil {
+				return writeErr
+			}
+			c.changes = append(c.changes, changeDescription(modelV1.EventTypeAdded, key))
+			c.files = append(c.files, filePath)
+			c.events = append(c.events, t.dao.entityEvent(modelV1.EventTypeAdded, entity))
+			return nil
+		},
+	}); stageErr != nil {
+		return stageErr
+	}
+	t.setDocument(filePath, data)
+	return nil
+}
+
+func (t *transactionDAO) Upsert(entity modelAPI.Entity) error {
+	key, generateIDErr := generateID(modelV1.Kind(entity.GetKind()), entity.GetMetadata())
+	if generateIDErr != nil {
+		return generateIDErr
+	}
+	data, err := t.dao.marshal(entity)
+	if err != nil {
+		return err
+	}
+	filePath := t.dao.buildPath(key)
+	if stageErr := t.stage(filePath, operation{
+		apply: func(c *commit) error {
+			eventType := modelV1.EventTypeModified
+			if _, statErr := os.Stat(filePath); os.IsNotExist(statErr) {
+				eventType = modelV1.EventTypeAdded
+			}
+			if writeErr := c.write(t.dao.fileName(key), data); writeErr != nil {
+				return writeErr
+			}
+			c.changes = append(c.changes, changeDescription(eventType, key))
+			c.files = append(c.files, filePath)
+			c.events = append(c.events, t.dao.entityEvent(eventType, entity))
+			return nil
+		},
+	}); stageErr != nil {
+		return stageErr
+	}
+	t.setDocument(filePath, data)
+	return nil
+}
+
+func (t *transactionDAO) Update(entity modelAPI.Entity, expectedVersion uint64) error {
+	key, generateIDErr := generateID(modelV1.Kind(entity.GetKind()), entity.GetMetadata())
+	if generateIDErr != nil {
+		return generateIDErr
+	}
+	data, err := t.dao.marshal(entity)
+	if err != nil {
+		return err
+	}
+	filePath := t.dao.buildPath(key)
+	if _, isStaged := t.staged(filePath); isStaged {
+		if checkErr := t.dao.checkVersion(t, key, expectedVersion); checkErr != nil {
+			return checkErr
+		}
+	}
+	if stageErr := t.stage(filePath, operation{
+		check: func() error {
+			return t.dao.checkVersion(t.dao, key, expectedVersion)
+		},
+		apply: func(c *commit) error {
+			if writeErr := c.write(t.dao.fileName(key), data); writeErr != nil {
+				return writeErr
+			}
+			c.changes = append(c.changes, changeDescription(modelV1.EventTypeModified, key))
+			c.files = append(c.files, filePath)
+			c.events = append(c.events, t.dao.entityEvent(modelV1.EventTypeModified, entity))
+			return nil
+		},
+	}); stageErr != nil {
+		return stageErr
+	}
+	t.setDocument(filePath, data)
+	return nil
+}
+
+func (t *transactionDAO) Get(kind modelV1.Kind, metadata modelAPI.Metadata, entity modelAPI.Entity) error {
+	return t.dao.get(t, kind, metadata, entity)
+}
+
+func (t *transactionDAO) Query(query databaseModel.Query, slice interface{}) error {
+	return t.dao.query(t, query, slice)
+}
+
+func (t *transactionDAO) Delete(kind modelV1.Kind, metadata modelAPI.Metadata) error {
+	key, generateIDErr := generateID(kind, metadata)
+	if generateIDErr != nil {
+		return generateIDErr
+	}
+	filePath := t.dao.buildPath(key)
+	if staged, isStaged := t.staged(filePath); isStaged && staged == nil {
+		return &databaseModel.Error{Key: key, Code: databaseModel.ErrorCodeNotFound}
+	}
+	if stageErr := t.stage(filePath, operation{
+		check: func() error {
+			if _, err := os.Stat(filePath); err != nil {
+				if os.IsNotExist(err) {
+					return &databaseModel.Error{Key: key, Code: databaseModel.ErrorCodeNotFound}
+				}
+				return err
+			}
+			return nil
+		},
+		apply: func(c *commit) error {
+			events := t.dao.fileEvents(modelV1.EventTypeDeleted, []string{filePath})
+			if err := c.backup(filePath); err != nil {
+				return err
+			}
+			c.changes = append(c.changes, changeDescription(modelV1.EventTypeDeleted, key))
+			c.files = append(c.files, filePath)
+			c.events = append(c.events, events...)
+			return nil
+		},
+	}); stageErr != nil {
+		return stageErr
+	}
+	t.setDocument(filePath, nil)
+	return nil
+}
+
+func (t *transactionDAO) DeleteByQuery(query databaseModel.Query) error {
+	folder, prefix, _, err := t.dao.buildQuery(query)
+	if err != nil {
+		return err
+	}
+	t.operations = append(t.operations, operation{
+		apply: func(c *commit) error {
+			_, _, isExist, buildErr := t.dao.buildQuery(query)
+			if buildErr != nil || !isExist {
+				return buildErr
+			}
+			files, visitErr := t.dao.visit(folder, prefix)
+			if visitErr != nil {
+				return visitErr
+			}
+			events := t.dao.fileEvents(modelV1.EventTypeDeleted, files)
+			if len(prefix) == 0 {
+				if backupErr := c.backup(folder); backupErr != nil {
+					return backupErr
+				}
+				c.changes = append(c.changes, t.dao.fileChanges(modelV1.EventTypeDeleted, files)...)
+				c.files = append(c.files, files...)
+				c.events = append(c.events, events...)
+				return nil
+			}
+			for _, file := range files {
+				if backupErr := c.backup(file); backupErr != nil {
+					return backupErr
+				}
+			}
+			c.changes = append(c.changes, t.dao.fileChanges(modelV1.EventTypeDeleted, files)...)
+			c.files = append(c.files, files...)
+			c.events = append(c.events, events...)
+			return nil
+		},
+	})
+	for file := range t.documents {
+		if t.dao.isInFolder(file, folder, prefix) {
+			delete(t.documents, file)
+		}
+	}
+	t.deletions = append(t.deletions, deletion{folder: folder, prefix: prefix})
+	return nil
+}
+
+func (t *transactionDAO) Transaction(f func(tx databaseModel.DAO) error) error {
+	// a nested transaction is part of the current one.
+	return f(t)
+}
+
+func (t *transactionDAO) Watch(ctx context.Context, kind modelV1.Kind) (<-chan *databaseModel.Event, error) {
+	return t.dao.Watch(ctx, kind)
+}
+
+func (t *transactionDAO) HealthCheck() bool {
+	return t.dao.HealthCheck()
+}
@@ -0,0 +1,250 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasefile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/perses/perses/internal/api/interface/v1/secret"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	secretModel "github.com/perses/perses/pkg/model/api/v1/secret"
+	"github.com/stretchr/testify/assert"
+)
+
+func newProjectWithSecret(t *testing.T, d *DAO) (*modelV1.Project, *modelV1.Secret) {
+	projectEntity := &modelV1.Project{
+		Kind: modelV1.KindProject,
+		Metadata: modelV1.Metadata{
+			Name: "perses",
+		},
+	}
+	secretEntity := &modelV1.Secret{
+		Kind: modelV1.KindSecret,
+		Metadata: modelV1.ProjectMetadata{
+			Metadata: modelV1.Metadata{
+				Name: "foo",
+			},
+			Project: "perses",
+		},
+		Spec: modelV1.SecretSpec{
+			BasicAuth: &secretModel.BasicAuth{
+				Username: "user",
+				Password: "password",
+			},
+		},
+	}
+	if err := d.Create(projectEntity); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Create(secretEntity); err != nil {
+		t.Fatal(err)
+	}
+	return projectEntity, secretEntity
+}
+
+func TestDAO_TransactionCommit(t *testing.T) {
+	d := newDAO()
+	projectEntity, secretEntity := newProjectWithSecret(t, d)
+	err := d.Transaction(func(tx databaseModel.DAO) error {
+		if err := tx.DeleteByQuery(&secret.Query{Project: "perses"}); err != nil {
+			return err
+		}
+		return tx.Delete(modelV1.KindProject, projectEntity.GetMetadata())
+	})
+	assert.NoError(t, err)
+	assert.True(t, databaseModel.IsKeyNotFound(d.Get(modelV1.KindProject, projectEntity.GetMetadata(), &modelV1.Project{})))
+	assert.True(t, databaseModel.IsKeyNotFound(d.Get(modelV1.KindSecret, secretEntity.GetMetadata(), &modelV1.Secret{})))
+	// nothing should remain in the staging folder once the transaction is committed
+	entries, readErr := os.ReadDir(path.Join(d.Folder, transactionFolder))
+	assert.NoError(t, readErr)
+	assert.Len(t, entries, 0)
+	clear(t)
+}
+
+func TestDAO_TransactionRollback(t *testing.T) {
+	d := newDAO()
+	projectEntity, secretEntity := newProjectWithSecret(t, d)
+	err := d.Transaction(func(tx databaseModel.DAO) error {
+		if err := tx.DeleteByQuery(&secret.Query{Project: "perses"}); err != nil {
+			return err
+		}
+		return fmt.Errorf("something went wrong")
+	})
+	assert.Error(t, err)
+	assert.NoError(t, d.Get(modelV1.KindProject, projectEntity.GetMetadata(), &modelV1.Project{}))
+	assert.NoError(t, d.Get(modelV1.KindSecret, secretEntity.GetMetadata(), &modelV1.Secret{}))
+	clear(t)
+}
+
+func TestDAO_TransactionCommitFailure(t *testing.T) {
+	d := newDAO()
+	projectEntity, secretEntity := newProjectWithSecret(t, d)
+	err := d.Transaction(func(tx databaseModel.DAO) error {
+		if err := tx.DeleteByQuery(&secret.Query{Project: "perses"}); err != nil {
+			return err
+		}
+		if err := tx.Delete(modelV1.KindProject, projectEntity.GetMetadata()); err != nil {
+			return err
+		}
+		// the project is removed by someone else before the transaction is committed
+		return d.Delete(modelV1.KindProject, projectEntity.GetMetadata())
+	})
+	assert.True(t, databaseModel.IsKeyNotFound(err))
+	assert.NoError(t, d.Get(modelV1.KindSecret, secretEntity.GetMetadata(), &modelV1.Secret{}))
+	clear(t)
+}
+
+func TestDAO_TransactionReadsItsWrites(t *testing.T) {
+	d := newDAO()
+	projectEntity, secretEntity := newProjectWithSecret(t, d)
+	newSecret := *secretEntity
+	newSecret.Metadata.Name = "bar"
+	err := d.Transaction(func(tx databaseModel.DAO) error {
+		assert.NoError(t, tx.Create(&newSecret))
+		var secrets []*modelV1.Secret
+		assert.NoError(t, tx.Query(&secret.Query{Project: "perses"}, &secrets))
+		assert.Len(t, secrets, 2)
+		assert.NoError(t, tx.Get(modelV1.KindSecret, newSecret.GetMetadata(), &modelV1.Secret{}))
+		// the secret created is not visible outside the transaction until it is committed
+		assert.True(t, databaseModel.IsKeyNotFound(d.Get(modelV1.KindSecret, newSecret.GetMetadata(), &modelV1.Secret{})))
+		assert.True(t, databaseModel.IsKeyConflict(tx.Create(&newSecret)))
+
+		assert.NoError(t, tx.Delete(modelV1.KindSecret, secretEntity.GetMetadata()))
+		assert.True(t, databaseModel.IsKeyNotFound(tx.Get(modelV1.KindSecret, secretEntity.GetMetadata(), &modelV1.Secret{})))
+		assert.True(t, databaseModel.IsKeyNotFound(tx.Delete(modelV1.KindSecret, secretEntity.GetMetadata())))
+		// the secret removed can be created again within the same transaction
+		assert.NoError(t, tx.Create(secretEntity))
+
+		assert.NoError(t, tx.DeleteByQuery(&secret.Query{Project: "perses"}))
+		secrets = nil
+		assert.NoError(t, tx.Query(&secret.Query{Project: "perses"}, &secrets))
+		assert.Len(t, secrets, 0)
+		newSecret.Metadata.Version = 1
+		assert.NoError(t, tx.Upsert(&newSecret))
+		// the version checked is the one of the document staged
+		updatedSecret := newSecret
+		updatedSecret.Metadata.Version = 2
+		assert.True(t, databaseModel.IsKeyConflict(tx.Update(&updatedSecret, 0)))
+		return tx.Update(&updatedSecret, 1)
+	})
+	assert.NoError(t, err)
+	var secrets []*modelV1.Secret
+	assert.NoError(t, d.Query(&secret.Query{Project: "perses"}, &secrets))
+	assert.Len(t, secrets, 1)
+	assert.Equal(t, "bar", secrets[0].Metadata.Name)
+	assert.Equal(t, uint64(2), secrets[0].Metadata.Version)
+	assert.NoError(t, d.Get(modelV1.KindProject, projectEntity.GetMetadata(), &modelV1.Project{}))
+	clear(t)
+}
+
+func TestDAO_TransactionWatch(t *testing.T) {
+	d := newDAO()
+	projectEntity, _ := newProjectWithSecret(t, d)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := d.Watch(ctx, modelV1.KindSecret)
+	assert.NoError(t, err)
+	// nothing is sent when the transaction is rolled back
+	assert.Error(t, d.Transaction(func(tx databaseModel.DAO) error {
+		if deleteErr := tx.DeleteByQuery(&secret.Query{Project: "perses"}); deleteErr != nil {
+			return deleteErr
+		}
+		return fmt.Errorf("something went wrong")
+	}))
+	assert.NoError(t, d.Transaction(func(tx databaseModel.DAO) error {
+		if deleteErr := tx.DeleteByQuery(&secret.Query{Project: "perses"}); deleteErr != nil {
+			return deleteErr
+		}
+		return tx.Delete(modelV1.KindProject, projectEntity.GetMetadata())
+	}))
+	event := receive(t, events)
+	assert.Equal(t, modelV1.EventTypeDeleted, event.Type)
+	assert.Equal(t, modelV1.KindSecret, event.Kind)
+	select {
+	case unexpected := <-events:
+		t.Fatalf("unexpected event %s", unexpected.Type)
+	default:
+	}
+	clear(t)
+}
+
+// stageCrash stages the update of the secret and the removal of the project, and applies the given number of operations
+// before leaving the transaction as a crash would do.
+func stageCrash(t *testing.T, d *DAO, projectEntity *modelV1.Project, secretEntity *modelV1.Secret, applied int, committed bool) {
+	updatedSecret := *secretEntity
+	updatedSecret.Spec = modelV1.SecretSpec{BasicAuth: &secretModel.BasicAuth{Username: "user", Password: "updated"}}
+	tx := &transactionDAO{dao: d}
+	assert.NoError(t, tx.Update(&updatedSecret, 0))
+	assert.NoError(t, tx.Delete(modelV1.KindProject, projectEntity.GetMetadata()))
+	c, err := d.newCommit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, op := range tx.operations[:applied] {
+		assert.NoError(t, op.apply(c))
+	}
+	if committed {
+		assert.NoError(t, c.markCommitted())
+	} else {
+		assert.NoError(t, c.journal.Close())
+	}
+}
+
+func TestDAO_RecoverInterruptedTransaction(t *testing.T) {
+	d := newDAO()
+	projectEntity, secretEntity := newProjectWithSecret(t, d)
+	// the process crashes once the secret is replaced, before the project is removed
+	stageCrash(t, d, projectEntity, secretEntity, 1, false)
+	restarted := newDAO()
+	assert.NoError(t, restarted.Init())
+	result := &modelV1.Secret{}
+	assert.NoError(t, restarted.Get(modelV1.KindSecret, secretEntity.GetMetadata(), result))
+	assert.Equal(t, "password", result.Spec.BasicAuth.Password)
+	assert.NoError(t, restarted.Get(modelV1.KindProject, projectEntity.GetMetadata(), &modelV1.Project{}))
+	entries, readErr := os.ReadDir(path.Join(d.Folder, transactionFolder))
+	assert.NoError(t, readErr)
+	assert.Len(t, entries, 0)
+	clear(t)
+}
+
+func TestDAO_RecoverCommittedTransaction(t *testing.T) {
+	d := newDAO()
+	projectEntity, secretEntity := newProjectWithSecret(t, d)
+	// the process crashes once every change is applied, before the staging folder is removed
+	stageCrash(t, d, projectEntity, secretEntity, 2, true)
+	restarted := newDAO()
+	assert.NoError(t, restarted.Init())
+	result := &modelV1.Secret{}
+	assert.NoError(t, restarted.Get(modelV1.KindSecret, secretEntity.GetMetadata(), result))
+	assert.Equal(t, "updated", result.Spec.BasicAuth.Password)
+	assert.True(t, databaseModel.IsKeyNotFound(restarted.Get(modelV1.KindProject, projectEntity.GetMetadata(), &modelV1.Project{})))
+	entries, readErr := os.ReadDir(path.Join(d.Folder, transactionFolder))
+	assert.NoError(t, readErr)
+	assert.Len(t, entries, 0)
+	clear(t)
+}
+
+func TestReadJournalPartialLine(t *testing.T) {
+	folder := t.TempDir()
+	content := `{"src":"secrets/perses/foo.json","dst":".transactions/tx-1/backup/secrets/perses/foo.json"}` + "\n" + `{"src":"projects/per`
+	assert.NoError(t, os.WriteFile(path.Join(folder, journalFile), []byte(content), 0600))
+	renames, err := readJournal(folder)
+	assert.NoError(t, err)
+	assert.Equal(t, []rename{{Src: "secrets/perses/foo.json", Dst: ".transactions/tx-1/backup/secrets/perses/foo.json"}}, renames)
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasefile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/perses/perses/internal/api/config"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// Watch returns the changes made through this DAO. As the files are not watched, the changes made by another instance of Perses,
+// or directly in the folder, are not returned.
+func (d *DAO) Watch(ctx context.Context, kind modelV1.Kind) (<-chan *databaseModel.Event, error) {
+	return d.notifier.Subscribe(ctx, kind), nil
+}
+
+// entityEvent returns the event of the change of the entity. It returns nil if nobody is watching the changes.
+func (d *DAO) entityEvent(eventType modelV1.EventType, entity modelAPI.Entity) *databaseModel.Event {
+	if !d.notifier.HasSubscriber() {
+		return nil
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		logrus.WithError(err).Error("unable to encode the event of the change")
+		return nil
+	}
+	return d.newEvent(eventType, data)
+}
+
+// fileEvents returns the events of the change of the documents stored in the files.
+// It returns nil if nobody is watching the changes, so it can be called before removing the files at no cost.
+func (d *DAO) fileEvents(eventType modelV1.EventType, files []string) []*databaseModel.Event {
+	if !d.notifier.HasSubscriber() {
+		return nil
+	}
+	var events []*databaseModel.Event
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			logrus.WithError(err).Errorf("unable to read the document %q to build the event of the change", file)
+			continue
+		}
+		if data, err = d.toJSON(data); err != nil {
+			logrus.WithError(err).Errorf("unable to convert the document %q to build the event of the change", file)
+			continue
+		}
+		if event := d.newEvent(eventType, data); event != nil {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (d *DAO) newEvent(eventType modelV1.EventType, data []byte) *databaseModel.Event {
+	event, err := databaseModel.NewEvent(eventType, data)
+	if err != nil {
+		logrus.WithError(err).Error("unable to build the event of the change")
+		return nil
+	}
+	return event
+}
+
+// publish sends the events to the watchers. The nil events are ignored.
+func (d *DAO) publish(events ...*databaseModel.Event) {
+	for _, event := range events {
+		if event != nil {
+			d.notifier.Publish(event)
+		}
+	}
+}
+
+// toJSON converts a document stored in a file to JSON.
+func (d *DAO) toJSON(data []byte) ([]byte, error) {
+	if d.Extension == config.JSONExtension {
+		return data, nil
+	}
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return json.Marshal(convertYAMLMaps(document))
+}
+
+// convertYAMLMaps replaces the maps decoded from YAML, whose keys are not typed, by maps that can be encoded in JSON.
+func convertYAMLMaps(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = convertYAMLMaps(item)
+		}
+		return result
+	case []interface{}:
+		for i, item := range v {
+			v[i] = convertYAMLMaps(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -14,6 +14,7 @@
 package model
 
 import (
+	"context"
 	"io"
 
 	modelAPI "github.com/perses/perses/pkg/model/api"
@@ -25,9 +26,14 @@ type Query interface {
 
 type DAO interface {
 	io.Closer
+	// Init prepares the database before it is used. For the SQL databases, it applies the migrations of the schema that have not been applied yet.
 	Init() error
 	Create(entity modelAPI.Entity) error
 	Upsert(entity modelAPI.Entity) error
+	// Update replaces an existing entity, only if the version of the entity stored is equal to expectedVersion.
+	// The comparison and the replacement are done atomically, so the entity cannot be modified in the meantime.
+	// It returns an Error with the code ErrorCodeNotFound if the entity doesn't exist, or with the code ErrorCodeConflict if the version doesn't match.
+	Update(entity modelAPI.Entity, expectedVersion uint64) error
 	// Get will find a unique object. It will depend on the implementation to generate the key based on the kind and the metadata.
 	// entity is the object that will be used by the method to set the value returned by the database.
 	Get(kind modelV1.Kind, metadata modelAPI.Metadata, entity modelAPI.Entity) error
@@ -36,5 +42,27 @@ type DAO interface {
 	Query(query Query, slice interface{}) error
 	Delete(kind modelV1.Kind, metadata modelAPI.Metadata) error
 	DeleteByQuery(query Query) error
+	// Transaction runs f within a unit of work. The changes made through the DAO passed to f are applied only if f returns nil.
+	// If f returns an error, or if the changes cannot be applied, none of them is kept and the error is returned.
+	Transaction(f func(tx DAO) error) error
+	// Watch returns the changes of the resources of the kind made from now on. The changes made by the other instances of Perses
+	// sharing the database are returned as well when the database supports it.
+	// The channel is closed when ctx is done, or when the changes are not consumed fast enough.
+	Watch(ctx context.Context, kind modelV1.Kind) (<-chan *Event, error)
 	HealthCheck() bool
 }
+
+// AuthoredDAO is implemented by the databases recording who made each change, like the file database stored in git.
+type AuthoredDAO interface {
+	// WithAuthor returns a DAO attributing the changes made through it, including the ones of its transactions, to author.
+	WithAuthor(author string) DAO
+}
+
+// WithAuthor returns a DAO attributing the changes made through it to author, when the database records who made each change.
+// Otherwise, or when author is empty, it returns dao.
+func WithAuthor(dao DAO, author string) DAO {
+	if authored, ok := dao.(AuthoredDAO); ok && len(author) > 0 {
+		return authored.WithAuthor(author)
+	}
+	return dao
+}
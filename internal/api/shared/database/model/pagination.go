@@ -0,0 +1,176 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+const (
+	SortByName      = "name"
+	SortByCreatedAt = "createdAt"
+	SortByUpdatedAt = "updatedAt"
+
+	// timeFormat is used to compare the dates as strings. Contrary to time.RFC3339Nano, the length is fixed,
+	// so the lexicographical order is the chronological order.
+	timeFormat = "2006-01-02T15:04:05.000000000Z"
+)
+
+// FormatTime returns the date in UTC, in a format that can be sorted lexicographically.
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// PaginatedQuery is implemented by the queries supporting the pagination and the sorting.
+type PaginatedQuery interface {
+	Query
+	GetPagination() *Pagination
+}
+
+// Pagination is embedded in a query to paginate and to sort the list returned.
+type Pagination struct {
+	// Limit is the maximum number of resources returned. 0 means there is no limit.
+	Limit int `query:"limit"`
+	// Continue is the token returned with the previous page. It is used to get the next one.
+	Continue string `query:"continue"`
+	// Sort is the field used to sort the list: name, createdAt or updatedAt. It is name by default.
+	// The order is ascending, unless the field is prefixed by "-".
+	Sort string `query:"sort"`
+	// next is the token to use to get the next page. It is set by the DAO and remains empty when there is no more page.
+	next string
+}
+
+func (p *Pagination) GetPagination() *Pagination {
+	return p
+}
+
+// IsEnabled returns true if the list must be sorted. Otherwise, the resources are returned in the order of the database.
+func (p *Pagination) IsEnabled() bool {
+	return p.Limit > 0 || len(p.Continue) > 0 || len(p.Sort) > 0
+}
+
+// SortField returns the field used to sort the list and whether the order is descending.
+func (p *Pagination) SortField() (string, bool) {
+	field := strings.TrimPrefix(p.Sort, "-")
+	if len(field) == 0 {
+		field = SortByName
+	}
+	return field, strings.HasPrefix(p.Sort, "-")
+}
+
+func (p *Pagination) Validate() error {
+	if p.Limit < 0 {
+		return fmt.Errorf("limit cannot be negative")
+	}
+	field, _ := p.SortField()
+	if field != SortByName && field != SortByCreatedAt && field != SortByUpdatedAt {
+		return fmt.Errorf("unable to sort by %q, the possible values are %s, %s and %s", field, SortByName, SortByCreatedAt, SortByUpdatedAt)
+	}
+	_, err := p.Cursor()
+	return err
+}
+
+// Cursor decodes the token Continue. It returns nil if it is empty.
+func (p *Pagination) Cursor() (*Cursor, error) {
+	if len(p.Continue) == 0 {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(p.Continue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid continue token")
+	}
+	cursor := &Cursor{}
+	if unmarshalErr := json.Unmarshal(data, cursor); unmarshalErr != nil {
+		return nil, fmt.Errorf("invalid continue token")
+	}
+	if cursor.Sort != p.Sort {
+		return nil, fmt.Errorf("the continue token has been created for another sort, the sort cannot change between two pages")
+	}
+	return cursor, nil
+}
+
+// Next returns the token to use to get the next page. It is empty if there is no more page.
+func (p *Pagination) Next() string {
+	return p.next
+}
+
+// SetNext is used by the DAO to set the token of the next page from the last resource of the current page.
+func (p *Pagination) SetNext(lastEntity modelAPI.Entity) error {
+	data, err := json.Marshal(NewCursor(p, lastEntity.GetMetadata()))
+	if err != nil {
+		return err
+	}
+	p.next = base64.RawURLEncoding.EncodeToString(data)
+	return nil
+}
+
+// ResetNext must be called by the DAO before running the query, in case the query is reused.
+func (p *Pagination) ResetNext() {
+	p.next = ""
+}
+
+// Cursor is the position of a resource in a sorted list.
+// The resources are sorted by Value (the value of the field used to sort), then by Project and finally by Name, so that the position is unique.
+type Cursor struct {
+	Sort    string `json:"sort,omitempty"`
+	Value   string `json:"value"`
+	Project string `json:"project,omitempty"`
+	Name    string `json:"name"`
+}
+
+func NewCursor(p *Pagination, metadata modelAPI.Metadata) *Cursor {
+	cursor := &Cursor{Sort: p.Sort}
+	var m *modelV1.Metadata
+	switch meta := metadata.(type) {
+	case *modelV1.ProjectMetadata:
+		cursor.Project = meta.Project
+		m = &meta.Metadata
+	case *modelV1.Metadata:
+		m = meta
+	default:
+		cursor.Name = metadata.GetName()
+		cursor.Value = cursor.Name
+		return cursor
+	}
+	cursor.Name = m.Name
+	field, _ := p.SortField()
+	switch field {
+	case SortByCreatedAt:
+		cursor.Value = FormatTime(m.CreatedAt)
+	case SortByUpdatedAt:
+		cursor.Value = FormatTime(m.UpdatedAt)
+	default:
+		cursor.Value = m.Name
+	}
+	return cursor
+}
+
+// Compare returns an integer comparing the position of two cursors in ascending order.
+// The result is 0 if a == b, -1 if a < b, and +1 if a > b.
+func (c *Cursor) Compare(other *Cursor) int {
+	if result := strings.Compare(c.Value, other.Value); result != 0 {
+		return result
+	}
+	if result := strings.Compare(c.Project, other.Project); result != 0 {
+		return result
+	}
+	return strings.Compare(c.Name, other.Name)
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTime(t *testing.T) {
+	date := time.Date(2023, 1, 2, 3, 4, 5, 100000000, time.UTC)
+	laterDate := time.Date(2023, 1, 2, 3, 4, 5, 120000000, time.UTC)
+	assert.Equal(t, "2023-01-02T03:04:05.100000000Z", FormatTime(date))
+	assert.True(t, FormatTime(date) < FormatTime(laterDate))
+}
+
+func TestPagination_Validate(t *testing.T) {
+	testSuites := []struct {
+		title      string
+		pagination Pagination
+		isValid    bool
+	}{
+		{
+			title:      "empty pagination",
+			pagination: Pagination{},
+			isValid:    true,
+		},
+		{
+			title:      "descending sort",
+			pagination: Pagination{Limit: 10, Sort: "-updatedAt"},
+			isValid:    true,
+		},
+		{
+			title:      "negative limit",
+			pagination: Pagination{Limit: -1},
+			isValid:    false,
+		},
+		{
+			title:      "unknown sort",
+			pagination: Pagination{Sort: "kind"},
+			isValid:    false,
+		},
+		{
+			title:      "invalid continue token",
+			pagination: Pagination{Continue: "not a token"},
+			isValid:    false,
+		},
+	}
+	for _, test := range testSuites {
+		t.Run(test.title, func(t *testing.T) {
+			err := test.pagination.Validate()
+			if test.isValid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestPagination_Next(t *testing.T) {
+	pagination := &Pagination{Limit: 1, Sort: SortByCreatedAt}
+	dashboard := &modelV1.Dashboard{
+		Kind:     modelV1.KindDashboard,
+		Metadata: *modelV1.NewProjectMetadata("perses", "demo"),
+	}
+	dashboard.Metadata.CreateNow()
+	assert.NoError(t, pagination.SetNext(dashboard))
+
+	nextPage := &Pagination{Limit: 1, Sort: SortByCreatedAt, Continue: pagination.Next()}
+	cursor, err := nextPage.Cursor()
+	assert.NoError(t, err)
+	assert.Equal(t, &Cursor{
+		Sort:    SortByCreatedAt,
+		Value:   FormatTime(dashboard.Metadata.CreatedAt),
+		Project: "perses",
+		Name:    "demo",
+	}, cursor)
+
+	// the token cannot be used with another sort
+	otherSort := &Pagination{Limit: 1, Sort: SortByName, Continue: pagination.Next()}
+	assert.Error(t, otherSort.Validate())
+
+	pagination.ResetNext()
+	assert.Empty(t, pagination.Next())
+}
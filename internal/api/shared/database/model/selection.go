@@ -0,0 +1,49 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+)
+
+// LabelSelectionQuery is implemented by the queries supporting the selection of the resources by their labels.
+type LabelSelectionQuery interface {
+	Query
+	GetLabelSelection() *LabelSelection
+}
+
+// LabelSelection is embedded in a query to return only the resources matching a label selector.
+type LabelSelection struct {
+	// LabelSelector is a comma-separated list of requirements on the labels, like "team=sre,tier!=dev" or "tier in (frontend,backend)".
+	// It can be empty in case you don't want to filter the list by labels.
+	LabelSelector string `query:"labelSelector"`
+}
+
+func (l *LabelSelection) GetLabelSelection() *LabelSelection {
+	return l
+}
+
+// Selector parses the label selector.
+func (l *LabelSelection) Selector() (common.LabelSelector, error) {
+	return common.ParseLabelSelector(l.LabelSelector)
+}
+
+// GetLabels returns the labels of the resource described by the metadata. It returns nil if the metadata doesn't have labels.
+func GetLabels(metadata modelAPI.Metadata) map[string]string {
+	if m, ok := metadata.(interface{ GetLabels() map[string]string }); ok {
+		return m.GetLabels()
+	}
+	return nil
+}
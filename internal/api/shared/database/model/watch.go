@@ -0,0 +1,170 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	"github.com/sirupsen/logrus"
+)
+
+// eventBufferSize is the number of events a subscriber can be late before being dropped.
+const eventBufferSize = 256
+
+// Event is a change of a resource stored in the database.
+type Event struct {
+	Type modelV1.EventType
+	Kind modelV1.Kind
+	// Document is the resource encoded in JSON. For a deleted resource, it is the last version stored.
+	Document []byte
+}
+
+// NewEvent returns the event of the change of the resource encoded in JSON. The kind is read from the document.
+func NewEvent(eventType modelV1.EventType, document []byte) (*Event, error) {
+	kind := &struct {
+		Kind modelV1.Kind `json:"kind"`
+	}{}
+	if err := json.Unmarshal(document, kind); err != nil {
+		return nil, err
+	}
+	if len(kind.Kind) == 0 {
+		return nil, fmt.Errorf("the kind of the document is missing")
+	}
+	return &Event{Type: eventType, Kind: kind.Kind, Document: document}, nil
+}
+
+// Notifier dispatches the events to the subscribers watching their kind. The zero value is ready to use.
+type Notifier struct {
+	mutex       sync.Mutex
+	subscribers map[*subscriber]bool
+}
+
+type subscriber struct {
+	kind   modelV1.Kind
+	events chan *Event
+}
+
+// Subscribe returns the events of the kind published from now on.
+// The channel is closed when ctx is done, or when the events are not consumed fast enough.
+func (n *Notifier) Subscribe(ctx context.Context, kind modelV1.Kind) <-chan *Event {
+	s := &subscriber{kind: kind, events: make(chan *Event, eventBufferSize)}
+	n.mutex.Lock()
+	if n.subscribers == nil {
+		n.subscribers = make(map[*subscriber]bool)
+	}
+	n.subscribers[s] = true
+	n.mutex.Unlock()
+	go func() {
+		<-ctx.Done()
+		n.mutex.Lock()
+		defer n.mutex.Unlock()
+		n.unsubscribe(s)
+	}()
+	return s.events
+}
+
+// HasSubscriber returns true if at least one subscriber is watching the events.
+// It is used to avoid building the events when nobody is interested in them.
+func (n *Notifier) HasSubscriber() bool {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return len(n.subscribers) > 0
+}
+
+// Publish sends the events to the subscribers. It never blocks: a subscriber whose buffer is full is dropped.
+func (n *Notifier) Publish(events ...*Event) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	for _, event := range events {
+		for s := range n.subscribers {
+			if s.kind != event.Kind {
+				continue
+			}
+			select {
+			case s.events <- event:
+			default:
+				logrus.Warningf("a watcher of the kind %s is too slow and is dropped", s.kind)
+				n.unsubscribe(s)
+			}
+		}
+	}
+}
+
+// unsubscribe must be called with the lock held.
+func (n *Notifier) unsubscribe(s *subscriber) {
+	if n.subscribers[s] {
+		delete(n.subscribers, s)
+		close(s.events)
+	}
+}
+
+// WatchFilter selects the resources whose changes are returned by Watch.
+type WatchFilter struct {
+	// Project is the exact name of the project. It is empty to get the changes of every project.
+	Project string
+	// NamePrefix is a prefix of the name of the resources. It can be empty.
+	NamePrefix string
+	// LabelSelection contains the label selector the resources must match.
+	LabelSelection LabelSelection
+}
+
+func (f WatchFilter) accept(entity modelAPI.Entity, selector common.LabelSelector) bool {
+	metadata := entity.GetMetadata()
+	if len(f.Project) > 0 {
+		if projectMetadata, ok := metadata.(*modelV1.ProjectMetadata); !ok || projectMetadata.Project != f.Project {
+			return false
+		}
+	}
+	return strings.HasPrefix(metadata.GetName(), f.NamePrefix) && selector.Matches(GetLabels(metadata))
+}
+
+// Watch returns the changes of the resources of the kind matching the filter. newEntity is used to decode the resources.
+// The channel is closed when ctx is done, or when the DAO stops sending the changes.
+func Watch(ctx context.Context, dao DAO, kind modelV1.Kind, filter WatchFilter, newEntity func() modelAPI.Entity) (<-chan *modelV1.WatchEvent, error) {
+	selector, err := filter.LabelSelection.Selector()
+	if err != nil {
+		return nil, err
+	}
+	events, err := dao.Watch(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+	result := make(chan *modelV1.WatchEvent)
+	go func() {
+		defer close(result)
+		for event := range events {
+			entity := newEntity()
+			if unmarshalErr := json.Unmarshal(event.Document, entity); unmarshalErr != nil {
+				logrus.WithError(unmarshalErr).Errorf("unable to decode the %s sent by the database", kind)
+				continue
+			}
+			if !filter.accept(entity, selector) {
+				continue
+			}
+			select {
+			case result <- &modelV1.WatchEvent{Type: event.Type, Object: entity}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return result, nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/variable"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEvent(t *testing.T, eventType modelV1.EventType, entity interface{}) *Event {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := NewEvent(eventType, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+func newTestVariable(project string, name string, labels map[string]string) *modelV1.Variable {
+	return &modelV1.Variable{
+		Kind: modelV1.KindVariable,
+		Metadata: modelV1.ProjectMetadata{
+			Metadata: modelV1.Metadata{Name: name, Labels: labels},
+			Project:  project,
+		},
+		Spec: modelV1.VariableSpec{
+			Kind: variable.KindText,
+			Spec: &variable.TextSpec{Value: "value"},
+		},
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	event, err := NewEvent(modelV1.EventTypeAdded, []byte(`{"kind":"Project","metadata":{"name":"perses"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, modelV1.KindProject, event.Kind)
+	assert.Equal(t, modelV1.EventTypeAdded, event.Type)
+	_, err = NewEvent(modelV1.EventTypeAdded, []byte(`{"metadata":{"name":"perses"}}`))
+	assert.Error(t, err)
+}
+
+func TestNotifier(t *testing.T) {
+	n := &Notifier{}
+	assert.False(t, n.HasSubscriber())
+	ctx, cancel := context.WithCancel(context.Background())
+	variables := n.Subscribe(ctx, modelV1.KindVariable)
+	assert.True(t, n.HasSubscriber())
+
+	n.Publish(
+		newTestEvent(t, modelV1.EventTypeAdded, &modelV1.Project{Kind: modelV1.KindProject}),
+		newTestEvent(t, modelV1.EventTypeAdded, newTestVariable("perses", "foo", nil)),
+	)
+	// only the events of the kind watched are received
+	event := <-variables
+	assert.Equal(t, modelV1.KindVariable, event.Kind)
+
+	cancel()
+	_, isOpen := <-variables
+	assert.False(t, isOpen)
+	assert.False(t, n.HasSubscriber())
+}
+
+func TestNotifier_SlowSubscriber(t *testing.T) {
+	n := &Notifier{}
+	events := n.Subscribe(context.Background(), modelV1.KindVariable)
+	event := newTestEvent(t, modelV1.EventTypeAdded, newTestVariable("perses", "foo", nil))
+	for i := 0; i <= eventBufferSize; i++ {
+		n.Publish(event)
+	}
+	// the subscriber is dropped once its buffer is full, so the channel is closed after the events buffered.
+	count := 0
+	for range events {
+		count++
+	}
+	assert.Equal(t, eventBufferSize, count)
+	assert.False(t, n.HasSubscriber())
+}
+
+// notifierDAO is a DAO sending the events published in its notifier.
+type notifierDAO struct {
+	DAO
+	notifier *Notifier
+}
+
+func (d *notifierDAO) Watch(ctx context.Context, kind modelV1.Kind) (<-chan *Event, error) {
+	return d.notifier.Subscribe(ctx, kind), nil
+}
+
+func TestWatch(t *testing.T) {
+	n := &Notifier{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	filter := WatchFilter{Project: "perses", NamePrefix: "node", LabelSelection: LabelSelection{LabelSelector: "team=sre"}}
+	events, err := Watch(ctx, &notifierDAO{notifier: n}, modelV1.KindVariable, filter, func() modelAPI.Entity {
+		return &modelV1.Variable{}
+	})
+	assert.NoError(t, err)
+
+	sre := map[string]string{"team": "sre"}
+	n.Publish(
+		newTestEvent(t, modelV1.EventTypeAdded, newTestVariable("demo", "node", sre)),
+		newTestEvent(t, modelV1.EventTypeAdded, newTestVariable("perses", "cluster", sre)),
+		newTestEvent(t, modelV1.EventTypeAdded, newTestVariable("perses", "node", map[string]string{"team": "dev"})),
+		newTestEvent(t, modelV1.EventTypeDeleted, newTestVariable("perses", "node_name", sre)),
+	)
+	event := <-events
+	assert.Equal(t, modelV1.EventTypeDeleted, event.Type)
+	assert.Equal(t, "node_name", event.Object.(*modelV1.Variable).Metadata.Name)
+
+	_, err = Watch(ctx, &notifierDAO{notifier: n}, modelV1.KindVariable, WatchFilter{LabelSelection: LabelSelection{LabelSelector: "team in"}}, func() modelAPI.Entity {
+		return &modelV1.Variable{}
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,407 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasesql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/huandu/go-sqlbuilder"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	tableSchemaVersion = "schema_version"
+
+	colVersion     = "version"
+	colDescription = "description"
+	colAppliedAt   = "applied_at"
+
+	// migrationLockName is the name of the lock taken while migrating the database.
+	migrationLockName = "perses_migration"
+	// migrationLockTimeout is how long, in seconds, MySQL waits for another instance to finish migrating the database.
+	migrationLockTimeout = 600
+)
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// migrationFileRegexp matches the name of a migration file: <version>_<description>.sql
+var migrationFileRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.sql$`)
+
+// migration is a step to upgrade the schema of the database.
+// The content is a template of SQL statements separated by ";". The following functions are available in the template:
+//   - table returns the complete name of a table, i.e. prefixed by the schema.
+//   - docType returns the type of the column used to store the JSON documents.
+//   - flavor returns the SQL dialect, like "PostgreSQL", so a statement can be specific to a database.
+//   - addColumn returns the statement adding a column to a table, or nothing if the column already exists.
+type migration struct {
+	version     int
+	description string
+	content     string
+	// hook is run after the statements, in the same transaction. It is used to migrate the data when it cannot be done in SQL.
+	hook func(tx *DAO) error
+}
+
+// migrationHooks are the hooks of the embedded migrations, by version.
+var migrationHooks = map[int]func(tx *DAO) error{
+	2: fillDateColumns,
+}
+
+// loadMigrations returns the migrations embedded in the binary, sorted by version.
+func loadMigrations() ([]migration, error) {
+	entries, err := migrationFS.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+	var result []migration
+	for _, entry := range entries {
+		matches := migrationFileRegexp.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("%q is not a valid migration file name", entry.Name())
+		}
+		version, convErr := strconv.Atoi(matches[1])
+		if convErr != nil {
+			return nil, convErr
+		}
+		data, readErr := migrationFS.ReadFile(path.Join("migrations", entry.Name()))
+		if readErr != nil {
+			return nil, readErr
+		}
+		result = append(result, migration{
+			version:     version,
+			description: strings.ReplaceAll(matches[2], "_", " "),
+			content:     string(data),
+			hook:        migrationHooks[version],
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].version < result[j].version
+	})
+	for i, m := range result {
+		if m.version != i+1 {
+			return nil, fmt.Errorf("the migrations must be numbered from 1 without gap, found the version %d at the position %d", m.version, i+1)
+		}
+	}
+	return result, nil
+}
+
+// statements renders the template of the migration and returns every SQL statement it contains.
+func (d *DAO) statements(m migration) ([]string, error) {
+	tmpl, err := template.New(fmt.Sprintf("migration %d", m.version)).
+		Funcs(template.FuncMap{
+			"table":     d.generateCompleteTableName,
+			"docType":   d.docType,
+			"flavor":    d.flavor().String,
+			"addColumn": d.addColumn,
+		}).
+		Parse(m.content)
+	if err != nil {
+		return nil, err
+	}
+	buffer := &bytes.Buffer{}
+	if execErr := tmpl.Execute(buffer, nil); execErr != nil {
+		return nil, execErr
+	}
+	var result []string
+	for _, statement := range strings.Split(buffer.String(), ";") {
+		// remove the comments, so a statement containing only comments is skipped.
+		var lines []string
+		for _, line := range strings.Split(statement, "\n") {
+			if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+				lines = append(lines, line)
+			}
+		}
+		statement = strings.TrimSpace(strings.Join(lines, "\n"))
+		if len(statement) > 0 {
+			result = append(result, statement)
+		}
+	}
+	return result, nil
+}
+
+// hasColumn returns true if the table has the column.
+func (d *DAO) hasColumn(table string, column string) (bool, error) {
+	var query string
+	var args []interface{}
+	switch d.flavor() {
+	case sqlbuilder.SQLite:
+		query = "SELECT COUNT(*) FROM pragma_table_info(?, ?) WHERE name = ?"
+		args = []interface{}{table, d.SchemaName, column}
+	case sqlbuilder.PostgreSQL:
+		query = "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = $1 AND table_name = $2 AND column_name = $3"
+		args = []interface{}{d.SchemaName, table, column}
+	default:
+		query = "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = ? AND table_name = ? AND column_name = ?"
+		args = []interface{}{d.SchemaName, table, column}
+	}
+	var count int
+	err := d.conn().QueryRow(query, args...).Scan(&count)
+	return count > 0, err
+}
+
+// addColumn returns the statement adding the column to the table, or an empty string if the column already exists.
+// MySQL commits each statement modifying the schema, so a migration adding several columns and failing in the middle can then be run again.
+func (d *DAO) addColumn(table string, column string, definition string) (string, error) {
+	exists, err := d.hasColumn(table, column)
+	if err != nil {
+		return "", fmt.Errorf("unable to check whether the column %s of the table %s exists: %w", column, table, err)
+	}
+	if exists {
+		return "", nil
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", d.generateCompleteTableName(table), column, definition), nil
+}
+
+func (d *DAO) createSchemaVersionTable() error {
+	query := d.flavor().NewCreateTableBuilder().CreateTable(d.generateCompleteTableName(tableSchemaVersion)).IfNotExists().
+		Define(colVersion, "INTEGER", "NOT NULL", "PRIMARY KEY").
+		Define(colDescription, "VARCHAR(256)", "NOT NULL").
+		Define(colAppliedAt, "VARCHAR(64)", "NOT NULL").
+		String()
+	_, err := d.conn().Exec(query)
+	return err
+}
+
+// schemaVersion returns the version of the last migration applied. It is 0 when no migration has been applied yet.
+func (d *DAO) schemaVersion() (int, error) {
+	var version int
+	query := fmt.Sprintf("SELECT COALESCE(MAX(%s), 0) FROM %s", colVersion, d.generateCompleteTableName(tableSchemaVersion))
+	err := d.conn().QueryRow(query).Scan(&version)
+	return version, err
+}
+
+// IsInitialized returns true if at least one migration of the schema has been applied, so the tables of the resources exist.
+// Unlike Init, it doesn't modify the database.
+func (d *DAO) IsInitialized() (bool, error) {
+	exists, err := d.hasColumn(tableSchemaVersion, colVersion)
+	if err != nil || !exists {
+		return false, err
+	}
+	version, err := d.schemaVersion()
+	return version > 0, err
+}
+
+// connQueryer runs the queries on a single connection of the pool.
+type connQueryer struct {
+	conn *sql.Conn
+}
+
+func (c *connQueryer) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.conn.ExecContext(context.Background(), query, args...)
+}
+
+func (c *connQueryer) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.conn.QueryContext(context.Background(), query, args...)
+}
+
+func (c *connQueryer) QueryRow(query string, args ...interface{}) *sql.Row {
+	return c.conn.QueryRowContext(context.Background(), query, args...)
+}
+
+// migrationLockKey returns the key of the PostgreSQL advisory lock taken while migrating the schema.
+// Advisory locks are shared by the whole database, so the key depends on the schema.
+func (d *DAO) migrationLockKey() int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(fmt.Sprintf("%s.%s", d.SchemaName, migrationLockName)))
+	return int64(h.Sum64())
+}
+
+// lockMigrations prevents other instances of Perses from migrating the database at the same time, typically when several replicas are starting together.
+// The lock is held by the given connection. It returns the DAO to use to migrate the database, and the function releasing the lock.
+// With SQLite, the lock is a transaction started with "BEGIN IMMEDIATE", so the migrations must be applied within this transaction.
+// This is why the function releasing the lock commits the migrations, or rolls them back when it is given an error.
+func (d *DAO) lockMigrations(conn *sql.Conn) (*DAO, func(migrateErr error) error, error) {
+	ctx := context.Background()
+	switch d.flavor() {
+	case sqlbuilder.SQLite:
+		if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+			return nil, nil, err
+		}
+		locked := &DAO{DB: d.DB, SchemaName: d.SchemaName, Flavor: d.Flavor, PollInterval: d.PollInterval, ChangeRetention: d.ChangeRetention, tx: &connQueryer{conn: conn}}
+		return locked, func(migrateErr error) error {
+			if migrateErr != nil {
+				_, err := conn.ExecContext(ctx, "ROLLBACK")
+				return err
+			}
+			_, err := conn.ExecContext(ctx, "COMMIT")
+			return err
+		}, nil
+	case sqlbuilder.PostgreSQL:
+		key := d.migrationLockKey()
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+			return nil, nil, err
+		}
+		return d, func(error) error {
+			_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key)
+			return err
+		}, nil
+	default:
+		name := fmt.Sprintf("%s.%s", d.SchemaName, migrationLockName)
+		var acquired sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, migrationLockTimeout).Scan(&acquired); err != nil {
+			return nil, nil, err
+		}
+		if !acquired.Valid || acquired.Int64 != 1 {
+			return nil, nil, fmt.Errorf("another instance is still migrating the database after %d seconds", migrationLockTimeout)
+		}
+		return d, func(error) error {
+			_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", name)
+			return err
+		}, nil
+	}
+}
+
+// migrate applies, in order, the migrations that have not been applied yet.
+// The database is locked during the migration, so the instances starting at the same time don't apply the same migrations.
+// Each migration is applied in a transaction along with the insertion of its version in the table schema_version.
+// Note that MySQL commits implicitly the statements modifying the schema, so a migration failing in the middle is not rolled back there.
+// That's why the statements must be written so they can be run again, using "IF NOT EXISTS" or the function addColumn.
+func (d *DAO) migrate(migrations []migration) error {
+	conn, err := d.DB.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	locked, unlock, err := d.lockMigrations(conn)
+	if err != nil {
+		return fmt.Errorf("unable to lock the database to migrate it: %w", err)
+	}
+	migrateErr := locked.applyMigrations(migrations)
+	if unlockErr := unlock(migrateErr); unlockErr != nil && migrateErr == nil {
+		return fmt.Errorf("unable to unlock the database after migrating it: %w", unlockErr)
+	}
+	return migrateErr
+}
+
+// applyMigrations applies the migrations more recent than the version of the schema. The database must be locked.
+func (d *DAO) applyMigrations(migrations []migration) error {
+	if d.flavor() == sqlbuilder.PostgreSQL {
+		// With PostgreSQL, the schema is not the database itself, so it can be created if it doesn't exist.
+		if _, err := d.conn().Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", d.SchemaName)); err != nil {
+			return err
+		}
+	}
+	if err := d.createSchemaVersionTable(); err != nil {
+		return fmt.Errorf("unable to create the table %s: %w", tableSchemaVersion, err)
+	}
+	// the version is read once the lock is held, so the migrations applied by another instance in the meantime are skipped.
+	currentVersion, err := d.schemaVersion()
+	if err != nil {
+		return fmt.Errorf("unable to get the version of the database schema: %w", err)
+	}
+	if len(migrations) > 0 && currentVersion > migrations[len(migrations)-1].version {
+		return fmt.Errorf("the version of the database schema (%d) is more recent than the one supported by this version of Perses (%d)", currentVersion, migrations[len(migrations)-1].version)
+	}
+	for _, m := range migrations {
+		if m.version <= currentVersion {
+			continue
+		}
+		logrus.Infof("applying the database migration %d: %s", m.version, m.description)
+		if migrateErr := d.applyMigration(m); migrateErr != nil {
+			return fmt.Errorf("unable to apply the database migration %d: %w", m.version, migrateErr)
+		}
+	}
+	return nil
+}
+
+func (d *DAO) applyMigration(m migration) error {
+	statements, err := d.statements(m)
+	if err != nil {
+		return err
+	}
+	return d.inTransaction(func(tx *DAO) error {
+		for _, statement := range statements {
+			if _, execErr := tx.conn().Exec(statement); execErr != nil {
+				return execErr
+			}
+		}
+		if m.hook != nil {
+			if hookErr := m.hook(tx); hookErr != nil {
+				return hookErr
+			}
+		}
+		insertBuilder := d.flavor().NewInsertBuilder().
+			InsertInto(d.generateCompleteTableName(tableSchemaVersion)).
+			Cols(colVersion, colDescription, colAppliedAt).
+			Values(m.version, m.description, time.Now().UTC().Format(time.RFC3339))
+		sqlQuery, args := insertBuilder.Build()
+		_, execErr := tx.conn().Exec(sqlQuery, args...)
+		return execErr
+	})
+}
+
+// datedDocument is used to decode only the dates of a document stored.
+type datedDocument struct {
+	Metadata struct {
+		CreatedAt time.Time `json:"createdAt"`
+		UpdatedAt time.Time `json:"updatedAt"`
+	} `json:"metadata"`
+}
+
+// fillDateColumns sets the columns created_at and updated_at of the resources stored before these columns existed.
+func fillDateColumns(tx *DAO) error {
+	for _, table := range resourceTables {
+		tableName := tx.generateCompleteTableName(table)
+		queryBuilder := tx.flavor().NewSelectBuilder().Select(colID, colDoc).From(tableName)
+		sqlQuery, args := queryBuilder.Build()
+		rows, err := tx.conn().Query(sqlQuery, args...)
+		if err != nil {
+			return err
+		}
+		documents := make(map[string]*datedDocument)
+		for rows.Next() {
+			var id, rowJSONDoc string
+			if scanErr := rows.Scan(&id, &rowJSONDoc); scanErr != nil {
+				_ = rows.Close()
+				return scanErr
+			}
+			doc := &datedDocument{}
+			if unmarshalErr := json.Unmarshal([]byte(rowJSONDoc), doc); unmarshalErr != nil {
+				_ = rows.Close()
+				return unmarshalErr
+			}
+			documents[id] = doc
+		}
+		if closeErr := rows.Close(); closeErr != nil {
+			return closeErr
+		}
+		for id, doc := range documents {
+			updateBuilder := tx.flavor().NewUpdateBuilder().Update(tableName)
+			updateBuilder.Set(
+				updateBuilder.Assign(colCreatedAt, databaseModel.FormatTime(doc.Metadata.CreatedAt)),
+				updateBuilder.Assign(colUpdatedAt, databaseModel.FormatTime(doc.Metadata.UpdatedAt)),
+			)
+			updateBuilder.Where(updateBuilder.Equal(colID, id))
+			updateQuery, updateArgs := updateBuilder.Build()
+			if _, execErr := tx.conn().Exec(updateQuery, updateArgs...); execErr != nil {
+				return execErr
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasesql
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	assert.Equal(t, 1, migrations[0].version)
+	assert.Equal(t, "create tables", migrations[0].description)
+}
+
+func TestDAO_Statements(t *testing.T) {
+	d := &DAO{SchemaName: "perses", Flavor: sqlbuilder.PostgreSQL}
+	statements, err := d.statements(migration{
+		version: 1,
+		content: `-- a comment
+CREATE TABLE {{ table "foo" }} (doc {{ docType }} NOT NULL);
+
+CREATE INDEX foo_doc ON {{ table "foo" }} (doc);
+`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"CREATE TABLE perses.foo (doc JSONB NOT NULL)",
+		"CREATE INDEX foo_doc ON perses.foo (doc)",
+	}, statements)
+}
+
+func TestDAO_StatementsFlavor(t *testing.T) {
+	m := migration{
+		version: 1,
+		content: `{{ if eq flavor "PostgreSQL" }}CREATE INDEX foo_doc ON {{ table "foo" }} USING GIN (doc);{{ end }}`,
+	}
+	statements, err := (&DAO{SchemaName: "perses", Flavor: sqlbuilder.PostgreSQL}).statements(m)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CREATE INDEX foo_doc ON perses.foo USING GIN (doc)"}, statements)
+	statements, err = (&DAO{SchemaName: "perses", Flavor: sqlbuilder.SQLite}).statements(m)
+	assert.NoError(t, err)
+	assert.Empty(t, statements)
+}
+
+func TestDAO_Migrate(t *testing.T) {
+	// newDAO already applies the embedded migrations
+	d := newDAO(t)
+	migrations, err := loadMigrations()
+	assert.NoError(t, err)
+	version, err := d.schemaVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, len(migrations), version)
+
+	// running the migrations again must not do anything
+	assert.NoError(t, d.Init())
+
+	next := migration{
+		version:     len(migrations) + 1,
+		description: "add a table",
+		content:     `CREATE TABLE {{ table "foo" }} (id VARCHAR(128) NOT NULL PRIMARY KEY)`,
+	}
+	assert.NoError(t, d.migrate(append(migrations, next)))
+	version, err = d.schemaVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, next.version, version)
+	// the table foo would already exist if the migration was applied twice
+	assert.NoError(t, d.migrate(append(migrations, next)))
+
+	// a failing migration must not be recorded
+	failing := migration{
+		version:     next.version + 1,
+		description: "fail",
+		content:     `CREATE TABLE {{ table "bar" }} (id VARCHAR(128) NOT NULL PRIMARY KEY); INSERT INTO {{ table "unknown" }} VALUES (1)`,
+	}
+	assert.Error(t, d.migrate(append(migrations, next, failing)))
+	version, err = d.schemaVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, next.version, version)
+
+	// the schema is more recent than the migrations known
+	assert.Error(t, d.Init())
+}
+
+func TestDAO_MigrateConcurrently(t *testing.T) {
+	dsn := "file:" + filepath.Join(t.TempDir(), "perses.db") + "?_pragma=busy_timeout(10000)"
+	migrations, err := loadMigrations()
+	assert.NoError(t, err)
+	// the table foo is created without "IF NOT EXISTS", so the migration fails if it is applied twice.
+	migrations = append(migrations, migration{
+		version:     len(migrations) + 1,
+		description: "add a table",
+		content:     `CREATE TABLE {{ table "foo" }} (id VARCHAR(128) NOT NULL PRIMARY KEY)`,
+	})
+	// two instances are started at the same time on the same database.
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		db, openErr := sql.Open("sqlite", dsn)
+		if openErr != nil {
+			t.Fatal(openErr)
+		}
+		defer db.Close()
+		db.SetMaxOpenConns(1)
+		d := &DAO{DB: db, SchemaName: "main", Flavor: sqlbuilder.SQLite}
+		go func() {
+			errs <- d.migrate(migrations)
+		}()
+	}
+	assert.NoError(t, <-errs)
+	assert.NoError(t, <-errs)
+}
+
+func TestFillDateColumns(t *testing.T) {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "perses.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	d := &DAO{DB: db, SchemaName: "main", Flavor: sqlbuilder.SQLite}
+	migrations, err := loadMigrations()
+	assert.NoError(t, err)
+
+	// a project stored before the date columns are added
+	assert.NoError(t, d.migrate(migrations[:1]))
+	_, err = db.Exec(`INSERT INTO main.project (id, name, doc) VALUES ('perses', 'perses', '{"kind":"Project","metadata":{"name":"perses","createdAt":"2023-01-02T03:04:05.1Z","updatedAt":"2023-02-03T04:05:06Z"}}')`)
+	assert.NoError(t, err)
+
+	assert.NoError(t, d.migrate(migrations[:2]))
+	var createdAt, updatedAt string
+	assert.NoError(t, db.QueryRow("SELECT created_at, updated_at FROM main.project WHERE id = 'perses'").Scan(&createdAt, &updatedAt))
+	assert.Equal(t, "2023-01-02T03:04:05.100000000Z", createdAt)
+	assert.Equal(t, "2023-02-03T04:05:06.000000000Z", updatedAt)
+}
+
+func TestAddDateColumnsAgain(t *testing.T) {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "perses.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	d := &DAO{DB: db, SchemaName: "main", Flavor: sqlbuilder.SQLite}
+	migrations, err := loadMigrations()
+	assert.NoError(t, err)
+
+	// a previous attempt failed after adding some of the columns.
+	assert.NoError(t, d.migrate(migrations[:1]))
+	_, err = db.Exec(`ALTER TABLE main.project ADD COLUMN created_at VARCHAR(32) NOT NULL DEFAULT ''`)
+	assert.NoError(t, err)
+
+	assert.NoError(t, d.migrate(migrations[:2]))
+	exists, err := d.hasColumn("project", "updated_at")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = d.hasColumn("project", "unknown")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -0,0 +1,13 @@
+-- Tables of the global resources. The id is the name of the resource.
+CREATE TABLE IF NOT EXISTS {{ table "globaldatasource" }} (id VARCHAR(128) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL);
+CREATE TABLE IF NOT EXISTS {{ table "globalsecret" }} (id VARCHAR(128) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL);
+CREATE TABLE IF NOT EXISTS {{ table "globalvariable" }} (id VARCHAR(128) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL);
+CREATE TABLE IF NOT EXISTS {{ table "project" }} (id VARCHAR(128) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL);
+
+-- Tables of the resources that belong to a project. The id is the concatenation of the project and the name of the resource.
+CREATE TABLE IF NOT EXISTS {{ table "dashboard" }} (id VARCHAR(256) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, project VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL);
+CREATE TABLE IF NOT EXISTS {{ table "dashboardrevision" }} (id VARCHAR(256) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, project VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL);
+CREATE TABLE IF NOT EXISTS {{ table "folder" }} (id VARCHAR(256) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, project VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL);
+CREATE TABLE IF NOT EXISTS {{ table "datasource" }} (id VARCHAR(256) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, project VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL);
+CREATE TABLE IF NOT EXISTS {{ table "secret" }} (id VARCHAR(256) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, project VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL);
+CREATE TABLE IF NOT EXISTS {{ table "variable" }} (id VARCHAR(256) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, project VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL);
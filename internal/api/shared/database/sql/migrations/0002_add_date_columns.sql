@@ -0,0 +1,23 @@
+-- The dates are stored in dedicated columns, so the resources can be sorted by date.
+-- They are filled from the documents already stored once the columns are added.
+-- The columns are added only if they don't exist, so the migration can be run again after a failure in the middle.
+{{ addColumn "globaldatasource" "created_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "globaldatasource" "updated_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "globalsecret" "created_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "globalsecret" "updated_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "globalvariable" "created_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "globalvariable" "updated_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "project" "created_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "project" "updated_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "dashboard" "created_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "dashboard" "updated_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "dashboardrevision" "created_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "dashboardrevision" "updated_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "folder" "created_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "folder" "updated_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "datasource" "created_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "datasource" "updated_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "secret" "created_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "secret" "updated_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "variable" "created_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
+{{ addColumn "variable" "updated_at" "VARCHAR(32) NOT NULL DEFAULT ''" }};
@@ -0,0 +1,13 @@
+-- With PostgreSQL, the labels are indexed so the label selectors checking the value of a label don't have to scan every document.
+-- The other databases are extracting the labels from the documents.
+{{ if eq flavor "PostgreSQL" }}
+CREATE INDEX IF NOT EXISTS globaldatasource_labels ON {{ table "globaldatasource" }} USING GIN ((doc->'metadata'->'labels'));
+CREATE INDEX IF NOT EXISTS globalsecret_labels ON {{ table "globalsecret" }} USING GIN ((doc->'metadata'->'labels'));
+CREATE INDEX IF NOT EXISTS globalvariable_labels ON {{ table "globalvariable" }} USING GIN ((doc->'metadata'->'labels'));
+CREATE INDEX IF NOT EXISTS project_labels ON {{ table "project" }} USING GIN ((doc->'metadata'->'labels'));
+CREATE INDEX IF NOT EXISTS dashboard_labels ON {{ table "dashboard" }} USING GIN ((doc->'metadata'->'labels'));
+CREATE INDEX IF NOT EXISTS folder_labels ON {{ table "folder" }} USING GIN ((doc->'metadata'->'labels'));
+CREATE INDEX IF NOT EXISTS datasource_labels ON {{ table "datasource" }} USING GIN ((doc->'metadata'->'labels'));
+CREATE INDEX IF NOT EXISTS secret_labels ON {{ table "secret" }} USING GIN ((doc->'metadata'->'labels'));
+CREATE INDEX IF NOT EXISTS variable_labels ON {{ table "variable" }} USING GIN ((doc->'metadata'->'labels'));
+{{ end }}
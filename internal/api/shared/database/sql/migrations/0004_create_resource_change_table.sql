@@ -0,0 +1,12 @@
+-- Every change of a resource is recorded, so each instance of Perses sharing the database can send it to its watchers.
+-- The rows are removed once they are older than the retention, so the table only contains the recent changes.
+{{ if eq flavor "PostgreSQL" }}
+CREATE TABLE IF NOT EXISTS {{ table "resourcechange" }} (seq BIGSERIAL PRIMARY KEY, kind VARCHAR(64) NOT NULL, event VARCHAR(16) NOT NULL, doc {{ docType }} NOT NULL, created_at VARCHAR(32) NOT NULL);
+CREATE INDEX IF NOT EXISTS resourcechange_created_at ON {{ table "resourcechange" }} (created_at);
+{{ else if eq flavor "SQLite" }}
+CREATE TABLE IF NOT EXISTS {{ table "resourcechange" }} (seq INTEGER PRIMARY KEY AUTOINCREMENT, kind VARCHAR(64) NOT NULL, event VARCHAR(16) NOT NULL, doc {{ docType }} NOT NULL, created_at VARCHAR(32) NOT NULL);
+-- SQLite expects the schema on the name of the index rather than on the name of the table.
+CREATE INDEX IF NOT EXISTS {{ table "resourcechange_created_at" }} ON resourcechange (created_at);
+{{ else }}
+CREATE TABLE IF NOT EXISTS {{ table "resourcechange" }} (seq BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY, kind VARCHAR(64) NOT NULL, event VARCHAR(16) NOT NULL, doc {{ docType }} NOT NULL, created_at VARCHAR(32) NOT NULL, INDEX resourcechange_created_at (created_at));
+{{ end }}
@@ -0,0 +1,3 @@
+-- The trash keeps the dashboards and the projects deleted until they are restored, purged or expired.
+-- The id is the name of the entry. The project of the resource deleted is only in the document.
+CREATE TABLE IF NOT EXISTS {{ table "trashentry" }} (id VARCHAR(128) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL, created_at VARCHAR(32) NOT NULL DEFAULT '', updated_at VARCHAR(32) NOT NULL DEFAULT '');
@@ -0,0 +1,2 @@
+-- The users are global resources. The table is named users, as user is a reserved word in PostgreSQL.
+CREATE TABLE IF NOT EXISTS {{ table "users" }} (id VARCHAR(128) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL, created_at VARCHAR(32) NOT NULL DEFAULT '', updated_at VARCHAR(32) NOT NULL DEFAULT '');
@@ -0,0 +1,5 @@
+-- The roles and the role bindings giving the permissions of the users.
+CREATE TABLE IF NOT EXISTS {{ table "globalrole" }} (id VARCHAR(128) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL, created_at VARCHAR(32) NOT NULL DEFAULT '', updated_at VARCHAR(32) NOT NULL DEFAULT '');
+CREATE TABLE IF NOT EXISTS {{ table "globalrolebinding" }} (id VARCHAR(128) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL, created_at VARCHAR(32) NOT NULL DEFAULT '', updated_at VARCHAR(32) NOT NULL DEFAULT '');
+CREATE TABLE IF NOT EXISTS {{ table "role" }} (id VARCHAR(256) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, project VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL, created_at VARCHAR(32) NOT NULL DEFAULT '', updated_at VARCHAR(32) NOT NULL DEFAULT '');
+CREATE TABLE IF NOT EXISTS {{ table "rolebinding" }} (id VARCHAR(256) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, project VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL, created_at VARCHAR(32) NOT NULL DEFAULT '', updated_at VARCHAR(32) NOT NULL DEFAULT '');
@@ -0,0 +1,2 @@
+-- The service accounts, whose API tokens are stored in their document.
+CREATE TABLE IF NOT EXISTS {{ table "serviceaccount" }} (id VARCHAR(128) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL, created_at VARCHAR(32) NOT NULL DEFAULT '', updated_at VARCHAR(32) NOT NULL DEFAULT '');
@@ -0,0 +1,2 @@
+-- The audit log, one document per change made with the API. The records are only appended, never updated.
+CREATE TABLE IF NOT EXISTS {{ table "auditrecord" }} (id VARCHAR(128) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL, created_at VARCHAR(32) NOT NULL DEFAULT '', updated_at VARCHAR(32) NOT NULL DEFAULT '');
@@ -0,0 +1,4 @@
+-- The webhooks notified of the changes, and the queue of the deliveries of the changes to them.
+CREATE TABLE IF NOT EXISTS {{ table "globalwebhook" }} (id VARCHAR(128) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL, created_at VARCHAR(32) NOT NULL DEFAULT '', updated_at VARCHAR(32) NOT NULL DEFAULT '');
+CREATE TABLE IF NOT EXISTS {{ table "webhook" }} (id VARCHAR(256) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, project VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL, created_at VARCHAR(32) NOT NULL DEFAULT '', updated_at VARCHAR(32) NOT NULL DEFAULT '');
+CREATE TABLE IF NOT EXISTS {{ table "webhookdelivery" }} (id VARCHAR(128) NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, doc {{ docType }} NOT NULL, created_at VARCHAR(32) NOT NULL DEFAULT '', updated_at VARCHAR(32) NOT NULL DEFAULT '');
@@ -0,0 +1,10 @@
+-- The deliveries are read by status and by date of their last modification, to send the pending ones and to remove the expired ones,
+-- so the queue doesn't have to be scanned entirely. MySQL only indexes the date, as it cannot index an expression of every version.
+{{ if eq flavor "PostgreSQL" }}
+CREATE INDEX IF NOT EXISTS webhookdelivery_status ON {{ table "webhookdelivery" }} ((doc->'spec'->>'status'), updated_at);
+{{ else if eq flavor "SQLite" }}
+-- SQLite expects the schema on the name of the index rather than on the name of the table.
+CREATE INDEX IF NOT EXISTS {{ table "webhookdelivery_status" }} ON webhookdelivery (json_extract(doc, '$.spec.status'), updated_at);
+{{ else }}
+CREATE INDEX webhookdelivery_updated_at ON {{ table "webhookdelivery" }} (updated_at);
+{{ end }}
@@ -16,35 +16,52 @@ package databasesql
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/huandu/go-sqlbuilder"
+	"github.com/perses/perses/internal/api/interface/v1/audit"
 	"github.com/perses/perses/internal/api/interface/v1/dashboard"
 	"github.com/perses/perses/internal/api/interface/v1/datasource"
 	"github.com/perses/perses/internal/api/interface/v1/folder"
 	"github.com/perses/perses/internal/api/interface/v1/globaldatasource"
+	"github.com/perses/perses/internal/api/interface/v1/globalrole"
+	"github.com/perses/perses/internal/api/interface/v1/globalrolebinding"
 	"github.com/perses/perses/internal/api/interface/v1/globalsecret"
 	"github.com/perses/perses/internal/api/interface/v1/globalvariable"
+	"github.com/perses/perses/internal/api/interface/v1/globalwebhook"
 	"github.com/perses/perses/internal/api/interface/v1/project"
+	"github.com/perses/perses/internal/api/interface/v1/role"
+	"github.com/perses/perses/internal/api/interface/v1/rolebinding"
 	"github.com/perses/perses/internal/api/interface/v1/secret"
+	"github.com/perses/perses/internal/api/interface/v1/serviceaccount"
+	"github.com/perses/perses/internal/api/interface/v1/trash"
+	"github.com/perses/perses/internal/api/interface/v1/user"
 	"github.com/perses/perses/internal/api/interface/v1/variable"
+	"github.com/perses/perses/internal/api/interface/v1/webhook"
+	"github.com/perses/perses/internal/api/interface/v1/webhookdelivery"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
 	modelAPI "github.com/perses/perses/pkg/model/api"
 	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
 )
 
-func generateProjectResourceInsertQuery(tableName string, id string, rowJSONDoc []byte, metadata *modelV1.ProjectMetadata) (string, []interface{}) {
-	return sqlbuilder.NewInsertBuilder().
+// The JSON document is always passed as a string to the database.
+// A slice of bytes would be sent as a binary value by some drivers (like the PostgreSQL one), which is not accepted by a JSON column.
+
+func generateProjectResourceInsertQuery(flavor sqlbuilder.Flavor, tableName string, id string, rowJSONDoc []byte, metadata *modelV1.ProjectMetadata) (string, []interface{}) {
+	return flavor.NewInsertBuilder().
 		InsertInto(tableName).
-		Cols(colID, colName, colProject, colDoc).
-		Values(id, metadata.Name, metadata.Project, rowJSONDoc).
+		Cols(colID, colName, colProject, colCreatedAt, colUpdatedAt, colDoc).
+		Values(id, metadata.Name, metadata.Project, databaseModel.FormatTime(metadata.CreatedAt), databaseModel.FormatTime(metadata.UpdatedAt), string(rowJSONDoc)).
 		Build()
 }
 
-func generateResourceInsertQuery(tableName string, id string, rowJSONDoc []byte, metadata *modelV1.Metadata) (string, []interface{}) {
-	return sqlbuilder.NewInsertBuilder().
+func generateResourceInsertQuery(flavor sqlbuilder.Flavor, tableName string, id string, rowJSONDoc []byte, metadata *modelV1.Metadata) (string, []interface{}) {
+	return flavor.NewInsertBuilder().
 		InsertInto(tableName).
-		Cols(colID, colName, colDoc).
-		Values(id, metadata.Name, rowJSONDoc).
+		Cols(colID, colName, colCreatedAt, colUpdatedAt, colDoc).
+		Values(id, metadata.Name, databaseModel.FormatTime(metadata.CreatedAt), databaseModel.FormatTime(metadata.UpdatedAt), string(rowJSONDoc)).
 		Build()
 }
 
@@ -60,10 +77,12 @@ func (d *DAO) generateInsertQuery(entity modelAPI.Entity) (string, []interface{}
 	var sql string
 	var args []interface{}
 	switch m := entity.GetMetadata().(type) {
+	case *modelV1.RevisionMetadata:
+		sql, args = generateProjectResourceInsertQuery(d.flavor(), tableName, id, rowJSONDoc, &m.ProjectMetadata)
 	case *modelV1.ProjectMetadata:
-		sql, args = generateProjectResourceInsertQuery(tableName, id, rowJSONDoc, m)
+		sql, args = generateProjectResourceInsertQuery(d.flavor(), tableName, id, rowJSONDoc, m)
 	case *modelV1.Metadata:
-		sql, args = generateResourceInsertQuery(tableName, id, rowJSONDoc, m)
+		sql, args = generateResourceInsertQuery(d.flavor(), tableName, id, rowJSONDoc, m)
 	}
 	return sql, args, nil
 }
@@ -77,15 +96,28 @@ func (d *DAO) generateUpdateQuery(entity modelAPI.Entity) (string, []interface{}
 	if unmarshalErr != nil {
 		return "", nil, unmarshalErr
 	}
-	builder := sqlbuilder.NewUpdateBuilder().Update(tableName)
+	var createdAt, updatedAt time.Time
+	switch m := entity.GetMetadata().(type) {
+	case *modelV1.RevisionMetadata:
+		createdAt, updatedAt = m.CreatedAt, m.UpdatedAt
+	case *modelV1.ProjectMetadata:
+		createdAt, updatedAt = m.CreatedAt, m.UpdatedAt
+	case *modelV1.Metadata:
+		createdAt, updatedAt = m.CreatedAt, m.UpdatedAt
+	}
+	builder := d.flavor().NewUpdateBuilder().Update(tableName)
 	builder.Where(builder.Equal(colID, id))
-	builder.Set(builder.Assign(colDoc, rowJSONDoc))
+	builder.Set(
+		builder.Assign(colDoc, string(rowJSONDoc)),
+		builder.Assign(colCreatedAt, databaseModel.FormatTime(createdAt)),
+		builder.Assign(colUpdatedAt, databaseModel.FormatTime(updatedAt)),
+	)
 	sql, args := builder.Build()
 	return sql, args, nil
 }
 
-func generatSelectQuery(tableName string, project string, name string) (string, []interface{}) {
-	queryBuilder := sqlbuilder.NewSelectBuilder().
+func newSelectBuilder(flavor sqlbuilder.Flavor, tableName string, project string, name string) *sqlbuilder.SelectBuilder {
+	queryBuilder := flavor.NewSelectBuilder().
 		Select(colDoc).
 		From(tableName)
 	if len(name) > 0 {
@@ -94,73 +126,339 @@ func generatSelectQuery(tableName string, project string, name string) (string,
 	if len(project) > 0 {
 		queryBuilder.Where(queryBuilder.Equal(colProject, project))
 	}
+	return queryBuilder
+}
+
+func generatSelectQuery(flavor sqlbuilder.Flavor, tableName string, project string, name string) (string, []interface{}) {
+	return newSelectBuilder(flavor, tableName, project, name).Build()
+}
+
+// paginate sorts the result and selects only the page requested.
+// The rows are sorted by the column requested, then by project and finally by name, like the cursor.
+// One more row than the limit is selected, to know if there is a next page.
+func paginate(queryBuilder *sqlbuilder.SelectBuilder, query databaseModel.Query, isProjectResource bool) error {
+	paginatedQuery, ok := query.(databaseModel.PaginatedQuery)
+	if !ok || !paginatedQuery.GetPagination().IsEnabled() {
+		return nil
+	}
+	pagination := paginatedQuery.GetPagination()
+	cursor, err := pagination.Cursor()
+	if err != nil {
+		return err
+	}
+	if cursor == nil {
+		cursor = &databaseModel.Cursor{}
+	}
+	field, descending := pagination.SortField()
+	sortColumn := colName
+	switch field {
+	case databaseModel.SortByCreatedAt:
+		sortColumn = colCreatedAt
+	case databaseModel.SortByUpdatedAt:
+		sortColumn = colUpdatedAt
+	}
+	columns := []string{sortColumn}
+	values := []string{cursor.Value}
+	if isProjectResource {
+		columns = append(columns, colProject)
+		values = append(values, cursor.Project)
+	}
+	if sortColumn != colName {
+		columns = append(columns, colName)
+		values = append(values, cursor.Name)
+	}
+	operator := ">"
+	order := "ASC"
+	if descending {
+		operator = "<"
+		order = "DESC"
+	}
+	if len(pagination.Continue) > 0 {
+		placeholders := make([]string, 0, len(values))
+		for _, v := range values {
+			placeholders = append(placeholders, queryBuilder.Var(v))
+		}
+		queryBuilder.Where(fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), operator, strings.Join(placeholders, ", ")))
+	}
+	orderBy := make([]string, 0, len(columns))
+	for _, column := range columns {
+		orderBy = append(orderBy, fmt.Sprintf("%s %s", column, order))
+	}
+	queryBuilder.OrderBy(orderBy...)
+	if pagination.Limit > 0 {
+		queryBuilder.Limit(pagination.Limit + 1)
+	}
+	return nil
+}
+
+// labelPath returns the JSON path of the label in the document, as used by MySQL and SQLite.
+// The key is quoted, as it can contain dots and slashes.
+func labelPath(key string) string {
+	return fmt.Sprintf(`$.metadata.labels."%s"`, key)
+}
+
+// labelValue returns the SQL expression extracting the value of the label from the document. It is NULL when the label is not set.
+func labelValue(flavor sqlbuilder.Flavor, queryBuilder *sqlbuilder.SelectBuilder, key string) string {
+	switch flavor {
+	case sqlbuilder.PostgreSQL:
+		return fmt.Sprintf("(%s->'metadata'->'labels'->>%s)", colDoc, queryBuilder.Var(key))
+	case sqlbuilder.SQLite:
+		return fmt.Sprintf("json_extract(%s, %s)", colDoc, queryBuilder.Var(labelPath(key)))
+	default:
+		return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, %s))", colDoc, queryBuilder.Var(labelPath(key)))
+	}
+}
+
+// deliveryStatus returns the SQL expression extracting the status of a webhook delivery from the document.
+// It is written exactly like in the index of the deliveries, so the databases can use it.
+func deliveryStatus(flavor sqlbuilder.Flavor) string {
+	switch flavor {
+	case sqlbuilder.PostgreSQL:
+		return fmt.Sprintf("(%s->'spec'->>'status')", colDoc)
+	case sqlbuilder.SQLite:
+		return fmt.Sprintf("json_extract(%s, '$.spec.status')", colDoc)
+	default:
+		return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, '$.spec.status'))", colDoc)
+	}
+}
+
+// labelIn returns the SQL condition checking the label is set with one of the values.
+// With PostgreSQL, it is using the containment operator, so the GIN index on the labels can be used.
+func labelIn(flavor sqlbuilder.Flavor, queryBuilder *sqlbuilder.SelectBuilder, key string, values []string) (string, error) {
+	if flavor == sqlbuilder.PostgreSQL {
+		conditions := make([]string, 0, len(values))
+		for _, value := range values {
+			data, err := json.Marshal(map[string]string{key: value})
+			if err != nil {
+				return "", err
+			}
+			conditions = append(conditions, fmt.Sprintf("%s->'metadata'->'labels' @> CAST(%s AS JSONB)", colDoc, queryBuilder.Var(string(data))))
+		}
+		return fmt.Sprintf("(%s)", strings.Join(conditions, " OR ")), nil
+	}
+	placeholders := make([]string, 0, len(values))
+	for _, value := range values {
+		placeholders = append(placeholders, queryBuilder.Var(value))
+	}
+	return fmt.Sprintf("%s IN (%s)", labelValue(flavor, queryBuilder, key), strings.Join(placeholders, ", ")), nil
+}
+
+// selectLabels keeps only the rows matching the label selector of the query.
+// Like in Kubernetes, a label that is not set fulfills the operators != and notin.
+func selectLabels(flavor sqlbuilder.Flavor, queryBuilder *sqlbuilder.SelectBuilder, query databaseModel.Query) error {
+	selectionQuery, ok := query.(databaseModel.LabelSelectionQuery)
+	if !ok {
+		return nil
+	}
+	selector, err := selectionQuery.GetLabelSelection().Selector()
+	if err != nil {
+		return err
+	}
+	for _, requirement := range selector {
+		var condition string
+		switch requirement.Operator {
+		case common.SelectorOperatorExists:
+			condition = fmt.Sprintf("%s IS NOT NULL", labelValue(flavor, queryBuilder, requirement.Key))
+		case common.SelectorOperatorDoesNotExist:
+			condition = fmt.Sprintf("%s IS NULL", labelValue(flavor, queryBuilder, requirement.Key))
+		case common.SelectorOperatorEquals, common.SelectorOperatorIn:
+			if condition, err = labelIn(flavor, queryBuilder, requirement.Key, requirement.Values); err != nil {
+				return err
+			}
+		case common.SelectorOperatorNotEquals, common.SelectorOperatorNotIn:
+			in, inErr := labelIn(flavor, queryBuilder, requirement.Key, requirement.Values)
+			if inErr != nil {
+				return inErr
+			}
+			condition = fmt.Sprintf("(%s IS NULL OR NOT (%s))", labelValue(flavor, queryBuilder, requirement.Key), in)
+		default:
+			return fmt.Errorf("the label selector operator %q is not supported", requirement.Operator)
+		}
+		queryBuilder.Where(condition)
+	}
+	return nil
+}
+
+// generateRevisionSelectQuery is selecting the revisions of a dashboard, or of every dashboard of the project when the name is empty.
+// Contrary to the other queries, the name must be an exact match, otherwise the revisions of the dashboards sharing the same prefix would be returned.
+func generateRevisionSelectQuery(flavor sqlbuilder.Flavor, tableName string, project string, name string) (string, []interface{}) {
+	queryBuilder := flavor.NewSelectBuilder().
+		Select(colDoc).
+		From(tableName)
+	queryBuilder.Where(queryBuilder.Equal(colProject, project))
+	if len(name) > 0 {
+		queryBuilder.Where(queryBuilder.Equal(colName, name))
+	}
 	return queryBuilder.Build()
 }
 
 func (d *DAO) buildQuery(query databaseModel.Query) (string, []interface{}, error) {
-	var sqlQuery string
-	var args []interface{}
+	var queryBuilder *sqlbuilder.SelectBuilder
+	isProjectResource := true
 	switch qt := query.(type) {
+	case *audit.Query:
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableAuditRecord), "", "")
+		isProjectResource = false
 	case *dashboard.Query:
-		sqlQuery, args = generatSelectQuery(d.generateCompleteTableName(tableDashboard), qt.Project, qt.NamePrefix)
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableDashboard), qt.Project, qt.NamePrefix)
+	case *dashboard.RevisionQuery:
+		sqlQuery, args := generateRevisionSelectQuery(d.flavor(), d.generateCompleteTableName(tableDashboardRevision), qt.Project, qt.Name)
+		return sqlQuery, args, nil
 	case *datasource.Query:
-		sqlQuery, args = generatSelectQuery(d.generateCompleteTableName(tableDatasource), qt.Project, qt.NamePrefix)
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableDatasource), qt.Project, qt.NamePrefix)
 	case *folder.Query:
-		sqlQuery, args = generatSelectQuery(d.generateCompleteTableName(tableFolder), qt.Project, qt.NamePrefix)
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableFolder), qt.Project, qt.NamePrefix)
 	case *globaldatasource.Query:
-		sqlQuery, args = generatSelectQuery(d.generateCompleteTableName(tableGlobalDatasource), "", qt.NamePrefix)
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableGlobalDatasource), "", qt.NamePrefix)
+		isProjectResource = false
+	case *globalrole.Query:
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableGlobalRole), "", qt.NamePrefix)
+		isProjectResource = false
+	case *globalrolebinding.Query:
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableGlobalRoleBinding), "", qt.NamePrefix)
+		isProjectResource = false
 	case *globalsecret.Query:
-		sqlQuery, args = generatSelectQuery(d.generateCompleteTableName(tableGlobalSecret), "", qt.NamePrefix)
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableGlobalSecret), "", qt.NamePrefix)
+		isProjectResource = false
 	case *globalvariable.Query:
-		sqlQuery, args = generatSelectQuery(d.generateCompleteTableName(tableGlobalVariable), "", qt.NamePrefix)
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableGlobalVariable), "", qt.NamePrefix)
+		isProjectResource = false
+	case *globalwebhook.Query:
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableGlobalWebhook), "", qt.NamePrefix)
+		isProjectResource = false
 	case *project.Query:
-		sqlQuery, args = generatSelectQuery(d.generateCompleteTableName(tableProject), "", qt.NamePrefix)
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableProject), "", qt.NamePrefix)
+		isProjectResource = false
+	case *role.Query:
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableRole), qt.Project, qt.NamePrefix)
+	case *rolebinding.Query:
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableRoleBinding), qt.Project, qt.NamePrefix)
 	case *secret.Query:
-		sqlQuery, args = generatSelectQuery(d.generateCompleteTableName(tableSecret), qt.Project, qt.NamePrefix)
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableSecret), qt.Project, qt.NamePrefix)
+	case *serviceaccount.Query:
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableServiceAccount), "", qt.NamePrefix)
+		isProjectResource = false
+	case *trash.Query:
+		// the project of an entry is in its document, so the entries are filtered by project once they are read.
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableTrashEntry), "", qt.NamePrefix)
+		isProjectResource = false
+	case *user.Query:
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableUser), "", qt.NamePrefix)
+		isProjectResource = false
 	case *variable.Query:
-		sqlQuery, args = generatSelectQuery(d.generateCompleteTableName(tableVariable), qt.Project, qt.NamePrefix)
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableVariable), qt.Project, qt.NamePrefix)
+	case *webhook.Query:
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableWebhook), qt.Project, qt.NamePrefix)
+	case *webhookdelivery.Query:
+		// the webhook of a delivery is in its document, so the deliveries are filtered by webhook once they are read.
+		queryBuilder = newSelectBuilder(d.flavor(), d.generateCompleteTableName(tableWebhookDelivery), "", "")
+		if len(qt.Status) > 0 {
+			queryBuilder.Where(fmt.Sprintf("%s = %s", deliveryStatus(d.flavor()), queryBuilder.Var(string(qt.Status))))
+		}
+		if !qt.UpdatedBefore.IsZero() {
+			queryBuilder.Where(queryBuilder.LessThan(colUpdatedAt, databaseModel.FormatTime(qt.UpdatedBefore)))
+		}
+		isProjectResource = false
 	default:
 		return "", nil, fmt.Errorf("this type of query '%T' is not managed", qt)
 	}
+	if err := selectLabels(d.flavor(), queryBuilder, query); err != nil {
+		return "", nil, err
+	}
+	if err := paginate(queryBuilder, query, isProjectResource); err != nil {
+		return "", nil, err
+	}
+	sqlQuery, args := queryBuilder.Build()
 	return sqlQuery, args, nil
 }
 
-func generateDeleteQuery(tableName string, project string, name string) (string, []interface{}) {
-	queryBuilder := sqlbuilder.NewDeleteBuilder().
-		DeleteFrom(tableName)
-	if len(name) > 0 {
-		queryBuilder.Where(queryBuilder.Like(colName, fmt.Sprintf("%s%%", name)))
+// deleteScope describes the rows removed by a DeleteByQuery.
+type deleteScope struct {
+	tableName string
+	project   string
+	name      string
+	// isExactName is true when the name must be an exact match rather than a prefix, like for the revisions of a dashboard.
+	isExactName bool
+}
+
+func (s deleteScope) conditions(cond *sqlbuilder.Cond) []string {
+	var result []string
+	if len(s.name) > 0 {
+		if s.isExactName {
+			result = append(result, cond.Equal(colName, s.name))
+		} else {
+			result = append(result, cond.Like(colName, fmt.Sprintf("%s%%", s.name)))
+		}
 	}
-	if len(project) > 0 {
-		queryBuilder.Where(queryBuilder.Equal(colProject, project))
+	if len(s.project) > 0 {
+		result = append(result, cond.Equal(colProject, s.project))
 	}
-	return queryBuilder.Build()
+	return result
 }
 
-func (d *DAO) buildDeleteQuery(query databaseModel.Query) (string, []interface{}, error) {
-	var sqlQuery string
-	var args []interface{}
+func (d *DAO) buildDeleteScope(query databaseModel.Query) (deleteScope, error) {
 	switch qt := query.(type) {
+	case *audit.Query:
+		return deleteScope{tableName: tableAuditRecord}, nil
 	case *dashboard.Query:
-		sqlQuery, args = generateDeleteQuery(d.generateCompleteTableName(tableDashboard), qt.Project, qt.NamePrefix)
+		return deleteScope{tableName: tableDashboard, project: qt.Project, name: qt.NamePrefix}, nil
+	case *dashboard.RevisionQuery:
+		return deleteScope{tableName: tableDashboardRevision, project: qt.Project, name: qt.Name, isExactName: true}, nil
 	case *datasource.Query:
-		sqlQuery, args = generateDeleteQuery(d.generateCompleteTableName(tableDatasource), qt.Project, qt.NamePrefix)
+		return deleteScope{tableName: tableDatasource, project: qt.Project, name: qt.NamePrefix}, nil
 	case *folder.Query:
-		sqlQuery, args = generateDeleteQuery(d.generateCompleteTableName(tableFolder), qt.Project, qt.NamePrefix)
+		return deleteScope{tableName: tableFolder, project: qt.Project, name: qt.NamePrefix}, nil
 	case *globaldatasource.Query:
-		sqlQuery, args = generateDeleteQuery(d.generateCompleteTableName(tableGlobalDatasource), "", qt.NamePrefix)
+		return deleteScope{tableName: tableGlobalDatasource, name: qt.NamePrefix}, nil
+	case *globalrole.Query:
+		return deleteScope{tableName: tableGlobalRole, name: qt.NamePrefix}, nil
+	case *globalrolebinding.Query:
+		return deleteScope{tableName: tableGlobalRoleBinding, name: qt.NamePrefix}, nil
 	case *globalsecret.Query:
-		sqlQuery, args = generateDeleteQuery(d.generateCompleteTableName(tableGlobalSecret), "", qt.NamePrefix)
+		return deleteScope{tableName: tableGlobalSecret, name: qt.NamePrefix}, nil
 	case *globalvariable.Query:
-		sqlQuery, args = generateDeleteQuery(d.generateCompleteTableName(tableGlobalVariable), "", qt.NamePrefix)
+		return deleteScope{tableName: tableGlobalVariable, name: qt.NamePrefix}, nil
+	case *globalwebhook.Query:
+		return deleteScope{tableName: tableGlobalWebhook, name: qt.NamePrefix}, nil
 	case *project.Query:
-		sqlQuery, args = generateDeleteQuery(d.generateCompleteTableName(tableProject), "", qt.NamePrefix)
+		return deleteScope{tableName: tableProject, name: qt.NamePrefix}, nil
+	case *role.Query:
+		return deleteScope{tableName: tableRole, project: qt.Project, name: qt.NamePrefix}, nil
+	case *rolebinding.Query:
+		return deleteScope{tableName: tableRoleBinding, project: qt.Project, name: qt.NamePrefix}, nil
 	case *secret.Query:
-		sqlQuery, args = generateDeleteQuery(d.generateCompleteTableName(tableSecret), qt.Project, qt.NamePrefix)
+		return deleteScope{tableName: tableSecret, project: qt.Project, name: qt.NamePrefix}, nil
+	case *serviceaccount.Query:
+		return deleteScope{tableName: tableServiceAccount, name: qt.NamePrefix}, nil
+	case *trash.Query:
+		return deleteScope{tableName: tableTrashEntry, name: qt.NamePrefix}, nil
+	case *user.Query:
+		return deleteScope{tableName: tableUser, name: qt.NamePrefix}, nil
 	case *variable.Query:
-		sqlQuery, args = generateDeleteQuery(d.generateCompleteTableName(tableVariable), qt.Project, qt.NamePrefix)
+		return deleteScope{tableName: tableVariable, project: qt.Project, name: qt.NamePrefix}, nil
+	case *webhook.Query:
+		return deleteScope{tableName: tableWebhook, project: qt.Project, name: qt.NamePrefix}, nil
 	default:
-		return "", nil, fmt.Errorf("this type of query '%T' is not managed", qt)
+		return deleteScope{}, fmt.Errorf("this type of query '%T' is not managed", qt)
 	}
-	return sqlQuery, args, nil
+}
+
+func (d *DAO) buildDeleteQuery(scope deleteScope) (string, []interface{}) {
+	queryBuilder := d.flavor().NewDeleteBuilder().
+		DeleteFrom(d.generateCompleteTableName(scope.tableName))
+	if conditions := scope.conditions(&queryBuilder.Cond); len(conditions) > 0 {
+		queryBuilder.Where(conditions...)
+	}
+	return queryBuilder.Build()
+}
+
+// buildDeletedSelectQuery selects the documents that are removed by the delete query of the same scope.
+func (d *DAO) buildDeletedSelectQuery(scope deleteScope) (string, []interface{}) {
+	queryBuilder := d.flavor().NewSelectBuilder().
+		Select(colDoc).
+		From(d.generateCompleteTableName(scope.tableName))
+	if conditions := scope.conditions(&queryBuilder.Cond); len(conditions) > 0 {
+		queryBuilder.Where(conditions...)
+	}
+	return queryBuilder.Build()
 }
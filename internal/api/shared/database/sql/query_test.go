@@ -16,12 +16,16 @@ package databasesql
 import (
 	"testing"
 
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/perses/perses/internal/api/interface/v1/dashboard"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestGenerateProjectResourceSelectQuery(t *testing.T) {
 	testSuite := []struct {
 		title    string
+		flavor   sqlbuilder.Flavor
 		project  string
 		name     string
 		sqlQuery string
@@ -29,6 +33,7 @@ func TestGenerateProjectResourceSelectQuery(t *testing.T) {
 	}{
 		{
 			title:    "no project with a prefix name",
+			flavor:   sqlbuilder.MySQL,
 			project:  "",
 			name:     "test",
 			sqlQuery: "SELECT doc FROM perses.dashboard WHERE name LIKE ?",
@@ -36,6 +41,7 @@ func TestGenerateProjectResourceSelectQuery(t *testing.T) {
 		},
 		{
 			title:    "with a prefix name",
+			flavor:   sqlbuilder.MySQL,
 			project:  "",
 			name:     "test",
 			sqlQuery: "SELECT doc FROM perses.dashboard WHERE name LIKE ?",
@@ -43,6 +49,7 @@ func TestGenerateProjectResourceSelectQuery(t *testing.T) {
 		},
 		{
 			title:    "a project with a prefix name",
+			flavor:   sqlbuilder.MySQL,
 			project:  "foo",
 			name:     "bar",
 			sqlQuery: "SELECT doc FROM perses.dashboard WHERE name LIKE ? AND project = ?",
@@ -50,15 +57,73 @@ func TestGenerateProjectResourceSelectQuery(t *testing.T) {
 		},
 		{
 			title:    "empty query",
+			flavor:   sqlbuilder.MySQL,
 			project:  "",
 			name:     "",
 			sqlQuery: "SELECT doc FROM perses.dashboard",
 		},
+		{
+			title:    "postgres: a project with a prefix name",
+			flavor:   sqlbuilder.PostgreSQL,
+			project:  "foo",
+			name:     "bar",
+			sqlQuery: "SELECT doc FROM perses.dashboard WHERE name LIKE $1 AND project = $2",
+			sqlArgs:  []interface{}{"bar%", "foo"},
+		},
+		{
+			title:    "postgres: empty query",
+			flavor:   sqlbuilder.PostgreSQL,
+			project:  "",
+			name:     "",
+			sqlQuery: "SELECT doc FROM perses.dashboard",
+		},
+	}
+
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			sqlQuery, args := generatSelectQuery(test.flavor, "perses.dashboard", test.project, test.name)
+			assert.Equal(t, test.sqlQuery, sqlQuery)
+			assert.Equal(t, test.sqlArgs, args)
+		})
 	}
+}
 
+func TestSelectLabels(t *testing.T) {
+	testSuite := []struct {
+		title    string
+		flavor   sqlbuilder.Flavor
+		selector string
+		sqlQuery string
+		sqlArgs  []interface{}
+	}{
+		{
+			title:    "mysql: equality and existence",
+			flavor:   sqlbuilder.MySQL,
+			selector: "team=sre,!perses.dev/tier",
+			sqlQuery: "SELECT doc FROM perses.dashboard WHERE JSON_UNQUOTE(JSON_EXTRACT(doc, ?)) IN (?) AND JSON_UNQUOTE(JSON_EXTRACT(doc, ?)) IS NULL",
+			sqlArgs:  []interface{}{`$.metadata.labels."team"`, "sre", `$.metadata.labels."perses.dev/tier"`},
+		},
+		{
+			title:    "sqlite: set based",
+			flavor:   sqlbuilder.SQLite,
+			selector: "team notin (sre,dev)",
+			sqlQuery: "SELECT doc FROM perses.dashboard WHERE (json_extract(doc, ?) IS NULL OR NOT (json_extract(doc, ?) IN (?, ?)))",
+			sqlArgs:  []interface{}{`$.metadata.labels."team"`, `$.metadata.labels."team"`, "sre", "dev"},
+		},
+		{
+			title:    "postgres: equality uses the containment",
+			flavor:   sqlbuilder.PostgreSQL,
+			selector: "team in (sre,dev),tier",
+			sqlQuery: "SELECT doc FROM perses.dashboard WHERE (doc->'metadata'->'labels' @> CAST($1 AS JSONB) OR doc->'metadata'->'labels' @> CAST($2 AS JSONB)) AND (doc->'metadata'->'labels'->>$3) IS NOT NULL",
+			sqlArgs:  []interface{}{`{"team":"sre"}`, `{"team":"dev"}`, "tier"},
+		},
+	}
 	for _, test := range testSuite {
 		t.Run(test.title, func(t *testing.T) {
-			sqlQuery, args := generatSelectQuery("perses.dashboard", test.project, test.name)
+			queryBuilder := newSelectBuilder(test.flavor, "perses.dashboard", "", "")
+			q := &dashboard.Query{LabelSelection: databaseModel.LabelSelection{LabelSelector: test.selector}}
+			assert.NoError(t, selectLabels(test.flavor, queryBuilder, q))
+			sqlQuery, args := queryBuilder.Build()
 			assert.Equal(t, test.sqlQuery, sqlQuery)
 			assert.Equal(t, test.sqlArgs, args)
 		})
@@ -16,53 +16,112 @@ package databasesql
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/huandu/go-sqlbuilder"
+	"github.com/lib/pq"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
 	modelAPI "github.com/perses/perses/pkg/model/api"
 	modelV1 "github.com/perses/perses/pkg/model/api/v1"
 	"github.com/sirupsen/logrus"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
 const (
-	tableGlobalDatasource = "globaldatasource"
-	tableGlobalSecret     = "globalsecret"
-	tableGlobalVariable   = "globalvariable"
-	tableProject          = "project"
-	tableDashboard        = "dashboard"
-	tableFolder           = "folder"
-	tableDatasource       = "datasource"
-	tableSecret           = "secret"
-	tableVariable         = "variable"
-
-	colID      = "id"
-	colDoc     = "doc"
-	colName    = "name"
-	colProject = "project"
+	tableAuditRecord       = "auditrecord"
+	tableGlobalDatasource  = "globaldatasource"
+	tableGlobalRole        = "globalrole"
+	tableGlobalRoleBinding = "globalrolebinding"
+	tableGlobalSecret      = "globalsecret"
+	tableGlobalVariable    = "globalvariable"
+	tableGlobalWebhook     = "globalwebhook"
+	tableProject           = "project"
+	tableDashboard         = "dashboard"
+	tableDashboardRevision = "dashboardrevision"
+	tableFolder            = "folder"
+	tableRole              = "role"
+	tableRoleBinding       = "rolebinding"
+	tableDatasource        = "datasource"
+	tableSecret            = "secret"
+	tableServiceAccount    = "serviceaccount"
+	tableTrashEntry        = "trashentry"
+	tableUser              = "users"
+	tableVariable          = "variable"
+	tableWebhook           = "webhook"
+	tableWebhookDelivery   = "webhookdelivery"
+
+	colID        = "id"
+	colDoc       = "doc"
+	colName      = "name"
+	colProject   = "project"
+	colCreatedAt = "created_at"
+	colUpdatedAt = "updated_at"
 )
 
+// resourceTables contains every table storing a resource when the date columns have been added.
+// The tables created afterwards are created with these columns.
+var resourceTables = []string{
+	tableGlobalDatasource,
+	tableGlobalSecret,
+	tableGlobalVariable,
+	tableProject,
+	tableDashboard,
+	tableDashboardRevision,
+	tableFolder,
+	tableDatasource,
+	tableSecret,
+	tableVariable,
+}
+
 func getTableName(kind modelV1.Kind) (string, error) {
 	switch kind {
+	case modelV1.KindAuditRecord:
+		return tableAuditRecord, nil
 	case modelV1.KindDashboard:
 		return tableDashboard, nil
+	case modelV1.KindDashboardRevision:
+		return tableDashboardRevision, nil
 	case modelV1.KindDatasource:
 		return tableDatasource, nil
 	case modelV1.KindFolder:
 		return tableFolder, nil
 	case modelV1.KindGlobalDatasource:
 		return tableGlobalDatasource, nil
+	case modelV1.KindGlobalRole:
+		return tableGlobalRole, nil
+	case modelV1.KindGlobalRoleBinding:
+		return tableGlobalRoleBinding, nil
 	case modelV1.KindGlobalSecret:
 		return tableGlobalSecret, nil
 	case modelV1.KindGlobalVariable:
 		return tableGlobalVariable, nil
+	case modelV1.KindGlobalWebhook:
+		return tableGlobalWebhook, nil
 	case modelV1.KindProject:
 		return tableProject, nil
+	case modelV1.KindRole:
+		return tableRole, nil
+	case modelV1.KindRoleBinding:
+		return tableRoleBinding, nil
 	case modelV1.KindSecret:
 		return tableSecret, nil
+	case modelV1.KindServiceAccount:
+		return tableServiceAccount, nil
+	case modelV1.KindTrashEntry:
+		return tableTrashEntry, nil
+	case modelV1.KindUser:
+		return tableUser, nil
 	case modelV1.KindVariable:
 		return tableVariable, nil
+	case modelV1.KindWebhook:
+		return tableWebhook, nil
+	case modelV1.KindWebhookDelivery:
+		return tableWebhookDelivery, nil
 	default:
 		return "", fmt.Errorf("%q has no associated table", kind)
 	}
@@ -70,6 +129,8 @@ func getTableName(kind modelV1.Kind) (string, error) {
 
 func generateID(metadata modelAPI.Metadata) (string, error) {
 	switch m := metadata.(type) {
+	case *modelV1.RevisionMetadata:
+		return fmt.Sprintf("%s|%s|%d", m.Project, m.Name, m.Version), nil
 	case *modelV1.ProjectMetadata:
 		return fmt.Sprintf("%s|%s", m.Project, m.Name), nil
 	case *modelV1.Metadata:
@@ -78,64 +139,100 @@ func generateID(metadata modelAPI.Metadata) (string, error) {
 	return "", fmt.Errorf("metadata %T not managed", metadata)
 }
 
+// versionedDocument is used to decode only the version of a document stored.
+type versionedDocument struct {
+	Metadata struct {
+		Version uint64 `json:"version"`
+	} `json:"metadata"`
+}
+
 type DAO struct {
 	databaseModel.DAO
 	DB         *sql.DB
 	SchemaName string
+	// Flavor is the SQL dialect used to generate the different queries. MySQL is used if not set.
+	Flavor sqlbuilder.Flavor
+	// PollInterval is the interval between two reads of the changes recorded in the database, to send them to the watchers.
+	PollInterval time.Duration
+	// ChangeRetention is how long a change is kept in the database.
+	ChangeRetention time.Duration
+	// feed sends the changes to the watchers. It is started by Init, so it is nil when the DAO is bound to a transaction.
+	feed *changeFeed
+	// tx is set when the DAO is bound to a transaction. In this case, every query is executed within this transaction.
+	tx queryer
 }
 
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Init creates the schema if needed and applies the migrations that have not been applied yet.
 func (d *DAO) Init() error {
-	tables := []string{
-		d.createResourceTable(tableGlobalDatasource),
-		d.createResourceTable(tableGlobalSecret),
-		d.createResourceTable(tableGlobalVariable),
-		d.createResourceTable(tableProject),
-
-		d.createProjectResourceTable(tableDashboard),
-		d.createProjectResourceTable(tableFolder),
-		d.createProjectResourceTable(tableDatasource),
-		d.createProjectResourceTable(tableSecret),
-		d.createProjectResourceTable(tableVariable),
-	}
-
-	for _, table := range tables {
-		if err := d.createTable(table); err != nil {
-			return err
-		}
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	if migrateErr := d.migrate(migrations); migrateErr != nil {
+		return migrateErr
+	}
+	if d.tx == nil && d.feed == nil {
+		d.feed = newChangeFeed(d)
+		go d.feed.run()
 	}
 	return nil
 }
 
-func (d *DAO) createResourceTable(tableName string) string {
-	return sqlbuilder.CreateTable(d.generateCompleteTableName(tableName)).IfNotExists().
-		Define(colID, "VARCHAR(128)", "NOT NULL", "PRIMARY KEY").
-		Define(colName, "VARCHAR(128)", "NOT NULL").
-		Define(colDoc, "JSON", "NOT NULL").
-		String()
-}
-
-func (d *DAO) createProjectResourceTable(tableName string) string {
-	return sqlbuilder.CreateTable(d.generateCompleteTableName(tableName)).IfNotExists().
-		Define(colID, "VARCHAR(256)", "NOT NULL", "PRIMARY KEY").
-		Define(colName, "VARCHAR(128)", "NOT NULL").
-		Define(colProject, "VARCHAR(128)", "NOT NULL").
-		Define(colDoc, "JSON", "NOT NULL").
-		String()
+// docType returns the type of the column used to store the JSON document.
+// PostgreSQL is using JSONB as it is stored in a decomposed binary format that is faster to process and that can be indexed.
+func (d *DAO) docType() string {
+	switch d.flavor() {
+	case sqlbuilder.PostgreSQL:
+		return "JSONB"
+	case sqlbuilder.SQLite:
+		// SQLite doesn't have a JSON type. JSON documents are stored as text.
+		return "TEXT"
+	default:
+		return "JSON"
+	}
 }
 
-func (d *DAO) createTable(query string) error {
-	r, e := d.DB.Query(query)
-	if e != nil {
-		return e
+func (d *DAO) flavor() sqlbuilder.Flavor {
+	if d.Flavor == 0 {
+		return sqlbuilder.MySQL
 	}
-	return r.Close()
+	return d.Flavor
 }
 
 func (d *DAO) Close() error {
+	if d.tx != nil {
+		// the connection is owned by the DAO that started the transaction.
+		return nil
+	}
+	if d.feed != nil {
+		d.feed.close()
+		d.feed = nil
+	}
 	return d.DB.Close()
 }
 
+// conn returns the transaction when the DAO is bound to one, otherwise the database itself.
+func (d *DAO) conn() queryer {
+	if d.tx != nil {
+		return d.tx
+	}
+	return d.DB
+}
+
 func (d *DAO) Create(entity modelAPI.Entity) error {
+	return d.inTransaction(func(tx *DAO) error {
+		return tx.create(entity)
+	})
+}
+
+func (d *DAO) create(entity modelAPI.Entity) error {
 	id, isExist, err := d.exists(modelV1.Kind(entity.GetKind()), entity.GetMetadata())
 	if err != nil {
 		return err
@@ -149,34 +246,146 @@ func (d *DAO) Create(entity modelAPI.Entity) error {
 		return queryErr
 	}
 
-	createQuery, createErr := d.DB.Query(sqlQuery, args...)
+	createQuery, createErr := d.conn().Query(sqlQuery, args...)
 	if createErr != nil {
-		return createErr
+		return handleInsertError(id, createErr)
+	}
+	if closeErr := createQuery.Close(); closeErr != nil {
+		return closeErr
 	}
-	return createQuery.Close()
+	return d.recordEntityChange(modelV1.EventTypeAdded, entity)
+}
+
+// handleInsertError returns a conflict when the row has been inserted by another request since its existence has been checked,
+// i.e. when the error is the violation of the primary key. Otherwise, the error is returned as is.
+func handleInsertError(id string, err error) error {
+	if isUniqueViolation(err) {
+		return &databaseModel.Error{Key: id, Code: databaseModel.ErrorCodeConflict}
+	}
+	return err
+}
+
+// isUniqueViolation returns true if the error is the violation of a primary key or of a unique index, whatever the database.
+func isUniqueViolation(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		// ER_DUP_ENTRY
+		return mysqlErr.Number == 1062
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// unique_violation
+		return pqErr.Code == "23505"
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+	}
+	return false
 }
 
 func (d *DAO) Upsert(entity modelAPI.Entity) error {
-	_, isExist, err := d.exists(modelV1.Kind(entity.GetKind()), entity.GetMetadata())
+	return d.inTransaction(func(tx *DAO) error {
+		return tx.upsert(entity)
+	})
+}
+
+func (d *DAO) upsert(entity modelAPI.Entity) error {
+	id, isExist, err := d.exists(modelV1.Kind(entity.GetKind()), entity.GetMetadata())
 	if err != nil {
 		return err
 	}
 	var sqlQuery string
 	var args []interface{}
 	var queryGeneratorErr error
+	eventType := modelV1.EventTypeModified
 	if !isExist {
 		sqlQuery, args, queryGeneratorErr = d.generateInsertQuery(entity)
+		eventType = modelV1.EventTypeAdded
 	} else {
 		sqlQuery, args, queryGeneratorErr = d.generateUpdateQuery(entity)
 	}
 	if queryGeneratorErr != nil {
 		return queryGeneratorErr
 	}
-	upsertQuery, upsertErr := d.DB.Query(sqlQuery, args...)
+	upsertQuery, upsertErr := d.conn().Query(sqlQuery, args...)
 	if upsertErr != nil {
-		return upsertErr
+		return handleInsertError(id, upsertErr)
 	}
-	return upsertQuery.Close()
+	if closeErr := upsertQuery.Close(); closeErr != nil {
+		return closeErr
+	}
+	return d.recordEntityChange(eventType, entity)
+}
+
+func (d *DAO) Update(entity modelAPI.Entity, expectedVersion uint64) error {
+	return d.inTransaction(func(tx *DAO) error {
+		return tx.update(entity, expectedVersion)
+	})
+}
+
+func (d *DAO) update(entity modelAPI.Entity, expectedVersion uint64) error {
+	id, tableName, idErr := d.getIDAndTableName(modelV1.Kind(entity.GetKind()), entity.GetMetadata())
+	if idErr != nil {
+		return idErr
+	}
+	queryBuilder := d.flavor().NewSelectBuilder().
+		Select(colDoc).
+		From(tableName)
+	queryBuilder.Where(queryBuilder.Equal(colID, id))
+	if d.flavor() != sqlbuilder.SQLite {
+		// Lock the row until the end of the transaction, so it cannot be modified between the check of the version and the update.
+		// SQLite doesn't support it, but there it isn't needed as only one connection is used and so only one transaction can run at a time.
+		queryBuilder.ForUpdate()
+	}
+	sqlQuery, args := queryBuilder.Build()
+	var rowJSONDoc string
+	if scanErr := d.conn().QueryRow(sqlQuery, args...).Scan(&rowJSONDoc); scanErr != nil {
+		if errors.Is(scanErr, sql.ErrNoRows) {
+			return &databaseModel.Error{Key: id, Code: databaseModel.ErrorCodeNotFound}
+		}
+		return scanErr
+	}
+	stored := &versionedDocument{}
+	if unmarshalErr := json.Unmarshal([]byte(rowJSONDoc), stored); unmarshalErr != nil {
+		return unmarshalErr
+	}
+	if stored.Metadata.Version != expectedVersion {
+		return &databaseModel.Error{Key: id, Code: databaseModel.ErrorCodeConflict}
+	}
+
+	updateQuery, updateArgs, queryGeneratorErr := d.generateUpdateQuery(entity)
+	if queryGeneratorErr != nil {
+		return queryGeneratorErr
+	}
+	if _, execErr := d.conn().Exec(updateQuery, updateArgs...); execErr != nil {
+		return execErr
+	}
+	return d.recordEntityChange(modelV1.EventTypeModified, entity)
+}
+
+func (d *DAO) Transaction(f func(tx databaseModel.DAO) error) error {
+	return d.inTransaction(func(tx *DAO) error {
+		return f(tx)
+	})
+}
+
+// inTransaction runs f with a DAO bound to a transaction. The transaction is committed if f succeeds, otherwise it is rolled back.
+// When the DAO is already bound to a transaction, f joins it.
+func (d *DAO) inTransaction(f func(tx *DAO) error) error {
+	if d.tx != nil {
+		return f(d)
+	}
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback does nothing once the transaction is committed.
+	defer tx.Rollback() // nolint: errcheck
+	if txErr := f(&DAO{DB: d.DB, SchemaName: d.SchemaName, Flavor: d.Flavor, PollInterval: d.PollInterval, ChangeRetention: d.ChangeRetention, tx: tx}); txErr != nil {
+		return txErr
+	}
+	return tx.Commit()
 }
 
 func (d *DAO) Get(kind modelV1.Kind, metadata modelAPI.Metadata, entity modelAPI.Entity) error {
@@ -215,15 +424,21 @@ func (d *DAO) Query(query databaseModel.Query, slice interface{}) error {
 	if typeParameter.Kind() != reflect.Slice {
 		return fmt.Errorf("slice in parameter is not actually a slice but a %q", typeParameter.Kind())
 	}
+	var pagination *databaseModel.Pagination
+	if paginatedQuery, ok := query.(databaseModel.PaginatedQuery); ok {
+		pagination = paginatedQuery.GetPagination()
+		pagination.ResetNext()
+	}
 	q, args, buildQueryErr := d.buildQuery(query)
 	if buildQueryErr != nil {
 		return fmt.Errorf("unable to build the query: %s", buildQueryErr)
 	}
-	rows, runQueryErr := d.DB.Query(q, args...)
+	rows, runQueryErr := d.conn().Query(q, args...)
 	if runQueryErr != nil {
 		return runQueryErr
 	}
 	defer rows.Close()
+	sliceElem = reflect.MakeSlice(typeParameter, 0, 0)
 	for rows.Next() {
 		var rowJSONDoc string
 		if scanErr := rows.Scan(&rowJSONDoc); scanErr != nil {
@@ -245,14 +460,23 @@ func (d *DAO) Query(query databaseModel.Query, slice interface{}) error {
 		if typeParameter.Elem().Kind() != reflect.Ptr {
 			// In case the type of the slice element is not a pointer,
 			// we should return the value of the pointer created in the previous step.
-			sliceElem.Set(reflect.Append(sliceElem, value.Elem()))
+			sliceElem = reflect.Append(sliceElem, value.Elem())
 		} else {
-			sliceElem.Set(reflect.Append(sliceElem, value))
+			sliceElem = reflect.Append(sliceElem, value)
 		}
 	}
-	if sliceElem.Len() == 0 {
-		// in case the result is empty, let's initialize the slice just to avoid returning a nil slice
-		sliceElem = reflect.MakeSlice(typeParameter, 0, 0)
+	if pagination != nil && pagination.Limit > 0 && sliceElem.Len() > pagination.Limit {
+		// one more row than the limit has been selected, which means there is a next page.
+		sliceElem = sliceElem.Slice(0, pagination.Limit)
+		last := sliceElem.Index(pagination.Limit - 1)
+		if last.Kind() != reflect.Ptr {
+			last = last.Addr()
+		}
+		if entity, isEntity := last.Interface().(modelAPI.Entity); isEntity {
+			if nextErr := pagination.SetNext(entity); nextErr != nil {
+				return nextErr
+			}
+		}
 	}
 	// at the end reset the element of the slice to ensure we didn't disconnect the link between the pointer to the slice and the actual slice
 	result.Elem().Set(sliceElem)
@@ -260,9 +484,27 @@ func (d *DAO) Query(query databaseModel.Query, slice interface{}) error {
 }
 
 func (d *DAO) Delete(kind modelV1.Kind, metadata modelAPI.Metadata) error {
-	id, isExist, err := d.exists(kind, metadata)
-	if err != nil {
-		return err
+	return d.inTransaction(func(tx *DAO) error {
+		return tx.delete(kind, metadata)
+	})
+}
+
+func (d *DAO) delete(kind modelV1.Kind, metadata modelAPI.Metadata) error {
+	id, query, queryErr := d.get(kind, metadata)
+	if queryErr != nil {
+		return queryErr
+	}
+	// the document is kept to be sent to the watchers.
+	var rowJSONDoc string
+	isExist := query.Next()
+	if isExist {
+		if scanErr := query.Scan(&rowJSONDoc); scanErr != nil {
+			_ = query.Close()
+			return scanErr
+		}
+	}
+	if closeErr := query.Close(); closeErr != nil {
+		return closeErr
 	}
 	if !isExist {
 		return &databaseModel.Error{Key: id, Code: databaseModel.ErrorCodeNotFound}
@@ -273,27 +515,67 @@ func (d *DAO) Delete(kind modelV1.Kind, metadata modelAPI.Metadata) error {
 		return idErr
 	}
 
-	deleteBuilder := sqlbuilder.NewDeleteBuilder().DeleteFrom(tableName)
+	deleteBuilder := d.flavor().NewDeleteBuilder().DeleteFrom(tableName)
 	deleteBuilder.Where(deleteBuilder.Equal(colID, id))
 	sqlQuery, args := deleteBuilder.Build()
 
-	deleteQuery, err := d.DB.Query(sqlQuery, args...)
-	if err != nil {
+	if _, err := d.conn().Exec(sqlQuery, args...); err != nil {
 		return err
 	}
-	return deleteQuery.Close()
+	return d.recordChange(modelV1.EventTypeDeleted, []byte(rowJSONDoc))
 }
 
 func (d *DAO) DeleteByQuery(query databaseModel.Query) error {
-	q, args, buildQueryErr := d.buildDeleteQuery(query)
-	if buildQueryErr != nil {
-		return fmt.Errorf("unable to build the query: %s", buildQueryErr)
+	scope, scopeErr := d.buildDeleteScope(query)
+	if scopeErr != nil {
+		return fmt.Errorf("unable to build the query: %s", scopeErr)
 	}
-	rows, runQueryErr := d.DB.Query(q, args...)
-	if runQueryErr != nil {
-		return runQueryErr
+	return d.inTransaction(func(tx *DAO) error {
+		return tx.deleteByScope(scope)
+	})
+}
+
+func (d *DAO) deleteByScope(scope deleteScope) error {
+	// the documents are kept to be sent to the watchers.
+	selectQuery, selectArgs := d.buildDeletedSelectQuery(scope)
+	rows, err := d.conn().Query(selectQuery, selectArgs...)
+	if err != nil {
+		return err
+	}
+	var documents []string
+	for rows.Next() {
+		var rowJSONDoc string
+		if scanErr := rows.Scan(&rowJSONDoc); scanErr != nil {
+			_ = rows.Close()
+			return scanErr
+		}
+		documents = append(documents, rowJSONDoc)
+	}
+	if closeErr := rows.Close(); closeErr != nil {
+		return closeErr
+	}
+	if len(documents) == 0 {
+		return nil
+	}
+	deleteQuery, deleteArgs := d.buildDeleteQuery(scope)
+	if _, execErr := d.conn().Exec(deleteQuery, deleteArgs...); execErr != nil {
+		return execErr
+	}
+	for _, rowJSONDoc := range documents {
+		if recordErr := d.recordChange(modelV1.EventTypeDeleted, []byte(rowJSONDoc)); recordErr != nil {
+			return recordErr
+		}
+	}
+	return nil
+}
+
+// recordEntityChange records the change of the entity. See recordChange.
+func (d *DAO) recordEntityChange(eventType modelV1.EventType, entity modelAPI.Entity) error {
+	rowJSONDoc, err := json.Marshal(entity)
+	if err != nil {
+		return err
 	}
-	return rows.Close()
+	return d.recordChange(eventType, rowJSONDoc)
 }
 
 func (d *DAO) HealthCheck() bool {
@@ -336,12 +618,12 @@ func (d *DAO) get(kind modelV1.Kind, metadata modelAPI.Metadata) (string, *sql.R
 		return "", nil, idErr
 	}
 
-	queryBuilder := sqlbuilder.NewSelectBuilder().
+	queryBuilder := d.flavor().NewSelectBuilder().
 		Select(colDoc).
 		From(tableName)
 	queryBuilder.Where(queryBuilder.Equal(colID, id))
 	sqlQuery, args := queryBuilder.Build()
 
-	rows, err := d.DB.Query(sqlQuery, args...)
+	rows, err := d.conn().Query(sqlQuery, args...)
 	return id, rows, err
 }
@@ -0,0 +1,338 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasesql
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/perses/perses/internal/api/interface/v1/project"
+	"github.com/perses/perses/internal/api/interface/v1/secret"
+	"github.com/perses/perses/internal/api/interface/v1/trash"
+	"github.com/perses/perses/internal/api/interface/v1/webhookdelivery"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	secretModel "github.com/perses/perses/pkg/model/api/v1/secret"
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+// newDAO returns a DAO backed by an SQLite database stored in a temporary folder.
+// It is the easiest way to test the SQL queries without running an external database.
+func newDAO(t *testing.T) *DAO {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "perses.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	d := &DAO{
+		DB:         db,
+		SchemaName: "main",
+		Flavor:     sqlbuilder.SQLite,
+		// the changes are read often, so the tests watching them don't have to wait.
+		PollInterval: 10 * time.Millisecond,
+	}
+	if initErr := d.Init(); initErr != nil {
+		t.Fatal(initErr)
+	}
+	t.Cleanup(func() {
+		_ = d.Close()
+	})
+	return d
+}
+
+func newProject(name string) *modelV1.Project {
+	return &modelV1.Project{
+		Kind: modelV1.KindProject,
+		Metadata: modelV1.Metadata{
+			Name: name,
+		},
+	}
+}
+
+func newSecret(project string, name string) *modelV1.Secret {
+	return &modelV1.Secret{
+		Kind: modelV1.KindSecret,
+		Metadata: modelV1.ProjectMetadata{
+			Metadata: modelV1.Metadata{
+				Name: name,
+			},
+			Project: project,
+		},
+		Spec: modelV1.SecretSpec{
+			BasicAuth: &secretModel.BasicAuth{
+				Username: "user",
+				Password: "password",
+			},
+		},
+	}
+}
+
+func TestDAO_Create(t *testing.T) {
+	d := newDAO(t)
+	projectEntity := newProject("perses")
+	assert.NoError(t, d.Create(projectEntity))
+	assert.True(t, databaseModel.IsKeyConflict(d.Create(projectEntity)))
+}
+
+func TestDAO_CreateConcurrently(t *testing.T) {
+	d := newDAO(t)
+	projectEntity := newProject("perses")
+	assert.NoError(t, d.Create(projectEntity))
+	// the row inserted by another request between the check of its existence and the insertion is a conflict too.
+	sqlQuery, args, err := d.generateInsertQuery(projectEntity)
+	assert.NoError(t, err)
+	_, insertErr := d.conn().Exec(sqlQuery, args...)
+	assert.True(t, isUniqueViolation(insertErr))
+	assert.True(t, databaseModel.IsKeyConflict(handleInsertError("perses", insertErr)))
+}
+
+func TestDAO_Upsert(t *testing.T) {
+	d := newDAO(t)
+	projectEntity := newProject("perses")
+	assert.NoError(t, d.Upsert(projectEntity))
+	projectEntity.Metadata.Version = 1
+	assert.NoError(t, d.Upsert(projectEntity))
+	result := &modelV1.Project{}
+	assert.NoError(t, d.Get(modelV1.KindProject, projectEntity.GetMetadata(), result))
+	assert.Equal(t, uint64(1), result.Metadata.Version)
+}
+
+func TestDAO_Update(t *testing.T) {
+	d := newDAO(t)
+	projectEntity := newProject("perses")
+	assert.True(t, databaseModel.IsKeyNotFound(d.Update(projectEntity, 0)))
+	assert.NoError(t, d.Create(projectEntity))
+	projectEntity.Metadata.Version = 1
+	assert.NoError(t, d.Update(projectEntity, 0))
+	// the version stored is now 1, so an update based on the version 0 must be rejected
+	assert.True(t, databaseModel.IsKeyConflict(d.Update(projectEntity, 0)))
+	projectEntity.Metadata.Version = 2
+	assert.NoError(t, d.Update(projectEntity, 1))
+	result := &modelV1.Project{}
+	assert.NoError(t, d.Get(modelV1.KindProject, projectEntity.GetMetadata(), result))
+	assert.Equal(t, uint64(2), result.Metadata.Version)
+}
+
+func TestDAO_Query(t *testing.T) {
+	d := newDAO(t)
+	assert.NoError(t, d.Create(newSecret("perses", "foo")))
+	assert.NoError(t, d.Create(newSecret("perses", "bar")))
+	assert.NoError(t, d.Create(newSecret("another", "foo")))
+
+	var result []*modelV1.Secret
+	assert.NoError(t, d.Query(&secret.Query{Project: "perses"}, &result))
+	assert.Len(t, result, 2)
+
+	var prefixResult []*modelV1.Secret
+	assert.NoError(t, d.Query(&secret.Query{NamePrefix: "fo"}, &prefixResult))
+	assert.Len(t, prefixResult, 2)
+
+	var emptyResult []modelV1.Project
+	assert.NoError(t, d.Query(&project.Query{}, &emptyResult))
+	assert.NotNil(t, emptyResult)
+	assert.Len(t, emptyResult, 0)
+}
+
+func TestDAO_Delete(t *testing.T) {
+	d := newDAO(t)
+	projectEntity := newProject("perses")
+	assert.NoError(t, d.Create(projectEntity))
+	assert.NoError(t, d.Delete(modelV1.KindProject, projectEntity.GetMetadata()))
+	result := &modelV1.Project{}
+	assert.True(t, databaseModel.IsKeyNotFound(d.Get(modelV1.KindProject, projectEntity.GetMetadata(), result)))
+	assert.True(t, databaseModel.IsKeyNotFound(d.Delete(modelV1.KindProject, projectEntity.GetMetadata())))
+}
+
+func TestDAO_DeleteByQuery(t *testing.T) {
+	d := newDAO(t)
+	assert.NoError(t, d.Create(newSecret("perses", "foo")))
+	assert.NoError(t, d.Create(newSecret("another", "foo")))
+	assert.NoError(t, d.DeleteByQuery(&secret.Query{Project: "perses"}))
+
+	var result []*modelV1.Secret
+	assert.NoError(t, d.Query(&secret.Query{}, &result))
+	assert.Len(t, result, 1)
+	assert.Equal(t, "another", result[0].Metadata.Project)
+}
+
+func TestDAO_Transaction(t *testing.T) {
+	d := newDAO(t)
+	projectEntity := newProject("perses")
+	assert.NoError(t, d.Create(projectEntity))
+	assert.NoError(t, d.Create(newSecret("perses", "foo")))
+
+	// the transaction fails, so the secrets must still be there
+	err := d.Transaction(func(tx databaseModel.DAO) error {
+		if err := tx.DeleteByQuery(&secret.Query{Project: "perses"}); err != nil {
+			return err
+		}
+		return fmt.Errorf("something went wrong")
+	})
+	assert.Error(t, err)
+	var result []*modelV1.Secret
+	assert.NoError(t, d.Query(&secret.Query{Project: "perses"}, &result))
+	assert.Len(t, result, 1)
+
+	err = d.Transaction(func(tx databaseModel.DAO) error {
+		if err := tx.DeleteByQuery(&secret.Query{Project: "perses"}); err != nil {
+			return err
+		}
+		return tx.Delete(modelV1.KindProject, projectEntity.GetMetadata())
+	})
+	assert.NoError(t, err)
+	var emptyResult []*modelV1.Secret
+	assert.NoError(t, d.Query(&secret.Query{Project: "perses"}, &emptyResult))
+	assert.Len(t, emptyResult, 0)
+	assert.True(t, databaseModel.IsKeyNotFound(d.Get(modelV1.KindProject, projectEntity.GetMetadata(), &modelV1.Project{})))
+}
+
+func TestDAO_QueryPagination(t *testing.T) {
+	d := newDAO(t)
+	for i, name := range []string{"c", "a", "d", "b"} {
+		entity := newSecret("perses", name)
+		entity.Metadata.CreateNow()
+		// the secrets are created in a different order than the one of their names
+		entity.Metadata.CreatedAt = entity.Metadata.CreatedAt.Add(time.Duration(i) * time.Second)
+		assert.NoError(t, d.Create(entity))
+	}
+	assert.NoError(t, d.Create(newSecret("another", "a")))
+
+	q := &secret.Query{Project: "perses", Pagination: databaseModel.Pagination{Limit: 3}}
+	var firstPage []*modelV1.Secret
+	assert.NoError(t, d.Query(q, &firstPage))
+	assert.Len(t, firstPage, 3)
+	assert.Equal(t, "a", firstPage[0].Metadata.Name)
+	assert.Equal(t, "c", firstPage[2].Metadata.Name)
+	assert.NotEmpty(t, q.Next())
+
+	q.Continue = q.Next()
+	var secondPage []*modelV1.Secret
+	assert.NoError(t, d.Query(q, &secondPage))
+	assert.Len(t, secondPage, 1)
+	assert.Equal(t, "d", secondPage[0].Metadata.Name)
+	assert.Empty(t, q.Next())
+
+	var byDate []*modelV1.Secret
+	assert.NoError(t, d.Query(&secret.Query{Project: "perses", Pagination: databaseModel.Pagination{Sort: "-createdAt"}}, &byDate))
+	assert.Len(t, byDate, 4)
+	assert.Equal(t, "b", byDate[0].Metadata.Name)
+	assert.Equal(t, "c", byDate[3].Metadata.Name)
+
+	// the secrets with the same name are sorted by project
+	var allProjects []*modelV1.Secret
+	assert.NoError(t, d.Query(&secret.Query{Pagination: databaseModel.Pagination{Limit: 2}}, &allProjects))
+	assert.Len(t, allProjects, 2)
+	assert.Equal(t, "another", allProjects[0].Metadata.Project)
+	assert.Equal(t, "perses", allProjects[1].Metadata.Project)
+}
+
+func TestDAO_QueryLabelSelector(t *testing.T) {
+	d := newDAO(t)
+	labels := map[string][]string{
+		"sre-backend":  {"team=sre", "tier=backend"},
+		"sre-frontend": {"team=sre", "tier=frontend"},
+		"dev":          {"team=dev", "perses.dev/tier=dev"},
+		"none":         {},
+	}
+	for name, pairs := range labels {
+		entity := newSecret("perses", name)
+		for _, pair := range pairs {
+			key, value, _ := strings.Cut(pair, "=")
+			if entity.Metadata.Labels == nil {
+				entity.Metadata.Labels = make(map[string]string)
+			}
+			entity.Metadata.Labels[key] = value
+		}
+		assert.NoError(t, d.Create(entity))
+	}
+	testSuite := []struct {
+		selector string
+		result   []string
+	}{
+		{selector: "", result: []string{"dev", "none", "sre-backend", "sre-frontend"}},
+		{selector: "team=sre", result: []string{"sre-backend", "sre-frontend"}},
+		{selector: "team=sre,tier!=frontend", result: []string{"sre-backend"}},
+		{selector: "tier!=frontend", result: []string{"dev", "none", "sre-backend"}},
+		{selector: "team in (dev,sre),tier notin (backend)", result: []string{"dev", "sre-frontend"}},
+		{selector: "perses.dev/tier", result: []string{"dev"}},
+		{selector: "!team", result: []string{"none"}},
+	}
+	for _, test := range testSuite {
+		t.Run(test.selector, func(t *testing.T) {
+			var result []*modelV1.Secret
+			q := &secret.Query{Project: "perses", LabelSelection: databaseModel.LabelSelection{LabelSelector: test.selector}, Pagination: databaseModel.Pagination{Sort: databaseModel.SortByName}}
+			assert.NoError(t, d.Query(q, &result))
+			var names []string
+			for _, s := range result {
+				names = append(names, s.Metadata.Name)
+			}
+			assert.Equal(t, test.result, names)
+		})
+	}
+}
+
+func TestDAO_QueryTrash(t *testing.T) {
+	d := newDAO(t)
+	content := &modelV1.TrashContent{Secrets: []*modelV1.Secret{newSecret("perses", "foo")}}
+	entry := modelV1.NewTrashEntry(modelV1.TrashResource{Kind: modelV1.KindProject, Project: "perses", Name: "perses"}, content, time.Hour)
+	assert.NoError(t, d.Create(entry))
+
+	var result []*modelV1.TrashEntry
+	assert.NoError(t, d.Query(&trash.Query{NamePrefix: "project-"}, &result))
+	assert.Len(t, result, 1)
+	// the content must be stored, so the resources can be restored
+	assert.Len(t, result[0].Spec.Content.Secrets, 1)
+	assert.Equal(t, "foo", result[0].Spec.Content.Secrets[0].Metadata.Name)
+
+	var emptyResult []*modelV1.TrashEntry
+	assert.NoError(t, d.Query(&trash.Query{NamePrefix: "dashboard-"}, &emptyResult))
+	assert.Len(t, emptyResult, 0)
+}
+
+func TestDAO_QueryWebhookDeliveries(t *testing.T) {
+	d := newDAO(t)
+	now := time.Now().UTC()
+	reference := modelV1.WebhookReference{Kind: modelV1.KindGlobalWebhook, Name: "hooks"}
+	newDelivery := func(status modelV1.WebhookDeliveryStatus, updatedAt time.Time) *modelV1.WebhookDelivery {
+		delivery := modelV1.NewWebhookDelivery(reference, modelV1.WebhookEvent{Action: modelV1.ActionCreate, Resource: modelV1.AuditResource{Kind: modelV1.KindProject, Name: "perses"}})
+		delivery.Spec.Status = status
+		delivery.Metadata.UpdatedAt = updatedAt
+		assert.NoError(t, d.Create(delivery))
+		return delivery
+	}
+	pending := newDelivery(modelV1.WebhookDeliveryStatusPending, now.Add(-48*time.Hour))
+	oldSuccess := newDelivery(modelV1.WebhookDeliveryStatusSucceeded, now.Add(-48*time.Hour))
+	newDelivery(modelV1.WebhookDeliveryStatusSucceeded, now)
+	newDelivery(modelV1.WebhookDeliveryStatusFailed, now)
+
+	names := func(q *webhookdelivery.Query) []string {
+		var result []*modelV1.WebhookDelivery
+		assert.NoError(t, d.Query(q, &result))
+		var names []string
+		for _, delivery := range result {
+			names = append(names, delivery.Metadata.Name)
+		}
+		return names
+	}
+	assert.Len(t, names(&webhookdelivery.Query{}), 4)
+	assert.Equal(t, []string{pending.Metadata.Name}, names(&webhookdelivery.Query{Status: modelV1.WebhookDeliveryStatusPending}))
+	assert.Equal(t, []string{oldSuccess.Metadata.Name}, names(&webhookdelivery.Query{Status: modelV1.WebhookDeliveryStatusSucceeded, UpdatedBefore: now.Add(-time.Hour)}))
+	assert.Empty(t, names(&webhookdelivery.Query{Status: modelV1.WebhookDeliveryStatusFailed, UpdatedBefore: now.Add(-time.Hour)}))
+}
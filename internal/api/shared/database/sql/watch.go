@@ -0,0 +1,248 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasesql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	tableResourceChange = "resourcechange"
+
+	colSeq   = "seq"
+	colKind  = "kind"
+	colEvent = "event"
+
+	defaultPollInterval    = time.Second
+	defaultChangeRetention = time.Hour
+	// pruneInterval is the interval between two removals of the changes older than the retention.
+	pruneInterval = time.Minute
+	// gapTimeout is how long a missing sequence number is waited for. A change with a lower sequence number than the last one read
+	// can still be committed later, as the sequence numbers are allocated when the rows are inserted. After the timeout,
+	// the number is considered lost, as it happens when a transaction is rolled back.
+	gapTimeout = time.Minute
+)
+
+// recordChange inserts the change in the table read by every instance of Perses to send the changes to their watchers.
+// It must be called in the transaction modifying the resource, so the change is recorded only if the modification is committed.
+func (d *DAO) recordChange(eventType modelV1.EventType, rowJSONDoc []byte) error {
+	event, err := databaseModel.NewEvent(eventType, rowJSONDoc)
+	if err != nil {
+		return err
+	}
+	sqlQuery, args := d.flavor().NewInsertBuilder().
+		InsertInto(d.generateCompleteTableName(tableResourceChange)).
+		Cols(colKind, colEvent, colDoc, colCreatedAt).
+		Values(string(event.Kind), string(event.Type), string(event.Document), databaseModel.FormatTime(time.Now())).
+		Build()
+	_, execErr := d.conn().Exec(sqlQuery, args...)
+	return execErr
+}
+
+// Watch returns the changes recorded in the database, including the ones made by the other instances of Perses.
+// The changes are read periodically, so they are received with a delay of the poll interval at most.
+func (d *DAO) Watch(ctx context.Context, kind modelV1.Kind) (<-chan *databaseModel.Event, error) {
+	if d.feed == nil {
+		return nil, fmt.Errorf("the changes can only be watched once the database is initialized and outside a transaction")
+	}
+	return d.feed.subscribe(ctx, kind)
+}
+
+// changeFeed reads periodically the changes recorded in the database and sends them to the watchers.
+type changeFeed struct {
+	dao      *DAO
+	notifier databaseModel.Notifier
+	mutex    sync.Mutex
+	// isWatching is true while there is a subscriber. The changes are not read when nobody is watching them.
+	isWatching bool
+	// lastSeq is the sequence number of the last change read, such as every change with a lower number has been read as well.
+	lastSeq int64
+	// published contains the sequence numbers, greater than lastSeq, of the changes already read.
+	published map[int64]bool
+	// gapSince is when the changes after lastSeq have been read while the next one is missing.
+	gapSince  time.Time
+	lastPrune time.Time
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func newChangeFeed(dao *DAO) *changeFeed {
+	return &changeFeed{
+		dao:       dao,
+		published: make(map[int64]bool),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+func (f *changeFeed) pollInterval() time.Duration {
+	if f.dao.PollInterval <= 0 {
+		return defaultPollInterval
+	}
+	return f.dao.PollInterval
+}
+
+func (f *changeFeed) retention() time.Duration {
+	if f.dao.ChangeRetention <= 0 {
+		return defaultChangeRetention
+	}
+	return f.dao.ChangeRetention
+}
+
+func (f *changeFeed) run() {
+	defer close(f.done)
+	ticker := time.NewTicker(f.pollInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stop:
+			return
+		case now := <-ticker.C:
+			if err := f.poll(now); err != nil {
+				logrus.WithError(err).Error("unable to read the changes of the resources")
+			}
+			if now.Sub(f.lastPrune) >= pruneInterval {
+				f.lastPrune = now
+				if err := f.prune(now); err != nil {
+					logrus.WithError(err).Error("unable to remove the old changes of the resources")
+				}
+			}
+		}
+	}
+}
+
+func (f *changeFeed) close() {
+	close(f.stop)
+	<-f.done
+}
+
+func (f *changeFeed) subscribe(ctx context.Context, kind modelV1.Kind) (<-chan *databaseModel.Event, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.isWatching && f.notifier.HasSubscriber() {
+		// the changes not read yet have been made before the subscription, so they are sent to the current watchers only.
+		if err := f.read(time.Now()); err != nil {
+			return nil, err
+		}
+	} else {
+		// only the changes made from now on are sent.
+		var lastSeq int64
+		query := fmt.Sprintf("SELECT COALESCE(MAX(%s), 0) FROM %s", colSeq, f.dao.generateCompleteTableName(tableResourceChange))
+		if err := f.dao.DB.QueryRow(query).Scan(&lastSeq); err != nil {
+			return nil, err
+		}
+		f.lastSeq = lastSeq
+		f.published = make(map[int64]bool)
+		f.gapSince = time.Time{}
+		f.isWatching = true
+	}
+	return f.notifier.Subscribe(ctx, kind), nil
+}
+
+// poll reads the changes recorded since the last call and sends them to the watchers.
+func (f *changeFeed) poll(now time.Time) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.read(now)
+}
+
+// read must be called with the lock held.
+func (f *changeFeed) read(now time.Time) error {
+	if !f.notifier.HasSubscriber() {
+		f.isWatching = false
+		return nil
+	}
+	if !f.isWatching {
+		return nil
+	}
+	queryBuilder := f.dao.flavor().NewSelectBuilder().
+		Select(colSeq, colKind, colEvent, colDoc).
+		From(f.dao.generateCompleteTableName(tableResourceChange))
+	queryBuilder.Where(queryBuilder.GreaterThan(colSeq, f.lastSeq))
+	queryBuilder.OrderBy(colSeq)
+	sqlQuery, args := queryBuilder.Build()
+	rows, err := f.dao.DB.Query(sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+	var events []*databaseModel.Event
+	for rows.Next() {
+		var seq int64
+		var kind, event, rowJSONDoc string
+		if scanErr := rows.Scan(&seq, &kind, &event, &rowJSONDoc); scanErr != nil {
+			_ = rows.Close()
+			return scanErr
+		}
+		if f.published[seq] {
+			continue
+		}
+		f.published[seq] = true
+		events = append(events, &databaseModel.Event{
+			Type:     modelV1.EventType(event),
+			Kind:     modelV1.Kind(kind),
+			Document: []byte(rowJSONDoc),
+		})
+	}
+	if closeErr := rows.Close(); closeErr != nil {
+		return closeErr
+	}
+	f.notifier.Publish(events...)
+	f.advance(now)
+	return nil
+}
+
+// advance moves lastSeq forward as long as the following changes have been read.
+func (f *changeFeed) advance(now time.Time) {
+	for f.published[f.lastSeq+1] {
+		delete(f.published, f.lastSeq+1)
+		f.lastSeq++
+	}
+	if len(f.published) == 0 {
+		f.gapSince = time.Time{}
+		return
+	}
+	if f.gapSince.IsZero() {
+		f.gapSince = now
+		return
+	}
+	if now.Sub(f.gapSince) < gapTimeout {
+		return
+	}
+	// the missing changes are given up, lastSeq jumps to the next change read.
+	next := int64(-1)
+	for seq := range f.published {
+		if next < 0 || seq < next {
+			next = seq
+		}
+	}
+	f.lastSeq = next - 1
+	f.gapSince = time.Time{}
+	f.advance(now)
+}
+
+// prune removes the changes older than the retention.
+func (f *changeFeed) prune(now time.Time) error {
+	deleteBuilder := f.dao.flavor().NewDeleteBuilder().DeleteFrom(f.dao.generateCompleteTableName(tableResourceChange))
+	deleteBuilder.Where(deleteBuilder.LessThan(colCreatedAt, databaseModel.FormatTime(now.Add(-f.retention()))))
+	sqlQuery, args := deleteBuilder.Build()
+	_, err := f.dao.DB.Exec(sqlQuery, args...)
+	return err
+}
@@ -0,0 +1,150 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasesql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/perses/perses/internal/api/interface/v1/secret"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+// receive returns the next event, or fails if none is received.
+func receive(t *testing.T, events <-chan *databaseModel.Event) *databaseModel.Event {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+		return nil
+	}
+}
+
+func TestDAO_Watch(t *testing.T) {
+	d := newDAO(t)
+	// another instance of Perses using the same database
+	other := &DAO{DB: d.DB, SchemaName: d.SchemaName, Flavor: d.Flavor, PollInterval: d.PollInterval}
+	assert.NoError(t, other.Init())
+	defer other.feed.close()
+
+	// the changes made before watching are not sent
+	assert.NoError(t, d.Create(newSecret("perses", "bar")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := other.Watch(ctx, modelV1.KindSecret)
+	assert.NoError(t, err)
+
+	secretEntity := newSecret("perses", "foo")
+	assert.NoError(t, d.Create(secretEntity))
+	event := receive(t, events)
+	assert.Equal(t, modelV1.EventTypeAdded, event.Type)
+	received := &modelV1.Secret{}
+	assert.NoError(t, json.Unmarshal(event.Document, received))
+	assert.Equal(t, "foo", received.Metadata.Name)
+
+	assert.NoError(t, d.Upsert(secretEntity))
+	assert.Equal(t, modelV1.EventTypeModified, receive(t, events).Type)
+	assert.NoError(t, d.Update(secretEntity, 0))
+	assert.Equal(t, modelV1.EventTypeModified, receive(t, events).Type)
+	assert.NoError(t, d.Delete(modelV1.KindSecret, secretEntity.GetMetadata()))
+	assert.Equal(t, modelV1.EventTypeDeleted, receive(t, events).Type)
+
+	// nothing is recorded when the transaction is rolled back
+	assert.Error(t, d.Transaction(func(tx databaseModel.DAO) error {
+		if deleteErr := tx.DeleteByQuery(&secret.Query{Project: "perses"}); deleteErr != nil {
+			return deleteErr
+		}
+		return assert.AnError
+	}))
+	assert.NoError(t, d.DeleteByQuery(&secret.Query{Project: "perses"}))
+	event = receive(t, events)
+	assert.Equal(t, modelV1.EventTypeDeleted, event.Type)
+	assert.NoError(t, json.Unmarshal(event.Document, received))
+	assert.Equal(t, "bar", received.Metadata.Name)
+
+	// a transaction cannot be watched
+	assert.NoError(t, d.Transaction(func(tx databaseModel.DAO) error {
+		_, watchErr := tx.Watch(ctx, modelV1.KindSecret)
+		assert.Error(t, watchErr)
+		return nil
+	}))
+}
+
+func TestDAO_WatchWhileWatched(t *testing.T) {
+	d := newDAO(t)
+	// the changes are only read when a watcher subscribes
+	other := &DAO{DB: d.DB, SchemaName: d.SchemaName, Flavor: d.Flavor, PollInterval: time.Hour}
+	assert.NoError(t, other.Init())
+	defer other.feed.close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := &modelV1.Secret{}
+
+	first, err := other.Watch(ctx, modelV1.KindSecret)
+	assert.NoError(t, err)
+	assert.NoError(t, d.Create(newSecret("perses", "before")))
+	// the change made before the second watch started is only sent to the first one
+	second, err := other.Watch(ctx, modelV1.KindSecret)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(receive(t, first).Document, received))
+	assert.Equal(t, "before", received.Metadata.Name)
+
+	assert.NoError(t, d.Create(newSecret("perses", "after")))
+	_, err = other.Watch(ctx, modelV1.KindSecret)
+	assert.NoError(t, err)
+	for _, events := range []<-chan *databaseModel.Event{first, second} {
+		assert.NoError(t, json.Unmarshal(receive(t, events).Document, received))
+		assert.Equal(t, "after", received.Metadata.Name)
+	}
+}
+
+func TestChangeFeed_Advance(t *testing.T) {
+	now := time.Now()
+	f := &changeFeed{lastSeq: 1, published: map[int64]bool{2: true, 3: true, 5: true}}
+	f.advance(now)
+	// the change 4 may not be committed yet
+	assert.Equal(t, int64(3), f.lastSeq)
+	assert.Equal(t, map[int64]bool{5: true}, f.published)
+	assert.Equal(t, now, f.gapSince)
+
+	f.advance(now.Add(gapTimeout / 2))
+	assert.Equal(t, int64(3), f.lastSeq)
+
+	// the change 4 is considered lost after the timeout
+	f.advance(now.Add(gapTimeout))
+	assert.Equal(t, int64(5), f.lastSeq)
+	assert.Empty(t, f.published)
+	assert.True(t, f.gapSince.IsZero())
+}
+
+func TestChangeFeed_Prune(t *testing.T) {
+	d := newDAO(t)
+	assert.NoError(t, d.Create(newSecret("perses", "foo")))
+	count := func() int {
+		var result int
+		assert.NoError(t, d.DB.QueryRow("SELECT COUNT(*) FROM main.resourcechange").Scan(&result))
+		return result
+	}
+	assert.Equal(t, 1, count())
+	assert.NoError(t, d.feed.prune(time.Now()))
+	assert.Equal(t, 1, count())
+	assert.NoError(t, d.feed.prune(time.Now().Add(defaultChangeRetention+time.Second)))
+	assert.Equal(t, 0, count())
+}
@@ -15,57 +15,102 @@ package dependency
 
 import (
 	"github.com/perses/perses/internal/api/config"
+	auditImpl "github.com/perses/perses/internal/api/impl/v1/audit"
 	dashboardImpl "github.com/perses/perses/internal/api/impl/v1/dashboard"
 	datasourceImpl "github.com/perses/perses/internal/api/impl/v1/datasource"
 	folderImpl "github.com/perses/perses/internal/api/impl/v1/folder"
 	globalDatasourceImpl "github.com/perses/perses/internal/api/impl/v1/globaldatasource"
+	globalRoleImpl "github.com/perses/perses/internal/api/impl/v1/globalrole"
+	globalRoleBindingImpl "github.com/perses/perses/internal/api/impl/v1/globalrolebinding"
 	globalSecretImpl "github.com/perses/perses/internal/api/impl/v1/globalsecret"
 	globalVariableImpl "github.com/perses/perses/internal/api/impl/v1/globalvariable"
+	globalWebhookImpl "github.com/perses/perses/internal/api/impl/v1/globalwebhook"
 	healthImpl "github.com/perses/perses/internal/api/impl/v1/health"
 	projectImpl "github.com/perses/perses/internal/api/impl/v1/project"
+	roleImpl "github.com/perses/perses/internal/api/impl/v1/role"
+	roleBindingImpl "github.com/perses/perses/internal/api/impl/v1/rolebinding"
 	secretImpl "github.com/perses/perses/internal/api/impl/v1/secret"
+	serviceAccountImpl "github.com/perses/perses/internal/api/impl/v1/serviceaccount"
+	trashImpl "github.com/perses/perses/internal/api/impl/v1/trash"
+	userImpl "github.com/perses/perses/internal/api/impl/v1/user"
 	variableImpl "github.com/perses/perses/internal/api/impl/v1/variable"
+	webhookImpl "github.com/perses/perses/internal/api/impl/v1/webhook"
+	webhookDeliveryImpl "github.com/perses/perses/internal/api/impl/v1/webhookdelivery"
+	"github.com/perses/perses/internal/api/interface/v1/audit"
 	"github.com/perses/perses/internal/api/interface/v1/dashboard"
 	"github.com/perses/perses/internal/api/interface/v1/datasource"
 	"github.com/perses/perses/internal/api/interface/v1/folder"
 	"github.com/perses/perses/internal/api/interface/v1/globaldatasource"
+	"github.com/perses/perses/internal/api/interface/v1/globalrole"
+	"github.com/perses/perses/internal/api/interface/v1/globalrolebinding"
 	"github.com/perses/perses/internal/api/interface/v1/globalsecret"
 	"github.com/perses/perses/internal/api/interface/v1/globalvariable"
+	"github.com/perses/perses/internal/api/interface/v1/globalwebhook"
 	"github.com/perses/perses/internal/api/interface/v1/health"
 	"github.com/perses/perses/internal/api/interface/v1/project"
+	"github.com/perses/perses/internal/api/interface/v1/role"
+	"github.com/perses/perses/internal/api/interface/v1/rolebinding"
 	"github.com/perses/perses/internal/api/interface/v1/secret"
+	"github.com/perses/perses/internal/api/interface/v1/serviceaccount"
+	"github.com/perses/perses/internal/api/interface/v1/trash"
+	"github.com/perses/perses/internal/api/interface/v1/user"
 	"github.com/perses/perses/internal/api/interface/v1/variable"
+	"github.com/perses/perses/internal/api/interface/v1/webhook"
+	"github.com/perses/perses/internal/api/interface/v1/webhookdelivery"
 	"github.com/perses/perses/internal/api/shared/database"
+	databaseCache "github.com/perses/perses/internal/api/shared/database/cache"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
 )
 
 type PersistenceManager interface {
+	GetAudit() audit.DAO
 	GetDashboard() dashboard.DAO
 	GetDatasource() datasource.DAO
 	GetFolder() folder.DAO
 	GetGlobalDatasource() globaldatasource.DAO
+	GetGlobalRole() globalrole.DAO
+	GetGlobalRoleBinding() globalrolebinding.DAO
 	GetGlobalSecret() globalsecret.DAO
 	GetGlobalVariable() globalvariable.DAO
+	GetGlobalWebhook() globalwebhook.DAO
 	GetHealth() health.DAO
 	GetPersesDAO() databaseModel.DAO
 	GetProject() project.DAO
+	GetRole() role.DAO
+	GetRoleBinding() rolebinding.DAO
 	GetSecret() secret.DAO
+	GetServiceAccount() serviceaccount.DAO
+	GetTrash() trash.DAO
+	GetUser() user.DAO
 	GetVariable() variable.DAO
+	GetWebhook() webhook.DAO
+	GetWebhookDelivery() webhookdelivery.DAO
 }
 
 type persistence struct {
 	PersistenceManager
-	dashboard        dashboard.DAO
-	datasource       datasource.DAO
-	folder           folder.DAO
-	globalDatasource globaldatasource.DAO
-	globalSecret     globalsecret.DAO
-	globalVariable   globalvariable.DAO
-	health           health.DAO
-	perses           databaseModel.DAO
-	project          project.DAO
-	secret           secret.DAO
-	variable         variable.DAO
+	audit             audit.DAO
+	dashboard         dashboard.DAO
+	datasource        datasource.DAO
+	folder            folder.DAO
+	globalDatasource  globaldatasource.DAO
+	globalRole        globalrole.DAO
+	globalRoleBinding globalrolebinding.DAO
+	globalSecret      globalsecret.DAO
+	globalVariable    globalvariable.DAO
+	globalWebhook     globalwebhook.DAO
+	health            health.DAO
+	perses            databaseModel.DAO
+	project           project.DAO
+	role              role.DAO
+	roleBinding       rolebinding.DAO
+	secret            secret.DAO
+	serviceAccount    serviceaccount.DAO
+	trash             trash.DAO
+	user              user.DAO
+	variable          variable.DAO
+	webhook           webhook.DAO
+	webhookDelivery   webhookdelivery.DAO
 }
 
 func NewPersistenceManager(conf config.Database) (PersistenceManager, error) {
@@ -73,31 +118,60 @@ func NewPersistenceManager(conf config.Database) (PersistenceManager, error) {
 	if err != nil {
 		return nil, err
 	}
+	if conf.Cache.Enable {
+		persesDAO = databaseCache.New(persesDAO, conf.Cache)
+	}
+	auditDAO := auditImpl.NewDAO(persesDAO)
 	dashboardDAO := dashboardImpl.NewDAO(persesDAO)
 	datasourceDAO := datasourceImpl.NewDAO(persesDAO)
 	folderDAO := folderImpl.NewDAO(persesDAO)
 	globalDatatasourceDAO := globalDatasourceImpl.NewDAO(persesDAO)
+	globalRoleDAO := globalRoleImpl.NewDAO(persesDAO)
+	globalRoleBindingDAO := globalRoleBindingImpl.NewDAO(persesDAO)
 	globalSecretDAO := globalSecretImpl.NewDAO(persesDAO)
 	globalVariableDAO := globalVariableImpl.NewDAO(persesDAO)
+	globalWebhookDAO := globalWebhookImpl.NewDAO(persesDAO)
 	healthDAO := healthImpl.NewDAO(persesDAO)
 	projectDAO := projectImpl.NewDAO(persesDAO)
+	roleDAO := roleImpl.NewDAO(persesDAO)
+	roleBindingDAO := roleBindingImpl.NewDAO(persesDAO)
 	secretDAO := secretImpl.NewDAO(persesDAO)
+	serviceAccountDAO := serviceAccountImpl.NewDAO(persesDAO)
+	trashDAO := trashImpl.NewDAO(persesDAO)
+	userDAO := userImpl.NewDAO(persesDAO)
 	variableDAO := variableImpl.NewDAO(persesDAO)
+	webhookDAO := webhookImpl.NewDAO(persesDAO)
+	webhookDeliveryDAO := webhookDeliveryImpl.NewDAO(persesDAO)
 	return &persistence{
-		dashboard:        dashboardDAO,
-		datasource:       datasourceDAO,
-		folder:           folderDAO,
-		globalDatasource: globalDatatasourceDAO,
-		globalSecret:     globalSecretDAO,
-		globalVariable:   globalVariableDAO,
-		health:           healthDAO,
-		perses:           persesDAO,
-		project:          projectDAO,
-		secret:           secretDAO,
-		variable:         variableDAO,
+		audit:             auditDAO,
+		dashboard:         dashboardDAO,
+		datasource:        datasourceDAO,
+		folder:            folderDAO,
+		globalDatasource:  globalDatatasourceDAO,
+		globalRole:        globalRoleDAO,
+		globalRoleBinding: globalRoleBindingDAO,
+		globalSecret:      globalSecretDAO,
+		globalVariable:    globalVariableDAO,
+		globalWebhook:     globalWebhookDAO,
+		health:            healthDAO,
+		perses:            persesDAO,
+		project:           projectDAO,
+		role:              roleDAO,
+		roleBinding:       roleBindingDAO,
+		secret:            secretDAO,
+		serviceAccount:    serviceAccountDAO,
+		trash:             trashDAO,
+		user:              userDAO,
+		variable:          variableDAO,
+		webhook:           webhookDAO,
+		webhookDelivery:   webhookDeliveryDAO,
 	}, nil
 }
 
+func (p *persistence) GetAudit() audit.DAO {
+	return p.audit
+}
+
 func (p *persistence) GetDashboard() dashboard.DAO {
 	return p.dashboard
 }
@@ -114,6 +188,14 @@ func (p *persistence) GetGlobalDatasource() globaldatasource.DAO {
 	return p.globalDatasource
 }
 
+func (p *persistence) GetGlobalRole() globalrole.DAO {
+	return p.globalRole
+}
+
+func (p *persistence) GetGlobalRoleBinding() globalrolebinding.DAO {
+	return p.globalRoleBinding
+}
+
 func (p *persistence) GetGlobalSecret() globalsecret.DAO {
 	return p.globalSecret
 }
@@ -122,6 +204,10 @@ func (p *persistence) GetGlobalVariable() globalvariable.DAO {
 	return p.globalVariable
 }
 
+func (p *persistence) GetGlobalWebhook() globalwebhook.DAO {
+	return p.globalWebhook
+}
+
 func (p *persistence) GetHealth() health.DAO {
 	return p.health
 }
@@ -134,10 +220,38 @@ func (p *persistence) GetProject() project.DAO {
 	return p.project
 }
 
+func (p *persistence) GetRole() role.DAO {
+	return p.role
+}
+
+func (p *persistence) GetRoleBinding() rolebinding.DAO {
+	return p.roleBinding
+}
+
 func (p *persistence) GetSecret() secret.DAO {
 	return p.secret
 }
 
+func (p *persistence) GetServiceAccount() serviceaccount.DAO {
+	return p.serviceAccount
+}
+
+func (p *persistence) GetTrash() trash.DAO {
+	return p.trash
+}
+
+func (p *persistence) GetUser() user.DAO {
+	return p.user
+}
+
 func (p *persistence) GetVariable() variable.DAO {
 	return p.variable
 }
+
+func (p *persistence) GetWebhook() webhook.DAO {
+	return p.webhook
+}
+
+func (p *persistence) GetWebhookDelivery() webhookdelivery.DAO {
+	return p.webhookDelivery
+}
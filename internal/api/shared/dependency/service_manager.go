@@ -16,63 +16,137 @@
 package dependency
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/perses/perses/internal/api/config"
+	applyImpl "github.com/perses/perses/internal/api/impl/v1/apply"
+	auditImpl "github.com/perses/perses/internal/api/impl/v1/audit"
 	dashboardImpl "github.com/perses/perses/internal/api/impl/v1/dashboard"
 	datasourceImpl "github.com/perses/perses/internal/api/impl/v1/datasource"
 	folderImpl "github.com/perses/perses/internal/api/impl/v1/folder"
 	globalDatasourceImpl "github.com/perses/perses/internal/api/impl/v1/globaldatasource"
+	globalRoleImpl "github.com/perses/perses/internal/api/impl/v1/globalrole"
+	globalRoleBindingImpl "github.com/perses/perses/internal/api/impl/v1/globalrolebinding"
 	globalSecretImpl "github.com/perses/perses/internal/api/impl/v1/globalsecret"
 	globalVariableImpl "github.com/perses/perses/internal/api/impl/v1/globalvariable"
+	globalWebhookImpl "github.com/perses/perses/internal/api/impl/v1/globalwebhook"
 	healthImpl "github.com/perses/perses/internal/api/impl/v1/health"
 	projectImpl "github.com/perses/perses/internal/api/impl/v1/project"
+	roleImpl "github.com/perses/perses/internal/api/impl/v1/role"
+	roleBindingImpl "github.com/perses/perses/internal/api/impl/v1/rolebinding"
+	searchImpl "github.com/perses/perses/internal/api/impl/v1/search"
 	secretImpl "github.com/perses/perses/internal/api/impl/v1/secret"
+	serviceAccountImpl "github.com/perses/perses/internal/api/impl/v1/serviceaccount"
+	trashImpl "github.com/perses/perses/internal/api/impl/v1/trash"
+	userImpl "github.com/perses/perses/internal/api/impl/v1/user"
 	variableImpl "github.com/perses/perses/internal/api/impl/v1/variable"
+	webhookImpl "github.com/perses/perses/internal/api/impl/v1/webhook"
+	webhookDeliveryImpl "github.com/perses/perses/internal/api/impl/v1/webhookdelivery"
+	"github.com/perses/perses/internal/api/interface/v1/apply"
+	"github.com/perses/perses/internal/api/interface/v1/audit"
 	"github.com/perses/perses/internal/api/interface/v1/dashboard"
 	"github.com/perses/perses/internal/api/interface/v1/datasource"
 	"github.com/perses/perses/internal/api/interface/v1/folder"
 	"github.com/perses/perses/internal/api/interface/v1/globaldatasource"
+	"github.com/perses/perses/internal/api/interface/v1/globalrole"
+	"github.com/perses/perses/internal/api/interface/v1/globalrolebinding"
 	"github.com/perses/perses/internal/api/interface/v1/globalsecret"
 	"github.com/perses/perses/internal/api/interface/v1/globalvariable"
+	"github.com/perses/perses/internal/api/interface/v1/globalwebhook"
 	"github.com/perses/perses/internal/api/interface/v1/health"
 	"github.com/perses/perses/internal/api/interface/v1/project"
+	"github.com/perses/perses/internal/api/interface/v1/role"
+	"github.com/perses/perses/internal/api/interface/v1/rolebinding"
+	"github.com/perses/perses/internal/api/interface/v1/search"
 	"github.com/perses/perses/internal/api/interface/v1/secret"
+	"github.com/perses/perses/internal/api/interface/v1/serviceaccount"
+	"github.com/perses/perses/internal/api/interface/v1/trash"
+	"github.com/perses/perses/internal/api/interface/v1/user"
 	"github.com/perses/perses/internal/api/interface/v1/variable"
+	"github.com/perses/perses/internal/api/interface/v1/webhook"
+	"github.com/perses/perses/internal/api/interface/v1/webhookdelivery"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/internal/api/shared/authentication"
+	"github.com/perses/perses/internal/api/shared/backup"
 	"github.com/perses/perses/internal/api/shared/crypto"
 	"github.com/perses/perses/internal/api/shared/migrate"
+	"github.com/perses/perses/internal/api/shared/oauth"
+	"github.com/perses/perses/internal/api/shared/rbac"
 	"github.com/perses/perses/internal/api/shared/schemas"
+	searchIndex "github.com/perses/perses/internal/api/shared/search"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
 type ServiceManager interface {
+	GetApply() apply.Service
+	GetAudit() audit.Service
+	GetAuthentication() authentication.Authentication
+	GetBackup() backup.Backup
 	GetCrypto() crypto.Crypto
 	GetDashboard() dashboard.Service
 	GetDatasource() datasource.Service
 	GetFolder() folder.Service
 	GetGlobalDatasource() globaldatasource.Service
+	GetGlobalRole() globalrole.Service
+	GetGlobalRoleBinding() globalrolebinding.Service
 	GetGlobalSecret() globalsecret.Service
 	GetGlobalVariable() globalvariable.Service
+	GetGlobalWebhook() globalwebhook.Service
 	GetHealth() health.Service
+	GetJWT() crypto.JWT
 	GetMigration() migrate.Migration
+	GetOAuthProviders() oauth.Providers
 	GetProject() project.Service
+	GetRBAC() rbac.RBAC
+	GetRole() role.Service
+	GetRoleBinding() rolebinding.Service
 	GetSchemas() schemas.Schemas
+	GetSearch() search.Service
+	GetSearchIndex() searchIndex.Index
 	GetSecret() secret.Service
+	GetServiceAccount() serviceaccount.Service
+	GetTrash() trash.Service
+	GetUser() user.Service
 	GetVariable() variable.Service
+	GetWebhook() webhook.Service
+	GetWebhookDelivery() webhookdelivery.Service
 }
 
 type service struct {
 	ServiceManager
-	crypto           crypto.Crypto
-	dashboard        dashboard.Service
-	datasource       datasource.Service
-	folder           folder.Service
-	globalDatasource globaldatasource.Service
-	globalSecret     globalsecret.Service
-	globalVariable   globalvariable.Service
-	health           health.Service
-	migrate          migrate.Migration
-	project          project.Service
-	schemas          schemas.Schemas
-	secret           secret.Service
-	variable         variable.Service
+	apply             apply.Service
+	audit             audit.Service
+	authentication    authentication.Authentication
+	backup            backup.Backup
+	crypto            crypto.Crypto
+	dashboard         dashboard.Service
+	datasource        datasource.Service
+	folder            folder.Service
+	globalDatasource  globaldatasource.Service
+	globalRole        globalrole.Service
+	globalRoleBinding globalrolebinding.Service
+	globalSecret      globalsecret.Service
+	globalVariable    globalvariable.Service
+	globalWebhook     globalwebhook.Service
+	health            health.Service
+	jwt               crypto.JWT
+	migrate           migrate.Migration
+	oauthProviders    oauth.Providers
+	project           project.Service
+	rbac              rbac.RBAC
+	role              role.Service
+	roleBinding       rolebinding.Service
+	schemas           schemas.Schemas
+	search            search.Service
+	searchIndex       searchIndex.Index
+	secret            secret.Service
+	serviceAccount    serviceaccount.Service
+	trash             trash.Service
+	user              user.Service
+	variable          variable.Service
+	webhook           webhook.Service
+	webhookDelivery   webhookdelivery.Service
 }
 
 func NewServiceManager(dao PersistenceManager, conf config.Config) (ServiceManager, error) {
@@ -80,6 +154,10 @@ func NewServiceManager(dao PersistenceManager, conf config.Config) (ServiceManag
 	if err != nil {
 		return nil, err
 	}
+	jwtService, err := crypto.NewJWT(string(conf.EncryptionKey), conf.Authentication)
+	if err != nil {
+		return nil, err
+	}
 	schemasService, err := schemas.New(conf.Schemas)
 	if err != nil {
 		return nil, err
@@ -88,33 +166,104 @@ func NewServiceManager(dao PersistenceManager, conf config.Config) (ServiceManag
 	if err != nil {
 		return nil, err
 	}
-	dashboardService := dashboardImpl.NewService(dao.GetDashboard(), schemasService, dao.GetGlobalVariable(), dao.GetVariable())
+	// the search index is built once before the API starts, and then updated by the services each time a resource is written.
+	index := searchIndex.New(dao.GetDashboard(), dao.GetVariable(), dao.GetGlobalVariable())
+	if err := index.Rebuild(); err != nil {
+		return nil, fmt.Errorf("unable to build the search index: %w", err)
+	}
+	// the permissions are loaded once before the API starts, and then reloaded by the services each time a role or a binding is written.
+	rbacService := rbac.New(dao.GetRole(), dao.GetGlobalRole(), dao.GetRoleBinding(), dao.GetGlobalRoleBinding(), conf.Authorization)
+	if err := rbacService.Refresh(); err != nil {
+		return nil, fmt.Errorf("unable to load the permissions: %w", err)
+	}
+	auditService := auditImpl.NewService(auditImpl.NewSink(conf.Audit, dao.GetAudit()))
+	dashboardService := dashboardImpl.NewService(dao.GetDashboard(), dao.GetPersesDAO(), schemasService, dao.GetGlobalVariable(), dao.GetVariable(), conf.DashboardRevision, conf.Trash, index)
 	datasourceService := datasourceImpl.NewService(dao.GetDatasource(), schemasService)
 	folderService := folderImpl.NewService(dao.GetFolder())
-	variableService := variableImpl.NewService(dao.GetVariable(), schemasService)
+	variableService := variableImpl.NewService(dao.GetVariable(), schemasService, index)
 	globalDatasourceService := globalDatasourceImpl.NewService(dao.GetGlobalDatasource(), schemasService)
+	globalRoleService := globalRoleImpl.NewService(dao.GetGlobalRole(), rbacService)
+	globalRoleBindingService := globalRoleBindingImpl.NewService(dao.GetGlobalRoleBinding(), dao.GetGlobalRole(), dao.GetUser(), dao.GetServiceAccount(), rbacService)
 	globalSecret := globalSecretImpl.NewService(dao.GetGlobalSecret(), cryptoService)
-	globalVariableService := globalVariableImpl.NewService(dao.GetGlobalVariable(), schemasService)
+	globalVariableService := globalVariableImpl.NewService(dao.GetGlobalVariable(), schemasService, index)
+	globalWebhookService := globalWebhookImpl.NewService(dao.GetGlobalWebhook(), dao.GetPersesDAO(), conf.Webhook)
 	healthService := healthImpl.NewService(dao.GetHealth())
-	projectService := projectImpl.NewService(dao.GetProject(), dao.GetFolder(), dao.GetDatasource(), dao.GetDashboard(), dao.GetSecret(), dao.GetVariable())
+	projectService := projectImpl.NewService(dao.GetProject(), dao.GetPersesDAO(), conf.Trash, index, rbacService)
+	roleService := roleImpl.NewService(dao.GetRole(), rbacService)
+	roleBindingService := roleBindingImpl.NewService(dao.GetRoleBinding(), dao.GetRole(), dao.GetUser(), dao.GetServiceAccount(), rbacService)
+	searchService := searchImpl.NewService(index)
 	secretService := secretImpl.NewService(dao.GetSecret(), cryptoService)
+	serviceAccountService := serviceAccountImpl.NewService(dao.GetServiceAccount(), time.Duration(conf.Authentication.APITokenMaxTTL))
+	trashService := trashImpl.NewService(dao.GetTrash(), dao.GetPersesDAO(), index, rbacService)
+	userService := userImpl.NewService(dao.GetUser())
+	webhookService := webhookImpl.NewService(dao.GetWebhook(), dao.GetPersesDAO(), conf.Webhook)
+	webhookDeliveryService := webhookDeliveryImpl.NewService(dao.GetWebhookDelivery(), dao.GetWebhook(), dao.GetGlobalWebhook(), dao.GetSecret(), dao.GetGlobalSecret(), cryptoService, conf.Webhook)
+	// the resources applied are written by the service of their kind, so they are validated, encrypted and indexed the same way.
+	applyService := applyImpl.NewService(map[v1.Kind]shared.TransactionalService{
+		v1.KindDashboard:        dashboardService,
+		v1.KindDatasource:       datasourceService,
+		v1.KindFolder:           folderService,
+		v1.KindGlobalDatasource: globalDatasourceService,
+		v1.KindGlobalSecret:     globalSecret,
+		v1.KindGlobalVariable:   globalVariableService,
+		v1.KindProject:          projectService,
+		v1.KindSecret:           secretService,
+		v1.KindVariable:         variableService,
+	}, dao.GetPersesDAO(), schemasService)
+	authenticationService := authentication.New(dao.GetUser(), dao.GetServiceAccount(), jwtService)
+	backupService := backup.New(dao.GetPersesDAO(), cryptoService, index)
 	return &service{
-		crypto:           cryptoService,
-		dashboard:        dashboardService,
-		datasource:       datasourceService,
-		folder:           folderService,
-		globalDatasource: globalDatasourceService,
-		globalSecret:     globalSecret,
-		globalVariable:   globalVariableService,
-		health:           healthService,
-		migrate:          migrateService,
-		project:          projectService,
-		schemas:          schemasService,
-		secret:           secretService,
-		variable:         variableService,
+		apply:             applyService,
+		audit:             auditService,
+		authentication:    authenticationService,
+		backup:            backupService,
+		crypto:            cryptoService,
+		dashboard:         dashboardService,
+		datasource:        datasourceService,
+		folder:            folderService,
+		globalDatasource:  globalDatasourceService,
+		globalRole:        globalRoleService,
+		globalRoleBinding: globalRoleBindingService,
+		globalSecret:      globalSecret,
+		globalVariable:    globalVariableService,
+		globalWebhook:     globalWebhookService,
+		health:            healthService,
+		jwt:               jwtService,
+		migrate:           migrateService,
+		oauthProviders:    oauth.New(conf.Authentication.Providers),
+		project:           projectService,
+		rbac:              rbacService,
+		role:              roleService,
+		roleBinding:       roleBindingService,
+		schemas:           schemasService,
+		search:            searchService,
+		searchIndex:       index,
+		secret:            secretService,
+		serviceAccount:    serviceAccountService,
+		trash:             trashService,
+		user:              userService,
+		variable:          variableService,
+		webhook:           webhookService,
+		webhookDelivery:   webhookDeliveryService,
 	}, nil
 }
 
+func (s *service) GetApply() apply.Service {
+	return s.apply
+}
+
+func (s *service) GetAudit() audit.Service {
+	return s.audit
+}
+
+func (s *service) GetAuthentication() authentication.Authentication {
+	return s.authentication
+}
+
+func (s *service) GetBackup() backup.Backup {
+	return s.backup
+}
+
 func (s *service) GetCrypto() crypto.Crypto {
 	return s.crypto
 }
@@ -135,6 +284,14 @@ func (s *service) GetGlobalDatasource() globaldatasource.Service {
 	return s.globalDatasource
 }
 
+func (s *service) GetGlobalRole() globalrole.Service {
+	return s.globalRole
+}
+
+func (s *service) GetGlobalRoleBinding() globalrolebinding.Service {
+	return s.globalRoleBinding
+}
+
 func (s *service) GetGlobalSecret() globalsecret.Service {
 	return s.globalSecret
 }
@@ -143,26 +300,78 @@ func (s *service) GetGlobalVariable() globalvariable.Service {
 	return s.globalVariable
 }
 
+func (s *service) GetGlobalWebhook() globalwebhook.Service {
+	return s.globalWebhook
+}
+
 func (s *service) GetHealth() health.Service {
 	return s.health
 }
 
+func (s *service) GetJWT() crypto.JWT {
+	return s.jwt
+}
+
 func (s *service) GetMigration() migrate.Migration {
 	return s.migrate
 }
 
+func (s *service) GetOAuthProviders() oauth.Providers {
+	return s.oauthProviders
+}
+
 func (s *service) GetProject() project.Service {
 	return s.project
 }
 
+func (s *service) GetRBAC() rbac.RBAC {
+	return s.rbac
+}
+
+func (s *service) GetRole() role.Service {
+	return s.role
+}
+
+func (s *service) GetRoleBinding() rolebinding.Service {
+	return s.roleBinding
+}
+
 func (s *service) GetSchemas() schemas.Schemas {
 	return s.schemas
 }
 
+func (s *service) GetSearch() search.Service {
+	return s.search
+}
+
+func (s *service) GetSearchIndex() searchIndex.Index {
+	return s.searchIndex
+}
+
 func (s *service) GetSecret() secret.Service {
 	return s.secret
 }
 
+func (s *service) GetServiceAccount() serviceaccount.Service {
+	return s.serviceAccount
+}
+
+func (s *service) GetTrash() trash.Service {
+	return s.trash
+}
+
+func (s *service) GetUser() user.Service {
+	return s.user
+}
+
 func (s *service) GetVariable() variable.Service {
 	return s.variable
 }
+
+func (s *service) GetWebhook() webhook.Service {
+	return s.webhook
+}
+
+func (s *service) GetWebhookDelivery() webhookdelivery.Service {
+	return s.webhookDelivery
+}
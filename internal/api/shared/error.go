@@ -32,10 +32,13 @@ func (e *PersesError) Error() string {
 }
 
 var (
-	InternalError   = &PersesError{message: "internal server error"}
-	NotFoundError   = &PersesError{message: "document not found"}
-	ConflictError   = &PersesError{message: "document already exists"}
-	BadRequestError = &PersesError{message: "bad request"}
+	InternalError        = &PersesError{message: "internal server error"}
+	NotFoundError        = &PersesError{message: "document not found"}
+	ConflictError        = &PersesError{message: "document already exists"}
+	VersionConflictError = &PersesError{message: "document has been modified in the meantime"}
+	BadRequestError      = &PersesError{message: "bad request"}
+	UnauthorizedError    = &PersesError{message: "unauthorized"}
+	ForbiddenError       = &PersesError{message: "forbidden"}
 )
 
 // HandleError is translating the given error to the echoHTTPError
@@ -50,12 +53,21 @@ func HandleError(err error) error {
 	if databaseModel.IsKeyNotFound(err) || errors.Is(err, NotFoundError) {
 		return echo.NewHTTPError(http.StatusNotFound, NotFoundError.message)
 	}
+	if errors.Is(err, VersionConflictError) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
 	if databaseModel.IsKeyConflict(err) || errors.Is(err, ConflictError) {
 		return echo.NewHTTPError(http.StatusConflict, ConflictError.message)
 	}
 	if errors.Is(err, BadRequestError) {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	if errors.Is(err, UnauthorizedError) {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	}
+	if errors.Is(err, ForbiddenError) {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	}
 
 	if _, ok := err.(*echo.HTTPError); ok {
 		// the error is coming from the echo framework likely because the route doesn't exist.
@@ -69,3 +81,15 @@ func HandleError(err error) error {
 func HandleBadRequestError(msg string) error {
 	return fmt.Errorf("%w: %s", BadRequestError, msg)
 }
+
+func HandleUnauthorizedError(msg string) error {
+	return fmt.Errorf("%w: %s", UnauthorizedError, msg)
+}
+
+func HandleForbiddenError(msg string) error {
+	return fmt.Errorf("%w: %s", ForbiddenError, msg)
+}
+
+func HandleVersionConflictError(msg string) error {
+	return fmt.Errorf("%w: %s", VersionConflictError, msg)
+}
@@ -21,32 +21,24 @@ import (
 	"sync"
 
 	"cuelang.org/go/cue"
-	"github.com/fsnotify/fsnotify"
 	"github.com/perses/common/async"
 	"github.com/perses/perses/internal/api/shared/schemas"
 	"github.com/sirupsen/logrus"
 )
 
-func NewHotReloaders(service Migration) (async.SimpleTask, async.SimpleTask, error) {
-	fsWatcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return nil, nil, err
-	}
-
+func NewHotReloaders(service Migration) (async.SimpleTask, async.SimpleTask) {
 	callback := func() {
 		service.BuildMigrationSchemaString()
 	}
 	loaders := service.GetLoaders()
 
 	return &schemas.Watcher{
-			FSWatcher:      fsWatcher,
-			Loaders:        loaders,
-			LoaderCallback: callback,
-		}, &schemas.Reloader{
-			Loaders:        loaders,
-			LoaderCallback: callback,
-		},
-		nil
+		Loaders:        loaders,
+		LoaderCallback: callback,
+	}, &schemas.Reloader{
+		Loaders:        loaders,
+		LoaderCallback: callback,
+	}
 }
 
 type loader interface {
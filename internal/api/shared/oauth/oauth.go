@@ -0,0 +1,324 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oauth authenticates the users with OpenID Connect and OAuth 2.0 providers. The browsers use the authorization code flow,
+// protected by PKCE, and percli uses the device authorization flow.
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/perses/perses/internal/api/config"
+	"github.com/perses/perses/pkg/model/api"
+)
+
+const (
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	// maxResponseSize limits what is read from a provider.
+	maxResponseSize = 1 << 20
+)
+
+var (
+	// ErrAuthorizationPending is returned while the user hasn't approved the device yet.
+	ErrAuthorizationPending = errors.New(api.DeviceAuthorizationPending)
+	// ErrSlowDown is returned when the device polls too often. It has to increase its interval by 5 seconds.
+	ErrSlowDown = errors.New(api.DeviceSlowDown)
+	// ErrDeviceCodeNotSupported is returned when the provider doesn't have a device authorization endpoint.
+	ErrDeviceCodeNotSupported = errors.New("the provider doesn't support the device authorization flow")
+)
+
+// Identity is a user authenticated by a provider.
+type Identity struct {
+	// Issuer and Subject identify the account of the user in the provider.
+	Issuer    string
+	Subject   string
+	Login     string
+	FirstName string
+	LastName  string
+	Groups    []string
+}
+
+type Provider interface {
+	// Info returns the description of the provider displayed to the users.
+	Info() api.AuthProvider
+	// RedirectURI returns the URL of the callback set in the configuration. It is empty when it has to be built from the request.
+	RedirectURI() string
+	// AuthCodeURL returns the URL of the provider where the user is redirected to log in.
+	AuthCodeURL(ctx context.Context, redirectURI string, state string, nonce string, codeVerifier string) (string, error)
+	// Exchange returns the user who logged in, from the code sent by the provider to the callback.
+	// The code verifier and the nonce must be the ones given to AuthCodeURL.
+	Exchange(ctx context.Context, redirectURI string, code string, codeVerifier string, nonce string) (*Identity, error)
+	// DeviceCode starts the device authorization flow.
+	DeviceCode(ctx context.Context) (*api.DeviceCodeResponse, error)
+	// DeviceAccessToken returns the user who approved the device.
+	// It returns ErrAuthorizationPending or ErrSlowDown while the user hasn't approved it yet.
+	DeviceAccessToken(ctx context.Context, deviceCode string) (*Identity, error)
+}
+
+// Providers are the providers of the configuration.
+type Providers interface {
+	// Get returns the provider of the kind having the slug ID. It returns false if it doesn't exist.
+	Get(kind api.AuthProviderKind, slugID string) (Provider, bool)
+	// List returns the description of every provider, in the order of the configuration.
+	List() []api.AuthProvider
+}
+
+func New(conf config.AuthProviders) Providers {
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	p := &providers{}
+	for _, providerConf := range conf.OIDC {
+		p.list = append(p.list, newOIDCProvider(providerConf, httpClient))
+	}
+	for _, providerConf := range conf.OAuth {
+		p.list = append(p.list, newOAuthProvider(providerConf, httpClient))
+	}
+	return p
+}
+
+type providers struct {
+	Providers
+	list []Provider
+}
+
+func (p *providers) Get(kind api.AuthProviderKind, slugID string) (Provider, bool) {
+	for _, provider := range p.list {
+		if info := provider.Info(); info.Kind == kind && info.SlugID == slugID {
+			return provider, true
+		}
+	}
+	return nil, false
+}
+
+func (p *providers) List() []api.AuthProvider {
+	result := make([]api.AuthProvider, 0, len(p.list))
+	for _, provider := range p.list {
+		result = append(result, provider.Info())
+	}
+	return result
+}
+
+// RandomString returns a random string encoded in base64 URL, fit for a state, a nonce or a PKCE code verifier.
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// codeChallenge returns the PKCE challenge of the verifier, with the method S256.
+func codeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// tokenResponse is the response of the token endpoint. The error is set when the request is rejected.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type deviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	// VerificationURL is the name used by some providers, like Google, instead of VerificationURI.
+	VerificationURL  string `json:"verification_url"`
+	ExpiresIn        int    `json:"expires_in"`
+	Interval         int    `json:"interval"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// client sends the requests common to every provider, once their endpoints are known.
+type client struct {
+	clientID     string
+	clientSecret string
+	scopes       []string
+	httpClient   *http.Client
+}
+
+func (c *client) authCodeURL(authURL string, redirectURI string, state string, nonce string, codeVerifier string) (string, error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", c.clientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("state", state)
+	query.Set("code_challenge", codeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+	if len(c.scopes) > 0 {
+		query.Set("scope", strings.Join(c.scopes, " "))
+	}
+	if len(nonce) > 0 {
+		query.Set("nonce", nonce)
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func (c *client) exchange(ctx context.Context, tokenURL string, redirectURI string, code string, codeVerifier string) (*tokenResponse, error) {
+	return c.token(ctx, tokenURL, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {codeVerifier},
+	})
+}
+
+func (c *client) deviceCode(ctx context.Context, deviceAuthURL string) (*api.DeviceCodeResponse, error) {
+	form := c.clientForm()
+	if len(c.scopes) > 0 {
+		form.Set("scope", strings.Join(c.scopes, " "))
+	}
+	response := &deviceCodeResponse{}
+	if err := c.post(ctx, deviceAuthURL, form, response); err != nil {
+		return nil, err
+	}
+	if len(response.Error) > 0 {
+		return nil, providerError(response.Error, response.ErrorDescription)
+	}
+	if len(response.VerificationURI) == 0 {
+		response.VerificationURI = response.VerificationURL
+	}
+	if len(response.DeviceCode) == 0 || len(response.UserCode) == 0 || len(response.VerificationURI) == 0 {
+		return nil, fmt.Errorf("the device authorization response of the provider is incomplete")
+	}
+	if response.Interval <= 0 {
+		// it is the default interval defined by the RFC 8628.
+		response.Interval = 5
+	}
+	return &api.DeviceCodeResponse{
+		DeviceCode:              response.DeviceCode,
+		UserCode:                response.UserCode,
+		VerificationURI:         response.VerificationURI,
+		VerificationURIComplete: response.VerificationURIComplete,
+		ExpiresIn:               response.ExpiresIn,
+		Interval:                response.Interval,
+	}, nil
+}
+
+func (c *client) deviceAccessToken(ctx context.Context, tokenURL string, deviceCode string) (*tokenResponse, error) {
+	return c.token(ctx, tokenURL, url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"device_code": {deviceCode},
+	})
+}
+
+func (c *client) token(ctx context.Context, tokenURL string, form url.Values) (*tokenResponse, error) {
+	for key, values := range c.clientForm() {
+		form[key] = values
+	}
+	response := &tokenResponse{}
+	if err := c.post(ctx, tokenURL, form, response); err != nil {
+		return nil, err
+	}
+	// some providers, like GitHub, return the errors with the status code 200.
+	if len(response.Error) > 0 {
+		return nil, providerError(response.Error, response.ErrorDescription)
+	}
+	if len(response.AccessToken) == 0 {
+		return nil, fmt.Errorf("the token response of the provider doesn't contain an access token")
+	}
+	return response, nil
+}
+
+// clientForm returns the credentials of the client. They are sent in the body, as it is supported by the most providers.
+func (c *client) clientForm() url.Values {
+	form := url.Values{"client_id": {c.clientID}}
+	if len(c.clientSecret) > 0 {
+		form.Set("client_secret", c.clientSecret)
+	}
+	return form
+}
+
+// post sends the form and decodes the JSON response.
+func (c *client) post(ctx context.Context, endpoint string, form url.Values, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	return c.do(req, result, true)
+}
+
+// get sends a request authenticated by the access token and decodes the JSON response.
+func (c *client) get(ctx context.Context, endpoint string, accessToken string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if len(accessToken) > 0 {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	}
+	req.Header.Set("Accept", "application/json")
+	return c.do(req, result, false)
+}
+
+// do sends the request and decodes the JSON response. If isErrorDescribed is true, a response with the status code 400 or 401
+// is decoded too, as it contains the error code defined by the RFC 6749.
+func (c *client) do(req *http.Request, result interface{}, isErrorDescribed bool) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	isDescribedError := isErrorDescribed && (resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized)
+	if resp.StatusCode >= http.StatusMultipleChoices && !isDescribedError {
+		return fmt.Errorf("the request %s %s failed with the status code %d", req.Method, req.URL.Redacted(), resp.StatusCode)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// the numbers are kept as they are, so an identifier is not converted to a float.
+	decoder.UseNumber()
+	if decodeErr := decoder.Decode(result); decodeErr != nil {
+		return fmt.Errorf("unable to decode the response of %s %s with the status code %d: %w", req.Method, req.URL.Redacted(), resp.StatusCode, decodeErr)
+	}
+	return nil
+}
+
+// providerError returns the error for the error code sent by a provider, as described by the RFC 6749 and the RFC 8628.
+func providerError(code string, description string) error {
+	switch code {
+	case api.DeviceAuthorizationPending:
+		return ErrAuthorizationPending
+	case api.DeviceSlowDown:
+		return ErrSlowDown
+	}
+	if len(description) > 0 {
+		return fmt.Errorf("the provider returned the error %q: %s", code, description)
+	}
+	return fmt.Errorf("the provider returned the error %q", code)
+}
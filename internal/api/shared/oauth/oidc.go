@@ -0,0 +1,333 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/perses/perses/internal/api/config"
+	"github.com/perses/perses/pkg/model/api"
+)
+
+// clockSkew is the time an ID token is still accepted after its expiration, as the clocks of the servers can differ.
+const clockSkew = time.Minute
+
+// discovery is the configuration of the provider, published at {issuer}/.well-known/openid-configuration.
+type discovery struct {
+	Issuer                      string `json:"issuer"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	UserInfoEndpoint            string `json:"userinfo_endpoint"`
+	JWKSURI                     string `json:"jwks_uri"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// publicKey returns the RSA or the ECDSA key. It returns nil if the type of the key is not supported.
+func (k *jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func newOIDCProvider(conf config.OIDCProvider, httpClient *http.Client) Provider {
+	return &oidcProvider{
+		client: client{
+			clientID:     conf.ClientID,
+			clientSecret: string(conf.ClientSecret),
+			scopes:       conf.Scopes,
+			httpClient:   httpClient,
+		},
+		conf: conf,
+	}
+}
+
+// oidcProvider discovers the endpoints of the provider the first time they are needed, so Perses can start while the provider is unavailable.
+type oidcProvider struct {
+	Provider
+	client
+	conf      config.OIDCProvider
+	mutex     sync.Mutex
+	discovery *discovery
+	// keys are the public keys signing the ID tokens, by ID. They are loaded again when a token is signed by an unknown key.
+	keys map[string]interface{}
+}
+
+func (p *oidcProvider) Info() api.AuthProvider {
+	return api.AuthProvider{Kind: api.AuthProviderKindOIDC, SlugID: p.conf.SlugID, Name: p.conf.Name}
+}
+
+func (p *oidcProvider) RedirectURI() string {
+	return p.conf.RedirectURI
+}
+
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, redirectURI string, state string, nonce string, codeVerifier string) (string, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+	return p.authCodeURL(d.AuthorizationEndpoint, redirectURI, state, nonce, codeVerifier)
+}
+
+func (p *oidcProvider) Exchange(ctx context.Context, redirectURI string, code string, codeVerifier string, nonce string) (*Identity, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := p.exchange(ctx, d.TokenEndpoint, redirectURI, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	return p.identity(ctx, d, token, nonce)
+}
+
+func (p *oidcProvider) DeviceCode(ctx context.Context) (*api.DeviceCodeResponse, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.DeviceAuthorizationEndpoint) == 0 {
+		return nil, ErrDeviceCodeNotSupported
+	}
+	return p.deviceCode(ctx, d.DeviceAuthorizationEndpoint)
+}
+
+func (p *oidcProvider) DeviceAccessToken(ctx context.Context, deviceCode string) (*Identity, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := p.deviceAccessToken(ctx, d.TokenEndpoint, deviceCode)
+	if err != nil {
+		return nil, err
+	}
+	// there is no nonce in the device authorization flow.
+	return p.identity(ctx, d, token, "")
+}
+
+// identity returns the user described by the ID token. The claims missing in the ID token are read from the user info endpoint,
+// as some providers only put the profile of the user there.
+func (p *oidcProvider) identity(ctx context.Context, d *discovery, token *tokenResponse, nonce string) (*Identity, error) {
+	if len(token.IDToken) == 0 {
+		return nil, fmt.Errorf("the token response of the provider doesn't contain an ID token")
+	}
+	claims, err := p.verifyIDToken(ctx, token.IDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+	if (len(stringClaim(claims, p.conf.LoginClaim)) == 0 || claims[p.conf.GroupsClaim] == nil) && len(d.UserInfoEndpoint) > 0 {
+		userInfo := make(map[string]interface{})
+		if getErr := p.get(ctx, d.UserInfoEndpoint, token.AccessToken, &userInfo); getErr != nil {
+			return nil, getErr
+		}
+		// the user info must be the ones of the user of the ID token.
+		if stringClaim(userInfo, "sub") != stringClaim(claims, "sub") {
+			return nil, fmt.Errorf("the subject of the user info doesn't match the one of the ID token")
+		}
+		for key, value := range userInfo {
+			if _, exists := claims[key]; !exists {
+				claims[key] = value
+			}
+		}
+	}
+	identity := &Identity{
+		Issuer:    p.conf.Issuer,
+		Subject:   stringClaim(claims, "sub"),
+		Login:     stringClaim(claims, p.conf.LoginClaim),
+		FirstName: stringClaim(claims, "given_name"),
+		LastName:  stringClaim(claims, "family_name"),
+		Groups:    stringsClaim(claims, p.conf.GroupsClaim),
+	}
+	if len(identity.Subject) == 0 {
+		return nil, fmt.Errorf("the claim \"sub\" of the ID token is missing")
+	}
+	if len(identity.Login) == 0 {
+		return nil, fmt.Errorf("the claim %q containing the login of the user is missing", p.conf.LoginClaim)
+	}
+	return identity, nil
+}
+
+// verifyIDToken returns the claims of the ID token once its signature, its issuer, its audience, its expiration and its nonce are verified.
+func (p *oidcProvider) verifyIDToken(ctx context.Context, rawIDToken string, nonce string) (map[string]interface{}, error) {
+	claims := jwt.MapClaims{}
+	// the claims are verified below, with a tolerance for the clock skew.
+	parser := &jwt.Parser{UseJSONNumber: true, SkipClaimsValidation: true}
+	_, err := parser.ParseWithClaims(rawIDToken, claims, func(t *jwt.Token) (interface{}, error) {
+		// the algorithms using a shared secret, or none, are not accepted: the token must be signed by the provider.
+		switch t.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("unexpected signing method %q", t.Header["alg"])
+		}
+		kid, _ := t.Header["kid"].(string)
+		return p.getKey(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+	if issuer := stringClaim(claims, "iss"); issuer != p.conf.Issuer {
+		return nil, fmt.Errorf("invalid ID token: unexpected issuer %q", issuer)
+	}
+	if !contains(stringsClaim(claims, "aud"), p.clientID) {
+		return nil, fmt.Errorf("invalid ID token: the client %q is not in the audience", p.clientID)
+	}
+	expiresAt := numericClaim(claims, "exp")
+	if expiresAt == 0 || time.Now().After(time.Unix(expiresAt, 0).Add(clockSkew)) {
+		return nil, fmt.Errorf("invalid ID token: it has expired")
+	}
+	if len(nonce) > 0 && stringClaim(claims, "nonce") != nonce {
+		return nil, fmt.Errorf("invalid ID token: unexpected nonce")
+	}
+	return claims, nil
+}
+
+func (p *oidcProvider) getDiscovery(ctx context.Context) (*discovery, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+	d := &discovery{}
+	if err := p.get(ctx, strings.TrimSuffix(p.conf.Issuer, "/")+"/.well-known/openid-configuration", "", d); err != nil {
+		return nil, fmt.Errorf("unable to discover the provider %q: %w", p.conf.SlugID, err)
+	}
+	if d.Issuer != p.conf.Issuer {
+		return nil, fmt.Errorf("the issuer %q of the provider %q doesn't match the one configured", d.Issuer, p.conf.SlugID)
+	}
+	if len(d.AuthorizationEndpoint) == 0 || len(d.TokenEndpoint) == 0 || len(d.JWKSURI) == 0 {
+		return nil, fmt.Errorf("the configuration of the provider %q is incomplete", p.conf.SlugID)
+	}
+	p.discovery = d
+	return d, nil
+}
+
+// getKey returns the key of the ID. A token without key ID is accepted when the provider has a single key.
+func (p *oidcProvider) getKey(ctx context.Context, kid string) (interface{}, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	lookup := func() (interface{}, bool) {
+		if len(kid) == 0 && len(p.keys) == 1 {
+			for _, key := range p.keys {
+				return key, true
+			}
+		}
+		key, exists := p.keys[kid]
+		return key, exists
+	}
+	if key, exists := lookup(); exists {
+		return key, nil
+	}
+	// the keys may have been rotated since they have been loaded.
+	keySet := &jsonWebKeySet{}
+	if getErr := p.get(ctx, d.JWKSURI, "", keySet); getErr != nil {
+		return nil, fmt.Errorf("unable to get the keys of the provider %q: %w", p.conf.SlugID, getErr)
+	}
+	keys := make(map[string]interface{}, len(keySet.Keys))
+	for i := range keySet.Keys {
+		jwk := &keySet.Keys[i]
+		if jwk.Use == "enc" {
+			continue
+		}
+		key, keyErr := jwk.publicKey()
+		if keyErr != nil {
+			return nil, fmt.Errorf("unable to decode the key %q of the provider %q: %w", jwk.Kid, p.conf.SlugID, keyErr)
+		}
+		if key != nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	p.keys = keys
+	if key, exists := lookup(); exists {
+		return key, nil
+	}
+	return nil, fmt.Errorf("the key %q is unknown", kid)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
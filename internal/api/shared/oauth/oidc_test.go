@@ -0,0 +1,186 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/perses/perses/internal/api/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestOIDCProvider returns a provider whose discovery and keys are served by a test server.
+func newTestOIDCProvider(t *testing.T, key *rsa.PrivateKey) (*oidcProvider, *httptest.Server) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"jwks_uri":               server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "test",
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	conf := config.OIDCProvider{SlugID: "test", ClientID: "perses", Issuer: server.URL}
+	if err := conf.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	return newOIDCProvider(conf, server.Client()).(*oidcProvider), server
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider, server := newTestOIDCProvider(t, key)
+	defer server.Close()
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":    server.URL,
+			"sub":    "1234",
+			"aud":    []string{"perses", "other"},
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"nonce":  "nonce",
+			"groups": []string{"sre"},
+		}
+	}
+	sign := func(method jwt.SigningMethod, kid string, signingKey interface{}, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		if len(kid) > 0 {
+			token.Header["kid"] = kid
+		}
+		signed, signErr := token.SignedString(signingKey)
+		if signErr != nil {
+			t.Fatal(signErr)
+		}
+		return signed
+	}
+	testSuite := []struct {
+		title   string
+		token   func() string
+		isValid bool
+	}{
+		{
+			title: "valid token",
+			token: func() string {
+				return sign(jwt.SigningMethodRS256, "test", key, validClaims())
+			},
+			isValid: true,
+		},
+		{
+			title: "token without key ID",
+			token: func() string {
+				return sign(jwt.SigningMethodRS256, "", key, validClaims())
+			},
+			isValid: true,
+		},
+		{
+			title: "signed by another key",
+			token: func() string {
+				return sign(jwt.SigningMethodRS256, "test", otherKey, validClaims())
+			},
+		},
+		{
+			title: "unknown key ID",
+			token: func() string {
+				return sign(jwt.SigningMethodRS256, "unknown", key, validClaims())
+			},
+		},
+		{
+			title: "signed with a shared secret",
+			token: func() string {
+				return sign(jwt.SigningMethodHS256, "test", []byte("secret"), validClaims())
+			},
+		},
+		{
+			title: "other issuer",
+			token: func() string {
+				claims := validClaims()
+				claims["iss"] = "https://other.example.com"
+				return sign(jwt.SigningMethodRS256, "test", key, claims)
+			},
+		},
+		{
+			title: "other audience",
+			token: func() string {
+				claims := validClaims()
+				claims["aud"] = "other"
+				return sign(jwt.SigningMethodRS256, "test", key, claims)
+			},
+		},
+		{
+			title: "expired",
+			token: func() string {
+				claims := validClaims()
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+				return sign(jwt.SigningMethodRS256, "test", key, claims)
+			},
+		},
+		{
+			title: "other nonce",
+			token: func() string {
+				claims := validClaims()
+				claims["nonce"] = "other"
+				return sign(jwt.SigningMethodRS256, "test", key, claims)
+			},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			claims, verifyErr := provider.verifyIDToken(context.Background(), test.token(), "nonce")
+			if !test.isValid {
+				assert.Error(t, verifyErr)
+				return
+			}
+			if assert.NoError(t, verifyErr) {
+				assert.Equal(t, "1234", stringClaim(claims, "sub"))
+				assert.Equal(t, []string{"sre"}, stringsClaim(claims, "groups"))
+			}
+		})
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	c := &client{clientID: "perses", scopes: []string{"openid", "email"}}
+	authCodeURL, err := c.authCodeURL("https://idp.example.com/authorize?prompt=login", "https://perses.dev/callback", "state", "nonce", "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	assert.NoError(t, err)
+	// the verifier and its challenge are the ones of the example of the RFC 7636.
+	assert.Equal(t, "https://idp.example.com/authorize?client_id=perses&code_challenge=E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"+
+		"&code_challenge_method=S256&nonce=nonce&prompt=login&redirect_uri=https%3A%2F%2Fperses.dev%2Fcallback&response_type=code&scope=openid+email&state=state", authCodeURL)
+}
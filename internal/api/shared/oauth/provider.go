@@ -0,0 +1,108 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/perses/perses/internal/api/config"
+	"github.com/perses/perses/pkg/model/api"
+)
+
+func newOAuthProvider(conf config.OAuthProvider, httpClient *http.Client) Provider {
+	return &oauthProvider{
+		client: client{
+			clientID:     conf.ClientID,
+			clientSecret: string(conf.ClientSecret),
+			scopes:       conf.Scopes,
+			httpClient:   httpClient,
+		},
+		conf: conf,
+	}
+}
+
+// oauthProvider is a provider implementing only OAuth 2.0. The user is the one returned by the user info endpoint.
+type oauthProvider struct {
+	Provider
+	client
+	conf config.OAuthProvider
+}
+
+func (p *oauthProvider) Info() api.AuthProvider {
+	return api.AuthProvider{Kind: api.AuthProviderKindOAuth, SlugID: p.conf.SlugID, Name: p.conf.Name}
+}
+
+func (p *oauthProvider) RedirectURI() string {
+	return p.conf.RedirectURI
+}
+
+func (p *oauthProvider) AuthCodeURL(_ context.Context, redirectURI string, state string, _ string, codeVerifier string) (string, error) {
+	// the nonce is part of OpenID Connect, OAuth 2.0 only has the state.
+	return p.authCodeURL(p.conf.AuthURL, redirectURI, state, "", codeVerifier)
+}
+
+func (p *oauthProvider) Exchange(ctx context.Context, redirectURI string, code string, codeVerifier string, _ string) (*Identity, error) {
+	token, err := p.exchange(ctx, p.conf.TokenURL, redirectURI, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	return p.identity(ctx, token)
+}
+
+func (p *oauthProvider) DeviceCode(ctx context.Context) (*api.DeviceCodeResponse, error) {
+	if len(p.conf.DeviceAuthURL) == 0 {
+		return nil, ErrDeviceCodeNotSupported
+	}
+	return p.deviceCode(ctx, p.conf.DeviceAuthURL)
+}
+
+func (p *oauthProvider) DeviceAccessToken(ctx context.Context, deviceCode string) (*Identity, error) {
+	if len(p.conf.DeviceAuthURL) == 0 {
+		return nil, ErrDeviceCodeNotSupported
+	}
+	token, err := p.deviceAccessToken(ctx, p.conf.TokenURL, deviceCode)
+	if err != nil {
+		return nil, err
+	}
+	return p.identity(ctx, token)
+}
+
+// identity returns the user of the access token. The user is identified by the field sub or id of the user info, or else by its login.
+func (p *oauthProvider) identity(ctx context.Context, token *tokenResponse) (*Identity, error) {
+	userInfo := make(map[string]interface{})
+	if err := p.get(ctx, p.conf.UserInfoURL, token.AccessToken, &userInfo); err != nil {
+		return nil, err
+	}
+	identity := &Identity{
+		Issuer: p.conf.AuthURL,
+		Login:  stringClaim(userInfo, p.conf.LoginClaim),
+	}
+	if len(identity.Login) == 0 {
+		return nil, fmt.Errorf("the field %q containing the login of the user is missing in the user info", p.conf.LoginClaim)
+	}
+	for _, name := range []string{"sub", "id"} {
+		if identity.Subject = stringClaim(userInfo, name); len(identity.Subject) > 0 {
+			break
+		}
+	}
+	if len(identity.Subject) == 0 {
+		identity.Subject = identity.Login
+	}
+	if len(p.conf.GroupsClaim) > 0 {
+		identity.Groups = stringsClaim(userInfo, p.conf.GroupsClaim)
+	}
+	return identity, nil
+}
@@ -0,0 +1,619 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/perses/perses/internal/api/config"
+	"github.com/perses/perses/internal/api/interface/v1/audit"
+	"github.com/perses/perses/internal/api/interface/v1/dashboard"
+	"github.com/perses/perses/internal/api/interface/v1/datasource"
+	"github.com/perses/perses/internal/api/interface/v1/folder"
+	"github.com/perses/perses/internal/api/interface/v1/globaldatasource"
+	"github.com/perses/perses/internal/api/interface/v1/globalrole"
+	"github.com/perses/perses/internal/api/interface/v1/globalrolebinding"
+	"github.com/perses/perses/internal/api/interface/v1/globalsecret"
+	"github.com/perses/perses/internal/api/interface/v1/globalvariable"
+	"github.com/perses/perses/internal/api/interface/v1/globalwebhook"
+	"github.com/perses/perses/internal/api/interface/v1/project"
+	"github.com/perses/perses/internal/api/interface/v1/role"
+	"github.com/perses/perses/internal/api/interface/v1/rolebinding"
+	"github.com/perses/perses/internal/api/interface/v1/search"
+	"github.com/perses/perses/internal/api/interface/v1/secret"
+	"github.com/perses/perses/internal/api/interface/v1/serviceaccount"
+	"github.com/perses/perses/internal/api/interface/v1/trash"
+	"github.com/perses/perses/internal/api/interface/v1/user"
+	"github.com/perses/perses/internal/api/interface/v1/variable"
+	"github.com/perses/perses/internal/api/interface/v1/webhook"
+	"github.com/perses/perses/internal/api/shared"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/prometheus/common/version"
+)
+
+const (
+	// PathOpenAPI is the path, relative to /api, where the document is served.
+	PathOpenAPI = "/openapi.json"
+
+	contentTypeJSON  = "application/json"
+	contentTypeGzip  = "application/gzip"
+	contentTypeYAML  = "application/yaml"
+	securityBearer   = "bearerAuth"
+	schemaError      = "Error"
+	schemaJSONPatch  = "JSONPatchOperation"
+	headerPassphrase = "X-Backup-Passphrase"
+	paramAuthKind    = "kind"
+	paramAuthSlugID  = "slugID"
+)
+
+// resource is a kind whose CRUD endpoint is generated.
+type resource struct {
+	kind v1.Kind
+	path string
+	// entity is the struct sent in the body of the requests creating or updating the resource.
+	entity api.Entity
+	// public is the struct returned by the API. It differs from the entity when the API hides some of its fields.
+	public interface{}
+	// query is the struct bound to the query parameters of the list.
+	query interface{}
+}
+
+var resources = []resource{
+	{kind: v1.KindDashboard, path: shared.PathDashboard, entity: &v1.Dashboard{}, query: &dashboard.Query{}},
+	{kind: v1.KindDatasource, path: shared.PathDatasource, entity: &v1.Datasource{}, query: &datasource.Query{}},
+	{kind: v1.KindFolder, path: shared.PathFolder, entity: &v1.Folder{}, query: &folder.Query{}},
+	{kind: v1.KindGlobalDatasource, path: shared.PathGlobalDatasource, entity: &v1.GlobalDatasource{}, query: &globaldatasource.Query{}},
+	{kind: v1.KindGlobalRole, path: shared.PathGlobalRole, entity: &v1.GlobalRole{}, query: &globalrole.Query{}},
+	{kind: v1.KindGlobalRoleBinding, path: shared.PathGlobalRoleBinding, entity: &v1.GlobalRoleBinding{}, query: &globalrolebinding.Query{}},
+	{kind: v1.KindGlobalSecret, path: shared.PathGlobalSecret, entity: &v1.GlobalSecret{}, public: &v1.PublicGlobalSecret{}, query: &globalsecret.Query{}},
+	{kind: v1.KindGlobalVariable, path: shared.PathGlobalVariable, entity: &v1.GlobalVariable{}, query: &globalvariable.Query{}},
+	{kind: v1.KindGlobalWebhook, path: shared.PathGlobalWebhook, entity: &v1.GlobalWebhook{}, query: &globalwebhook.Query{}},
+	{kind: v1.KindProject, path: shared.PathProject, entity: &v1.Project{}, query: &project.Query{}},
+	{kind: v1.KindRole, path: shared.PathRole, entity: &v1.Role{}, query: &role.Query{}},
+	{kind: v1.KindRoleBinding, path: shared.PathRoleBinding, entity: &v1.RoleBinding{}, query: &rolebinding.Query{}},
+	{kind: v1.KindSecret, path: shared.PathSecret, entity: &v1.Secret{}, public: &v1.PublicSecret{}, query: &secret.Query{}},
+	{kind: v1.KindServiceAccount, path: shared.PathServiceAccount, entity: &v1.ServiceAccount{}, query: &serviceaccount.Query{}},
+	{kind: v1.KindUser, path: shared.PathUser, entity: &v1.User{}, public: &v1.PublicUser{}, query: &user.Query{}},
+	{kind: v1.KindVariable, path: shared.PathVariable, entity: &v1.Variable{}, query: &variable.Query{}},
+	{kind: v1.KindWebhook, path: shared.PathWebhook, entity: &v1.Webhook{}, query: &webhook.Query{}},
+}
+
+// appliedEntities are the kinds accepted by the endpoint /apply.
+var appliedEntities = []interface{}{
+	&v1.Project{}, &v1.GlobalSecret{}, &v1.Secret{}, &v1.GlobalDatasource{}, &v1.Datasource{},
+	&v1.GlobalVariable{}, &v1.Variable{}, &v1.Dashboard{}, &v1.Folder{},
+}
+
+type builder struct {
+	gen           *generator
+	doc           *Document
+	errorResponse *Response
+}
+
+// New returns the description of every route of the API. The routes modifying the resources are described even
+// though they are not registered when the server is read only.
+func New() *Document {
+	gen := newGenerator()
+	b := &builder{
+		gen: gen,
+		doc: &Document{
+			OpenAPI: Version,
+			Info: Info{
+				Title:       "Perses API",
+				Description: "The API of Perses. When the server is read only, the operations modifying the resources are not available.",
+				Version:     version.Version,
+			},
+			// The empty requirement means the API can be used anonymously, when the authentication is disabled.
+			Security: []map[string][]string{{securityBearer: {}}, {}},
+			Paths:    make(map[string]PathItem),
+			Components: Components{
+				Schemas: gen.schemas,
+				SecuritySchemes: map[string]*SecurityScheme{
+					securityBearer: {
+						Type:         "http",
+						Scheme:       "bearer",
+						BearerFormat: "JWT",
+						Description:  "The access token returned by the login, or the token of a service account.",
+					},
+				},
+			},
+		},
+	}
+	gen.schemas[schemaError] = &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"message": {Type: "string"}},
+		Required:   []string{"message"},
+	}
+	gen.schemas[schemaJSONPatch] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"op":    {Type: "string", Enum: []string{"add", "remove", "replace", "move", "copy", "test"}},
+			"path":  {Type: "string"},
+			"from":  {Type: "string"},
+			"value": {},
+		},
+		Required: []string{"op", "path"},
+	}
+	b.errorResponse = &Response{
+		Description: "The error that occurred.",
+		Content:     jsonContent(&Schema{Ref: componentSchemaPrefix + schemaError}),
+	}
+	for _, r := range resources {
+		b.addResource(r)
+	}
+	b.addAPIV1()
+	b.addAPI()
+	return b.doc
+}
+
+// add describes the operation available on the path with the given method.
+func (b *builder) add(method string, path string, op *Operation) {
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		b.doc.Paths[path] = item
+	}
+	key := strings.ToLower(method)
+	if _, exist := item[key]; exist {
+		panic(fmt.Sprintf("openapi: the operation %s %s is described twice", method, path))
+	}
+	if op.Responses == nil {
+		op.Responses = make(map[string]*Response)
+	}
+	op.Responses["default"] = b.errorResponse
+	item[key] = op
+}
+
+func (b *builder) addResource(r resource) {
+	kind := string(r.kind)
+	plural := kind + "s"
+	tags := []string{kind}
+	public := r.public
+	if public == nil {
+		public = r.entity
+	}
+	publicSchema := b.gen.of(public)
+	entityBody := jsonBody(b.gen.of(r.entity))
+	listParameters := append(b.gen.queryParameters(reflect.TypeOf(r.query)), &Parameter{
+		Name:        "watch",
+		In:          "query",
+		Description: "Send the resources as ADDED events, and then every change made to them, until the client closes the connection.",
+		Schema:      &Schema{Type: "boolean"},
+	})
+	listResponses := map[string]*Response{
+		"200": {
+			Description: fmt.Sprintf("The list of %s.", plural),
+			Headers: map[string]*Header{
+				shared.HeaderContinue: {
+					Description: "The token to send with the parameter continue to get the next page.",
+					Schema:      &Schema{Type: "string"},
+				},
+			},
+			Content: map[string]*MediaType{
+				contentTypeJSON:          {Schema: &Schema{Type: "array", Items: publicSchema}},
+				shared.ContentTypeNDJSON: {Schema: b.gen.of(&v1.WatchEvent{})},
+			},
+		},
+	}
+	entityResponse := func(description string) map[string]*Response {
+		return map[string]*Response{
+			"200": {
+				Description: description,
+				Headers:     map[string]*Header{shared.HeaderETag: {Description: "The version of the resource.", Schema: &Schema{Type: "string"}}},
+				Content:     jsonContent(publicSchema),
+			},
+		}
+	}
+
+	collection := fmt.Sprintf("%s/%s", shared.APIV1Prefix, r.path)
+	item := fmt.Sprintf("%s/{%s}", collection, shared.ParamName)
+	var itemParameters []*Parameter
+	b.add(http.MethodGet, collection, &Operation{
+		OperationID: "list" + plural,
+		Tags:        tags,
+		Parameters:  listParameters,
+		Responses:   listResponses,
+	})
+	b.add(http.MethodPost, collection, &Operation{
+		OperationID: "create" + kind,
+		Tags:        tags,
+		RequestBody: entityBody,
+		Responses:   entityResponse(fmt.Sprintf("The %s created.", kind)),
+	})
+	if v1.ProjectKindMap[r.kind] {
+		projectCollection := fmt.Sprintf("%s/%s/{%s}/%s", shared.APIV1Prefix, shared.PathProject, shared.ParamProject, r.path)
+		item = fmt.Sprintf("%s/{%s}", projectCollection, shared.ParamName)
+		projectParameter := pathParameter(shared.ParamProject, "The name of the project.")
+		itemParameters = append(itemParameters, projectParameter)
+		projectListParameters := []*Parameter{projectParameter}
+		for _, parameter := range listParameters {
+			if parameter.Name != shared.ParamProject {
+				projectListParameters = append(projectListParameters, parameter)
+			}
+		}
+		b.add(http.MethodGet, projectCollection, &Operation{
+			OperationID: "list" + plural + "InProject",
+			Tags:        tags,
+			Parameters:  projectListParameters,
+			Responses:   listResponses,
+		})
+		b.add(http.MethodPost, projectCollection, &Operation{
+			OperationID: "create" + kind + "InProject",
+			Tags:        tags,
+			Parameters:  []*Parameter{projectParameter},
+			RequestBody: entityBody,
+			Responses:   entityResponse(fmt.Sprintf("The %s created.", kind)),
+		})
+	}
+	itemParameters = append(itemParameters, pathParameter(shared.ParamName, fmt.Sprintf("The name of the %s.", kind)))
+	b.add(http.MethodGet, item, &Operation{
+		OperationID: "get" + kind,
+		Tags:        tags,
+		Parameters:  itemParameters,
+		Responses:   entityResponse(fmt.Sprintf("The %s.", kind)),
+	})
+	b.add(http.MethodPut, item, &Operation{
+		OperationID: "update" + kind,
+		Tags:        tags,
+		Parameters: append(itemParameters, &Parameter{
+			Name:        shared.HeaderIfMatch,
+			In:          "header",
+			Description: "The version of the resource the update is based on, as returned in the header ETag. The update is rejected with the status code 409 when the resource has been modified in the meantime. Without this header, the metadata.version of the resource is used instead, and the version is not checked when it is 0 or not set.",
+			Schema:      &Schema{Type: "string"},
+		}),
+		RequestBody: entityBody,
+		Responses:   entityResponse(fmt.Sprintf("The %s updated.", kind)),
+	})
+	b.add(http.MethodPatch, item, &Operation{
+		OperationID: "patch" + kind,
+		Tags:        tags,
+		Parameters:  itemParameters,
+		RequestBody: &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{
+				string(api.MergePatchType): {Schema: &Schema{Type: "object", AdditionalProperties: true}},
+				string(api.JSONPatchType):  {Schema: &Schema{Type: "array", Items: &Schema{Ref: componentSchemaPrefix + schemaJSONPatch}}},
+			},
+		},
+		Responses: entityResponse(fmt.Sprintf("The %s patched.", kind)),
+	})
+	b.add(http.MethodDelete, item, &Operation{
+		OperationID: "delete" + kind,
+		Tags:        tags,
+		Parameters:  itemParameters,
+		Responses:   map[string]*Response{"204": {Description: fmt.Sprintf("The %s has been deleted.", kind)}},
+	})
+}
+
+// addAPIV1 describes the routes prefixed by /api/v1 that are not generated.
+func (b *builder) addAPIV1() {
+	g := b.gen
+	var applied []*Schema
+	for _, entity := range appliedEntities {
+		applied = append(applied, g.of(entity))
+	}
+	b.add(http.MethodPost, fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathApply), &Operation{
+		OperationID: "apply",
+		Summary:     "Create or update a list of resources",
+		Tags:        []string{"Apply"},
+		Parameters: []*Parameter{{
+			Name:        "dryRun",
+			In:          "query",
+			Description: "Check the resources and return what would be done, without applying them.",
+			Schema:      &Schema{Type: "boolean"},
+		}},
+		RequestBody: &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{
+				contentTypeJSON: {Schema: &Schema{Type: "array", Items: &Schema{OneOf: applied}}},
+				contentTypeYAML: {Schema: &Schema{Type: "string", Description: "The resources as YAML documents."}},
+			},
+		},
+		Responses: okResponse("What has been done for each resource.", g.of(&v1.ApplyResponse{})),
+	})
+	b.add(http.MethodGet, fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathAudit), &Operation{
+		OperationID: "listAuditRecords",
+		Tags:        []string{"Audit"},
+		Parameters:  g.queryParameters(reflect.TypeOf(&audit.Query{})),
+		Responses:   okResponse("The changes made with the API, the most recent first.", g.listOf(&v1.AuditRecord{})),
+	})
+	b.add(http.MethodGet, shared.APIV1Prefix+"/health", &Operation{
+		OperationID: "health",
+		Tags:        []string{"Health"},
+		Responses: map[string]*Response{
+			"200": {Description: "The server is healthy.", Content: jsonContent(g.of(&v1.Health{}))},
+			"503": {Description: "The database is unavailable.", Content: jsonContent(g.of(&v1.Health{}))},
+		},
+	})
+	b.add(http.MethodGet, shared.APIV1Prefix+"/search", &Operation{
+		OperationID: "search",
+		Tags:        []string{"Search"},
+		Parameters:  g.queryParameters(reflect.TypeOf(&search.Query{})),
+		Responses:   okResponse("The resources matching the text, the most relevant first.", g.listOf(&v1.SearchResult{})),
+	})
+
+	// dashboard revisions
+	revisions := fmt.Sprintf("%s/%s/{%s}/%s/{%s}/%s", shared.APIV1Prefix, shared.PathProject, shared.ParamProject, shared.PathDashboard, shared.ParamName, shared.PathRevision)
+	revisionParameters := []*Parameter{
+		pathParameter(shared.ParamProject, "The name of the project."),
+		pathParameter(shared.ParamName, "The name of the Dashboard."),
+	}
+	versionParameters := append(revisionParameters, &Parameter{
+		Name:     shared.ParamVersion,
+		In:       "path",
+		Required: true,
+		Schema:   &Schema{Type: "integer", Format: "int64"},
+	})
+	b.add(http.MethodGet, revisions, &Operation{
+		OperationID: "listDashboardRevisions",
+		Tags:        []string{string(v1.KindDashboard)},
+		Parameters:  revisionParameters,
+		Responses:   okResponse("The revisions of the dashboard, the most recent first.", g.listOf(&v1.DashboardRevision{})),
+	})
+	b.add(http.MethodGet, fmt.Sprintf("%s/{%s}", revisions, shared.ParamVersion), &Operation{
+		OperationID: "getDashboardRevision",
+		Tags:        []string{string(v1.KindDashboard)},
+		Parameters:  versionParameters,
+		Responses:   okResponse("The revision.", g.of(&v1.DashboardRevision{})),
+	})
+	b.add(http.MethodPost, fmt.Sprintf("%s/{%s}/restore", revisions, shared.ParamVersion), &Operation{
+		OperationID: "restoreDashboardRevision",
+		Tags:        []string{string(v1.KindDashboard)},
+		Parameters:  versionParameters,
+		Responses:   okResponse("The dashboard updated with the content of the revision.", g.of(&v1.Dashboard{})),
+	})
+
+	// service account tokens
+	tokens := fmt.Sprintf("%s/%s/{%s}/%s", shared.APIV1Prefix, shared.PathServiceAccount, shared.ParamName, shared.PathToken)
+	tokenParameters := []*Parameter{pathParameter(shared.ParamName, "The name of the ServiceAccount.")}
+	b.add(http.MethodGet, tokens, &Operation{
+		OperationID: "listServiceAccountTokens",
+		Tags:        []string{string(v1.KindServiceAccount)},
+		Parameters:  tokenParameters,
+		Responses:   okResponse("The tokens of the service account, without their hash.", g.listOf(v1.APIToken{})),
+	})
+	b.add(http.MethodPost, tokens, &Operation{
+		OperationID: "createServiceAccountToken",
+		Tags:        []string{string(v1.KindServiceAccount)},
+		Parameters:  tokenParameters,
+		RequestBody: jsonBody(g.of(&v1.APITokenRequest{})),
+		Responses:   okResponse("The token created. It is the only time the token itself is returned.", g.of(&v1.APITokenResponse{})),
+	})
+	b.add(http.MethodDelete, fmt.Sprintf("%s/{%s}", tokens, shared.ParamToken), &Operation{
+		OperationID: "revokeServiceAccountToken",
+		Tags:        []string{string(v1.KindServiceAccount)},
+		Parameters:  append(tokenParameters, pathParameter(shared.ParamToken, "The name of the token.")),
+		Responses:   map[string]*Response{"204": {Description: "The token has been revoked."}},
+	})
+
+	// webhook deliveries
+	b.addWebhookDeliveries(v1.KindWebhook, fmt.Sprintf("%s/%s/{%s}/%s/{%s}/%s", shared.APIV1Prefix, shared.PathProject, shared.ParamProject, shared.PathWebhook, shared.ParamName, shared.PathDelivery),
+		pathParameter(shared.ParamProject, "The name of the project."))
+	b.addWebhookDeliveries(v1.KindGlobalWebhook, fmt.Sprintf("%s/%s/{%s}/%s", shared.APIV1Prefix, shared.PathGlobalWebhook, shared.ParamName, shared.PathDelivery))
+
+	// trash
+	trashPath := fmt.Sprintf("%s/%s", shared.APIV1Prefix, shared.PathTrash)
+	trashEntry := fmt.Sprintf("%s/{%s}", trashPath, shared.ParamName)
+	trashTags := []string{string(v1.KindTrashEntry)}
+	trashQuery := g.queryParameters(reflect.TypeOf(&trash.Query{}))
+	entryParameters := []*Parameter{pathParameter(shared.ParamName, "The name of the entry.")}
+	b.add(http.MethodGet, trashPath, &Operation{
+		OperationID: "listTrashEntries",
+		Tags:        trashTags,
+		Parameters:  trashQuery,
+		Responses:   okResponse("The entries of the trash, the most recent first, without their content.", g.listOf(&v1.TrashEntry{})),
+	})
+	b.add(http.MethodDelete, trashPath, &Operation{
+		OperationID: "purgeTrashEntries",
+		Tags:        trashTags,
+		Parameters:  trashQuery,
+		Responses:   okResponse("The entries removed for good, without their content.", g.listOf(&v1.TrashEntry{})),
+	})
+	b.add(http.MethodGet, trashEntry, &Operation{
+		OperationID: "getTrashEntry",
+		Tags:        trashTags,
+		Parameters:  entryParameters,
+		Responses:   okResponse("The entry, without its content.", g.of(&v1.TrashEntry{})),
+	})
+	b.add(http.MethodDelete, trashEntry, &Operation{
+		OperationID: "purgeTrashEntry",
+		Tags:        trashTags,
+		Parameters:  entryParameters,
+		Responses:   map[string]*Response{"204": {Description: "The entry has been removed for good."}},
+	})
+	b.add(http.MethodPost, trashEntry+"/restore", &Operation{
+		OperationID: "restoreTrashEntry",
+		Tags:        trashTags,
+		Parameters:  entryParameters,
+		Responses: okResponse("The dashboard or the project restored.", &Schema{OneOf: []*Schema{
+			g.of(&v1.Dashboard{}), g.of(&v1.Project{}),
+		}}),
+	})
+}
+
+// addWebhookDeliveries describes the log of the deliveries of a kind of webhook.
+func (b *builder) addWebhookDeliveries(kind v1.Kind, deliveries string, parameters ...*Parameter) {
+	g := b.gen
+	tags := []string{string(kind)}
+	parameters = append(parameters, pathParameter(shared.ParamName, fmt.Sprintf("The name of the %s.", kind)))
+	// the slices are copied, so the parameters of an operation don't share the array of the others.
+	listParameters := append(append([]*Parameter{}, parameters...), &Parameter{
+		Name:        "status",
+		In:          "query",
+		Description: "Return only the deliveries with this status.",
+		Schema: &Schema{Type: "string", Enum: []string{
+			string(v1.WebhookDeliveryStatusPending), string(v1.WebhookDeliveryStatusSucceeded), string(v1.WebhookDeliveryStatusFailed),
+		}},
+	})
+	deliveryParameters := append(append([]*Parameter{}, parameters...), pathParameter(shared.ParamDelivery, "The name of the delivery."))
+	b.add(http.MethodGet, deliveries, &Operation{
+		OperationID: fmt.Sprintf("list%sDeliveries", kind),
+		Tags:        tags,
+		Parameters:  listParameters,
+		Responses:   okResponse("The deliveries of the events to the webhook, the most recent first.", g.listOf(&v1.WebhookDelivery{})),
+	})
+	b.add(http.MethodGet, fmt.Sprintf("%s/{%s}", deliveries, shared.ParamDelivery), &Operation{
+		OperationID: fmt.Sprintf("get%sDelivery", kind),
+		Tags:        tags,
+		Parameters:  deliveryParameters,
+		Responses:   okResponse("The delivery, with its attempts.", g.of(&v1.WebhookDelivery{})),
+	})
+	b.add(http.MethodPost, fmt.Sprintf("%s/{%s}/replay", deliveries, shared.ParamDelivery), &Operation{
+		OperationID: fmt.Sprintf("replay%sDelivery", kind),
+		Tags:        tags,
+		Parameters:  deliveryParameters,
+		Responses:   okResponse("The new delivery of the event of the delivery replayed.", g.of(&v1.WebhookDelivery{})),
+	})
+}
+
+// addAPI describes the routes prefixed by /api that are not versioned.
+func (b *builder) addAPI() {
+	g := b.gen
+	passphrase := &Parameter{
+		Name:        headerPassphrase,
+		In:          "header",
+		Description: "The passphrase used to encrypt the secrets of the archive. The encryption key of the server is used when it is not set.",
+		Schema:      &Schema{Type: "string"},
+	}
+	b.add(http.MethodGet, "/api/admin/export", &Operation{
+		OperationID: "exportBackup",
+		Tags:        []string{"Admin"},
+		Parameters:  []*Parameter{passphrase},
+		Responses: map[string]*Response{"200": {
+			Description: "The archive containing every resource of the server.",
+			Content:     map[string]*MediaType{contentTypeGzip: {Schema: &Schema{Type: "string", Format: "binary"}}},
+		}},
+	})
+	b.add(http.MethodPost, "/api/admin/import", &Operation{
+		OperationID: "importBackup",
+		Tags:        []string{"Admin"},
+		Parameters:  []*Parameter{passphrase},
+		RequestBody: &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{contentTypeGzip: {Schema: &Schema{Type: "string", Format: "binary"}}},
+		},
+		Responses: okResponse("The number of resources restored by kind.", g.of(&v1.BackupSummary{})),
+	})
+
+	// authentication
+	authTags := []string{"Auth"}
+	tokens := g.of(&api.AuthResponse{})
+	b.add(http.MethodPost, "/api/auth/login", &Operation{
+		OperationID: "login",
+		Tags:        authTags,
+		RequestBody: jsonBody(g.of(&api.Auth{})),
+		Responses:   okResponse("The tokens of the user.", tokens),
+	})
+	b.add(http.MethodPost, "/api/auth/refresh", &Operation{
+		OperationID: "refresh",
+		Tags:        authTags,
+		RequestBody: jsonBody(g.of(&api.RefreshRequest{})),
+		Responses:   okResponse("A new access token.", tokens),
+	})
+	b.add(http.MethodGet, "/api/auth/providers", &Operation{
+		OperationID: "listAuthProviders",
+		Tags:        authTags,
+		Responses:   okResponse("The OpenID Connect and OAuth 2.0 providers the users can log in with.", g.listOf(&api.AuthProvider{})),
+	})
+	provider := fmt.Sprintf("/api/auth/providers/{%s}/{%s}", paramAuthKind, paramAuthSlugID)
+	providerParameters := []*Parameter{
+		pathParameter(paramAuthKind, "The kind of the provider."),
+		pathParameter(paramAuthSlugID, "The identifier of the provider."),
+	}
+	b.add(http.MethodGet, provider+"/login", &Operation{
+		OperationID: "loginWithProvider",
+		Tags:        authTags,
+		Parameters:  providerParameters,
+		Responses:   map[string]*Response{"302": {Description: "The redirection to the provider."}},
+	})
+	var callbackParameters []*Parameter
+	for _, name := range []string{"code", "state", "error", "error_description"} {
+		callbackParameters = append(callbackParameters, &Parameter{Name: name, In: "query", Schema: &Schema{Type: "string"}})
+	}
+	b.add(http.MethodGet, provider+"/callback", &Operation{
+		OperationID: "providerCallback",
+		Tags:        authTags,
+		Parameters:  append(providerParameters, callbackParameters...),
+		Responses:   okResponse("The tokens of the user.", tokens),
+	})
+	b.add(http.MethodPost, provider+"/device/code", &Operation{
+		OperationID: "providerDeviceCode",
+		Tags:        authTags,
+		Parameters:  providerParameters,
+		Responses:   okResponse("The codes of the device authorization.", g.of(&api.DeviceCodeResponse{})),
+	})
+	b.add(http.MethodPost, provider+"/device/token", &Operation{
+		OperationID: "providerDeviceToken",
+		Tags:        authTags,
+		Parameters:  providerParameters,
+		RequestBody: jsonBody(g.of(&api.DeviceAccessTokenRequest{})),
+		Responses:   okResponse("The tokens of the user who approved the device.", tokens),
+	})
+
+	b.add(http.MethodGet, "/api/config", &Operation{
+		OperationID: "getConfig",
+		Tags:        []string{"Config"},
+		Responses:   okResponse("The configuration of the server.", g.of(&config.Config{})),
+	})
+	b.add(http.MethodPost, "/api/migrate", &Operation{
+		OperationID: "migrate",
+		Summary:     "Migrate a Grafana dashboard to a Perses dashboard",
+		Tags:        []string{"Migrate"},
+		RequestBody: jsonBody(g.of(&api.Migrate{})),
+		Responses:   okResponse("The dashboard migrated.", g.of(&v1.Dashboard{})),
+	})
+	b.add(http.MethodGet, "/api"+PathOpenAPI, &Operation{
+		OperationID: "getOpenAPI",
+		Tags:        []string{"OpenAPI"},
+		Responses:   okResponse("This document.", &Schema{Type: "object", AdditionalProperties: true}),
+	})
+	for _, validated := range []struct {
+		path   string
+		entity api.Entity
+	}{
+		{path: shared.PathDashboard, entity: &v1.Dashboard{}},
+		{path: shared.PathDatasource, entity: &v1.Datasource{}},
+		{path: shared.PathGlobalDatasource, entity: &v1.GlobalDatasource{}},
+		{path: shared.PathGlobalVariable, entity: &v1.GlobalVariable{}},
+		{path: shared.PathVariable, entity: &v1.Variable{}},
+	} {
+		kind := reflect.TypeOf(validated.entity).Elem().Name()
+		b.add(http.MethodPost, "/api/validate/"+validated.path, &Operation{
+			OperationID: "validate" + kind,
+			Tags:        []string{"Validate"},
+			RequestBody: jsonBody(g.of(validated.entity)),
+			Responses:   map[string]*Response{"200": {Description: fmt.Sprintf("The %s is valid, including the specs of its plugins.", kind)}},
+		})
+	}
+}
+
+func pathParameter(name string, description string) *Parameter {
+	return &Parameter{Name: name, In: "path", Description: description, Required: true, Schema: &Schema{Type: "string"}}
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{contentTypeJSON: {Schema: schema}}
+}
+
+func jsonBody(schema *Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: jsonContent(schema)}
+}
+
+func okResponse(description string, schema *Schema) map[string]*Response {
+	return map[string]*Response{"200": {Description: description, Content: jsonContent(schema)}}
+}
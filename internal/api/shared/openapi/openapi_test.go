@@ -0,0 +1,100 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pathParamMatcher = regexp.MustCompile(`{([^}]+)}`)
+
+// collectRefs returns every reference contained in the JSON value.
+func collectRefs(value interface{}, refs map[string]bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				refs[ref] = true
+				continue
+			}
+			collectRefs(child, refs)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectRefs(child, refs)
+		}
+	}
+}
+
+func TestReferencesExist(t *testing.T) {
+	doc := New()
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &raw))
+	refs := make(map[string]bool)
+	collectRefs(raw, refs)
+	assert.NotEmpty(t, refs)
+	for ref := range refs {
+		name, found := strings.CutPrefix(ref, componentSchemaPrefix)
+		if assert.Truef(t, found, "unexpected reference %q", ref) {
+			assert.Containsf(t, doc.Components.Schemas, name, "the schema referenced by %q doesn't exist", ref)
+		}
+	}
+}
+
+func TestOperations(t *testing.T) {
+	operationIDs := make(map[string]bool)
+	for path, item := range New().Paths {
+		var expectedParams []string
+		for _, match := range pathParamMatcher.FindAllStringSubmatch(path, -1) {
+			expectedParams = append(expectedParams, match[1])
+		}
+		for method, op := range item {
+			assert.Falsef(t, operationIDs[op.OperationID], "the operation ID %q is used twice", op.OperationID)
+			operationIDs[op.OperationID] = true
+			var params []string
+			for _, param := range op.Parameters {
+				if param.In == "path" {
+					assert.Truef(t, param.Required, "the parameter %q of %s %s must be required", param.Name, method, path)
+					params = append(params, param.Name)
+				}
+			}
+			assert.Equalf(t, expectedParams, params, "wrong path parameters for %s %s", method, path)
+		}
+	}
+}
+
+func TestPluginSpecIsOpen(t *testing.T) {
+	plugin := New().Components.Schemas["Plugin"]
+	require.NotNil(t, plugin)
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: true}, plugin.Properties["spec"])
+}
+
+func TestModelSchemas(t *testing.T) {
+	doc := New()
+	for _, name := range []string{"Dashboard", "ProjectMetadata", "Plugin", "VariableListSpec", "DashboardLayout", "SecretBasicAuth"} {
+		assert.Contains(t, doc.Components.Schemas, name)
+	}
+	metadata := doc.Components.Schemas["ProjectMetadata"]
+	require.NotNil(t, metadata)
+	assert.Equal(t, []string{"name", "createdAt", "updatedAt", "version", "project"}, metadata.Required)
+	assert.True(t, metadata.Properties["version"].ReadOnly)
+}
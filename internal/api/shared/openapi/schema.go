@@ -0,0 +1,293 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+	"github.com/perses/perses/pkg/model/api/v1/variable"
+	"github.com/prometheus/common/model"
+)
+
+const componentSchemaPrefix = "#/components/schemas/"
+
+// unprefixedPackages are the packages of the model whose types keep their name in the components of the document.
+var unprefixedPackages = map[string]bool{
+	reflect.TypeOf(api.Auth{}).PkgPath():      true,
+	reflect.TypeOf(v1.Metadata{}).PkgPath():   true,
+	reflect.TypeOf(common.Plugin{}).PkgPath(): true,
+}
+
+// customSchema returns the schema of the types that cannot be described from their fields, mostly because they have
+// their own way to be marshalled. The boolean component tells whether the schema is added to the components of the
+// document, or described inline. The function is nil when the type has no custom schema.
+func (g *generator) customSchema(t reflect.Type) (schema func() *Schema, component bool) {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return func() *Schema { return &Schema{Type: "string", Format: "date-time"} }, false
+	case reflect.TypeOf(model.Duration(0)):
+		return func() *Schema { return &Schema{Type: "string", Description: "A duration like 30s, 5m or 1h30m."} }, false
+	case reflect.TypeOf(json.RawMessage{}):
+		return func() *Schema { return &Schema{} }, false
+	case reflect.TypeOf(url.URL{}):
+		return func() *Schema { return &Schema{Type: "string", Format: "uri"} }, false
+	case reflect.TypeOf(common.Regexp{}):
+		return func() *Schema { return &Schema{Type: "string", Format: "regex"} }, false
+	case reflect.TypeOf(variable.DefaultValue{}):
+		return func() *Schema {
+			return &Schema{OneOf: []*Schema{{Type: "string"}, {Type: "array", Items: &Schema{Type: "string"}}}}
+		}, false
+	case reflect.TypeOf(v1.Kind("")):
+		return func() *Schema { return &Schema{Type: "string", Enum: sortedKeys(v1.PluralKindMap)} }, true
+	case reflect.TypeOf(common.Plugin{}):
+		return func() *Schema {
+			return &Schema{
+				Type:        "object",
+				Description: "The plugin is identified by its kind. The content of its spec depends on the kind, and is validated by the CUE schema of the plugin.",
+				Properties: map[string]*Schema{
+					"kind": {Type: "string"},
+					"spec": {Type: "object", AdditionalProperties: true},
+				},
+				Required: []string{"kind", "spec"},
+			}
+		}, true
+	case reflect.TypeOf(v1.VariableSpec{}):
+		return func() *Schema {
+			return g.kindAndSpec(sortedKeys(variable.KindMap), variable.ListSpec{}, variable.TextSpec{})
+		}, true
+	case reflect.TypeOf(dashboard.Variable{}):
+		return func() *Schema {
+			return g.kindAndSpec(sortedKeys(variable.KindMap), dashboard.ListVariableSpec{}, dashboard.TextVariableSpec{})
+		}, true
+	case reflect.TypeOf(dashboard.Layout{}):
+		return func() *Schema {
+			return g.kindAndSpec([]string{string(dashboard.KindGridLayout)}, dashboard.GridLayoutSpec{})
+		}, true
+	}
+	return nil, false
+}
+
+// readOnlyFields contains the fields filled by the server, by type.
+var readOnlyFields = map[reflect.Type]map[string]bool{
+	reflect.TypeOf(v1.Metadata{}): {"createdAt": true, "updatedAt": true, "version": true},
+}
+
+func sortedKeys[K ~string, V any](m map[K]V) []string {
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, string(key))
+	}
+	sort.Strings(result)
+	return result
+}
+
+// generator generates the schemas of the Go types. The named structs are added to the components of the document
+// and referenced, the other types are described inline.
+type generator struct {
+	schemas map[string]*Schema
+	types   map[string]reflect.Type
+}
+
+func newGenerator() *generator {
+	return &generator{
+		schemas: make(map[string]*Schema),
+		types:   make(map[string]reflect.Type),
+	}
+}
+
+// componentName returns the name of the schema of the type in the components of the document.
+// The types of the main packages of the model are not prefixed, the other ones are prefixed by the name of their package.
+func componentName(t reflect.Type) string {
+	if unprefixedPackages[t.PkgPath()] {
+		return t.Name()
+	}
+	pkg := path.Base(t.PkgPath())
+	return capitalize(pkg) + capitalize(t.Name())
+}
+
+func capitalize(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// of returns the schema of the type of the value.
+func (g *generator) of(value interface{}) *Schema {
+	return g.schemaOf(reflect.TypeOf(value))
+}
+
+// listOf returns the schema of a list of values of the same type than the value.
+func (g *generator) listOf(value interface{}) *Schema {
+	return &Schema{Type: "array", Items: g.of(value)}
+}
+
+func (g *generator) schemaOf(t reflect.Type) *Schema {
+	if custom, component := g.customSchema(t); custom != nil {
+		if component {
+			return g.component(t, custom)
+		}
+		return custom()
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaOf(t.Elem())
+	case reflect.Interface:
+		// the content is unknown, any value is accepted.
+		return &Schema{}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes the slices of bytes in base64.
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if len(t.Name()) == 0 {
+			return g.structSchema(t)
+		}
+		return g.component(t, func() *Schema {
+			return g.structSchema(t)
+		})
+	}
+	panic(fmt.Sprintf("openapi: the type %s cannot be described", t))
+}
+
+// component adds the schema of the type to the components of the document, and returns a reference to it.
+func (g *generator) component(t reflect.Type, build func() *Schema) *Schema {
+	name := componentName(t)
+	if existing, ok := g.types[name]; !ok {
+		// the type is registered before building its schema, so a recursive type references itself.
+		g.types[name] = t
+		g.schemas[name] = build()
+	} else if existing != t {
+		panic(fmt.Sprintf("openapi: the types %s and %s have the same schema name %q", existing, t, name))
+	}
+	return &Schema{Ref: componentSchemaPrefix + name}
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(schema, t)
+	return schema
+}
+
+// addFields describes the fields of the struct like encoding/json marshals them.
+// The fields of the embedded structs without a JSON name are added to the parent.
+func (g *generator) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitempty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && len(name) == 0 && fieldType.Kind() == reflect.Struct {
+			g.addFields(schema, fieldType)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+		fieldSchema := g.schemaOf(field.Type)
+		if readOnlyFields[t][name] {
+			fieldSchema.ReadOnly = true
+		}
+		schema.Properties[name] = fieldSchema
+		if !omitempty && field.Type.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// kindAndSpec describes a struct whose spec depends on its kind.
+func (g *generator) kindAndSpec(kinds []string, specs ...interface{}) *Schema {
+	spec := &Schema{}
+	for _, value := range specs {
+		spec.OneOf = append(spec.OneOf, g.of(value))
+	}
+	if len(spec.OneOf) == 1 {
+		spec = spec.OneOf[0]
+	}
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"kind": {Type: "string", Enum: kinds},
+			"spec": spec,
+		},
+		Required: []string{"kind", "spec"},
+	}
+}
+
+// queryParameters describes the fields of the struct that are bound to the query parameters.
+func (g *generator) queryParameters(t reflect.Type) []*Parameter {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var result []*Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			result = append(result, g.queryParameters(field.Type)...)
+			continue
+		}
+		name := field.Tag.Get("query")
+		if len(name) == 0 {
+			continue
+		}
+		result = append(result, &Parameter{Name: name, In: "query", Schema: g.schemaOf(field.Type)})
+	}
+	return result
+}
+
+func jsonName(field reflect.StructField) (name string, omitempty bool, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	options := strings.Split(tag, ",")
+	for _, option := range options[1:] {
+		if option == "omitempty" {
+			omitempty = true
+		}
+	}
+	return options[0], omitempty, false
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/pkg/model/api"
+)
+
+// applyPatch applies the patch sent in the body of the request to the JSON document.
+// The content type of the request tells whether the patch is a JSON merge patch or a JSON patch.
+func applyPatch(ctx echo.Context, document []byte) ([]byte, error) {
+	contentType, _, err := mime.ParseMediaType(ctx.Request().Header.Get(echo.HeaderContentType))
+	if err != nil || (api.PatchType(contentType) != api.MergePatchType && api.PatchType(contentType) != api.JSONPatchType) {
+		return nil, echo.NewHTTPError(http.StatusUnsupportedMediaType, fmt.Sprintf("the content type of a patch must be %q or %q", api.MergePatchType, api.JSONPatchType))
+	}
+	body, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return nil, HandleBadRequestError(err.Error())
+	}
+	if api.PatchType(contentType) == api.MergePatchType {
+		result, mergeErr := jsonpatch.MergePatch(document, body)
+		if mergeErr != nil {
+			return nil, HandleBadRequestError(fmt.Sprintf("invalid merge patch: %s", mergeErr))
+		}
+		return result, nil
+	}
+	patch, err := jsonpatch.DecodePatch(body)
+	if err != nil {
+		return nil, HandleBadRequestError(fmt.Sprintf("invalid JSON patch: %s", err))
+	}
+	result, err := patch.Apply(document)
+	if err != nil {
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			// the resource is not in the state the client expects.
+			return nil, HandleVersionConflictError(err.Error())
+		}
+		return nil, HandleBadRequestError(fmt.Sprintf("unable to apply the JSON patch: %s", err))
+	}
+	return result, nil
+}
@@ -0,0 +1,209 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rbac computes the permissions of the users and of the service accounts from the roles and the role bindings.
+// The permissions are kept in memory. They are loaded from the database when the API starts, reloaded each time a role or a binding
+// is written, and reloaded periodically so the changes made by other instances of the API are eventually applied.
+package rbac
+
+import (
+	"context"
+	"sync"
+
+	"github.com/perses/common/async"
+	"github.com/perses/perses/internal/api/config"
+	"github.com/perses/perses/internal/api/interface/v1/globalrole"
+	"github.com/perses/perses/internal/api/interface/v1/globalrolebinding"
+	"github.com/perses/perses/internal/api/interface/v1/role"
+	"github.com/perses/perses/internal/api/interface/v1/rolebinding"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+type RBAC interface {
+	// HasPermission returns true if one of the subjects, like a user and their groups, can do the action on the resources of the kind in the project.
+	// The project is empty for the global resources, and for the creation of a project.
+	HasPermission(subjects []v1.Subject, action v1.Action, project string, scope v1.Scope) bool
+	// Refresh loads again every role and every binding from the database.
+	Refresh() error
+}
+
+func New(roleDAO role.DAO, globalRoleDAO globalrole.DAO, roleBindingDAO rolebinding.DAO, globalRoleBindingDAO globalrolebinding.DAO, conf config.Authorization) RBAC {
+	admins := make(map[string]bool, len(conf.Admins))
+	for _, login := range conf.Admins {
+		admins[login] = true
+	}
+	return &rbac{
+		roleDAO:              roleDAO,
+		globalRoleDAO:        globalRoleDAO,
+		roleBindingDAO:       roleBindingDAO,
+		globalRoleBindingDAO: globalRoleBindingDAO,
+		admins:               admins,
+		guestPermissions:     conf.GuestPermissions,
+		permissions:          newPermissions(),
+	}
+}
+
+// permissions are the permissions of each subject, a user, a group or a service account.
+type permissions struct {
+	// global are given by the global roles. They apply in every project and on the global resources.
+	global map[v1.Subject][]v1.Permission
+	// project are given by the roles, by project.
+	project map[v1.Subject]map[string][]v1.Permission
+}
+
+func newPermissions() *permissions {
+	return &permissions{
+		global:  make(map[v1.Subject][]v1.Permission),
+		project: make(map[v1.Subject]map[string][]v1.Permission),
+	}
+}
+
+func (p *permissions) addProject(subject v1.Subject, project string, permissions []v1.Permission) {
+	if p.project[subject] == nil {
+		p.project[subject] = make(map[string][]v1.Permission)
+	}
+	p.project[subject][project] = append(p.project[subject][project], permissions...)
+}
+
+func (p *permissions) allows(subject v1.Subject, action v1.Action, project string, scope v1.Scope) bool {
+	if allows(p.global[subject], action, scope) {
+		return true
+	}
+	return len(project) > 0 && allows(p.project[subject][project], action, scope)
+}
+
+type rbac struct {
+	RBAC
+	roleDAO              role.DAO
+	globalRoleDAO        globalrole.DAO
+	roleBindingDAO       rolebinding.DAO
+	globalRoleBindingDAO globalrolebinding.DAO
+	admins               map[string]bool
+	guestPermissions     []v1.Permission
+	mutex                sync.RWMutex
+	permissions          *permissions
+}
+
+func (r *rbac) HasPermission(subjects []v1.Subject, action v1.Action, project string, scope v1.Scope) bool {
+	if len(subjects) == 0 {
+		return false
+	}
+	if allows(r.guestPermissions, action, scope) {
+		return true
+	}
+	for _, subject := range subjects {
+		// the administrators are users, a service account needs a role binding.
+		if subject.Kind == v1.SubjectKindUser && r.admins[subject.Name] {
+			return true
+		}
+	}
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	for _, subject := range subjects {
+		if r.permissions.allows(subject, action, project, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func allows(permissions []v1.Permission, action v1.Action, scope v1.Scope) bool {
+	for i := range permissions {
+		if permissions[i].Allows(action, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// Refresh computes the new permissions and then replaces the current ones.
+// A binding referencing a role that doesn't exist gives no permission.
+func (r *rbac) Refresh() error {
+	p := newPermissions()
+	globalRoles, err := r.globalRoleDAO.List(&globalrole.Query{})
+	if err != nil {
+		return err
+	}
+	globalRoleSpecs := make(map[string]v1.RoleSpec, len(globalRoles))
+	for _, entity := range globalRoles {
+		globalRoleSpecs[entity.Metadata.Name] = entity.Spec
+	}
+	globalRoleBindings, err := r.globalRoleBindingDAO.List(&globalrolebinding.Query{})
+	if err != nil {
+		return err
+	}
+	for _, binding := range globalRoleBindings {
+		spec, exists := globalRoleSpecs[binding.Spec.Role]
+		if !exists {
+			continue
+		}
+		for _, subject := range binding.Spec.Subjects {
+			p.global[subject] = append(p.global[subject], spec.Permissions...)
+		}
+	}
+	roles, err := r.roleDAO.List(&role.Query{})
+	if err != nil {
+		return err
+	}
+	roleSpecs := make(map[string]map[string]v1.RoleSpec)
+	for _, entity := range roles {
+		if roleSpecs[entity.Metadata.Project] == nil {
+			roleSpecs[entity.Metadata.Project] = make(map[string]v1.RoleSpec)
+		}
+		roleSpecs[entity.Metadata.Project][entity.Metadata.Name] = entity.Spec
+	}
+	roleBindings, err := r.roleBindingDAO.List(&rolebinding.Query{})
+	if err != nil {
+		return err
+	}
+	for _, binding := range roleBindings {
+		spec, exists := roleSpecs[binding.Metadata.Project][binding.Spec.Role]
+		if !exists {
+			continue
+		}
+		for _, subject := range binding.Spec.Subjects {
+			p.addProject(subject, binding.Metadata.Project, spec.Permissions)
+		}
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.permissions = p
+	return nil
+}
+
+// NewRefresher returns the task reloading periodically the permissions.
+func NewRefresher(rbac RBAC) async.SimpleTask {
+	return &refresher{rbac: rbac}
+}
+
+type refresher struct {
+	async.SimpleTask
+	rbac RBAC
+}
+
+func (r *refresher) String() string {
+	return "permissions refresher"
+}
+
+func (r *refresher) Execute(ctx context.Context, _ context.CancelFunc) error {
+	select {
+	case <-ctx.Done():
+		logrus.Infof("canceled %s", r.String())
+	default:
+		if err := r.rbac.Refresh(); err != nil {
+			logrus.WithError(err).Error("unable to reload the permissions")
+		}
+	}
+	return nil
+}
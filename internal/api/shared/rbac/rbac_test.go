@@ -0,0 +1,246 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"testing"
+
+	"github.com/perses/perses/internal/api/config"
+	"github.com/perses/perses/internal/api/interface/v1/globalrole"
+	"github.com/perses/perses/internal/api/interface/v1/globalrolebinding"
+	"github.com/perses/perses/internal/api/interface/v1/role"
+	"github.com/perses/perses/internal/api/interface/v1/rolebinding"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRoleDAO struct {
+	role.DAO
+	list []*v1.Role
+}
+
+func (f *fakeRoleDAO) List(_ databaseModel.Query) ([]*v1.Role, error) {
+	return f.list, nil
+}
+
+type fakeGlobalRoleDAO struct {
+	globalrole.DAO
+	list []*v1.GlobalRole
+}
+
+func (f *fakeGlobalRoleDAO) List(_ databaseModel.Query) ([]*v1.GlobalRole, error) {
+	return f.list, nil
+}
+
+type fakeRoleBindingDAO struct {
+	rolebinding.DAO
+	list []*v1.RoleBinding
+}
+
+func (f *fakeRoleBindingDAO) List(_ databaseModel.Query) ([]*v1.RoleBinding, error) {
+	return f.list, nil
+}
+
+type fakeGlobalRoleBindingDAO struct {
+	globalrolebinding.DAO
+	list []*v1.GlobalRoleBinding
+}
+
+func (f *fakeGlobalRoleBindingDAO) List(_ databaseModel.Query) ([]*v1.GlobalRoleBinding, error) {
+	return f.list, nil
+}
+
+func newProjectMetadata(project string, name string) v1.ProjectMetadata {
+	return v1.ProjectMetadata{Metadata: v1.Metadata{Name: name}, Project: project}
+}
+
+func newBindingSpec(roleName string, logins ...string) v1.RoleBindingSpec {
+	spec := v1.RoleBindingSpec{Role: roleName}
+	for _, login := range logins {
+		spec.Subjects = append(spec.Subjects, v1.Subject{Kind: v1.SubjectKindUser, Name: login})
+	}
+	return spec
+}
+
+func TestHasPermission(t *testing.T) {
+	readDashboards := []v1.Permission{{Actions: []v1.Action{v1.ActionRead}, Scopes: []v1.Scope{v1.Scope(v1.KindDashboard)}}}
+	editor := []v1.Permission{{Actions: []v1.Action{v1.ActionWildcard}, Scopes: []v1.Scope{v1.Scope(v1.KindDashboard), v1.Scope(v1.KindVariable)}}}
+	r := New(
+		&fakeRoleDAO{list: []*v1.Role{
+			{Kind: v1.KindRole, Metadata: newProjectMetadata("perses", "editor"), Spec: v1.RoleSpec{Permissions: editor}},
+		}},
+		&fakeGlobalRoleDAO{list: []*v1.GlobalRole{
+			{Kind: v1.KindGlobalRole, Metadata: v1.Metadata{Name: "viewer"}, Spec: v1.RoleSpec{Permissions: readDashboards}},
+		}},
+		&fakeRoleBindingDAO{list: []*v1.RoleBinding{
+			{Kind: v1.KindRoleBinding, Metadata: newProjectMetadata("perses", "editors"), Spec: newBindingSpec("editor", "jane")},
+			// the role doesn't exist in this project
+			{Kind: v1.KindRoleBinding, Metadata: newProjectMetadata("other", "editors"), Spec: newBindingSpec("editor", "jane")},
+			{Kind: v1.KindRoleBinding, Metadata: newProjectMetadata("perses", "sre"), Spec: v1.RoleBindingSpec{
+				Role:     "editor",
+				Subjects: []v1.Subject{{Kind: v1.SubjectKindGroup, Name: "sre"}},
+			}},
+		}},
+		&fakeGlobalRoleBindingDAO{list: []*v1.GlobalRoleBinding{
+			{Kind: v1.KindGlobalRoleBinding, Metadata: v1.Metadata{Name: "viewers"}, Spec: newBindingSpec("viewer", "john")},
+			{Kind: v1.KindGlobalRoleBinding, Metadata: v1.Metadata{Name: "ci"}, Spec: v1.RoleBindingSpec{
+				Role:     "viewer",
+				Subjects: []v1.Subject{{Kind: v1.SubjectKindServiceAccount, Name: "ci"}},
+			}},
+		}},
+		config.Authorization{
+			Admins:           []string{"admin"},
+			GuestPermissions: []v1.Permission{{Actions: []v1.Action{v1.ActionRead}, Scopes: []v1.Scope{v1.Scope(v1.KindGlobalVariable)}}},
+		},
+	)
+	if err := r.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	testSuite := []struct {
+		title          string
+		login          string
+		groups         []string
+		serviceAccount string
+		action         v1.Action
+		project        string
+		scope          v1.Scope
+		expected       bool
+	}{
+		{
+			title:    "admin",
+			login:    "admin",
+			action:   v1.ActionWildcard,
+			scope:    v1.ScopeWildcard,
+			expected: true,
+		},
+		{
+			title:    "guest permission",
+			login:    "nobody",
+			action:   v1.ActionRead,
+			scope:    v1.Scope(v1.KindGlobalVariable),
+			expected: true,
+		},
+		{
+			title:    "action missing in the guest permissions",
+			login:    "nobody",
+			action:   v1.ActionUpdate,
+			scope:    v1.Scope(v1.KindGlobalVariable),
+			expected: false,
+		},
+		{
+			title:    "global role applied in a project",
+			login:    "john",
+			action:   v1.ActionRead,
+			project:  "perses",
+			scope:    v1.Scope(v1.KindDashboard),
+			expected: true,
+		},
+		{
+			title:    "action missing in the global role",
+			login:    "john",
+			action:   v1.ActionDelete,
+			project:  "perses",
+			scope:    v1.Scope(v1.KindDashboard),
+			expected: false,
+		},
+		{
+			title:    "wildcard action of a role",
+			login:    "jane",
+			action:   v1.ActionDelete,
+			project:  "perses",
+			scope:    v1.Scope(v1.KindVariable),
+			expected: true,
+		},
+		{
+			title:    "role in another project",
+			login:    "jane",
+			action:   v1.ActionRead,
+			project:  "other",
+			scope:    v1.Scope(v1.KindDashboard),
+			expected: false,
+		},
+		{
+			title:    "role applied on a global resource",
+			login:    "jane",
+			action:   v1.ActionRead,
+			scope:    v1.Scope(v1.KindDashboard),
+			expected: false,
+		},
+		{
+			title:    "wildcard requested",
+			login:    "jane",
+			action:   v1.ActionWildcard,
+			project:  "perses",
+			scope:    v1.ScopeWildcard,
+			expected: false,
+		},
+		{
+			title:    "role bound to a group of the user",
+			login:    "bob",
+			groups:   []string{"dev", "sre"},
+			action:   v1.ActionUpdate,
+			project:  "perses",
+			scope:    v1.Scope(v1.KindDashboard),
+			expected: true,
+		},
+		{
+			title:    "user named like a group",
+			login:    "sre",
+			action:   v1.ActionUpdate,
+			project:  "perses",
+			scope:    v1.Scope(v1.KindDashboard),
+			expected: false,
+		},
+		{
+			title:    "group named like a user",
+			login:    "bob",
+			groups:   []string{"jane"},
+			action:   v1.ActionUpdate,
+			project:  "perses",
+			scope:    v1.Scope(v1.KindDashboard),
+			expected: false,
+		},
+		{
+			title:          "role bound to a service account",
+			serviceAccount: "ci",
+			action:         v1.ActionRead,
+			project:        "perses",
+			scope:          v1.Scope(v1.KindDashboard),
+			expected:       true,
+		},
+		{
+			title:          "service account named like an administrator",
+			serviceAccount: "admin",
+			action:         v1.ActionRead,
+			project:        "perses",
+			scope:          v1.Scope(v1.KindDashboard),
+			expected:       false,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			var subjects []v1.Subject
+			if len(test.serviceAccount) > 0 {
+				subjects = append(subjects, v1.Subject{Kind: v1.SubjectKindServiceAccount, Name: test.serviceAccount})
+			} else {
+				subjects = append(subjects, v1.Subject{Kind: v1.SubjectKindUser, Name: test.login})
+			}
+			for _, group := range test.groups {
+				subjects = append(subjects, v1.Subject{Kind: v1.SubjectKindGroup, Name: group})
+			}
+			assert.Equal(t, test.expected, r.HasPermission(subjects, test.action, test.project, test.scope))
+		})
+	}
+}
@@ -120,23 +120,17 @@ func (c *cueDefs) Load() error {
 	return nil
 }
 
-func NewHotReloaders(loaders []Loader) (async.SimpleTask, async.SimpleTask, error) {
-	fsWatcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return nil, nil, err
-	}
-
+func NewHotReloaders(loaders []Loader) (async.SimpleTask, async.SimpleTask) {
 	return &Watcher{
-			FSWatcher: fsWatcher,
-			Loaders:   loaders,
-		}, &Reloader{
-			Loaders: loaders,
-		},
-		nil
+		Loaders: loaders,
+	}, &Reloader{
+		Loaders: loaders,
+	}
 }
 
 type Watcher struct {
 	async.Task
+	// FSWatcher is created when the task is initialized, so nothing is watched as long as the task is not started.
 	FSWatcher      *fsnotify.Watcher
 	Loaders        []Loader
 	LoaderCallback func()
@@ -147,6 +141,13 @@ func (w *Watcher) String() string {
 }
 
 func (w *Watcher) Initialize() error {
+	if w.FSWatcher == nil {
+		fsWatcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return err
+		}
+		w.FSWatcher = fsWatcher
+	}
 	for _, l := range w.Loaders {
 		if err := w.FSWatcher.Add(l.GetSchemaPath()); err != nil {
 			return err
@@ -192,6 +193,9 @@ func (w *Watcher) Execute(ctx context.Context, cancel context.CancelFunc) error
 }
 
 func (w *Watcher) Finalize() error {
+	if w.FSWatcher == nil {
+		return nil
+	}
 	return w.FSWatcher.Close()
 }
 
@@ -0,0 +1,57 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// NewDeferred returns an index recording the changes, and the function making them in the index given.
+// It is used when the resources are written in a transaction, so the index only gets the changes once the transaction is committed.
+// The searches are made with the index given.
+func NewDeferred(index Index) (Index, func()) {
+	d := &deferredIndex{Index: index}
+	return d, d.flush
+}
+
+type deferredIndex struct {
+	Index
+	changes []func()
+}
+
+func (d *deferredIndex) Add(entity modelAPI.Entity) {
+	d.changes = append(d.changes, func() {
+		d.Index.Add(entity)
+	})
+}
+
+func (d *deferredIndex) Remove(kind v1.Kind, project string, name string) {
+	d.changes = append(d.changes, func() {
+		d.Index.Remove(kind, project, name)
+	})
+}
+
+func (d *deferredIndex) RemoveProject(project string) {
+	d.changes = append(d.changes, func() {
+		d.Index.RemoveProject(project)
+	})
+}
+
+func (d *deferredIndex) flush() {
+	for _, change := range d.changes {
+		change()
+	}
+	d.changes = nil
+}
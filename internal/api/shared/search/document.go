@@ -0,0 +1,224 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+	"github.com/perses/perses/pkg/model/api/v1/variable"
+)
+
+// The weight of a field is the score given to a term found in it. The more a field describes the resource, the higher is the weight.
+const (
+	weightDisplayName  = 10
+	weightName         = 8
+	weightPanelTitle   = 6
+	weightVariableName = 4
+	weightDescription  = 2
+	weightQuery        = 1
+)
+
+// field is a text of a document that can be searched.
+type field struct {
+	path   string
+	value  string
+	weight float64
+}
+
+// document is what is indexed for a resource.
+type document struct {
+	kind        v1.Kind
+	project     string
+	name        string
+	displayName string
+	fields      []field
+}
+
+func documentKey(kind v1.Kind, project string, name string) string {
+	return path.Join(string(kind), project, name)
+}
+
+func (d *document) key() string {
+	return documentKey(d.kind, d.project, d.name)
+}
+
+func (d *document) add(fieldPath string, value string, weight float64) {
+	if len(strings.TrimSpace(value)) == 0 {
+		return
+	}
+	d.fields = append(d.fields, field{path: fieldPath, value: value, weight: weight})
+}
+
+// newDocument returns the document to index for the entity. It returns nil if the kind of the entity is not searchable.
+func newDocument(entity modelAPI.Entity) *document {
+	switch e := entity.(type) {
+	case *v1.Dashboard:
+		return newDashboardDocument(e)
+	case *v1.Variable:
+		doc := &document{kind: v1.KindVariable, project: e.Metadata.Project, name: e.Metadata.Name}
+		doc.addVariableSpec(e.Spec)
+		return doc
+	case *v1.GlobalVariable:
+		doc := &document{kind: v1.KindGlobalVariable, name: e.Metadata.Name}
+		doc.addVariableSpec(e.Spec)
+		return doc
+	default:
+		return nil
+	}
+}
+
+func newDashboardDocument(entity *v1.Dashboard) *document {
+	doc := &document{kind: v1.KindDashboard, project: entity.Metadata.Project, name: entity.Metadata.Name}
+	doc.add("metadata.name", entity.Metadata.Name, weightName)
+	if entity.Spec.Display != nil {
+		doc.displayName = entity.Spec.Display.Name
+		doc.add("spec.display.name", entity.Spec.Display.Name, weightDisplayName)
+		doc.add("spec.display.description", entity.Spec.Display.Description, weightDescription)
+	}
+	panelKeys := make([]string, 0, len(entity.Spec.Panels))
+	for key := range entity.Spec.Panels {
+		panelKeys = append(panelKeys, key)
+	}
+	sort.Strings(panelKeys)
+	for _, key := range panelKeys {
+		panel := entity.Spec.Panels[key]
+		if panel == nil {
+			continue
+		}
+		prefix := fmt.Sprintf("spec.panels.%s.spec", key)
+		doc.add(prefix+".display.name", panel.Spec.Display.Name, weightPanelTitle)
+		doc.add(prefix+".display.description", panel.Spec.Display.Description, weightDescription)
+		for i, query := range panel.Spec.Queries {
+			doc.add(fmt.Sprintf("%s.queries[%d].spec.plugin.spec", prefix, i), pluginText(query.Spec.Plugin.Spec), weightQuery)
+		}
+	}
+	for i, v := range entity.Spec.Variables {
+		if v.Spec == nil {
+			continue
+		}
+		prefix := fmt.Sprintf("spec.variables[%d].spec", i)
+		doc.add(prefix+".name", v.Spec.GetName(), weightVariableName)
+		var display *variable.Display
+		switch spec := v.Spec.(type) {
+		case *dashboard.ListVariableSpec:
+			display = spec.Display
+		case *dashboard.TextVariableSpec:
+			display = spec.Display
+		}
+		if display != nil {
+			doc.add(prefix+".display.name", display.Name, weightVariableName)
+		}
+	}
+	return doc
+}
+
+func (d *document) addVariableSpec(spec v1.VariableSpec) {
+	d.add("metadata.name", d.name, weightName)
+	var display *variable.Display
+	switch s := spec.Spec.(type) {
+	case *variable.ListSpec:
+		display = s.Display
+		d.add("spec.spec.plugin.spec", pluginText(s.Plugin.Spec), weightQuery)
+	case *variable.TextSpec:
+		display = s.Display
+	}
+	if display != nil {
+		d.displayName = display.Name
+		d.add("spec.spec.display.name", display.Name, weightDisplayName)
+		d.add("spec.spec.display.description", display.Description, weightDescription)
+	}
+}
+
+// pluginText returns every string contained in the spec of a plugin, like the query expressions, separated by a space.
+// The keys are sorted, so the text doesn't change from a call to another.
+func pluginText(spec interface{}) string {
+	var values []string
+	collectStrings(spec, &values)
+	return strings.Join(values, " ")
+}
+
+func collectStrings(value interface{}, result *[]string) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case string:
+		if len(v) > 0 {
+			*result = append(*result, v)
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectStrings(item, result)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			collectStrings(v[key], result)
+		}
+	case map[interface{}]interface{}:
+		// it is what is decoded from YAML
+		keys := make([]string, 0, len(v))
+		values := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			k := fmt.Sprint(key)
+			keys = append(keys, k)
+			values[k] = item
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			collectStrings(values[key], result)
+		}
+	case bool, int, int64, float64:
+		return
+	default:
+		// a struct, decode it as a generic JSON object.
+		data, err := json.Marshal(v)
+		if err != nil {
+			return
+		}
+		var generic interface{}
+		if unmarshalErr := json.Unmarshal(data, &generic); unmarshalErr != nil {
+			return
+		}
+		collectStrings(generic, result)
+	}
+}
+
+// tokenize splits the text in lowercase terms. A term is a sequence of letters, digits and underscores,
+// so the metric names and the label names of a query are terms.
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	seen := make(map[string]bool, len(words))
+	result := make([]string, 0, len(words))
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			result = append(result, word)
+		}
+	}
+	return result
+}
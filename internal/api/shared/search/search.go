@@ -0,0 +1,339 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package search provides a full-text index of the dashboards and of the variables.
+// The index is kept in memory. It is built from the database when the API starts, updated each time a resource is written,
+// and rebuilt periodically so the changes made by other instances of the API are eventually visible.
+package search
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/perses/common/async"
+	"github.com/perses/perses/internal/api/interface/v1/dashboard"
+	"github.com/perses/perses/internal/api/interface/v1/globalvariable"
+	"github.com/perses/perses/internal/api/interface/v1/variable"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// prefixFactor reduces the score of a term that only matches the beginning of an indexed term.
+const prefixFactor = 0.5
+
+// Filter restricts the results of a search.
+type Filter struct {
+	// Project keeps only the resources of the project. The global resources are excluded when it is set.
+	Project string
+	// Kind keeps only the resources of this kind.
+	Kind v1.Kind
+	// Limit is the maximum number of results. 0 means there is no limit.
+	Limit int
+	// CanRead keeps only the resources the user can read. It can be nil.
+	CanRead func(kind v1.Kind, project string) bool
+}
+
+func (f Filter) accept(doc *document) bool {
+	if len(f.Project) > 0 && doc.project != f.Project {
+		return false
+	}
+	if f.CanRead != nil && !f.CanRead(doc.kind, doc.project) {
+		return false
+	}
+	return len(f.Kind) == 0 || doc.kind == f.Kind
+}
+
+type Index interface {
+	// Search returns the resources containing every term of the text, sorted by relevance.
+	// A term matches the beginning of a word, so the results can be returned while the user is typing.
+	Search(text string, filter Filter) []*v1.SearchResult
+	// Add indexes the entity, or re-indexes it if it already exists. The entities that are not searchable are ignored.
+	Add(entity modelAPI.Entity)
+	// Remove removes the resource from the index.
+	Remove(kind v1.Kind, project string, name string)
+	// RemoveProject removes every resource of the project from the index.
+	RemoveProject(project string)
+	// Rebuild indexes again every resource stored in the database.
+	Rebuild() error
+}
+
+func New(dashboardDAO dashboard.DAO, variableDAO variable.DAO, globalVariableDAO globalvariable.DAO) Index {
+	return &index{
+		store:             newStore(),
+		dashboardDAO:      dashboardDAO,
+		variableDAO:       variableDAO,
+		globalVariableDAO: globalVariableDAO,
+	}
+}
+
+type index struct {
+	Index
+	mutex             sync.Mutex
+	store             *store
+	dashboardDAO      dashboard.DAO
+	variableDAO       variable.DAO
+	globalVariableDAO globalvariable.DAO
+}
+
+func (i *index) Search(text string, filter Filter) []*v1.SearchResult {
+	// the lock is exclusive, as the terms may have to be sorted first.
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.store.sortTerms()
+	return i.store.search(text, filter)
+}
+
+func (i *index) Add(entity modelAPI.Entity) {
+	doc := newDocument(entity)
+	if doc == nil {
+		return
+	}
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.store.remove(doc.key())
+	i.store.insert(doc)
+}
+
+func (i *index) Remove(kind v1.Kind, project string, name string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.store.remove(documentKey(kind, project, name))
+}
+
+func (i *index) RemoveProject(project string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	for key, doc := range i.store.documents {
+		if doc.project == project {
+			i.store.remove(key)
+		}
+	}
+}
+
+// Rebuild builds a new index from the database and then replaces the current one.
+// A resource written while the new index is built may be missing until the next rebuild.
+func (i *index) Rebuild() error {
+	s := newStore()
+	dashboards, err := i.dashboardDAO.List(&dashboard.Query{})
+	if err != nil {
+		return err
+	}
+	for _, entity := range dashboards {
+		s.insert(newDocument(entity))
+	}
+	variables, err := i.variableDAO.List(&variable.Query{})
+	if err != nil {
+		return err
+	}
+	for _, entity := range variables {
+		s.insert(newDocument(entity))
+	}
+	globalVariables, err := i.globalVariableDAO.List(&globalvariable.Query{})
+	if err != nil {
+		return err
+	}
+	for _, entity := range globalVariables {
+		s.insert(newDocument(entity))
+	}
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.store = s
+	return nil
+}
+
+// store is an inverted index: it gives for each term the documents, and the fields of these documents, containing it.
+type store struct {
+	documents map[string]*document
+	// postings contains, by term, the index of the fields containing the term, by document.
+	postings map[string]map[string][]int
+	// terms are the keys of postings, sorted so the terms starting with a prefix can be found quickly.
+	// They are sorted again before a search when they have been modified.
+	terms    []string
+	isSorted bool
+}
+
+func newStore() *store {
+	return &store{
+		documents: make(map[string]*document),
+		postings:  make(map[string]map[string][]int),
+		isSorted:  true,
+	}
+}
+
+func (s *store) insert(doc *document) {
+	key := doc.key()
+	s.documents[key] = doc
+	for fieldIndex, f := range doc.fields {
+		for _, term := range tokenize(f.value) {
+			docs, exists := s.postings[term]
+			if !exists {
+				docs = make(map[string][]int)
+				s.postings[term] = docs
+				s.terms = append(s.terms, term)
+				s.isSorted = false
+			}
+			docs[key] = append(docs[key], fieldIndex)
+		}
+	}
+}
+
+func (s *store) remove(key string) {
+	doc, exists := s.documents[key]
+	if !exists {
+		return
+	}
+	delete(s.documents, key)
+	isTermRemoved := false
+	for _, f := range doc.fields {
+		for _, term := range tokenize(f.value) {
+			docs := s.postings[term]
+			delete(docs, key)
+			if len(docs) == 0 && s.postings[term] != nil {
+				delete(s.postings, term)
+				isTermRemoved = true
+			}
+		}
+	}
+	if isTermRemoved {
+		terms := make([]string, 0, len(s.postings))
+		for _, term := range s.terms {
+			if _, ok := s.postings[term]; ok {
+				terms = append(terms, term)
+			}
+		}
+		s.terms = terms
+	}
+}
+
+func (s *store) sortTerms() {
+	if s.isSorted {
+		return
+	}
+	sort.Strings(s.terms)
+	s.isSorted = true
+}
+
+// termsWithPrefix returns the indexed terms starting with the prefix. The terms must be sorted.
+func (s *store) termsWithPrefix(prefix string) []string {
+	var result []string
+	for i := sort.SearchStrings(s.terms, prefix); i < len(s.terms) && strings.HasPrefix(s.terms[i], prefix); i++ {
+		result = append(result, s.terms[i])
+	}
+	return result
+}
+
+func (s *store) search(text string, filter Filter) []*v1.SearchResult {
+	terms := tokenize(text)
+	if len(terms) == 0 {
+		return []*v1.SearchResult{}
+	}
+	scores := make(map[string]float64)
+	matchedTerms := make(map[string]int)
+	matchedFields := make(map[string]map[int]bool)
+	for _, term := range terms {
+		// the score of a term in a document is the one of the best field containing it.
+		best := make(map[string]float64)
+		for _, indexedTerm := range s.termsWithPrefix(term) {
+			factor := 1.0
+			if indexedTerm != term {
+				factor = prefixFactor
+			}
+			for key, fieldIndexes := range s.postings[indexedTerm] {
+				doc := s.documents[key]
+				if !filter.accept(doc) {
+					continue
+				}
+				if matchedFields[key] == nil {
+					matchedFields[key] = make(map[int]bool)
+				}
+				for _, fieldIndex := range fieldIndexes {
+					matchedFields[key][fieldIndex] = true
+					if score := doc.fields[fieldIndex].weight * factor; score > best[key] {
+						best[key] = score
+					}
+				}
+			}
+		}
+		for key, score := range best {
+			scores[key] += score
+			matchedTerms[key]++
+		}
+	}
+	results := make([]*v1.SearchResult, 0)
+	for key, count := range matchedTerms {
+		if count != len(terms) {
+			continue
+		}
+		doc := s.documents[key]
+		result := &v1.SearchResult{
+			Kind:        doc.kind,
+			Project:     doc.project,
+			Name:        doc.name,
+			DisplayName: doc.displayName,
+			Score:       scores[key],
+			Matches:     make([]v1.SearchMatch, 0, len(matchedFields[key])),
+		}
+		for fieldIndex, f := range doc.fields {
+			if matchedFields[key][fieldIndex] {
+				result.Matches = append(result.Matches, v1.SearchMatch{Field: f.path, Value: f.value})
+			}
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		return a.Name < b.Name
+	})
+	if filter.Limit > 0 && len(results) > filter.Limit {
+		results = results[:filter.Limit]
+	}
+	return results
+}
+
+// NewRefresher returns the task rebuilding periodically the index.
+func NewRefresher(index Index) async.SimpleTask {
+	return &refresher{index: index}
+}
+
+type refresher struct {
+	async.SimpleTask
+	index Index
+}
+
+func (r *refresher) String() string {
+	return "search index refresher"
+}
+
+func (r *refresher) Execute(ctx context.Context, _ context.CancelFunc) error {
+	select {
+	case <-ctx.Done():
+		logrus.Infof("canceled %s", r.String())
+	default:
+		if err := r.index.Rebuild(); err != nil {
+			logrus.WithError(err).Error("unable to rebuild the search index")
+		}
+	}
+	return nil
+}
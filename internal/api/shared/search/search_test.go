@@ -0,0 +1,215 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"testing"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+	"github.com/perses/perses/pkg/model/api/v1/variable"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDashboard(project string, name string, displayName string, panelTitle string, expr string) *v1.Dashboard {
+	return &v1.Dashboard{
+		Kind: v1.KindDashboard,
+		Metadata: v1.ProjectMetadata{
+			Metadata: v1.Metadata{Name: name},
+			Project:  project,
+		},
+		Spec: v1.DashboardSpec{
+			Display: &common.Display{Name: displayName},
+			Panels: map[string]*v1.Panel{
+				"panel": {
+					Kind: "Panel",
+					Spec: v1.PanelSpec{
+						Display: v1.PanelDisplay{Name: panelTitle},
+						Queries: []v1.Query{
+							{
+								Kind: "TimeSeriesQuery",
+								Spec: v1.QuerySpec{
+									Plugin: common.Plugin{
+										Kind: "PrometheusTimeSeriesQuery",
+										Spec: map[string]interface{}{"query": expr},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Variables: []dashboard.Variable{
+				{
+					Kind: variable.KindText,
+					Spec: &dashboard.TextVariableSpec{Name: "instance"},
+				},
+			},
+		},
+	}
+}
+
+func newIndex() *index {
+	i := &index{store: newStore()}
+	i.Add(newDashboard("perses", "node", "Node Exporter", "CPU usage", `rate(node_cpu_seconds_total{mode!="idle"}[5m])`))
+	i.Add(newDashboard("perses", "cpu", "Processors", "Load", "node_load1"))
+	i.Add(newDashboard("demo", "memory", "Memory", "Memory usage", "node_memory_MemAvailable_bytes"))
+	i.Add(&v1.GlobalVariable{
+		Kind:     v1.KindGlobalVariable,
+		Metadata: v1.Metadata{Name: "cluster"},
+		Spec: v1.VariableSpec{
+			Kind: variable.KindList,
+			Spec: &variable.ListSpec{
+				Display: &variable.Display{Name: "Kubernetes cluster"},
+				Plugin: common.Plugin{
+					Kind: "PrometheusLabelValuesVariable",
+					Spec: map[string]interface{}{"labelName": "cluster", "matchers": []interface{}{"kube_node_info"}},
+				},
+			},
+		},
+	})
+	return i
+}
+
+func resultNames(results []*v1.SearchResult) []string {
+	names := make([]string, 0, len(results))
+	for _, result := range results {
+		names = append(names, result.Name)
+	}
+	return names
+}
+
+func TestIndexSearch(t *testing.T) {
+	testSuite := []struct {
+		title  string
+		text   string
+		filter Filter
+		result []string
+	}{
+		{
+			title:  "display name ranked before panel title",
+			text:   "cpu",
+			result: []string{"cpu", "node"},
+		},
+		{
+			title:  "every term must match",
+			text:   "node usage",
+			result: []string{"node", "memory"},
+		},
+		{
+			title:  "term in a query expression",
+			text:   "node_load1",
+			result: []string{"cpu"},
+		},
+		{
+			title:  "prefix of a term",
+			text:   "proc",
+			result: []string{"cpu"},
+		},
+		{
+			title:  "exact term ranked before a prefix",
+			text:   "memory",
+			result: []string{"memory"},
+		},
+		{
+			title:  "same score sorted by project and name",
+			text:   "instance",
+			result: []string{"memory", "cpu", "node"},
+		},
+		{
+			title:  "global variable",
+			text:   "kubernetes",
+			result: []string{"cluster"},
+		},
+		{
+			title:  "filter by project",
+			text:   "usage",
+			filter: Filter{Project: "demo"},
+			result: []string{"memory"},
+		},
+		{
+			title:  "filter by kind",
+			text:   "cluster",
+			filter: Filter{Kind: v1.KindDashboard},
+			result: []string{},
+		},
+		{
+			title:  "limit",
+			text:   "instance",
+			filter: Filter{Limit: 2},
+			result: []string{"memory", "cpu"},
+		},
+		{
+			title:  "no term",
+			text:   " - ",
+			result: []string{},
+		},
+	}
+	i := newIndex()
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			assert.Equal(t, test.result, resultNames(i.Search(test.text, test.filter)))
+		})
+	}
+}
+
+func TestIndexSearchMatches(t *testing.T) {
+	results := newIndex().Search("cpu idle", Filter{})
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "perses", results[0].Project)
+	assert.Equal(t, "Node Exporter", results[0].DisplayName)
+	assert.Equal(t, []v1.SearchMatch{
+		{Field: "spec.panels.panel.spec.display.name", Value: "CPU usage"},
+		{Field: "spec.panels.panel.spec.queries[0].spec.plugin.spec", Value: `rate(node_cpu_seconds_total{mode!="idle"}[5m])`},
+	}, results[0].Matches)
+}
+
+func TestIndexUpdate(t *testing.T) {
+	i := newIndex()
+	// re-indexing a dashboard replaces its previous content
+	i.Add(newDashboard("perses", "node", "Node Exporter", "Network", "node_network_receive_bytes_total"))
+	assert.Equal(t, []string{"node"}, resultNames(i.Search("network", Filter{})))
+	assert.Equal(t, []string{}, resultNames(i.Search("idle", Filter{})))
+
+	i.Remove(v1.KindDashboard, "perses", "node")
+	assert.Equal(t, []string{}, resultNames(i.Search("network", Filter{})))
+
+	i.RemoveProject("perses")
+	assert.Equal(t, []string{"memory"}, resultNames(i.Search("instance", Filter{})))
+}
+
+func TestDeferredIndex(t *testing.T) {
+	i := newIndex()
+	deferred, flush := NewDeferred(i)
+	deferred.Add(newDashboard("perses", "network", "Network", "Received", "node_network_receive_bytes_total"))
+	deferred.Remove(v1.KindDashboard, "perses", "node")
+	// nothing changes until the changes are flushed
+	assert.Equal(t, []string{}, resultNames(deferred.Search("received", Filter{})))
+	assert.Equal(t, []string{"node"}, resultNames(i.Search("idle", Filter{})))
+
+	flush()
+	assert.Equal(t, []string{"network"}, resultNames(i.Search("received", Filter{})))
+	assert.Equal(t, []string{}, resultNames(i.Search("idle", Filter{})))
+}
+
+func TestPluginText(t *testing.T) {
+	spec := map[interface{}]interface{}{
+		"query":    "up",
+		"minStep":  15,
+		"series":   []interface{}{"a", map[string]interface{}{"b": "c"}},
+		"disabled": false,
+	}
+	assert.Equal(t, "up a c", pluginText(spec))
+}
@@ -0,0 +1,285 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package storagemigrate copies every resource stored in a database to another one, like from the filesystem to SQL.
+// The resources are copied as they are, so their metadata, including the timestamps and the version, are preserved.
+// The secrets are copied encrypted, so the server using the destination must use the same encryption key.
+package storagemigrate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/perses/perses/internal/api/shared/backup"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type Options struct {
+	// DryRun computes what would be copied without writing anything in the destination.
+	// The destination doesn't need to be initialized: when its schema doesn't exist yet, it is considered empty.
+	DryRun bool
+	// Resume allows the destination to already contain resources, like when a previous migration has been interrupted.
+	// The resources already copied are skipped, and the ones that changed in the source since then are copied again.
+	Resume bool
+}
+
+// KindReport is what has been copied for a kind.
+type KindReport struct {
+	Kind v1.Kind
+	// Source is the number of resources stored in the source.
+	Source int
+	// Created is the number of resources that didn't exist in the destination.
+	Created int
+	// Updated is the number of resources that existed in the destination with a different content.
+	Updated int
+	// Unchanged is the number of resources that existed in the destination with the same content. They are not written again.
+	Unchanged int
+}
+
+// Report contains a KindReport for each kind, in the order of backup.Kinds.
+type Report struct {
+	Kinds []*KindReport
+}
+
+func newReport() *Report {
+	r := &Report{}
+	for _, kind := range backup.Kinds {
+		r.Kinds = append(r.Kinds, &KindReport{Kind: kind})
+	}
+	return r
+}
+
+func (r *Report) get(kind v1.Kind) *KindReport {
+	for _, k := range r.Kinds {
+		if k.Kind == kind {
+			return k
+		}
+	}
+	k := &KindReport{Kind: kind}
+	r.Kinds = append(r.Kinds, k)
+	return k
+}
+
+// initializer is implemented by the databases whose schema must be created before they can be read, like the SQL databases.
+type initializer interface {
+	IsInitialized() (bool, error)
+}
+
+// Migrate copies every resource of from into to. The resources are read from the source within a single transaction,
+// and written one by one, so an interrupted migration can be resumed without copying again what has already been copied.
+// Unless opts.Resume is set, the destination must be empty.
+func Migrate(from databaseModel.DAO, to databaseModel.DAO, opts Options) (*Report, error) {
+	source, err := read(from)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the source database: %w", err)
+	}
+	destination, err := readDestination(to, opts.DryRun)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the destination database: %w", err)
+	}
+	if len(destination.documents) > 0 && !opts.Resume {
+		return nil, fmt.Errorf("the destination database already contains %d resources, the resume option must be used to continue an interrupted migration", len(destination.documents))
+	}
+	report := newReport()
+	for i, entity := range source.resources {
+		key := source.keys[i]
+		kindReport := report.get(v1.Kind(entity.GetKind()))
+		kindReport.Source++
+		existing, exists := destination.documents[key]
+		if exists && bytes.Equal(existing, source.documents[key]) {
+			kindReport.Unchanged++
+			continue
+		}
+		if exists {
+			kindReport.Updated++
+		} else {
+			kindReport.Created++
+		}
+		if opts.DryRun {
+			continue
+		}
+		if upsertErr := to.Upsert(entity); upsertErr != nil {
+			return report, fmt.Errorf("unable to write %q in the destination database: %w", key, upsertErr)
+		}
+	}
+	return report, nil
+}
+
+// readDestination reads the destination database. A dry run doesn't initialize the destination, so when its schema
+// doesn't exist yet, the destination is considered empty instead of being read.
+func readDestination(to databaseModel.DAO, dryRun bool) (*snapshot, error) {
+	if i, ok := to.(initializer); ok && dryRun {
+		initialized, err := i.IsInitialized()
+		if err != nil {
+			return nil, err
+		}
+		if !initialized {
+			return &snapshot{documents: map[string][]byte{}}, nil
+		}
+	}
+	return read(to)
+}
+
+// Verify checks the destination contains exactly the resources of the source.
+// For every kind, the number of resources and the checksum of their content must be the same in both databases.
+func Verify(from databaseModel.DAO, to databaseModel.DAO) error {
+	source, err := read(from)
+	if err != nil {
+		return fmt.Errorf("unable to read the source database: %w", err)
+	}
+	destination, err := read(to)
+	if err != nil {
+		return fmt.Errorf("unable to read the destination database: %w", err)
+	}
+	sourceSummary := source.summary()
+	destinationSummary := destination.summary()
+	var mismatches []string
+	for _, kind := range backup.Kinds {
+		s, d := sourceSummary[kind], destinationSummary[kind]
+		if s.count != d.count {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %d in the source, %d in the destination", kind, s.count, d.count))
+		} else if s.checksum != d.checksum {
+			mismatches = append(mismatches, fmt.Sprintf("%s: the checksum %s of the source doesn't match the checksum %s of the destination", kind, s.checksum, d.checksum))
+		}
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("the destination database doesn't match the source database: %s", strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+// snapshot contains every resource of a database, and their content encoded by canonicalJSON by key.
+type snapshot struct {
+	resources []modelAPI.Entity
+	keys      []string
+	documents map[string][]byte
+}
+
+func read(dao databaseModel.DAO) (*snapshot, error) {
+	var resources []modelAPI.Entity
+	if err := dao.Transaction(func(tx databaseModel.DAO) error {
+		var listErr error
+		resources, listErr = backup.ListAll(tx)
+		return listErr
+	}); err != nil {
+		return nil, err
+	}
+	s := &snapshot{
+		resources: resources,
+		keys:      make([]string, 0, len(resources)),
+		documents: make(map[string][]byte, len(resources)),
+	}
+	for _, entity := range resources {
+		key, err := resourceKey(entity)
+		if err != nil {
+			return nil, err
+		}
+		data, err := canonicalJSON(entity)
+		if err != nil {
+			return nil, err
+		}
+		s.keys = append(s.keys, key)
+		s.documents[key] = data
+	}
+	return s, nil
+}
+
+type kindSummary struct {
+	count    int
+	checksum string
+}
+
+// summary returns the number of resources and the checksum of their content by kind. The checksum doesn't depend on the order
+// in which the database returned the resources.
+func (s *snapshot) summary() map[v1.Kind]kindSummary {
+	keysByKind := make(map[v1.Kind][]string)
+	for i, entity := range s.resources {
+		kind := v1.Kind(entity.GetKind())
+		keysByKind[kind] = append(keysByKind[kind], s.keys[i])
+	}
+	result := make(map[v1.Kind]kindSummary, len(keysByKind))
+	for kind, keys := range keysByKind {
+		sort.Strings(keys)
+		hash := sha256.New()
+		for _, key := range keys {
+			hash.Write([]byte(key))
+			hash.Write([]byte{'\n'})
+			hash.Write(s.documents[key])
+			hash.Write([]byte{'\n'})
+		}
+		result[kind] = kindSummary{count: len(keys), checksum: hex.EncodeToString(hash.Sum(nil))}
+	}
+	return result
+}
+
+// canonicalJSON encodes the entity in JSON without the null and empty values. A database can decode an empty list as nil
+// and another one as an empty slice, so the content of a resource can be compared whatever the database it has been read from.
+func canonicalJSON(entity modelAPI.Entity) ([]byte, error) {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if unmarshalErr := json.Unmarshal(data, &generic); unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	return json.Marshal(removeEmpty(generic))
+}
+
+func removeEmpty(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if item = removeEmpty(item); item == nil {
+				delete(v, key)
+			} else {
+				v[key] = item
+			}
+		}
+		if len(v) == 0 {
+			return nil
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		for i, item := range v {
+			// the items are kept even if they are empty, to not shift the other ones.
+			v[i] = removeEmpty(item)
+		}
+	}
+	return value
+}
+
+// resourceKey returns the key identifying the resource in a database, whatever the database is.
+func resourceKey(entity modelAPI.Entity) (string, error) {
+	kind := entity.GetKind()
+	switch m := entity.GetMetadata().(type) {
+	case *v1.RevisionMetadata:
+		return path.Join(kind, m.Project, m.Name, strconv.FormatUint(m.Version, 10)), nil
+	case *v1.ProjectMetadata:
+		return path.Join(kind, m.Project, m.Name), nil
+	case *v1.Metadata:
+		return path.Join(kind, m.Name), nil
+	}
+	return "", fmt.Errorf("the metadata %T of the kind %s is not managed", entity.GetMetadata(), kind)
+}
@@ -0,0 +1,191 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storagemigrate
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/perses/perses/internal/api/config"
+	"github.com/perses/perses/internal/api/shared/database"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	"github.com/perses/perses/pkg/model/api/v1/variable"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDAO(t *testing.T, conf config.Database) databaseModel.DAO {
+	dao, err := database.New(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if initErr := dao.Init(); initErr != nil {
+		t.Fatal(initErr)
+	}
+	t.Cleanup(func() {
+		_ = dao.Close()
+	})
+	return dao
+}
+
+func newFileDAO(t *testing.T) databaseModel.DAO {
+	return newDAO(t, config.Database{File: &config.File{Folder: t.TempDir(), Extension: config.YAMLExtension}})
+}
+
+func newSQLiteDAO(t *testing.T) databaseModel.DAO {
+	return newDAO(t, config.Database{SQLite: &config.SQLite{Path: filepath.Join(t.TempDir(), "perses.db")}})
+}
+
+func newResources() []modelAPI.Entity {
+	createdAt := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	project := &v1.Project{Kind: v1.KindProject, Metadata: v1.Metadata{Name: "perses", CreatedAt: createdAt, UpdatedAt: createdAt, Version: 3}}
+	dashboard := &v1.Dashboard{
+		Kind: v1.KindDashboard,
+		Metadata: v1.ProjectMetadata{
+			Metadata: v1.Metadata{Name: "demo", CreatedAt: createdAt, UpdatedAt: createdAt.Add(time.Hour), Version: 7, Labels: map[string]string{"team": "sre"}},
+			Project:  "perses",
+		},
+		Spec: v1.DashboardSpec{
+			Panels: map[string]*v1.Panel{
+				"cpu": {Kind: "Panel", Spec: v1.PanelSpec{Display: v1.PanelDisplay{Name: "CPU"}, Plugin: common.Plugin{Kind: "TimeSeriesChart"}}},
+			},
+		},
+	}
+	globalVariable := &v1.GlobalVariable{
+		Kind:     v1.KindGlobalVariable,
+		Metadata: v1.Metadata{Name: "env", CreatedAt: createdAt, UpdatedAt: createdAt, Version: 1},
+		Spec:     v1.VariableSpec{Kind: variable.KindText, Spec: &variable.TextSpec{Value: "prod"}},
+	}
+	return []modelAPI.Entity{project, dashboard, v1.NewDashboardRevision(dashboard, "admin"), globalVariable}
+}
+
+func fill(t *testing.T, dao databaseModel.DAO, resources []modelAPI.Entity) {
+	for _, entity := range resources {
+		if err := dao.Upsert(entity); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func kindReport(report *Report, kind v1.Kind) KindReport {
+	for _, k := range report.Kinds {
+		if k.Kind == kind {
+			return *k
+		}
+	}
+	return KindReport{Kind: kind}
+}
+
+func TestMigrate(t *testing.T) {
+	from := newFileDAO(t)
+	to := newSQLiteDAO(t)
+	fill(t, from, newResources())
+
+	report, err := Migrate(from, to, Options{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, KindReport{Kind: v1.KindDashboard, Source: 1, Created: 1}, kindReport(report, v1.KindDashboard))
+	assert.Equal(t, KindReport{Kind: v1.KindDashboardRevision, Source: 1, Created: 1}, kindReport(report, v1.KindDashboardRevision))
+	assert.NoError(t, Verify(from, to))
+
+	// the metadata are preserved.
+	dashboard := &v1.Dashboard{}
+	if getErr := to.Get(v1.KindDashboard, v1.NewProjectMetadata("perses", "demo"), dashboard); getErr != nil {
+		t.Fatal(getErr)
+	}
+	assert.Equal(t, uint64(7), dashboard.Metadata.Version)
+	assert.True(t, dashboard.Metadata.UpdatedAt.Equal(time.Date(2023, 6, 1, 11, 0, 0, 0, time.UTC)))
+	assert.Equal(t, map[string]string{"team": "sre"}, dashboard.Metadata.Labels)
+
+	// the destination isn't empty anymore.
+	_, err = Migrate(from, to, Options{})
+	assert.Error(t, err)
+}
+
+func TestMigrateResume(t *testing.T) {
+	resources := newResources()
+	from := newFileDAO(t)
+	to := newSQLiteDAO(t)
+	fill(t, from, resources)
+	// an interrupted migration has copied the project only, and the global variable has been modified since then.
+	fill(t, to, resources[:1])
+	globalVariable := *resources[3].(*v1.GlobalVariable)
+	globalVariable.Metadata.Version = 0
+	fill(t, to, []modelAPI.Entity{&globalVariable})
+	assert.Error(t, Verify(from, to))
+
+	report, err := Migrate(from, to, Options{Resume: true, DryRun: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, KindReport{Kind: v1.KindProject, Source: 1, Unchanged: 1}, kindReport(report, v1.KindProject))
+	assert.Equal(t, KindReport{Kind: v1.KindGlobalVariable, Source: 1, Updated: 1}, kindReport(report, v1.KindGlobalVariable))
+	assert.Equal(t, KindReport{Kind: v1.KindDashboard, Source: 1, Created: 1}, kindReport(report, v1.KindDashboard))
+	// nothing has been written by the dry run.
+	assert.Error(t, Verify(from, to))
+
+	if _, err = Migrate(from, to, Options{Resume: true}); !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, Verify(from, to))
+
+	// the migration can be run again, nothing is copied.
+	report, err = Migrate(from, to, Options{Resume: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, k := range report.Kinds {
+		assert.Equal(t, k.Source, k.Unchanged, k.Kind)
+	}
+}
+
+func TestMigrateDryRunUninitialized(t *testing.T) {
+	from := newFileDAO(t)
+	fill(t, from, newResources())
+	path := filepath.Join(t.TempDir(), "perses.db")
+	to, err := database.New(config.Database{SQLite: &config.SQLite{Path: path}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer to.Close()
+
+	// the dry run doesn't create the schema of the destination.
+	report, err := Migrate(from, to, Options{DryRun: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, KindReport{Kind: v1.KindDashboard, Source: 1, Created: 1}, kindReport(report, v1.KindDashboard))
+	initialized, err := to.(initializer).IsInitialized()
+	assert.NoError(t, err)
+	assert.False(t, initialized)
+}
+
+func TestVerify(t *testing.T) {
+	from := newSQLiteDAO(t)
+	to := newFileDAO(t)
+	resources := newResources()
+	fill(t, from, resources)
+	fill(t, to, resources)
+	// the file database decodes the empty layouts of the dashboard as an empty list, while SQLite decodes them as nil.
+	assert.NoError(t, Verify(from, to))
+
+	// a resource only in the destination is detected.
+	extra := &v1.Project{Kind: v1.KindProject, Metadata: v1.Metadata{Name: "extra"}}
+	fill(t, to, []modelAPI.Entity{extra})
+	assert.ErrorContains(t, Verify(from, to), "Project: 1 in the source, 2 in the destination")
+}
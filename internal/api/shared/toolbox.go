@@ -14,31 +14,78 @@
 package shared
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
 	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
 	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
+// HeaderContinue is the header of the response containing the token to get the next page of a list.
+// It is not set when there is no more page.
+const HeaderContinue = "X-Continue-Token"
+
 type Parameters struct {
 	Project string
 	Name    string
+	// Version is the version of the resource the client expects to modify.
+	// It is coming from the header If-Match and is nil if the header is not set.
+	Version *uint64
+	// Author is who sent the request. It is empty when the request is not authenticated.
+	Author string
 }
 
 func ExtractParameters(ctx echo.Context) Parameters {
 	return Parameters{
 		Project: GetProjectParameter(ctx),
 		Name:    GetNameParameter(ctx),
+		Author:  GetAuthor(ctx),
 	}
 }
 
 type ToolboxService interface {
-	Create(entity api.Entity) (interface{}, error)
+	Create(entity api.Entity, parameters Parameters) (interface{}, error)
 	Update(entity api.Entity, parameters Parameters) (interface{}, error)
 	Delete(parameters Parameters) error
 	Get(parameters Parameters) (interface{}, error)
 	List(q databaseModel.Query, parameters Parameters) (interface{}, error)
+	// Watch returns the changes of the resources matching the query. The channel is closed when ctx is done.
+	Watch(ctx context.Context, q databaseModel.Query, parameters Parameters) (<-chan *v1.WatchEvent, error)
+}
+
+// PatchTargetService is implemented by the services whose Get doesn't return the whole resource, like the ones of the secrets.
+// A patch is applied to the resource returned by GetPatchTarget instead, so it can modify what Get hides.
+type PatchTargetService interface {
+	GetPatchTarget(parameters Parameters) (api.Entity, error)
+}
+
+// TransactionalService is implemented by the services whose writes can be part of a transaction, like the ones of the resources applied together.
+type TransactionalService interface {
+	ToolboxService
+	// InTransaction returns a copy of the service reading and writing with tx. The changes it makes outside the database,
+	// like the updates of the search index, are only made by commit, which must be called once the transaction is committed.
+	// The resources written are not validated against the other resources stored, as the caller validates them against the ones
+	// written in the same transaction.
+	InTransaction(tx databaseModel.DAO) (service ToolboxService, commit func())
+}
+
+// AuthoredService is implemented by the services whose changes can be attributed to who made them, like in the history of the
+// file database stored in git.
+type AuthoredService interface {
+	// WithAuthor returns a copy of the service attributing the changes it makes to author.
+	WithAuthor(author string) ToolboxService
+}
+
+// WithAuthor returns the service attributing its changes to the author of the request, when the service supports it.
+func WithAuthor(service ToolboxService, parameters Parameters) ToolboxService {
+	if authored, ok := service.(AuthoredService); ok && len(parameters.Author) > 0 {
+		return authored.WithAuthor(parameters.Author)
+	}
+	return service
 }
 
 // Toolbox is an interface that defines the different methods that can be used in the different endpoint of the API.
@@ -46,6 +93,9 @@ type ToolboxService interface {
 type Toolbox interface {
 	Create(ctx echo.Context, entity api.Entity) error
 	Update(ctx echo.Context, entity api.Entity) error
+	// Patch applies the patch sent in the body to the resource, and then updates it like Update.
+	// The entity is where the result of the patch is decoded.
+	Patch(ctx echo.Context, entity api.Entity) error
 	Delete(ctx echo.Context) error
 	Get(ctx echo.Context) error
 	List(ctx echo.Context, q databaseModel.Query) error
@@ -66,10 +116,14 @@ func (t *toolbox) Create(ctx echo.Context, entity api.Entity) error {
 	if err := t.bind(ctx, entity); err != nil {
 		return err
 	}
-	newEntity, err := t.service.Create(entity)
+	parameters := ExtractParameters(ctx)
+	newEntity, err := WithAuthor(t.service, parameters).Create(entity, parameters)
 	if err != nil {
 		return err
 	}
+	AuditChange(ctx, v1.ActionCreate, nil, newEntity)
+	NotifyChange(ctx, v1.ActionCreate, nil, newEntity)
+	setETag(ctx, newEntity)
 	return ctx.JSON(http.StatusOK, newEntity)
 }
 
@@ -77,28 +131,95 @@ func (t *toolbox) Update(ctx echo.Context, entity api.Entity) error {
 	if err := t.bind(ctx, entity); err != nil {
 		return err
 	}
-	parameters := ExtractParameters(ctx)
-	newEntity, err := t.service.Update(entity, parameters)
+	parameters, err := extractUpdateParameters(ctx)
+	if err != nil {
+		return err
+	}
+	return t.update(ctx, entity, parameters)
+}
+
+func (t *toolbox) Patch(ctx echo.Context, entity api.Entity) error {
+	parameters, err := extractUpdateParameters(ctx)
+	if err != nil {
+		return err
+	}
+	target, err := t.getPatchTarget(parameters)
+	if err != nil {
+		return err
+	}
+	document, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	patched, err := applyPatch(ctx, document)
+	if err != nil {
+		return err
+	}
+	// the version of the resource patched is kept in the result, so the update fails if the resource has been modified in the meantime.
+	if unmarshalErr := json.Unmarshal(patched, entity); unmarshalErr != nil {
+		return HandleBadRequestError(unmarshalErr.Error())
+	}
+	if validationErr := validateMetadata(ctx, entity.GetMetadata()); validationErr != nil {
+		return HandleBadRequestError(validationErr.Error())
+	}
+	return t.update(ctx, entity, parameters)
+}
+
+func (t *toolbox) getPatchTarget(parameters Parameters) (interface{}, error) {
+	if service, ok := t.service.(PatchTargetService); ok {
+		return service.GetPatchTarget(parameters)
+	}
+	return t.service.Get(parameters)
+}
+
+func (t *toolbox) update(ctx echo.Context, entity api.Entity, parameters Parameters) error {
+	oldEntity := t.getAudited(ctx, parameters)
+	newEntity, err := WithAuthor(t.service, parameters).Update(entity, parameters)
 	if err != nil {
 		return err
 	}
+	AuditChange(ctx, v1.ActionUpdate, oldEntity, newEntity)
+	NotifyChange(ctx, v1.ActionUpdate, oldEntity, newEntity)
+	setETag(ctx, newEntity)
 	return ctx.JSON(http.StatusOK, newEntity)
 }
 
+// extractUpdateParameters returns the parameters of a request modifying a resource, with the version expected by the header If-Match.
+func extractUpdateParameters(ctx echo.Context) (Parameters, error) {
+	parameters := ExtractParameters(ctx)
+	version, err := extractIfMatchVersion(ctx)
+	if err != nil {
+		return parameters, HandleBadRequestError(err.Error())
+	}
+	parameters.Version = version
+	return parameters, nil
+}
+
 func (t *toolbox) Delete(ctx echo.Context) error {
 	parameters := ExtractParameters(ctx)
-	if err := t.service.Delete(parameters); err != nil {
+	oldEntity := t.getAudited(ctx, parameters)
+	if err := WithAuthor(t.service, parameters).Delete(parameters); err != nil {
 		return err
 	}
+	AuditChange(ctx, v1.ActionDelete, oldEntity, nil)
+	NotifyChange(ctx, v1.ActionDelete, oldEntity, nil)
 	return ctx.NoContent(http.StatusNoContent)
 }
 
+// getAudited returns the entity about to be changed, so the change can be recorded and sent to the webhooks.
+func (t *toolbox) getAudited(ctx echo.Context, parameters Parameters) interface{} {
+	return GetChanged(ctx, func() (interface{}, error) {
+		return t.service.Get(parameters)
+	})
+}
+
 func (t *toolbox) Get(ctx echo.Context) error {
 	parameters := ExtractParameters(ctx)
 	entity, err := t.service.Get(parameters)
 	if err != nil {
 		return err
 	}
+	setETag(ctx, entity)
 	return ctx.JSON(http.StatusOK, entity)
 }
 
@@ -106,11 +227,34 @@ func (t *toolbox) List(ctx echo.Context, q databaseModel.Query) error {
 	if err := ctx.Bind(q); err != nil {
 		return HandleBadRequestError(err.Error())
 	}
+	paginatedQuery, isPaginated := q.(databaseModel.PaginatedQuery)
+	if isPaginated {
+		if err := paginatedQuery.GetPagination().Validate(); err != nil {
+			return HandleBadRequestError(err.Error())
+		}
+	}
+	if selectionQuery, ok := q.(databaseModel.LabelSelectionQuery); ok {
+		if _, err := selectionQuery.GetLabelSelection().Selector(); err != nil {
+			return HandleBadRequestError(err.Error())
+		}
+	}
 	parameters := ExtractParameters(ctx)
+	isWatch, watchErr := IsWatchRequest(ctx)
+	if watchErr != nil {
+		return HandleBadRequestError(fmt.Sprintf("invalid value for the parameter watch: %s", watchErr))
+	}
+	if isWatch {
+		return t.watch(ctx, q, parameters)
+	}
 	result, err := t.service.List(q, parameters)
 	if err != nil {
 		return err
 	}
+	// the resources are filtered once the page is read, so a page can be smaller than the limit.
+	result = filterReadable(ctx, result)
+	if isPaginated && len(paginatedQuery.GetPagination().Next()) > 0 {
+		ctx.Response().Header().Set(HeaderContinue, paginatedQuery.GetPagination().Next())
+	}
 	return ctx.JSON(http.StatusOK, result)
 }
 
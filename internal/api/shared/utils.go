@@ -24,23 +24,121 @@ import (
 )
 
 const (
-	ParamName            = "name"
-	ParamProject         = "project"
-	APIV1Prefix          = "/api/v1"
-	PathDashboard        = "dashboards"
-	PathDatasource       = "datasources"
-	PathFolder           = "folders"
-	PathGlobalDatasource = "globaldatasources"
-	PathGlobalSecret     = "globalsecrets"
-	PathGlobalVariable   = "globalvariables"
-	PathProject          = "projects"
-	PathSecret           = "secrets"
-	PathVariable         = "variables"
+	ParamName             = "name"
+	ParamProject          = "project"
+	ParamVersion          = "version"
+	ParamToken            = "token"
+	ParamDelivery         = "delivery"
+	APIV1Prefix           = "/api/v1"
+	PathApply             = "apply"
+	PathAudit             = "audit"
+	PathDashboard         = "dashboards"
+	PathDatasource        = "datasources"
+	PathDelivery          = "deliveries"
+	PathFolder            = "folders"
+	PathGlobalDatasource  = "globaldatasources"
+	PathGlobalRole        = "globalroles"
+	PathGlobalRoleBinding = "globalrolebindings"
+	PathGlobalSecret      = "globalsecrets"
+	PathGlobalVariable    = "globalvariables"
+	PathGlobalWebhook     = "globalwebhooks"
+	PathProject           = "projects"
+	PathRevision          = "revisions"
+	PathRole              = "roles"
+	PathRoleBinding       = "rolebindings"
+	PathSecret            = "secrets"
+	PathServiceAccount    = "serviceaccounts"
+	PathToken             = "tokens"
+	PathTrash             = "trash"
+	PathUser              = "users"
+	PathVariable          = "variables"
+	PathWebhook           = "webhooks"
 )
 
 // ProjectResourcePathList is containing the list of the resource path that are part of a project.
 var ProjectResourcePathList = []string{
-	PathDashboard, PathDatasource, PathFolder, PathSecret, PathVariable,
+	PathDashboard, PathDatasource, PathFolder, PathRole, PathRoleBinding, PathSecret, PathVariable, PathWebhook,
+}
+
+const (
+	// contextKeyUsername is the key of the echo context where the login of the authenticated user is stored.
+	contextKeyUsername = "perses.username"
+	// contextKeyGroups is the key of the echo context where the groups of the authenticated user are stored.
+	contextKeyGroups = "perses.groups"
+	// contextKeyServiceAccount is the key of the echo context where the name of the service account authenticated with an API token is stored.
+	contextKeyServiceAccount = "perses.serviceaccount"
+	// contextKeyTokenPermissions is the key of the echo context where the permissions the API token is restricted to are stored.
+	contextKeyTokenPermissions = "perses.tokenpermissions"
+)
+
+// SetUsername stores in the context the login of the user who sent the request.
+func SetUsername(ctx echo.Context, username string) {
+	ctx.Set(contextKeyUsername, username)
+}
+
+// GetUsername returns the login of the user who sent the request. It is empty when the request is not authenticated.
+func GetUsername(ctx echo.Context) string {
+	username, _ := ctx.Get(contextKeyUsername).(string)
+	return username
+}
+
+// SetGroups stores in the context the groups of the user who sent the request.
+func SetGroups(ctx echo.Context, groups []string) {
+	ctx.Set(contextKeyGroups, groups)
+}
+
+// GetGroups returns the groups of the user who sent the request, given by the identity provider they logged in with.
+func GetGroups(ctx echo.Context) []string {
+	groups, _ := ctx.Get(contextKeyGroups).([]string)
+	return groups
+}
+
+// SetServiceAccount stores in the context the service account who sent the request, and the permissions its API token is restricted to.
+// The permissions are empty when the token has every permission of the service account.
+func SetServiceAccount(ctx echo.Context, name string, tokenPermissions []v1.Permission) {
+	ctx.Set(contextKeyServiceAccount, name)
+	ctx.Set(contextKeyTokenPermissions, tokenPermissions)
+}
+
+// GetServiceAccount returns the name of the service account who sent the request. It is empty when the request is not sent with an API token.
+func GetServiceAccount(ctx echo.Context) string {
+	name, _ := ctx.Get(contextKeyServiceAccount).(string)
+	return name
+}
+
+// GetTokenPermissions returns the permissions the API token of the request is restricted to.
+func GetTokenPermissions(ctx echo.Context) []v1.Permission {
+	permissions, _ := ctx.Get(contextKeyTokenPermissions).([]v1.Permission)
+	return permissions
+}
+
+// GetSubjects returns who sent the request, as the subjects of the role bindings: the user and their groups, or the service account.
+// It is empty when the request is not authenticated.
+func GetSubjects(ctx echo.Context) []v1.Subject {
+	if name := GetServiceAccount(ctx); len(name) > 0 {
+		return []v1.Subject{{Kind: v1.SubjectKindServiceAccount, Name: name}}
+	}
+	login := GetUsername(ctx)
+	if len(login) == 0 {
+		return nil
+	}
+	groups := GetGroups(ctx)
+	subjects := make([]v1.Subject, 0, 1+len(groups))
+	subjects = append(subjects, v1.Subject{Kind: v1.SubjectKindUser, Name: login})
+	for _, group := range groups {
+		subjects = append(subjects, v1.Subject{Kind: v1.SubjectKindGroup, Name: group})
+	}
+	return subjects
+}
+
+// GetAuthor returns the name of the user or of the service account who sent the request.
+// It is empty when the request is not authenticated.
+func GetAuthor(ctx echo.Context) string {
+	subjects := GetSubjects(ctx)
+	if len(subjects) == 0 {
+		return ""
+	}
+	return subjects[0].Name
 }
 
 func GetNameParameter(ctx echo.Context) string {
@@ -72,14 +170,31 @@ func validateMetadataVersusParameter(ctx echo.Context, paramName string, metadat
 }
 
 func validateMetadata(ctx echo.Context, metadata api.Metadata) error {
+	if met, ok := metadata.(*v1.ProjectMetadata); ok {
+		if err := validateMetadataVersusParameter(ctx, ParamProject, &met.Project); err != nil {
+			return err
+		}
+	}
+	return ValidateMetadata(metadata)
+}
+
+// ValidateMetadata checks the name, the labels and the annotations of a resource.
+func ValidateMetadata(metadata api.Metadata) error {
 	if err := common.ValidateID(metadata.GetName()); err != nil {
 		return err
 	}
 	switch met := metadata.(type) {
 	case *v1.ProjectMetadata:
-		if err := validateMetadataVersusParameter(ctx, ParamProject, &met.Project); err != nil {
-			return err
-		}
+		return validateLabelsAndAnnotations(&met.Metadata)
+	case *v1.Metadata:
+		return validateLabelsAndAnnotations(met)
 	}
 	return nil
 }
+
+func validateLabelsAndAnnotations(metadata *v1.Metadata) error {
+	if err := common.ValidateLabels(metadata.Labels); err != nil {
+		return err
+	}
+	return common.ValidateAnnotations(metadata.Annotations)
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderIfMatch = "If-Match"
+	HeaderETag    = "ETag"
+	// UncheckedVersion is the metadata.version of an entity sent without its version. A stored resource starts at the version 1,
+	// so when there is no header If-Match either, the client opts out of the check: the entity replaces the one stored, whatever its version.
+	// It keeps the clients that are not aware of the version working. The others send the version they have read to be protected
+	// from overwriting a change made in the meantime.
+	UncheckedVersion uint64 = 0
+)
+
+// extractIfMatchVersion returns the version contained in the header If-Match.
+// The version is the ETag sent by the API when getting or updating a resource. Weak ETag are accepted too.
+// It returns nil when the header is not set or when it is equal to "*", as it means any version is matching.
+func extractIfMatchVersion(ctx echo.Context) (*uint64, error) {
+	value := strings.TrimSpace(ctx.Request().Header.Get(HeaderIfMatch))
+	if len(value) == 0 || value == "*" {
+		return nil, nil
+	}
+	value = strings.Trim(strings.TrimPrefix(value, "W/"), `"`)
+	version, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the header %s: %q is not a version", HeaderIfMatch, value)
+	}
+	return &version, nil
+}
+
+// getMetadata returns the metadata holding the version of the entity. It returns nil if the object is not an entity with a version.
+func getMetadata(entity interface{}) *v1.Metadata {
+	e, ok := entity.(api.Entity)
+	if !ok {
+		return nil
+	}
+	switch m := e.GetMetadata().(type) {
+	case *v1.ProjectMetadata:
+		return &m.Metadata
+	case *v1.Metadata:
+		return m
+	default:
+		return nil
+	}
+}
+
+// getVersion returns the metadata.version of the entity. It returns false if the object is not an entity with a version.
+func getVersion(entity interface{}) (uint64, bool) {
+	metadata := getMetadata(entity)
+	if metadata == nil {
+		return 0, false
+	}
+	return metadata.Version, true
+}
+
+// setETag sets the header ETag of the response with the version of the entity.
+func setETag(ctx echo.Context, entity interface{}) {
+	version, ok := getVersion(entity)
+	if !ok {
+		return
+	}
+	ctx.Response().Header().Set(HeaderETag, fmt.Sprintf("%q", strconv.FormatUint(version, 10)))
+}
+
+// CheckVersion verifies the entity sent by the client is based on the version currently stored.
+// The version expected by the client is the one from the header If-Match if set, otherwise it is the metadata.version of the entity.
+// Without header If-Match, an entity at the UncheckedVersion is an explicit opt-out: the version is not checked at all.
+func CheckVersion(parameters Parameters, entityVersion uint64, storedVersion uint64) error {
+	expectedVersion := entityVersion
+	if parameters.Version != nil {
+		expectedVersion = *parameters.Version
+	} else if entityVersion == UncheckedVersion {
+		return nil
+	}
+	if expectedVersion != storedVersion {
+		return HandleVersionConflictError(fmt.Sprintf("expected version %d but the current version is %d", expectedVersion, storedVersion))
+	}
+	return nil
+}
+
+// UpdateEntity replaces the stored entity previous by the entity sent by the client.
+// It checks the entity is based on the stored version, increases the version and calls update with the stored version,
+// so the database rejects the write if the entity has been modified in the meantime.
+func UpdateEntity(parameters Parameters, entity api.Entity, previous api.Entity, update func(previousVersion uint64) error) error {
+	metadata, previousMetadata := getMetadata(entity), getMetadata(previous)
+	if err := CheckVersion(parameters, metadata.Version, previousMetadata.Version); err != nil {
+		return err
+	}
+	metadata.Update(*previousMetadata)
+	if err := update(previousMetadata.Version); err != nil {
+		if databaseModel.IsKeyConflict(err) {
+			// the entity has been modified between the time it has been read and the time it has been replaced.
+			return HandleVersionConflictError(fmt.Sprintf("the version %d has been modified during the update", previousMetadata.Version))
+		}
+		logrus.WithError(err).Errorf("unable to perform the update of the %s %q, something wrong with the database", entity.GetKind(), metadata.Name)
+		return err
+	}
+	return nil
+}
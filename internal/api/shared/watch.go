@@ -0,0 +1,106 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	databaseModel "github.com/perses/perses/internal/api/shared/database/model"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// ContentTypeNDJSON is the content type of the response of a watch: one JSON event per line.
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// IsWatchRequest returns true if the client asks to watch the list rather than getting it once.
+func IsWatchRequest(ctx echo.Context) (bool, error) {
+	value := ctx.QueryParam("watch")
+	if len(value) == 0 {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// MapWatchEvents returns the events with their object replaced by the result of f.
+// It is used when the object returned to the client is not the one stored, like for the secrets.
+func MapWatchEvents(ctx context.Context, events <-chan *v1.WatchEvent, f func(object interface{}) interface{}) <-chan *v1.WatchEvent {
+	result := make(chan *v1.WatchEvent)
+	go func() {
+		defer close(result)
+		for event := range events {
+			select {
+			case result <- &v1.WatchEvent{Type: event.Type, Object: f(event.Object)}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return result
+}
+
+// watch sends the resources of the list as ADDED events, then every change until the client closes the connection.
+// The client subscribes to the changes before the list is read, so nothing is missed in between.
+// As a result, a resource modified meanwhile can be received twice, which is fine as the events contain the whole resource.
+func (t *toolbox) watch(ctx echo.Context, q databaseModel.Query, parameters Parameters) error {
+	if paginatedQuery, ok := q.(databaseModel.PaginatedQuery); ok {
+		if pagination := paginatedQuery.GetPagination(); pagination.Limit > 0 || len(pagination.Continue) > 0 {
+			return HandleBadRequestError("the parameters limit and continue cannot be used with watch")
+		}
+	}
+	requestCtx := ctx.Request().Context()
+	events, err := t.service.Watch(requestCtx, q, parameters)
+	if err != nil {
+		return err
+	}
+	list, err := t.service.List(q, parameters)
+	if err != nil {
+		return err
+	}
+	response := ctx.Response()
+	response.Header().Set(echo.HeaderContentType, ContentTypeNDJSON)
+	// the events must not be buffered by a proxy.
+	response.Header().Set("X-Accel-Buffering", "no")
+	response.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(response)
+	items := reflect.ValueOf(filterReadable(ctx, list))
+	for i := 0; i < items.Len(); i++ {
+		if encodeErr := encoder.Encode(&v1.WatchEvent{Type: v1.EventTypeAdded, Object: items.Index(i).Interface()}); encodeErr != nil {
+			return encodeErr
+		}
+	}
+	response.Flush()
+	for {
+		select {
+		case <-requestCtx.Done():
+			return nil
+		case event, isOpen := <-events:
+			if !isOpen {
+				// the watcher has been dropped, the client has to watch again.
+				return nil
+			}
+			if !canReadObject(ctx, event.Object) {
+				continue
+			}
+			if encodeErr := encoder.Encode(event); encodeErr != nil {
+				return encodeErr
+			}
+			response.Flush()
+		}
+	}
+}
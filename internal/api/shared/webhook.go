@@ -0,0 +1,74 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shared
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// contextKeyNotifier is the key of the echo context where the Notifier of the request is stored.
+const contextKeyNotifier = "perses.notifier"
+
+// Notifier sends to the webhooks an event describing a change made by a request.
+type Notifier func(event *v1.WebhookEvent)
+
+// SetNotifier stores in the context where the changes made by the request are sent. Nothing is sent when it is not set.
+func SetNotifier(ctx echo.Context, notifier Notifier) {
+	ctx.Set(contextKeyNotifier, notifier)
+}
+
+// IsNotified returns true if the changes made by the request are sent to the webhooks.
+func IsNotified(ctx echo.Context) bool {
+	_, ok := ctx.Get(contextKeyNotifier).(Notifier)
+	return ok
+}
+
+// NotifyChange sends to the webhooks the creation, the update or the deletion of an entity. The old entity is nil for a creation,
+// and the new one is nil for a deletion. The event contains the new entity, or the old one for a deletion.
+func NotifyChange(ctx echo.Context, action v1.Action, oldEntity interface{}, newEntity interface{}) {
+	notifier, isNotified := ctx.Get(contextKeyNotifier).(Notifier)
+	if !isNotified {
+		return
+	}
+	var object interface{} = newEntity
+	if newEntity == nil {
+		object = oldEntity
+	}
+	entity, ok := object.(api.Entity)
+	if !ok {
+		return
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		// the event is sent anyway, only the resource is missing.
+		logrus.WithError(err).Errorf("unable to encode the %s %q sent to the webhooks", entity.GetKind(), entity.GetMetadata().GetName())
+	}
+	event := &v1.WebhookEvent{
+		Time:     time.Now().UTC(),
+		Action:   action,
+		Resource: getAuditResource(entity),
+		Object:   data,
+	}
+	if subjects := GetSubjects(ctx); len(subjects) > 0 {
+		// the first subject is the user or the service account, the others are the groups of the user.
+		event.Actor = &subjects[0]
+	}
+	notifier(event)
+}
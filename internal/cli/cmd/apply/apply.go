@@ -14,7 +14,6 @@
 package apply
 
 import (
-	"errors"
 	"fmt"
 	"io"
 
@@ -23,9 +22,7 @@ import (
 	"github.com/perses/perses/internal/cli/file"
 	"github.com/perses/perses/internal/cli/opt"
 	"github.com/perses/perses/internal/cli/resource"
-	"github.com/perses/perses/internal/cli/service"
 	"github.com/perses/perses/pkg/client/api"
-	"github.com/perses/perses/pkg/client/perseshttp"
 	modelV1 "github.com/perses/perses/pkg/model/api/v1"
 	"github.com/spf13/cobra"
 )
@@ -34,6 +31,7 @@ type option struct {
 	persesCMD.Option
 	opt.ProjectOption
 	opt.FileOption
+	dryRun    bool
 	writer    io.Writer
 	apiClient api.ClientInterface
 }
@@ -70,39 +68,36 @@ func (o *option) Execute() error {
 		return err
 	}
 	for _, entity := range entities {
-		kind := modelV1.Kind(entity.GetKind())
-		name := entity.GetMetadata().GetName()
-		project := resource.GetProject(entity.GetMetadata(), o.Project)
-		svc, svcErr := service.New(kind, project, o.apiClient)
-		if svcErr != nil {
-			return svcErr
+		// the resources without project are applied in the project given by the flag or by the CLI config.
+		if metadata, ok := entity.GetMetadata().(*modelV1.ProjectMetadata); ok && len(metadata.Project) == 0 {
+			metadata.Project = o.Project
 		}
-
-		// retrieve if exists the entity from the Perses API
-		_, apiError := svc.GetResource(name)
-		if apiError != nil && !errors.Is(apiError, perseshttp.RequestNotFoundError) {
-			return fmt.Errorf("unable to retrieve the %q from the Perses API. %w", kind, apiError)
-		}
-
-		if errors.Is(apiError, perseshttp.RequestNotFoundError) {
-			// the document doesn't exist, so we have to create it.
-			if _, createError := svc.CreateResource(entity); createError != nil {
-				return createError
-			}
-		} else {
-			// the document doesn't exist, so we have to create it.
-			if _, updateError := svc.UpdateResource(entity); updateError != nil {
-				return updateError
-			}
-		}
-
-		if outputError := resource.HandleSuccessMessage(o.writer, kind, project, fmt.Sprintf("object %q %q has been applied", kind, name)); outputError != nil {
+	}
+	// the resources are sent all at once, so the server validates them all before writing the first one.
+	response, err := o.apiClient.V1().Apply().Apply(entities, o.dryRun)
+	if err != nil {
+		return err
+	}
+	for _, result := range response.Results {
+		message := fmt.Sprintf("object %q %q %s", result.Kind, result.Name, describeAction(result.Action, response.DryRun))
+		if outputError := resource.HandleSuccessMessage(o.writer, result.Kind, result.Project, message); outputError != nil {
 			return outputError
 		}
 	}
 	return nil
 }
 
+func describeAction(action modelV1.ApplyAction, dryRun bool) string {
+	switch {
+	case action == modelV1.ApplyActionUnchanged:
+		return "is unchanged"
+	case dryRun:
+		return fmt.Sprintf("would be %sd", action)
+	default:
+		return fmt.Sprintf("has been %sd", action)
+	}
+}
+
 func (o *option) SetWriter(writer io.Writer) {
 	o.writer = writer
 }
@@ -118,6 +113,9 @@ percli apply -f ./resources.json
 
 # Apply the JSON passed into stdin to the remote Perses server.
 cat ./resources.json | percli apply -f -
+
+# Show what would be created or updated, without modifying anything.
+percli apply -f ./resources.json --dry-run
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return persesCMD.Run(o, cmd, args)
@@ -126,5 +124,6 @@ cat ./resources.json | percli apply -f -
 	opt.AddProjectFlags(cmd, &o.ProjectOption)
 	opt.AddFileFlags(cmd, &o.FileOption)
 	opt.MarkFileFlagAsMandatory(cmd)
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", o.dryRun, "If true, the resources are only validated by the server, which tells what would be created or updated.")
 	return cmd
 }
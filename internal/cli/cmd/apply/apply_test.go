@@ -46,7 +46,7 @@ func TestApplyCMD(t *testing.T) {
 			Args:            []string{"-f", "../../test/sample_resources/single_resource.json", "--project", "perses"},
 			APIClient:       fakeapi.New(),
 			IsErrorExpected: false,
-			ExpectedMessage: `object "Folder" "ff15" has been applied in the project "perses"
+			ExpectedMessage: `object "Folder" "ff15" has been created in the project "perses"
 `,
 		},
 		{
@@ -54,9 +54,17 @@ func TestApplyCMD(t *testing.T) {
 			Args:            []string{"-f", "../../test/sample_resources/multiple_resources.json", "--project", "perses"},
 			APIClient:       fakeapi.New(),
 			IsErrorExpected: false,
-			ExpectedMessage: `object "Folder" "ff15" has been applied in the project "perses"
-object "Folder" "aoe4" has been applied in the project "game"
-object "Project" "perses" has been applied
+			ExpectedMessage: `object "Folder" "ff15" has been created in the project "perses"
+object "Folder" "aoe4" has been created in the project "game"
+object "Project" "perses" has been created
+`,
+		},
+		{
+			Title:           "dry run",
+			Args:            []string{"-f", "../../test/sample_resources/single_resource.json", "--project", "perses", "--dry-run"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: false,
+			ExpectedMessage: `object "Folder" "ff15" would be created in the project "perses"
 `,
 		},
 	}
@@ -0,0 +1,130 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/perses/perses/internal/api/shared/backup"
+	persesCMD "github.com/perses/perses/internal/cli/cmd"
+	"github.com/perses/perses/internal/cli/config"
+	"github.com/perses/perses/internal/cli/opt"
+	"github.com/perses/perses/internal/cli/output"
+	"github.com/perses/perses/pkg/client/api"
+	"github.com/spf13/cobra"
+)
+
+type option struct {
+	persesCMD.Option
+	opt.PassphraseOption
+	writer    io.Writer
+	file      string
+	apiClient api.ClientInterface
+}
+
+func (o *option) Complete(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("only the path of the backup can be specified as an argument")
+	}
+	if len(args) == 1 {
+		o.file = args[0]
+	} else {
+		o.file = fmt.Sprintf("perses-backup-%s.ndjson.gz", time.Now().UTC().Format("20060102-150405"))
+	}
+	if err := o.PassphraseOption.Complete(); err != nil {
+		return err
+	}
+	apiClient, err := config.Global.GetAPIClient()
+	if err != nil {
+		return err
+	}
+	o.apiClient = apiClient
+	return nil
+}
+
+func (o *option) Validate() error {
+	if _, err := os.Stat(o.file); err == nil {
+		return fmt.Errorf("the file %q already exists", o.file)
+	}
+	return nil
+}
+
+func (o *option) Execute() error {
+	if err := o.download(); err != nil {
+		_ = os.Remove(o.file)
+		return err
+	}
+	// the server cannot report an error once it has started to send the archive, so it is checked it is complete.
+	f, err := os.Open(o.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint: errcheck
+	summary, err := backup.Verify(f)
+	if err != nil {
+		return fmt.Errorf("the backup written in %q is not valid: %w", o.file, err)
+	}
+	count := 0
+	for _, c := range summary.Resources {
+		count += c
+	}
+	return output.HandleString(o.writer, fmt.Sprintf("backup of %d resources written in %q", count, o.file))
+}
+
+func (o *option) download() error {
+	archive, err := o.apiClient.Admin().Export(o.Passphrase)
+	if err != nil {
+		return err
+	}
+	defer archive.Close() // nolint: errcheck
+	f, err := os.OpenFile(o.file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, copyErr := io.Copy(f, archive); copyErr != nil {
+		_ = f.Close()
+		return copyErr
+	}
+	return f.Close()
+}
+
+func (o *option) SetWriter(writer io.Writer) {
+	o.writer = writer
+}
+
+func NewCMD() *cobra.Command {
+	o := &option{}
+	cmd := &cobra.Command{
+		Use:   "backup [FILE]",
+		Short: "Save every resource of the Perses server in an archive",
+		Long: `Save every resource of the Perses server in an archive, that can be restored with 'percli restore'.
+By default, the secrets are encrypted with the encryption key of the server, so the archive can only be restored on a server using the same key.
+When a passphrase is given, the secrets are encrypted with it instead, so the archive can be restored on any server.`,
+		Example: `
+# Save the resources in a file named with the current date.
+percli backup
+
+# Save the resources in the given file, with the secrets encrypted with a passphrase.
+percli backup ./perses.ndjson.gz --passphrase-file ./passphrase
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return persesCMD.Run(o, cmd, args)
+		},
+	}
+	opt.AddPassphraseFlags(cmd, &o.PassphraseOption)
+	return cmd
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cmdTest "github.com/perses/perses/internal/cli/test"
+	fakeapi "github.com/perses/perses/pkg/client/fake/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackupCMD(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "perses.ndjson.gz")
+	existingFile := filepath.Join(t.TempDir(), "existing.ndjson.gz")
+	if err := os.WriteFile(existingFile, []byte("content"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	testSuite := []cmdTest.Suite{
+		{
+			Title:           "too many args",
+			Args:            []string{"a.ndjson.gz", "b.ndjson.gz"},
+			IsErrorExpected: true,
+			ExpectedMessage: "only the path of the backup can be specified as an argument",
+		},
+		{
+			Title:           "not connected to any API",
+			Args:            []string{file},
+			IsErrorExpected: true,
+			ExpectedMessage: "you are not connected to any API",
+		},
+		{
+			Title:           "file already existing",
+			Args:            []string{existingFile},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: fmt.Sprintf("the file %q already exists", existingFile),
+		},
+		{
+			Title:           "backup written",
+			Args:            []string{file},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: false,
+			ExpectedMessage: fmt.Sprintf("backup of 1 resources written in %q\n", file),
+		},
+	}
+	cmdTest.ExecuteSuiteTest(t, NewCMD, testSuite)
+	data, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, fakeapi.BackupArchive(), data)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/perses/perses/internal/cli/resource"
 	"github.com/perses/perses/internal/cli/service"
 	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
 	"github.com/spf13/cobra"
 )
 
@@ -35,6 +36,7 @@ type option struct {
 	kind            modelV1.Kind
 	allProject      bool
 	prefix          string
+	labelSelector   string
 	resourceService service.Service
 }
 
@@ -84,11 +86,14 @@ func (o *option) Complete(args []string) error {
 }
 
 func (o *option) Validate() error {
+	if _, err := common.ParseLabelSelector(o.labelSelector); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (o *option) Execute() error {
-	resourceList, err := o.resourceService.ListResource(o.prefix)
+	resourceList, err := o.resourceService.ListResource(o.prefix, o.labelSelector)
 	if err != nil {
 		return err
 	}
@@ -119,6 +124,9 @@ percli get dashboards node
 # List all dashboards in a specific project.
 percli get dashboards -p my_project
 
+# List all dashboards with the label team=sre and without the label tier=dev.
+percli get dashboards -l 'team=sre,tier!=dev'
+
 #List all dashboards as a JSON object.
 percli get dashboards -a -ojson
 
@@ -130,6 +138,7 @@ percli get dashboards -a -ojson
 	opt.AddOutputFlags(cmd, &o.OutputOption)
 	opt.AddProjectFlags(cmd, &o.ProjectOption)
 	cmd.Flags().BoolVarP(&o.allProject, "all", "a", o.allProject, "If present, list the requested object(s) across all projects. The project in the current context is ignored even if specified with --project.")
+	cmd.Flags().StringVarP(&o.labelSelector, "selector", "l", o.labelSelector, "Label selector to filter on, like 'team=sre,tier!=dev' or 'tier in (frontend,backend)'. The supported operators are =, ==, !=, in, notin, and ! to check a label is not set.")
 	cmd.MarkFlagsMutuallyExclusive("project", "all")
 	return cmd
 }
@@ -57,6 +57,20 @@ func TestGetCMD(t *testing.T) {
 			IsErrorExpected: false,
 			ExpectedMessage: string(test.JSONMarshalStrict(fakev1.ProjectList("per"))) + "\n",
 		},
+		{
+			Title:           "get project with a label selector in json format",
+			Args:            []string{"project", "-l", "team=perses", "-ojson"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: false,
+			ExpectedMessage: string(test.JSONMarshalStrict(fakev1.ProjectList("perses"))) + "\n",
+		},
+		{
+			Title:           "get project with an invalid label selector",
+			Args:            []string{"project", "-l", "team in perses"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: "invalid label selector \"team in perses\": unable to parse the requirement \"team in perses\"",
+		},
 		{
 			Title:           "get globaldatasource in json format",
 			Args:            []string{"gdts", "-ojson"},
@@ -14,13 +14,20 @@
 package login
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	persesCMD "github.com/perses/perses/internal/cli/cmd"
 	"github.com/perses/perses/internal/cli/config"
+	"github.com/perses/perses/internal/cli/output"
+	"github.com/perses/perses/pkg/client/api"
 	"github.com/perses/perses/pkg/client/perseshttp"
+	modelAPI "github.com/perses/perses/pkg/model/api"
 	"github.com/spf13/cobra"
 )
 
@@ -29,6 +36,10 @@ type option struct {
 	writer      io.Writer
 	url         string
 	insecureTLS bool
+	username    string
+	password    string
+	provider    string
+	token       string
 }
 
 func (o *option) Complete(args []string) error {
@@ -43,16 +54,128 @@ func (o *option) Validate() error {
 	if _, err := url.Parse(o.url); err != nil {
 		return err
 	}
+	if (len(o.username) > 0) != (len(o.password) > 0) {
+		return fmt.Errorf("--username and --password must be used together")
+	}
+	if len(o.provider) > 0 && len(o.username) > 0 {
+		return fmt.Errorf("--provider and --username are mutually exclusive")
+	}
+	if len(o.token) > 0 && (len(o.username) > 0 || len(o.provider) > 0) {
+		return fmt.Errorf("--token cannot be used with --username or --provider")
+	}
 	return nil
 }
 
 func (o *option) Execute() error {
-	return config.Write(&config.Config{
+	conf := &config.Config{
 		RestClientConfig: perseshttp.RestConfigClient{
 			URL:         o.url,
 			InsecureTLS: o.insecureTLS,
+			// an API token is used as it is until it expires, there is nothing to refresh.
+			Token: o.token,
 		},
-	})
+	}
+	if len(o.username) > 0 {
+		if err := o.authenticate(conf); err != nil {
+			return err
+		}
+	}
+	if len(o.provider) > 0 {
+		if err := o.authenticateWithProvider(conf); err != nil {
+			return err
+		}
+	}
+	if err := config.Write(conf); err != nil {
+		return err
+	}
+	if len(o.username) > 0 {
+		return output.HandleString(o.writer, fmt.Sprintf("successfully logged in %s as %s", o.url, o.username))
+	}
+	if len(o.provider) > 0 {
+		return output.HandleString(o.writer, fmt.Sprintf("successfully logged in %s with the provider %s", o.url, o.provider))
+	}
+	if len(o.token) > 0 {
+		return output.HandleString(o.writer, fmt.Sprintf("successfully logged in %s with an API token", o.url))
+	}
+	return nil
+}
+
+// authenticate sets in the config the tokens returned by the server for the user.
+func (o *option) authenticate(conf *config.Config) error {
+	restClient, err := perseshttp.NewFromConfig(conf.RestClientConfig)
+	if err != nil {
+		return err
+	}
+	response, err := api.NewWithClient(restClient).Auth().Login(o.username, o.password)
+	if err != nil {
+		return err
+	}
+	conf.RestClientConfig.Token = response.AccessToken
+	conf.RefreshToken = response.RefreshToken
+	return nil
+}
+
+// authenticateWithProvider sets in the config the tokens of the user logged in with the provider, using the device authorization flow:
+// the user logs in with a browser, while percli polls the server until it is done.
+func (o *option) authenticateWithProvider(conf *config.Config) error {
+	restClient, err := perseshttp.NewFromConfig(conf.RestClientConfig)
+	if err != nil {
+		return err
+	}
+	authClient := api.NewWithClient(restClient).Auth()
+	providers, err := authClient.Providers()
+	if err != nil {
+		return err
+	}
+	var provider *modelAPI.AuthProvider
+	slugIDs := make([]string, 0, len(providers))
+	for i := range providers {
+		slugIDs = append(slugIDs, providers[i].SlugID)
+		if providers[i].SlugID == o.provider {
+			provider = &providers[i]
+		}
+	}
+	if provider == nil {
+		return fmt.Errorf("the provider %q doesn't exist. The providers available are: %s", o.provider, strings.Join(slugIDs, ", "))
+	}
+	deviceCode, err := authClient.DeviceCode(provider.Kind, provider.SlugID)
+	if err != nil {
+		return err
+	}
+	if len(deviceCode.VerificationURIComplete) > 0 {
+		err = output.HandleString(o.writer, fmt.Sprintf("To log in with %s, open %s in a browser and check the code is %s", provider.Name, deviceCode.VerificationURIComplete, deviceCode.UserCode))
+	} else {
+		err = output.HandleString(o.writer, fmt.Sprintf("To log in with %s, open %s in a browser and enter the code %s", provider.Name, deviceCode.VerificationURI, deviceCode.UserCode))
+	}
+	if err != nil {
+		return err
+	}
+	interval := time.Duration(deviceCode.Interval) * time.Second
+	var deadline time.Time
+	if deviceCode.ExpiresIn > 0 {
+		deadline = time.Now().Add(time.Duration(deviceCode.ExpiresIn) * time.Second)
+	}
+	for deadline.IsZero() || time.Now().Before(deadline) {
+		time.Sleep(interval)
+		response, tokenErr := authClient.DeviceAccessToken(provider.Kind, provider.SlugID, deviceCode.DeviceCode)
+		if tokenErr == nil {
+			conf.RestClientConfig.Token = response.AccessToken
+			conf.RefreshToken = response.RefreshToken
+			return nil
+		}
+		var requestErr *perseshttp.RequestError
+		if !errors.As(tokenErr, &requestErr) || requestErr.StatusCode != http.StatusBadRequest {
+			return tokenErr
+		}
+		switch requestErr.Message {
+		case modelAPI.DeviceAuthorizationPending:
+		case modelAPI.DeviceSlowDown:
+			interval += 5 * time.Second
+		default:
+			return tokenErr
+		}
+	}
+	return fmt.Errorf("the code has expired before the login was done")
 }
 
 func (o *option) SetWriter(writer io.Writer) {
@@ -67,11 +190,24 @@ func NewCMD() *cobra.Command {
 		Example: `
 # Log in to the given server
 percli login https://perses.dev
+
+# Log in to the given server with a user, when the authentication is enabled
+percli login https://perses.dev --username=john --password=secret
+
+# Log in to the given server with an identity provider configured on the server
+percli login https://perses.dev --provider=google
+
+# Log in to the given server with the API token of a service account
+percli login https://perses.dev --token=perses_ci.deploy.xxxxxxxx
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return persesCMD.Run(o, cmd, args)
 		},
 	}
+	cmd.Flags().StringVar(&o.username, "username", "", "Username used to authenticate against the server, when the authentication is enabled.")
+	cmd.Flags().StringVar(&o.password, "password", "", "Password of the user.")
+	cmd.Flags().StringVar(&o.provider, "provider", "", "Slug ID of the identity provider to log in with. You log in with a browser, using the device authorization flow.")
+	cmd.Flags().StringVar(&o.token, "token", "", "API token of a service account to authenticate with, instead of a user.")
 	cmd.Flags().BoolVar(&o.insecureTLS, "insecure-skip-tls-verify", o.insecureTLS, "If true the server's certificate will not be checked for validity. This will make your HTTPS connections insecure.")
 	return cmd
 }
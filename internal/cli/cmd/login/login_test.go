@@ -0,0 +1,56 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package login
+
+import (
+	"testing"
+
+	cmdTest "github.com/perses/perses/internal/cli/test"
+)
+
+func TestLoginCMD(t *testing.T) {
+	testSuite := []cmdTest.Suite{
+		{
+			Title:           "empty args",
+			Args:            []string{},
+			IsErrorExpected: true,
+			ExpectedMessage: "only the server URL should be specified as an argument",
+		},
+		{
+			Title:           "username without password",
+			Args:            []string{"https://demo.perses.dev", "--username", "john"},
+			IsErrorExpected: true,
+			ExpectedMessage: "--username and --password must be used together",
+		},
+		{
+			Title:           "password without username",
+			Args:            []string{"https://demo.perses.dev", "--password", "secret"},
+			IsErrorExpected: true,
+			ExpectedMessage: "--username and --password must be used together",
+		},
+		{
+			Title:           "provider with username",
+			Args:            []string{"https://demo.perses.dev", "--provider", "google", "--username", "john", "--password", "secret"},
+			IsErrorExpected: true,
+			ExpectedMessage: "--provider and --username are mutually exclusive",
+		},
+		{
+			Title:           "token with provider",
+			Args:            []string{"https://demo.perses.dev", "--token", "perses_ci.deploy.secret", "--provider", "google"},
+			IsErrorExpected: true,
+			ExpectedMessage: "--token cannot be used with --username or --provider",
+		},
+	}
+	cmdTest.ExecuteSuiteTest(t, NewCMD, testSuite)
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/perses/perses/internal/api/shared/backup"
+	persesCMD "github.com/perses/perses/internal/cli/cmd"
+	"github.com/perses/perses/internal/cli/config"
+	"github.com/perses/perses/internal/cli/opt"
+	"github.com/perses/perses/internal/cli/output"
+	"github.com/perses/perses/pkg/client/api"
+	"github.com/spf13/cobra"
+)
+
+var columnHeader = []string{"KIND", "RESTORED"}
+
+type option struct {
+	persesCMD.Option
+	opt.OutputOption
+	opt.PassphraseOption
+	writer    io.Writer
+	file      string
+	apiClient api.ClientInterface
+}
+
+func (o *option) Complete(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("the path of the backup to restore must be specified as an argument")
+	}
+	o.file = args[0]
+	// Complete the output only if it has been set by the user
+	if len(o.Output) > 0 {
+		if outputErr := o.OutputOption.Complete(); outputErr != nil {
+			return outputErr
+		}
+	}
+	if err := o.PassphraseOption.Complete(); err != nil {
+		return err
+	}
+	apiClient, err := config.Global.GetAPIClient()
+	if err != nil {
+		return err
+	}
+	o.apiClient = apiClient
+	return nil
+}
+
+func (o *option) Validate() error {
+	// the archive is checked before being sent, so an incomplete one is rejected without sending it.
+	f, err := os.Open(o.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint: errcheck
+	if _, verifyErr := backup.Verify(f); verifyErr != nil {
+		return fmt.Errorf("the backup %q is not valid: %w", o.file, verifyErr)
+	}
+	return nil
+}
+
+func (o *option) Execute() error {
+	f, err := os.Open(o.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint: errcheck
+	summary, err := o.apiClient.Admin().Import(f, o.Passphrase)
+	if err != nil {
+		return err
+	}
+	if len(o.Output) > 0 {
+		return output.Handle(o.writer, o.Output, summary)
+	}
+	if outputErr := output.HandleString(o.writer, fmt.Sprintf("the backup created the %s has been restored", summary.CreatedAt.Local().Format("2006-01-02 15:04:05"))); outputErr != nil {
+		return outputErr
+	}
+	var data [][]string
+	for _, kind := range backup.Kinds {
+		if count := summary.Resources[kind]; count > 0 {
+			data = append(data, []string{string(kind), strconv.Itoa(count)})
+		}
+	}
+	output.HandlerTable(o.writer, columnHeader, data)
+	return nil
+}
+
+func (o *option) SetWriter(writer io.Writer) {
+	o.writer = writer
+}
+
+func NewCMD() *cobra.Command {
+	o := &option{}
+	cmd := &cobra.Command{
+		Use:   "restore FILE",
+		Short: "Restore the resources saved in an archive by 'percli backup'",
+		Long: `Restore the resources saved in an archive by 'percli backup'.
+The resources existing with the same name are replaced, the other ones are kept.
+The passphrase is required if the archive has been created with one.`,
+		Example: `
+# Restore a backup whose secrets are encrypted with a passphrase.
+percli restore ./perses.ndjson.gz --passphrase-file ./passphrase
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return persesCMD.Run(o, cmd, args)
+		},
+	}
+	opt.AddOutputFlags(cmd, &o.OutputOption)
+	opt.AddPassphraseFlags(cmd, &o.PassphraseOption)
+	return cmd
+}
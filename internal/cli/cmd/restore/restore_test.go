@@ -0,0 +1,78 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cmdTest "github.com/perses/perses/internal/cli/test"
+	fakeapi "github.com/perses/perses/pkg/client/fake/api"
+)
+
+func TestRestoreCMD(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "perses.ndjson.gz")
+	if err := os.WriteFile(file, fakeapi.BackupArchive(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	invalidFile := filepath.Join(t.TempDir(), "invalid.ndjson.gz")
+	if err := os.WriteFile(invalidFile, []byte("content"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	createdAt := time.Date(2023, time.October, 1, 12, 0, 0, 0, time.UTC).Local().Format("2006-01-02 15:04:05")
+	testSuite := []cmdTest.Suite{
+		{
+			Title:           "empty args",
+			Args:            []string{},
+			IsErrorExpected: true,
+			ExpectedMessage: "the path of the backup to restore must be specified as an argument",
+		},
+		{
+			Title:           "not connected to any API",
+			Args:            []string{file},
+			IsErrorExpected: true,
+			ExpectedMessage: "you are not connected to any API",
+		},
+		{
+			Title:           "invalid backup",
+			Args:            []string{invalidFile},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: fmt.Sprintf("the backup %q is not valid: the archive is not compressed with gzip: unexpected EOF", invalidFile),
+		},
+		{
+			Title:           "backup restored",
+			Args:            []string{file},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: false,
+			ExpectedMessage: fmt.Sprintf(`the backup created the %s has been restored
+   KIND   | RESTORED  
+----------+-----------
+  Project |        1  
+`, createdAt),
+		},
+		{
+			Title:           "backup restored in json format",
+			Args:            []string{file, "-ojson"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: false,
+			ExpectedMessage: `{"createdAt":"2023-10-01T12:00:00Z","resources":{"Project":1}}
+`,
+		},
+	}
+	cmdTest.ExecuteSuiteTest(t, NewCMD, testSuite)
+}
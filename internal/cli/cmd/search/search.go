@@ -0,0 +1,139 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	persesCMD "github.com/perses/perses/internal/cli/cmd"
+	"github.com/perses/perses/internal/cli/config"
+	"github.com/perses/perses/internal/cli/opt"
+	"github.com/perses/perses/internal/cli/output"
+	"github.com/perses/perses/internal/cli/resource"
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/spf13/cobra"
+)
+
+var columnHeader = []string{"KIND", "PROJECT", "NAME", "DISPLAY NAME", "SCORE"}
+
+type option struct {
+	persesCMD.Option
+	opt.ProjectOption
+	opt.OutputOption
+	writer    io.Writer
+	text      string
+	kind      string
+	limit     int
+	apiClient v1.ClientInterface
+	options   v1.SearchOptions
+}
+
+func (o *option) Complete(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("please specify the text to search")
+	} else if len(args) > 1 {
+		return fmt.Errorf("you cannot have more than one argument for the command 'search'. Use quotes to search several terms")
+	}
+	o.text = args[0]
+	// Complete the output only if it has been set by the user
+	if len(o.Output) > 0 {
+		if outputErr := o.OutputOption.Complete(); outputErr != nil {
+			return outputErr
+		}
+	}
+	if len(o.kind) > 0 {
+		kind, err := resource.GetKind(o.kind)
+		if err != nil {
+			return err
+		}
+		o.options.Kind = kind
+	}
+	// Unlike the other commands, the search is done across all projects unless a project is explicitly given.
+	o.options.Project = o.Project
+	o.options.Limit = o.limit
+
+	apiClient, err := config.Global.GetAPIClient()
+	if err != nil {
+		return err
+	}
+	o.apiClient = apiClient.V1()
+	return nil
+}
+
+func (o *option) Validate() error {
+	if len(o.options.Kind) > 0 && o.options.Kind != modelV1.KindDashboard && o.options.Kind != modelV1.KindVariable && o.options.Kind != modelV1.KindGlobalVariable {
+		return fmt.Errorf("the kind %q cannot be searched, only dashboards, variables and global variables can", o.options.Kind)
+	}
+	if o.limit < 0 {
+		return fmt.Errorf("limit cannot be negative")
+	}
+	return nil
+}
+
+func (o *option) Execute() error {
+	results, err := o.apiClient.Search().Search(o.text, o.options)
+	if err != nil {
+		return err
+	}
+	if len(o.Output) > 0 {
+		return output.Handle(o.writer, o.Output, results)
+	}
+	data := make([][]string, 0, len(results))
+	for _, result := range results {
+		data = append(data, []string{
+			string(result.Kind),
+			result.Project,
+			result.Name,
+			result.DisplayName,
+			strconv.FormatFloat(result.Score, 'f', -1, 64),
+		})
+	}
+	output.HandlerTable(o.writer, columnHeader, data)
+	return nil
+}
+
+func (o *option) SetWriter(writer io.Writer) {
+	o.writer = writer
+}
+
+func NewCMD() *cobra.Command {
+	o := &option{}
+	cmd := &cobra.Command{
+		Use:   "search [TEXT]",
+		Short: "Search the dashboards and the variables containing the given text.",
+		Long: `Search the dashboards and the variables containing every term of the given text, the most relevant first.
+A dashboard is found by its name, its description, the title of its panels, the queries of its panels and the name of its variables.`,
+		Example: `
+# Search the dashboards and the variables about the CPU in all projects.
+percli search cpu
+
+# Search the dashboards containing both terms in a specific project.
+percli search 'node memory' --kind dashboard -p my_project
+
+# Get the 5 most relevant results as a JSON object.
+percli search node_cpu_seconds_total --limit 5 -ojson
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return persesCMD.Run(o, cmd, args)
+		},
+	}
+	opt.AddOutputFlags(cmd, &o.OutputOption)
+	opt.AddProjectFlags(cmd, &o.ProjectOption)
+	cmd.Flags().StringVar(&o.kind, "kind", o.kind, "If present, only the resources of this kind are returned: dashboard, variable or globalvariable.")
+	cmd.Flags().IntVar(&o.limit, "limit", o.limit, "The maximum number of results. The default limit of the API is used when it is not set.")
+	return cmd
+}
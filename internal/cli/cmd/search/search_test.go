@@ -0,0 +1,71 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"testing"
+
+	cmdTest "github.com/perses/perses/internal/cli/test"
+	test "github.com/perses/perses/internal/test"
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	fakeapi "github.com/perses/perses/pkg/client/fake/api"
+	fakev1 "github.com/perses/perses/pkg/client/fake/api/v1"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+func TestSearchCMD(t *testing.T) {
+	testSuite := []cmdTest.Suite{
+		{
+			Title:           "empty args",
+			Args:            []string{},
+			IsErrorExpected: true,
+			ExpectedMessage: "please specify the text to search",
+		},
+		{
+			Title:           "not connected to any API",
+			Args:            []string{"node"},
+			IsErrorExpected: true,
+			ExpectedMessage: "you are not connected to any API",
+		},
+		{
+			Title:           "kind not managed",
+			Args:            []string{"node", "--kind", "whatever"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: "resource \"whatever\" not managed",
+		},
+		{
+			Title:           "kind not searchable",
+			Args:            []string{"node", "--kind", "folder"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: "the kind \"Folder\" cannot be searched, only dashboards, variables and global variables can",
+		},
+		{
+			Title:           "search in json format",
+			Args:            []string{"node", "-ojson"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: false,
+			ExpectedMessage: string(test.JSONMarshalStrict(fakev1.SearchResults("node", v1.SearchOptions{}))) + "\n",
+		},
+		{
+			Title:           "search in a project in json format",
+			Args:            []string{"node", "-p", "perses", "--kind", "dashboard", "-ojson"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: false,
+			ExpectedMessage: string(test.JSONMarshalStrict(fakev1.SearchResults("node", v1.SearchOptions{Project: "perses", Kind: modelV1.KindDashboard}))) + "\n",
+		},
+	}
+	cmdTest.ExecuteSuiteTest(t, NewCMD, testSuite)
+}
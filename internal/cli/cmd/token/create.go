@@ -0,0 +1,123 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"fmt"
+	"io"
+
+	persesCMD "github.com/perses/perses/internal/cli/cmd"
+	"github.com/perses/perses/internal/cli/opt"
+	"github.com/perses/perses/internal/cli/output"
+	"github.com/perses/perses/internal/cli/resource"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/prometheus/common/model"
+	"github.com/spf13/cobra"
+)
+
+type createOption struct {
+	persesCMD.Option
+	opt.OutputOption
+	serviceAccountOption
+	writer  io.Writer
+	ttl     string
+	actions []string
+	scopes  []string
+	request *modelV1.APITokenRequest
+}
+
+func (o *createOption) Complete(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("please specify the name of the token to create")
+	}
+	// Complete the output only if it has been set by the user
+	if len(o.Output) > 0 {
+		if outputErr := o.OutputOption.Complete(); outputErr != nil {
+			return outputErr
+		}
+	}
+	o.request = &modelV1.APITokenRequest{Name: args[0]}
+	if len(o.ttl) > 0 {
+		ttl, err := model.ParseDuration(o.ttl)
+		if err != nil {
+			return err
+		}
+		o.request.TTL = ttl
+	}
+	if len(o.actions) > 0 || len(o.scopes) > 0 {
+		permission := modelV1.Permission{}
+		for _, action := range o.actions {
+			permission.Actions = append(permission.Actions, modelV1.Action(action))
+		}
+		for _, scope := range o.scopes {
+			if scope == string(modelV1.ScopeWildcard) {
+				permission.Scopes = append(permission.Scopes, modelV1.ScopeWildcard)
+				continue
+			}
+			kind, err := resource.GetKind(scope)
+			if err != nil {
+				return err
+			}
+			permission.Scopes = append(permission.Scopes, modelV1.Scope(kind))
+		}
+		o.request.Permissions = []modelV1.Permission{permission}
+	}
+	return o.serviceAccountOption.complete()
+}
+
+func (o *createOption) Validate() error {
+	if (len(o.actions) > 0) != (len(o.scopes) > 0) {
+		return fmt.Errorf("--action and --scope must be used together")
+	}
+	return nil
+}
+
+func (o *createOption) Execute() error {
+	response, err := o.apiClient.Create(o.request)
+	if err != nil {
+		return err
+	}
+	if len(o.Output) > 0 {
+		return output.Handle(o.writer, o.Output, response)
+	}
+	return output.HandleString(o.writer, fmt.Sprintf("token %q has been created for the service account %q, it won't be shown again:\n%s", response.Name, o.serviceAccount, response.Token))
+}
+
+func (o *createOption) SetWriter(writer io.Writer) {
+	o.writer = writer
+}
+
+func newCreateCMD() *cobra.Command {
+	o := &createOption{}
+	cmd := &cobra.Command{
+		Use:   "create [NAME]",
+		Short: "Create an API token for a service account. The token is only shown once.",
+		Example: `
+# Create a token with every permission of the service account, valid as long as the server allows.
+percli token create deploy --service-account ci
+
+# Create a token valid for 30 days, which can only read the dashboards and the datasources.
+percli token create monitoring --service-account ci --ttl 30d --action read --scope dashboard --scope datasource
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return persesCMD.Run(o, cmd, args)
+		},
+	}
+	opt.AddOutputFlags(cmd, &o.OutputOption)
+	addServiceAccountFlags(cmd, &o.serviceAccountOption)
+	cmd.Flags().StringVar(&o.ttl, "ttl", o.ttl, "How long the token can be used, like 30d. The server uses its maximum duration if it is not set.")
+	cmd.Flags().StringSliceVar(&o.actions, "action", o.actions, "If present, the token is restricted to these actions: read, create, update, delete or *.")
+	cmd.Flags().StringSliceVar(&o.scopes, "scope", o.scopes, "If present, the token is restricted to the resources of these kinds, or * for every kind.")
+	return cmd
+}
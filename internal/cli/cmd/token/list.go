@@ -0,0 +1,103 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	persesCMD "github.com/perses/perses/internal/cli/cmd"
+	"github.com/perses/perses/internal/cli/opt"
+	"github.com/perses/perses/internal/cli/output"
+	"github.com/spf13/cobra"
+)
+
+var columnHeader = []string{"NAME", "PERMISSIONS", "CREATED", "EXPIRATION", "LAST USED"}
+
+type listOption struct {
+	persesCMD.Option
+	opt.OutputOption
+	serviceAccountOption
+	writer io.Writer
+}
+
+func (o *listOption) Complete(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("no args are supported by the command 'list'")
+	}
+	// Complete the output only if it has been set by the user
+	if len(o.Output) > 0 {
+		if outputErr := o.OutputOption.Complete(); outputErr != nil {
+			return outputErr
+		}
+	}
+	return o.serviceAccountOption.complete()
+}
+
+func (o *listOption) Validate() error {
+	return nil
+}
+
+func (o *listOption) Execute() error {
+	tokens, err := o.apiClient.List()
+	if err != nil {
+		return err
+	}
+	if len(o.Output) > 0 {
+		return output.Handle(o.writer, o.Output, tokens)
+	}
+	data := make([][]string, 0, len(tokens))
+	for _, token := range tokens {
+		permissions := "all"
+		if len(token.Permissions) > 0 {
+			permissions = "restricted"
+		}
+		lastUsed := "never"
+		if token.LastUsedAt != nil {
+			lastUsed = output.FormatTime(*token.LastUsedAt)
+		}
+		data = append(data, []string{
+			token.Name,
+			permissions,
+			output.FormatTime(token.CreatedAt),
+			token.ExpiresAt.Local().Format(time.DateTime),
+			lastUsed,
+		})
+	}
+	output.HandlerTable(o.writer, columnHeader, data)
+	return nil
+}
+
+func (o *listOption) SetWriter(writer io.Writer) {
+	o.writer = writer
+}
+
+func newListCMD() *cobra.Command {
+	o := &listOption{}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the API tokens of a service account. The tokens themselves are never returned.",
+		Example: `
+# List the tokens of a service account.
+percli token list --service-account ci
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return persesCMD.Run(o, cmd, args)
+		},
+	}
+	opt.AddOutputFlags(cmd, &o.OutputOption)
+	addServiceAccountFlags(cmd, &o.serviceAccountOption)
+	return cmd
+}
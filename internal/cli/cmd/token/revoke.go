@@ -0,0 +1,75 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"fmt"
+	"io"
+
+	persesCMD "github.com/perses/perses/internal/cli/cmd"
+	"github.com/perses/perses/internal/cli/output"
+	"github.com/spf13/cobra"
+)
+
+type revokeOption struct {
+	persesCMD.Option
+	serviceAccountOption
+	writer io.Writer
+	names  []string
+}
+
+func (o *revokeOption) Complete(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("please specify the name of the tokens to revoke. Use 'percli token list' to get them")
+	}
+	o.names = args
+	return o.serviceAccountOption.complete()
+}
+
+func (o *revokeOption) Validate() error {
+	return nil
+}
+
+func (o *revokeOption) Execute() error {
+	for _, name := range o.names {
+		if err := o.apiClient.Revoke(name); err != nil {
+			return err
+		}
+		if outputErr := output.HandleString(o.writer, fmt.Sprintf("token %q of the service account %q has been revoked", name, o.serviceAccount)); outputErr != nil {
+			return outputErr
+		}
+	}
+	return nil
+}
+
+func (o *revokeOption) SetWriter(writer io.Writer) {
+	o.writer = writer
+}
+
+func newRevokeCMD() *cobra.Command {
+	o := &revokeOption{}
+	cmd := &cobra.Command{
+		Use:   "revoke [NAME...]",
+		Short: "Revoke API tokens of a service account. They are rejected by the server right away.",
+		Example: `
+# Revoke a token of a service account.
+percli token revoke deploy --service-account ci
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return persesCMD.Run(o, cmd, args)
+		},
+	}
+	addServiceAccountFlags(cmd, &o.serviceAccountOption)
+	return cmd
+}
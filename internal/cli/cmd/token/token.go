@@ -0,0 +1,57 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"fmt"
+
+	"github.com/perses/perses/internal/cli/config"
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/spf13/cobra"
+)
+
+// serviceAccountOption contains the flag giving the service account owning the tokens.
+type serviceAccountOption struct {
+	serviceAccount string
+	apiClient      v1.APITokenInterface
+}
+
+func (o *serviceAccountOption) complete() error {
+	if len(o.serviceAccount) == 0 {
+		return fmt.Errorf("please specify the service account with the flag --service-account")
+	}
+	apiClient, err := config.Global.GetAPIClient()
+	if err != nil {
+		return err
+	}
+	o.apiClient = apiClient.V1().APIToken(o.serviceAccount)
+	return nil
+}
+
+func addServiceAccountFlags(cmd *cobra.Command, o *serviceAccountOption) {
+	cmd.Flags().StringVar(&o.serviceAccount, "service-account", o.serviceAccount, "Name of the service account owning the tokens.")
+}
+
+func NewCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage the API tokens of the service accounts",
+		Long: `An API token authenticates the requests of a service account, like a script or a CI pipeline.
+It is sent in the header Authorization, and it can be restricted to some of the permissions of the service account.`,
+	}
+	cmd.AddCommand(newCreateCMD())
+	cmd.AddCommand(newListCMD())
+	cmd.AddCommand(newRevokeCMD())
+	return cmd
+}
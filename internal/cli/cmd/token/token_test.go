@@ -0,0 +1,113 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"testing"
+
+	cmdTest "github.com/perses/perses/internal/cli/test"
+	"github.com/perses/perses/internal/test"
+	fakeapi "github.com/perses/perses/pkg/client/fake/api"
+	fakev1 "github.com/perses/perses/pkg/client/fake/api/v1"
+)
+
+func TestTokenCreateCMD(t *testing.T) {
+	testSuite := []cmdTest.Suite{
+		{
+			Title:           "empty args",
+			Args:            []string{"create", "--service-account", "ci"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: "please specify the name of the token to create",
+		},
+		{
+			Title:           "no service account",
+			Args:            []string{"create", "deploy"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: "please specify the service account with the flag --service-account",
+		},
+		{
+			Title:           "action without scope",
+			Args:            []string{"create", "deploy", "--service-account", "ci", "--action", "read"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: "--action and --scope must be used together",
+		},
+		{
+			Title:           "unknown scope",
+			Args:            []string{"create", "deploy", "--service-account", "ci", "--action", "read", "--scope", "unicorn"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: "resource \"unicorn\" not managed",
+		},
+		{
+			Title:           "create a token",
+			Args:            []string{"create", "deploy", "--service-account", "ci", "--ttl", "30d"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: false,
+			ExpectedMessage: `token "deploy" has been created for the service account "ci", it won't be shown again:
+perses_ci.deploy.secret
+`,
+		},
+	}
+	cmdTest.ExecuteSuiteTest(t, NewCMD, testSuite)
+}
+
+func TestTokenListCMD(t *testing.T) {
+	testSuite := []cmdTest.Suite{
+		{
+			Title:           "not connected to any API",
+			Args:            []string{"list", "--service-account", "ci"},
+			IsErrorExpected: true,
+			ExpectedMessage: "you are not connected to any API",
+		},
+		{
+			Title:           "list in json format",
+			Args:            []string{"list", "--service-account", "ci", "-ojson"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: false,
+			ExpectedMessage: string(test.JSONMarshalStrict(fakev1.APITokenList())) + "\n",
+		},
+	}
+	cmdTest.ExecuteSuiteTest(t, NewCMD, testSuite)
+}
+
+func TestTokenRevokeCMD(t *testing.T) {
+	testSuite := []cmdTest.Suite{
+		{
+			Title:           "empty args",
+			Args:            []string{"revoke", "--service-account", "ci"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: "please specify the name of the tokens to revoke. Use 'percli token list' to get them",
+		},
+		{
+			Title:           "revoke a token",
+			Args:            []string{"revoke", "deploy", "--service-account", "ci"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: false,
+			ExpectedMessage: `token "deploy" of the service account "ci" has been revoked
+`,
+		},
+		{
+			Title:           "revoke an unknown token",
+			Args:            []string{"revoke", "unknown", "--service-account", "ci"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: "something wrong happened with the request to the API.  Message: document not found StatusCode: 404",
+		},
+	}
+	cmdTest.ExecuteSuiteTest(t, NewCMD, testSuite)
+}
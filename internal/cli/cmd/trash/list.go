@@ -0,0 +1,110 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trash
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	persesCMD "github.com/perses/perses/internal/cli/cmd"
+	"github.com/perses/perses/internal/cli/config"
+	"github.com/perses/perses/internal/cli/opt"
+	"github.com/perses/perses/internal/cli/output"
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/spf13/cobra"
+)
+
+var columnHeader = []string{"NAME", "KIND", "PROJECT", "RESOURCE", "DELETED", "EXPIRATION"}
+
+type listOption struct {
+	persesCMD.Option
+	opt.OutputOption
+	filterOption
+	writer    io.Writer
+	apiClient v1.ClientInterface
+}
+
+func (o *listOption) Complete(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("no args are supported by the command 'list'")
+	}
+	// Complete the output only if it has been set by the user
+	if len(o.Output) > 0 {
+		if outputErr := o.OutputOption.Complete(); outputErr != nil {
+			return outputErr
+		}
+	}
+	if err := o.filterOption.complete(); err != nil {
+		return err
+	}
+	apiClient, err := config.Global.GetAPIClient()
+	if err != nil {
+		return err
+	}
+	o.apiClient = apiClient.V1()
+	return nil
+}
+
+func (o *listOption) Validate() error {
+	return nil
+}
+
+func (o *listOption) Execute() error {
+	entries, err := o.apiClient.Trash().List(o.options)
+	if err != nil {
+		return err
+	}
+	if len(o.Output) > 0 {
+		return output.Handle(o.writer, o.Output, entries)
+	}
+	data := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		data = append(data, []string{
+			entry.Metadata.Name,
+			string(entry.Spec.Resource.Kind),
+			entry.Spec.Resource.Project,
+			entry.Spec.Resource.Name,
+			output.FormatTime(entry.Spec.DeletedAt),
+			entry.Spec.ExpiresAt.Local().Format(time.DateTime),
+		})
+	}
+	output.HandlerTable(o.writer, columnHeader, data)
+	return nil
+}
+
+func (o *listOption) SetWriter(writer io.Writer) {
+	o.writer = writer
+}
+
+func newListCMD() *cobra.Command {
+	o := &listOption{}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the entries of the trash, the most recent first.",
+		Example: `
+# List the dashboards and the projects deleted.
+percli trash list
+
+# List the dashboards deleted in a specific project.
+percli trash list --kind dashboard -p my_project
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return persesCMD.Run(o, cmd, args)
+		},
+	}
+	opt.AddOutputFlags(cmd, &o.OutputOption)
+	addFilterFlags(cmd, &o.filterOption)
+	return cmd
+}
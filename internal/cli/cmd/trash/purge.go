@@ -0,0 +1,118 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trash
+
+import (
+	"fmt"
+	"io"
+
+	persesCMD "github.com/perses/perses/internal/cli/cmd"
+	"github.com/perses/perses/internal/cli/config"
+	"github.com/perses/perses/internal/cli/output"
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/spf13/cobra"
+)
+
+type purgeOption struct {
+	persesCMD.Option
+	filterOption
+	writer    io.Writer
+	all       bool
+	names     []string
+	apiClient v1.ClientInterface
+}
+
+func (o *purgeOption) Complete(args []string) error {
+	o.names = args
+	if err := o.filterOption.complete(); err != nil {
+		return err
+	}
+	apiClient, err := config.Global.GetAPIClient()
+	if err != nil {
+		return err
+	}
+	o.apiClient = apiClient.V1()
+	return nil
+}
+
+func (o *purgeOption) Validate() error {
+	if o.all && len(o.names) > 0 {
+		return fmt.Errorf("you cannot give the name of the entries to purge when using the flag --all")
+	}
+	if !o.all && len(o.names) == 0 {
+		return fmt.Errorf("please specify the name of the entries to purge, or use the flag --all")
+	}
+	if !o.all && (len(o.options.Project) > 0 || len(o.options.Kind) > 0) {
+		return fmt.Errorf("the flags --project and --kind can only be used with the flag --all")
+	}
+	return nil
+}
+
+func (o *purgeOption) Execute() error {
+	if o.all {
+		entries, err := o.apiClient.Trash().PurgeAll(o.options)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if outputErr := o.printPurged(entry.Metadata.Name); outputErr != nil {
+				return outputErr
+			}
+		}
+		return nil
+	}
+	for _, name := range o.names {
+		if err := o.apiClient.Trash().Purge(name); err != nil {
+			return err
+		}
+		if outputErr := o.printPurged(name); outputErr != nil {
+			return outputErr
+		}
+	}
+	return nil
+}
+
+func (o *purgeOption) printPurged(name string) error {
+	return output.HandleString(o.writer, fmt.Sprintf("trash entry %q has been purged", name))
+}
+
+func (o *purgeOption) SetWriter(writer io.Writer) {
+	o.writer = writer
+}
+
+func newPurgeCMD() *cobra.Command {
+	o := &purgeOption{}
+	cmd := &cobra.Command{
+		Use:   "purge [NAME...]",
+		Short: "Remove for good the given entries of the trash.",
+		Long: `Remove for good the given entries of the trash. The resources they contain cannot be restored anymore.
+The entries are removed automatically once their retention period is over.`,
+		Example: `
+# Purge an entry of the trash.
+percli trash purge dashboard-s2b0k1
+
+# Purge every project deleted.
+percli trash purge --all --kind project
+
+# Empty the trash.
+percli trash purge --all
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return persesCMD.Run(o, cmd, args)
+		},
+	}
+	addFilterFlags(cmd, &o.filterOption)
+	cmd.Flags().BoolVar(&o.all, "all", o.all, "If present, every entry of the trash is purged, or only the ones matching the flags --project and --kind.")
+	return cmd
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trash
+
+import (
+	"fmt"
+	"io"
+
+	persesCMD "github.com/perses/perses/internal/cli/cmd"
+	"github.com/perses/perses/internal/cli/config"
+	"github.com/perses/perses/internal/cli/resource"
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/spf13/cobra"
+)
+
+type restoreOption struct {
+	persesCMD.Option
+	writer    io.Writer
+	names     []string
+	apiClient v1.ClientInterface
+}
+
+func (o *restoreOption) Complete(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("please specify the name of the entries to restore. Use 'percli trash list' to get them")
+	}
+	o.names = args
+	apiClient, err := config.Global.GetAPIClient()
+	if err != nil {
+		return err
+	}
+	o.apiClient = apiClient.V1()
+	return nil
+}
+
+func (o *restoreOption) Validate() error {
+	return nil
+}
+
+func (o *restoreOption) Execute() error {
+	for _, name := range o.names {
+		entry, err := o.apiClient.Trash().Get(name)
+		if err != nil {
+			return err
+		}
+		if restoreErr := o.apiClient.Trash().Restore(name); restoreErr != nil {
+			return restoreErr
+		}
+		res := entry.Spec.Resource
+		if outputErr := resource.HandleSuccessMessage(o.writer, res.Kind, res.Project, fmt.Sprintf("object %q %q has been restored", res.Kind, res.Name)); outputErr != nil {
+			return outputErr
+		}
+	}
+	return nil
+}
+
+func (o *restoreOption) SetWriter(writer io.Writer) {
+	o.writer = writer
+}
+
+func newRestoreCMD() *cobra.Command {
+	o := &restoreOption{}
+	cmd := &cobra.Command{
+		Use:   "restore [NAME...]",
+		Short: "Restore the dashboards or the projects contained in the given entries of the trash.",
+		Long: `Restore the dashboards or the projects contained in the given entries of the trash.
+A project is restored with everything it contained. A dashboard can only be restored once its project exists.`,
+		Example: `
+# Restore a dashboard deleted.
+percli trash restore dashboard-s2b0k1
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return persesCMD.Run(o, cmd, args)
+		},
+	}
+	return cmd
+}
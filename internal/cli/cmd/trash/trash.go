@@ -0,0 +1,64 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trash
+
+import (
+	"fmt"
+
+	"github.com/perses/perses/internal/cli/resource"
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/spf13/cobra"
+)
+
+// filterOption contains the flags used to select the entries of the trash.
+type filterOption struct {
+	project string
+	kind    string
+	options v1.TrashOptions
+}
+
+func (o *filterOption) complete() error {
+	if len(o.kind) > 0 {
+		kind, err := resource.GetKind(o.kind)
+		if err != nil {
+			return err
+		}
+		if kind != modelV1.KindDashboard && kind != modelV1.KindProject {
+			return fmt.Errorf("the trash only contains dashboards and projects, not %q", kind)
+		}
+		o.options.Kind = kind
+	}
+	// Unlike the other commands, the entries of every project are used unless a project is explicitly given.
+	o.options.Project = o.project
+	return nil
+}
+
+func addFilterFlags(cmd *cobra.Command, o *filterOption) {
+	cmd.Flags().StringVarP(&o.project, "project", "p", o.project, "If present, only the entries of this project are used.")
+	cmd.Flags().StringVar(&o.kind, "kind", o.kind, "If present, only the entries of the resources of this kind are used: dashboard or project.")
+}
+
+func NewCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "Manage the dashboards and the projects deleted",
+		Long: `When a dashboard or a project is deleted, it is moved to the trash, along with everything it contains.
+It stays there until it is restored, purged or until its retention period is over.`,
+	}
+	cmd.AddCommand(newListCMD())
+	cmd.AddCommand(newRestoreCMD())
+	cmd.AddCommand(newPurgeCMD())
+	return cmd
+}
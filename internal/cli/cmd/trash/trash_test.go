@@ -0,0 +1,129 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trash
+
+import (
+	"testing"
+
+	cmdTest "github.com/perses/perses/internal/cli/test"
+	"github.com/perses/perses/internal/test"
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	fakeapi "github.com/perses/perses/pkg/client/fake/api"
+	fakev1 "github.com/perses/perses/pkg/client/fake/api/v1"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+func TestTrashListCMD(t *testing.T) {
+	testSuite := []cmdTest.Suite{
+		{
+			Title:           "not connected to any API",
+			Args:            []string{"list"},
+			IsErrorExpected: true,
+			ExpectedMessage: "you are not connected to any API",
+		},
+		{
+			Title:           "kind not in the trash",
+			Args:            []string{"list", "--kind", "folder"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: "the trash only contains dashboards and projects, not \"Folder\"",
+		},
+		{
+			Title:           "list in json format",
+			Args:            []string{"list", "-ojson"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: false,
+			ExpectedMessage: string(test.JSONMarshalStrict(fakev1.TrashList(v1.TrashOptions{}))) + "\n",
+		},
+		{
+			Title:           "list the dashboards of a project in json format",
+			Args:            []string{"list", "-p", "perses", "--kind", "dashboard", "-ojson"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: false,
+			ExpectedMessage: string(test.JSONMarshalStrict(fakev1.TrashList(v1.TrashOptions{Project: "perses", Kind: modelV1.KindDashboard}))) + "\n",
+		},
+	}
+	cmdTest.ExecuteSuiteTest(t, NewCMD, testSuite)
+}
+
+func TestTrashRestoreCMD(t *testing.T) {
+	testSuite := []cmdTest.Suite{
+		{
+			Title:           "empty args",
+			Args:            []string{"restore"},
+			IsErrorExpected: true,
+			ExpectedMessage: "please specify the name of the entries to restore. Use 'percli trash list' to get them",
+		},
+		{
+			Title:           "restore a dashboard and a project",
+			Args:            []string{"restore", "dashboard-s2b0k1", "project-s2b0j7"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: false,
+			ExpectedMessage: `object "Dashboard" "Demo" has been restored in the project "perses"
+object "Project" "Amadeus" has been restored
+`,
+		},
+		{
+			Title:           "restore an unknown entry",
+			Args:            []string{"restore", "dashboard-unknown"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: "something wrong happened with the request to the API.  Message: document not found StatusCode: 404",
+		},
+	}
+	cmdTest.ExecuteSuiteTest(t, NewCMD, testSuite)
+}
+
+func TestTrashPurgeCMD(t *testing.T) {
+	testSuite := []cmdTest.Suite{
+		{
+			Title:           "neither names nor all",
+			Args:            []string{"purge"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: "please specify the name of the entries to purge, or use the flag --all",
+		},
+		{
+			Title:           "names and all",
+			Args:            []string{"purge", "dashboard-s2b0k1", "--all"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: "you cannot give the name of the entries to purge when using the flag --all",
+		},
+		{
+			Title:           "filter without all",
+			Args:            []string{"purge", "dashboard-s2b0k1", "--kind", "dashboard"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: true,
+			ExpectedMessage: "the flags --project and --kind can only be used with the flag --all",
+		},
+		{
+			Title:           "purge an entry",
+			Args:            []string{"purge", "dashboard-s2b0k1"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: false,
+			ExpectedMessage: `trash entry "dashboard-s2b0k1" has been purged
+`,
+		},
+		{
+			Title:           "purge the projects",
+			Args:            []string{"purge", "--all", "--kind", "project"},
+			APIClient:       fakeapi.New(),
+			IsErrorExpected: false,
+			ExpectedMessage: `trash entry "project-s2b0j7" has been purged
+`,
+		},
+	}
+	cmdTest.ExecuteSuiteTest(t, NewCMD, testSuite)
+}
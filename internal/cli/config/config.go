@@ -19,7 +19,9 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"time"
 
+	"github.com/golang-jwt/jwt"
 	"github.com/perses/perses/pkg/client/api"
 	"github.com/perses/perses/pkg/client/perseshttp"
 	"github.com/sirupsen/logrus"
@@ -28,6 +30,8 @@ import (
 const (
 	pathConfig     = ".perses"
 	configFileName = "config.json"
+	// refreshMargin is how long before its expiration the access token is refreshed, so it doesn't expire during the command.
+	refreshMargin = time.Minute
 )
 
 var Global *Config
@@ -38,20 +42,22 @@ func Init(configPath string) {
 	if err != nil {
 		logrus.WithError(err).Debug("unable to read the config")
 		Global = &Config{}
-	} else {
-		err = Global.init()
-		if err != nil {
-			logrus.WithError(err).Errorf("unable to initialize the CLI from the config")
-		}
+		Global.filePath = configPath
+		return
 	}
 	Global.filePath = configPath
+	if err = Global.init(); err != nil {
+		logrus.WithError(err).Errorf("unable to initialize the CLI from the config")
+	}
 }
 
 type Config struct {
 	RestClientConfig perseshttp.RestConfigClient `json:"rest_client_config"`
-	Project          string                      `json:"project"`
-	filePath         string
-	apiClient        api.ClientInterface
+	// RefreshToken is used to get a new access token, stored in RestClientConfig.Token, once it has expired.
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Project      string `json:"project"`
+	filePath     string
+	apiClient    api.ClientInterface
 }
 
 func (c *Config) init() error {
@@ -60,9 +66,36 @@ func (c *Config) init() error {
 		return err
 	}
 	c.apiClient = api.NewWithClient(restClient)
+	if len(c.RefreshToken) > 0 && isExpired(c.RestClientConfig.Token) {
+		c.refreshAccessToken(restClient)
+	}
 	return nil
 }
 
+// refreshAccessToken gets a new access token and saves it in the configuration file.
+// If it fails, the commands are run with the expired token, so the API returns why they are rejected.
+func (c *Config) refreshAccessToken(restClient *perseshttp.RESTClient) {
+	response, err := c.apiClient.Auth().Refresh(c.RefreshToken)
+	if err != nil {
+		logrus.WithError(err).Warning("unable to refresh the access token, you may have to log in again")
+		return
+	}
+	c.RestClientConfig.Token = response.AccessToken
+	restClient.SetToken(response.AccessToken)
+	if writeErr := Write(&Config{RestClientConfig: c.RestClientConfig, RefreshToken: c.RefreshToken}); writeErr != nil {
+		logrus.WithError(writeErr).Warning("unable to save the access token refreshed")
+	}
+}
+
+// isExpired returns true if the access token expires in less than refreshMargin. Its signature is not verified, it is the job of the API.
+func isExpired(token string) bool {
+	claims := &jwt.StandardClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, claims); err != nil {
+		return true
+	}
+	return !claims.VerifyExpiresAt(time.Now().Add(refreshMargin).Unix(), true)
+}
+
 func (c *Config) GetAPIClient() (api.ClientInterface, error) {
 	if c.apiClient != nil {
 		return c.apiClient, nil
@@ -136,6 +169,9 @@ func Write(config *Config) error {
 			previousConf.RestClientConfig.InsecureTLS = config.RestClientConfig.InsecureTLS
 			if len(config.RestClientConfig.URL) > 0 {
 				previousConf.RestClientConfig.URL = config.RestClientConfig.URL
+				// the tokens are the ones of the session opened on the server, so they are replaced as well.
+				previousConf.RestClientConfig.Token = config.RestClientConfig.Token
+				previousConf.RefreshToken = config.RefreshToken
 			}
 			if len(config.Project) > 0 {
 				previousConf.Project = config.Project
@@ -15,6 +15,8 @@ package opt
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/perses/perses/internal/cli/config"
 	"github.com/perses/perses/internal/cli/output"
@@ -66,3 +68,30 @@ func (o *ProjectOption) Complete() error {
 func AddProjectFlags(cmd *cobra.Command, o *ProjectOption) {
 	cmd.Flags().StringVarP(&o.Project, "project", "p", o.Project, "If present, the project scope for this CLI request")
 }
+
+type PassphraseOption struct {
+	Passphrase     string
+	PassphraseFile string
+}
+
+// Complete will fill the attribute PassphraseOption.Passphrase with the content of the file when it is used.
+func (o *PassphraseOption) Complete() error {
+	if len(o.PassphraseFile) == 0 {
+		return nil
+	}
+	data, err := os.ReadFile(o.PassphraseFile)
+	if err != nil {
+		return err
+	}
+	o.Passphrase = strings.TrimSpace(string(data))
+	if len(o.Passphrase) == 0 {
+		return fmt.Errorf("the file %q doesn't contain any passphrase", o.PassphraseFile)
+	}
+	return nil
+}
+
+func AddPassphraseFlags(cmd *cobra.Command, o *PassphraseOption) {
+	cmd.Flags().StringVar(&o.Passphrase, "passphrase", o.Passphrase, "Passphrase used to encrypt the secrets of the backup. Prefer --passphrase-file, so it doesn't appear in the history of the shell.")
+	cmd.Flags().StringVar(&o.PassphraseFile, "passphrase-file", o.PassphraseFile, "Path to the file containing the passphrase used to encrypt the secrets of the backup.")
+	cmd.MarkFlagsMutuallyExclusive("passphrase", "passphrase-file")
+}
@@ -61,6 +61,20 @@ var resources = []resource{
 			"globalDatasources",
 		},
 	},
+	{
+		kind:      modelV1.KindGlobalRole,
+		shortTerm: "gr",
+		aliases: []string{
+			"globalRoles",
+		},
+	},
+	{
+		kind:      modelV1.KindGlobalRoleBinding,
+		shortTerm: "grb",
+		aliases: []string{
+			"globalRoleBindings",
+		},
+	},
 	{
 		kind:      modelV1.KindGlobalSecret,
 		shortTerm: "gs",
@@ -79,12 +93,32 @@ var resources = []resource{
 			"gvs",
 		},
 	},
+	{
+		kind:      modelV1.KindGlobalWebhook,
+		shortTerm: "gwh",
+		aliases: []string{
+			"globalWebhooks",
+		},
+	},
 	{
 		kind: modelV1.KindProject,
 		aliases: []string{
 			"projects",
 		},
 	},
+	{
+		kind: modelV1.KindRole,
+		aliases: []string{
+			"roles",
+		},
+	},
+	{
+		kind:      modelV1.KindRoleBinding,
+		shortTerm: "rb",
+		aliases: []string{
+			"roleBindings",
+		},
+	},
 	{
 		kind: modelV1.KindSecret,
 		aliases: []string{
@@ -92,6 +126,19 @@ var resources = []resource{
 			"scrt",
 		},
 	},
+	{
+		kind:      modelV1.KindServiceAccount,
+		shortTerm: "sa",
+		aliases: []string{
+			"serviceAccounts",
+		},
+	},
+	{
+		kind: modelV1.KindUser,
+		aliases: []string{
+			"users",
+		},
+	},
 	{
 		kind:      modelV1.KindVariable,
 		shortTerm: "var",
@@ -100,6 +147,13 @@ var resources = []resource{
 			"vars",
 		},
 	},
+	{
+		kind:      modelV1.KindWebhook,
+		shortTerm: "wh",
+		aliases: []string{
+			"webhooks",
+		},
+	},
 }
 
 func HandleSuccessMessage(writer io.Writer, kind modelV1.Kind, project string, globalResourceMessage string) error {
@@ -116,7 +170,7 @@ func HandleSuccessMessage(writer io.Writer, kind modelV1.Kind, project string, g
 // Returns false otherwise.
 func IsGlobal(kind modelV1.Kind) bool {
 	switch kind {
-	case modelV1.KindProject, modelV1.KindGlobalDatasource, modelV1.KindGlobalSecret, modelV1.KindGlobalVariable:
+	case modelV1.KindProject, modelV1.KindGlobalDatasource, modelV1.KindGlobalRole, modelV1.KindGlobalRoleBinding, modelV1.KindGlobalSecret, modelV1.KindGlobalVariable, modelV1.KindGlobalWebhook, modelV1.KindServiceAccount, modelV1.KindUser:
 		return true
 	default:
 		return false
@@ -0,0 +1,68 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"github.com/perses/perses/internal/cli/output"
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type globalRoleBinding struct {
+	Service
+	apiClient v1.GlobalRoleBindingInterface
+}
+
+func (r *globalRoleBinding) CreateResource(entity modelAPI.Entity) (modelAPI.Entity, error) {
+	return r.apiClient.Create(entity.(*modelV1.GlobalRoleBinding))
+}
+
+func (r *globalRoleBinding) UpdateResource(entity modelAPI.Entity) (modelAPI.Entity, error) {
+	return r.apiClient.Update(entity.(*modelV1.GlobalRoleBinding))
+}
+
+func (r *globalRoleBinding) ListResource(prefix string, labelSelector string) ([]modelAPI.Entity, error) {
+	return convertToEntityIfNoError(list(r.apiClient.ListPage(prefix, v1.ListOptions{LabelSelector: labelSelector})))
+}
+
+func (r *globalRoleBinding) GetResource(name string) (modelAPI.Entity, error) {
+	return r.apiClient.Get(name)
+}
+
+func (r *globalRoleBinding) DeleteResource(name string) error {
+	return r.apiClient.Delete(name)
+}
+
+func (r *globalRoleBinding) BuildMatrix(hits []modelAPI.Entity) [][]string {
+	var data [][]string
+	for _, hit := range hits {
+		entity := hit.(*modelV1.GlobalRoleBinding)
+		line := []string{
+			entity.Metadata.Name,
+			entity.Spec.Role,
+			output.FormatTime(entity.Metadata.UpdatedAt),
+		}
+		data = append(data, line)
+	}
+	return data
+}
+
+func (r *globalRoleBinding) GetColumHeader() []string {
+	return []string{
+		"NAME",
+		"ROLE",
+		"AGE",
+	}
+}
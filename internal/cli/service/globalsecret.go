@@ -33,8 +33,8 @@ func (d *globalSecret) UpdateResource(entity modelAPI.Entity) (modelAPI.Entity,
 	return d.apiClient.Update(entity.(*modelV1.GlobalSecret))
 }
 
-func (d *globalSecret) ListResource(prefix string) ([]modelAPI.Entity, error) {
-	return convertToEntityIfNoError(d.apiClient.List(prefix))
+func (d *globalSecret) ListResource(prefix string, labelSelector string) ([]modelAPI.Entity, error) {
+	return convertToEntityIfNoError(list(d.apiClient.ListPage(prefix, v1.ListOptions{LabelSelector: labelSelector})))
 }
 
 func (d *globalSecret) GetResource(name string) (modelAPI.Entity, error) {
@@ -0,0 +1,68 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"github.com/perses/perses/internal/cli/output"
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type globalWebhook struct {
+	Service
+	apiClient v1.GlobalWebhookInterface
+}
+
+func (w *globalWebhook) CreateResource(entity modelAPI.Entity) (modelAPI.Entity, error) {
+	return w.apiClient.Create(entity.(*modelV1.GlobalWebhook))
+}
+
+func (w *globalWebhook) UpdateResource(entity modelAPI.Entity) (modelAPI.Entity, error) {
+	return w.apiClient.Update(entity.(*modelV1.GlobalWebhook))
+}
+
+func (w *globalWebhook) ListResource(prefix string, labelSelector string) ([]modelAPI.Entity, error) {
+	return convertToEntityIfNoError(list(w.apiClient.ListPage(prefix, v1.ListOptions{LabelSelector: labelSelector})))
+}
+
+func (w *globalWebhook) GetResource(name string) (modelAPI.Entity, error) {
+	return w.apiClient.Get(name)
+}
+
+func (w *globalWebhook) DeleteResource(name string) error {
+	return w.apiClient.Delete(name)
+}
+
+func (w *globalWebhook) BuildMatrix(hits []modelAPI.Entity) [][]string {
+	var data [][]string
+	for _, hit := range hits {
+		entity := hit.(*modelV1.GlobalWebhook)
+		line := []string{
+			entity.Metadata.Name,
+			entity.Spec.URL,
+			output.FormatTime(entity.Metadata.UpdatedAt),
+		}
+		data = append(data, line)
+	}
+	return data
+}
+
+func (w *globalWebhook) GetColumHeader() []string {
+	return []string{
+		"NAME",
+		"URL",
+		"AGE",
+	}
+}
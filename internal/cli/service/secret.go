@@ -33,8 +33,8 @@ func (d *secret) UpdateResource(entity modelAPI.Entity) (modelAPI.Entity, error)
 	return d.apiClient.Update(entity.(*modelV1.Secret))
 }
 
-func (d *secret) ListResource(prefix string) ([]modelAPI.Entity, error) {
-	return convertToEntityIfNoError(d.apiClient.List(prefix))
+func (d *secret) ListResource(prefix string, labelSelector string) ([]modelAPI.Entity, error) {
+	return convertToEntityIfNoError(list(d.apiClient.ListPage(prefix, v1.ListOptions{LabelSelector: labelSelector})))
 }
 
 func (d *secret) GetResource(name string) (modelAPI.Entity, error) {
@@ -32,10 +32,17 @@ func convertToEntityIfNoError[T modelAPI.Entity](entities []T, err error) ([]mod
 	return result, nil
 }
 
+// list drops the token of the next page returned by the method ListPage of the clients, as the whole list is requested.
+func list[T modelAPI.Entity](entities []T, _ string, err error) ([]T, error) {
+	return entities, err
+}
+
 type Service interface {
 	CreateResource(entity modelAPI.Entity) (modelAPI.Entity, error)
 	UpdateResource(entity modelAPI.Entity) (modelAPI.Entity, error)
-	ListResource(prefix string) ([]modelAPI.Entity, error)
+	// ListResource returns the resources whose name starts with the prefix and whose labels match the label selector.
+	// Both can be empty to get every resource.
+	ListResource(prefix string, labelSelector string) ([]modelAPI.Entity, error)
 	GetResource(name string) (modelAPI.Entity, error)
 	DeleteResource(name string) error
 	BuildMatrix(hits []modelAPI.Entity) [][]string
@@ -60,6 +67,14 @@ func New(kind modelV1.Kind, projectName string, apiClient api.ClientInterface) (
 		return &globalDatasource{
 			apiClient: apiClient.V1().GlobalDatasource(),
 		}, nil
+	case modelV1.KindGlobalRole:
+		return &globalRole{
+			apiClient: apiClient.V1().GlobalRole(),
+		}, nil
+	case modelV1.KindGlobalRoleBinding:
+		return &globalRoleBinding{
+			apiClient: apiClient.V1().GlobalRoleBinding(),
+		}, nil
 	case modelV1.KindGlobalSecret:
 		return &globalSecret{
 			apiClient: apiClient.V1().GlobalSecret(),
@@ -68,18 +83,42 @@ func New(kind modelV1.Kind, projectName string, apiClient api.ClientInterface) (
 		return &globalVariable{
 			apiClient: apiClient.V1().GlobalVariable(),
 		}, nil
+	case modelV1.KindGlobalWebhook:
+		return &globalWebhook{
+			apiClient: apiClient.V1().GlobalWebhook(),
+		}, nil
 	case modelV1.KindProject:
 		return &project{
 			apiClient: apiClient.V1().Project(),
 		}, nil
+	case modelV1.KindRole:
+		return &role{
+			apiClient: apiClient.V1().Role(projectName),
+		}, nil
+	case modelV1.KindRoleBinding:
+		return &roleBinding{
+			apiClient: apiClient.V1().RoleBinding(projectName),
+		}, nil
 	case modelV1.KindSecret:
 		return &secret{
 			apiClient: apiClient.V1().Secret(projectName),
 		}, nil
+	case modelV1.KindServiceAccount:
+		return &serviceAccount{
+			apiClient: apiClient.V1().ServiceAccount(),
+		}, nil
+	case modelV1.KindUser:
+		return &user{
+			apiClient: apiClient.V1().User(),
+		}, nil
 	case modelV1.KindVariable:
 		return &variable{
 			apiClient: apiClient.V1().Variable(projectName),
 		}, nil
+	case modelV1.KindWebhook:
+		return &webhook{
+			apiClient: apiClient.V1().Webhook(projectName),
+		}, nil
 	default:
 		return nil, fmt.Errorf("resource %q not supported by the command", kind)
 	}
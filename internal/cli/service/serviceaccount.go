@@ -0,0 +1,70 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"strconv"
+
+	"github.com/perses/perses/internal/cli/output"
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type serviceAccount struct {
+	Service
+	apiClient v1.ServiceAccountInterface
+}
+
+func (s *serviceAccount) CreateResource(entity modelAPI.Entity) (modelAPI.Entity, error) {
+	return s.apiClient.Create(entity.(*modelV1.ServiceAccount))
+}
+
+func (s *serviceAccount) UpdateResource(entity modelAPI.Entity) (modelAPI.Entity, error) {
+	return s.apiClient.Update(entity.(*modelV1.ServiceAccount))
+}
+
+func (s *serviceAccount) ListResource(prefix string, labelSelector string) ([]modelAPI.Entity, error) {
+	return convertToEntityIfNoError(list(s.apiClient.ListPage(prefix, v1.ListOptions{LabelSelector: labelSelector})))
+}
+
+func (s *serviceAccount) GetResource(name string) (modelAPI.Entity, error) {
+	return s.apiClient.Get(name)
+}
+
+func (s *serviceAccount) DeleteResource(name string) error {
+	return s.apiClient.Delete(name)
+}
+
+func (s *serviceAccount) BuildMatrix(hits []modelAPI.Entity) [][]string {
+	var data [][]string
+	for _, hit := range hits {
+		entity := hit.(*modelV1.ServiceAccount)
+		line := []string{
+			entity.Metadata.Name,
+			strconv.Itoa(len(entity.Spec.Tokens)),
+			output.FormatTime(entity.Metadata.UpdatedAt),
+		}
+		data = append(data, line)
+	}
+	return data
+}
+
+func (s *serviceAccount) GetColumHeader() []string {
+	return []string{
+		"NAME",
+		"TOKENS",
+		"AGE",
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"io"
+
+	"github.com/perses/perses/pkg/client/perseshttp"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// HeaderBackupPassphrase is the header of the request containing the passphrase used to encrypt or to decrypt the secrets of a backup.
+const HeaderBackupPassphrase = "X-Backup-Passphrase"
+
+type AdminInterface interface {
+	// Export returns the archive containing every resource of the server, as it is received. It must be closed by the caller.
+	// If the passphrase is not empty, the secrets are encrypted with it, so the archive can be restored on a server using another encryption key.
+	Export(passphrase string) (io.ReadCloser, error)
+	// Import restores the resources of the archive. The passphrase is required if the archive has been exported with one.
+	Import(archive io.Reader, passphrase string) (*v1.BackupSummary, error)
+}
+
+type admin struct {
+	AdminInterface
+	client *perseshttp.RESTClient
+}
+
+func newAdmin(client *perseshttp.RESTClient) AdminInterface {
+	return &admin{client: client}
+}
+
+func (c *admin) Export(passphrase string) (io.ReadCloser, error) {
+	request := c.client.Get().
+		APIVersion("").
+		Resource("admin/export")
+	if len(passphrase) > 0 {
+		request.Header(HeaderBackupPassphrase, passphrase)
+	}
+	return request.Stream()
+}
+
+func (c *admin) Import(archive io.Reader, passphrase string) (*v1.BackupSummary, error) {
+	result := &v1.BackupSummary{}
+	request := c.client.Post().
+		APIVersion("").
+		Resource("admin/import").
+		RawBody(archive, "application/gzip")
+	if len(passphrase) > 0 {
+		request.Header(HeaderBackupPassphrase, passphrase)
+	}
+	err := request.Do().Object(result)
+	return result, err
+}
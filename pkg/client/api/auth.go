@@ -0,0 +1,97 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/perses/perses/pkg/client/perseshttp"
+	"github.com/perses/perses/pkg/model/api"
+)
+
+type AuthInterface interface {
+	// Login returns the access token and the refresh token of the user.
+	Login(login string, password string) (*api.AuthResponse, error)
+	// Refresh returns a new access token for the user of the refresh token.
+	Refresh(refreshToken string) (*api.AuthResponse, error)
+	// Providers returns the identity providers the users can log in with.
+	Providers() ([]api.AuthProvider, error)
+	// DeviceCode starts the device authorization flow with the provider.
+	DeviceCode(kind api.AuthProviderKind, slugID string) (*api.DeviceCodeResponse, error)
+	// DeviceAccessToken returns the tokens of the user who approved the device. While the user hasn't approved it yet,
+	// it returns a perseshttp.RequestError with the message api.DeviceAuthorizationPending or api.DeviceSlowDown.
+	DeviceAccessToken(kind api.AuthProviderKind, slugID string, deviceCode string) (*api.AuthResponse, error)
+}
+
+type auth struct {
+	AuthInterface
+	client *perseshttp.RESTClient
+}
+
+func newAuth(client *perseshttp.RESTClient) AuthInterface {
+	return &auth{client: client}
+}
+
+func (c *auth) Login(login string, password string) (*api.AuthResponse, error) {
+	result := &api.AuthResponse{}
+	err := c.client.Post().
+		APIVersion("").
+		Resource("auth/login").
+		Body(&api.Auth{Login: login, Password: password}).
+		Do().
+		Object(result)
+	return result, err
+}
+
+func (c *auth) Refresh(refreshToken string) (*api.AuthResponse, error) {
+	result := &api.AuthResponse{}
+	err := c.client.Post().
+		APIVersion("").
+		Resource("auth/refresh").
+		Body(&api.RefreshRequest{RefreshToken: refreshToken}).
+		Do().
+		Object(result)
+	return result, err
+}
+
+func (c *auth) Providers() ([]api.AuthProvider, error) {
+	var result []api.AuthProvider
+	err := c.client.Get().
+		APIVersion("").
+		Resource("auth/providers").
+		Do().
+		Object(&result)
+	return result, err
+}
+
+func (c *auth) DeviceCode(kind api.AuthProviderKind, slugID string) (*api.DeviceCodeResponse, error) {
+	result := &api.DeviceCodeResponse{}
+	err := c.client.Post().
+		APIVersion("").
+		Resource(fmt.Sprintf("auth/providers/%s/%s/device/code", kind, slugID)).
+		Do().
+		Object(result)
+	return result, err
+}
+
+func (c *auth) DeviceAccessToken(kind api.AuthProviderKind, slugID string, deviceCode string) (*api.AuthResponse, error) {
+	result := &api.AuthResponse{}
+	err := c.client.Post().
+		APIVersion("").
+		Resource(fmt.Sprintf("auth/providers/%s/%s/device/token", kind, slugID)).
+		Body(&api.DeviceAccessTokenRequest{DeviceCode: deviceCode}).
+		Do().
+		Object(result)
+	return result, err
+}
@@ -23,6 +23,8 @@ import (
 type ClientInterface interface {
 	RESTClient() *perseshttp.RESTClient
 	V1() v1.ClientInterface
+	Admin() AdminInterface
+	Auth() AuthInterface
 	Migrate(body *api.Migrate) (*modelV1.Dashboard, error)
 	Validate() ValidateInterface
 }
@@ -46,6 +48,14 @@ func (c *client) V1() v1.ClientInterface {
 	return v1.NewWithClient(c.restClient)
 }
 
+func (c *client) Admin() AdminInterface {
+	return newAdmin(c.restClient)
+}
+
+func (c *client) Auth() AuthInterface {
+	return newAuth(c.restClient)
+}
+
 func (c *client) Migrate(body *api.Migrate) (*modelV1.Dashboard, error) {
 	result := &modelV1.Dashboard{}
 	err := c.restClient.Post().
@@ -0,0 +1,76 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"github.com/perses/perses/pkg/client/perseshttp"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+const tokenSubResource = "tokens"
+
+// APITokenInterface manages the API tokens of a service account.
+type APITokenInterface interface {
+	// Create returns the token created. It is the only time the token itself is returned.
+	Create(request *v1.APITokenRequest) (*v1.APITokenResponse, error)
+	List() ([]v1.APIToken, error)
+	// Revoke removes the token, so it can't be used anymore.
+	Revoke(name string) error
+}
+
+type apiToken struct {
+	APITokenInterface
+	client         *perseshttp.RESTClient
+	serviceAccount string
+}
+
+func newAPIToken(client *perseshttp.RESTClient, serviceAccount string) APITokenInterface {
+	return &apiToken{
+		client:         client,
+		serviceAccount: serviceAccount,
+	}
+}
+
+func (c *apiToken) Create(request *v1.APITokenRequest) (*v1.APITokenResponse, error) {
+	result := &v1.APITokenResponse{}
+	err := c.client.Post().
+		Resource(serviceAccountResource).
+		Name(c.serviceAccount).
+		SubResource(tokenSubResource).
+		Body(request).
+		Do().
+		Object(result)
+	return result, err
+}
+
+func (c *apiToken) List() ([]v1.APIToken, error) {
+	var result []v1.APIToken
+	err := c.client.Get().
+		Resource(serviceAccountResource).
+		Name(c.serviceAccount).
+		SubResource(tokenSubResource).
+		Do().
+		Object(&result)
+	return result, err
+}
+
+func (c *apiToken) Revoke(name string) error {
+	return c.client.Delete().
+		Resource(serviceAccountResource).
+		Name(c.serviceAccount).
+		SubResource(tokenSubResource).
+		SubResourceName(name).
+		Do().
+		Error()
+}
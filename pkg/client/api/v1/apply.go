@@ -0,0 +1,64 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"net/url"
+
+	"github.com/perses/perses/pkg/client/perseshttp"
+	"github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+const applyResource = "apply"
+
+type applyQuery struct {
+	dryRun bool
+}
+
+func (q *applyQuery) GetValues() url.Values {
+	values := make(url.Values)
+	if q.dryRun {
+		values["dryRun"] = []string{"true"}
+	}
+	return values
+}
+
+type ApplyInterface interface {
+	// Apply creates or updates the resources in a single request. The server writes them in the order of their dependencies,
+	// once they have all been validated. With dryRun, nothing is written and the response tells what would be done.
+	Apply(resources []api.Entity, dryRun bool) (*v1.ApplyResponse, error)
+}
+
+type apply struct {
+	ApplyInterface
+	client *perseshttp.RESTClient
+}
+
+func newApply(client *perseshttp.RESTClient) ApplyInterface {
+	return &apply{
+		client: client,
+	}
+}
+
+func (c *apply) Apply(resources []api.Entity, dryRun bool) (*v1.ApplyResponse, error) {
+	result := &v1.ApplyResponse{}
+	err := c.client.Post().
+		Resource(applyResource).
+		Query(&applyQuery{dryRun: dryRun}).
+		Body(resources).
+		Do().
+		Object(result)
+	return result, err
+}
@@ -15,22 +15,40 @@ package v1
 
 import (
 	"net/url"
+	"strconv"
 
 	"github.com/perses/perses/pkg/client/perseshttp"
 )
 
 type ClientInterface interface {
 	RESTClient() *perseshttp.RESTClient
+	Apply() ApplyInterface
 	Dashboard(project string) DashboardInterface
 	Datasource(project string) DatasourceInterface
 	Folder(project string) FolderInterface
 	GlobalDatasource() GlobalDatasourceInterface
+	GlobalRole() GlobalRoleInterface
+	GlobalRoleBinding() GlobalRoleBindingInterface
 	GlobalSecret() GlobalSecretInterface
 	GlobalVariable() GlobalVariableInterface
+	GlobalWebhook() GlobalWebhookInterface
+	// GlobalWebhookDelivery reads the deliveries of the global webhook.
+	GlobalWebhookDelivery(webhook string) WebhookDeliveryInterface
 	Health() HealthInterface
 	Project() ProjectInterface
+	Role(project string) RoleInterface
+	RoleBinding(project string) RoleBindingInterface
+	Search() SearchInterface
 	Secret(project string) SecretInterface
+	ServiceAccount() ServiceAccountInterface
+	// APIToken manages the tokens of the service account.
+	APIToken(serviceAccount string) APITokenInterface
+	Trash() TrashInterface
+	User() UserInterface
 	Variable(project string) VariableInterface
+	Webhook(project string) WebhookInterface
+	// WebhookDelivery reads the deliveries of the webhook of the project.
+	WebhookDelivery(project string, webhook string) WebhookDeliveryInterface
 }
 
 type client struct {
@@ -48,6 +66,10 @@ func (c *client) RESTClient() *perseshttp.RESTClient {
 	return c.restClient
 }
 
+func (c *client) Apply() ApplyInterface {
+	return newApply(c.restClient)
+}
+
 func (c *client) Dashboard(project string) DashboardInterface {
 	return newDashboard(c.restClient, project)
 }
@@ -64,6 +86,14 @@ func (c *client) GlobalDatasource() GlobalDatasourceInterface {
 	return newGlobalDatasource(c.restClient)
 }
 
+func (c *client) GlobalRole() GlobalRoleInterface {
+	return newGlobalRole(c.restClient)
+}
+
+func (c *client) GlobalRoleBinding() GlobalRoleBindingInterface {
+	return newGlobalRoleBinding(c.restClient)
+}
+
 func (c *client) GlobalSecret() GlobalSecretInterface {
 	return newGlobalSecret(c.restClient)
 }
@@ -72,6 +102,14 @@ func (c *client) GlobalVariable() GlobalVariableInterface {
 	return newGlobalVariable(c.restClient)
 }
 
+func (c *client) GlobalWebhook() GlobalWebhookInterface {
+	return newGlobalWebhook(c.restClient)
+}
+
+func (c *client) GlobalWebhookDelivery(webhook string) WebhookDeliveryInterface {
+	return newWebhookDelivery(c.restClient, "", webhook)
+}
+
 func (c *client) Health() HealthInterface {
 	return newHealth(c.restClient)
 }
@@ -80,16 +118,71 @@ func (c *client) Project() ProjectInterface {
 	return newProject(c.restClient)
 }
 
+func (c *client) Role(project string) RoleInterface {
+	return newRole(c.restClient, project)
+}
+
+func (c *client) RoleBinding(project string) RoleBindingInterface {
+	return newRoleBinding(c.restClient, project)
+}
+
+func (c *client) Search() SearchInterface {
+	return newSearch(c.restClient)
+}
+
 func (c *client) Secret(project string) SecretInterface {
 	return newSecret(c.restClient, project)
 }
 
+func (c *client) ServiceAccount() ServiceAccountInterface {
+	return newServiceAccount(c.restClient)
+}
+
+func (c *client) APIToken(serviceAccount string) APITokenInterface {
+	return newAPIToken(c.restClient, serviceAccount)
+}
+
+func (c *client) Trash() TrashInterface {
+	return newTrash(c.restClient)
+}
+
+func (c *client) User() UserInterface {
+	return newUser(c.restClient)
+}
+
 func (c *client) Variable(project string) VariableInterface {
 	return newVariable(c.restClient, project)
 }
 
+func (c *client) Webhook(project string) WebhookInterface {
+	return newWebhook(c.restClient, project)
+}
+
+func (c *client) WebhookDelivery(project string, webhook string) WebhookDeliveryInterface {
+	return newWebhookDelivery(c.restClient, project, webhook)
+}
+
+// HeaderContinue is the header of the response containing the token to get the next page of a list.
+const HeaderContinue = "X-Continue-Token"
+
+// ListOptions are used to filter and to sort a list, and to get only a page of it.
+type ListOptions struct {
+	// Limit is the maximum number of resources returned. 0 means there is no limit.
+	Limit int
+	// Continue is the token returned with the previous page. It is empty to get the first page.
+	Continue string
+	// Sort is the field used to sort the list: name, createdAt or updatedAt. It is name by default.
+	// The order is ascending, unless the field is prefixed by "-".
+	Sort string
+	// LabelSelector is used to get only the resources with matching labels, like "team=sre,tier!=dev" or "tier in (frontend,backend)".
+	LabelSelector string
+}
+
 type query struct {
-	name string
+	name    string
+	options ListOptions
+	// watch asks for the changes of the list rather than the list itself.
+	watch bool
 }
 
 func (q *query) GetValues() url.Values {
@@ -97,5 +190,20 @@ func (q *query) GetValues() url.Values {
 	if len(q.name) > 0 {
 		values["name"] = []string{q.name}
 	}
+	if q.options.Limit > 0 {
+		values["limit"] = []string{strconv.Itoa(q.options.Limit)}
+	}
+	if len(q.options.Continue) > 0 {
+		values["continue"] = []string{q.options.Continue}
+	}
+	if len(q.options.Sort) > 0 {
+		values["sort"] = []string{q.options.Sort}
+	}
+	if len(q.options.LabelSelector) > 0 {
+		values["labelSelector"] = []string{q.options.LabelSelector}
+	}
+	if q.watch {
+		values["watch"] = []string{"true"}
+	}
 	return values
 }
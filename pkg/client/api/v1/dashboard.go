@@ -16,7 +16,11 @@
 package v1
 
 import (
+	"bytes"
+	"context"
+
 	"github.com/perses/perses/pkg/client/perseshttp"
+	"github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
@@ -25,6 +29,9 @@ const dashboardResource = "dashboards"
 type DashboardInterface interface {
 	Create(entity *v1.Dashboard) (*v1.Dashboard, error)
 	Update(entity *v1.Dashboard) (*v1.Dashboard, error)
+	// Patch applies the patch to the Dashboard named name and returns the result.
+	// The patch is either a JSON merge patch or a JSON patch, depending on patchType.
+	Patch(name string, patchType api.PatchType, patch []byte) (*v1.Dashboard, error)
 	Delete(name string) error
 	// Get is returning an unique Dashboard.
 	// As such name is the exact value of Dashboard.metadata.name. It cannot be empty.
@@ -33,6 +40,12 @@ type DashboardInterface interface {
 	// prefix is a prefix of the Dashboard.metadata.name to search for.
 	// It can be empty in case you want to get the full list of Dashboard available
 	List(prefix string) ([]*v1.Dashboard, error)
+	// ListPage returns the page of the list of Dashboard described by the options.
+	// It returns as well the token to get the next page. The token is empty when there is no more page.
+	ListPage(prefix string, options ListOptions) ([]*v1.Dashboard, string, error)
+	// Watch returns the changes of the Dashboard whose name starts with the prefix. The existing Dashboard are received first, as ADDED events.
+	// The options Limit and Continue cannot be used. The watch stops when ctx is done or when Watcher.Stop is called.
+	Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.Dashboard], error)
 }
 
 type dashboard struct {
@@ -71,6 +84,18 @@ func (c *dashboard) Update(entity *v1.Dashboard) (*v1.Dashboard, error) {
 	return result, err
 }
 
+func (c *dashboard) Patch(name string, patchType api.PatchType, patch []byte) (*v1.Dashboard, error) {
+	result := &v1.Dashboard{}
+	err := c.client.Patch().
+		Resource(dashboardResource).
+		Name(name).
+		Project(c.project).
+		RawBody(bytes.NewReader(patch), string(patchType)).
+		Do().
+		Object(result)
+	return result, err
+}
+
 func (c *dashboard) Delete(name string) error {
 	return c.client.Delete().
 		Resource(dashboardResource).
@@ -103,3 +128,31 @@ func (c *dashboard) List(prefix string) ([]*v1.Dashboard, error) {
 		Object(&result)
 	return result, err
 }
+
+func (c *dashboard) ListPage(prefix string, options ListOptions) ([]*v1.Dashboard, string, error) {
+	var result []*v1.Dashboard
+	response := c.client.Get().
+		Resource(dashboardResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+		}).
+		Project(c.project).
+		Do()
+	err := response.Object(&result)
+	return result, response.Header().Get(HeaderContinue), err
+}
+
+func (c *dashboard) Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.Dashboard], error) {
+	request := c.client.Get().
+		Resource(dashboardResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+			watch:   true,
+		}).
+		Project(c.project)
+	return watch(ctx, request, func() *v1.Dashboard {
+		return &v1.Dashboard{}
+	})
+}
@@ -16,7 +16,11 @@
 package v1
 
 import (
+	"bytes"
+	"context"
+
 	"github.com/perses/perses/pkg/client/perseshttp"
+	"github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
@@ -25,6 +29,9 @@ const datasourceResource = "datasources"
 type DatasourceInterface interface {
 	Create(entity *v1.Datasource) (*v1.Datasource, error)
 	Update(entity *v1.Datasource) (*v1.Datasource, error)
+	// Patch applies the patch to the Datasource named name and returns the result.
+	// The patch is either a JSON merge patch or a JSON patch, depending on patchType.
+	Patch(name string, patchType api.PatchType, patch []byte) (*v1.Datasource, error)
 	Delete(name string) error
 	// Get is returning an unique Datasource.
 	// As such name is the exact value of Datasource.metadata.name. It cannot be empty.
@@ -33,6 +40,12 @@ type DatasourceInterface interface {
 	// prefix is a prefix of the Datasource.metadata.name to search for.
 	// It can be empty in case you want to get the full list of Datasource available
 	List(prefix string) ([]*v1.Datasource, error)
+	// ListPage returns the page of the list of Datasource described by the options.
+	// It returns as well the token to get the next page. The token is empty when there is no more page.
+	ListPage(prefix string, options ListOptions) ([]*v1.Datasource, string, error)
+	// Watch returns the changes of the Datasource whose name starts with the prefix. The existing Datasource are received first, as ADDED events.
+	// The options Limit and Continue cannot be used. The watch stops when ctx is done or when Watcher.Stop is called.
+	Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.Datasource], error)
 }
 
 type datasource struct {
@@ -71,6 +84,18 @@ func (c *datasource) Update(entity *v1.Datasource) (*v1.Datasource, error) {
 	return result, err
 }
 
+func (c *datasource) Patch(name string, patchType api.PatchType, patch []byte) (*v1.Datasource, error) {
+	result := &v1.Datasource{}
+	err := c.client.Patch().
+		Resource(datasourceResource).
+		Name(name).
+		Project(c.project).
+		RawBody(bytes.NewReader(patch), string(patchType)).
+		Do().
+		Object(result)
+	return result, err
+}
+
 func (c *datasource) Delete(name string) error {
 	return c.client.Delete().
 		Resource(datasourceResource).
@@ -103,3 +128,31 @@ func (c *datasource) List(prefix string) ([]*v1.Datasource, error) {
 		Object(&result)
 	return result, err
 }
+
+func (c *datasource) ListPage(prefix string, options ListOptions) ([]*v1.Datasource, string, error) {
+	var result []*v1.Datasource
+	response := c.client.Get().
+		Resource(datasourceResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+		}).
+		Project(c.project).
+		Do()
+	err := response.Object(&result)
+	return result, response.Header().Get(HeaderContinue), err
+}
+
+func (c *datasource) Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.Datasource], error) {
+	request := c.client.Get().
+		Resource(datasourceResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+			watch:   true,
+		}).
+		Project(c.project)
+	return watch(ctx, request, func() *v1.Datasource {
+		return &v1.Datasource{}
+	})
+}
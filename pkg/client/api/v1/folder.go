@@ -16,7 +16,11 @@
 package v1
 
 import (
+	"bytes"
+	"context"
+
 	"github.com/perses/perses/pkg/client/perseshttp"
+	"github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
@@ -25,6 +29,9 @@ const folderResource = "folders"
 type FolderInterface interface {
 	Create(entity *v1.Folder) (*v1.Folder, error)
 	Update(entity *v1.Folder) (*v1.Folder, error)
+	// Patch applies the patch to the Folder named name and returns the result.
+	// The patch is either a JSON merge patch or a JSON patch, depending on patchType.
+	Patch(name string, patchType api.PatchType, patch []byte) (*v1.Folder, error)
 	Delete(name string) error
 	// Get is returning an unique Folder.
 	// As such name is the exact value of Folder.metadata.name. It cannot be empty.
@@ -33,6 +40,12 @@ type FolderInterface interface {
 	// prefix is a prefix of the Folder.metadata.name to search for.
 	// It can be empty in case you want to get the full list of Folder available
 	List(prefix string) ([]*v1.Folder, error)
+	// ListPage returns the page of the list of Folder described by the options.
+	// It returns as well the token to get the next page. The token is empty when there is no more page.
+	ListPage(prefix string, options ListOptions) ([]*v1.Folder, string, error)
+	// Watch returns the changes of the Folder whose name starts with the prefix. The existing Folder are received first, as ADDED events.
+	// The options Limit and Continue cannot be used. The watch stops when ctx is done or when Watcher.Stop is called.
+	Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.Folder], error)
 }
 
 type folder struct {
@@ -71,6 +84,18 @@ func (c *folder) Update(entity *v1.Folder) (*v1.Folder, error) {
 	return result, err
 }
 
+func (c *folder) Patch(name string, patchType api.PatchType, patch []byte) (*v1.Folder, error) {
+	result := &v1.Folder{}
+	err := c.client.Patch().
+		Resource(folderResource).
+		Name(name).
+		Project(c.project).
+		RawBody(bytes.NewReader(patch), string(patchType)).
+		Do().
+		Object(result)
+	return result, err
+}
+
 func (c *folder) Delete(name string) error {
 	return c.client.Delete().
 		Resource(folderResource).
@@ -103,3 +128,31 @@ func (c *folder) List(prefix string) ([]*v1.Folder, error) {
 		Object(&result)
 	return result, err
 }
+
+func (c *folder) ListPage(prefix string, options ListOptions) ([]*v1.Folder, string, error) {
+	var result []*v1.Folder
+	response := c.client.Get().
+		Resource(folderResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+		}).
+		Project(c.project).
+		Do()
+	err := response.Object(&result)
+	return result, response.Header().Get(HeaderContinue), err
+}
+
+func (c *folder) Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.Folder], error) {
+	request := c.client.Get().
+		Resource(folderResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+			watch:   true,
+		}).
+		Project(c.project)
+	return watch(ctx, request, func() *v1.Folder {
+		return &v1.Folder{}
+	})
+}
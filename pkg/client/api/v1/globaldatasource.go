@@ -16,7 +16,11 @@
 package v1
 
 import (
+	"bytes"
+	"context"
+
 	"github.com/perses/perses/pkg/client/perseshttp"
+	"github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
@@ -25,6 +29,9 @@ const globalDatasourceResource = "globaldatasources"
 type GlobalDatasourceInterface interface {
 	Create(entity *v1.GlobalDatasource) (*v1.GlobalDatasource, error)
 	Update(entity *v1.GlobalDatasource) (*v1.GlobalDatasource, error)
+	// Patch applies the patch to the GlobalDatasource named name and returns the result.
+	// The patch is either a JSON merge patch or a JSON patch, depending on patchType.
+	Patch(name string, patchType api.PatchType, patch []byte) (*v1.GlobalDatasource, error)
 	Delete(name string) error
 	// Get is returning an unique GlobalDatasource.
 	// As such name is the exact value of GlobalDatasource.metadata.name. It cannot be empty.
@@ -33,6 +40,12 @@ type GlobalDatasourceInterface interface {
 	// prefix is a prefix of the GlobalDatasource.metadata.name to search for.
 	// It can be empty in case you want to get the full list of GlobalDatasource available
 	List(prefix string) ([]*v1.GlobalDatasource, error)
+	// ListPage returns the page of the list of GlobalDatasource described by the options.
+	// It returns as well the token to get the next page. The token is empty when there is no more page.
+	ListPage(prefix string, options ListOptions) ([]*v1.GlobalDatasource, string, error)
+	// Watch returns the changes of the GlobalDatasource whose name starts with the prefix. The existing GlobalDatasource are received first, as ADDED events.
+	// The options Limit and Continue cannot be used. The watch stops when ctx is done or when Watcher.Stop is called.
+	Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.GlobalDatasource], error)
 }
 
 type globalDatasource struct {
@@ -67,6 +80,17 @@ func (c *globalDatasource) Update(entity *v1.GlobalDatasource) (*v1.GlobalDataso
 	return result, err
 }
 
+func (c *globalDatasource) Patch(name string, patchType api.PatchType, patch []byte) (*v1.GlobalDatasource, error) {
+	result := &v1.GlobalDatasource{}
+	err := c.client.Patch().
+		Resource(globalDatasourceResource).
+		Name(name).
+		RawBody(bytes.NewReader(patch), string(patchType)).
+		Do().
+		Object(result)
+	return result, err
+}
+
 func (c *globalDatasource) Delete(name string) error {
 	return c.client.Delete().
 		Resource(globalDatasourceResource).
@@ -96,3 +120,29 @@ func (c *globalDatasource) List(prefix string) ([]*v1.GlobalDatasource, error) {
 		Object(&result)
 	return result, err
 }
+
+func (c *globalDatasource) ListPage(prefix string, options ListOptions) ([]*v1.GlobalDatasource, string, error) {
+	var result []*v1.GlobalDatasource
+	response := c.client.Get().
+		Resource(globalDatasourceResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+		}).
+		Do()
+	err := response.Object(&result)
+	return result, response.Header().Get(HeaderContinue), err
+}
+
+func (c *globalDatasource) Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.GlobalDatasource], error) {
+	request := c.client.Get().
+		Resource(globalDatasourceResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+			watch:   true,
+		})
+	return watch(ctx, request, func() *v1.GlobalDatasource {
+		return &v1.GlobalDatasource{}
+	})
+}
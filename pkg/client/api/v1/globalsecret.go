@@ -16,7 +16,11 @@
 package v1
 
 import (
+	"bytes"
+	"context"
+
 	"github.com/perses/perses/pkg/client/perseshttp"
+	"github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
@@ -25,6 +29,9 @@ const globalSecretResource = "globalsecrets"
 type GlobalSecretInterface interface {
 	Create(entity *v1.GlobalSecret) (*v1.GlobalSecret, error)
 	Update(entity *v1.GlobalSecret) (*v1.GlobalSecret, error)
+	// Patch applies the patch to the GlobalSecret named name and returns the result.
+	// The patch is either a JSON merge patch or a JSON patch, depending on patchType.
+	Patch(name string, patchType api.PatchType, patch []byte) (*v1.GlobalSecret, error)
 	Delete(name string) error
 	// Get is returning an unique GlobalSecret.
 	// As such name is the exact value of GlobalSecret.metadata.name. It cannot be empty.
@@ -33,6 +40,12 @@ type GlobalSecretInterface interface {
 	// prefix is a prefix of the GlobalSecret.metadata.name to search for.
 	// It can be empty in case you want to get the full list of GlobalSecret available
 	List(prefix string) ([]*v1.GlobalSecret, error)
+	// ListPage returns the page of the list of GlobalSecret described by the options.
+	// It returns as well the token to get the next page. The token is empty when there is no more page.
+	ListPage(prefix string, options ListOptions) ([]*v1.GlobalSecret, string, error)
+	// Watch returns the changes of the GlobalSecret whose name starts with the prefix. The existing GlobalSecret are received first, as ADDED events.
+	// The options Limit and Continue cannot be used. The watch stops when ctx is done or when Watcher.Stop is called.
+	Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.GlobalSecret], error)
 }
 
 type globalSecret struct {
@@ -67,6 +80,17 @@ func (c *globalSecret) Update(entity *v1.GlobalSecret) (*v1.GlobalSecret, error)
 	return result, err
 }
 
+func (c *globalSecret) Patch(name string, patchType api.PatchType, patch []byte) (*v1.GlobalSecret, error) {
+	result := &v1.GlobalSecret{}
+	err := c.client.Patch().
+		Resource(globalSecretResource).
+		Name(name).
+		RawBody(bytes.NewReader(patch), string(patchType)).
+		Do().
+		Object(result)
+	return result, err
+}
+
 func (c *globalSecret) Delete(name string) error {
 	return c.client.Delete().
 		Resource(globalSecretResource).
@@ -96,3 +120,29 @@ func (c *globalSecret) List(prefix string) ([]*v1.GlobalSecret, error) {
 		Object(&result)
 	return result, err
 }
+
+func (c *globalSecret) ListPage(prefix string, options ListOptions) ([]*v1.GlobalSecret, string, error) {
+	var result []*v1.GlobalSecret
+	response := c.client.Get().
+		Resource(globalSecretResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+		}).
+		Do()
+	err := response.Object(&result)
+	return result, response.Header().Get(HeaderContinue), err
+}
+
+func (c *globalSecret) Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.GlobalSecret], error) {
+	request := c.client.Get().
+		Resource(globalSecretResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+			watch:   true,
+		})
+	return watch(ctx, request, func() *v1.GlobalSecret {
+		return &v1.GlobalSecret{}
+	})
+}
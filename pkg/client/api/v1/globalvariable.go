@@ -16,7 +16,11 @@
 package v1
 
 import (
+	"bytes"
+	"context"
+
 	"github.com/perses/perses/pkg/client/perseshttp"
+	"github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
@@ -25,6 +29,9 @@ const globalVariableResource = "globalvariables"
 type GlobalVariableInterface interface {
 	Create(entity *v1.GlobalVariable) (*v1.GlobalVariable, error)
 	Update(entity *v1.GlobalVariable) (*v1.GlobalVariable, error)
+	// Patch applies the patch to the GlobalVariable named name and returns the result.
+	// The patch is either a JSON merge patch or a JSON patch, depending on patchType.
+	Patch(name string, patchType api.PatchType, patch []byte) (*v1.GlobalVariable, error)
 	Delete(name string) error
 	// Get is returning an unique GlobalVariable.
 	// As such name is the exact value of GlobalVariable.metadata.name. It cannot be empty.
@@ -33,6 +40,12 @@ type GlobalVariableInterface interface {
 	// prefix is a prefix of the GlobalVariable.metadata.name to search for.
 	// It can be empty in case you want to get the full list of GlobalVariable available
 	List(prefix string) ([]*v1.GlobalVariable, error)
+	// ListPage returns the page of the list of GlobalVariable described by the options.
+	// It returns as well the token to get the next page. The token is empty when there is no more page.
+	ListPage(prefix string, options ListOptions) ([]*v1.GlobalVariable, string, error)
+	// Watch returns the changes of the GlobalVariable whose name starts with the prefix. The existing GlobalVariable are received first, as ADDED events.
+	// The options Limit and Continue cannot be used. The watch stops when ctx is done or when Watcher.Stop is called.
+	Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.GlobalVariable], error)
 }
 
 type globalVariable struct {
@@ -67,6 +80,17 @@ func (c *globalVariable) Update(entity *v1.GlobalVariable) (*v1.GlobalVariable,
 	return result, err
 }
 
+func (c *globalVariable) Patch(name string, patchType api.PatchType, patch []byte) (*v1.GlobalVariable, error) {
+	result := &v1.GlobalVariable{}
+	err := c.client.Patch().
+		Resource(globalVariableResource).
+		Name(name).
+		RawBody(bytes.NewReader(patch), string(patchType)).
+		Do().
+		Object(result)
+	return result, err
+}
+
 func (c *globalVariable) Delete(name string) error {
 	return c.client.Delete().
 		Resource(globalVariableResource).
@@ -96,3 +120,29 @@ func (c *globalVariable) List(prefix string) ([]*v1.GlobalVariable, error) {
 		Object(&result)
 	return result, err
 }
+
+func (c *globalVariable) ListPage(prefix string, options ListOptions) ([]*v1.GlobalVariable, string, error) {
+	var result []*v1.GlobalVariable
+	response := c.client.Get().
+		Resource(globalVariableResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+		}).
+		Do()
+	err := response.Object(&result)
+	return result, response.Header().Get(HeaderContinue), err
+}
+
+func (c *globalVariable) Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.GlobalVariable], error) {
+	request := c.client.Get().
+		Resource(globalVariableResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+			watch:   true,
+		})
+	return watch(ctx, request, func() *v1.GlobalVariable {
+		return &v1.GlobalVariable{}
+	})
+}
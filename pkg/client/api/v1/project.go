@@ -16,7 +16,11 @@
 package v1
 
 import (
+	"bytes"
+	"context"
+
 	"github.com/perses/perses/pkg/client/perseshttp"
+	"github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
@@ -25,6 +29,9 @@ const projectResource = "projects"
 type ProjectInterface interface {
 	Create(entity *v1.Project) (*v1.Project, error)
 	Update(entity *v1.Project) (*v1.Project, error)
+	// Patch applies the patch to the Project named name and returns the result.
+	// The patch is either a JSON merge patch or a JSON patch, depending on patchType.
+	Patch(name string, patchType api.PatchType, patch []byte) (*v1.Project, error)
 	Delete(name string) error
 	// Get is returning an unique Project.
 	// As such name is the exact value of Project.metadata.name. It cannot be empty.
@@ -33,6 +40,12 @@ type ProjectInterface interface {
 	// prefix is a prefix of the Project.metadata.name to search for.
 	// It can be empty in case you want to get the full list of Project available
 	List(prefix string) ([]*v1.Project, error)
+	// ListPage returns the page of the list of Project described by the options.
+	// It returns as well the token to get the next page. The token is empty when there is no more page.
+	ListPage(prefix string, options ListOptions) ([]*v1.Project, string, error)
+	// Watch returns the changes of the Project whose name starts with the prefix. The existing Project are received first, as ADDED events.
+	// The options Limit and Continue cannot be used. The watch stops when ctx is done or when Watcher.Stop is called.
+	Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.Project], error)
 }
 
 type project struct {
@@ -67,6 +80,17 @@ func (c *project) Update(entity *v1.Project) (*v1.Project, error) {
 	return result, err
 }
 
+func (c *project) Patch(name string, patchType api.PatchType, patch []byte) (*v1.Project, error) {
+	result := &v1.Project{}
+	err := c.client.Patch().
+		Resource(projectResource).
+		Name(name).
+		RawBody(bytes.NewReader(patch), string(patchType)).
+		Do().
+		Object(result)
+	return result, err
+}
+
 func (c *project) Delete(name string) error {
 	return c.client.Delete().
 		Resource(projectResource).
@@ -96,3 +120,29 @@ func (c *project) List(prefix string) ([]*v1.Project, error) {
 		Object(&result)
 	return result, err
 }
+
+func (c *project) ListPage(prefix string, options ListOptions) ([]*v1.Project, string, error) {
+	var result []*v1.Project
+	response := c.client.Get().
+		Resource(projectResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+		}).
+		Do()
+	err := response.Object(&result)
+	return result, response.Header().Get(HeaderContinue), err
+}
+
+func (c *project) Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.Project], error) {
+	request := c.client.Get().
+		Resource(projectResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+			watch:   true,
+		})
+	return watch(ctx, request, func() *v1.Project {
+		return &v1.Project{}
+	})
+}
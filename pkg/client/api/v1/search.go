@@ -0,0 +1,83 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/perses/perses/pkg/client/perseshttp"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+const searchResource = "search"
+
+// SearchOptions are used to restrict the results of a search.
+type SearchOptions struct {
+	// Project is used to search only in this project. It is empty to search in every project and in the global resources.
+	Project string
+	// Kind is used to search only the resources of this kind: Dashboard, Variable or GlobalVariable.
+	Kind v1.Kind
+	// Limit is the maximum number of results. 0 means the default limit of the API is used.
+	Limit int
+}
+
+type searchQuery struct {
+	text    string
+	options SearchOptions
+}
+
+func (q *searchQuery) GetValues() url.Values {
+	values := make(url.Values)
+	values["q"] = []string{q.text}
+	if len(q.options.Project) > 0 {
+		values["project"] = []string{q.options.Project}
+	}
+	if len(q.options.Kind) > 0 {
+		values["kind"] = []string{string(q.options.Kind)}
+	}
+	if q.options.Limit > 0 {
+		values["limit"] = []string{strconv.Itoa(q.options.Limit)}
+	}
+	return values
+}
+
+type SearchInterface interface {
+	// Search returns the dashboards and the variables containing every term of the text, the most relevant first.
+	Search(text string, options SearchOptions) ([]*v1.SearchResult, error)
+}
+
+type search struct {
+	SearchInterface
+	client *perseshttp.RESTClient
+}
+
+func newSearch(client *perseshttp.RESTClient) SearchInterface {
+	return &search{
+		client: client,
+	}
+}
+
+func (c *search) Search(text string, options SearchOptions) ([]*v1.SearchResult, error) {
+	var result []*v1.SearchResult
+	err := c.client.Get().
+		Resource(searchResource).
+		Query(&searchQuery{
+			text:    text,
+			options: options,
+		}).
+		Do().
+		Object(&result)
+	return result, err
+}
@@ -16,7 +16,11 @@
 package v1
 
 import (
+	"bytes"
+	"context"
+
 	"github.com/perses/perses/pkg/client/perseshttp"
+	"github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
@@ -25,6 +29,9 @@ const secretResource = "secrets"
 type SecretInterface interface {
 	Create(entity *v1.Secret) (*v1.Secret, error)
 	Update(entity *v1.Secret) (*v1.Secret, error)
+	// Patch applies the patch to the Secret named name and returns the result.
+	// The patch is either a JSON merge patch or a JSON patch, depending on patchType.
+	Patch(name string, patchType api.PatchType, patch []byte) (*v1.Secret, error)
 	Delete(name string) error
 	// Get is returning an unique Secret.
 	// As such name is the exact value of Secret.metadata.name. It cannot be empty.
@@ -33,6 +40,12 @@ type SecretInterface interface {
 	// prefix is a prefix of the Secret.metadata.name to search for.
 	// It can be empty in case you want to get the full list of Secret available
 	List(prefix string) ([]*v1.Secret, error)
+	// ListPage returns the page of the list of Secret described by the options.
+	// It returns as well the token to get the next page. The token is empty when there is no more page.
+	ListPage(prefix string, options ListOptions) ([]*v1.Secret, string, error)
+	// Watch returns the changes of the Secret whose name starts with the prefix. The existing Secret are received first, as ADDED events.
+	// The options Limit and Continue cannot be used. The watch stops when ctx is done or when Watcher.Stop is called.
+	Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.Secret], error)
 }
 
 type secret struct {
@@ -71,6 +84,18 @@ func (c *secret) Update(entity *v1.Secret) (*v1.Secret, error) {
 	return result, err
 }
 
+func (c *secret) Patch(name string, patchType api.PatchType, patch []byte) (*v1.Secret, error) {
+	result := &v1.Secret{}
+	err := c.client.Patch().
+		Resource(secretResource).
+		Name(name).
+		Project(c.project).
+		RawBody(bytes.NewReader(patch), string(patchType)).
+		Do().
+		Object(result)
+	return result, err
+}
+
 func (c *secret) Delete(name string) error {
 	return c.client.Delete().
 		Resource(secretResource).
@@ -103,3 +128,31 @@ func (c *secret) List(prefix string) ([]*v1.Secret, error) {
 		Object(&result)
 	return result, err
 }
+
+func (c *secret) ListPage(prefix string, options ListOptions) ([]*v1.Secret, string, error) {
+	var result []*v1.Secret
+	response := c.client.Get().
+		Resource(secretResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+		}).
+		Project(c.project).
+		Do()
+	err := response.Object(&result)
+	return result, response.Header().Get(HeaderContinue), err
+}
+
+func (c *secret) Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.Secret], error) {
+	request := c.client.Get().
+		Resource(secretResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+			watch:   true,
+		}).
+		Project(c.project)
+	return watch(ctx, request, func() *v1.Secret {
+		return &v1.Secret{}
+	})
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"net/url"
+
+	"github.com/perses/perses/pkg/client/perseshttp"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+const trashResource = "trash"
+
+// TrashOptions are used to filter the entries of the trash.
+type TrashOptions struct {
+	// Project is used to get only the entries of this project. It is empty to get the entries of every project.
+	Project string
+	// Kind is used to get only the entries of the resources of this kind: Dashboard or Project.
+	Kind v1.Kind
+}
+
+type trashQuery struct {
+	options TrashOptions
+}
+
+func (q *trashQuery) GetValues() url.Values {
+	values := make(url.Values)
+	if len(q.options.Project) > 0 {
+		values["project"] = []string{q.options.Project}
+	}
+	if len(q.options.Kind) > 0 {
+		values["kind"] = []string{string(q.options.Kind)}
+	}
+	return values
+}
+
+type TrashInterface interface {
+	// List returns the entries of the trash matching the options, the most recent first.
+	List(options TrashOptions) ([]*v1.TrashEntry, error)
+	Get(name string) (*v1.TrashEntry, error)
+	// Restore creates again the dashboard or the project contained in the entry, and then removes the entry.
+	Restore(name string) error
+	// Purge removes the entry for good.
+	Purge(name string) error
+	// PurgeAll removes for good every entry matching the options, and returns them.
+	PurgeAll(options TrashOptions) ([]*v1.TrashEntry, error)
+}
+
+type trash struct {
+	TrashInterface
+	client *perseshttp.RESTClient
+}
+
+func newTrash(client *perseshttp.RESTClient) TrashInterface {
+	return &trash{
+		client: client,
+	}
+}
+
+func (c *trash) List(options TrashOptions) ([]*v1.TrashEntry, error) {
+	var result []*v1.TrashEntry
+	err := c.client.Get().
+		Resource(trashResource).
+		Query(&trashQuery{options: options}).
+		Do().
+		Object(&result)
+	return result, err
+}
+
+func (c *trash) Get(name string) (*v1.TrashEntry, error) {
+	result := &v1.TrashEntry{}
+	err := c.client.Get().
+		Resource(trashResource).
+		Name(name).
+		Do().
+		Object(result)
+	return result, err
+}
+
+func (c *trash) Restore(name string) error {
+	return c.client.Post().
+		Resource(trashResource).
+		Name(name).
+		SubResource("restore").
+		Do().
+		Error()
+}
+
+func (c *trash) Purge(name string) error {
+	return c.client.Delete().
+		Resource(trashResource).
+		Name(name).
+		Do().
+		Error()
+}
+
+func (c *trash) PurgeAll(options TrashOptions) ([]*v1.TrashEntry, error) {
+	var result []*v1.TrashEntry
+	err := c.client.Delete().
+		Resource(trashResource).
+		Query(&trashQuery{options: options}).
+		Do().
+		Object(&result)
+	return result, err
+}
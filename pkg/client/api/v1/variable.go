@@ -16,7 +16,11 @@
 package v1
 
 import (
+	"bytes"
+	"context"
+
 	"github.com/perses/perses/pkg/client/perseshttp"
+	"github.com/perses/perses/pkg/model/api"
 	v1 "github.com/perses/perses/pkg/model/api/v1"
 )
 
@@ -25,6 +29,9 @@ const variableResource = "variables"
 type VariableInterface interface {
 	Create(entity *v1.Variable) (*v1.Variable, error)
 	Update(entity *v1.Variable) (*v1.Variable, error)
+	// Patch applies the patch to the Variable named name and returns the result.
+	// The patch is either a JSON merge patch or a JSON patch, depending on patchType.
+	Patch(name string, patchType api.PatchType, patch []byte) (*v1.Variable, error)
 	Delete(name string) error
 	// Get is returning an unique Variable.
 	// As such name is the exact value of Variable.metadata.name. It cannot be empty.
@@ -33,6 +40,12 @@ type VariableInterface interface {
 	// prefix is a prefix of the Variable.metadata.name to search for.
 	// It can be empty in case you want to get the full list of Variable available
 	List(prefix string) ([]*v1.Variable, error)
+	// ListPage returns the page of the list of Variable described by the options.
+	// It returns as well the token to get the next page. The token is empty when there is no more page.
+	ListPage(prefix string, options ListOptions) ([]*v1.Variable, string, error)
+	// Watch returns the changes of the Variable whose name starts with the prefix. The existing Variable are received first, as ADDED events.
+	// The options Limit and Continue cannot be used. The watch stops when ctx is done or when Watcher.Stop is called.
+	Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.Variable], error)
 }
 
 type variable struct {
@@ -71,6 +84,18 @@ func (c *variable) Update(entity *v1.Variable) (*v1.Variable, error) {
 	return result, err
 }
 
+func (c *variable) Patch(name string, patchType api.PatchType, patch []byte) (*v1.Variable, error) {
+	result := &v1.Variable{}
+	err := c.client.Patch().
+		Resource(variableResource).
+		Name(name).
+		Project(c.project).
+		RawBody(bytes.NewReader(patch), string(patchType)).
+		Do().
+		Object(result)
+	return result, err
+}
+
 func (c *variable) Delete(name string) error {
 	return c.client.Delete().
 		Resource(variableResource).
@@ -103,3 +128,31 @@ func (c *variable) List(prefix string) ([]*v1.Variable, error) {
 		Object(&result)
 	return result, err
 }
+
+func (c *variable) ListPage(prefix string, options ListOptions) ([]*v1.Variable, string, error) {
+	var result []*v1.Variable
+	response := c.client.Get().
+		Resource(variableResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+		}).
+		Project(c.project).
+		Do()
+	err := response.Object(&result)
+	return result, response.Header().Get(HeaderContinue), err
+}
+
+func (c *variable) Watch(ctx context.Context, prefix string, options ListOptions) (*Watcher[*v1.Variable], error) {
+	request := c.client.Get().
+		Resource(variableResource).
+		Query(&query{
+			name:    prefix,
+			options: options,
+			watch:   true,
+		}).
+		Project(c.project)
+	return watch(ctx, request, func() *v1.Variable {
+		return &v1.Variable{}
+	})
+}
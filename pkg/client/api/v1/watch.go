@@ -0,0 +1,112 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// Event is a change of a resource received by a Watcher.
+type Event[T modelAPI.Entity] struct {
+	Type v1.EventType
+	// Object is the resource after the change. For a deleted resource, it is the last version known.
+	Object T
+}
+
+// Watcher receives the changes of the resources of a list.
+type Watcher[T modelAPI.Entity] struct {
+	events chan Event[T]
+	cancel context.CancelFunc
+	mutex  sync.Mutex
+	err    error
+}
+
+// Events returns the changes received. The channel is closed when the watch stops, then Err tells why.
+func (w *Watcher[T]) Events() <-chan Event[T] {
+	return w.events
+}
+
+// Err returns the error that stopped the watch. It is nil if the watch has been stopped by the client,
+// or if the server has closed the connection. In the latter case, the client has to watch again.
+func (w *Watcher[T]) Err() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.err
+}
+
+// Stop closes the connection to the server.
+func (w *Watcher[T]) Stop() {
+	w.cancel()
+}
+
+// watch executes the request and decodes the events, one JSON object per line, sent by the server.
+func watch[T modelAPI.Entity](ctx context.Context, request *perseshttp.Request, newEntity func() T) (*Watcher[T], error) {
+	ctx, cancel := context.WithCancel(ctx)
+	body, err := request.Context(ctx).Stream()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	w := &Watcher[T]{
+		events: make(chan Event[T]),
+		cancel: cancel,
+	}
+	go w.run(ctx, body, newEntity)
+	return w, nil
+}
+
+type rawEvent struct {
+	Type   v1.EventType        `json:"type"`
+	Object jsoniter.RawMessage `json:"object"`
+}
+
+func (w *Watcher[T]) run(ctx context.Context, body io.ReadCloser, newEntity func() T) {
+	defer close(w.events)
+	defer body.Close() // nolint: errcheck
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	decoder := json.NewDecoder(body)
+	for {
+		event := &rawEvent{}
+		if err := decoder.Decode(event); err != nil {
+			if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+				w.setErr(err)
+			}
+			return
+		}
+		object := newEntity()
+		if err := json.Unmarshal(event.Object, object); err != nil {
+			w.setErr(err)
+			return
+		}
+		select {
+		case w.events <- Event[T]{Type: event.Type, Object: object}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *Watcher[T]) setErr(err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.err = err
+}
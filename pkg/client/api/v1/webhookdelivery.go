@@ -0,0 +1,95 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/perses/perses/pkg/client/perseshttp"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+const deliverySubResource = "deliveries"
+
+type deliveryQuery struct {
+	status v1.WebhookDeliveryStatus
+}
+
+func (q *deliveryQuery) GetValues() url.Values {
+	values := make(url.Values)
+	if len(q.status) > 0 {
+		values["status"] = []string{string(q.status)}
+	}
+	return values
+}
+
+// WebhookDeliveryInterface reads the log of the deliveries of a webhook.
+type WebhookDeliveryInterface interface {
+	// List returns the deliveries of the webhook, the most recent first. The status is empty to get every delivery.
+	List(status v1.WebhookDeliveryStatus) ([]*v1.WebhookDelivery, error)
+	Get(name string) (*v1.WebhookDelivery, error)
+	// Replay sends again the event of a delivery that is over, and returns the new delivery.
+	Replay(name string) (*v1.WebhookDelivery, error)
+}
+
+type webhookDelivery struct {
+	WebhookDeliveryInterface
+	client *perseshttp.RESTClient
+	// project is empty for the deliveries of a GlobalWebhook.
+	project string
+	webhook string
+}
+
+func newWebhookDelivery(client *perseshttp.RESTClient, project string, webhook string) WebhookDeliveryInterface {
+	return &webhookDelivery{
+		client:  client,
+		project: project,
+		webhook: webhook,
+	}
+}
+
+func (c *webhookDelivery) request(request *perseshttp.Request) *perseshttp.Request {
+	if len(c.project) == 0 {
+		return request.Resource(globalWebhookResource).Name(c.webhook).SubResource(deliverySubResource)
+	}
+	return request.Project(c.project).Resource(webhookResource).Name(c.webhook).SubResource(deliverySubResource)
+}
+
+func (c *webhookDelivery) List(status v1.WebhookDeliveryStatus) ([]*v1.WebhookDelivery, error) {
+	var result []*v1.WebhookDelivery
+	err := c.request(c.client.Get()).
+		Query(&deliveryQuery{status: status}).
+		Do().
+		Object(&result)
+	return result, err
+}
+
+func (c *webhookDelivery) Get(name string) (*v1.WebhookDelivery, error) {
+	result := &v1.WebhookDelivery{}
+	err := c.request(c.client.Get()).
+		SubResourceName(name).
+		Do().
+		Object(result)
+	return result, err
+}
+
+func (c *webhookDelivery) Replay(name string) (*v1.WebhookDelivery, error) {
+	result := &v1.WebhookDelivery{}
+	err := c.request(c.client.Post()).
+		SubResourceName(fmt.Sprintf("%s/replay", name)).
+		Do().
+		Object(result)
+	return result, err
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/perses/perses/pkg/client/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+var backupCreatedAt = time.Date(2023, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+// BackupArchive returns a fake backup archive containing a single project.
+func BackupArchive() []byte {
+	project, _ := json.Marshal(&modelV1.Project{Kind: modelV1.KindProject, Metadata: *modelV1.NewMetadata("perses")})
+	checksum := sha256.Sum256(project)
+	records := []*modelV1.BackupRecord{
+		{Header: &modelV1.BackupHeader{
+			Version:    modelV1.BackupVersion,
+			CreatedAt:  backupCreatedAt,
+			Encryption: modelV1.BackupEncryption{Mode: modelV1.BackupEncryptionServerKey},
+		}},
+		{Resource: project},
+		{Footer: &modelV1.BackupFooter{
+			Resources: map[modelV1.Kind]int{modelV1.KindProject: 1},
+			Checksum:  hex.EncodeToString(checksum[:]),
+		}},
+	}
+	buffer := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buffer)
+	encoder := json.NewEncoder(gzipWriter)
+	for _, record := range records {
+		_ = encoder.Encode(record)
+	}
+	_ = gzipWriter.Close()
+	return buffer.Bytes()
+}
+
+type admin struct {
+	api.AdminInterface
+}
+
+func (c *admin) Export(_ string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(BackupArchive())), nil
+}
+
+func (c *admin) Import(archive io.Reader, _ string) (*modelV1.BackupSummary, error) {
+	if _, err := io.ReadAll(archive); err != nil {
+		return nil, err
+	}
+	return &modelV1.BackupSummary{
+		CreatedAt: backupCreatedAt,
+		Resources: map[modelV1.Kind]int{modelV1.KindProject: 1},
+	}, nil
+}
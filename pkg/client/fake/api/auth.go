@@ -0,0 +1,31 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeapi
+
+import (
+	"github.com/perses/perses/pkg/client/api"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+)
+
+type auth struct {
+	api.AuthInterface
+}
+
+func (a *auth) Login(_ string, _ string) (*modelAPI.AuthResponse, error) {
+	return &modelAPI.AuthResponse{AccessToken: "access_token", RefreshToken: "refresh_token"}, nil
+}
+
+func (a *auth) Refresh(_ string) (*modelAPI.AuthResponse, error) {
+	return &modelAPI.AuthResponse{AccessToken: "access_token"}, nil
+}
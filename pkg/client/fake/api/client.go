@@ -36,6 +36,14 @@ func (c *client) RESTClient() *perseshttp.RESTClient {
 	return nil
 }
 
+func (c *client) Admin() api.AdminInterface {
+	return &admin{}
+}
+
+func (c *client) Auth() api.AuthInterface {
+	return &auth{}
+}
+
 func (c *client) V1() v1.ClientInterface {
 	return fakev1.New()
 }
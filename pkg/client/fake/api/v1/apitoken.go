@@ -0,0 +1,64 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakev1
+
+import (
+	"time"
+
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// APITokenList returns the fake tokens of the service accounts.
+func APITokenList() []modelV1.APIToken {
+	createdAt := time.Date(2023, time.October, 1, 12, 0, 0, 0, time.UTC)
+	return []modelV1.APIToken{
+		{
+			Name:      "deploy",
+			CreatedAt: createdAt,
+			ExpiresAt: createdAt.Add(90 * 24 * time.Hour),
+		},
+	}
+}
+
+type apiToken struct {
+	v1.APITokenInterface
+}
+
+func (c *apiToken) Create(request *modelV1.APITokenRequest) (*modelV1.APITokenResponse, error) {
+	createdAt := time.Date(2023, time.October, 1, 12, 0, 0, 0, time.UTC)
+	return &modelV1.APITokenResponse{
+		APIToken: modelV1.APIToken{
+			Name:        request.Name,
+			Permissions: request.Permissions,
+			CreatedAt:   createdAt,
+			ExpiresAt:   createdAt.Add(90 * 24 * time.Hour),
+		},
+		Token: "perses_ci." + request.Name + ".secret",
+	}, nil
+}
+
+func (c *apiToken) List() ([]modelV1.APIToken, error) {
+	return APITokenList(), nil
+}
+
+func (c *apiToken) Revoke(name string) error {
+	for _, token := range APITokenList() {
+		if token.Name == name {
+			return nil
+		}
+	}
+	return perseshttp.RequestNotFoundError
+}
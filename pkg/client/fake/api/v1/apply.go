@@ -0,0 +1,42 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakev1
+
+import (
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type apply struct {
+	v1.ApplyInterface
+}
+
+// Apply creates every resource, in the order they are given.
+func (c *apply) Apply(resources []modelAPI.Entity, dryRun bool) (*modelV1.ApplyResponse, error) {
+	response := &modelV1.ApplyResponse{DryRun: dryRun}
+	for _, resource := range resources {
+		var project string
+		if metadata, ok := resource.GetMetadata().(*modelV1.ProjectMetadata); ok {
+			project = metadata.Project
+		}
+		response.Results = append(response.Results, &modelV1.ApplyResult{
+			Kind:    modelV1.Kind(resource.GetKind()),
+			Project: project,
+			Name:    resource.GetMetadata().GetName(),
+			Action:  modelV1.ApplyActionCreate,
+		})
+	}
+	return response, nil
+}
@@ -16,8 +16,30 @@ package fakev1
 import (
 	v1 "github.com/perses/perses/pkg/client/api/v1"
 	"github.com/perses/perses/pkg/client/perseshttp"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	"github.com/perses/perses/pkg/model/api/v1/common"
 )
 
+// selectByLabels returns the entities matching the label selector of the options.
+// The other options are ignored, so the whole list is always returned in a single page.
+func selectByLabels[T modelAPI.Entity](entities []T, options v1.ListOptions) ([]T, string, error) {
+	selector, err := common.ParseLabelSelector(options.LabelSelector)
+	if err != nil {
+		return nil, "", err
+	}
+	var result []T
+	for _, entity := range entities {
+		var labels map[string]string
+		if m, ok := entity.GetMetadata().(interface{ GetLabels() map[string]string }); ok {
+			labels = m.GetLabels()
+		}
+		if selector.Matches(labels) {
+			result = append(result, entity)
+		}
+	}
+	return result, "", nil
+}
+
 type client struct {
 	v1.ClientInterface
 }
@@ -30,6 +52,14 @@ func (c *client) RESTClient() *perseshttp.RESTClient {
 	return nil
 }
 
+func (c *client) Apply() v1.ApplyInterface {
+	return &apply{}
+}
+
+func (c *client) APIToken(_ string) v1.APITokenInterface {
+	return &apiToken{}
+}
+
 func (c *client) Folder(project string) v1.FolderInterface {
 	return &folder{
 		project: project,
@@ -47,3 +77,11 @@ func (c *client) Health() v1.HealthInterface {
 func (c *client) Project() v1.ProjectInterface {
 	return &project{}
 }
+
+func (c *client) Search() v1.SearchInterface {
+	return &search{}
+}
+
+func (c *client) Trash() v1.TrashInterface {
+	return &trash{}
+}
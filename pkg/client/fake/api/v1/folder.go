@@ -83,3 +83,7 @@ func (c *folder) Get(name string) (*modelV1.Folder, error) {
 func (c *folder) List(prefix string) ([]*modelV1.Folder, error) {
 	return FolderList(c.project, prefix), nil
 }
+
+func (c *folder) ListPage(prefix string, options v1.ListOptions) ([]*modelV1.Folder, string, error) {
+	return selectByLabels(FolderList(c.project, prefix), options)
+}
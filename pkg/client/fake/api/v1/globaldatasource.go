@@ -122,3 +122,7 @@ func (c *globalDatasource) Get(name string) (*modelV1.GlobalDatasource, error) {
 func (c *globalDatasource) List(prefix string) ([]*modelV1.GlobalDatasource, error) {
 	return GlobalDatasourceList(prefix), nil
 }
+
+func (c *globalDatasource) ListPage(prefix string, options v1.ListOptions) ([]*modelV1.GlobalDatasource, string, error) {
+	return selectByLabels(GlobalDatasourceList(prefix), options)
+}
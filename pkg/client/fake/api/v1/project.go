@@ -25,7 +25,8 @@ func ProjectList(prefix string) []*modelV1.Project {
 		{
 			Kind: modelV1.KindProject,
 			Metadata: modelV1.Metadata{
-				Name: "perses",
+				Name:   "perses",
+				Labels: map[string]string{"team": "perses"},
 			},
 		},
 		{
@@ -78,3 +79,7 @@ func (c *project) Get(name string) (*modelV1.Project, error) {
 func (c *project) List(prefix string) ([]*modelV1.Project, error) {
 	return ProjectList(prefix), nil
 }
+
+func (c *project) ListPage(prefix string, options v1.ListOptions) ([]*modelV1.Project, string, error) {
+	return selectByLabels(ProjectList(prefix), options)
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakev1
+
+import (
+	"strings"
+
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// SearchResults returns the fake results whose name contains the text and matching the options.
+func SearchResults(text string, options v1.SearchOptions) []*modelV1.SearchResult {
+	initialList := []*modelV1.SearchResult{
+		{
+			Kind:        modelV1.KindDashboard,
+			Project:     "perses",
+			Name:        "node_exporter",
+			DisplayName: "Node Exporter",
+			Score:       10,
+			Matches:     []modelV1.SearchMatch{{Field: "spec.display.name", Value: "Node Exporter"}},
+		},
+		{
+			Kind:    modelV1.KindVariable,
+			Project: "perses",
+			Name:    "node",
+			Score:   8,
+			Matches: []modelV1.SearchMatch{{Field: "metadata.name", Value: "node"}},
+		},
+		{
+			Kind:        modelV1.KindGlobalVariable,
+			Name:        "node_name",
+			DisplayName: "Node",
+			Score:       8,
+			Matches:     []modelV1.SearchMatch{{Field: "metadata.name", Value: "node_name"}},
+		},
+	}
+	result := []*modelV1.SearchResult{}
+	for _, r := range initialList {
+		if !strings.Contains(r.Name, strings.ToLower(text)) {
+			continue
+		}
+		if len(options.Project) > 0 && r.Project != options.Project {
+			continue
+		}
+		if len(options.Kind) > 0 && r.Kind != options.Kind {
+			continue
+		}
+		result = append(result, r)
+	}
+	if options.Limit > 0 && len(result) > options.Limit {
+		result = result[:options.Limit]
+	}
+	return result
+}
+
+type search struct {
+	v1.SearchInterface
+}
+
+func (c *search) Search(text string, options v1.SearchOptions) ([]*modelV1.SearchResult, error) {
+	return SearchResults(text, options), nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakev1
+
+import (
+	"time"
+
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	modelV1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+func newTrashEntry(name string, kind modelV1.Kind, project string, resourceName string) *modelV1.TrashEntry {
+	deletedAt := time.Date(2023, time.October, 1, 12, 0, 0, 0, time.UTC)
+	return &modelV1.TrashEntry{
+		Kind:     modelV1.KindTrashEntry,
+		Metadata: *modelV1.NewMetadata(name),
+		Spec: modelV1.TrashEntrySpec{
+			Resource:  modelV1.TrashResource{Kind: kind, Project: project, Name: resourceName},
+			DeletedAt: deletedAt,
+			ExpiresAt: deletedAt.Add(30 * 24 * time.Hour),
+		},
+	}
+}
+
+// TrashList returns the fake entries of the trash matching the options.
+func TrashList(options v1.TrashOptions) []*modelV1.TrashEntry {
+	initialList := []*modelV1.TrashEntry{
+		newTrashEntry("dashboard-s2b0k1", modelV1.KindDashboard, "perses", "Demo"),
+		newTrashEntry("project-s2b0j7", modelV1.KindProject, "Amadeus", "Amadeus"),
+	}
+	result := []*modelV1.TrashEntry{}
+	for _, entry := range initialList {
+		if len(options.Project) > 0 && entry.Spec.Resource.Project != options.Project {
+			continue
+		}
+		if len(options.Kind) > 0 && entry.Spec.Resource.Kind != options.Kind {
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+type trash struct {
+	v1.TrashInterface
+}
+
+func (c *trash) List(options v1.TrashOptions) ([]*modelV1.TrashEntry, error) {
+	return TrashList(options), nil
+}
+
+func (c *trash) Get(name string) (*modelV1.TrashEntry, error) {
+	for _, entry := range TrashList(v1.TrashOptions{}) {
+		if entry.Metadata.Name == name {
+			return entry, nil
+		}
+	}
+	return nil, perseshttp.RequestNotFoundError
+}
+
+func (c *trash) Restore(name string) error {
+	_, err := c.Get(name)
+	return err
+}
+
+func (c *trash) Purge(name string) error {
+	_, err := c.Get(name)
+	return err
+}
+
+func (c *trash) PurgeAll(options v1.TrashOptions) ([]*modelV1.TrashEntry, error) {
+	return TrashList(options), nil
+}
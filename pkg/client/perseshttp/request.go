@@ -53,13 +53,16 @@ type Request struct {
 	apiPrefix  string // it's the api prefix such as /api
 	apiVersion string
 	// Resource
-	project  string
-	resource string
-	name     string
-
-	queryParam url.Values
-	body       io.Reader
-	err        error
+	project         string
+	resource        string
+	name            string
+	subResource     string
+	subResourceName string
+
+	queryParam  url.Values
+	body        io.Reader
+	contentType string
+	err         error
 }
 
 // NewRequest creates a new request helper object for accessing resource on a the API
@@ -106,6 +109,19 @@ func (r *Request) Name(name string) *Request {
 	return r
 }
 
+// SubResource set the sub-resource or the action targeted on the resource, like the restoration of a resource (/restore).
+// It requires the name of the resource to be set.
+func (r *Request) SubResource(subResource string) *Request {
+	r.subResource = subResource
+	return r
+}
+
+// SubResourceName set the name of the sub-resource, like the name of a token of a service account. It requires the sub-resource to be set.
+func (r *Request) SubResourceName(subResourceName string) *Request {
+	r.subResourceName = subResourceName
+	return r
+}
+
 // Query set all queryParameter contains in the query passed as a parameter
 func (r *Request) Query(query QueryInterface) *Request {
 	if query == nil {
@@ -120,6 +136,12 @@ func (r *Request) Query(query QueryInterface) *Request {
 	return r
 }
 
+// Context sets the context of the HTTP request, so it can be canceled.
+func (r *Request) Context(ctx context.Context) *Request {
+	r.ctx = ctx
+	return r
+}
+
 // Body defines the body in the HTTP request.
 // The body shall be json compatible
 func (r *Request) Body(obj interface{}) *Request {
@@ -133,6 +155,29 @@ func (r *Request) Body(obj interface{}) *Request {
 	return r
 }
 
+// RawBody defines the body in the HTTP request as it is, like a file to upload. The body is sent with the given content type.
+func (r *Request) RawBody(body io.Reader, contentType string) *Request {
+	r.body = body
+	r.contentType = contentType
+	return r
+}
+
+// Header sets a header of the HTTP request.
+func (r *Request) Header(key string, value string) *Request {
+	if r.headers == nil {
+		r.headers = make(map[string]string)
+	} else {
+		// the headers are shared with the client and the other requests, so they are copied first.
+		headers := make(map[string]string, len(r.headers)+1)
+		for k, v := range r.headers {
+			headers[k] = v
+		}
+		r.headers = headers
+	}
+	r.headers[key] = value
+	return r
+}
+
 // Do build the query and execute it.
 // The error and/or the response from the server are set in the object Response
 func (r *Request) Do() *Response {
@@ -171,10 +216,42 @@ func (r *Request) Do() *Response {
 	// Deserialize the json response
 	if resp.Body != nil {
 		data, err := io.ReadAll(resp.Body)
-		return &Response{body: data, err: err, statusCode: resp.StatusCode}
+		return &Response{body: data, err: err, statusCode: resp.StatusCode, header: resp.Header}
 	}
 
-	return &Response{statusCode: resp.StatusCode}
+	return &Response{statusCode: resp.StatusCode, header: resp.Header}
+}
+
+// Stream executes the request and returns the body of the response as it is received, like the events of a watch.
+// The timeout of the client doesn't apply, so the request should be stopped using the context (see Request.Context) or by closing the body.
+// The body must be closed by the caller.
+func (r *Request) Stream() (io.ReadCloser, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	httpClient := http.DefaultClient
+	if r.client != nil {
+		streamClient := *r.client
+		streamClient.Timeout = 0
+		httpClient = &streamClient
+	}
+	httpRequest, err := r.prepareRequest()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode > http.StatusPartialContent {
+		defer resp.Body.Close() // nolint: errcheck
+		data, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, readErr
+		}
+		return nil, (&Response{body: data, statusCode: resp.StatusCode, header: resp.Header}).Error()
+	}
+	return resp.Body, nil
 }
 
 // prepareRequest build the HTTP request that #Do function will execute
@@ -197,7 +274,11 @@ func (r *Request) prepareRequest() (*http.Request, error) {
 
 	// set the default content type
 	if r.body != nil {
-		httpRequest.Header.Set("Content-Type", "application/json")
+		contentType := r.contentType
+		if len(contentType) == 0 {
+			contentType = "application/json"
+		}
+		httpRequest.Header.Set("Content-Type", contentType)
 	}
 
 	// set the accept content type
@@ -242,7 +323,7 @@ func (r *Request) url() (string, error) {
 }
 
 // buildPath builds the REST path according to a predefined ordering
-// /<api name>/<api version>[/<address>]/<resource type>[/<resource name>[/versions/<resource version>]][/<verb>]
+// /<api name>/<api version>[/<address>]/<resource type>[/<resource name>[/<sub-resource>[/<sub-resource name>]]]
 func (r *Request) buildPath() (string, error) {
 	var path strings.Builder
 
@@ -274,6 +355,22 @@ func (r *Request) buildPath() (string, error) {
 		path.WriteString(fmt.Sprintf("/%s", r.name))
 	}
 
+	// Sub-resource
+	if len(r.subResource) > 0 {
+		if len(r.name) <= 0 {
+			return "", errors.New("name cannot be empty when a sub-resource is set")
+		}
+		path.WriteString(fmt.Sprintf("/%s", r.subResource))
+	}
+
+	// Sub-resource name
+	if len(r.subResourceName) > 0 {
+		if len(r.subResource) <= 0 {
+			return "", errors.New("sub-resource cannot be empty when a sub-resource name is set")
+		}
+		path.WriteString(fmt.Sprintf("/%s", r.subResourceName))
+	}
+
 	return path.String(), nil
 }
 
@@ -305,6 +402,18 @@ func (re *RequestError) Unwrap() error {
 	return re.Err
 }
 
+// IsConflict returns true if the API responded with the status code 409 (Conflict).
+// It happens when the resource to create already exists, or when the resource to update has been modified since it has been retrieved.
+func (re *RequestError) IsConflict() bool {
+	return re.StatusCode == http.StatusConflict
+}
+
+// IsConflictError returns true if the error is (or is wrapping) a RequestError with the status code 409 (Conflict).
+func IsConflictError(err error) bool {
+	var requestErr *RequestError
+	return errors.As(err, &requestErr) && requestErr.IsConflict()
+}
+
 var (
 	RequestInternalError = &RequestError{Message: "internal server error", StatusCode: http.StatusInternalServerError}
 	RequestNotFoundError = &RequestError{Message: "document not found", StatusCode: http.StatusNotFound}
@@ -315,6 +424,15 @@ type Response struct {
 	body       []byte
 	err        error
 	statusCode int
+	header     http.Header
+}
+
+// Header returns the headers of the response. It is empty if the request failed before getting a response.
+func (r *Response) Header() http.Header {
+	if r.header == nil {
+		return make(http.Header)
+	}
+	return r.header
 }
 
 type errorResponse struct {
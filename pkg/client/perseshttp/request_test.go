@@ -14,6 +14,7 @@
 package perseshttp
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -79,6 +80,54 @@ func TestRequest_BuildPath(t *testing.T) {
 			expectedResult: "/api/v1/projects/perses/prometheusrules",
 			expectedError:  false,
 		},
+		{
+			title: "Path with a sub-resource",
+			request: &Request{
+				apiPrefix:   defaultAPIPrefix,
+				apiVersion:  defaultAPIVersion,
+				resource:    "trash",
+				name:        "dashboard-s2b0k1",
+				subResource: "restore",
+			},
+			expectedResult: "/api/v1/trash/dashboard-s2b0k1/restore",
+			expectedError:  false,
+		},
+		{
+			title: "Path with the name of a sub-resource",
+			request: &Request{
+				apiPrefix:       defaultAPIPrefix,
+				apiVersion:      defaultAPIVersion,
+				resource:        "serviceaccounts",
+				name:            "ci",
+				subResource:     "tokens",
+				subResourceName: "deploy",
+			},
+			expectedResult: "/api/v1/serviceaccounts/ci/tokens/deploy",
+			expectedError:  false,
+		},
+		{
+			title: "Sub-resource name without sub-resource",
+			request: &Request{
+				apiPrefix:       defaultAPIPrefix,
+				apiVersion:      defaultAPIVersion,
+				resource:        "serviceaccounts",
+				name:            "ci",
+				subResourceName: "deploy",
+			},
+			expectedResult: "",
+			expectedError:  true,
+		},
+		{
+			title: "Sub-resource without name",
+			request: &Request{
+				apiPrefix:   defaultAPIPrefix,
+				apiVersion:  defaultAPIVersion,
+				resource:    "trash",
+				subResource: "restore",
+			},
+			expectedResult: "",
+			expectedError:  true,
+		},
 	}
 	for _, test := range testSuites {
 		t.Run(test.title, func(t *testing.T) {
@@ -93,3 +142,42 @@ func TestRequest_BuildPath(t *testing.T) {
 
 	}
 }
+
+func TestResponse_Error(t *testing.T) {
+	testSuites := []struct {
+		title            string
+		response         *Response
+		expectedError    bool
+		expectedConflict bool
+	}{
+		{
+			title:         "successful response",
+			response:      &Response{statusCode: 200},
+			expectedError: false,
+		},
+		{
+			title:         "document not found",
+			response:      &Response{statusCode: 404},
+			expectedError: true,
+		},
+		{
+			title:            "version conflict",
+			response:         &Response{statusCode: 409, body: []byte(`{"message":"document has been modified in the meantime"}`)},
+			expectedError:    true,
+			expectedConflict: true,
+		},
+	}
+	for _, test := range testSuites {
+		t.Run(test.title, func(t *testing.T) {
+			err := test.response.Error()
+			if !test.expectedError {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			var requestErr *RequestError
+			assert.True(t, errors.As(err, &requestErr))
+			assert.Equal(t, test.expectedConflict, IsConflictError(err))
+		})
+	}
+}
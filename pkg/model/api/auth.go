@@ -0,0 +1,126 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Auth contains the credentials of a user logging in.
+type Auth struct {
+	Login    string `json:"login" yaml:"login"`
+	Password string `json:"password" yaml:"password"`
+}
+
+func (a *Auth) UnmarshalJSON(data []byte) error {
+	var tmp Auth
+	type plain Auth
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*a = tmp
+	return nil
+}
+
+func (a *Auth) validate() error {
+	if len(a.Login) == 0 {
+		return fmt.Errorf("login cannot be empty")
+	}
+	if len(a.Password) == 0 {
+		return fmt.Errorf("password cannot be empty")
+	}
+	return nil
+}
+
+// RefreshRequest contains the refresh token used to get a new access token.
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" yaml:"refreshToken"`
+}
+
+func (r *RefreshRequest) UnmarshalJSON(data []byte) error {
+	var tmp RefreshRequest
+	type plain RefreshRequest
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if len(tmp.RefreshToken) == 0 {
+		return fmt.Errorf("refreshToken cannot be empty")
+	}
+	*r = tmp
+	return nil
+}
+
+// AuthResponse contains the tokens given to an authenticated user. The access token is sent in the header Authorization of the requests,
+// and the refresh token is used to get a new access token once it has expired.
+type AuthResponse struct {
+	AccessToken  string `json:"accessToken" yaml:"accessToken"`
+	RefreshToken string `json:"refreshToken,omitempty" yaml:"refreshToken,omitempty"`
+}
+
+// AuthProviderKind is the kind of identity provider.
+type AuthProviderKind string
+
+const (
+	AuthProviderKindOIDC  AuthProviderKind = "oidc"
+	AuthProviderKindOAuth AuthProviderKind = "oauth"
+)
+
+// AuthProvider is an identity provider the users can log in with.
+type AuthProvider struct {
+	Kind   AuthProviderKind `json:"kind" yaml:"kind"`
+	SlugID string           `json:"slugID" yaml:"slugID"`
+	Name   string           `json:"name" yaml:"name"`
+}
+
+// DeviceCodeResponse starts the device authorization flow. The user opens the verification URI in a browser, logs in
+// and enters the user code, while the device polls the API with the device code to get its tokens.
+type DeviceCodeResponse struct {
+	DeviceCode      string `json:"deviceCode" yaml:"deviceCode"`
+	UserCode        string `json:"userCode" yaml:"userCode"`
+	VerificationURI string `json:"verificationURI" yaml:"verificationURI"`
+	// VerificationURIComplete contains the user code, so the user doesn't have to enter it. It is optional.
+	VerificationURIComplete string `json:"verificationURIComplete,omitempty" yaml:"verificationURIComplete,omitempty"`
+	// ExpiresIn is the number of seconds the device code is valid.
+	ExpiresIn int `json:"expiresIn" yaml:"expiresIn"`
+	// Interval is the number of seconds the device has to wait between two requests.
+	Interval int `json:"interval" yaml:"interval"`
+}
+
+// Errors returned by the API, with the status code 400, while the user hasn't approved the device yet.
+const (
+	DeviceAuthorizationPending = "authorization_pending"
+	DeviceSlowDown             = "slow_down"
+)
+
+// DeviceAccessTokenRequest contains the device code used to get the tokens of the user who approved the device.
+type DeviceAccessTokenRequest struct {
+	DeviceCode string `json:"deviceCode" yaml:"deviceCode"`
+}
+
+func (d *DeviceAccessTokenRequest) UnmarshalJSON(data []byte) error {
+	var tmp DeviceAccessTokenRequest
+	type plain DeviceAccessTokenRequest
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if len(tmp.DeviceCode) == 0 {
+		return fmt.Errorf("deviceCode cannot be empty")
+	}
+	*d = tmp
+	return nil
+}
@@ -0,0 +1,24 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+// PatchType is the content type of the body of a PATCH request. It tells how the patch is applied to the resource.
+type PatchType string
+
+const (
+	// MergePatchType is a JSON merge patch (RFC 7386). The fields of the patch replace the ones of the resource, and the fields set to null are removed.
+	MergePatchType PatchType = "application/merge-patch+json"
+	// JSONPatchType is a JSON patch (RFC 6902). It is a list of operations applied in order to the resource.
+	JSONPatchType PatchType = "application/json-patch+json"
+)
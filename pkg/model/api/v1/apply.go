@@ -0,0 +1,39 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// ApplyAction is what an apply does, or would do in a dry run, with a resource.
+type ApplyAction string
+
+const (
+	ApplyActionCreate    ApplyAction = "create"
+	ApplyActionUpdate    ApplyAction = "update"
+	ApplyActionUnchanged ApplyAction = "unchanged"
+)
+
+// ApplyResult is the outcome of an apply for one of the resources.
+type ApplyResult struct {
+	Kind Kind `json:"kind" yaml:"kind"`
+	// Project is empty for the global resources and for the projects.
+	Project string      `json:"project,omitempty" yaml:"project,omitempty"`
+	Name    string      `json:"name" yaml:"name"`
+	Action  ApplyAction `json:"action" yaml:"action"`
+}
+
+// ApplyResponse is returned by an apply. The results are in the order the resources are applied, the dependencies first.
+type ApplyResponse struct {
+	// DryRun is true when nothing has been written. The actions are then the ones that would be done.
+	DryRun  bool           `json:"dryRun" yaml:"dryRun"`
+	Results []*ApplyResult `json:"results" yaml:"results"`
+}
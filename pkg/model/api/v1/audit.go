@@ -0,0 +1,106 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	modelAPI "github.com/perses/perses/pkg/model/api"
+)
+
+// AuditResource identifies the resource modified.
+type AuditResource struct {
+	Kind Kind `json:"kind" yaml:"kind"`
+	// Project is empty for the global resources. For a project, it is the name of the project itself.
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+	Name    string `json:"name" yaml:"name"`
+}
+
+// AuditProxyRequest is a request sent to a datasource through the proxy.
+type AuditProxyRequest struct {
+	// Datasource is the name of the datasource, when it is defined in the dashboard of the resource.
+	Datasource string `json:"datasource,omitempty" yaml:"datasource,omitempty"`
+	Method     string `json:"method" yaml:"method"`
+	// Path is the path of the request sent to the datasource.
+	Path       string `json:"path" yaml:"path"`
+	StatusCode int    `json:"statusCode" yaml:"statusCode"`
+}
+
+// AuditRequest is a request modifying the resources, recorded without the detail of the changes.
+type AuditRequest struct {
+	Method     string `json:"method" yaml:"method"`
+	Path       string `json:"path" yaml:"path"`
+	StatusCode int    `json:"statusCode" yaml:"statusCode"`
+}
+
+type AuditRecordSpec struct {
+	Time time.Time `json:"time" yaml:"time"`
+	// Actor is who sent the request: a user or a service account. It is not set when the authentication is disabled.
+	Actor    *Subject `json:"actor,omitempty" yaml:"actor,omitempty"`
+	SourceIP string   `json:"sourceIP" yaml:"sourceIP"`
+	// Action is create, update or delete. It is not set for a request to a datasource, nor for a request whose changes are not detailed.
+	Action Action `json:"action,omitempty" yaml:"action,omitempty"`
+	// Resource is empty for a request whose changes are not detailed, when its path doesn't identify a resource.
+	Resource AuditResource `json:"resource" yaml:"resource"`
+	// OldVersion is the version of the resource before the change. It is not set for a creation.
+	OldVersion *uint64 `json:"oldVersion,omitempty" yaml:"oldVersion,omitempty"`
+	// NewVersion is the version of the resource after the change. It is not set for a deletion.
+	NewVersion *uint64 `json:"newVersion,omitempty" yaml:"newVersion,omitempty"`
+	// Diff is the JSON merge patch (RFC 7386) changing the old version of the resource into the new one.
+	// It is the whole resource for a creation, and null for a deletion.
+	Diff json.RawMessage `json:"diff,omitempty" yaml:"diff,omitempty"`
+	// Proxy is only set for a request sent to a datasource.
+	Proxy *AuditProxyRequest `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	// Request is only set for a request modifying the resources whose changes are not detailed by the API.
+	Request *AuditRequest `json:"request,omitempty" yaml:"request,omitempty"`
+}
+
+// AuditRecord is a change made with the API, or a request modifying a datasource sent through the proxy.
+type AuditRecord struct {
+	Kind     Kind            `json:"kind" yaml:"kind"`
+	Metadata Metadata        `json:"metadata" yaml:"metadata"`
+	Spec     AuditRecordSpec `json:"spec" yaml:"spec"`
+}
+
+// NewAuditRecord returns a record of a change of the resource made now.
+// The name of the record is generated from the kind of the resource and from the time, like the entries of the trash.
+func NewAuditRecord(resource AuditResource) *AuditRecord {
+	now := time.Now().UTC()
+	record := &AuditRecord{
+		Kind:     KindAuditRecord,
+		Metadata: *NewMetadata(fmt.Sprintf("%s-%s", strings.ToLower(string(resource.Kind)), strconv.FormatInt(now.UnixNano(), 36))),
+		Spec: AuditRecordSpec{
+			Time:     now,
+			Resource: resource,
+		},
+	}
+	record.Metadata.CreateNow()
+	return record
+}
+
+func (a *AuditRecord) GetMetadata() modelAPI.Metadata {
+	return &a.Metadata
+}
+
+func (a *AuditRecord) GetKind() string {
+	return string(a.Kind)
+}
+
+func (a *AuditRecord) GetSpec() interface{} {
+	return a.Spec
+}
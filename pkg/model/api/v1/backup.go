@@ -0,0 +1,76 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BackupVersion is the version of the format of the backup archives. It changes each time the format is changed in a way
+// that an older version of Perses cannot read it.
+const BackupVersion = 1
+
+type BackupEncryptionMode string
+
+const (
+	// BackupEncryptionServerKey means the secrets are encrypted with the encryption key of the server that exported them.
+	// The archive can only be restored by a server using the same key.
+	BackupEncryptionServerKey BackupEncryptionMode = "serverKey"
+	// BackupEncryptionPassphrase means the secrets are encrypted with a key derived from a passphrase.
+	// The archive can be restored by any server, as long as the passphrase is provided.
+	BackupEncryptionPassphrase BackupEncryptionMode = "passphrase"
+)
+
+// BackupEncryption describes how the secrets of a backup archive are encrypted.
+type BackupEncryption struct {
+	Mode BackupEncryptionMode `json:"mode" yaml:"mode"`
+	// Salt is used with the passphrase to derive the key. It is only set when the mode is "passphrase".
+	Salt []byte `json:"salt,omitempty" yaml:"salt,omitempty"`
+	// KeyFingerprint identifies the key used to encrypt the secrets,
+	// so a wrong key or a wrong passphrase is detected before anything is restored.
+	KeyFingerprint string `json:"keyFingerprint" yaml:"keyFingerprint"`
+}
+
+// BackupHeader is the first record of a backup archive.
+type BackupHeader struct {
+	Version    int              `json:"version" yaml:"version"`
+	CreatedAt  time.Time        `json:"createdAt" yaml:"createdAt"`
+	Encryption BackupEncryption `json:"encryption" yaml:"encryption"`
+}
+
+// BackupFooter is the last record of a backup archive. It is the marker telling the archive is complete:
+// an archive without footer, or whose resources don't match the footer, is rejected.
+type BackupFooter struct {
+	// Resources is the number of resources of the archive, by kind.
+	Resources map[Kind]int `json:"resources" yaml:"resources"`
+	// Checksum is the SHA-256 of the resource records, as they are written in the archive.
+	Checksum string `json:"checksum" yaml:"checksum"`
+}
+
+// BackupRecord is a line of a backup archive. Exactly one of its fields is set.
+// An archive is a stream of records compressed with gzip: the header, then one record per resource, and finally the footer.
+type BackupRecord struct {
+	Header   *BackupHeader   `json:"header,omitempty" yaml:"header,omitempty"`
+	Resource json.RawMessage `json:"resource,omitempty" yaml:"resource,omitempty"`
+	Footer   *BackupFooter   `json:"footer,omitempty" yaml:"footer,omitempty"`
+}
+
+// BackupSummary is returned once a backup archive has been restored.
+type BackupSummary struct {
+	// CreatedAt is the date the archive has been created.
+	CreatedAt time.Time `json:"createdAt" yaml:"createdAt"`
+	// Resources is the number of resources restored, by kind.
+	Resources map[Kind]int `json:"resources" yaml:"resources"`
+}
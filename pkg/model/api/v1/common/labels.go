@@ -0,0 +1,232 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	labelNameMaxLength   = 63
+	labelPrefixMaxLength = 253
+	// annotationsMaxSize is the maximum size of all the keys and the values of the annotations of a resource.
+	annotationsMaxSize = 256 * 1024
+)
+
+var (
+	labelNameRegexp   = regexp.MustCompile(`^[a-zA-Z0-9]([-a-zA-Z0-9_.]*[a-zA-Z0-9])?$`)
+	labelPrefixRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// ValidateLabelKey checks the key of a label or of an annotation. Like in Kubernetes, the key is a name, optionally prefixed by a DNS subdomain and a slash.
+// For example: "team" or "perses.dev/team".
+func ValidateLabelKey(key string) error {
+	prefix, name, hasPrefix := strings.Cut(key, "/")
+	if hasPrefix {
+		if len(prefix) == 0 || len(prefix) > labelPrefixMaxLength || !labelPrefixRegexp.MatchString(prefix) {
+			return fmt.Errorf("the prefix of the key %q must be a DNS subdomain of at most %d characters", key, labelPrefixMaxLength)
+		}
+	} else {
+		name = prefix
+	}
+	if len(name) == 0 || len(name) > labelNameMaxLength || !labelNameRegexp.MatchString(name) {
+		return fmt.Errorf("the key %q is not valid, the name must contain at most %d characters and match the regexp %s", key, labelNameMaxLength, labelNameRegexp.String())
+	}
+	return nil
+}
+
+// ValidateLabelValue checks the value of a label. It can be empty.
+func ValidateLabelValue(value string) error {
+	if len(value) == 0 {
+		return nil
+	}
+	if len(value) > labelNameMaxLength || !labelNameRegexp.MatchString(value) {
+		return fmt.Errorf("the label value %q is not valid, it must contain at most %d characters and match the regexp %s", value, labelNameMaxLength, labelNameRegexp.String())
+	}
+	return nil
+}
+
+// ValidateLabels checks every key and every value of the labels.
+func ValidateLabels(labels map[string]string) error {
+	for key, value := range labels {
+		if err := ValidateLabelKey(key); err != nil {
+			return fmt.Errorf("invalid label: %w", err)
+		}
+		if err := ValidateLabelValue(value); err != nil {
+			return fmt.Errorf("invalid label %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// ValidateAnnotations checks every key of the annotations. The values are free, but the total size of the annotations is limited.
+func ValidateAnnotations(annotations map[string]string) error {
+	size := 0
+	for key, value := range annotations {
+		if err := ValidateLabelKey(key); err != nil {
+			return fmt.Errorf("invalid annotation: %w", err)
+		}
+		size += len(key) + len(value)
+	}
+	if size > annotationsMaxSize {
+		return fmt.Errorf("the annotations cannot exceed %d bytes", annotationsMaxSize)
+	}
+	return nil
+}
+
+type SelectorOperator string
+
+const (
+	SelectorOperatorEquals       SelectorOperator = "="
+	SelectorOperatorNotEquals    SelectorOperator = "!="
+	SelectorOperatorIn           SelectorOperator = "in"
+	SelectorOperatorNotIn        SelectorOperator = "notin"
+	SelectorOperatorExists       SelectorOperator = "exists"
+	SelectorOperatorDoesNotExist SelectorOperator = "!"
+)
+
+// LabelRequirement is a condition on a single label.
+type LabelRequirement struct {
+	Key      string
+	Operator SelectorOperator
+	// Values contains one value for the operators = and !=, at least one for in and notin, and none for the others.
+	Values []string
+}
+
+// Matches returns true if the labels fulfill the requirement.
+// Like in Kubernetes, a label that is not set fulfills the operators != and notin.
+func (r LabelRequirement) Matches(labels map[string]string) bool {
+	value, exists := labels[r.Key]
+	switch r.Operator {
+	case SelectorOperatorExists:
+		return exists
+	case SelectorOperatorDoesNotExist:
+		return !exists
+	case SelectorOperatorEquals, SelectorOperatorIn:
+		return exists && r.hasValue(value)
+	case SelectorOperatorNotEquals, SelectorOperatorNotIn:
+		return !exists || !r.hasValue(value)
+	default:
+		return false
+	}
+}
+
+func (r LabelRequirement) hasValue(value string) bool {
+	for _, v := range r.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// LabelSelector is a list of requirements that must all be fulfilled. An empty selector matches everything.
+type LabelSelector []LabelRequirement
+
+// Matches returns true if the labels fulfill every requirement of the selector.
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, requirement := range s {
+		if !requirement.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	existenceRequirementRegexp = regexp.MustCompile(`^(!?)\s*([^\s=!(),]+)$`)
+	equalityRequirementRegexp  = regexp.MustCompile(`^([^\s=!(),]+)\s*(==|=|!=)\s*([^\s=!(),]*)$`)
+	setRequirementRegexp       = regexp.MustCompile(`^([^\s=!(),]+)\s+(in|notin)\s*\(([^()]*)\)$`)
+)
+
+// ParseLabelSelector parses a comma-separated list of requirements. The following requirements are supported:
+//   - "key" and "!key" to check whether the label is set.
+//   - "key=value" (or "key==value") and "key!=value".
+//   - "key in (value1,value2)" and "key notin (value1,value2)".
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	var result LabelSelector
+	for _, raw := range splitRequirements(selector) {
+		raw = strings.TrimSpace(raw)
+		if len(raw) == 0 {
+			if len(strings.TrimSpace(selector)) == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("invalid label selector %q: empty requirement", selector)
+		}
+		requirement, err := parseRequirement(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %w", selector, err)
+		}
+		result = append(result, requirement)
+	}
+	return result, nil
+}
+
+// splitRequirements splits the selector on the commas that are not between parentheses.
+func splitRequirements(selector string) []string {
+	var result []string
+	depth := 0
+	start := 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(result, selector[start:])
+}
+
+func parseRequirement(raw string) (LabelRequirement, error) {
+	var requirement LabelRequirement
+	if matches := setRequirementRegexp.FindStringSubmatch(raw); matches != nil {
+		requirement.Key = matches[1]
+		requirement.Operator = SelectorOperator(matches[2])
+		for _, value := range strings.Split(matches[3], ",") {
+			requirement.Values = append(requirement.Values, strings.TrimSpace(value))
+		}
+	} else if matches = equalityRequirementRegexp.FindStringSubmatch(raw); matches != nil {
+		requirement.Key = matches[1]
+		requirement.Operator = SelectorOperatorEquals
+		if matches[2] == string(SelectorOperatorNotEquals) {
+			requirement.Operator = SelectorOperatorNotEquals
+		}
+		requirement.Values = []string{matches[3]}
+	} else if matches = existenceRequirementRegexp.FindStringSubmatch(raw); matches != nil {
+		requirement.Key = matches[2]
+		requirement.Operator = SelectorOperatorExists
+		if len(matches[1]) > 0 {
+			requirement.Operator = SelectorOperatorDoesNotExist
+		}
+	} else {
+		return requirement, fmt.Errorf("unable to parse the requirement %q", raw)
+	}
+	if err := ValidateLabelKey(requirement.Key); err != nil {
+		return requirement, err
+	}
+	for _, value := range requirement.Values {
+		if err := ValidateLabelValue(value); err != nil {
+			return requirement, err
+		}
+	}
+	return requirement, nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLabels(t *testing.T) {
+	testSuite := []struct {
+		title   string
+		labels  map[string]string
+		isValid bool
+	}{
+		{
+			title:   "simple labels",
+			labels:  map[string]string{"team": "sre", "tier": ""},
+			isValid: true,
+		},
+		{
+			title:   "key with a prefix",
+			labels:  map[string]string{"perses.dev/team": "sre"},
+			isValid: true,
+		},
+		{
+			title:   "empty key",
+			labels:  map[string]string{"": "sre"},
+			isValid: false,
+		},
+		{
+			title:   "empty prefix",
+			labels:  map[string]string{"/team": "sre"},
+			isValid: false,
+		},
+		{
+			title:   "invalid prefix",
+			labels:  map[string]string{"Perses_dev/team": "sre"},
+			isValid: false,
+		},
+		{
+			title:   "name too long",
+			labels:  map[string]string{strings.Repeat("a", 64): "sre"},
+			isValid: false,
+		},
+		{
+			title:   "invalid value",
+			labels:  map[string]string{"team": "sre team"},
+			isValid: false,
+		},
+		{
+			title:   "value not ending with an alphanumeric character",
+			labels:  map[string]string{"team": "sre-"},
+			isValid: false,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			err := ValidateLabels(test.labels)
+			assert.Equal(t, test.isValid, err == nil, err)
+		})
+	}
+}
+
+func TestValidateAnnotations(t *testing.T) {
+	assert.NoError(t, ValidateAnnotations(map[string]string{"perses.dev/description": "a value with spaces, and: symbols"}))
+	assert.Error(t, ValidateAnnotations(map[string]string{"invalid key": "value"}))
+	assert.Error(t, ValidateAnnotations(map[string]string{"description": strings.Repeat("a", annotationsMaxSize)}))
+}
+
+func TestParseLabelSelector(t *testing.T) {
+	testSuite := []struct {
+		title    string
+		selector string
+		result   LabelSelector
+	}{
+		{
+			title:    "empty selector",
+			selector: " ",
+			result:   nil,
+		},
+		{
+			title:    "equality",
+			selector: "team=sre,tier!=dev, env == prod",
+			result: LabelSelector{
+				{Key: "team", Operator: SelectorOperatorEquals, Values: []string{"sre"}},
+				{Key: "tier", Operator: SelectorOperatorNotEquals, Values: []string{"dev"}},
+				{Key: "env", Operator: SelectorOperatorEquals, Values: []string{"prod"}},
+			},
+		},
+		{
+			title:    "set based",
+			selector: "team in (sre, dev),perses.dev/tier notin (frontend)",
+			result: LabelSelector{
+				{Key: "team", Operator: SelectorOperatorIn, Values: []string{"sre", "dev"}},
+				{Key: "perses.dev/tier", Operator: SelectorOperatorNotIn, Values: []string{"frontend"}},
+			},
+		},
+		{
+			title:    "existence",
+			selector: "team,!tier",
+			result: LabelSelector{
+				{Key: "team", Operator: SelectorOperatorExists},
+				{Key: "tier", Operator: SelectorOperatorDoesNotExist},
+			},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			result, err := ParseLabelSelector(test.selector)
+			assert.NoError(t, err)
+			assert.Equal(t, test.result, result)
+		})
+	}
+}
+
+func TestParseLabelSelectorError(t *testing.T) {
+	for _, selector := range []string{
+		"team=sre,",
+		"team=sre=dev",
+		"team in sre",
+		"team in (sre",
+		"team is sre",
+		"te am=sre",
+		"team=s re",
+	} {
+		t.Run(selector, func(t *testing.T) {
+			_, err := ParseLabelSelector(selector)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLabelSelectorMatches(t *testing.T) {
+	labels := map[string]string{"team": "sre", "tier": "backend"}
+	testSuite := []struct {
+		selector string
+		matches  bool
+	}{
+		{selector: "", matches: true},
+		{selector: "team=sre", matches: true},
+		{selector: "team=dev", matches: false},
+		{selector: "team=sre,tier!=dev", matches: true},
+		{selector: "team=sre,tier!=backend", matches: false},
+		{selector: "env!=prod", matches: true},
+		{selector: "team in (dev,sre)", matches: true},
+		{selector: "env in (prod)", matches: false},
+		{selector: "tier notin (frontend)", matches: true},
+		{selector: "env notin (prod)", matches: true},
+		{selector: "team", matches: true},
+		{selector: "!team", matches: false},
+		{selector: "!env", matches: true},
+	}
+	for _, test := range testSuite {
+		t.Run(test.selector, func(t *testing.T) {
+			selector, err := ParseLabelSelector(test.selector)
+			assert.NoError(t, err)
+			assert.Equal(t, test.matches, selector.Matches(labels))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	modelAPI "github.com/perses/perses/pkg/model/api"
+)
+
+// RevisionMetadata is the metadata of a revision.
+// Name, Project and Version are the ones of the dashboard at the time the revision has been saved.
+type RevisionMetadata struct {
+	ProjectMetadata `json:",inline" yaml:",inline"`
+	// Author is the name of the user that saved the dashboard. It is empty when the author is unknown.
+	Author string `json:"author,omitempty" yaml:"author,omitempty"`
+}
+
+func (m *RevisionMetadata) GetName() string {
+	return m.Name
+}
+
+// DashboardRevision is a snapshot of a dashboard. A new one is saved each time a dashboard is created or updated.
+type DashboardRevision struct {
+	Kind     Kind             `json:"kind" yaml:"kind"`
+	Metadata RevisionMetadata `json:"metadata" yaml:"metadata"`
+	Spec     DashboardSpec    `json:"spec" yaml:"spec"`
+}
+
+func NewDashboardRevision(dashboard *Dashboard, author string) *DashboardRevision {
+	return &DashboardRevision{
+		Kind: KindDashboardRevision,
+		Metadata: RevisionMetadata{
+			ProjectMetadata: dashboard.Metadata,
+			Author:          author,
+		},
+		Spec: dashboard.Spec,
+	}
+}
+
+func (d *DashboardRevision) GetMetadata() modelAPI.Metadata {
+	return &d.Metadata
+}
+
+func (d *DashboardRevision) GetKind() string {
+	return string(d.Kind)
+}
+
+func (d *DashboardRevision) GetSpec() interface{} {
+	return d.Spec
+}
+
+// ToDashboard returns the dashboard as it was when the revision has been saved.
+func (d *DashboardRevision) ToDashboard() *Dashboard {
+	return &Dashboard{
+		Kind:     KindDashboard,
+		Metadata: d.Metadata.ProjectMetadata,
+		Spec:     d.Spec,
+	}
+}
@@ -23,39 +23,94 @@ import (
 type Kind string
 
 const (
-	KindDashboard        Kind = "Dashboard"
-	KindDatasource       Kind = "Datasource"
-	KindFolder           Kind = "Folder"
-	KindGlobalDatasource Kind = "GlobalDatasource"
-	KindGlobalVariable   Kind = "GlobalVariable"
-	KindGlobalSecret     Kind = "GlobalSecret"
-	KindProject          Kind = "Project"
-	KindSecret           Kind = "Secret"
-	KindVariable         Kind = "Variable"
+	KindAuditRecord       Kind = "AuditRecord"
+	KindDashboard         Kind = "Dashboard"
+	KindDashboardRevision Kind = "DashboardRevision"
+	KindDatasource        Kind = "Datasource"
+	KindFolder            Kind = "Folder"
+	KindGlobalDatasource  Kind = "GlobalDatasource"
+	KindGlobalVariable    Kind = "GlobalVariable"
+	KindGlobalRole        Kind = "GlobalRole"
+	KindGlobalRoleBinding Kind = "GlobalRoleBinding"
+	KindGlobalSecret      Kind = "GlobalSecret"
+	KindGlobalWebhook     Kind = "GlobalWebhook"
+	KindProject           Kind = "Project"
+	KindRole              Kind = "Role"
+	KindRoleBinding       Kind = "RoleBinding"
+	KindSecret            Kind = "Secret"
+	KindServiceAccount    Kind = "ServiceAccount"
+	KindTrashEntry        Kind = "TrashEntry"
+	KindUser              Kind = "User"
+	KindVariable          Kind = "Variable"
+	KindWebhook           Kind = "Webhook"
+	KindWebhookDelivery   Kind = "WebhookDelivery"
 )
 
+// KindMap contains the kinds of the resources managed by the users. Only these kinds can be the scope of a permission,
+// or be created from a file with percli.
 var KindMap = map[Kind]bool{
-	KindDashboard:        true,
-	KindDatasource:       true,
-	KindFolder:           true,
-	KindGlobalDatasource: true,
-	KindGlobalSecret:     true,
-	KindGlobalVariable:   true,
-	KindProject:          true,
-	KindSecret:           true,
-	KindVariable:         true,
+	KindDashboard:         true,
+	KindDatasource:        true,
+	KindFolder:            true,
+	KindGlobalDatasource:  true,
+	KindGlobalRole:        true,
+	KindGlobalRoleBinding: true,
+	KindGlobalSecret:      true,
+	KindGlobalVariable:    true,
+	KindGlobalWebhook:     true,
+	KindProject:           true,
+	KindRole:              true,
+	KindRoleBinding:       true,
+	KindSecret:            true,
+	KindServiceAccount:    true,
+	KindUser:              true,
+	KindVariable:          true,
+	KindWebhook:           true,
+}
+
+// InternalKindMap contains the kinds of the resources created by Perses itself, like the revisions of a dashboard or the audit log.
+// They can be read with the API, but they are not managed by the users.
+var InternalKindMap = map[Kind]bool{
+	KindAuditRecord:       true,
+	KindDashboardRevision: true,
+	KindTrashEntry:        true,
+	KindWebhookDelivery:   true,
 }
 
 var PluralKindMap = map[Kind]string{
-	KindDashboard:        "dashboards",
-	KindDatasource:       "datasources",
-	KindFolder:           "folders",
-	KindGlobalDatasource: "globaldatasources",
-	KindGlobalSecret:     "globalsecrets",
-	KindGlobalVariable:   "globalvariables",
-	KindProject:          "projects",
-	KindSecret:           "secrets",
-	KindVariable:         "variables",
+	KindAuditRecord:       "audit",
+	KindDashboard:         "dashboards",
+	KindDashboardRevision: "dashboardrevisions",
+	KindDatasource:        "datasources",
+	KindFolder:            "folders",
+	KindGlobalDatasource:  "globaldatasources",
+	KindGlobalRole:        "globalroles",
+	KindGlobalRoleBinding: "globalrolebindings",
+	KindGlobalSecret:      "globalsecrets",
+	KindGlobalVariable:    "globalvariables",
+	KindGlobalWebhook:     "globalwebhooks",
+	KindProject:           "projects",
+	KindRole:              "roles",
+	KindRoleBinding:       "rolebindings",
+	KindSecret:            "secrets",
+	KindServiceAccount:    "serviceaccounts",
+	KindTrashEntry:        "trash",
+	KindUser:              "users",
+	KindVariable:          "variables",
+	KindWebhook:           "webhooks",
+	KindWebhookDelivery:   "webhookdeliveries",
+}
+
+// ProjectKindMap contains the kinds of KindMap belonging to a project.
+var ProjectKindMap = map[Kind]bool{
+	KindDashboard:   true,
+	KindDatasource:  true,
+	KindFolder:      true,
+	KindRole:        true,
+	KindRoleBinding: true,
+	KindSecret:      true,
+	KindVariable:    true,
+	KindWebhook:     true,
 }
 
 func (k *Kind) UnmarshalJSON(data []byte) error {
@@ -88,13 +143,14 @@ func (k *Kind) validate() error {
 	if len(*k) == 0 {
 		return fmt.Errorf("kind cannot be empty")
 	}
-	if _, ok := KindMap[*k]; !ok {
+	if !KindMap[*k] && !InternalKindMap[*k] {
 		return fmt.Errorf("unknown kind %q used", *k)
 	}
 	return nil
 }
 
 // GetStruct return a pointer to an empty struct that matches the kind passed as a parameter.
+// The internal kinds have no associated struct, as they cannot be created by the users.
 func GetStruct(kind Kind) (modelAPI.Entity, error) {
 	switch kind {
 	case KindDashboard:
@@ -105,17 +161,50 @@ func GetStruct(kind Kind) (modelAPI.Entity, error) {
 		return &Folder{}, nil
 	case KindGlobalDatasource:
 		return &GlobalDatasource{}, nil
+	case KindGlobalRole:
+		return &GlobalRole{}, nil
+	case KindGlobalRoleBinding:
+		return &GlobalRoleBinding{}, nil
 	case KindGlobalSecret:
 		return &GlobalSecret{}, nil
 	case KindGlobalVariable:
 		return &GlobalVariable{}, nil
+	case KindGlobalWebhook:
+		return &GlobalWebhook{}, nil
 	case KindProject:
 		return &Project{}, nil
+	case KindRole:
+		return &Role{}, nil
+	case KindRoleBinding:
+		return &RoleBinding{}, nil
 	case KindSecret:
 		return &Secret{}, nil
+	case KindServiceAccount:
+		return &ServiceAccount{}, nil
+	case KindUser:
+		return &User{}, nil
 	case KindVariable:
 		return &Variable{}, nil
+	case KindWebhook:
+		return &Webhook{}, nil
 	default:
 		return nil, fmt.Errorf("%q has no associated struct", kind)
 	}
 }
+
+// GetInternalStruct is like GetStruct, but it also accepts the internal kinds.
+// It is only meant to read the resources stored by Perses itself, like the content of a backup.
+func GetInternalStruct(kind Kind) (modelAPI.Entity, error) {
+	switch kind {
+	case KindAuditRecord:
+		return &AuditRecord{}, nil
+	case KindDashboardRevision:
+		return &DashboardRevision{}, nil
+	case KindTrashEntry:
+		return &TrashEntry{}, nil
+	case KindWebhookDelivery:
+		return &WebhookDelivery{}, nil
+	default:
+		return GetStruct(kind)
+	}
+}
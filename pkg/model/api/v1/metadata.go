@@ -28,12 +28,17 @@ type Metadata struct {
 	CreatedAt time.Time `json:"createdAt" yaml:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt" yaml:"updatedAt"`
 	Version   uint64    `json:"version" yaml:"version"`
+	// Labels are used to organize the resources and to select them when listing them.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// Annotations are used to attach arbitrary information to the resource. Contrary to the labels, they cannot be used to select the resources.
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
 }
 
 func (m *Metadata) CreateNow() {
 	m.CreatedAt = time.Now().UTC()
 	m.UpdatedAt = m.CreatedAt
-	m.Version = 0
+	// the version starts at 1, so a version equal to 0 means a client didn't send it.
+	m.Version = 1
 }
 
 func (m *Metadata) Update(previous Metadata) {
@@ -49,6 +54,10 @@ func (m *Metadata) GetName() string {
 	return m.Name
 }
 
+func (m *Metadata) GetLabels() map[string]string {
+	return m.Labels
+}
+
 func NewProjectMetadata(project string, name string) *ProjectMetadata {
 	return &ProjectMetadata{
 		Metadata: Metadata{
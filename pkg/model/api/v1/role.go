@@ -0,0 +1,293 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	modelAPI "github.com/perses/perses/pkg/model/api"
+)
+
+// Action is what a permission allows to do with the resources.
+type Action string
+
+const (
+	ActionRead   Action = "read"
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	// ActionWildcard allows every action.
+	ActionWildcard Action = "*"
+)
+
+func (a *Action) UnmarshalJSON(data []byte) error {
+	var tmp Action
+	type plain Action
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*a = tmp
+	return nil
+}
+
+func (a *Action) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var tmp Action
+	type plain Action
+	if err := unmarshal((*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*a = tmp
+	return nil
+}
+
+func (a *Action) validate() error {
+	switch *a {
+	case ActionRead, ActionCreate, ActionUpdate, ActionDelete, ActionWildcard:
+		return nil
+	default:
+		return fmt.Errorf("unknown action %q, it can only be %q, %q, %q, %q or %q", *a, ActionRead, ActionCreate, ActionUpdate, ActionDelete, ActionWildcard)
+	}
+}
+
+// Scope is the kind of the resources a permission applies to.
+type Scope string
+
+// ScopeWildcard applies the permission to every kind.
+const ScopeWildcard Scope = "*"
+
+func (s *Scope) UnmarshalJSON(data []byte) error {
+	var tmp Scope
+	type plain Scope
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*s = tmp
+	return nil
+}
+
+func (s *Scope) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var tmp Scope
+	type plain Scope
+	if err := unmarshal((*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*s = tmp
+	return nil
+}
+
+func (s *Scope) validate() error {
+	if *s == ScopeWildcard {
+		return nil
+	}
+	// the internal kinds cannot be a scope. The revisions of a dashboard are covered by the permissions on the dashboard,
+	// the deliveries of a webhook by the ones on the webhook, and the audit log and the trash by the wildcard scope.
+	if !KindMap[Kind(*s)] {
+		return fmt.Errorf("unknown scope %q, it must be a kind or %q", *s, ScopeWildcard)
+	}
+	return nil
+}
+
+// Permission allows the actions on the resources of the kinds given by the scopes.
+type Permission struct {
+	Actions []Action `json:"actions" yaml:"actions"`
+	Scopes  []Scope  `json:"scopes" yaml:"scopes"`
+}
+
+func (p *Permission) UnmarshalJSON(data []byte) error {
+	var tmp Permission
+	type plain Permission
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*p = tmp
+	return nil
+}
+
+func (p *Permission) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var tmp Permission
+	type plain Permission
+	if err := unmarshal((*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*p = tmp
+	return nil
+}
+
+func (p *Permission) validate() error {
+	if len(p.Actions) == 0 {
+		return fmt.Errorf("the actions of a permission cannot be empty")
+	}
+	if len(p.Scopes) == 0 {
+		return fmt.Errorf("the scopes of a permission cannot be empty")
+	}
+	return nil
+}
+
+// Allows returns true if the permission allows the action on the kind. ActionWildcard and ScopeWildcard only match a permission containing them.
+func (p *Permission) Allows(action Action, scope Scope) bool {
+	return containsAction(p.Actions, action) && containsScope(p.Scopes, scope)
+}
+
+func containsAction(actions []Action, action Action) bool {
+	for _, a := range actions {
+		if a == ActionWildcard || a == action {
+			return true
+		}
+	}
+	return false
+}
+
+func containsScope(scopes []Scope, scope Scope) bool {
+	for _, s := range scopes {
+		if s == ScopeWildcard || s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type RoleSpec struct {
+	Permissions []Permission `json:"permissions" yaml:"permissions"`
+}
+
+// Role contains the permissions given in its project to the users bound to it by a RoleBinding.
+// Its scopes can only be the kinds of the resources belonging to a project, and Project to manage the project itself.
+type Role struct {
+	Kind     Kind            `json:"kind" yaml:"kind"`
+	Metadata ProjectMetadata `json:"metadata" yaml:"metadata"`
+	Spec     RoleSpec        `json:"spec" yaml:"spec"`
+}
+
+func (r *Role) GetMetadata() modelAPI.Metadata {
+	return &r.Metadata
+}
+
+func (r *Role) GetKind() string {
+	return string(r.Kind)
+}
+
+func (r *Role) GetSpec() interface{} {
+	return r.Spec
+}
+
+func (r *Role) UnmarshalJSON(data []byte) error {
+	var tmp Role
+	type plain Role
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*r = tmp
+	return nil
+}
+
+func (r *Role) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var tmp Role
+	type plain Role
+	if err := unmarshal((*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*r = tmp
+	return nil
+}
+
+func (r *Role) validate() error {
+	if r.Kind != KindRole {
+		return fmt.Errorf("invalid kind: %q for a Role type", r.Kind)
+	}
+	for _, permission := range r.Spec.Permissions {
+		for _, scope := range permission.Scopes {
+			if scope != ScopeWildcard && scope != Scope(KindProject) && !ProjectKindMap[Kind(scope)] {
+				return fmt.Errorf("the scope %q cannot be used in a Role, it is not the kind of a resource belonging to a project", scope)
+			}
+		}
+	}
+	return nil
+}
+
+// GlobalRole contains the permissions given in every project, and on the global resources, to the users bound to it by a GlobalRoleBinding.
+type GlobalRole struct {
+	Kind     Kind     `json:"kind" yaml:"kind"`
+	Metadata Metadata `json:"metadata" yaml:"metadata"`
+	Spec     RoleSpec `json:"spec" yaml:"spec"`
+}
+
+func (g *GlobalRole) GetMetadata() modelAPI.Metadata {
+	return &g.Metadata
+}
+
+func (g *GlobalRole) GetKind() string {
+	return string(g.Kind)
+}
+
+func (g *GlobalRole) GetSpec() interface{} {
+	return g.Spec
+}
+
+func (g *GlobalRole) UnmarshalJSON(data []byte) error {
+	var tmp GlobalRole
+	type plain GlobalRole
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*g = tmp
+	return nil
+}
+
+func (g *GlobalRole) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var tmp GlobalRole
+	type plain GlobalRole
+	if err := unmarshal((*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*g = tmp
+	return nil
+}
+
+func (g *GlobalRole) validate() error {
+	if g.Kind != KindGlobalRole {
+		return fmt.Errorf("invalid kind: %q for a GlobalRole type", g.Kind)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalRoleError(t *testing.T) {
+	testSuite := []struct {
+		title string
+		jason string
+		err   error
+	}{
+		{
+			title: "unknown action",
+			jason: `
+{
+  "kind": "Role",
+  "metadata": {
+    "name": "editor",
+    "project": "perses"
+  },
+  "spec": {
+    "permissions": [
+      {
+        "actions": ["write"],
+        "scopes": ["Dashboard"]
+      }
+    ]
+  }
+}
+`,
+			err: fmt.Errorf("unknown action \"write\", it can only be \"read\", \"create\", \"update\", \"delete\" or \"*\""),
+		},
+		{
+			title: "unknown scope",
+			jason: `
+{
+  "kind": "Role",
+  "metadata": {
+    "name": "editor",
+    "project": "perses"
+  },
+  "spec": {
+    "permissions": [
+      {
+        "actions": ["read"],
+        "scopes": ["DashboardRevision"]
+      }
+    ]
+  }
+}
+`,
+			err: fmt.Errorf("unknown scope \"DashboardRevision\", it must be a kind or \"*\""),
+		},
+		{
+			title: "internal kind as scope",
+			jason: `
+{
+  "kind": "Role",
+  "metadata": {
+    "name": "auditor",
+    "project": "perses"
+  },
+  "spec": {
+    "permissions": [
+      {
+        "actions": ["read"],
+        "scopes": ["AuditRecord"]
+      }
+    ]
+  }
+}
+`,
+			err: fmt.Errorf("unknown scope \"AuditRecord\", it must be a kind or \"*\""),
+		},
+		{
+			title: "scopes cannot be empty",
+			jason: `
+{
+  "kind": "Role",
+  "metadata": {
+    "name": "editor",
+    "project": "perses"
+  },
+  "spec": {
+    "permissions": [
+      {
+        "actions": ["read"]
+      }
+    ]
+  }
+}
+`,
+			err: fmt.Errorf("the scopes of a permission cannot be empty"),
+		},
+		{
+			title: "global scope in a role",
+			jason: `
+{
+  "kind": "Role",
+  "metadata": {
+    "name": "editor",
+    "project": "perses"
+  },
+  "spec": {
+    "permissions": [
+      {
+        "actions": ["read"],
+        "scopes": ["Dashboard", "GlobalDatasource"]
+      }
+    ]
+  }
+}
+`,
+			err: fmt.Errorf("the scope \"GlobalDatasource\" cannot be used in a Role, it is not the kind of a resource belonging to a project"),
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			result := Role{}
+			assert.Equal(t, test.err, json.Unmarshal([]byte(test.jason), &result))
+		})
+	}
+}
+
+func TestUnmarshalRoleBindingError(t *testing.T) {
+	testSuite := []struct {
+		title string
+		jason string
+		err   error
+	}{
+		{
+			title: "role cannot be empty",
+			jason: `
+{
+  "kind": "RoleBinding",
+  "metadata": {
+    "name": "editors",
+    "project": "perses"
+  },
+  "spec": {
+    "subjects": [{"kind": "User", "name": "john"}]
+  }
+}
+`,
+			err: fmt.Errorf("the role of a binding cannot be empty"),
+		},
+		{
+			title: "unknown kind of subject",
+			jason: `
+{
+  "kind": "RoleBinding",
+  "metadata": {
+    "name": "editors",
+    "project": "perses"
+  },
+  "spec": {
+    "role": "editor",
+    "subjects": [{"kind": "Team", "name": "sre"}]
+  }
+}
+`,
+			err: fmt.Errorf("the kind of a subject can only be \"User\", \"Group\" or \"ServiceAccount\", not \"Team\""),
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			result := RoleBinding{}
+			assert.Equal(t, test.err, json.Unmarshal([]byte(test.jason), &result))
+		})
+	}
+}
+
+func TestPermissionAllows(t *testing.T) {
+	permission := Permission{Actions: []Action{ActionRead, ActionUpdate}, Scopes: []Scope{Scope(KindDashboard)}}
+	assert.True(t, permission.Allows(ActionUpdate, Scope(KindDashboard)))
+	assert.False(t, permission.Allows(ActionDelete, Scope(KindDashboard)))
+	assert.False(t, permission.Allows(ActionRead, Scope(KindVariable)))
+	assert.False(t, permission.Allows(ActionWildcard, ScopeWildcard))
+	wildcard := Permission{Actions: []Action{ActionWildcard}, Scopes: []Scope{ScopeWildcard}}
+	assert.True(t, wildcard.Allows(ActionDelete, Scope(KindSecret)))
+	assert.True(t, wildcard.Allows(ActionWildcard, ScopeWildcard))
+}
@@ -0,0 +1,205 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	modelAPI "github.com/perses/perses/pkg/model/api"
+)
+
+type SubjectKind string
+
+const (
+	SubjectKindUser SubjectKind = "User"
+	// SubjectKindGroup is a group of users given by the identity provider they logged in with.
+	SubjectKindGroup SubjectKind = "Group"
+	// SubjectKindServiceAccount is a ServiceAccount, used by the automations authenticated with one of its API tokens.
+	SubjectKindServiceAccount SubjectKind = "ServiceAccount"
+)
+
+// Subject is who is given the permissions of a role.
+type Subject struct {
+	// Kind is `User`, `Group` or `ServiceAccount`.
+	Kind SubjectKind `json:"kind" yaml:"kind"`
+	// Name is the login of the user, the name of the group as it is sent by the identity provider, or the name of the service account.
+	Name string `json:"name" yaml:"name"`
+}
+
+type RoleBindingSpec struct {
+	// Role is the name of the role bound. It is a Role of the same project for a RoleBinding, and a GlobalRole for a GlobalRoleBinding.
+	Role     string    `json:"role" yaml:"role"`
+	Subjects []Subject `json:"subjects" yaml:"subjects"`
+}
+
+func (r *RoleBindingSpec) UnmarshalJSON(data []byte) error {
+	var tmp RoleBindingSpec
+	type plain RoleBindingSpec
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*r = tmp
+	return nil
+}
+
+func (r *RoleBindingSpec) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var tmp RoleBindingSpec
+	type plain RoleBindingSpec
+	if err := unmarshal((*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*r = tmp
+	return nil
+}
+
+func (r *RoleBindingSpec) validate() error {
+	if len(r.Role) == 0 {
+		return fmt.Errorf("the role of a binding cannot be empty")
+	}
+	if len(r.Subjects) == 0 {
+		return fmt.Errorf("the subjects of a binding cannot be empty")
+	}
+	for _, subject := range r.Subjects {
+		switch subject.Kind {
+		case SubjectKindUser, SubjectKindGroup, SubjectKindServiceAccount:
+		default:
+			return fmt.Errorf("the kind of a subject can only be %q, %q or %q, not %q", SubjectKindUser, SubjectKindGroup, SubjectKindServiceAccount, subject.Kind)
+		}
+		if len(subject.Name) == 0 {
+			return fmt.Errorf("the name of a subject cannot be empty")
+		}
+	}
+	return nil
+}
+
+// HasUser returns true if the user is one of the subjects.
+func (r *RoleBindingSpec) HasUser(login string) bool {
+	for _, subject := range r.Subjects {
+		if subject.Kind == SubjectKindUser && subject.Name == login {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleBinding gives the permissions of a Role to users, groups and service accounts, in the project of the Role.
+type RoleBinding struct {
+	Kind     Kind            `json:"kind" yaml:"kind"`
+	Metadata ProjectMetadata `json:"metadata" yaml:"metadata"`
+	Spec     RoleBindingSpec `json:"spec" yaml:"spec"`
+}
+
+func (r *RoleBinding) GetMetadata() modelAPI.Metadata {
+	return &r.Metadata
+}
+
+func (r *RoleBinding) GetKind() string {
+	return string(r.Kind)
+}
+
+func (r *RoleBinding) GetSpec() interface{} {
+	return r.Spec
+}
+
+func (r *RoleBinding) UnmarshalJSON(data []byte) error {
+	var tmp RoleBinding
+	type plain RoleBinding
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*r = tmp
+	return nil
+}
+
+func (r *RoleBinding) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var tmp RoleBinding
+	type plain RoleBinding
+	if err := unmarshal((*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*r = tmp
+	return nil
+}
+
+func (r *RoleBinding) validate() error {
+	if r.Kind != KindRoleBinding {
+		return fmt.Errorf("invalid kind: %q for a RoleBinding type", r.Kind)
+	}
+	return nil
+}
+
+// GlobalRoleBinding gives the permissions of a GlobalRole to users, groups and service accounts.
+type GlobalRoleBinding struct {
+	Kind     Kind            `json:"kind" yaml:"kind"`
+	Metadata Metadata        `json:"metadata" yaml:"metadata"`
+	Spec     RoleBindingSpec `json:"spec" yaml:"spec"`
+}
+
+func (g *GlobalRoleBinding) GetMetadata() modelAPI.Metadata {
+	return &g.Metadata
+}
+
+func (g *GlobalRoleBinding) GetKind() string {
+	return string(g.Kind)
+}
+
+func (g *GlobalRoleBinding) GetSpec() interface{} {
+	return g.Spec
+}
+
+func (g *GlobalRoleBinding) UnmarshalJSON(data []byte) error {
+	var tmp GlobalRoleBinding
+	type plain GlobalRoleBinding
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*g = tmp
+	return nil
+}
+
+func (g *GlobalRoleBinding) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var tmp GlobalRoleBinding
+	type plain GlobalRoleBinding
+	if err := unmarshal((*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*g = tmp
+	return nil
+}
+
+func (g *GlobalRoleBinding) validate() error {
+	if g.Kind != KindGlobalRoleBinding {
+		return fmt.Errorf("invalid kind: %q for a GlobalRoleBinding type", g.Kind)
+	}
+	return nil
+}
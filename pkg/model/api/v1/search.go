@@ -0,0 +1,36 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// SearchMatch is a field of a resource matching the search.
+type SearchMatch struct {
+	// Field is the path of the field in the resource, like "spec.panels.cpu.spec.display.name".
+	Field string `json:"field" yaml:"field"`
+	// Value is the content of the field.
+	Value string `json:"value" yaml:"value"`
+}
+
+// SearchResult is a resource matching the search.
+type SearchResult struct {
+	Kind Kind `json:"kind" yaml:"kind"`
+	// Project is empty for the global resources.
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+	Name    string `json:"name" yaml:"name"`
+	// DisplayName is the name displayed in the UI. It is empty if the resource doesn't have one.
+	DisplayName string `json:"displayName,omitempty" yaml:"displayName,omitempty"`
+	// Score is the relevance of the result. The results are sorted by decreasing score.
+	Score float64 `json:"score" yaml:"score"`
+	// Matches are the fields that contain the terms searched.
+	Matches []SearchMatch `json:"matches" yaml:"matches"`
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	"github.com/prometheus/common/model"
+)
+
+var apiTokenNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// APIToken is a long-lived credential of a service account. The token itself is only returned when it is created.
+type APIToken struct {
+	// Name identifies the token among the ones of the service account.
+	Name string `json:"name" yaml:"name"`
+	// Permissions restrict what can be done with the token. They can't give more than the roles bound to the service account.
+	// When they are empty, the token has every permission of the service account.
+	Permissions []Permission `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+	CreatedAt   time.Time    `json:"createdAt" yaml:"createdAt"`
+	ExpiresAt   time.Time    `json:"expiresAt" yaml:"expiresAt"`
+	// LastUsedAt is the last time a request has been authenticated with the token. It is updated once per minute at most,
+	// without changing the version of the service account.
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty" yaml:"lastUsedAt,omitempty"`
+	// Hash is the SHA-256 hash of the secret part of the token. It is only stored in the database, it is never returned by the API.
+	Hash string `json:"hash,omitempty" yaml:"hash,omitempty"`
+}
+
+// IsExpired returns true if the token can't be used anymore.
+func (t *APIToken) IsExpired(now time.Time) bool {
+	return now.After(t.ExpiresAt)
+}
+
+type ServiceAccountSpec struct {
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Tokens can't be modified with the service account. They are managed with the endpoint /serviceaccounts/<name>/tokens.
+	Tokens []APIToken `json:"tokens,omitempty" yaml:"tokens,omitempty"`
+}
+
+// GetToken returns the token with the given name, or nil if it doesn't exist.
+func (s *ServiceAccountSpec) GetToken(name string) *APIToken {
+	for i := range s.Tokens {
+		if s.Tokens[i].Name == name {
+			return &s.Tokens[i]
+		}
+	}
+	return nil
+}
+
+// ServiceAccount is an identity used by the automations, like a CI pipeline, rather than by a person.
+// It is authenticated with its API tokens, and it gets its permissions from the role bindings having it as a subject.
+type ServiceAccount struct {
+	Kind     Kind               `json:"kind" yaml:"kind"`
+	Metadata Metadata           `json:"metadata" yaml:"metadata"`
+	Spec     ServiceAccountSpec `json:"spec" yaml:"spec"`
+}
+
+func (s *ServiceAccount) GetMetadata() modelAPI.Metadata {
+	return &s.Metadata
+}
+
+func (s *ServiceAccount) GetKind() string {
+	return string(s.Kind)
+}
+
+func (s *ServiceAccount) GetSpec() interface{} {
+	return s.Spec
+}
+
+func (s *ServiceAccount) UnmarshalJSON(data []byte) error {
+	var tmp ServiceAccount
+	type plain ServiceAccount
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*s = tmp
+	return nil
+}
+
+func (s *ServiceAccount) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var tmp ServiceAccount
+	type plain ServiceAccount
+	if err := unmarshal((*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*s = tmp
+	return nil
+}
+
+func (s *ServiceAccount) validate() error {
+	if s.Kind != KindServiceAccount {
+		return fmt.Errorf("invalid kind: %q for a ServiceAccount type", s.Kind)
+	}
+	return nil
+}
+
+// APITokenRequest is sent to create a token for a service account.
+type APITokenRequest struct {
+	// Name must be unique among the tokens of the service account. It can only contain letters, digits, `_` and `-`.
+	Name        string       `json:"name" yaml:"name"`
+	Permissions []Permission `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+	// TTL is how long the token can be used. The server uses its default duration when it is not set.
+	TTL model.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+}
+
+func (r *APITokenRequest) UnmarshalJSON(data []byte) error {
+	var tmp APITokenRequest
+	type plain APITokenRequest
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*r = tmp
+	return nil
+}
+
+func (r *APITokenRequest) validate() error {
+	if !apiTokenNameRegexp.MatchString(r.Name) {
+		return fmt.Errorf("the name of a token must match the regexp %s", apiTokenNameRegexp)
+	}
+	if r.TTL < 0 {
+		return fmt.Errorf("the ttl of a token cannot be negative")
+	}
+	return nil
+}
+
+// APITokenResponse is the token created. Token is the only time the secret is given: it must be kept by the client.
+type APITokenResponse struct {
+	APIToken `json:",inline" yaml:",inline"`
+	// Token is sent in the header `Authorization: Bearer <token>` to authenticate the requests.
+	Token string `json:"token" yaml:"token"`
+}
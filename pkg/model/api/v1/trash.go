@@ -0,0 +1,98 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	modelAPI "github.com/perses/perses/pkg/model/api"
+)
+
+// TrashResource identifies the resource that has been deleted.
+type TrashResource struct {
+	// Kind can only be `Dashboard` or `Project`.
+	Kind Kind `json:"kind" yaml:"kind"`
+	// Project is the project of the resource. For a project, it is the name of the project itself.
+	Project string `json:"project" yaml:"project"`
+	Name    string `json:"name" yaml:"name"`
+}
+
+// TrashContent contains every resource removed by a deletion, so they can be restored as they were.
+type TrashContent struct {
+	// Project is only set when the project itself has been deleted.
+	Project            *Project             `json:"project,omitempty" yaml:"project,omitempty"`
+	Dashboards         []*Dashboard         `json:"dashboards,omitempty" yaml:"dashboards,omitempty"`
+	DashboardRevisions []*DashboardRevision `json:"dashboardRevisions,omitempty" yaml:"dashboardRevisions,omitempty"`
+	Datasources        []*Datasource        `json:"datasources,omitempty" yaml:"datasources,omitempty"`
+	Folders            []*Folder            `json:"folders,omitempty" yaml:"folders,omitempty"`
+	Roles              []*Role              `json:"roles,omitempty" yaml:"roles,omitempty"`
+	RoleBindings       []*RoleBinding       `json:"roleBindings,omitempty" yaml:"roleBindings,omitempty"`
+	Secrets            []*Secret            `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+	Variables          []*Variable          `json:"variables,omitempty" yaml:"variables,omitempty"`
+	Webhooks           []*Webhook           `json:"webhooks,omitempty" yaml:"webhooks,omitempty"`
+}
+
+type TrashEntrySpec struct {
+	Resource  TrashResource `json:"resource" yaml:"resource"`
+	DeletedAt time.Time     `json:"deletedAt" yaml:"deletedAt"`
+	// ExpiresAt is the time after which the entry is removed for good.
+	ExpiresAt time.Time `json:"expiresAt" yaml:"expiresAt"`
+	// Content is only stored in the database. It is never returned by the API, as it can contain secrets.
+	Content *TrashContent `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// TrashEntry is a deleted dashboard or project, kept in the trash until it is restored, purged or expired.
+type TrashEntry struct {
+	Kind     Kind           `json:"kind" yaml:"kind"`
+	Metadata Metadata       `json:"metadata" yaml:"metadata"`
+	Spec     TrashEntrySpec `json:"spec" yaml:"spec"`
+}
+
+// NewTrashEntry returns the entry keeping the content deleted during the retention.
+// The name of the entry is generated from the kind of the resource and from the time of the deletion, so it is unique.
+func NewTrashEntry(resource TrashResource, content *TrashContent, retention time.Duration) *TrashEntry {
+	now := time.Now().UTC()
+	entry := &TrashEntry{
+		Kind:     KindTrashEntry,
+		Metadata: *NewMetadata(fmt.Sprintf("%s-%s", strings.ToLower(string(resource.Kind)), strconv.FormatInt(now.UnixNano(), 36))),
+		Spec: TrashEntrySpec{
+			Resource:  resource,
+			DeletedAt: now,
+			ExpiresAt: now.Add(retention),
+			Content:   content,
+		},
+	}
+	entry.Metadata.CreateNow()
+	return entry
+}
+
+// IsExpired returns true if the entry has been in the trash for longer than the retention.
+func (t *TrashEntry) IsExpired(now time.Time) bool {
+	return now.After(t.Spec.ExpiresAt)
+}
+
+func (t *TrashEntry) GetMetadata() modelAPI.Metadata {
+	return &t.Metadata
+}
+
+func (t *TrashEntry) GetKind() string {
+	return string(t.Kind)
+}
+
+func (t *TrashEntry) GetSpec() interface{} {
+	return t.Spec
+}
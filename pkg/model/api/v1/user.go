@@ -0,0 +1,145 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	modelAPI "github.com/perses/perses/pkg/model/api"
+)
+
+// NativeProvider contains the credentials of a user authenticated by Perses itself.
+type NativeProvider struct {
+	// Password is sent in clear text when the user is created or updated. It is stored hashed with bcrypt, and it is never returned by the API.
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+}
+
+// OAuthProvider identifies the account of a user in an OpenID Connect or OAuth 2.0 identity provider.
+// It is set by the API when the user logs in with the provider, and it can't be modified.
+type OAuthProvider struct {
+	// Issuer is the issuer of the OpenID Connect provider, or the authorization URL of the OAuth 2.0 provider.
+	Issuer string `json:"issuer" yaml:"issuer"`
+	// Subject is the identifier of the user in the provider.
+	Subject string `json:"subject" yaml:"subject"`
+}
+
+type UserSpec struct {
+	FirstName      string          `json:"firstName,omitempty" yaml:"firstName,omitempty"`
+	LastName       string          `json:"lastName,omitempty" yaml:"lastName,omitempty"`
+	NativeProvider NativeProvider  `json:"nativeProvider,omitempty" yaml:"nativeProvider,omitempty"`
+	OAuthProviders []OAuthProvider `json:"oauthProviders,omitempty" yaml:"oauthProviders,omitempty"`
+}
+
+// HasOAuthProvider returns true if the user is linked to the account of the provider.
+func (u *UserSpec) HasOAuthProvider(issuer string, subject string) bool {
+	for _, provider := range u.OAuthProviders {
+		if provider.Issuer == issuer && provider.Subject == subject {
+			return true
+		}
+	}
+	return false
+}
+
+// User is an account that can log in to Perses. The name of the user is its login.
+type User struct {
+	Kind     Kind     `json:"kind" yaml:"kind"`
+	Metadata Metadata `json:"metadata" yaml:"metadata"`
+	Spec     UserSpec `json:"spec" yaml:"spec"`
+}
+
+func (u *User) GetMetadata() modelAPI.Metadata {
+	return &u.Metadata
+}
+
+func (u *User) GetKind() string {
+	return string(u.Kind)
+}
+
+func (u *User) GetSpec() interface{} {
+	return u.Spec
+}
+
+func (u *User) UnmarshalJSON(data []byte) error {
+	var tmp User
+	type plain User
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*u = tmp
+	return nil
+}
+
+func (u *User) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var tmp User
+	type plain User
+	if err := unmarshal((*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*u = tmp
+	return nil
+}
+
+func (u *User) validate() error {
+	if u.Kind != KindUser {
+		return fmt.Errorf("invalid kind: %q for a User type", u.Kind)
+	}
+	return nil
+}
+
+type PublicUserSpec struct {
+	FirstName      string          `json:"firstName,omitempty" yaml:"firstName,omitempty"`
+	LastName       string          `json:"lastName,omitempty" yaml:"lastName,omitempty"`
+	OAuthProviders []OAuthProvider `json:"oauthProviders,omitempty" yaml:"oauthProviders,omitempty"`
+}
+
+// PublicUser is the User returned by the API, without its password.
+type PublicUser struct {
+	Kind     Kind           `json:"kind" yaml:"kind"`
+	Metadata Metadata       `json:"metadata" yaml:"metadata"`
+	Spec     PublicUserSpec `json:"spec" yaml:"spec"`
+}
+
+func NewPublicUser(u *User) *PublicUser {
+	if u == nil {
+		return nil
+	}
+	return &PublicUser{
+		Kind:     u.Kind,
+		Metadata: u.Metadata,
+		Spec: PublicUserSpec{
+			FirstName:      u.Spec.FirstName,
+			LastName:       u.Spec.LastName,
+			OAuthProviders: u.Spec.OAuthProviders,
+		},
+	}
+}
+
+func (u *PublicUser) GetMetadata() modelAPI.Metadata {
+	return &u.Metadata
+}
+
+func (u *PublicUser) GetKind() string {
+	return string(u.Kind)
+}
+
+func (u *PublicUser) GetSpec() interface{} {
+	return u.Spec
+}
@@ -0,0 +1,30 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// EventType is the kind of change of a resource.
+type EventType string
+
+const (
+	EventTypeAdded    EventType = "ADDED"
+	EventTypeModified EventType = "MODIFIED"
+	EventTypeDeleted  EventType = "DELETED"
+)
+
+// WatchEvent is a change of a resource. The list endpoints are sending them, one JSON object per line, when the query parameter watch is true.
+type WatchEvent struct {
+	Type EventType `json:"type" yaml:"type"`
+	// Object is the resource. For a deleted resource, it is the last version of the resource.
+	Object interface{} `json:"object" yaml:"object"`
+}
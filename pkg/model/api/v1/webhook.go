@@ -0,0 +1,334 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	defaultWebhookMaxAttempts = 5
+	maxWebhookAttempts        = 20
+	defaultWebhookBackoff     = model.Duration(30 * time.Second)
+	// maxWebhookRetryDelay caps the delay between two attempts, as it is doubled after each of them.
+	maxWebhookRetryDelay = time.Hour
+)
+
+// WebhookSpec describes where the changes are sent, and which of them.
+type WebhookSpec struct {
+	// URL is where the events are sent, with a POST request. It must be an http or an https URL.
+	URL string `json:"url" yaml:"url"`
+	// Kinds are the kinds of the resources whose changes are sent. The changes of every kind are sent when it is empty.
+	Kinds []Kind `json:"kinds,omitempty" yaml:"kinds,omitempty"`
+	// Actions are the changes sent: create, update or delete. Every change is sent when it is empty.
+	Actions []Action `json:"actions,omitempty" yaml:"actions,omitempty"`
+	// Projects restricts the changes sent to the resources of these projects. A change of a project itself belongs to the project.
+	// The changes of every project and of the global resources are sent when it is empty.
+	// It can only be set for a GlobalWebhook, a Webhook only gets the changes of its own project.
+	Projects []string `json:"projects,omitempty" yaml:"projects,omitempty"`
+	// Secret is the name of the secret whose authorization credentials sign the events. It is a Secret of the same project for a Webhook,
+	// and a GlobalSecret for a GlobalWebhook. The events are not signed when it is empty.
+	Secret string `json:"secret,omitempty" yaml:"secret,omitempty"`
+	// MaxAttempts is how many times an event is sent before its delivery is considered failed. Default is 5.
+	MaxAttempts int `json:"maxAttempts,omitempty" yaml:"maxAttempts,omitempty"`
+	// Backoff is the delay before sending again an event that failed. It is doubled after each attempt, up to one hour. Default is 30s.
+	Backoff model.Duration `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+}
+
+func (s *WebhookSpec) validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return fmt.Errorf("the url %q must be an absolute http or https URL", s.URL)
+	}
+	for _, action := range s.Actions {
+		if action != ActionCreate && action != ActionUpdate && action != ActionDelete {
+			return fmt.Errorf("the action %q cannot be sent to a webhook, it can only be %q, %q or %q", action, ActionCreate, ActionUpdate, ActionDelete)
+		}
+	}
+	if s.MaxAttempts < 0 || s.MaxAttempts > maxWebhookAttempts {
+		return fmt.Errorf("maxAttempts must be between 1 and %d", maxWebhookAttempts)
+	}
+	if s.MaxAttempts == 0 {
+		s.MaxAttempts = defaultWebhookMaxAttempts
+	}
+	if s.Backoff < 0 {
+		return fmt.Errorf("backoff cannot be negative")
+	}
+	if s.Backoff == 0 {
+		s.Backoff = defaultWebhookBackoff
+	}
+	return nil
+}
+
+// Matches returns true if the event passes every filter of the webhook.
+func (s *WebhookSpec) Matches(event *WebhookEvent) bool {
+	if len(s.Kinds) > 0 && !containsKind(s.Kinds, event.Resource.Kind) {
+		return false
+	}
+	if len(s.Actions) > 0 && !containsAction(s.Actions, event.Action) {
+		return false
+	}
+	if len(s.Projects) == 0 {
+		return true
+	}
+	for _, project := range s.Projects {
+		if project == event.Resource.Project {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryDelay returns how long to wait before sending again an event that has already been sent the given number of times.
+func (s *WebhookSpec) RetryDelay(attempts int) time.Duration {
+	delay := time.Duration(s.Backoff)
+	if delay <= 0 {
+		delay = time.Duration(defaultWebhookBackoff)
+	}
+	for i := 1; i < attempts && delay < maxWebhookRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxWebhookRetryDelay {
+		return maxWebhookRetryDelay
+	}
+	return delay
+}
+
+func containsKind(kinds []Kind, kind Kind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook sends the changes of the resources of its project to an external service.
+type Webhook struct {
+	Kind     Kind            `json:"kind" yaml:"kind"`
+	Metadata ProjectMetadata `json:"metadata" yaml:"metadata"`
+	Spec     WebhookSpec     `json:"spec" yaml:"spec"`
+}
+
+func (w *Webhook) GetMetadata() modelAPI.Metadata {
+	return &w.Metadata
+}
+
+func (w *Webhook) GetKind() string {
+	return string(w.Kind)
+}
+
+func (w *Webhook) GetSpec() interface{} {
+	return w.Spec
+}
+
+func (w *Webhook) UnmarshalJSON(data []byte) error {
+	var tmp Webhook
+	type plain Webhook
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*w = tmp
+	return nil
+}
+
+func (w *Webhook) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var tmp Webhook
+	type plain Webhook
+	if err := unmarshal((*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*w = tmp
+	return nil
+}
+
+func (w *Webhook) validate() error {
+	if w.Kind != KindWebhook {
+		return fmt.Errorf("invalid kind: %q for a Webhook type", w.Kind)
+	}
+	if len(w.Spec.Projects) > 0 {
+		return fmt.Errorf("projects cannot be set in a Webhook, it only gets the changes of its own project")
+	}
+	return w.Spec.validate()
+}
+
+// GlobalWebhook sends the changes of the resources of every project, and of the global resources, to an external service.
+type GlobalWebhook struct {
+	Kind     Kind        `json:"kind" yaml:"kind"`
+	Metadata Metadata    `json:"metadata" yaml:"metadata"`
+	Spec     WebhookSpec `json:"spec" yaml:"spec"`
+}
+
+func (w *GlobalWebhook) GetMetadata() modelAPI.Metadata {
+	return &w.Metadata
+}
+
+func (w *GlobalWebhook) GetKind() string {
+	return string(w.Kind)
+}
+
+func (w *GlobalWebhook) GetSpec() interface{} {
+	return w.Spec
+}
+
+func (w *GlobalWebhook) UnmarshalJSON(data []byte) error {
+	var tmp GlobalWebhook
+	type plain GlobalWebhook
+	if err := json.Unmarshal(data, (*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*w = tmp
+	return nil
+}
+
+func (w *GlobalWebhook) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var tmp GlobalWebhook
+	type plain GlobalWebhook
+	if err := unmarshal((*plain)(&tmp)); err != nil {
+		return err
+	}
+	if err := (&tmp).validate(); err != nil {
+		return err
+	}
+	*w = tmp
+	return nil
+}
+
+func (w *GlobalWebhook) validate() error {
+	if w.Kind != KindGlobalWebhook {
+		return fmt.Errorf("invalid kind: %q for a GlobalWebhook type", w.Kind)
+	}
+	return w.Spec.validate()
+}
+
+// WebhookEvent is the body of the requests sent to the webhooks: a change made with the API.
+type WebhookEvent struct {
+	Time time.Time `json:"time" yaml:"time"`
+	// Action is create, update or delete.
+	Action   Action        `json:"action" yaml:"action"`
+	Resource AuditResource `json:"resource" yaml:"resource"`
+	// Actor is who made the change: a user or a service account. It is not set when the authentication is disabled.
+	Actor *Subject `json:"actor,omitempty" yaml:"actor,omitempty"`
+	// Object is the resource once changed, or the resource deleted. It is the one returned by the API, so it doesn't contain any secret.
+	Object json.RawMessage `json:"object,omitempty" yaml:"object,omitempty"`
+}
+
+// WebhookReference identifies the webhook an event is delivered to.
+type WebhookReference struct {
+	// Kind is Webhook or GlobalWebhook.
+	Kind Kind `json:"kind" yaml:"kind"`
+	// Project is empty for a GlobalWebhook.
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+	Name    string `json:"name" yaml:"name"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryStatusPending is the status of a delivery that is going to be sent, for the first time or again.
+	WebhookDeliveryStatusPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliveryStatusSucceeded is the status of a delivery the webhook has answered with a 2xx status code.
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryStatusFailed is the status of a delivery that won't be sent anymore. It can be replayed.
+	WebhookDeliveryStatusFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookAttempt is one of the requests sent for a delivery.
+type WebhookAttempt struct {
+	Time time.Time `json:"time" yaml:"time"`
+	// StatusCode is the status code of the response. It is not set when no response has been received.
+	StatusCode int `json:"statusCode,omitempty" yaml:"statusCode,omitempty"`
+	// Error tells why the attempt has failed. It contains the beginning of the body of the response when the status code is not 2xx.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+type WebhookDeliverySpec struct {
+	Webhook WebhookReference      `json:"webhook" yaml:"webhook"`
+	Event   WebhookEvent          `json:"event" yaml:"event"`
+	Status  WebhookDeliveryStatus `json:"status" yaml:"status"`
+	// Attempts are the requests sent so far, the oldest first.
+	Attempts []WebhookAttempt `json:"attempts,omitempty" yaml:"attempts,omitempty"`
+	// NextAttemptAt is when the event is sent again. It is only set while the delivery is pending.
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty" yaml:"nextAttemptAt,omitempty"`
+	// ReplayOf is the name of the delivery this one is replaying.
+	ReplayOf string `json:"replayOf,omitempty" yaml:"replayOf,omitempty"`
+}
+
+// WebhookDelivery is an event to send to a webhook. The deliveries are the queue of the events waiting to be sent,
+// and the log of the ones sent, until their retention is over.
+type WebhookDelivery struct {
+	Kind     Kind                `json:"kind" yaml:"kind"`
+	Metadata Metadata            `json:"metadata" yaml:"metadata"`
+	Spec     WebhookDeliverySpec `json:"spec" yaml:"spec"`
+}
+
+// NewWebhookDelivery returns a pending delivery of the event, to be sent now.
+// The name of the delivery is generated from the time, so the names sort like the deliveries, followed by a random suffix
+// so two deliveries created at the same time don't collide.
+func NewWebhookDelivery(webhook WebhookReference, event WebhookEvent) *WebhookDelivery {
+	now := time.Now().UTC()
+	suffix := make([]byte, 4)
+	// the suffix only avoids collisions, it doesn't need to be unpredictable, so an error of the random generator is ignored.
+	_, _ = rand.Read(suffix)
+	delivery := &WebhookDelivery{
+		Kind:     KindWebhookDelivery,
+		Metadata: *NewMetadata(fmt.Sprintf("%s-%s", strconv.FormatInt(now.UnixNano(), 36), hex.EncodeToString(suffix))),
+		Spec: WebhookDeliverySpec{
+			Webhook:       webhook,
+			Event:         event,
+			Status:        WebhookDeliveryStatusPending,
+			NextAttemptAt: &now,
+		},
+	}
+	delivery.Metadata.CreateNow()
+	return delivery
+}
+
+// IsDue returns true if the delivery is pending and its next attempt is now or before.
+func (d *WebhookDelivery) IsDue(now time.Time) bool {
+	return d.Spec.Status == WebhookDeliveryStatusPending && d.Spec.NextAttemptAt != nil && !d.Spec.NextAttemptAt.After(now)
+}
+
+func (d *WebhookDelivery) GetMetadata() modelAPI.Metadata {
+	return &d.Metadata
+}
+
+func (d *WebhookDelivery) GetKind() string {
+	return string(d.Kind)
+}
+
+func (d *WebhookDelivery) GetSpec() interface{} {
+	return d.Spec
+}
@@ -0,0 +1,208 @@
+// Copyright 2023 The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalWebhook(t *testing.T) {
+	jason := `
+{
+  "kind": "Webhook",
+  "metadata": {
+    "name": "ci",
+    "project": "perses"
+  },
+  "spec": {
+    "url": "https://ci.example.com/hooks/perses",
+    "kinds": ["Dashboard"]
+  }
+}
+`
+	result := &Webhook{}
+	assert.NoError(t, json.Unmarshal([]byte(jason), result))
+	assert.Equal(t, WebhookSpec{
+		URL:         "https://ci.example.com/hooks/perses",
+		Kinds:       []Kind{KindDashboard},
+		MaxAttempts: defaultWebhookMaxAttempts,
+		Backoff:     defaultWebhookBackoff,
+	}, result.Spec)
+}
+
+func TestUnmarshalWebhookError(t *testing.T) {
+	testSuite := []struct {
+		title string
+		jason string
+		err   error
+	}{
+		{
+			title: "relative url",
+			jason: `
+{
+  "kind": "Webhook",
+  "metadata": {
+    "name": "ci",
+    "project": "perses"
+  },
+  "spec": {
+    "url": "/hooks/perses"
+  }
+}
+`,
+			err: fmt.Errorf("the url \"/hooks/perses\" must be an absolute http or https URL"),
+		},
+		{
+			title: "unsupported scheme",
+			jason: `
+{
+  "kind": "Webhook",
+  "metadata": {
+    "name": "ci",
+    "project": "perses"
+  },
+  "spec": {
+    "url": "ftp://ci.example.com/hooks"
+  }
+}
+`,
+			err: fmt.Errorf("the url \"ftp://ci.example.com/hooks\" must be an absolute http or https URL"),
+		},
+		{
+			title: "read is not a change",
+			jason: `
+{
+  "kind": "Webhook",
+  "metadata": {
+    "name": "ci",
+    "project": "perses"
+  },
+  "spec": {
+    "url": "https://ci.example.com/hooks/perses",
+    "actions": ["read"]
+  }
+}
+`,
+			err: fmt.Errorf("the action \"read\" cannot be sent to a webhook, it can only be \"create\", \"update\" or \"delete\""),
+		},
+		{
+			title: "too many attempts",
+			jason: `
+{
+  "kind": "Webhook",
+  "metadata": {
+    "name": "ci",
+    "project": "perses"
+  },
+  "spec": {
+    "url": "https://ci.example.com/hooks/perses",
+    "maxAttempts": 21
+  }
+}
+`,
+			err: fmt.Errorf("maxAttempts must be between 1 and 20"),
+		},
+		{
+			title: "projects of a project webhook",
+			jason: `
+{
+  "kind": "Webhook",
+  "metadata": {
+    "name": "ci",
+    "project": "perses"
+  },
+  "spec": {
+    "url": "https://ci.example.com/hooks/perses",
+    "projects": ["other"]
+  }
+}
+`,
+			err: fmt.Errorf("projects cannot be set in a Webhook, it only gets the changes of its own project"),
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			result := &Webhook{}
+			assert.Equal(t, test.err, json.Unmarshal([]byte(test.jason), result))
+		})
+	}
+}
+
+func TestWebhookSpecMatches(t *testing.T) {
+	event := &WebhookEvent{
+		Action:   ActionUpdate,
+		Resource: AuditResource{Kind: KindDashboard, Project: "perses", Name: "demo"},
+	}
+	testSuite := []struct {
+		title  string
+		spec   WebhookSpec
+		result bool
+	}{
+		{
+			title:  "no filter",
+			spec:   WebhookSpec{},
+			result: true,
+		},
+		{
+			title:  "matching every filter",
+			spec:   WebhookSpec{Kinds: []Kind{KindDatasource, KindDashboard}, Actions: []Action{ActionUpdate}, Projects: []string{"perses"}},
+			result: true,
+		},
+		{
+			title:  "other kind",
+			spec:   WebhookSpec{Kinds: []Kind{KindDatasource}},
+			result: false,
+		},
+		{
+			title:  "other action",
+			spec:   WebhookSpec{Actions: []Action{ActionCreate, ActionDelete}},
+			result: false,
+		},
+		{
+			title:  "other project",
+			spec:   WebhookSpec{Projects: []string{"other"}},
+			result: false,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			assert.Equal(t, test.result, test.spec.Matches(event))
+		})
+	}
+}
+
+func TestWebhookSpecRetryDelay(t *testing.T) {
+	spec := WebhookSpec{Backoff: model.Duration(30 * time.Second)}
+	assert.Equal(t, 30*time.Second, spec.RetryDelay(1))
+	assert.Equal(t, time.Minute, spec.RetryDelay(2))
+	assert.Equal(t, 4*time.Minute, spec.RetryDelay(4))
+	assert.Equal(t, time.Hour, spec.RetryDelay(20))
+}
+
+func TestWebhookDeliveryIsDue(t *testing.T) {
+	delivery := NewWebhookDelivery(WebhookReference{Kind: KindWebhook, Project: "perses", Name: "ci"}, WebhookEvent{Action: ActionCreate})
+	now := time.Now().UTC()
+	assert.True(t, delivery.IsDue(now))
+	later := now.Add(time.Minute)
+	delivery.Spec.NextAttemptAt = &later
+	assert.False(t, delivery.IsDue(now))
+	delivery.Spec.Status = WebhookDeliveryStatusFailed
+	assert.False(t, delivery.IsDue(later))
+}